@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"ngc-go/packages/compiler-cli/src/astexport"
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+)
+
+// runAst runs `ngc-go ast` and returns the exit code.
+func runAst(args []string) int {
+	fs := newFlagSet("ast")
+	levelFlag := fs.String("level", string(astexport.LevelHTML), "AST to export: html, r3 or bound")
+	formatFlag := fs.String("format", "json", "output format: json")
+	projectFlag := fs.String("project", ".", "root of the project declaring the components (r3 and bound levels)")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsageError
+	}
+	if len(positional) != 1 {
+		fmt.Fprintf(os.Stderr, "ast error: expected a template or component file, or - for a template on stdin\n")
+		return exitUsageError
+	}
+	level := astexport.Level(*levelFlag)
+	if level != astexport.LevelHTML && level != astexport.LevelR3 && level != astexport.LevelBound {
+		fmt.Fprintf(os.Stderr, "ast error: unknown level %q, expected html, r3 or bound\n", *levelFlag)
+		return exitUsageError
+	}
+	if *formatFlag != "json" {
+		fmt.Fprintf(os.Stderr, "ast error: unknown format %q, expected json\n", *formatFlag)
+		return exitUsageError
+	}
+
+	templates, err := exportAst(positional[0], level, *projectFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ast error: %v\n", err)
+		return exitErrors
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(struct {
+		File      string                `json:"file"`
+		Level     astexport.Level       `json:"level"`
+		Templates []*astexport.Template `json:"templates"`
+	}{positional[0], level, templates}); err != nil {
+		fmt.Fprintf(os.Stderr, "ast error: %v\n", err)
+		return exitErrors
+	}
+	return exitOK
+}
+
+// exportAst exports the templates of a file, or of the template read from stdin when file is "-".
+// The r3 and bound levels analyze the project under rootPath, whose files are named by their
+// absolute paths so that the file is found whichever way both are written. No component uses the
+// template of stdin.
+func exportAst(file string, level astexport.Level, rootPath string) ([]*astexport.Template, error) {
+	if file == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		return astexport.ExportFile(file, string(data), level, nil)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if level == astexport.LevelHTML {
+		return astexport.ExportFile(file, string(data), level, nil)
+	}
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	files, err := reflectSourceFiles(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("error reading sources: %v", err)
+	}
+	compiler := annotations.NewCompiler(files, annotations.Options{
+		RootDir: absRoot,
+		ResourceLoader: func(path string) (string, error) {
+			data, err := os.ReadFile(path)
+			return string(data), err
+		},
+	})
+	compiler.Analyze()
+	return astexport.ExportFile(absFile, string(data), level, compiler)
+}
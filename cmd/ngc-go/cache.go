@@ -0,0 +1,171 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/incremental"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/core"
+)
+
+// openCache returns the compilation cache of a project, after removing its entries when clear
+// is set. It returns nil when disabled.
+func openCache(rootPath string, disabled bool, clear bool) (*incremental.Cache, error) {
+	cache := incremental.NewCache(filepath.Join(rootPath, incremental.DefaultCacheDir))
+	if clear {
+		if err := cache.Clear(); err != nil {
+			return nil, fmt.Errorf("error clearing the cache: %v", err)
+		}
+	}
+	if disabled {
+		return nil, nil
+	}
+	return cache, nil
+}
+
+// cachedBuild is the plan of a build using the compilation cache: the files whose results are
+// cached are skipped, and the others are compiled along with the files they depend on.
+type cachedBuild struct {
+	cache *incremental.Cache
+	keys  map[string]string
+	// entries are the cached results of the files, by name.
+	entries map[string]*incremental.Entry
+	// analyzed are the files to compile.
+	analyzed []*reflection.SourceFile
+	// owners are the source files of the external templates and stylesheets, whose diagnostics
+	// are cached with them.
+	owners map[string]string
+}
+
+// planBuild looks the files of a project up in the cache. The salt identifies the output, e.g.
+// the compilation mode, and is combined with the version of the compiler. Without cache, every
+// file is compiled.
+func planBuild(cache *incremental.Cache, rootPath string, files []*reflection.SourceFile, salt string) *cachedBuild {
+	b := &cachedBuild{
+		cache:   cache,
+		keys:    make(map[string]string),
+		entries: make(map[string]*incremental.Entry),
+		owners:  make(map[string]string),
+	}
+	if cache == nil {
+		b.analyzed = files
+		return b
+	}
+
+	graph := incremental.NewGraph(files)
+	salt = strings.Join([]string{compilerVersion(), salt, rootPath}, "\n")
+	readResource := func(path string) (string, error) {
+		data, err := os.ReadFile(path)
+		return string(data), err
+	}
+	needed := make(map[string]bool)
+	for _, sf := range files {
+		for _, resource := range annotations.ResourceFiles(sf) {
+			b.owners[resource] = sf.FileName
+		}
+		key := graph.Key(sf.FileName, salt, readResource)
+		b.keys[sf.FileName] = key
+		if entry, ok := cache.Get(key); ok {
+			b.entries[sf.FileName] = entry
+			continue
+		}
+		for _, name := range graph.Dependencies(sf.FileName) {
+			needed[name] = true
+		}
+	}
+	for _, sf := range files {
+		if needed[filepath.Clean(sf.FileName)] {
+			b.analyzed = append(b.analyzed, sf)
+		}
+	}
+	return b
+}
+
+// cached returns the cached result of a file.
+func (b *cachedBuild) cached(sf *reflection.SourceFile) (*incremental.Entry, bool) {
+	entry, ok := b.entries[sf.FileName]
+	return entry, ok
+}
+
+// diagnostics returns the diagnostics of the build: the cached ones of the skipped files and,
+// among the diagnostics of the compiled files, those of the files which are not skipped.
+func (b *cachedBuild) diagnostics(compiled []*diagnostics.Diagnostic) []*diagnostics.Diagnostic {
+	var diags []*diagnostics.Diagnostic
+	for _, diag := range compiled {
+		if _, ok := b.entries[b.owner(diag)]; !ok {
+			diags = append(diags, diag)
+		}
+	}
+	for _, entry := range b.entries {
+		diags = append(diags, entry.Diagnostics...)
+	}
+	diagnostics.Sort(diags)
+	return diags
+}
+
+// store caches the result of a compiled file, with its diagnostics among diags. Failures to
+// write the cache only make the next build slower, so they are logged.
+func (b *cachedBuild) store(sf *reflection.SourceFile, entry *incremental.Entry, diags []*diagnostics.Diagnostic) {
+	if b.cache == nil {
+		return
+	}
+	for _, diag := range diags {
+		if b.owner(diag) == sf.FileName {
+			entry.Diagnostics = append(entry.Diagnostics, diag)
+		}
+	}
+	if err := b.cache.Put(b.keys[sf.FileName], entry); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: error writing the cache: %v\n", err)
+	}
+}
+
+// owner returns the source file a diagnostic is cached with.
+func (b *cachedBuild) owner(diag *diagnostics.Diagnostic) string {
+	if owner, ok := b.owners[diag.File]; ok {
+		return owner
+	}
+	return diag.File
+}
+
+// report logs how many files were skipped.
+func (b *cachedBuild) report(log io.Writer) {
+	if b.cache != nil && len(b.entries) > 0 {
+		fmt.Fprintf(log, "♻️  %d file(s) unchanged since the last build, reused from %s\n", len(b.entries), incremental.DefaultCacheDir)
+	}
+}
+
+var (
+	versionOnce sync.Once
+	version     string
+)
+
+// compilerVersion identifies the compiler for the cache: the Angular version it implements and
+// a hash of its executable, so that rebuilding ngc-go invalidates the cache.
+func compilerVersion() string {
+	versionOnce.Do(func() {
+		version = core.VERSION.Full
+		executable, err := os.Executable()
+		if err != nil {
+			return
+		}
+		f, err := os.Open(executable)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err == nil {
+			version += "+" + hex.EncodeToString(h.Sum(nil))
+		}
+	})
+	return version
+}
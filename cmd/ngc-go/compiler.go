@@ -1,280 +1,161 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
-	"ngc-go/packages/compiler/src/ml_parser"
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/incremental"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck/extended"
 )
 
-// ComponentInfo contains information about an Angular component
-type ComponentInfo struct {
-	FilePath    string
-	ClassName   string
-	Selector    string
-	Template    string
-	TemplateUrl string
-	Styles      []string
-	StyleUrls   []string
-}
-
-// CompileProject compiles an Angular project
-func CompileProject(rootPath string, outputPath string) error {
-	fmt.Printf("🔨 Compiling Angular project at: %s\n", rootPath)
-	fmt.Println("")
-
-	// Find all TypeScript component files
-	components, err := findComponents(rootPath)
+// CompileProject compiles the decorated classes of an application in full compilation mode.
+// Every source file with decorated classes gets an ES module in the output directory at the
+// same relative path, <file>.mjs, which adds the definitions to its classes. The modules import
+// what the definitions refer to: `@angular/core` and the modules of the directives and pipes
+// used by templates as namespaces, and the names of the source file from where it gets them.
+// Like the modules of CompileLibrary, they import the classes from the tsc output of the file.
+// With a cache, the files whose inputs are unchanged since it was written are not compiled
+// again. options are the options changing the output. With options.typeCheckBlocks, the
+// type-check blocks of the templates of a file are written next to its module, as
+// <file>.ngtypecheck.ts; the files taken from the cache get none.
+//
+// The progress is logged to log. The returned error is only set for failures which are not tied
+// to a source file, e.g. when the output directory cannot be written.
+func CompileProject(log io.Writer, rootPath string, outputPath string, options fullOptions, cache *incremental.Cache) ([]*diagnostics.Diagnostic, error) {
+	fmt.Fprintf(log, "🔨 Compiling Angular project at: %s\n", rootPath)
+	fmt.Fprintln(log)
+
+	outputDir := resolveOutputDir(rootPath, outputPath)
+	files, err := reflectSourceFiles(rootPath, outputDir)
 	if err != nil {
-		return fmt.Errorf("error finding components: %v", err)
+		return nil, fmt.Errorf("error reading sources: %v", err)
 	}
-
-	if len(components) == 0 {
-		fmt.Println("⚠️  No Angular components found")
-		return nil
-	}
-
-	fmt.Printf("📦 Found %d component(s)\n", len(components))
-	fmt.Println("")
-
-	// Determine output directory
-	var outputDir string
-	if outputPath != "" {
-		// Use provided output path (can be absolute or relative)
-		if filepath.IsAbs(outputPath) {
-			outputDir = outputPath
-		} else {
-			outputDir = filepath.Join(rootPath, outputPath)
-		}
-	} else {
-		// Default output directory
-		outputDir = filepath.Join(rootPath, "dist", "ngc-go")
+	fmt.Fprintf(log, "📦 Found %d TypeScript file(s)\n", len(files))
+
+	build := planBuild(cache, rootPath, files, options.salt("full"))
+	build.report(log)
+	compiler := annotations.NewCompiler(build.analyzed, options.compilerOptions(rootPath))
+	compiled := compiler.Analyze()
+	diags := build.diagnostics(compiled)
+	if options.domOnly {
+		reportDomOnly(log, compiler)
 	}
 
-	// Create output directory
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("error creating output directory: %v", err)
+		return diags, fmt.Errorf("error creating output directory: %v", err)
 	}
-	
-	fmt.Printf("📁 Output directory: %s\n", outputDir)
-	fmt.Println("")
-
-	// Compile each component
-	successCount := 0
-	for i, comp := range components {
-		fmt.Printf("[%d/%d] Compiling %s...\n", i+1, len(components), comp.ClassName)
-
-		if err := compileComponent(comp, outputDir); err != nil {
-			fmt.Printf("   ❌ Error: %v\n", err)
-			continue
-		}
-
-		successCount++
-		fmt.Printf("   ✅ Compiled successfully\n")
-	}
-
-	fmt.Println("")
-	fmt.Printf("✅ Compilation complete: %d/%d components compiled\n", successCount, len(components))
-
-	if successCount < len(components) {
-		return fmt.Errorf("some components failed to compile")
-	}
-
-	return nil
-}
-
-// findComponents finds all Angular components in the project
-func findComponents(rootPath string) ([]ComponentInfo, error) {
-	var components []ComponentInfo
-
-	// Improved regex patterns to handle various formats
-	compRe := regexp.MustCompile(`@Component\s*\(\s*\{([\s\S]*?)\}\s*\)`)
-	classRe := regexp.MustCompile(`export\s+(?:default\s+)?class\s+(\w+)\s*(?:extends|implements)?`)
-	templateRe := regexp.MustCompile(`template\s*:\s*` + "`" + `([\s\S]*?)` + "`")
-	// Support both single and double quotes, and handle ./ prefix
-	templateUrlRe := regexp.MustCompile(`templateUrl\s*:\s*['\"]([^'\"]+)['\"]`)
-	selectorRe := regexp.MustCompile(`selector\s*:\s*['\"]([^'\"]+)['\"]`)
-
-	var filesChecked int
-	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-
-		if info.IsDir() {
-			// Skip node_modules and dist directories
-			if info.Name() == "node_modules" || info.Name() == "dist" {
-				return filepath.SkipDir
-			}
-			return nil
+	fmt.Fprintf(log, "📁 Output directory: %s\n", outputDir)
+	fmt.Fprintln(log)
+
+	written := 0
+	for _, sf := range files {
+		var source string
+		if entry, ok := build.cached(sf); ok {
+			source = entry.Code
+		} else {
+			source = compiler.EmitFullModule(sf)
+			build.store(sf, &incremental.Entry{Code: source}, compiled)
 		}
-
-		if !strings.HasSuffix(path, ".ts") {
-			return nil
+		if source == "" {
+			continue
 		}
-
-		filesChecked++
-		data, err := os.ReadFile(path)
+		rel, err := filepath.Rel(rootPath, sf.FileName)
 		if err != nil {
-			return nil
-		}
-
-		content := string(data)
-
-		// Check if it's a component
-		if !compRe.MatchString(content) {
-			return nil
+			rel = filepath.Base(sf.FileName)
 		}
-
-		// Extract class name - improved regex to handle default exports
-		classMatch := classRe.FindStringSubmatch(content)
-		if len(classMatch) < 2 {
-			fmt.Printf("   ⚠️  Found @Component in %s but could not extract class name\n", path)
-			return nil
-		}
-		className := classMatch[1]
-
-		// Extract component metadata
-		compMatch := compRe.FindStringSubmatch(content)
-		if len(compMatch) < 2 {
-			return nil
-		}
-		compBody := compMatch[1]
-
-		comp := ComponentInfo{
-			FilePath:  path,
-			ClassName: className,
+		outputFile := filepath.Join(outputDir, strings.TrimSuffix(rel, ".ts")+".mjs")
+		if err := writeFile(outputFile, source); err != nil {
+			return diags, err
 		}
-
-		// Extract selector
-		if selectorMatch := selectorRe.FindStringSubmatch(compBody); len(selectorMatch) >= 2 {
-			comp.Selector = selectorMatch[1]
-		}
-
-		// Extract template
-		if templateMatch := templateRe.FindStringSubmatch(compBody); len(templateMatch) >= 2 {
-			comp.Template = templateMatch[1]
-		} else if templateUrlMatch := templateUrlRe.FindStringSubmatch(compBody); len(templateUrlMatch) >= 2 {
-			comp.TemplateUrl = templateUrlMatch[1]
+		fmt.Fprintf(log, "   📄 %s\n", outputFile)
+		written++
+		if file := compiler.TypeCheckFile(sf); options.typeCheckBlocks && file != nil {
+			typeCheckFile := filepath.Join(outputDir, strings.TrimSuffix(rel, ".ts")+".ngtypecheck.ts")
+			if err := writeFile(typeCheckFile, file.Render()); err != nil {
+				return diags, err
+			}
+			fmt.Fprintf(log, "   📄 %s\n", typeCheckFile)
 		}
-
-		fmt.Printf("   ✓ Found component: %s (selector: %s, template: %s)\n", 
-			className, comp.Selector, 
-			func() string {
-				if comp.Template != "" {
-					return "inline"
-				}
-				if comp.TemplateUrl != "" {
-					return comp.TemplateUrl
-				}
-				return "none"
-			}())
-
-		components = append(components, comp)
-		return nil
-	})
-
-	if err == nil {
-		fmt.Printf("   📂 Scanned %d TypeScript files\n", filesChecked)
 	}
 
-	return components, err
+	fmt.Fprintln(log)
+	if diagnostics.HasErrors(diags) {
+		fmt.Fprintf(log, "❌ %d error(s) found\n", diagnostics.Count(diags, diagnostics.CategoryError))
+	}
+	fmt.Fprintf(log, "✅ Compilation complete: %d module(s) written\n", written)
+	return diags, nil
 }
 
-// compileComponent compiles a single Angular component
-func compileComponent(comp ComponentInfo, outputDir string) error {
-	fmt.Printf("   🔍 Debug: Template='%s', TemplateUrl='%s'\n", 
-		func() string {
-			if comp.Template != "" {
-				return fmt.Sprintf("inline (%d chars)", len(comp.Template))
-			}
-			return "empty"
-		}(),
-		comp.TemplateUrl)
+// fullOptions are the options of the full compilation which change its output.
+type fullOptions struct {
+	// domOnly compiles the components of NgModules matching no directives in DOM-only mode, see
+	// annotations.Options.DomOnly.
+	domOnly bool
+	// closure annotates the output for Closure Compiler, see annotations.Options.ClosureCompiler.
+	closure bool
+	// typeCheckBlocks writes the type-check blocks of the templates of the files next to their
+	// modules, see annotations.Options.TypeCheckBlocks.
+	typeCheckBlocks bool
+	// strictNullChecks reports the null-safe operators of templates applied to values which are
+	// never null, see annotations.Options.StrictNullChecks.
+	strictNullChecks bool
+	// extendedDiagnostics are the options of the extended diagnostics, which are not run when
+	// nil, see annotations.Options.ExtendedDiagnostics.
+	extendedDiagnostics *extended.Options
+}
 
-	if comp.Template == "" && comp.TemplateUrl == "" {
-		return fmt.Errorf("component has no template")
+// compilerOptions returns the options of the compiler of the project under rootPath.
+func (o fullOptions) compilerOptions(rootPath string) annotations.Options {
+	return annotations.Options{
+		RootDir: rootPath,
+		ResourceLoader: func(path string) (string, error) {
+			data, err := os.ReadFile(path)
+			return string(data), err
+		},
+		DomOnly:             o.domOnly,
+		ClosureCompiler:     o.closure,
+		CheckTemplates:      true,
+		TypeCheckBlocks:     o.typeCheckBlocks,
+		StrictNullChecks:    o.strictNullChecks,
+		ExtendedDiagnostics: o.extendedDiagnostics,
 	}
+}
 
-	var templateContent string
-	var templatePath string
-
-	// Get template content - either inline or from file
-	if comp.Template != "" {
-		// Inline template
-		templateContent = comp.Template
-		templatePath = comp.FilePath
-		fmt.Printf("   📝 Using inline template from: %s\n", templatePath)
-	} else if comp.TemplateUrl != "" {
-		// External template file - resolve path relative to component file
-		componentDir := filepath.Dir(comp.FilePath)
-		templatePath = filepath.Join(componentDir, comp.TemplateUrl)
-		
-		// Normalize path (handle ./ prefix)
-		templatePath = filepath.Clean(templatePath)
-		
-		fmt.Printf("   🔍 Attempting to read template from: %s\n", templatePath)
-		
-		// Read template file
-		data, err := os.ReadFile(templatePath)
-		if err != nil {
-			return fmt.Errorf("error reading template file %s: %v", templatePath, err)
-		}
-		templateContent = string(data)
-		fmt.Printf("   📖 Read template from: %s (%d bytes)\n", templatePath, len(data))
-	} else {
-		return fmt.Errorf("no template or templateUrl found")
+// salt adds the options to the salt of the cache, as they change the output.
+func (o fullOptions) salt(salt string) string {
+	if o.domOnly {
+		salt += ",dom-only"
 	}
-
-	// Parse template using ml_parser
-	fmt.Printf("   🔍 Parsing template (%d bytes)...\n", len(templateContent))
-	htmlParser := ml_parser.NewHtmlParser()
-	parseResult := htmlParser.Parse(templateContent, templatePath, nil)
-
-	if len(parseResult.Errors) > 0 {
-		errMsg := fmt.Sprintf("error parsing template: %d errors found", len(parseResult.Errors))
-		for i, err := range parseResult.Errors {
-			if i < 5 { // Show first 5 errors
-				errMsg += fmt.Sprintf("\n      - %v", err)
-			}
-		}
-		if len(parseResult.Errors) > 5 {
-			errMsg += fmt.Sprintf("\n      ... and %d more errors", len(parseResult.Errors)-5)
-		}
-		return fmt.Errorf(errMsg)
+	if o.closure {
+		salt += ",closure"
 	}
-
-	fmt.Printf("   📝 Template parsed: %d nodes\n", len(parseResult.RootNodes))
-
-	// Generate code from AST
-	fmt.Printf("   🔧 Generating factory code...\n")
-	codeGen := NewCodeGenerator()
-	generatedCode := codeGen.Generate(parseResult.RootNodes, comp.ClassName)
-	
-	// Create output file
-	outputFile := filepath.Join(outputDir, strings.ToLower(comp.ClassName)+".ngfactory.js")
-	fmt.Printf("   🔍 Writing output file to: %s\n", outputFile)
-	
-	// Build final output with imports and metadata
-	outputContent := fmt.Sprintf(`// Compiled by ngc-go
-// Component: %s
-// Selector: %s
-// Template: %s
-// Template nodes: %d
-
-import { ɵɵelement, ɵɵelementStart, ɵɵelementEnd, ɵɵtext, ɵɵtextInterpolate, ɵɵattribute } from '@angular/core';
-
-%s
-`, comp.ClassName, comp.Selector, templatePath, len(parseResult.RootNodes), generatedCode)
-
-	if err := os.WriteFile(outputFile, []byte(outputContent), 0644); err != nil {
-		return fmt.Errorf("error writing output file %s: %v", outputFile, err)
+	if o.typeCheckBlocks {
+		salt += ",type-check-blocks"
 	}
+	if o.strictNullChecks {
+		salt += ",strict-null-checks"
+	}
+	if o.extendedDiagnostics != nil {
+		// Maps are marshalled with sorted keys.
+		options, _ := json.Marshal(o.extendedDiagnostics)
+		salt += ",extended-diagnostics=" + string(options)
+	}
+	return salt
+}
 
-	fmt.Printf("   📄 Output file created: %s (%d bytes)\n", outputFile, len(outputContent))
-
-	return nil
+// reportDomOnly logs the components compiled in DOM-only mode. The ones of the files taken from
+// the cache are not listed.
+func reportDomOnly(log io.Writer, compiler *annotations.Compiler) {
+	components := compiler.DomOnlyComponents()
+	if len(components) == 0 {
+		fmt.Fprintln(log, "🪶 No component compiled in DOM-only mode")
+		return
+	}
+	fmt.Fprintf(log, "🪶 %d component(s) compiled in DOM-only mode: %s\n", len(components), strings.Join(components, ", "))
 }
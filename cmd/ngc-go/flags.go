@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/template/pipeline"
+)
+
+// Exit codes of the CLI.
+const (
+	exitOK         = 0
+	exitErrors     = 1
+	exitUsageError = 2
+)
+
+// newFlagSet creates the flag set of a command. Parse errors are returned instead of exiting
+// so that commands can report them with their own usage text.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// parseArgs parses the flags of a command and returns its positional arguments. Unlike
+// `FlagSet.Parse`, flags may appear after positional arguments, e.g.
+// `ngc-go compile ./app --diagnostics-format=json`. Everything after `--` is positional.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		// `FlagSet.Parse` consumes a terminating `--`, leaving only positional arguments.
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// diagnosticsFormatFlag registers `--diagnostics-format` on the flag set.
+func diagnosticsFormatFlag(fs *flag.FlagSet) *string {
+	return fs.String("diagnostics-format", string(diagnostics.FormatText),
+		"output format of diagnostics: text, json or sarif")
+}
+
+// reportDiagnostics writes diagnostics in the requested format. File names are reported
+// relative to the working directory.
+func reportDiagnostics(w io.Writer, diags []*diagnostics.Diagnostic, format diagnostics.Format) error {
+	baseDir, err := os.Getwd()
+	if err != nil {
+		baseDir = ""
+	}
+	diagnostics.Sort(diags)
+	if format == diagnostics.FormatText && len(diags) == 0 {
+		return nil
+	}
+	if err := diagnostics.WriteDiagnostics(w, diags, diagnostics.FormatOptions{
+		Format:  format,
+		BaseDir: baseDir,
+	}); err != nil {
+		return fmt.Errorf("error writing diagnostics: %v", err)
+	}
+	return nil
+}
+
+// parseTraceOptions reads `--dump-ir` and `--component`, returning nil when the pipeline is not
+// traced. Phase names are matched ignoring case.
+func parseTraceOptions(dumpIR string, component string) (*pipeline.TraceOptions, error) {
+	if dumpIR == "" {
+		if component != "" {
+			return nil, fmt.Errorf("--component requires --dump-ir")
+		}
+		return nil, nil
+	}
+	known := make(map[string]bool)
+	for _, name := range pipeline.PhaseNames() {
+		known[strings.ToLower(name)] = true
+	}
+	phases := strings.Split(dumpIR, ",")
+	for _, phase := range phases {
+		if phase != pipeline.DumpAllPhases && !known[strings.ToLower(phase)] {
+			return nil, fmt.Errorf("unknown phase %q in --dump-ir, expected all or one of: %s",
+				phase, strings.Join(pipeline.PhaseNames(), ", "))
+		}
+	}
+	return &pipeline.TraceOptions{DumpIR: phases, Component: component, Out: os.Stderr}, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/format"
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+// runFmt runs `ngc-go fmt` and returns the exit code.
+func runFmt(args []string) int {
+	fs := newFlagSet("fmt")
+	checkFlag := fs.Bool("check", false, "list the files which are not formatted without writing them")
+	widthFlag := fs.Int("width", 80, "line width past which elements and attributes are wrapped")
+	indentFlag := fs.Int("indent", 2, "number of spaces of an indentation level")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsageError
+	}
+	if *widthFlag <= 0 || *indentFlag <= 0 {
+		fmt.Fprintf(os.Stderr, "fmt error: --width and --indent must be positive\n")
+		return exitUsageError
+	}
+	path := "."
+	if len(positional) >= 1 {
+		path = positional[0]
+	}
+
+	files, err := projectSources(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fmt error: %v\n", err)
+		return exitErrors
+	}
+	preserved, err := preservedTemplates(files)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fmt error: %v\n", err)
+		return exitErrors
+	}
+	options := format.Options{Width: *widthFlag, IndentSize: *indentFlag}
+	formatted, changed, failed := 0, 0, 0
+	for _, file := range files {
+		if preserved[filepath.Clean(file)] {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fmt error: %v\n", err)
+			return exitErrors
+		}
+		content, err := format.FormatFile(file, string(data), options)
+		if err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+			failed++
+			continue
+		}
+		formatted++
+		if content == string(data) {
+			continue
+		}
+		changed++
+		if !*checkFlag {
+			info, err := os.Stat(file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "fmt error: %v\n", err)
+				return exitErrors
+			}
+			if err := os.WriteFile(file, []byte(content), info.Mode()); err != nil {
+				fmt.Fprintf(os.Stderr, "fmt error: %v\n", err)
+				return exitErrors
+			}
+		}
+		fmt.Printf("   📄 %s\n", file)
+	}
+
+	fmt.Println("")
+	switch {
+	case *checkFlag && changed > 0:
+		fmt.Printf("❌ %d of %d file(s) not formatted", changed, formatted)
+	case *checkFlag:
+		fmt.Printf("✅ %d file(s) formatted", formatted)
+	default:
+		fmt.Printf("✅ %d file(s) formatted, %d rewritten", formatted, changed)
+	}
+	if failed > 0 {
+		fmt.Printf(", %d with errors", failed)
+	}
+	fmt.Println("")
+	if failed > 0 || (*checkFlag && changed > 0) {
+		return exitErrors
+	}
+	return exitOK
+}
+
+// preservedTemplates returns the external templates of the components with preserveWhitespaces
+// among files, whose whitespace is significant.
+func preservedTemplates(files []string) (map[string]bool, error) {
+	preserved := make(map[string]bool)
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".ts") {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, template := range annotations.TemplateSources(reflection.ReflectSourceFile(file, string(data))) {
+			if template.URL != "" && template.PreserveWhitespaces {
+				preserved[filepath.Clean(template.URL)] = true
+			}
+		}
+	}
+	return preserved, nil
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+const (
+	defaultHmrAddr = "127.0.0.1:4201"
+	// hmrEventsPath streams the update notifications as server-sent events.
+	hmrEventsPath = "/events"
+	// hmrComponentPath serves the update module of a component, `?c=<id>`. The HMR initializers
+	// request it relative to the module of the component, so the dev server serving the modules
+	// is expected to proxy it here.
+	hmrComponentPath = "/@ng/component"
+	// hmrUpdateDir is the directory of the output where update modules are written, as
+	// `<id>.mjs`.
+	hmrUpdateDir = "hmr"
+)
+
+// hmrEvent is a notification sent to the listeners of the HMR endpoint. Component updates are
+// named like the event the HMR initializers listen to on `import.meta.hot`.
+type hmrEvent struct {
+	name string
+	data any
+}
+
+const (
+	hmrEventComponentUpdate = "angular:component-update"
+	hmrEventFullReload      = "full-reload"
+)
+
+// hmrBuilder compiles a project into ES modules with HMR initializers. After the first build,
+// changes to the templates and styles of components are published as update modules, while
+// other changes require reloading the application.
+type hmrBuilder struct {
+	rootPath  string
+	outputDir string
+	server    *hmrServer
+
+	built bool
+	// code is the text of each source file with its component decorators replaced by a marker.
+	// When it changes, the change is not limited to component metadata.
+	code    map[string]string
+	updates map[string]*annotations.HmrUpdate
+}
+
+func newHmrBuilder(rootPath string, outputDir string) *hmrBuilder {
+	return &hmrBuilder{
+		rootPath:  rootPath,
+		outputDir: outputDir,
+		server:    newHmrServer(filepath.Join(outputDir, hmrUpdateDir)),
+		code:      make(map[string]string),
+		updates:   make(map[string]*annotations.HmrUpdate),
+	}
+}
+
+// build compiles the project and notifies the listeners of what changed since the last build.
+func (b *hmrBuilder) build() {
+	files, err := reflectSourceFiles(b.rootPath, b.outputDir)
+	if err != nil {
+		reportWatchDiagnostics(nil, fmt.Errorf("error reading sources: %v", err))
+		return
+	}
+	compiler := annotations.NewCompiler(files, annotations.Options{
+		RootDir: b.rootPath,
+		ResourceLoader: func(path string) (string, error) {
+			data, err := os.ReadFile(path)
+			return string(data), err
+		},
+		Hmr: true,
+	})
+	diags := compiler.Analyze()
+	reportWatchDiagnostics(diags, nil)
+	if diagnostics.HasErrors(diags) {
+		// The application keeps running the last successful build.
+		fmt.Println("❌ Build failed, waiting for changes...")
+		return
+	}
+
+	written := 0
+	code := make(map[string]string)
+	updates := make(map[string]*annotations.HmrUpdate)
+	for _, sf := range files {
+		code[sf.FileName] = withoutComponentDecorators(sf)
+		for _, update := range compiler.HmrUpdates(sf) {
+			updates[update.ID] = update
+		}
+		source := compiler.EmitFullModule(sf)
+		if source == "" {
+			continue
+		}
+		rel, err := filepath.Rel(b.rootPath, sf.FileName)
+		if err != nil {
+			rel = filepath.Base(sf.FileName)
+		}
+		outputFile := filepath.Join(b.outputDir, strings.TrimSuffix(rel, ".ts")+".mjs")
+		if err := writeFile(outputFile, source); err != nil {
+			reportWatchDiagnostics(nil, err)
+			continue
+		}
+		written++
+	}
+	fmt.Printf("✅ Build complete: %d module(s) written to %s\n", written, b.outputDir)
+
+	if b.built {
+		b.notify(code, updates)
+	}
+	b.built = true
+	b.code = code
+	b.updates = updates
+}
+
+// notify publishes the changes of a build. Changed components are updated in place unless the
+// change goes beyond component metadata, or changes what the initializer passes to the update
+// function, in which case the application has to reload.
+func (b *hmrBuilder) notify(code map[string]string, updates map[string]*annotations.HmrUpdate) {
+	if !reflect.DeepEqual(code, b.code) {
+		fmt.Println("🔁 Source code changed, requesting a full reload")
+		b.server.broadcast(hmrEvent{name: hmrEventFullReload, data: map[string]any{}})
+		return
+	}
+	var changed []*annotations.HmrUpdate
+	for id, update := range updates {
+		prev, ok := b.updates[id]
+		if !ok || !reflect.DeepEqual(prev.Dependencies, update.Dependencies) {
+			fmt.Printf("🔁 Dependencies of %s changed, requesting a full reload\n", update.ClassName)
+			b.server.broadcast(hmrEvent{name: hmrEventFullReload, data: map[string]any{}})
+			return
+		}
+		if prev.Module != update.Module {
+			changed = append(changed, update)
+		}
+	}
+	timestamp := time.Now().UnixMilli()
+	for _, update := range changed {
+		updateFile := filepath.Join(b.server.updateDir, update.ID+".mjs")
+		if err := writeFile(updateFile, update.Module); err != nil {
+			reportWatchDiagnostics(nil, err)
+			continue
+		}
+		fmt.Printf("🔥 Updated %s: %s\n", update.ClassName, updateFile)
+		b.server.broadcast(hmrEvent{
+			name: hmrEventComponentUpdate,
+			data: map[string]any{"id": update.ID, "timestamp": timestamp},
+		})
+	}
+}
+
+// withoutComponentDecorators replaces the `@Component` decorators of a source file with a
+// marker, so that the text only changes when something else does.
+func withoutComponentDecorators(sf *reflection.SourceFile) string {
+	var b strings.Builder
+	last := 0
+	for _, class := range sf.Classes {
+		for _, dec := range class.Decorators {
+			if dec.Name != "Component" && !strings.HasSuffix(dec.Name, ".Component") {
+				continue
+			}
+			b.WriteString(sf.Text[last:dec.Start])
+			b.WriteString("@Component")
+			last = dec.End
+		}
+	}
+	b.WriteString(sf.Text[last:])
+	return b.String()
+}
+
+// writeFile writes an output file, creating its directory.
+func writeFile(outputFile string, content string) error {
+	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+		return fmt.Errorf("error creating output directory: %v", err)
+	}
+	if err := os.WriteFile(outputFile, []byte(content), 0644); err != nil {
+		return fmt.Errorf("error writing output file %s: %v", outputFile, err)
+	}
+	return nil
+}
+
+// hmrServer is the HMR endpoint. It streams notifications to its listeners and serves the
+// update modules.
+type hmrServer struct {
+	updateDir string
+	addr      string
+
+	mu        sync.Mutex
+	listeners map[chan hmrEvent]bool
+}
+
+func newHmrServer(updateDir string) *hmrServer {
+	return &hmrServer{updateDir: updateDir, listeners: make(map[chan hmrEvent]bool)}
+}
+
+// listen starts serving on the given address in the background.
+func (s *hmrServer) listen(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error starting the HMR endpoint: %v", err)
+	}
+	s.addr = listener.Addr().String()
+	mux := http.NewServeMux()
+	mux.HandleFunc(hmrEventsPath, s.serveEvents)
+	mux.HandleFunc(hmrComponentPath, s.serveComponent)
+	go http.Serve(listener, mux)
+	return nil
+}
+
+// serveEvents streams notifications as server-sent events until the listener disconnects.
+func (s *hmrServer) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	events := make(chan hmrEvent, 16)
+	s.mu.Lock()
+	s.listeners[events] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, events)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			data, err := json.Marshal(event.data)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, data)
+			flusher.Flush()
+		}
+	}
+}
+
+// serveComponent serves the latest update module of the component `?c=<id>`.
+func (s *hmrServer) serveComponent(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	id := r.URL.Query().Get("c")
+	// IDs are query-escaped, so they can't contain path separators.
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		http.Error(w, "invalid component id", http.StatusBadRequest)
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(s.updateDir, id+".mjs"))
+	if err != nil {
+		// Initializers request updates when they load, before anything changed. An empty
+		// module has no default export, which they ignore.
+		data = nil
+	}
+	w.Header().Set("Content-Type", "text/javascript")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(data)
+}
+
+// broadcast sends an event to all listeners. Listeners which don't keep up miss it.
+func (s *hmrServer) broadcast(event hmrEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for listener := range s.listeners {
+		select {
+		case listener <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/linker"
+)
+
+// runLink runs `ngc-go link` and returns the exit code.
+func runLink(args []string) int {
+	fs := newFlagSet("link")
+	jitFlag := fs.Bool("jit", false, "link NgModules with their selector scope for JIT compilation")
+	unknownVersionFlag := fs.String("unknown-declaration-version", "error",
+		"handling of declarations requiring a newer linker: error, warn or ignore")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsageError
+	}
+	options := linker.Options{JitMode: *jitFlag}
+	switch *unknownVersionFlag {
+	case "error":
+		options.UnknownDeclarationVersionHandling = linker.UnknownDeclarationVersionError
+	case "warn":
+		options.UnknownDeclarationVersionHandling = linker.UnknownDeclarationVersionWarn
+	case "ignore":
+		options.UnknownDeclarationVersionHandling = linker.UnknownDeclarationVersionIgnore
+	default:
+		fmt.Fprintf(os.Stderr, "link error: invalid --unknown-declaration-version %q: expected error, warn or ignore\n", *unknownVersionFlag)
+		return exitUsageError
+	}
+
+	path := "."
+	outputPath := ""
+	if len(positional) >= 1 {
+		path = positional[0]
+	}
+	if len(positional) >= 2 {
+		outputPath = positional[1]
+	}
+	if !LinkFiles(path, outputPath, options) {
+		return exitErrors
+	}
+	return exitOK
+}
+
+// LinkFiles links the partial declarations of the JavaScript files under rootPath, including
+// those of node_modules where libraries are installed. Linked files are written to the same
+// relative path under outputPath, or replace the originals when outputPath is empty. Errors are
+// reported per file; it returns false if any file failed to link.
+func LinkFiles(rootPath string, outputPath string, options linker.Options) bool {
+	fmt.Printf("🔗 Linking partial declarations under: %s\n", rootPath)
+	ok := true
+	linked := 0
+	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		if !strings.HasSuffix(path, ".js") && !strings.HasSuffix(path, ".mjs") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || !linker.NeedsLinking(string(data)) {
+			return nil
+		}
+
+		fileLinker := linker.NewFileLinker(path, string(data), options)
+		source, err := fileLinker.Link()
+		for _, warning := range fileLinker.Warnings {
+			fmt.Fprintf(os.Stderr, "%s: warning: %s\n", path, warning)
+		}
+		if err != nil {
+			if fatalErr, isFatal := err.(*linker.FatalLinkerError); isFatal {
+				line, col := fileLinker.Position(fatalErr.Start)
+				fmt.Fprintf(os.Stderr, "%s:%d:%d: error: %s\n", path, line+1, col+1, fatalErr.Message)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s: error: %v\n", path, err)
+			}
+			ok = false
+			return nil
+		}
+
+		outputFile := path
+		if outputPath != "" {
+			rel, err := filepath.Rel(rootPath, path)
+			if err != nil {
+				rel = filepath.Base(path)
+			}
+			outputFile = filepath.Join(outputPath, rel)
+			if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: error creating output directory: %v\n", path, err)
+				ok = false
+				return nil
+			}
+		}
+		if err := os.WriteFile(outputFile, []byte(source), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: error writing output file: %v\n", outputFile, err)
+			ok = false
+			return nil
+		}
+		fmt.Printf("   📄 %s\n", outputFile)
+		linked++
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "link error: %v\n", err)
+		return false
+	}
+	fmt.Printf("✅ Linking complete: %d file(s) linked\n", linked)
+	return ok
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"ngc-go/packages/language-service/src/lsp"
+)
+
+// runLsp runs `ngc-go lsp` and returns the exit code.
+func runLsp(args []string) int {
+	fs := newFlagSet("lsp")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsageError
+	}
+	// Without a path, the server uses the root the client sends with `initialize`.
+	rootPath := ""
+	if len(positional) >= 1 {
+		rootPath = positional[0]
+	}
+
+	if err := lsp.NewServer(rootPath).Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "lsp error: %v\n", err)
+		return exitErrors
+	}
+	return exitOK
+}
@@ -2,7 +2,13 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/incremental"
+	"ngc-go/packages/compiler/src/template/pipeline"
 )
 
 func usage() {
@@ -13,8 +19,182 @@ Commands:
   compile <path> [output]   Compile project
                             path: project root path
                             output: output directory (optional, default: dist/ngc-go)
-  watch <path>              Watch and compile (placeholder)
-  help                      Show help`)
+                            The templates are checked against the DOM schema and the
+                            directives in scope: unknown elements (NG8001), properties
+                            (NG8002) and events are reported. With the strictTemplates
+                            option of the angularCompilerOptions of the tsconfig.json,
+                            the extended diagnostics are also run, as configured by its
+                            extendedDiagnostics option (checks and defaultCategory).
+  watch <path> [output]     Compile, then recompile when files change
+  link <path> [output]      Link the ɵɵngDeclare* declarations of the .js/.mjs files
+                            under path (including node_modules) into full definitions.
+                            output: directory to write linked files to (optional,
+                            default: link in place)
+  serve-transform [path]    Serve source transforms to bundler plugins over stdin and
+                            stdout, without starting a process per file. Every line read
+                            is a request, {"id", "path", "contents"}, answered in order
+                            by a line {"id", "code", "map", "diagnostics"}: the file
+                            rewritten as by compile --transform with its source map (null
+                            when unchanged) and the diagnostics of the file and of its
+                            templates and stylesheets, as listed by
+                            --diagnostics-format=json; "error" is set instead when the
+                            request cannot be handled. The project under path (default:
+                            .) is read once; requests replace the files they name. Logs
+                            go to stderr.
+  lsp [path]                Serve a language server for the templates of the project under
+                            path (default: the root sent by the client) over stdin and
+                            stdout, speaking the Language Server Protocol: diagnostics of
+                            the open documents, hover, go to definition and completions
+                            in inline and external templates. Logs go to stderr.
+  migrate control-flow [path]
+                            Rewrite the *ngIf, *ngFor and [ngSwitch] directives of the
+                            templates under path (default: .), the .html files and the
+                            inline templates of components, to @if, @for and @switch
+                            blocks in place. else and then templates are moved into the
+                            blocks (or rendered with *ngTemplateOutlet when used
+                            elsewhere), trackBy becomes track and the index, count,
+                            first, last, even and odd variables are renamed; the rest of
+                            the files is unchanged. The uses of the directives which
+                            cannot be migrated are listed and left unchanged.
+  fmt [path]                Format the templates under path (default: .), the .html files
+                            and the inline templates of components, in place: indentation,
+                            attributes wrapped past the line width, blocks broken after
+                            their opening brace and spaced expressions. Only whitespace the compiler
+                            removes is changed; <pre>, ngPreserveWhitespaces, i18n and ICU
+                            content and the templates of components with
+                            preserveWhitespaces are kept as they are.
+  ast <file>                Print the template AST of a template file (.html) or of the
+                            inline templates of a component file (.ts), or of a template
+                            read from stdin with -, as a JSON document,
+                            {"file", "level", "templates"}: every template has its
+                            component, file, offsets, parse errors and nodes. A node has
+                            its "kind" (the Go type of the compiler), its fields (names,
+                            attributes, bindings, expression trees) and its source spans,
+                            with zero-based offsets, lines and columns in the file.
+  help                      Show help
+
+Compile options:
+  --diagnostics-format=<text|json|sarif>
+                            Output format of diagnostics (default: text). With json and
+                            sarif, stdout only contains the diagnostics document and
+                            progress is logged to stderr.
+  --compilation-mode=<full|partial>
+                            full (default) compiles an application into an ES module
+                            per source file (<output>/<file>.mjs) adding the definitions
+                            to its classes. partial emits ɵɵngDeclare* declarations in
+                            an Angular Package Format layout (esm2022/, package.json)
+                            for publishing a library. The sources without their
+                            Angular decorators are written to <output>/sources, for
+                            tsc to emit the classes the declarations are added to.
+  --emit=<js|ts>            js (default) emits JavaScript. ts writes every source file to
+                            the output directory with the full definitions added to its
+                            classes as typed static fields (static ɵcmp:
+                            i0.ɵɵComponentDeclaration<...> = ...), for type-checking and
+                            building with a TypeScript toolchain. Requires
+                            --compilation-mode=full.
+  --declaration             Also write the declaration file of every source file
+                            (<output>/<file>.d.ts), declaring the definitions of its
+                            classes as typed static fields (static ɵcmp:
+                            i0.ɵɵComponentDeclaration<...>;) for the template
+                            type-checking of consumers, and index.d.ts as the types of
+                            the package. Requires --compilation-mode=partial.
+  --transform               Rewrite every .ts and .js source file into the output
+                            directory at the same relative path, assigning the full
+                            definitions to its classes after them (Foo.ɵcmp = ...) and
+                            removing the Angular decorators, for bundling. The rest of
+                            the file is unchanged; rewritten files get a source map
+                            (<output>/<file>.map). Requires --compilation-mode=full and
+                            --emit=js.
+  --no-cache                Compile every file. By default, the full compilation and
+                            --transform store the result of every source file in the
+                            cache directory of the project (<path>/.ngc-go-cache), keyed
+                            by a hash of the file, the files it depends on, its external
+                            templates and stylesheets, the output and the ngc-go build,
+                            and skip the files whose result is in the cache.
+  --clear-cache             Remove the cache directory before compiling.
+  --dom-only                Also compile the components declared by NgModules with the
+                            DOM-only instruction set (ɵɵdomElementStart...) when their
+                            templates match no directives, as standalone components
+                            are, and list the components compiled in DOM-only mode.
+                            The NgModule must not import NgModules from outside the
+                            project. Requires --compilation-mode=full.
+  --closure                 Annotate the output for the advanced optimizations of Closure
+                            Compiler: /** @nocollapse */ on the static fields holding the
+                            definitions, /** @const */ on the constants of JavaScript
+                            output and long strings shared through functions. i18n
+                            messages are emitted for goog.getMsg, with their @desc and
+                            @meaning, when ngI18nClosureMode is set, and for $localize
+                            otherwise. Requires --compilation-mode=full.
+  --type-check-blocks       Write the type-check blocks of the templates of every file
+                            next to its module, as <output>/<file>.ngtypecheck.ts: a
+                            TypeScript function per component checking the expressions
+                            of its template, with /*start,end*/ comments giving their
+                            offsets in the template file. Like the modules, they import
+                            the classes from the tsc output. Requires
+                            --compilation-mode=full and --emit=js, without --transform.
+                            Implies --no-cache.
+  --strict-null-checks      Also report the safe navigation (?.) and non-null assertions
+                            (!) of templates applied to values which are never null, as
+                            strictTemplates does. The nullability of the members of a
+                            component is read from their declarations: optional members
+                            and types including null or undefined may be null; members
+                            without a type or a literal initializer are not checked.
+                            Requires --compilation-mode=full.
+  --dump-ir=<phase,...|all> Print to stderr the time every phase of the template pipeline
+                            takes and, after the listed phases (or all of them), the
+                            create and update operations of every view: their kind,
+                            xref, slot and expressions. Also checks that no IR expression
+                            is left after reification. Implies --no-cache.
+  --component=<name>        Only trace the component class with this name. Requires
+                            --dump-ir.
+
+Watch options:
+  --compilation-mode=<full|partial>
+                            As for compile.
+  --poll-interval=<duration>
+                            Interval between checks for changed files (default: 300ms).
+  --no-cache                As for compile. The cache is not used with --hmr.
+  --hmr                     Hot module replacement. Emits an ES module with the full
+                            definitions and an HMR initializer per source file
+                            (<output>/<file>.mjs). When only the templates or styles of
+                            components change, their update modules are written to
+                            <output>/hmr/<id>.mjs and announced to the listeners of the
+                            HMR endpoint; other changes announce a full reload.
+  --hmr-addr=<host:port>    Address of the HMR endpoint (default: 127.0.0.1:4201). It
+                            streams server-sent events on /events
+                            (angular:component-update and full-reload) and serves
+                            update modules on /@ng/component?c=<id>, where the dev
+                            server is expected to proxy the requests of the initializers.
+
+Migrate options:
+  --dry-run                 List the files which would change without writing them.
+
+Fmt options:
+  --check                   List the files which are not formatted without writing them,
+                            and exit with 1 when there are some.
+  --width=<n>               Line width past which elements and attributes are wrapped
+                            (default: 80).
+  --indent=<n>              Number of spaces of an indentation level (default: 2).
+
+Ast options:
+  --level=<html|r3|bound>   html (default) exports the HTML AST, parsing the file alone. r3
+                            exports the R3 AST of the components of the project using
+                            the template. bound adds the results of the binder: the
+                            directives matched by every element and template, the
+                            targets of the references, the consumers of the bindings and
+                            the references, variables and @let declarations the
+                            expressions read. A template no component uses, such as
+                            stdin, is parsed with the default options and bound against
+                            an empty scope, which sets its "emptyScope".
+  --format=json             Output format (default: json).
+  --project=<path>          Root of the project analyzed at the r3 and bound levels
+                            (default: .).
+
+Link options:
+  --jit                     Keep the selector scope of NgModules for JIT compilation.
+  --unknown-declaration-version=<error|warn|ignore>
+                            What to do with declarations published with a newer version
+                            of Angular than the linker (default: error).`)
 }
 
 func main() {
@@ -27,28 +207,160 @@ func main() {
 	case "help":
 		usage()
 	case "compile":
-		path := "."
-		outputPath := ""
-		if len(os.Args) >= 3 {
-			path = os.Args[2]
-		}
-		if len(os.Args) >= 4 {
-			outputPath = os.Args[3]
-		}
-		if err := compile(path, outputPath); err != nil {
-			fmt.Fprintf(os.Stderr, "compile error: %v\n", err)
-			os.Exit(1)
-		}
+		os.Exit(runCompile(os.Args[2:]))
+	case "link":
+		os.Exit(runLink(os.Args[2:]))
 	case "watch":
-		fmt.Println("watch is not implemented yet")
+		os.Exit(runWatch(os.Args[2:]))
+	case "serve-transform":
+		os.Exit(runServeTransform(os.Args[2:]))
+	case "lsp":
+		os.Exit(runLsp(os.Args[2:]))
+	case "migrate":
+		os.Exit(runMigrate(os.Args[2:]))
+	case "fmt":
+		os.Exit(runFmt(os.Args[2:]))
+	case "ast":
+		os.Exit(runAst(os.Args[2:]))
 	default:
 		usage()
 		os.Exit(1)
 	}
 }
 
-func compile(root string, outputPath string) error {
-	// Import from compiler-cli package instead
-	// For now, keep using local function
-	return CompileProject(root, outputPath)
+// runCompile runs `ngc-go compile` and returns the exit code.
+func runCompile(args []string) int {
+	fs := newFlagSet("compile")
+	formatFlag := diagnosticsFormatFlag(fs)
+	modeFlag := fs.String("compilation-mode", string(annotations.CompilationModeFull),
+		"compilation mode: full or partial")
+	emitFlag := fs.String("emit", emitJS, "output language: js or ts")
+	declarationFlag := fs.Bool("declaration", false, "write declaration files")
+	transformFlag := fs.Bool("transform", false, "rewrite the source files")
+	noCacheFlag := fs.Bool("no-cache", false, "compile every file")
+	clearCacheFlag := fs.Bool("clear-cache", false, "remove the cache before compiling")
+	domOnlyFlag := fs.Bool("dom-only", false, "compile the components matching no directives in DOM-only mode")
+	closureFlag := fs.Bool("closure", false, "annotate the output for Closure Compiler")
+	typeCheckBlocksFlag := fs.Bool("type-check-blocks", false, "write the type-check blocks of the templates")
+	strictNullChecksFlag := fs.Bool("strict-null-checks", false, "report null-safe operators on values which are never null")
+	dumpIRFlag := fs.String("dump-ir", "", "phases after which to print the IR, or all")
+	componentFlag := fs.String("component", "", "component to trace with --dump-ir")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsageError
+	}
+	trace, err := parseTraceOptions(*dumpIRFlag, *componentFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "compile error: %v\n", err)
+		return exitUsageError
+	}
+	format, err := diagnostics.ParseFormat(*formatFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "compile error: %v\n", err)
+		return exitUsageError
+	}
+	mode, err := annotations.ParseCompilationMode(*modeFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "compile error: %v\n", err)
+		return exitUsageError
+	}
+	switch {
+	case *emitFlag != emitJS && *emitFlag != emitTS:
+		fmt.Fprintf(os.Stderr, "compile error: unknown output language %q, expected js or ts\n", *emitFlag)
+		return exitUsageError
+	case *emitFlag == emitTS && mode != annotations.CompilationModeFull:
+		fmt.Fprintf(os.Stderr, "compile error: --emit=ts requires --compilation-mode=full\n")
+		return exitUsageError
+	case *declarationFlag && mode != annotations.CompilationModePartial:
+		fmt.Fprintf(os.Stderr, "compile error: --declaration requires --compilation-mode=partial\n")
+		return exitUsageError
+	case *domOnlyFlag && mode != annotations.CompilationModeFull:
+		fmt.Fprintf(os.Stderr, "compile error: --dom-only requires --compilation-mode=full\n")
+		return exitUsageError
+	case *closureFlag && mode != annotations.CompilationModeFull:
+		fmt.Fprintf(os.Stderr, "compile error: --closure requires --compilation-mode=full\n")
+		return exitUsageError
+	case *typeCheckBlocksFlag && (mode != annotations.CompilationModeFull || *emitFlag != emitJS || *transformFlag):
+		fmt.Fprintf(os.Stderr, "compile error: --type-check-blocks requires --compilation-mode=full and --emit=js, without --transform\n")
+		return exitUsageError
+	case *strictNullChecksFlag && mode != annotations.CompilationModeFull:
+		fmt.Fprintf(os.Stderr, "compile error: --strict-null-checks requires --compilation-mode=full\n")
+		return exitUsageError
+	case *transformFlag && (mode != annotations.CompilationModeFull || *emitFlag != emitJS):
+		fmt.Fprintf(os.Stderr, "compile error: --transform requires --compilation-mode=full and --emit=js\n")
+		return exitUsageError
+	}
+
+	path := "."
+	outputPath := ""
+	if len(positional) >= 1 {
+		path = positional[0]
+	}
+	if len(positional) >= 2 {
+		outputPath = positional[1]
+	}
+
+	// Machine-readable output must be the only thing on stdout, so log the progress to stderr.
+	var log io.Writer = os.Stdout
+	if format != diagnostics.FormatText {
+		log = os.Stderr
+	}
+
+	// Files taken from the cache do not go through the pipeline, so tracing and the type-check
+	// blocks compile every file.
+	if trace != nil {
+		pipeline.SetTraceOptions(trace)
+		defer pipeline.SetTraceOptions(nil)
+	}
+	cache, err := openCache(path, *noCacheFlag || trace != nil || *typeCheckBlocksFlag, *clearCacheFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "compile error: %v\n", err)
+		return exitErrors
+	}
+
+	config, err := readProjectConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "compile error: %v\n", err)
+		return exitErrors
+	}
+	extendedDiagnostics, configDiags := config.extendedDiagnostics(path)
+	options := fullOptions{
+		domOnly:             *domOnlyFlag,
+		closure:             *closureFlag,
+		typeCheckBlocks:     *typeCheckBlocksFlag,
+		strictNullChecks:    *strictNullChecksFlag,
+		extendedDiagnostics: extendedDiagnostics,
+	}
+	var diags []*diagnostics.Diagnostic
+	var compileErr error
+	switch {
+	case *emitFlag == emitTS:
+		diags, compileErr = CompileTypeScript(log, path, outputPath, options)
+	case *declarationFlag:
+		diags, compileErr = CompileLibrary(log, path, outputPath, true)
+	case *transformFlag:
+		diags, compileErr = TransformProject(log, path, outputPath, options, cache)
+	default:
+		diags, compileErr = compile(log, path, outputPath, mode, options, cache)
+	}
+	diags = append(configDiags, diags...)
+	if err := reportDiagnostics(os.Stdout, diags, format); err != nil {
+		fmt.Fprintf(os.Stderr, "compile error: %v\n", err)
+		return exitErrors
+	}
+	if compileErr != nil {
+		fmt.Fprintf(os.Stderr, "compile error: %v\n", compileErr)
+		return exitErrors
+	}
+	if diagnostics.HasErrors(diags) {
+		return exitErrors
+	}
+	return exitOK
+}
+
+func compile(log io.Writer, root string, outputPath string, mode annotations.CompilationMode, options fullOptions, cache *incremental.Cache) ([]*diagnostics.Diagnostic, error) {
+	if mode == annotations.CompilationModePartial {
+		return CompileLibrary(log, root, outputPath, false)
+	}
+	return CompileProject(log, root, outputPath, options, cache)
 }
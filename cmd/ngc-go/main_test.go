@@ -0,0 +1,365 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeProject writes the files of a project to a temporary directory and returns its path.
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// runCommand runs a command of the CLI and returns its exit code and what it wrote on stdout.
+func runCommand(t *testing.T, run func(args []string) int, args ...string) (int, string) {
+	t.Helper()
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	stdout := os.Stdout
+	os.Stdout = out
+	code := run(args)
+	os.Stdout = stdout
+	if _, err := out.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code, string(data)
+}
+
+const uncheckedComponent = `import {Component} from '@angular/core';
+
+@Component({
+  selector: 'app-root',
+  standalone: true,
+  template: '<foo-bar></foo-bar><div [nope]="x"></div>',
+})
+export class AppComponent {}
+`
+
+func TestCompileChecksTemplates(t *testing.T) {
+	t.Run("should report the unknown elements and properties of templates", func(t *testing.T) {
+		root := writeProject(t, map[string]string{"src/app.component.ts": uncheckedComponent})
+		code, out := runCommand(t, runCompile, "--no-cache", root, filepath.Join(root, "out"))
+		if code != exitErrors {
+			t.Errorf("expected exit code %d, got %d", exitErrors, code)
+		}
+		for _, want := range []string{
+			"NG8001",
+			"'foo-bar' is not a known element",
+			"NG8002",
+			"Can't bind to 'nope' since it isn't a known property of 'div'.",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("expected the output to contain %q, got:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("should not report the elements allowed by the schemas of the component", func(t *testing.T) {
+		root := writeProject(t, map[string]string{"src/app.component.ts": `import {Component, CUSTOM_ELEMENTS_SCHEMA} from '@angular/core';
+
+@Component({
+  selector: 'app-root',
+  standalone: true,
+  schemas: [CUSTOM_ELEMENTS_SCHEMA],
+  template: '<foo-bar [baz]="x"></foo-bar>',
+})
+export class AppComponent {}
+`})
+		code, out := runCommand(t, runCompile, "--no-cache", root, filepath.Join(root, "out"))
+		if code != exitOK {
+			t.Errorf("expected exit code %d, got %d:\n%s", exitOK, code, out)
+		}
+	})
+}
+
+func TestCompileDiagnosticsFormat(t *testing.T) {
+	t.Run("should report the template diagnostics in SARIF", func(t *testing.T) {
+		root := writeProject(t, map[string]string{"src/app.component.ts": uncheckedComponent})
+		code, out := runCommand(t, runCompile, "--no-cache", "--diagnostics-format=sarif", root, filepath.Join(root, "out"))
+		if code != exitErrors {
+			t.Errorf("expected exit code %d, got %d", exitErrors, code)
+		}
+		var log struct {
+			Runs []struct {
+				Results []struct {
+					RuleID    string `json:"ruleId"`
+					Level     string `json:"level"`
+					Locations []struct {
+						PhysicalLocation struct {
+							ArtifactLocation struct {
+								URI string `json:"uri"`
+							} `json:"artifactLocation"`
+							Region struct {
+								StartLine   int `json:"startLine"`
+								StartColumn int `json:"startColumn"`
+							} `json:"region"`
+						} `json:"physicalLocation"`
+					} `json:"locations"`
+				} `json:"results"`
+			} `json:"runs"`
+		}
+		if err := json.Unmarshal([]byte(out), &log); err != nil {
+			t.Fatalf("expected a SARIF log on stdout, got %v:\n%s", err, out)
+		}
+		if len(log.Runs) != 1 || len(log.Runs[0].Results) != 2 {
+			t.Fatalf("expected a run with 2 results, got:\n%s", out)
+		}
+		result := log.Runs[0].Results[0]
+		if result.RuleID != "NG8001" || result.Level != "error" {
+			t.Errorf("expected an NG8001 error, got %s %s", result.RuleID, result.Level)
+		}
+		// The element of the inline template, on line 6 of the component file.
+		location := result.Locations[0].PhysicalLocation
+		if !strings.HasSuffix(location.ArtifactLocation.URI, "src/app.component.ts") {
+			t.Errorf("expected the result in the component file, got %s", location.ArtifactLocation.URI)
+		}
+		if location.Region.StartLine != 6 || location.Region.StartColumn != 14 {
+			t.Errorf("expected the result at 6:14, got %d:%d", location.Region.StartLine, location.Region.StartColumn)
+		}
+		if log.Runs[0].Results[1].RuleID != "NG8002" {
+			t.Errorf("expected an NG8002 result, got %s", log.Runs[0].Results[1].RuleID)
+		}
+	})
+}
+
+func TestCompileProjectLog(t *testing.T) {
+	t.Run("should log the progress to the given writer", func(t *testing.T) {
+		root := writeProject(t, map[string]string{"src/app.component.ts": uncheckedComponent})
+		var log strings.Builder
+		_, out := runCommand(t, func([]string) int {
+			if _, err := CompileProject(&log, root, filepath.Join(root, "out"), fullOptions{}, nil); err != nil {
+				t.Fatal(err)
+			}
+			return exitOK
+		})
+		if out != "" {
+			t.Errorf("expected nothing on stdout, got:\n%s", out)
+		}
+		if !strings.Contains(log.String(), "Compilation complete: 1 module(s) written") {
+			t.Errorf("expected the progress in the log, got:\n%s", log.String())
+		}
+	})
+}
+
+func TestCompileDeclarations(t *testing.T) {
+	t.Run("should report the types the declaration files cannot infer", func(t *testing.T) {
+		root := writeProject(t, map[string]string{"src/util.ts": "export function double(x: number) {\n  return x * 2;\n}\n"})
+		code, out := runCommand(t, runCompile, "--compilation-mode=partial", "--declaration", root, filepath.Join(root, "out"))
+		if code != exitErrors || !strings.Contains(out, "NG9103") || !strings.Contains(out, "The return type of 'double' cannot be inferred") {
+			t.Errorf("expected an NG9103 error, got exit code %d:\n%s", code, out)
+		}
+	})
+}
+
+func TestCompileTypeCheckBlocks(t *testing.T) {
+	t.Run("should write the type-check blocks of the templates next to the modules", func(t *testing.T) {
+		root := writeProject(t, map[string]string{"src/app.component.ts": `import {Component} from '@angular/core';
+
+@Component({selector: 'app-root', standalone: true, template: '{{ title }}'})
+export class AppComponent {
+  title = '';
+}
+`})
+		out := filepath.Join(root, "out")
+		if code, stdout := runCommand(t, runCompile, "--type-check-blocks", root, out); code != exitOK {
+			t.Fatalf("expected exit code %d, got %d:\n%s", exitOK, code, stdout)
+		}
+		data, err := os.ReadFile(filepath.Join(out, "src", "app.component.ngtypecheck.ts"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{`from "./app.component.js";`, "function _tcb1(this: i1.AppComponent) {", "this.title /*"} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("expected the type-check file to contain %q, got:\n%s", want, data)
+			}
+		}
+	})
+
+	t.Run("should require the full compilation of JavaScript", func(t *testing.T) {
+		root := writeProject(t, map[string]string{"src/app.component.ts": uncheckedComponent})
+		if code, _ := runCommand(t, runCompile, "--type-check-blocks", "--compilation-mode=partial", root); code != exitUsageError {
+			t.Errorf("expected exit code %d, got %d", exitUsageError, code)
+		}
+	})
+}
+
+func TestCompileStrictNullChecks(t *testing.T) {
+	root := writeProject(t, map[string]string{"src/app.component.ts": `import {Component} from '@angular/core';
+
+@Component({selector: 'app-root', standalone: true, template: '{{ title?.length }}'})
+export class AppComponent {
+  title = '';
+}
+`})
+	t.Run("should not check null safety by default", func(t *testing.T) {
+		code, out := runCommand(t, runCompile, "--no-cache", root, filepath.Join(root, "out"))
+		if code != exitOK || strings.Contains(out, "NG8107") {
+			t.Errorf("expected no diagnostic, got exit code %d:\n%s", code, out)
+		}
+	})
+
+	t.Run("should report null-safe operators on values which are never null", func(t *testing.T) {
+		code, out := runCommand(t, runCompile, "--no-cache", "--strict-null-checks", root, filepath.Join(root, "out"))
+		// The diagnostic is a warning.
+		if code != exitOK {
+			t.Errorf("expected exit code %d, got %d", exitOK, code)
+		}
+		if !strings.Contains(out, "NG8107") {
+			t.Errorf("expected an NG8107 warning, got:\n%s", out)
+		}
+	})
+}
+
+func TestCompileExtendedDiagnostics(t *testing.T) {
+	component := `import {Component} from '@angular/core';
+
+@Component({selector: 'app-root', standalone: true, template: '<div ([title])="name"></div>'})
+export class AppComponent {
+  name = '';
+}
+`
+
+	t.Run("should run the extended diagnostics configured in the tsconfig.json", func(t *testing.T) {
+		root := writeProject(t, map[string]string{
+			"src/app.component.ts": component,
+			"tsconfig.json": `{
+  "angularCompilerOptions": {
+    // The extended diagnostics only run with strictTemplates.
+    "strictTemplates": true,
+    "extendedDiagnostics": {"checks": {"invalidBananaInBox": "error"}},
+  },
+}`,
+		})
+		code, out := runCommand(t, runCompile, "--no-cache", root, filepath.Join(root, "out"))
+		if code != exitErrors || !strings.Contains(out, "NG8101") || strings.Contains(out, "NG9101") {
+			t.Errorf("expected an NG8101 error only, got exit code %d:\n%s", code, out)
+		}
+	})
+
+	t.Run("should not run the extended diagnostics without strictTemplates", func(t *testing.T) {
+		root := writeProject(t, map[string]string{"src/app.component.ts": component})
+		code, out := runCommand(t, runCompile, "--no-cache", root, filepath.Join(root, "out"))
+		if code != exitOK || strings.Contains(out, "NG8101") {
+			t.Errorf("expected no diagnostic, got exit code %d:\n%s", code, out)
+		}
+	})
+
+	t.Run("should report extended diagnostics configured without strictTemplates", func(t *testing.T) {
+		root := writeProject(t, map[string]string{
+			"src/app.component.ts": component,
+			"tsconfig.json":        `{"angularCompilerOptions": {"extendedDiagnostics": {"defaultCategory": "error"}}}`,
+		})
+		code, out := runCommand(t, runCompile, "--no-cache", root, filepath.Join(root, "out"))
+		if code != exitErrors || !strings.Contains(out, "NG4003") || strings.Contains(out, "NG8101") {
+			t.Errorf("expected an NG4003 error only, got exit code %d:\n%s", code, out)
+		}
+	})
+}
+
+func TestAstUnownedTemplates(t *testing.T) {
+	t.Run("should bind a template read from stdin against an empty scope", func(t *testing.T) {
+		in := filepath.Join(t.TempDir(), "stdin")
+		if err := os.WriteFile(in, []byte(`<p #x>{{ x }}</p>`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Open(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		stdin := os.Stdin
+		os.Stdin = f
+		defer func() { os.Stdin = stdin }()
+
+		code, out := runCommand(t, runAst, "--level=bound", "-")
+		if code != exitOK {
+			t.Fatalf("expected exit code %d, got %d:\n%s", exitOK, code, out)
+		}
+		if !strings.Contains(out, `"emptyScope": true`) {
+			t.Errorf("expected an empty scope, got:\n%s", out)
+		}
+	})
+
+	t.Run("should export the R3 AST of a template file no component uses", func(t *testing.T) {
+		root := writeProject(t, map[string]string{"src/app.component.ts": uncheckedComponent, "src/other.html": "<b></b>"})
+		code, out := runCommand(t, runAst, "--level=r3", "--project="+root, filepath.Join(root, "src", "other.html"))
+		if code != exitOK || !strings.Contains(out, `"name": "b"`) || strings.Contains(out, "emptyScope") {
+			t.Errorf("expected the R3 AST without a scope, got exit code %d:\n%s", code, out)
+		}
+	})
+}
+
+func TestServeTransform(t *testing.T) {
+	t.Run("should report the unknown elements and properties of templates", func(t *testing.T) {
+		root := writeProject(t, map[string]string{"src/app.component.ts": uncheckedComponent})
+		service, err := newTransformService(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out strings.Builder
+		request, err := json.Marshal(transformRequest{
+			ID:       json.RawMessage("1"),
+			Path:     filepath.Join(root, "src", "app.component.ts"),
+			Contents: &[]string{uncheckedComponent}[0],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := service.serve(strings.NewReader(string(request)+"\n"), &out); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"NG8001", "NG8002"} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("expected the response to contain %q, got:\n%s", want, out.String())
+			}
+		}
+	})
+
+	t.Run("should only analyze the files the requested file depends on", func(t *testing.T) {
+		root := writeProject(t, map[string]string{
+			"src/app.component.ts": `import {Component} from '@angular/core';
+import {title} from './title';
+
+@Component({selector: 'app-root', standalone: true, template: '{{ title }}'})
+export class AppComponent {
+  title = title;
+}
+`,
+			"src/title.ts": "export const title = 'app';\n",
+			"src/other.ts": uncheckedComponent,
+		})
+		service, err := newTransformService(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, sf := range service.dependencies(filepath.Join(root, "src", "app.component.ts")) {
+			names = append(names, filepath.Base(sf.FileName))
+		}
+		if got := strings.Join(names, ","); got != "app.component.ts,title.ts" {
+			t.Errorf("expected the component and its import, got %s", got)
+		}
+	})
+}
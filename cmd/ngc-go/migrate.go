@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/migrations"
+)
+
+// runMigrate runs `ngc-go migrate <migration>` and returns the exit code.
+func runMigrate(args []string) int {
+	if len(args) == 0 || args[0] != "control-flow" {
+		fmt.Fprintf(os.Stderr, "migrate error: unknown migration, expected control-flow\n")
+		return exitUsageError
+	}
+	fs := newFlagSet("migrate control-flow")
+	dryRunFlag := fs.Bool("dry-run", false, "report the changes without writing them")
+	positional, err := parseArgs(fs, args[1:])
+	if err != nil {
+		return exitUsageError
+	}
+	path := "."
+	if len(positional) >= 1 {
+		path = positional[0]
+	}
+
+	files, err := projectSources(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate error: %v\n", err)
+		return exitErrors
+	}
+	migrated, changed, problems := 0, 0, 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate error: %v\n", err)
+			return exitErrors
+		}
+		m := migrations.MigrateControlFlow(file, string(data))
+		for _, problem := range m.Problems {
+			fmt.Printf("⚠️  %s\n", problem)
+		}
+		problems += len(m.Problems)
+		if m.Content == string(data) {
+			continue
+		}
+		if !*dryRunFlag {
+			info, err := os.Stat(file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "migrate error: %v\n", err)
+				return exitErrors
+			}
+			if err := os.WriteFile(file, []byte(m.Content), info.Mode()); err != nil {
+				fmt.Fprintf(os.Stderr, "migrate error: %v\n", err)
+				return exitErrors
+			}
+		}
+		fmt.Printf("   📄 %s: %d directive(s) migrated\n", file, m.Migrated)
+		migrated += m.Migrated
+		changed++
+	}
+
+	fmt.Println("")
+	if *dryRunFlag {
+		fmt.Printf("✅ %d directive(s) to migrate in %d file(s) (dry run, nothing written)", migrated, changed)
+	} else {
+		fmt.Printf("✅ %d directive(s) migrated in %d file(s)", migrated, changed)
+	}
+	if problems > 0 {
+		fmt.Printf(", %d left unchanged", problems)
+	}
+	fmt.Println("")
+	return exitOK
+}
+
+// projectSources returns the templates and TypeScript sources under a path, skipping dependencies
+// and build output, or the path itself when it is a file.
+func projectSources(rootPath string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != rootPath && (info.Name() == "node_modules" || info.Name() == "dist" || strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".html") || (strings.HasSuffix(path, ".ts") && !strings.HasSuffix(path, ".d.ts")) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
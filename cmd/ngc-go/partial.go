@@ -0,0 +1,291 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+// CompileLibrary compiles the decorated classes of a library in partial compilation mode. The
+// output directory is laid out like an Angular Package Format build:
+//
+//	esm2022/<file>.mjs  adds the ɵɵngDeclare* declarations to the classes of <file>.ts
+//	esm2022/index.mjs   re-exports all modules
+//	package.json        points `module` and `exports` at esm2022/index.mjs
+//	sources/<file>.ts   <file>.ts without the Angular decorators of its compiled classes
+//
+// With declaration, the declaration files are written along with them:
+//
+//	<file>.d.ts         declares the classes of <file>.ts with their typed definitions
+//	index.d.ts          re-exports the declarations of all modules
+//
+// The modules import the classes from the JavaScript emitted by tsc for the same file, which
+// is expected next to them. tsc must compile the sources directory rather than the project, as
+// the decorators would define the definitions the modules assign.
+func CompileLibrary(log io.Writer, rootPath string, outputPath string, declaration bool) ([]*diagnostics.Diagnostic, error) {
+	fmt.Fprintf(log, "🔨 Compiling Angular library at: %s (partial compilation)\n", rootPath)
+	fmt.Fprintln(log)
+
+	outputDir := resolveOutputDir(rootPath, outputPath)
+	files, err := reflectSourceFiles(rootPath, outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading sources: %v", err)
+	}
+	fmt.Fprintf(log, "📦 Found %d TypeScript file(s)\n", len(files))
+
+	compiler := annotations.NewCompiler(files, annotations.Options{
+		RootDir: rootPath,
+		ResourceLoader: func(path string) (string, error) {
+			data, err := os.ReadFile(path)
+			return string(data), err
+		},
+		CheckTemplates: true,
+	})
+	diags := compiler.Analyze()
+
+	esmDir := filepath.Join(outputDir, "esm2022")
+	if err := os.MkdirAll(esmDir, 0755); err != nil {
+		return diags, fmt.Errorf("error creating output directory: %v", err)
+	}
+	fmt.Fprintf(log, "📁 Output directory: %s\n", outputDir)
+	fmt.Fprintln(log)
+
+	var modules []string
+	for _, sf := range files {
+		source := compiler.EmitPartialModule(sf)
+		if source == "" {
+			continue
+		}
+		rel, err := filepath.Rel(rootPath, sf.FileName)
+		if err != nil {
+			rel = filepath.Base(sf.FileName)
+		}
+		module := filepath.ToSlash(strings.TrimSuffix(rel, ".ts") + ".mjs")
+		outputFile := filepath.Join(esmDir, filepath.FromSlash(module))
+		if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+			return diags, fmt.Errorf("error creating output directory: %v", err)
+		}
+		if err := os.WriteFile(outputFile, []byte(source), 0644); err != nil {
+			return diags, fmt.Errorf("error writing output file %s: %v", outputFile, err)
+		}
+		fmt.Fprintf(log, "   📄 %s\n", outputFile)
+		modules = append(modules, module)
+	}
+
+	if err := writeIndexModule(esmDir, modules); err != nil {
+		return diags, err
+	}
+	if err := writeUndecoratedSources(compiler, rootPath, filepath.Join(outputDir, "sources"), files); err != nil {
+		return diags, err
+	}
+	if declaration {
+		declarationDiags, err := writeDeclarations(log, compiler, rootPath, outputDir, files, modules)
+		diags = append(diags, declarationDiags...)
+		if err != nil {
+			return diags, err
+		}
+	}
+	if err := writePackageJSON(rootPath, outputDir, declaration); err != nil {
+		return diags, err
+	}
+
+	fmt.Fprintln(log)
+	fmt.Fprintf(log, "✅ Compilation complete: %d module(s) written\n", len(modules))
+	fmt.Fprintf(log, "   ℹ️  The modules import the classes from the tsc output of each file (./<file>.js): compile %s into esm2022\n",
+		filepath.Join(outputDir, "sources"))
+	return diags, nil
+}
+
+// reflectSourceFiles reads the TypeScript sources of a project, skipping dependencies, build
+// output and declaration files. outputDirs are the directories the command writes to.
+func reflectSourceFiles(rootPath string, outputDirs ...string) ([]*reflection.SourceFile, error) {
+	return reflectFiles(rootPath, func(path string) bool {
+		return strings.HasSuffix(path, ".ts") && !strings.HasSuffix(path, ".d.ts") && !strings.HasSuffix(path, ".spec.ts")
+	}, outputDirs...)
+}
+
+// reflectFiles reads the files of a project accepted by isSource, skipping dependencies and
+// build output: node_modules and dist, the outDir of the tsconfig.json of the project and
+// outputDirs, so that the output of a build is not read back as sources by the next one.
+func reflectFiles(rootPath string, isSource func(path string) bool, outputDirs ...string) ([]*reflection.SourceFile, error) {
+	config, err := readProjectConfig(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	skipped := make(map[string]bool)
+	for _, dir := range append(outputDirs, config.outDir(rootPath)) {
+		if abs, err := filepath.Abs(dir); err == nil && dir != "" {
+			skipped[abs] = true
+		}
+	}
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*reflection.SourceFile
+	err = filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if info.Name() == "node_modules" || info.Name() == "dist" {
+				return filepath.SkipDir
+			}
+			// The project is read even when it is its own output directory.
+			if abs, err := filepath.Abs(path); err == nil && abs != absRoot && skipped[abs] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isSource(path) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		files = append(files, reflection.ReflectSourceFile(path, string(data)))
+		return nil
+	})
+	return files, err
+}
+
+// resolveOutputDir returns the output directory, dist/ngc-go by default. Relative paths are
+// resolved against the project root.
+func resolveOutputDir(rootPath string, outputPath string) string {
+	switch {
+	case outputPath == "":
+		return filepath.Join(rootPath, "dist", "ngc-go")
+	case filepath.IsAbs(outputPath):
+		return outputPath
+	}
+	return filepath.Join(rootPath, outputPath)
+}
+
+// writeDeclarations writes the declaration file of every source file, and the entry point
+// re-exporting the declarations of the given modules. It returns the diagnostics of the
+// declaration files.
+func writeDeclarations(log io.Writer, compiler *annotations.Compiler, rootPath string, outputDir string, files []*reflection.SourceFile, modules []string) ([]*diagnostics.Diagnostic, error) {
+	var diags []*diagnostics.Diagnostic
+	for _, sf := range files {
+		rel, err := filepath.Rel(rootPath, sf.FileName)
+		if err != nil {
+			rel = filepath.Base(sf.FileName)
+		}
+		outputFile := filepath.Join(outputDir, strings.TrimSuffix(rel, ".ts")+".d.ts")
+		declaration, declarationDiags := compiler.EmitDeclarations(sf)
+		diags = append(diags, declarationDiags...)
+		if err := writeFile(outputFile, declaration); err != nil {
+			return diags, err
+		}
+		fmt.Fprintf(log, "   📄 %s\n", outputFile)
+	}
+
+	var b strings.Builder
+	for _, module := range modules {
+		fmt.Fprintf(&b, "export * from './%s';\n", strings.TrimSuffix(module, ".mjs"))
+	}
+	return diags, writeFile(filepath.Join(outputDir, "index.d.ts"), b.String())
+}
+
+// writeUndecoratedSources writes every source file without the Angular decorators of its compiled
+// classes, at the same relative path in dir.
+func writeUndecoratedSources(compiler *annotations.Compiler, rootPath string, dir string, files []*reflection.SourceFile) error {
+	for _, sf := range files {
+		rel, err := filepath.Rel(rootPath, sf.FileName)
+		if err != nil {
+			rel = filepath.Base(sf.FileName)
+		}
+		if err := writeFile(filepath.Join(dir, rel), compiler.EmitUndecorated(sf)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeIndexModule writes the entry point re-exporting all modules.
+func writeIndexModule(esmDir string, modules []string) error {
+	sort.Strings(modules)
+	var b strings.Builder
+	for _, module := range modules {
+		fmt.Fprintf(&b, "export * from './%s';\n", module)
+	}
+	indexFile := filepath.Join(esmDir, "index.mjs")
+	if err := os.WriteFile(indexFile, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("error writing output file %s: %v", indexFile, err)
+	}
+	return nil
+}
+
+// exportConditions are the conditions of an entry of `exports`. TypeScript only resolves `types`
+// when it precedes `default`, hence a struct rather than a map.
+type exportConditions struct {
+	Types   string `json:"types,omitempty"`
+	Default string `json:"default"`
+}
+
+// writePackageJSON writes the package.json of the library. The name and version are taken from
+// the package.json of the project when there is one, the name defaulting to the name of the
+// project directory. With declaration, `typings` and the `types` condition point at index.d.ts.
+func writePackageJSON(rootPath string, outputDir string, declaration bool) error {
+	name := filepath.Base(rootPath)
+	if abs, err := filepath.Abs(rootPath); err == nil {
+		name = filepath.Base(abs)
+	}
+	pkg := struct {
+		Name        string            `json:"name"`
+		Version     string            `json:"version"`
+		Module      string            `json:"module"`
+		Typings     string            `json:"typings,omitempty"`
+		Exports     map[string]any    `json:"exports"`
+		SideEffects bool              `json:"sideEffects"`
+		Peer        map[string]string `json:"peerDependencies,omitempty"`
+	}{
+		Name:    name,
+		Version: "0.0.0",
+		Module:  "./esm2022/index.mjs",
+		Exports: map[string]any{
+			"./package.json": exportConditions{Default: "./package.json"},
+			".":              exportConditions{Default: "./esm2022/index.mjs"},
+		},
+	}
+	if declaration {
+		pkg.Typings = "./index.d.ts"
+		pkg.Exports["."] = exportConditions{Types: "./index.d.ts", Default: "./esm2022/index.mjs"}
+	}
+	if data, err := os.ReadFile(filepath.Join(rootPath, "package.json")); err == nil {
+		var project struct {
+			Name             string            `json:"name"`
+			Version          string            `json:"version"`
+			PeerDependencies map[string]string `json:"peerDependencies"`
+		}
+		if err := json.Unmarshal(data, &project); err == nil {
+			if project.Name != "" {
+				pkg.Name = project.Name
+			}
+			if project.Version != "" {
+				pkg.Version = project.Version
+			}
+			pkg.Peer = project.PeerDependencies
+		}
+	}
+
+	data, err := json.MarshalIndent(pkg, "", "  ")
+	if err != nil {
+		return err
+	}
+	packageFile := filepath.Join(outputDir, "package.json")
+	if err := os.WriteFile(packageFile, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing output file %s: %v", packageFile, err)
+	}
+	return nil
+}
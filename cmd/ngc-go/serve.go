@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/incremental"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/output"
+)
+
+// transformRequest asks the transform service to rewrite a source file, like
+// `compile --transform`. Relative paths are resolved against the working directory.
+type transformRequest struct {
+	ID       json.RawMessage `json:"id"`
+	Path     string          `json:"path"`
+	Contents *string         `json:"contents"`
+}
+
+// transformResponse answers a transformRequest with the same id. Map is null when the file is
+// unchanged; Error is set instead of the rest when the request cannot be handled.
+type transformResponse struct {
+	ID          json.RawMessage   `json:"id"`
+	Code        string            `json:"code"`
+	Map         *output.SourceMap `json:"map"`
+	Diagnostics interface{}       `json:"diagnostics"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// runServeTransform runs `ngc-go serve-transform` and returns the exit code.
+func runServeTransform(args []string) int {
+	fs := newFlagSet("serve-transform")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsageError
+	}
+	rootPath := "."
+	if len(positional) >= 1 {
+		rootPath = positional[0]
+	}
+
+	service, err := newTransformService(rootPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "serve-transform error: %v\n", err)
+		return exitErrors
+	}
+	if err := service.serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "serve-transform error: %v\n", err)
+		return exitErrors
+	}
+	return exitOK
+}
+
+// transformService rewrites the source files of a project on request, for bundler plugins which
+// would otherwise start a process per file. It reads the project once, and then keeps it up to
+// date with the contents of the requests, which are what the bundler is building: a request
+// replaces the file it names. Files imported by a request which are not known yet are read from
+// disk.
+//
+// A request only analyzes the files the compilation of its file depends on, with the options of
+// `compile --transform`. Between requests, it keeps the parsed templates of the project (the
+// parsed DOM schema is shared by the whole process), so that a request only parses the templates
+// that changed.
+type transformService struct {
+	rootPath  string
+	options   fullOptions
+	files     map[string]*reflection.SourceFile
+	templates *annotations.TemplateCache
+}
+
+func newTransformService(rootPath string) (*transformService, error) {
+	rootPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	files, err := reflectFiles(rootPath, isTransformSource)
+	if err != nil {
+		return nil, fmt.Errorf("error reading sources: %v", err)
+	}
+	config, err := readProjectConfig(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	// The problems of the configuration are not those of a file, so they are only logged.
+	extendedDiagnostics, configDiags := config.extendedDiagnostics(rootPath)
+	if err := reportDiagnostics(os.Stderr, configDiags, diagnostics.FormatText); err != nil {
+		return nil, err
+	}
+	s := &transformService{
+		rootPath:  rootPath,
+		options:   fullOptions{extendedDiagnostics: extendedDiagnostics},
+		files:     make(map[string]*reflection.SourceFile),
+		templates: annotations.NewTemplateCache(),
+	}
+	for _, sf := range files {
+		s.files[sf.FileName] = sf
+	}
+	fmt.Fprintf(os.Stderr, "📦 Serving transforms of %d source file(s) under %s\n", len(files), rootPath)
+	return s, nil
+}
+
+// serve answers the requests read from r, one JSON document per line, with a response per
+// line on w, in order. It returns when r is exhausted.
+func (s *transformService) serve(r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			if err := encoder.Encode(s.handle(line)); err != nil {
+				return fmt.Errorf("error writing response: %v", err)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading request: %v", err)
+		}
+	}
+}
+
+// handle answers a request.
+func (s *transformService) handle(line []byte) *transformResponse {
+	var req transformRequest
+	err := json.Unmarshal(line, &req)
+	res := &transformResponse{ID: req.ID, Diagnostics: []interface{}{}}
+	if err != nil {
+		res.Error = fmt.Sprintf("invalid request: %v", err)
+		return res
+	}
+	if req.Path == "" {
+		res.Error = "invalid request: missing path"
+		return res
+	}
+	if req.Contents == nil {
+		res.Error = "invalid request: missing contents"
+		return res
+	}
+	res.Code = *req.Contents
+	fileName, err := filepath.Abs(req.Path)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if !isTransformSource(fileName) {
+		return res
+	}
+
+	sf := reflection.ReflectSourceFile(fileName, *req.Contents)
+	s.files[fileName] = sf
+	s.loadImports(sf)
+
+	options := s.options.compilerOptions(s.rootPath)
+	options.TemplateCache = s.templates
+	compiler := annotations.NewCompiler(s.dependencies(fileName), options)
+	// The diagnostics of the external templates and stylesheets of the file are its own.
+	requested := map[string]bool{fileName: true}
+	for _, resource := range annotations.ResourceFiles(sf) {
+		requested[resource] = true
+	}
+	var diags []*diagnostics.Diagnostic
+	for _, diag := range compiler.Analyze() {
+		if requested[diag.File] {
+			diags = append(diags, diag)
+		}
+	}
+	diagnostics.Sort(diags)
+	res.Diagnostics = diagnostics.JSONDiagnostics(diags, "")
+	if diagnostics.HasErrors(diags) {
+		return res
+	}
+
+	transformed, err := compiler.TransformSource(sf)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Code = transformed.Code
+	res.Map = transformed.SourceMap
+	return res
+}
+
+// dependencies returns the files the compilation of a file depends on, see
+// incremental.Graph.Dependencies.
+func (s *transformService) dependencies(fileName string) []*reflection.SourceFile {
+	names := make([]string, 0, len(s.files))
+	for name := range s.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	files := make([]*reflection.SourceFile, len(names))
+	for i, name := range names {
+		files[i] = s.files[name]
+	}
+	var dependencies []*reflection.SourceFile
+	for _, name := range incremental.NewGraph(files).Dependencies(fileName) {
+		if sf, ok := s.files[name]; ok {
+			dependencies = append(dependencies, sf)
+		}
+	}
+	return dependencies
+}
+
+// loadImports reads the files a source file imports through relative module specifiers which
+// are not known yet, and theirs in turn.
+func (s *transformService) loadImports(sf *reflection.SourceFile) {
+	for _, imp := range sf.Imports {
+		if !strings.HasPrefix(imp.Module, ".") {
+			continue
+		}
+		base := filepath.Join(filepath.Dir(sf.FileName), imp.Module)
+		base = strings.TrimSuffix(strings.TrimSuffix(base, ".js"), ".ts")
+		for _, candidate := range []string{base + ".ts", filepath.Join(base, "index.ts"), base + ".js", filepath.Join(base, "index.js")} {
+			if _, ok := s.files[candidate]; ok {
+				break
+			}
+			data, err := os.ReadFile(candidate)
+			if err != nil {
+				continue
+			}
+			imported := reflection.ReflectSourceFile(candidate, string(data))
+			s.files[candidate] = imported
+			s.loadImports(imported)
+			break
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/incremental"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+// TransformProject compiles a project by rewriting its sources. Every `.ts` and `.js` source
+// file is written to the output directory at the same relative path, with the full definitions
+// assigned to its decorated classes after them and the Angular decorators removed, so that the
+// runtime finds the definitions on the classes when the file is bundled. Rewritten files get a
+// source map, <file>.map, mapping them to the source. Nothing is written when there are errors.
+// With a cache, the files whose inputs are unchanged since it was written are not compiled again.
+// options are as for CompileProject.
+func TransformProject(log io.Writer, rootPath string, outputPath string, options fullOptions, cache *incremental.Cache) ([]*diagnostics.Diagnostic, error) {
+	fmt.Fprintf(log, "🔨 Compiling Angular project at: %s (source transform)\n", rootPath)
+	fmt.Fprintln(log)
+
+	outputDir := resolveOutputDir(rootPath, outputPath)
+	files, err := reflectFiles(rootPath, isTransformSource, outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading sources: %v", err)
+	}
+	fmt.Fprintf(log, "📦 Found %d source file(s)\n", len(files))
+
+	build := planBuild(cache, rootPath, files, options.salt("transform"))
+	build.report(log)
+	compiler := annotations.NewCompiler(build.analyzed, options.compilerOptions(rootPath))
+	compiled := compiler.Analyze()
+	diags := build.diagnostics(compiled)
+	if options.domOnly {
+		reportDomOnly(log, compiler)
+	}
+	if diagnostics.HasErrors(diags) {
+		return diags, nil
+	}
+
+	fmt.Fprintf(log, "📁 Output directory: %s\n", outputDir)
+	fmt.Fprintln(log)
+
+	for _, sf := range files {
+		rel, err := filepath.Rel(rootPath, sf.FileName)
+		if err != nil {
+			rel = filepath.Base(sf.FileName)
+		}
+		outputFile := filepath.Join(outputDir, rel)
+		entry, ok := build.cached(sf)
+		if !ok {
+			res, err := compiler.TransformSource(sf)
+			if err != nil {
+				return diags, fmt.Errorf("error transforming %s: %v", sf.FileName, err)
+			}
+			entry = &incremental.Entry{Code: res.Code, Map: res.SourceMap}
+			build.store(sf, entry, compiled)
+		}
+		if err := writeTransformed(entry, sf, outputFile); err != nil {
+			return diags, err
+		}
+		fmt.Fprintf(log, "   📄 %s\n", outputFile)
+	}
+
+	fmt.Fprintln(log)
+	fmt.Fprintf(log, "✅ Compilation complete: %d file(s) written\n", len(files))
+	return diags, nil
+}
+
+// isTransformSource reports whether a file is rewritten by TransformProject.
+func isTransformSource(path string) bool {
+	switch {
+	case strings.HasSuffix(path, ".d.ts"), strings.HasSuffix(path, ".spec.ts"), strings.HasSuffix(path, ".spec.js"):
+		return false
+	default:
+		return strings.HasSuffix(path, ".ts") || strings.HasSuffix(path, ".js")
+	}
+}
+
+// writeTransformed writes a rewritten source file and, when it has changed, its source map.
+func writeTransformed(res *incremental.Entry, sf *reflection.SourceFile, outputFile string) error {
+	if res.Map == nil {
+		return writeFile(outputFile, res.Code)
+	}
+	sourceMap, err := json.Marshal(res.Map)
+	if err != nil {
+		return fmt.Errorf("error encoding the source map of %s: %v", sf.FileName, err)
+	}
+	if err := writeFile(outputFile+".map", string(sourceMap)); err != nil {
+		return err
+	}
+	code := res.Code
+	if !strings.HasSuffix(code, "\n") {
+		code += "\n"
+	}
+	return writeFile(outputFile, code+"//# sourceMappingURL="+filepath.Base(outputFile)+".map\n")
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck/extended"
+)
+
+// projectConfig is the part of the tsconfig.json of a project which the CLI reads.
+type projectConfig struct {
+	CompilerOptions struct {
+		// OutDir is where tsc writes the project, relative to the tsconfig.json.
+		OutDir string `json:"outDir"`
+	} `json:"compilerOptions"`
+	AngularCompilerOptions struct {
+		StrictTemplates bool `json:"strictTemplates"`
+		// ExtendedDiagnostics configures the extended diagnostics, which run with
+		// StrictTemplates, e.g. `{"checks": {"invalidBananaInBox": "error"}}`.
+		ExtendedDiagnostics *extended.Options `json:"extendedDiagnostics"`
+	} `json:"angularCompilerOptions"`
+}
+
+// readProjectConfig reads the tsconfig.json at the root of a project. A project without one
+// has an empty config.
+func readProjectConfig(rootPath string) (*projectConfig, error) {
+	config := &projectConfig{}
+	data, err := os.ReadFile(filepath.Join(rootPath, "tsconfig.json"))
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(stripJSONComments(string(data))), config); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", filepath.Join(rootPath, "tsconfig.json"), err)
+	}
+	return config, nil
+}
+
+// outDir returns the resolved outDir of the config, or "" when it has none.
+func (c *projectConfig) outDir(rootPath string) string {
+	if c.CompilerOptions.OutDir == "" {
+		return ""
+	}
+	if filepath.IsAbs(c.CompilerOptions.OutDir) {
+		return c.CompilerOptions.OutDir
+	}
+	return filepath.Join(rootPath, c.CompilerOptions.OutDir)
+}
+
+// extendedDiagnostics returns the options of the extended diagnostics, which only run with
+// strictTemplates as in Angular, or nil when they don't. Configuring them without
+// strictTemplates is reported.
+func (c *projectConfig) extendedDiagnostics(rootPath string) (*extended.Options, []*diagnostics.Diagnostic) {
+	options := c.AngularCompilerOptions.ExtendedDiagnostics
+	if !c.AngularCompilerOptions.StrictTemplates {
+		if options == nil {
+			return nil, nil
+		}
+		return nil, []*diagnostics.Diagnostic{diagnostics.MakeFileDiagnostic(
+			diagnostics.ConfigExtendedDiagnosticsImpliesStrictTemplates, diagnostics.CategoryError,
+			filepath.Join(rootPath, "tsconfig.json"),
+			`Angular compiler option "extendedDiagnostics" is configured, however "strictTemplates" is not enabled. This is not supported, since extended diagnostics require "strictTemplates".
+
+One of the following actions is required:
+1. Enable "strictTemplates".
+2. Remove "extendedDiagnostics" configuration to disable them.`)}
+	}
+	if options == nil {
+		options = &extended.Options{}
+	}
+	return options, nil
+}
+
+// stripJSONComments turns the JSON with comments and trailing commas of tsconfig files into
+// JSON. Strings are copied as they are.
+func stripJSONComments(text string) string {
+	var out []byte
+	// comma is the offset in out of a comma which is dropped if only whitespace follows it
+	// before the end of an object or array, or -1.
+	comma := -1
+	for i := 0; i < len(text); i++ {
+		switch ch := text[i]; {
+		case ch == '"':
+			start := i
+			for i++; i < len(text) && text[i] != '"'; i++ {
+				if text[i] == '\\' {
+					i++
+				}
+			}
+			out = append(out, text[start:min(i+1, len(text))]...)
+			comma = -1
+		case ch == '/' && strings.HasPrefix(text[i:], "//"):
+			for i+1 < len(text) && text[i+1] != '\n' {
+				i++
+			}
+		case ch == '/' && strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				i = len(text)
+				continue
+			}
+			i += end + 3
+		case ch == '}' || ch == ']':
+			if comma >= 0 {
+				out = append(out[:comma], out[comma+1:]...)
+			}
+			out = append(out, ch)
+			comma = -1
+		case ch == ',':
+			comma = len(out)
+			out = append(out, ch)
+		default:
+			if !strings.ContainsRune(" \t\r\n", rune(ch)) {
+				comma = -1
+			}
+			out = append(out, ch)
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+)
+
+// runWatch runs `ngc-go watch` and returns the exit code. It only returns on usage errors or
+// when the HMR endpoint cannot be started.
+func runWatch(args []string) int {
+	fs := newFlagSet("watch")
+	modeFlag := fs.String("compilation-mode", string(annotations.CompilationModeFull),
+		"compilation mode: full or partial")
+	hmrFlag := fs.Bool("hmr", false, "emit HMR initializers and serve component updates")
+	hmrAddrFlag := fs.String("hmr-addr", defaultHmrAddr, "address of the HMR endpoint")
+	intervalFlag := fs.Duration("poll-interval", 300*time.Millisecond, "interval between checks for changed files")
+	noCacheFlag := fs.Bool("no-cache", false, "compile every file")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsageError
+	}
+	mode, err := annotations.ParseCompilationMode(*modeFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "watch error: %v\n", err)
+		return exitUsageError
+	}
+	if *hmrFlag && mode != annotations.CompilationModeFull {
+		fmt.Fprintf(os.Stderr, "watch error: --hmr requires --compilation-mode=full\n")
+		return exitUsageError
+	}
+
+	path := "."
+	outputPath := ""
+	if len(positional) >= 1 {
+		path = positional[0]
+	}
+	if len(positional) >= 2 {
+		outputPath = positional[1]
+	}
+	outputDir := resolveOutputDir(path, outputPath)
+	cache, err := openCache(path, *noCacheFlag, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "watch error: %v\n", err)
+		return exitErrors
+	}
+
+	build := func() {
+		diags, err := compile(os.Stdout, path, outputPath, mode, fullOptions{}, cache)
+		reportWatchDiagnostics(diags, err)
+	}
+	if *hmrFlag {
+		builder := newHmrBuilder(path, outputDir)
+		if err := builder.server.listen(*hmrAddrFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "watch error: %v\n", err)
+			return exitErrors
+		}
+		fmt.Printf("🔥 HMR endpoint listening on http://%s (events: %s, updates: %s)\n",
+			builder.server.addr, hmrEventsPath, hmrComponentPath)
+		build = builder.build
+	}
+
+	build()
+	snapshot := watchSnapshot(path, outputDir)
+	fmt.Println("👀 Watching for changes...")
+	for {
+		time.Sleep(*intervalFlag)
+		next := watchSnapshot(path, outputDir)
+		changed := changedFiles(snapshot, next)
+		if len(changed) == 0 {
+			continue
+		}
+		snapshot = next
+		fmt.Println("")
+		for _, file := range changed {
+			fmt.Printf("🔄 Changed: %s\n", file)
+		}
+		build()
+	}
+}
+
+// reportWatchDiagnostics prints the result of a build. Watching continues after errors.
+func reportWatchDiagnostics(diags []*diagnostics.Diagnostic, err error) {
+	if reportErr := reportDiagnostics(os.Stdout, diags, diagnostics.FormatText); reportErr != nil {
+		fmt.Fprintf(os.Stderr, "watch error: %v\n", reportErr)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "watch error: %v\n", err)
+	}
+}
+
+// fileStamp is what a change of a watched file is detected from.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// watchSnapshot stamps the files of a project, skipping dependencies, build output and version
+// control.
+func watchSnapshot(rootPath string, outputDir string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	outputDir = filepath.Clean(outputDir)
+	filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if name == "node_modules" || name == "dist" || (name != "." && strings.HasPrefix(name, ".")) ||
+				filepath.Clean(path) == outputDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return stamps
+}
+
+// changedFiles returns the files added, modified or removed between two snapshots.
+func changedFiles(before, after map[string]fileStamp) []string {
+	var changed []string
+	for path, stamp := range after {
+		if prev, ok := before[path]; !ok || prev != stamp {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
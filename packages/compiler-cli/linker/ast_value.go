@@ -0,0 +1,358 @@
+package linker
+
+import (
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/output"
+)
+
+// ast is an expression of the linked source. Its offsets are relative to the whole source.
+type ast = reflection.Expression
+
+// parseAst parses source[start:end] as an expression.
+func parseAst(source string, start, end int) *ast {
+	expr := reflection.ParseExpression(source[start:end])
+	shiftAst(expr, start)
+	return expr
+}
+
+// shiftAst moves the offsets of an expression parsed from a part of the source by delta.
+func shiftAst(expr *ast, delta int) {
+	if expr == nil {
+		return
+	}
+	expr.Start += delta
+	expr.End += delta
+	for _, element := range expr.Elements {
+		shiftAst(element, delta)
+	}
+	for _, prop := range expr.Properties {
+		prop.Start += delta
+		prop.End += delta
+		shiftAst(prop.Value, delta)
+	}
+	shiftAst(expr.Callee, delta)
+}
+
+// astObject is an object literal of a partial declaration, with accessors that stop linking
+// when a property is missing or doesn't have the expected type.
+type astObject struct {
+	expr *ast
+}
+
+// has reports whether the object has the given property.
+func (o astObject) has(name string) bool {
+	return o.expr.Property(name) != nil
+}
+
+// getValue returns the value of a property which must be present.
+func (o astObject) getValue(name string) astValue {
+	value := o.expr.Property(name)
+	if value == nil {
+		fatal(o.expr, "Expected property '"+name+"' to be present.")
+	}
+	return astValue{value}
+}
+
+func (o astObject) getString(name string) string {
+	return o.getValue(name).getString()
+}
+
+func (o astObject) getBoolean(name string) bool {
+	return o.getValue(name).getBoolean()
+}
+
+func (o astObject) getArray(name string) []astValue {
+	return o.getValue(name).getArray()
+}
+
+func (o astObject) getObject(name string) astObject {
+	return o.getValue(name).getObject()
+}
+
+func (o astObject) getOpaque(name string) output.OutputExpression {
+	return o.getValue(name).getOpaque()
+}
+
+// optionalBoolean returns the value of a boolean property, or defaultValue when it is absent.
+func (o astObject) optionalBoolean(name string, defaultValue bool) bool {
+	if !o.has(name) {
+		return defaultValue
+	}
+	return o.getBoolean(name)
+}
+
+// optionalOpaque returns the value of a property, or nil when it is absent.
+func (o astObject) optionalOpaque(name string) *output.OutputExpression {
+	if !o.has(name) {
+		return nil
+	}
+	value := o.getOpaque(name)
+	return &value
+}
+
+// optionalStrings returns the values of a property holding an array of strings, or nil when it
+// is absent.
+func (o astObject) optionalStrings(name string) []string {
+	if !o.has(name) {
+		return nil
+	}
+	values := []string{}
+	for _, value := range o.getArray(name) {
+		values = append(values, value.getString())
+	}
+	return values
+}
+
+// toLiteral maps the properties of an object literal.
+func toLiteral[T any](o astObject, mapper func(value astValue, key string) T) map[string]T {
+	result := make(map[string]T)
+	for _, prop := range o.expr.Properties {
+		if prop.Name == "" {
+			fatal(prop.Value, "Unsupported syntax, expected a property assignment.")
+		}
+		result[prop.Name] = mapper(astValue{prop.Value}, prop.Name)
+	}
+	return result
+}
+
+// astValue is a value of a partial declaration.
+type astValue struct {
+	expr *ast
+}
+
+func (v astValue) isNull() bool {
+	return v.expr.Kind == reflection.ExpressionNull
+}
+
+func (v astValue) isString() bool {
+	return v.expr.Kind == reflection.ExpressionString
+}
+
+func (v astValue) getString() string {
+	value, ok := v.expr.StringValue()
+	if !ok {
+		fatal(v.expr, "Unsupported syntax, expected a string literal.")
+	}
+	return value
+}
+
+func (v astValue) getBoolean() bool {
+	value, ok := v.expr.BoolValue()
+	if !ok {
+		fatal(v.expr, "Unsupported syntax, expected a boolean literal.")
+	}
+	return value
+}
+
+func (v astValue) isArray() bool {
+	return v.expr.Kind == reflection.ExpressionArray
+}
+
+func (v astValue) getArray() []astValue {
+	if !v.isArray() {
+		fatal(v.expr, "Unsupported syntax, expected an array literal.")
+	}
+	values := make([]astValue, len(v.expr.Elements))
+	for i, element := range v.expr.Elements {
+		values[i] = astValue{element}
+	}
+	return values
+}
+
+func (v astValue) isObject() bool {
+	return v.expr.Kind == reflection.ExpressionObject
+}
+
+func (v astValue) getObject() astObject {
+	if !v.isObject() {
+		fatal(v.expr, "Unsupported syntax, expected an object literal.")
+	}
+	return astObject{v.expr}
+}
+
+func (v astValue) isCallExpression() bool {
+	return v.expr.Kind == reflection.ExpressionCall
+}
+
+func (v astValue) getCallee() astValue {
+	if !v.isCallExpression() {
+		fatal(v.expr, "Unsupported syntax, expected a call expression.")
+	}
+	return astValue{v.expr.Callee}
+}
+
+func (v astValue) getArguments() []astValue {
+	if !v.isCallExpression() {
+		fatal(v.expr, "Unsupported syntax, expected a call expression.")
+	}
+	args := make([]astValue, len(v.expr.Elements))
+	for i, arg := range v.expr.Elements {
+		args[i] = astValue{arg}
+	}
+	return args
+}
+
+// getSymbolName returns the name of an identifier, or the last property name of a property
+// chain, e.g. `None` for `i0.ViewEncapsulation.None`.
+func (v astValue) getSymbolName() (string, bool) {
+	if v.expr.Kind != reflection.ExpressionIdentifier {
+		return "", false
+	}
+	return v.expr.Value[strings.LastIndex(v.expr.Value, ".")+1:], true
+}
+
+// getOpaque returns the value as an expression which is emitted verbatim.
+func (v astValue) getOpaque() output.OutputExpression {
+	return output.NewWrappedNodeExpr(v.expr.Text, nil, nil)
+}
+
+func (v astValue) isFunction() bool {
+	_, ok := parseFunction(v.expr)
+	return ok
+}
+
+// getFunctionReturnValue returns the value returned by a function whose body is a single
+// return statement, e.g. `function () { return [A, B]; }` or `() => [A, B]`.
+func (v astValue) getFunctionReturnValue() astValue {
+	fn, ok := parseFunction(v.expr)
+	if !ok {
+		fatal(v.expr, "Unsupported syntax, expected a function.")
+	}
+	if fn.returnValue == nil {
+		fatal(v.expr, "Unsupported syntax, expected a function body with a single return statement.")
+	}
+	return astValue{fn.returnValue}
+}
+
+// getFunctionParameters returns the names of the parameters of a function.
+func (v astValue) getFunctionParameters() []string {
+	fn, ok := parseFunction(v.expr)
+	if !ok {
+		fatal(v.expr, "Unsupported syntax, expected a function.")
+	}
+	return fn.parameters
+}
+
+// function is a function expression or arrow function of the source.
+type function struct {
+	parameters []string
+	// returnValue is the returned expression when the body is a single return statement or an
+	// expression, nil otherwise.
+	returnValue *ast
+}
+
+// parseFunction recognizes `function (a, b) { return x; }`, `(a, b) => x`, `a => x` and arrow
+// functions with block bodies.
+func parseFunction(expr *ast) (*function, bool) {
+	if expr.Kind != reflection.ExpressionOther || expr.Text == "" {
+		return nil, false
+	}
+	text := expr.Text
+	tokens := reflection.Tokenize(text)
+	fn := &function{}
+
+	i := 0
+	isArrow := true
+	if tokens[i].Is("function") {
+		isArrow = false
+		i++
+		if tokens[i].Kind == reflection.TokenIdentifier {
+			i++
+		}
+	}
+	switch {
+	case tokens[i].Is("("):
+		closing := matchingBracket(tokens, i)
+		if closing < 0 {
+			return nil, false
+		}
+		fn.parameters = parameterNames(tokens[i+1 : closing])
+		i = closing + 1
+	case isArrow && tokens[i].Kind == reflection.TokenIdentifier:
+		fn.parameters = []string{tokens[i].Text}
+		i++
+	default:
+		return nil, false
+	}
+	if isArrow {
+		if !tokens[i].Is("=>") {
+			return nil, false
+		}
+		i++
+	}
+
+	if !tokens[i].Is("{") {
+		if !isArrow || tokens[i].Kind == reflection.TokenEOF {
+			return nil, false
+		}
+		fn.returnValue = parseAst(text, tokens[i].Start, len(text))
+		shiftAst(fn.returnValue, expr.Start)
+		return fn, true
+	}
+	closing := matchingBracket(tokens, i)
+	if closing < 0 || tokens[closing+1].Kind != reflection.TokenEOF {
+		return nil, false
+	}
+	if !tokens[i+1].Is("return") || i+2 >= closing {
+		return fn, true
+	}
+	end := closing
+	if tokens[end-1].Is(";") {
+		end--
+	}
+	returnValue := parseAst(text, tokens[i+2].Start, tokens[end-1].End)
+	if returnValue.End != tokens[end-1].End {
+		// The body has more statements than the return statement.
+		return fn, true
+	}
+	shiftAst(returnValue, expr.Start)
+	fn.returnValue = returnValue
+	return fn, true
+}
+
+// parameterNames returns the names of simple parameters, ignoring default values.
+func parameterNames(tokens []reflection.Token) []string {
+	names := []string{}
+	expectName := true
+	depth := 0
+	for _, tok := range tokens {
+		switch {
+		case tok.Is("(") || tok.Is("[") || tok.Is("{"):
+			depth++
+		case tok.Is(")") || tok.Is("]") || tok.Is("}"):
+			depth--
+		case depth == 0 && tok.Is(","):
+			expectName = true
+		case depth == 0 && expectName && tok.Kind == reflection.TokenIdentifier:
+			names = append(names, tok.Text)
+			expectName = false
+		}
+	}
+	return names
+}
+
+// matchingBracket returns the index of the bracket closing the one at i, or -1.
+func matchingBracket(tokens []reflection.Token, i int) int {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.Kind == reflection.TokenEOF {
+			return -1
+		}
+		if tok.Kind != reflection.TokenPunctuation {
+			continue
+		}
+		switch tok.Text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,101 @@
+package linker
+
+import (
+	"strings"
+
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+// angularCore is the only module linked definitions may import from.
+const angularCore = "@angular/core"
+
+// emitScope translates the definitions compiled for the declarations of a file. Declarations
+// whose `ngImport` is imported at the top level of the file share a scope, and the constants
+// of their definitions are hoisted below the imports. Other declarations get a local scope whose
+// constants are emitted together with the definition.
+type emitScope struct {
+	ngImport     string
+	constantPool *constant.ConstantPool
+	local        bool
+}
+
+func newEmitScope(ngImport string, local bool) *emitScope {
+	return &emitScope{
+		ngImport:     ngImport,
+		constantPool: constant.NewConstantPool(false),
+		local:        local,
+	}
+}
+
+// translateDefinition emits a definition. Statements of the definition, and the constants of
+// a local scope, are wrapped along with it in an immediately invoked function.
+func (s *emitScope) translateDefinition(definition render3.R3CompiledExpression) string {
+	var statements []output.OutputStatement
+	if s.local {
+		statements = append(statements, s.constantPool.GetStatements()...)
+	}
+	statements = append(statements, definition.Statements...)
+	if len(statements) == 0 {
+		return s.emitExpression(definition.Expression)
+	}
+	statements = append(statements, output.NewReturnStatement(definition.Expression, nil, nil))
+	iife := output.NewInvokeFunctionExpr(
+		output.NewArrowFunctionExpr([]*output.FnParam{}, statements, nil, nil),
+		[]output.OutputExpression{},
+		nil,
+		nil,
+		false,
+	)
+	return s.emitExpression(iife)
+}
+
+// constantStatements emits the constants hoisted to the top level of the file.
+func (s *emitScope) constantStatements() string {
+	statements := s.constantPool.GetStatements()
+	if s.local || len(statements) == 0 {
+		return ""
+	}
+	e := newEmitter(s.ngImport)
+	ctx := output.CreateRootEmitterVisitorContext()
+	e.VisitAllStatements(statements, ctx)
+	return strings.TrimRight(ctx.ToSource(), "\n")
+}
+
+func (s *emitScope) emitExpression(expr output.OutputExpression) string {
+	e := newEmitter(s.ngImport)
+	ctx := output.CreateRootEmitterVisitorContext()
+	expr.VisitExpression(e, ctx)
+	return ctx.ToSource()
+}
+
+// emitter prints linked code. Symbols of `@angular/core` are read from the `ngImport` of the
+// declaration, as the linked file can't be given new imports.
+type emitter struct {
+	*output.JsEmitterVisitor
+	ngImport string
+}
+
+func newEmitter(ngImport string) *emitter {
+	e := &emitter{JsEmitterVisitor: output.NewJsEmitterVisitor(), ngImport: ngImport}
+	e.SetVisitor(e)
+	return e
+}
+
+// VisitExternalExpr prints `<ngImport>.<name>` for symbols of `@angular/core`.
+func (e *emitter) VisitExternalExpr(ast *output.ExternalExpr, context interface{}) interface{} {
+	ctx := context.(*output.EmitterVisitorContext)
+	if ast.Value.ModuleName != nil {
+		if *ast.Value.ModuleName != angularCore {
+			panic(&FatalLinkerError{Message: "Unable to import from anything other than '" + angularCore + "'"})
+		}
+		if ast.Value.Name == nil {
+			ctx.Print(ast, e.ngImport, false)
+			return nil
+		}
+		ctx.Print(ast, e.ngImport+".", false)
+	}
+	ctx.Print(ast, *ast.Value.Name, false)
+	return nil
+}
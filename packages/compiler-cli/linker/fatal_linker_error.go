@@ -0,0 +1,33 @@
+// Package linker turns the partial declarations (`ɵɵngDeclare*()` calls) emitted by libraries
+// compiled in partial compilation mode into full definitions, e.g. `ɵɵngDeclareComponent()` into
+// `ɵɵdefineComponent()`. This happens when an application is built, so that libraries don't
+// depend on the version of the compiler they were published with.
+//
+// Declarations are found by tokenizing the JavaScript source, their metadata is read from the
+// object literal passed to them and the definitions compiled from it replace the calls in place.
+package linker
+
+import "errors"
+
+// FatalLinkerError is an error which stops linking of a file. Start and End are the offsets of
+// the offending expression in the linked source.
+type FatalLinkerError struct {
+	Start   int
+	End     int
+	Message string
+}
+
+func (e *FatalLinkerError) Error() string {
+	return e.Message
+}
+
+// IsFatalLinkerError reports whether err is a FatalLinkerError.
+func IsFatalLinkerError(err error) bool {
+	var fatal *FatalLinkerError
+	return errors.As(err, &fatal)
+}
+
+// fatal stops linking with an error about expr. It is recovered by the FileLinker.
+func fatal(expr *ast, message string) {
+	panic(&FatalLinkerError{Start: expr.Start, End: expr.End, Message: message})
+}
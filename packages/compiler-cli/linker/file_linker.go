@@ -0,0 +1,241 @@
+package linker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// UnknownDeclarationVersionHandling is what the linker does with declarations published with a
+// version of Angular newer than its own.
+type UnknownDeclarationVersionHandling int
+
+const (
+	// UnknownDeclarationVersionError fails linking.
+	UnknownDeclarationVersionError UnknownDeclarationVersionHandling = iota
+	// UnknownDeclarationVersionWarn adds a warning and links the declaration with the latest
+	// linker.
+	UnknownDeclarationVersionWarn
+	// UnknownDeclarationVersionIgnore silently links the declaration with the latest linker.
+	UnknownDeclarationVersionIgnore
+)
+
+// Options configures the linker.
+type Options struct {
+	// JitMode links NgModules with their selector scope, which components compiled just in time
+	// need to find their dependencies.
+	JitMode bool
+
+	// UnknownDeclarationVersionHandling is what to do with declarations which require a newer
+	// version of the linker. Defaults to failing.
+	UnknownDeclarationVersionHandling UnknownDeclarationVersionHandling
+}
+
+// NeedsLinking reports whether a source may contain partial declarations. It is a quick check
+// which avoids parsing the sources of the many files that don't.
+func NeedsLinking(code string) bool {
+	return strings.Contains(code, "ɵɵngDeclare")
+}
+
+// LinkFile links the partial declarations of a JavaScript source. See FileLinker.
+func LinkFile(sourceUrl string, code string, options Options) (string, error) {
+	return NewFileLinker(sourceUrl, code, options).Link()
+}
+
+// FileLinker links the partial declarations of a JavaScript source.
+type FileLinker struct {
+	sourceUrl string
+	code      string
+	options   Options
+
+	parseFile  *util.ParseSourceFile
+	lineStarts []int
+	selector   *partialLinkerSelector
+
+	// fileScope holds the constants hoisted to the top level of the file.
+	fileScope *emitScope
+	// imports are the top-level imports of the file, reflected on first use.
+	imports []*reflection.Import
+
+	// Warnings are the warnings reported while linking, e.g. about declarations published with a
+	// newer version of Angular when UnknownDeclarationVersionWarn is configured.
+	Warnings []string
+}
+
+// NewFileLinker creates a linker for the given source. sourceUrl is used in source spans, e.g.
+// of template parse errors.
+func NewFileLinker(sourceUrl string, code string, options Options) *FileLinker {
+	l := &FileLinker{
+		sourceUrl:  sourceUrl,
+		code:       code,
+		options:    options,
+		parseFile:  util.NewParseSourceFile(code, sourceUrl),
+		lineStarts: []int{0},
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] == '\n' {
+			l.lineStarts = append(l.lineStarts, i+1)
+		}
+	}
+	l.selector = newPartialLinkerSelector(l)
+	return l
+}
+
+// edit replaces code[start:end] with text.
+type edit struct {
+	start, end int
+	text       string
+}
+
+// Link replaces the partial declarations of the source with the definitions compiled from them
+// and returns the linked source. Sources without declarations are returned unchanged. Errors
+// are *FatalLinkerError when they relate to an expression of the source.
+func (l *FileLinker) Link() (string, error) {
+	if !NeedsLinking(l.code) {
+		return l.code, nil
+	}
+
+	tokens := reflection.Tokenize(l.code)
+	var edits []edit
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.Kind != reflection.TokenIdentifier || !declarationFunctions[tok.Text] || !tokens[i+1].Is("(") {
+			continue
+		}
+		// Skip the declarations of the functions themselves, i.e. when linking `@angular/core`.
+		if i > 0 && tokens[i-1].Is("function") {
+			continue
+		}
+		closing := matchingBracket(tokens, i+1)
+		if closing < 0 {
+			continue
+		}
+		start := i
+		for start >= 2 && tokens[start-1].Is(".") && tokens[start-2].Kind == reflection.TokenIdentifier {
+			start -= 2
+		}
+		call := parseAst(l.code, tokens[start].Start, tokens[closing].End)
+		text, err := l.linkPartialDeclaration(tok.Text, call)
+		if err != nil {
+			return "", err
+		}
+		edits = append(edits, edit{start: call.Start, end: call.End, text: text})
+		i = closing
+	}
+
+	if l.fileScope != nil {
+		if constants := l.fileScope.constantStatements(); constants != "" {
+			// Hoisted constants go after the last import, or first when there are none.
+			offset := 0
+			for _, imp := range l.topLevelImports() {
+				offset = max(offset, imp.End)
+			}
+			if offset == 0 {
+				constants += "\n"
+			} else {
+				constants = "\n" + constants
+			}
+			edits = append(edits, edit{start: offset, end: offset, text: constants})
+			sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+		}
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, e := range edits {
+		b.WriteString(l.code[last:e.start])
+		b.WriteString(e.text)
+		last = e.end
+	}
+	b.WriteString(l.code[last:])
+	return b.String(), nil
+}
+
+// linkPartialDeclaration compiles a declaration call into the source of its definition.
+func (l *FileLinker) linkPartialDeclaration(declarationFn string, call *ast) (linked string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			fatalErr, ok := r.(*FatalLinkerError)
+			if !ok {
+				panic(r)
+			}
+			if fatalErr.Start == 0 && fatalErr.End == 0 {
+				fatalErr.Start, fatalErr.End = call.Start, call.End
+			}
+			err = fatalErr
+		}
+	}()
+
+	if call.Kind != reflection.ExpressionCall || len(call.Elements) != 1 {
+		fatal(call, fmt.Sprintf("Invalid function call: It should have only a single object literal argument, but contained %d.", len(call.Elements)))
+	}
+	metaObj := astValue{call.Elements[0]}.getObject()
+	ngImport := metaObj.getValue("ngImport").expr
+	scope := l.getEmitScope(ngImport)
+
+	minVersion := metaObj.getString("minVersion")
+	version := metaObj.getString("version")
+	linker, err := l.selector.getLinker(declarationFn, minVersion, version)
+	if err != nil {
+		fatal(call, err.Error())
+	}
+	definition := linker.linkPartialDeclaration(scope.constantPool, metaObj, version)
+	return scope.translateDefinition(definition), nil
+}
+
+// getEmitScope returns the scope of a declaration. Declarations share the file scope when their
+// `ngImport` is imported at the top level, so that hoisted constants can refer to it.
+func (l *FileLinker) getEmitScope(ngImport *ast) *emitScope {
+	if ngImport.Kind != reflection.ExpressionIdentifier || strings.Contains(ngImport.Value, ".") ||
+		!l.isTopLevelImport(ngImport.Value) {
+		return newEmitScope(ngImport.Text, true)
+	}
+	if l.fileScope == nil {
+		l.fileScope = newEmitScope(ngImport.Text, false)
+	}
+	return l.fileScope
+}
+
+func (l *FileLinker) isTopLevelImport(name string) bool {
+	for _, imp := range l.topLevelImports() {
+		if imp.NamespaceName == name || imp.DefaultName == name {
+			return true
+		}
+		for _, spec := range imp.Specifiers {
+			if spec.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (l *FileLinker) topLevelImports() []*reflection.Import {
+	if l.imports == nil {
+		l.imports = reflection.ReflectSourceFile(l.sourceUrl, l.code).Imports
+		if l.imports == nil {
+			l.imports = []*reflection.Import{}
+		}
+	}
+	return l.imports
+}
+
+// span returns the source span of code[start:end].
+func (l *FileLinker) span(start, end int) *util.ParseSourceSpan {
+	return util.NewParseSourceSpan(l.location(start), l.location(end), nil, nil)
+}
+
+func (l *FileLinker) location(offset int) *util.ParseLocation {
+	line := sort.Search(len(l.lineStarts), func(i int) bool { return l.lineStarts[i] > offset }) - 1
+	return util.NewParseLocation(l.parseFile, offset, line, offset-l.lineStarts[line])
+}
+
+// Position returns the zero-based line and column of an offset of the source, e.g. of a
+// FatalLinkerError.
+func (l *FileLinker) Position(offset int) (line int, col int) {
+	loc := l.location(offset)
+	return loc.Line, loc.Col
+}
@@ -0,0 +1,51 @@
+package linker
+
+import (
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/partial"
+	render3_class_metadata_compiler "ngc-go/packages/compiler/src/render3/r3_class_metadata_compiler"
+)
+
+// partialClassMetadataLinker links `ɵɵngDeclareClassMetadata()` into a dev-mode only
+// `ɵsetClassMetadata()` call.
+type partialClassMetadataLinker struct{}
+
+func (p *partialClassMetadataLinker) linkPartialDeclaration(_ *constant.ConstantPool, metaObj astObject, _ string) render3.R3CompiledExpression {
+	return render3.R3CompiledExpression{
+		Expression: render3_class_metadata_compiler.CompileClassMetadata(toR3ClassMetadata(metaObj, metaObj.getOpaque("type"))),
+		Statements: []output.OutputStatement{},
+	}
+}
+
+// toR3ClassMetadata reads the decorators of a class from the object declaring them.
+func toR3ClassMetadata(obj astObject, typ output.OutputExpression) partial.R3ClassMetadata {
+	return partial.R3ClassMetadata{
+		Type:           typ,
+		Decorators:     obj.getOpaque("decorators"),
+		CtorParameters: obj.optionalOpaque("ctorParameters"),
+		PropDecorators: obj.optionalOpaque("propDecorators"),
+	}
+}
+
+// partialClassMetadataAsyncLinker links `ɵɵngDeclareClassMetadataAsync()`, declared by
+// components with deferred dependencies, into a dev-mode only `ɵsetClassMetadataAsync()` call.
+type partialClassMetadataAsyncLinker struct{}
+
+func (p *partialClassMetadataAsyncLinker) linkPartialDeclaration(_ *constant.ConstantPool, metaObj astObject, _ string) render3.R3CompiledExpression {
+	resolveMetadata := metaObj.getValue("resolveMetadata")
+	if !resolveMetadata.isFunction() {
+		fatal(resolveMetadata.expr, "Unsupported `resolveMetadata` value. Expected a function.")
+	}
+
+	dependencyResolverFunction := metaObj.getOpaque("resolveDeferredDeps")
+	deferredSymbolNames := resolveMetadata.getFunctionParameters()
+	returnValue := resolveMetadata.getFunctionReturnValue().getObject()
+	metadata := toR3ClassMetadata(returnValue, metaObj.getOpaque("type"))
+
+	return render3.R3CompiledExpression{
+		Expression: render3_class_metadata_compiler.CompileSetClassMetadataAsync(metadata, dependencyResolverFunction, deferredSymbolNames),
+		Statements: []output.OutputStatement{},
+	}
+}
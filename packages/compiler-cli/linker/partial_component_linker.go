@@ -0,0 +1,218 @@
+package linker
+
+import (
+	"strings"
+
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/core"
+	"ngc-go/packages/compiler/src/ml_parser"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/view"
+	view_compiler "ngc-go/packages/compiler/src/render3/view/compiler"
+)
+
+// partialComponentLinker links `ɵɵngDeclareComponent()` into `ɵɵdefineComponent()`. The
+// template is parsed from the linked source, so that source spans point into it.
+type partialComponentLinker struct {
+	file *FileLinker
+}
+
+func (p *partialComponentLinker) linkPartialDeclaration(constantPool *constant.ConstantPool, metaObj astObject, version string) render3.R3CompiledExpression {
+	meta := p.toR3ComponentMeta(metaObj, version)
+	return view_compiler.CompileComponentFromMetadata(meta, constantPool, *view.MakeBindingParser(false))
+}
+
+func (p *partialComponentLinker) toR3ComponentMeta(metaObj astObject, version string) *view.R3ComponentMetadata {
+	templateValue := metaObj.getValue("template")
+	isInline := metaObj.optionalBoolean("isInline", false)
+	template := p.parseTemplate(templateValue, metaObj.optionalBoolean("preserveWhitespaces", false), isInline)
+
+	declarationListEmitMode := view.DeclarationListEmitModeDirect
+	extractDeclarationTypeExpr := func(typ astValue) output.OutputExpression {
+		ref := extractForwardRef(typ)
+		if ref.ForwardRef == render3.ForwardRefHandlingUnwrapped {
+			declarationListEmitMode = view.DeclarationListEmitModeClosure
+		}
+		return ref.Expression
+	}
+
+	var declarations []view.R3TemplateDependencyMetadata
+	// Components, directives and pipes are listed separately by declarations of versions older
+	// than v14, which didn't have `dependencies`.
+	if metaObj.has("components") {
+		for _, component := range metaObj.getArray("components") {
+			obj := component.getObject()
+			declarations = append(declarations, makeDirectiveMetadata(obj, extractDeclarationTypeExpr(obj.getValue("type")), true))
+		}
+	}
+	if metaObj.has("directives") {
+		for _, directive := range metaObj.getArray("directives") {
+			obj := directive.getObject()
+			declarations = append(declarations, makeDirectiveMetadata(obj, extractDeclarationTypeExpr(obj.getValue("type")), false))
+		}
+	}
+	if metaObj.has("pipes") {
+		pipes := metaObj.getObject("pipes")
+		for _, prop := range pipes.expr.Properties {
+			declarations = append(declarations, &view.R3PipeDependencyMetadata{
+				R3TemplateDependency: view.R3TemplateDependency{
+					Kind: view.R3TemplateDependencyKindPipe,
+					Type: extractDeclarationTypeExpr(astValue{prop.Value}),
+				},
+				Name: prop.Name,
+			})
+		}
+	}
+
+	baseMeta := toR3DirectiveMeta(metaObj, p.file, version)
+
+	hasDirectiveDependencies := false
+	if metaObj.has("dependencies") {
+		for _, dep := range metaObj.getArray("dependencies") {
+			depObj := dep.getObject()
+			typeExpr := extractDeclarationTypeExpr(depObj.getValue("type"))
+			switch depObj.getString("kind") {
+			case "directive", "component":
+				hasDirectiveDependencies = true
+				declarations = append(declarations, makeDirectiveMetadata(depObj, typeExpr, false))
+			case "pipe":
+				declarations = append(declarations, &view.R3PipeDependencyMetadata{
+					R3TemplateDependency: view.R3TemplateDependency{Kind: view.R3TemplateDependencyKindPipe, Type: typeExpr},
+					Name:                 depObj.getString("name"),
+				})
+			case "ngmodule":
+				declarations = append(declarations, &view.R3NgModuleDependencyMetadata{
+					R3TemplateDependency: view.R3TemplateDependency{Kind: view.R3TemplateDependencyKindNgModule, Type: typeExpr},
+				})
+			}
+		}
+	}
+
+	meta := &view.R3ComponentMetadata{
+		R3DirectiveMetadata: *baseMeta,
+		Template: view.R3ComponentTemplateMetadata{
+			Nodes:              template.Nodes,
+			NgContentSelectors: template.NgContentSelectors,
+		},
+		Declarations:             declarations,
+		Defer:                    createR3ComponentDeferMetadata(metaObj, template.Nodes),
+		DeclarationListEmitMode:  declarationListEmitMode,
+		Styles:                   []string{},
+		Encapsulation:            core.ViewEncapsulationEmulated,
+		ChangeDetection:          core.ChangeDetectionStrategyDefault,
+		Animations:               metaObj.optionalOpaque("animations"),
+		ViewProviders:            metaObj.optionalOpaque("viewProviders"),
+		RelativeContextFilePath:  p.file.sourceUrl,
+		I18nUseExternalIds:       false,
+		HasDirectiveDependencies: !baseMeta.IsStandalone || hasDirectiveDependencies,
+	}
+	if metaObj.has("styles") {
+		for _, style := range metaObj.getArray("styles") {
+			meta.Styles = append(meta.Styles, style.getString())
+		}
+	}
+	if metaObj.has("encapsulation") {
+		meta.Encapsulation = parseEncapsulation(metaObj.getValue("encapsulation"))
+	}
+	if metaObj.has("changeDetection") {
+		meta.ChangeDetection = parseChangeDetectionStrategy(metaObj.getValue("changeDetection"))
+	}
+	return meta
+}
+
+// parseTemplate parses the template from the string literal in the linked source, which keeps
+// the source spans of the template pointing at the right place.
+func (p *partialComponentLinker) parseTemplate(templateValue astValue, preserveWhitespaces bool, isInline bool) *view.ParsedTemplate {
+	expr := templateValue.expr
+	code := p.file.code
+	if expr.Start >= len(code) || !strings.ContainsRune("\"'`", rune(code[expr.Start])) {
+		fatal(expr, "Expected the template string to start with a quote but got: "+expr.Text)
+	}
+	start := p.file.location(expr.Start)
+	escapedString := true
+	enableI18nLegacyMessageIdFormat := false
+	template := view.ParseTemplate(code, p.file.sourceUrl, &view.ParseTemplateOptions{
+		EscapedString:                   &escapedString,
+		Range:                           &ml_parser.LexerRange{StartPos: expr.Start + 1, EndPos: expr.End - 1, StartLine: start.Line, StartCol: start.Col + 1},
+		EnableI18nLegacyMessageIdFormat: &enableI18nLegacyMessageIdFormat,
+		PreserveWhitespaces:             &preserveWhitespaces,
+		// We normalize line endings if the template was inline.
+		I18nNormalizeLineEndingsInICUs: &isInline,
+	})
+	if len(template.Errors) > 0 {
+		messages := make([]string, len(template.Errors))
+		for i, err := range template.Errors {
+			messages[i] = err.String()
+		}
+		fatal(expr, "Errors found in the template:\n"+strings.Join(messages, "\n"))
+	}
+	return template
+}
+
+// makeDirectiveMetadata reads a directive or component used by the template.
+func makeDirectiveMetadata(directiveObj astObject, typeExpr output.OutputExpression, isComponentByDefault bool) *view.R3DirectiveDependencyMetadata {
+	meta := &view.R3DirectiveDependencyMetadata{
+		R3TemplateDependency: view.R3TemplateDependency{Kind: view.R3TemplateDependencyKindDirective, Type: typeExpr},
+		IsComponent:          isComponentByDefault || (directiveObj.has("kind") && directiveObj.getString("kind") == "component"),
+		Selector:             directiveObj.getString("selector"),
+		Inputs:               directiveObj.optionalStrings("inputs"),
+		Outputs:              directiveObj.optionalStrings("outputs"),
+		ExportAs:             directiveObj.optionalStrings("exportAs"),
+	}
+	if meta.Inputs == nil {
+		meta.Inputs = []string{}
+	}
+	if meta.Outputs == nil {
+		meta.Outputs = []string{}
+	}
+	return meta
+}
+
+// createR3ComponentDeferMetadata matches the dependency functions of `deferBlockDependencies`
+// with the `@defer` blocks of the template, which appear in the same order.
+func createR3ComponentDeferMetadata(metaObj astObject, nodes []render3.Node) view.R3ComponentDeferMetadata {
+	blocks := make(map[*render3.DeferredBlock]*output.OutputExpression)
+	deferredBlocks := view.NewR3TargetBinder(nil).Bind(&view.Target{Template: nodes}).GetDeferBlocks()
+	var dependencies []astValue
+	if metaObj.has("deferBlockDependencies") {
+		dependencies = metaObj.getArray("deferBlockDependencies")
+	}
+	for i, block := range deferredBlocks {
+		if i >= len(dependencies) || dependencies[i].isNull() {
+			blocks[block] = nil
+			continue
+		}
+		fn := dependencies[i].getOpaque()
+		blocks[block] = &fn
+	}
+	return view.R3ComponentDeferMetadata{Mode: view.DeferBlockDepsEmitModePerBlock, Blocks: blocks}
+}
+
+// parseEncapsulation reads e.g. `i0.ViewEncapsulation.None`.
+func parseEncapsulation(encapsulation astValue) core.ViewEncapsulation {
+	switch symbolName(encapsulation, "encapsulation") {
+	case "Emulated":
+		return core.ViewEncapsulationEmulated
+	case "None":
+		return core.ViewEncapsulationNone
+	case "ShadowDom":
+		return core.ViewEncapsulationShadowDom
+	case "ExperimentalIsolatedShadowDom":
+		return core.ViewEncapsulationExperimentalIsolatedShadowDom
+	}
+	fatal(encapsulation.expr, "Unsupported encapsulation")
+	return core.ViewEncapsulationEmulated
+}
+
+// parseChangeDetectionStrategy reads e.g. `i0.ChangeDetectionStrategy.OnPush`.
+func parseChangeDetectionStrategy(changeDetectionStrategy astValue) core.ChangeDetectionStrategy {
+	switch symbolName(changeDetectionStrategy, "change detection strategy") {
+	case "OnPush":
+		return core.ChangeDetectionStrategyOnPush
+	case "Default":
+		return core.ChangeDetectionStrategyDefault
+	}
+	fatal(changeDetectionStrategy.expr, "Unsupported change detection strategy")
+	return core.ChangeDetectionStrategyDefault
+}
@@ -0,0 +1,213 @@
+package linker
+
+import (
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/view"
+	view_compiler "ngc-go/packages/compiler/src/render3/view/compiler"
+)
+
+// partialDirectiveLinker links `ɵɵngDeclareDirective()` into `ɵɵdefineDirective()`.
+type partialDirectiveLinker struct {
+	file *FileLinker
+}
+
+func (p *partialDirectiveLinker) linkPartialDeclaration(constantPool *constant.ConstantPool, metaObj astObject, version string) render3.R3CompiledExpression {
+	meta := toR3DirectiveMeta(metaObj, p.file, version)
+	return view_compiler.CompileDirectiveFromMetadata(meta, constantPool, *view.MakeBindingParser(false))
+}
+
+// toR3DirectiveMeta reads the metadata shared by `ɵɵngDeclareDirective()` and
+// `ɵɵngDeclareComponent()`.
+func toR3DirectiveMeta(metaObj astObject, file *FileLinker, version string) *view.R3DirectiveMetadata {
+	typeExpr := metaObj.getValue("type")
+	typeName, ok := typeExpr.getSymbolName()
+	if !ok {
+		fatal(typeExpr.expr, "Unsupported type, its name could not be determined")
+	}
+
+	meta := &view.R3DirectiveMetadata{
+		Name:              typeName,
+		Type:              wrapReference(typeExpr),
+		TypeArgumentCount: 0,
+		TypeSourceSpan:    file.span(typeExpr.expr.Start, typeExpr.expr.End),
+		Deps:              nil,
+		Host:              toHostMetadata(metaObj),
+		Inputs:            map[string]view.R3InputMetadata{},
+		Outputs:           map[string]string{},
+		Providers:         metaObj.optionalOpaque("providers"),
+		ExportAs:          metaObj.optionalStrings("exportAs"),
+		Lifecycle: view.R3LifecycleMetadata{
+			UsesOnChanges: metaObj.optionalBoolean("usesOnChanges", false),
+		},
+		UsesInheritance: metaObj.optionalBoolean("usesInheritance", false),
+		IsStandalone:    metaObj.optionalBoolean("isStandalone", getDefaultStandaloneValue(version)),
+		IsSignal:        metaObj.optionalBoolean("isSignal", false),
+	}
+	if metaObj.has("inputs") {
+		meta.Inputs = toLiteral(metaObj.getObject("inputs"), toInputMapping)
+	}
+	if metaObj.has("outputs") {
+		meta.Outputs = toLiteral(metaObj.getObject("outputs"), func(value astValue, _ string) string {
+			return value.getString()
+		})
+	}
+	if metaObj.has("queries") {
+		for _, entry := range metaObj.getArray("queries") {
+			meta.Queries = append(meta.Queries, toQueryMetadata(entry.getObject()))
+		}
+	}
+	if metaObj.has("viewQueries") {
+		for _, entry := range metaObj.getArray("viewQueries") {
+			meta.ViewQueries = append(meta.ViewQueries, toQueryMetadata(entry.getObject()))
+		}
+	}
+	if metaObj.has("selector") {
+		selector := metaObj.getString("selector")
+		meta.Selector = &selector
+	}
+	if metaObj.has("hostDirectives") {
+		meta.HostDirectives = toHostDirectivesMetadata(metaObj.getValue("hostDirectives"))
+	}
+	return meta
+}
+
+// toInputMapping reads an input, declared either as an object since v17.1 or in the legacy
+// formats `'publicName'` and `['publicName', 'classPropertyName', transformFunction?]`.
+func toInputMapping(value astValue, key string) view.R3InputMetadata {
+	if value.isObject() {
+		obj := value.getObject()
+		input := view.R3InputMetadata{
+			ClassPropertyName:   obj.getString("classPropertyName"),
+			BindingPropertyName: obj.getString("publicName"),
+			IsSignal:            obj.getBoolean("isSignal"),
+			Required:            obj.getBoolean("isRequired"),
+		}
+		if transform := obj.getValue("transformFunction"); !transform.isNull() {
+			expr := transform.getOpaque()
+			input.TransformFunction = &expr
+		}
+		return input
+	}
+	return parseLegacyInputPartialOutput(key, value)
+}
+
+func parseLegacyInputPartialOutput(key string, value astValue) view.R3InputMetadata {
+	if value.isString() {
+		return view.R3InputMetadata{BindingPropertyName: value.getString(), ClassPropertyName: key}
+	}
+
+	values := value.getArray()
+	if len(values) != 2 && len(values) != 3 {
+		fatal(value.expr, "Unsupported input, expected a string or an array containing two strings and an optional function")
+	}
+	input := view.R3InputMetadata{
+		BindingPropertyName: values[0].getString(),
+		ClassPropertyName:   values[1].getString(),
+	}
+	if len(values) > 2 {
+		expr := values[2].getOpaque()
+		input.TransformFunction = &expr
+	}
+	return input
+}
+
+// toHostMetadata reads the `host` metadata, whose bindings are still unparsed.
+func toHostMetadata(metaObj astObject) view.R3HostMetadata {
+	meta := view.R3HostMetadata{
+		Attributes: map[string]output.OutputExpression{},
+		Listeners:  map[string]string{},
+		Properties: map[string]string{},
+	}
+	if !metaObj.has("host") {
+		return meta
+	}
+	host := metaObj.getObject("host")
+	getString := func(value astValue, _ string) string {
+		return value.getString()
+	}
+	if host.has("attributes") {
+		meta.Attributes = toLiteral(host.getObject("attributes"), func(value astValue, _ string) output.OutputExpression {
+			return value.getOpaque()
+		})
+	}
+	if host.has("listeners") {
+		meta.Listeners = toLiteral(host.getObject("listeners"), getString)
+	}
+	if host.has("properties") {
+		meta.Properties = toLiteral(host.getObject("properties"), getString)
+	}
+	if host.has("styleAttribute") {
+		style := host.getString("styleAttribute")
+		meta.SpecialAttributes.StyleAttr = &style
+	}
+	if host.has("classAttribute") {
+		class := host.getString("classAttribute")
+		meta.SpecialAttributes.ClassAttr = &class
+	}
+	return meta
+}
+
+// toQueryMetadata reads an entry of `queries` or `viewQueries`.
+func toQueryMetadata(obj astObject) view.R3QueryMetadata {
+	query := view.R3QueryMetadata{
+		PropertyName:            obj.getString("propertyName"),
+		First:                   obj.optionalBoolean("first", false),
+		Descendants:             obj.optionalBoolean("descendants", false),
+		EmitDistinctChangesOnly: obj.optionalBoolean("emitDistinctChangesOnly", true),
+		Read:                    obj.optionalOpaque("read"),
+		Static:                  obj.optionalBoolean("static", false),
+		IsSignal:                obj.optionalBoolean("isSignal", false),
+	}
+	predicate := obj.getValue("predicate")
+	if predicate.isArray() {
+		selectors := []string{}
+		for _, entry := range predicate.getArray() {
+			selectors = append(selectors, entry.getString())
+		}
+		query.Predicate = selectors
+	} else {
+		query.Predicate = extractForwardRef(predicate)
+	}
+	return query
+}
+
+// toHostDirectivesMetadata reads `hostDirectives`, whose input and output mappings are flat
+// arrays of alternating public and aliased names.
+func toHostDirectivesMetadata(hostDirectives astValue) []view.R3HostDirectiveMetadata {
+	var result []view.R3HostDirectiveMetadata
+	for _, hostDirective := range hostDirectives.getArray() {
+		hostObject := hostDirective.getObject()
+		typ := extractForwardRef(hostObject.getValue("directive"))
+		meta := view.R3HostDirectiveMetadata{
+			Directive:          render3.R3Reference{Value: typ.Expression, Type: typ.Expression},
+			IsForwardReference: typ.ForwardRef != render3.ForwardRefHandlingNone,
+		}
+		if hostObject.has("inputs") {
+			meta.Inputs = getHostDirectiveBindingMapping(hostObject.getArray("inputs"))
+		}
+		if hostObject.has("outputs") {
+			meta.Outputs = getHostDirectiveBindingMapping(hostObject.getArray("outputs"))
+		}
+		result = append(result, meta)
+	}
+	return result
+}
+
+func getHostDirectiveBindingMapping(array []astValue) map[string]string {
+	var result map[string]string
+	for i := 1; i < len(array); i += 2 {
+		if result == nil {
+			result = make(map[string]string)
+		}
+		result[array[i-1].getString()] = array[i].getString()
+	}
+	return result
+}
+
+// getDefaultStandaloneValue returns whether directives, components and pipes are standalone
+// when the declaration doesn't say: they are since v19.
+func getDefaultStandaloneValue(version string) bool {
+	return isAtLeast(version, "19.0.0")
+}
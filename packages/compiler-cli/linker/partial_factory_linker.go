@@ -0,0 +1,64 @@
+package linker
+
+import (
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/facade"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+// partialFactoryLinker links `ɵɵngDeclareFactory()` into a factory function.
+type partialFactoryLinker struct{}
+
+func (p *partialFactoryLinker) linkPartialDeclaration(_ *constant.ConstantPool, metaObj astObject, _ string) render3.R3CompiledExpression {
+	typeExpr := metaObj.getValue("type")
+	typeName, ok := typeExpr.getSymbolName()
+	if !ok {
+		fatal(typeExpr.expr, "Unsupported type, its name could not be determined")
+	}
+
+	return render3.CompileFactoryFunction(&render3.R3ConstructorFactoryMetadata{
+		Name:              typeName,
+		Type:              wrapReference(typeExpr),
+		TypeArgumentCount: 0,
+		Target:            parseFactoryTarget(metaObj.getValue("target")),
+		Deps:              getDependencies(metaObj, "deps"),
+	})
+}
+
+// getDependencies reads the `deps` of a factory: an array of dependencies, 'invalid' when one
+// of them couldn't be resolved, or null when the constructor is inherited.
+func getDependencies(metaObj astObject, propName string) interface{} {
+	if !metaObj.has(propName) {
+		return nil
+	}
+	deps := metaObj.getValue(propName)
+	switch {
+	case deps.isArray():
+		result := []render3.R3DependencyMetadata{}
+		for _, dep := range deps.getArray() {
+			result = append(result, getDependency(dep.getObject()))
+		}
+		return result
+	case deps.isString():
+		return "invalid"
+	}
+	return nil
+}
+
+// parseFactoryTarget reads e.g. `i0.ɵɵFactoryTarget.Component`.
+func parseFactoryTarget(target astValue) facade.FactoryTarget {
+	switch symbolName(target, "factory target") {
+	case "Directive":
+		return facade.FactoryTargetDirective
+	case "Component":
+		return facade.FactoryTargetComponent
+	case "Injectable":
+		return facade.FactoryTargetInjectable
+	case "Pipe":
+		return facade.FactoryTargetPipe
+	case "NgModule":
+		return facade.FactoryTargetNgModule
+	}
+	fatal(target.expr, "Unsupported factory target")
+	return facade.FactoryTargetDirective
+}
@@ -0,0 +1,56 @@
+package linker
+
+import (
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/partial"
+	render3_injectable_compiler "ngc-go/packages/compiler/src/render3/r3_injectable_compiler"
+)
+
+// partialInjectableLinker links `ɵɵngDeclareInjectable()` into `ɵɵdefineInjectable()`.
+type partialInjectableLinker struct{}
+
+func (p *partialInjectableLinker) linkPartialDeclaration(_ *constant.ConstantPool, metaObj astObject, _ string) render3.R3CompiledExpression {
+	typeExpr := metaObj.getValue("type")
+	typeName, ok := typeExpr.getSymbolName()
+	if !ok {
+		fatal(typeExpr.expr, "Unsupported type, its name could not be determined")
+	}
+
+	meta := partial.R3InjectableMetadata{
+		Name:              typeName,
+		Type:              wrapReference(typeExpr),
+		TypeArgumentCount: 0,
+		ProvidedIn:        render3.CreateMaybeForwardRefExpression(output.NewLiteralExpr(nil, nil, nil), render3.ForwardRefHandlingNone),
+	}
+	if metaObj.has("providedIn") {
+		meta.ProvidedIn = extractForwardRef(metaObj.getValue("providedIn"))
+	}
+	if metaObj.has("useClass") {
+		useClass := extractForwardRef(metaObj.getValue("useClass"))
+		meta.UseClass = &useClass
+	}
+	if metaObj.has("useFactory") {
+		useFactory := metaObj.getOpaque("useFactory")
+		meta.UseFactory = &useFactory
+	}
+	if metaObj.has("useExisting") {
+		useExisting := extractForwardRef(metaObj.getValue("useExisting"))
+		meta.UseExisting = &useExisting
+	}
+	if metaObj.has("useValue") {
+		useValue := extractForwardRef(metaObj.getValue("useValue"))
+		meta.UseValue = &useValue
+	}
+	if metaObj.has("deps") {
+		deps := []render3.R3DependencyMetadata{}
+		for _, dep := range metaObj.getArray("deps") {
+			deps = append(deps, getDependency(dep.getObject()))
+		}
+		meta.Deps = &deps
+	}
+
+	// Forward references were unwrapped above, so they don't need to be resolved at runtime.
+	return render3_injectable_compiler.CompileInjectable(meta, false)
+}
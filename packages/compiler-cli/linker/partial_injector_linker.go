@@ -0,0 +1,32 @@
+package linker
+
+import (
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/render3"
+	render3_injector_compiler "ngc-go/packages/compiler/src/render3/r3_injector_compiler"
+)
+
+// partialInjectorLinker links `ɵɵngDeclareInjector()` into `ɵɵdefineInjector()`.
+type partialInjectorLinker struct{}
+
+func (p *partialInjectorLinker) linkPartialDeclaration(_ *constant.ConstantPool, metaObj astObject, _ string) render3.R3CompiledExpression {
+	typeExpr := metaObj.getValue("type")
+	typeName, ok := typeExpr.getSymbolName()
+	if !ok {
+		fatal(typeExpr.expr, "Unsupported type, its name could not be determined")
+	}
+
+	meta := render3_injector_compiler.R3InjectorMetadata{
+		Name: typeName,
+		Type: wrapReference(typeExpr),
+	}
+	if metaObj.has("providers") {
+		meta.Providers = metaObj.getOpaque("providers")
+	}
+	if metaObj.has("imports") {
+		for _, imp := range metaObj.getArray("imports") {
+			meta.Imports = append(meta.Imports, imp.getOpaque())
+		}
+	}
+	return render3_injector_compiler.CompileInjector(meta)
+}
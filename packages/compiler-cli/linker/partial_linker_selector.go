@@ -0,0 +1,129 @@
+package linker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/core"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+// placeholderVersion is the version of unpublished builds of the compiler.
+const placeholderVersion = "0.0.0-PLACEHOLDER"
+
+// The names of the partial declaration functions.
+const (
+	declareDirective          = "ɵɵngDeclareDirective"
+	declareClassMetadata      = "ɵɵngDeclareClassMetadata"
+	declareClassMetadataAsync = "ɵɵngDeclareClassMetadataAsync"
+	declareComponent          = "ɵɵngDeclareComponent"
+	declareFactory            = "ɵɵngDeclareFactory"
+	declareInjectable         = "ɵɵngDeclareInjectable"
+	declareInjector           = "ɵɵngDeclareInjector"
+	declareNgModule           = "ɵɵngDeclareNgModule"
+	declarePipe               = "ɵɵngDeclarePipe"
+)
+
+// declarationFunctions are the partial declaration functions the linker handles.
+var declarationFunctions = map[string]bool{
+	declareDirective:          true,
+	declareClassMetadata:      true,
+	declareClassMetadataAsync: true,
+	declareComponent:          true,
+	declareFactory:            true,
+	declareInjectable:         true,
+	declareInjector:           true,
+	declareNgModule:           true,
+	declarePipe:               true,
+}
+
+// partialLinker compiles the metadata of a partial declaration into a full definition.
+type partialLinker interface {
+	linkPartialDeclaration(constantPool *constant.ConstantPool, metaObj astObject, version string) render3.R3CompiledExpression
+}
+
+// partialLinkerSelector selects the linker of a declaration.
+type partialLinkerSelector struct {
+	linkers                           map[string]partialLinker
+	unknownDeclarationVersionHandling UnknownDeclarationVersionHandling
+	warn                              func(message string)
+}
+
+func newPartialLinkerSelector(l *FileLinker) *partialLinkerSelector {
+	return &partialLinkerSelector{
+		linkers: map[string]partialLinker{
+			declareDirective:          &partialDirectiveLinker{file: l},
+			declareClassMetadata:      &partialClassMetadataLinker{},
+			declareClassMetadataAsync: &partialClassMetadataAsyncLinker{},
+			declareComponent:          &partialComponentLinker{file: l},
+			declareFactory:            &partialFactoryLinker{},
+			declareInjectable:         &partialInjectableLinker{},
+			declareInjector:           &partialInjectorLinker{},
+			declareNgModule:           &partialNgModuleLinker{emitInline: l.options.JitMode},
+			declarePipe:               &partialPipeLinker{},
+		},
+		unknownDeclarationVersionHandling: l.options.UnknownDeclarationVersionHandling,
+		warn: func(message string) {
+			l.Warnings = append(l.Warnings, message)
+		},
+	}
+}
+
+// getLinker returns the linker of a declaration. Declarations which require a newer compiler
+// than this one are handled as configured by UnknownDeclarationVersionHandling.
+func (s *partialLinkerSelector) getLinker(functionName string, minVersion string, version string) (partialLinker, error) {
+	linker, ok := s.linkers[functionName]
+	if !ok {
+		return nil, fmt.Errorf("Unknown partial declaration function %s.", functionName)
+	}
+	if version == placeholderVersion || supportsVersion(core.VERSION.Full, minVersion) {
+		return linker, nil
+	}
+
+	message := fmt.Sprintf("This application depends upon a library published using Angular version %s, "+
+		"which requires Angular version %s or newer to work correctly.\n"+
+		"Consider upgrading your application to use a more recent version of Angular.", version, minVersion)
+	switch s.unknownDeclarationVersionHandling {
+	case UnknownDeclarationVersionError:
+		return nil, fmt.Errorf("%s", message)
+	case UnknownDeclarationVersionWarn:
+		s.warn(message + "\nAttempting to continue using this version of Angular.")
+	}
+	return linker, nil
+}
+
+// supportsVersion reports whether the linker of the given version can link declarations which
+// require minVersion. Unpublished linkers support all versions. Prereleases are ignored.
+func supportsVersion(linkerVersion string, minVersion string) bool {
+	if linkerVersion == placeholderVersion {
+		return true
+	}
+	return compareVersions(parseVersion(minVersion), parseVersion(linkerVersion)) <= 0
+}
+
+// isAtLeast reports whether the version of a declaration is at least the given version. The
+// placeholder version is the latest.
+func isAtLeast(version string, minVersion string) bool {
+	return version == placeholderVersion || compareVersions(parseVersion(version), parseVersion(minVersion)) >= 0
+}
+
+// parseVersion returns the major, minor and patch numbers of a version, ignoring prereleases.
+func parseVersion(version string) [3]int {
+	var parts [3]int
+	version, _, _ = strings.Cut(version, "-")
+	for i, part := range strings.SplitN(version, ".", 3) {
+		parts[i], _ = strconv.Atoi(part)
+	}
+	return parts
+}
+
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return 0
+}
@@ -0,0 +1,74 @@
+package linker
+
+import (
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/render3"
+	render3_module_compiler "ngc-go/packages/compiler/src/render3/r3_module_compiler"
+)
+
+// partialNgModuleLinker links `ɵɵngDeclareNgModule()` into `ɵɵdefineNgModule()`.
+type partialNgModuleLinker struct {
+	// emitInline emits the selector scope of the NgModule inline into its definition, which JIT
+	// compilation needs. Otherwise it is omitted.
+	emitInline bool
+}
+
+func (p *partialNgModuleLinker) linkPartialDeclaration(_ *constant.ConstantPool, metaObj astObject, _ string) render3.R3CompiledExpression {
+	meta := toR3NgModuleMeta(metaObj, p.emitInline)
+	return render3_module_compiler.CompileNgModule(meta)
+}
+
+func toR3NgModuleMeta(metaObj astObject, supportJit bool) *render3_module_compiler.R3NgModuleMetadataGlobal {
+	meta := &render3_module_compiler.R3NgModuleMetadataGlobal{
+		R3NgModuleMetadataCommon: render3_module_compiler.R3NgModuleMetadataCommon{
+			Kind:              render3_module_compiler.R3NgModuleMetadataKindGlobal,
+			Type:              wrapReference(metaObj.getValue("type")),
+			SelectorScopeMode: render3_module_compiler.R3SelectorScopeModeOmit,
+			Schemas:           []render3.R3Reference{},
+		},
+		Bootstrap:          []render3.R3Reference{},
+		Declarations:       []render3.R3Reference{},
+		Imports:            []render3.R3Reference{},
+		Exports:            []render3.R3Reference{},
+		IncludeImportTypes: true,
+	}
+	if supportJit {
+		meta.SelectorScopeMode = render3_module_compiler.R3SelectorScopeModeInline
+	}
+	if metaObj.has("id") {
+		meta.ID = metaObj.getOpaque("id")
+	}
+
+	// Each of `bootstrap`, `declarations`, `imports` and `exports` are normally an array. But if
+	// any of the references are not yet declared, then the arrays must be wrapped in a function to
+	// prevent errors at runtime when accessing the values. The arrays are unwrapped from such
+	// functions here, and since `ɵɵdefineNgModule()` suffers from the same forward declaration
+	// problem, `ContainsForwardDecls` is set.
+	references := func(name string) []render3.R3Reference {
+		if !metaObj.has(name) {
+			return []render3.R3Reference{}
+		}
+		value := metaObj.getValue(name)
+		if value.isFunction() {
+			meta.ContainsForwardDecls = true
+			value = value.getFunctionReturnValue()
+		}
+		return wrapReferences(value)
+	}
+	meta.Bootstrap = references("bootstrap")
+	meta.Declarations = references("declarations")
+	meta.Imports = references("imports")
+	meta.Exports = references("exports")
+	if metaObj.has("schemas") {
+		meta.Schemas = wrapReferences(metaObj.getValue("schemas"))
+	}
+	return meta
+}
+
+func wrapReferences(values astValue) []render3.R3Reference {
+	refs := []render3.R3Reference{}
+	for _, value := range values.getArray() {
+		refs = append(refs, wrapReference(value))
+	}
+	return refs
+}
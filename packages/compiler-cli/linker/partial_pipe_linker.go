@@ -0,0 +1,27 @@
+package linker
+
+import (
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+// partialPipeLinker links `ɵɵngDeclarePipe()` into `ɵɵdefinePipe()`.
+type partialPipeLinker struct{}
+
+func (p *partialPipeLinker) linkPartialDeclaration(_ *constant.ConstantPool, metaObj astObject, version string) render3.R3CompiledExpression {
+	typeExpr := metaObj.getValue("type")
+	typeName, ok := typeExpr.getSymbolName()
+	if !ok {
+		fatal(typeExpr.expr, "Unsupported type, its name could not be determined")
+	}
+	pipeName := metaObj.getString("name")
+	return render3.CompilePipeFromMetadata(render3.R3PipeMetadata{
+		Name:              typeName,
+		Type:              wrapReference(typeExpr),
+		TypeArgumentCount: 0,
+		PipeName:          &pipeName,
+		Deps:              nil,
+		Pure:              metaObj.optionalBoolean("pure", true),
+		IsStandalone:      metaObj.optionalBoolean("isStandalone", getDefaultStandaloneValue(version)),
+	})
+}
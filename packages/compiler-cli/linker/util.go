@@ -0,0 +1,65 @@
+package linker
+
+import (
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+// wrapReference refers to a value of a declaration, e.g. its `type`.
+func wrapReference(value astValue) render3.R3Reference {
+	return render3.WrapReference(value.expr.Text)
+}
+
+// extractForwardRef unwraps `forwardRef(function () { return X; })` into `X`. Other values are
+// returned as they are.
+func extractForwardRef(expr astValue) render3.MaybeForwardRefExpression {
+	if !expr.isCallExpression() {
+		return render3.CreateMaybeForwardRefExpression(expr.getOpaque(), render3.ForwardRefHandlingNone)
+	}
+
+	callee := expr.getCallee()
+	if name, _ := callee.getSymbolName(); name != "forwardRef" {
+		fatal(callee.expr, "Unsupported expression, expected a `forwardRef()` call or a type reference")
+	}
+
+	args := expr.getArguments()
+	if len(args) != 1 {
+		fatal(expr.expr, "Unsupported `forwardRef(fn)` call, expected a single argument")
+	}
+
+	wrapperFn := args[0]
+	if !wrapperFn.isFunction() {
+		fatal(wrapperFn.expr, "Unsupported `forwardRef(fn)` call, expected its argument to be a function")
+	}
+
+	return render3.CreateMaybeForwardRefExpression(wrapperFn.getFunctionReturnValue().getOpaque(), render3.ForwardRefHandlingUnwrapped)
+}
+
+// getDependency reads an entry of the `deps` of a factory or injectable.
+func getDependency(depObj astObject) render3.R3DependencyMetadata {
+	isAttribute := depObj.optionalBoolean("attribute", false)
+	dep := render3.R3DependencyMetadata{
+		Token:    depObj.getOpaque("token"),
+		Host:     depObj.optionalBoolean("host", false),
+		Optional: depObj.optionalBoolean("optional", false),
+		Self:     depObj.optionalBoolean("self", false),
+		SkipSelf: depObj.optionalBoolean("skipSelf", false),
+	}
+	// Normally `attribute` is a string literal and so its `attributeNameType` is the same string
+	// literal. The linker only deals with JavaScript, where the type isn't needed, so any literal
+	// does.
+	if isAttribute {
+		dep.AttributeNameType = output.NewLiteralExpr("unknown", nil, nil)
+	}
+	return dep
+}
+
+// symbolName returns the symbol name of a value of an enum, e.g. `OnPush` for
+// `i0.ChangeDetectionStrategy.OnPush`.
+func symbolName(value astValue, what string) string {
+	name, ok := value.getSymbolName()
+	if !ok {
+		fatal(value.expr, "Expected "+what+" to have a symbol name")
+	}
+	return name
+}
@@ -0,0 +1,261 @@
+// Package astexport exports the template ASTs of the compiler as JSON, for the tools which are not
+// written in Go.
+package astexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/ml_parser"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/view"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// Level is the stage of the compiler the exported AST comes from.
+type Level string
+
+const (
+	// LevelHTML is the HTML AST of ml_parser: elements, attributes, text, blocks and ICUs, with
+	// the bindings and expressions as written.
+	LevelHTML Level = "html"
+	// LevelR3 is the R3 AST of render3, with the bindings and their expression trees.
+	LevelR3 Level = "r3"
+	// LevelBound is the R3 AST with the results of the binder: the directives matched by the
+	// elements and templates, the targets of the references, the consumers of the bindings and
+	// the template entities read by the expressions.
+	LevelBound Level = "bound"
+)
+
+// Template is the exported AST of a template.
+type Template struct {
+	// Component is the component class, empty for a template file exported at the html level or
+	// which no component uses.
+	Component string `json:"component,omitempty"`
+	// File is the file of the template, the component file for inline templates.
+	File   string `json:"file"`
+	Inline bool   `json:"inline"`
+	// Start and End are the offsets of the template in File.
+	Start int `json:"start"`
+	End   int `json:"end"`
+	// Nodes are the root nodes of the template.
+	Nodes []Object `json:"nodes"`
+	// Errors are the errors parsing the template, which does not stop its export.
+	Errors []Object `json:"errors,omitempty"`
+	// EmptyScope is set at the bound level for a template file which no component uses: it is
+	// bound against no directive, so that its elements match none.
+	EmptyScope bool `json:"emptyScope,omitempty"`
+}
+
+// Object is a JSON object whose fields keep their order.
+type Object []Field
+
+// Field is a field of an Object.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Get returns the value of a field, nil when there is no such field.
+func (o Object) Get(key string) interface{} {
+	for _, field := range o {
+		if field.Key == key {
+			return field.Value
+		}
+	}
+	return nil
+}
+
+// MarshalJSON writes the fields in order.
+func (o Object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(field.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// Span is the source span of a node or expression.
+type Span struct {
+	Start Location `json:"start"`
+	End   Location `json:"end"`
+}
+
+// Location is a position in a file. Offset, Line and Col are zero-based.
+type Location struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Col    int `json:"col"`
+}
+
+// Binding gives the results of the binder to R3.
+type Binding struct {
+	Bound view.BoundTarget
+	// DirectiveName names a directive matched by the binder. Directives are named by their Go type
+	// when it is nil.
+	DirectiveName func(dir interface{}) string
+}
+
+// HTML exports the nodes of an HTML AST. text is the content of the file their spans point into.
+func HTML(text string, nodes []ml_parser.Node) []Object {
+	e := newExporter(text, nil)
+	objects := make([]Object, len(nodes))
+	for i, node := range nodes {
+		objects[i] = e.node(node)
+	}
+	return objects
+}
+
+// R3 exports the nodes of an R3 AST, with the results of the binder when binding is not nil. text
+// is the content of the file their spans point into.
+func R3(text string, nodes []render3.Node, binding *Binding) []Object {
+	e := newExporter(text, binding)
+	objects := make([]Object, len(nodes))
+	for i, node := range nodes {
+		objects[i] = e.node(node)
+	}
+	return objects
+}
+
+// ExportFile exports the templates of a file: a template file (.html) or the inline templates of
+// the components of a TypeScript file (.ts). The html level only parses the file; the r3 and
+// bound levels take the templates from compiler, which must be analyzed. A template file no
+// component uses, which is any when compiler is nil, is parsed with the default options and bound
+// against an empty scope.
+func ExportFile(fileName string, content string, level Level, compiler *annotations.Compiler) ([]*Template, error) {
+	switch level {
+	case LevelHTML:
+		return exportHTML(fileName, content), nil
+	case LevelR3, LevelBound:
+		if compiler == nil && strings.HasSuffix(fileName, ".ts") {
+			return nil, fmt.Errorf("the %s level of a component file requires a compiler", level)
+		}
+		return exportR3(fileName, content, level, compiler)
+	}
+	return nil, fmt.Errorf("unknown level %q, expected html, r3 or bound", level)
+}
+
+func exportHTML(fileName string, content string) []*Template {
+	if !strings.HasSuffix(fileName, ".ts") {
+		template := &Template{File: fileName, End: len(content)}
+		parseHTML(template, content, nil)
+		return []*Template{template}
+	}
+	templates := []*Template{}
+	for _, source := range annotations.TemplateSources(reflection.ReflectSourceFile(fileName, content)) {
+		if source.Inline == nil {
+			continue
+		}
+		template := &Template{
+			Component: source.ClassName,
+			File:      fileName,
+			Inline:    true,
+			Start:     source.Inline.Start + 1,
+			End:       source.Inline.End - 1,
+		}
+		line := strings.Count(content[:template.Start], "\n")
+		parseHTML(template, content, &ml_parser.LexerRange{
+			StartPos:  template.Start,
+			StartLine: line,
+			StartCol:  template.Start - strings.LastIndex(content[:template.Start], "\n") - 1,
+			EndPos:    template.End,
+		})
+		templates = append(templates, template)
+	}
+	return templates
+}
+
+// parseHTML parses a template like the compiler does, in a range of the content of its file for
+// inline templates.
+func parseHTML(template *Template, content string, lexerRange *ml_parser.LexerRange) {
+	enabled := true
+	options := &ml_parser.TokenizeOptions{
+		TokenizeExpansionForms: &enabled,
+		TokenizeBlocks:         &enabled,
+		TokenizeLet:            &enabled,
+		Range:                  lexerRange,
+	}
+	if lexerRange != nil {
+		options.EscapedString = &enabled
+	}
+	result := ml_parser.NewHtmlParser().Parse(content, template.File, options)
+	template.Nodes = HTML(content, result.RootNodes)
+	template.Errors = newExporter(content, nil).errors(result.Errors)
+}
+
+func exportR3(fileName string, content string, level Level, compiler *annotations.Compiler) ([]*Template, error) {
+	var owned []*annotations.ComponentTemplate
+	if compiler != nil {
+		for _, t := range compiler.Templates(fileName) {
+			if path.Clean(t.TemplateURL) == path.Clean(fileName) {
+				owned = append(owned, t)
+			}
+		}
+	}
+	if len(owned) == 0 && !strings.HasSuffix(fileName, ".ts") {
+		template := exportTemplate(content, level, annotations.UnownedTemplate(fileName, content))
+		template.EmptyScope = level == LevelBound
+		return []*Template{template}, nil
+	}
+	templates := []*Template{}
+	for _, t := range owned {
+		templates = append(templates, exportTemplate(content, level, t))
+	}
+	return templates, nil
+}
+
+// exportTemplate exports the R3 AST of a template, with the results of the binder at the bound
+// level.
+func exportTemplate(content string, level Level, t *annotations.ComponentTemplate) *Template {
+	parsed := t.Parse()
+	var binding *Binding
+	if level == LevelBound {
+		binding = &Binding{
+			Bound: t.Bind(parsed.Nodes),
+			DirectiveName: func(dir interface{}) string {
+				if scopeDir := t.Directive(dir); scopeDir != nil {
+					return scopeDir.Name
+				}
+				return ""
+			},
+		}
+	}
+	return &Template{
+		Component: t.ClassName,
+		File:      t.TemplateURL,
+		Inline:    t.Inline,
+		Start:     t.Start,
+		End:       t.End,
+		Nodes:     R3(content, parsed.Nodes, binding),
+		Errors:    newExporter(content, nil).errors(parsed.Errors),
+	}
+}
+
+// errors exports parse errors.
+func (e *exporter) errors(errs []*util.ParseError) []Object {
+	var objects []Object
+	for _, err := range errs {
+		objects = append(objects, e.parseError(err))
+	}
+	return objects
+}
@@ -0,0 +1,341 @@
+package astexport
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"ngc-go/packages/compiler/src/core"
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/view"
+	"ngc-go/packages/compiler/src/util"
+)
+
+var (
+	parseSourceSpanType    = reflect.TypeOf(&util.ParseSourceSpan{})
+	absoluteSourceSpanType = reflect.TypeOf(&expression_parser.AbsoluteSourceSpan{})
+	parseSpanType          = reflect.TypeOf(&expression_parser.ParseSpan{})
+	parseErrorType         = reflect.TypeOf(&util.ParseError{})
+)
+
+// skippedFields are the fields which are not exported: the i18n metadata, which is not part of
+// the template as written, and the tokens the values are read from.
+var skippedFields = map[string]bool{
+	"I18n":        true,
+	"Tokens":      true,
+	"ValueTokens": true,
+}
+
+// enumNames are the names of the values of the enums of the ASTs, exported instead of numbers.
+var enumNames = map[reflect.Type][]string{
+	reflect.TypeOf(expression_parser.BindingTypeProperty): {
+		"property", "attribute", "class", "style", "legacyAnimation", "twoWay", "animation",
+	},
+	reflect.TypeOf(expression_parser.ParsedEventTypeRegular): {
+		"regular", "legacyAnimation", "twoWay", "animation",
+	},
+	reflect.TypeOf(expression_parser.ReferencedByName): {
+		"referencedByName", "referencedDirectly",
+	},
+	reflect.TypeOf(core.SecurityContextNONE): {
+		"none", "html", "style", "script", "url", "resourceUrl",
+	},
+}
+
+type parseSourceSpanNode interface {
+	SourceSpan() *util.ParseSourceSpan
+}
+
+type absoluteSourceSpanNode interface {
+	SourceSpan() *expression_parser.AbsoluteSourceSpan
+}
+
+type nameSpanNode interface {
+	NameSpan() *expression_parser.AbsoluteSourceSpan
+}
+
+// exporter turns AST nodes into objects holding their kind, the Go type of the node, and their
+// exported fields, named in lowerCamelCase, with the embedded structs flattened. Source spans
+// become Spans and nil fields are left out.
+type exporter struct {
+	binding    *Binding
+	lineStarts []int
+	// visiting holds the nodes being exported, so that a cycle is not followed.
+	visiting map[uintptr]bool
+}
+
+func newExporter(text string, binding *Binding) *exporter {
+	lineStarts := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	return &exporter{binding: binding, lineStarts: lineStarts, visiting: make(map[uintptr]bool)}
+}
+
+// node exports a node, which is never nil.
+func (e *exporter) node(node interface{}) Object {
+	o, _ := e.value(reflect.ValueOf(node))
+	object, _ := o.(Object)
+	return object
+}
+
+// value exports a value, or returns false when it is left out.
+func (e *exporter) value(v reflect.Value) (interface{}, bool) {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil, false
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return nil, false
+		}
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil, false
+	}
+	switch v.Type() {
+	case parseSourceSpanType:
+		return e.span(v.Interface().(*util.ParseSourceSpan)), true
+	case absoluteSourceSpanType:
+		return e.absoluteSpan(v.Interface().(*expression_parser.AbsoluteSourceSpan)), true
+	case parseSpanType:
+		// Relative to the expression, the absolute span is exported instead.
+		return nil, false
+	case parseErrorType:
+		return e.parseError(v.Interface().(*util.ParseError)), true
+	}
+	if names, ok := enumNames[v.Type()]; ok {
+		if i := int(v.Int()); i >= 0 && i < len(names) {
+			return names[i], true
+		}
+		return v.Int(), true
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		return e.value(v.Elem())
+	case reflect.Ptr:
+		if v.Elem().Kind() != reflect.Struct {
+			return e.value(v.Elem())
+		}
+		return e.object(v)
+	case reflect.Struct:
+		return e.object(v)
+	case reflect.Slice, reflect.Array:
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			values[i], _ = e.value(v.Index(i))
+		}
+		return values, true
+	case reflect.Map:
+		var o Object
+		for _, key := range v.MapKeys() {
+			if value, ok := e.value(v.MapIndex(key)); ok {
+				o = append(o, Field{fmt.Sprint(key.Interface()), value})
+			}
+		}
+		sort.SliceStable(o, func(i, j int) bool { return o[i].Key < o[j].Key })
+		return o, true
+	case reflect.Bool:
+		return v.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		return v.String(), true
+	}
+	return nil, false
+}
+
+// object exports a struct, or a pointer to a struct.
+func (e *exporter) object(v reflect.Value) (interface{}, bool) {
+	if v.Kind() == reflect.Ptr {
+		if e.visiting[v.Pointer()] {
+			return nil, false
+		}
+		e.visiting[v.Pointer()] = true
+		defer delete(e.visiting, v.Pointer())
+	}
+	s := v
+	if s.Kind() == reflect.Ptr {
+		s = s.Elem()
+	}
+	o := Object{{"kind", s.Type().Name()}}
+	e.fields(s, &o)
+	if o.Get("sourceSpan") == nil {
+		switch node := v.Interface().(type) {
+		case parseSourceSpanNode:
+			if span := node.SourceSpan(); span != nil {
+				o = append(o, Field{"sourceSpan", e.span(span)})
+			}
+		case absoluteSourceSpanNode:
+			if span := node.SourceSpan(); span != nil {
+				o = append(o, Field{"sourceSpan", e.absoluteSpan(span)})
+			}
+		}
+	}
+	if node, ok := v.Interface().(nameSpanNode); ok && o.Get("nameSpan") == nil {
+		if span := node.NameSpan(); span != nil {
+			o = append(o, Field{"nameSpan", e.absoluteSpan(span)})
+		}
+	}
+	if e.binding != nil {
+		e.bound(v.Interface(), &o)
+	}
+	return o, true
+}
+
+// fields adds the exported fields of a struct to an object, flattening the embedded structs.
+func (e *exporter) fields(v reflect.Value, o *Object) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if field.Anonymous {
+			if value.Kind() == reflect.Ptr && !value.IsNil() {
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				e.fields(value, o)
+			}
+			continue
+		}
+		if !field.IsExported() || skippedFields[field.Name] {
+			continue
+		}
+		if exported, ok := e.value(value); ok {
+			*o = append(*o, Field{jsonName(field.Name), exported})
+		}
+	}
+}
+
+// bound adds the results of the binder for a node or expression to its object.
+func (e *exporter) bound(x interface{}, o *Object) {
+	bound := e.binding.Bound
+	if node, ok := x.(render3.Node); ok && view.IsDirectiveOwner(node) {
+		names := []string{}
+		for _, dir := range bound.GetDirectivesOfNode(node) {
+			names = append(names, e.directiveName(dir))
+		}
+		*o = append(*o, Field{"directives", names})
+	}
+	switch x := x.(type) {
+	case *render3.Reference:
+		if target := e.referenceTarget(bound.GetReferenceTarget(x)); target != nil {
+			*o = append(*o, Field{"target", target})
+		}
+	case *render3.BoundAttribute, *render3.BoundEvent, *render3.TextAttribute:
+		if consumer := e.target(bound.GetConsumerOfBinding(x)); consumer != nil {
+			*o = append(*o, Field{"consumer", consumer})
+		}
+	case expression_parser.AST:
+		if entity := bound.GetExpressionTarget(x); entity != nil {
+			if summary := e.summary(entity); summary != nil {
+				*o = append(*o, Field{"target", summary})
+			}
+		}
+	}
+}
+
+// referenceTarget exports the target of a reference: a directive, with the node matching it, or
+// an element or template.
+func (e *exporter) referenceTarget(target view.ReferenceTarget) Object {
+	switch target := target.(type) {
+	case *view.ReferenceTargetWithDirective:
+		return Object{{"directive", e.directiveName(target.Directive)}, {"node", e.summary(target.Node)}}
+	case *view.ReferenceTargetElement:
+		return Object{{"node", e.summary(target.Element)}}
+	case *view.ReferenceTargetTemplate:
+		return Object{{"node", e.summary(target.Template)}}
+	}
+	return nil
+}
+
+// target exports the consumer of a binding: a directive, or an element or template.
+func (e *exporter) target(x interface{}) Object {
+	if x == nil {
+		return nil
+	}
+	if node, ok := x.(render3.Node); ok {
+		return Object{{"node", e.summary(node)}}
+	}
+	return Object{{"directive", e.directiveName(x)}}
+}
+
+func (e *exporter) directiveName(dir interface{}) string {
+	if e.binding.DirectiveName == nil {
+		return fmt.Sprintf("%T", dir)
+	}
+	return e.binding.DirectiveName(dir)
+}
+
+// summary identifies a node exported elsewhere by its kind, name and source span.
+func (e *exporter) summary(x interface{}) Object {
+	v := reflect.ValueOf(x)
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return nil
+	}
+	s := reflect.Indirect(v)
+	o := Object{{"kind", s.Type().Name()}}
+	if s.Kind() == reflect.Struct {
+		if name := s.FieldByName("Name"); name.IsValid() && name.Kind() == reflect.String {
+			o = append(o, Field{"name", name.String()})
+		}
+	}
+	if node, ok := x.(parseSourceSpanNode); ok && node.SourceSpan() != nil {
+		o = append(o, Field{"sourceSpan", e.span(node.SourceSpan())})
+	}
+	return o
+}
+
+func (e *exporter) parseError(err *util.ParseError) Object {
+	o := Object{{"kind", "ParseError"}, {"msg", err.Msg}}
+	if err.Span != nil {
+		o = append(o, Field{"sourceSpan", e.span(err.Span)})
+	}
+	return o
+}
+
+func (e *exporter) span(span *util.ParseSourceSpan) Span {
+	return Span{Start: location(span.Start), End: location(span.End)}
+}
+
+func location(l *util.ParseLocation) Location {
+	if l == nil {
+		return Location{}
+	}
+	return Location{Offset: l.Offset, Line: l.Line, Col: l.Col}
+}
+
+// absoluteSpan exports the span of an expression, whose offsets are in the text of the exporter.
+func (e *exporter) absoluteSpan(span *expression_parser.AbsoluteSourceSpan) Span {
+	return Span{Start: e.location(span.Start), End: e.location(span.End)}
+}
+
+func (e *exporter) location(offset int) Location {
+	line := sort.Search(len(e.lineStarts), func(i int) bool { return e.lineStarts[i] > offset }) - 1
+	if line < 0 {
+		line = 0
+	}
+	return Location{Offset: offset, Line: line, Col: offset - e.lineStarts[line]}
+}
+
+// jsonName returns the lowerCamelCase name of a field, e.g. "sourceSpan" for SourceSpan and "ast"
+// for AST.
+func jsonName(name string) string {
+	runes := []rune(name)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+	if upper > 1 && upper < len(runes) {
+		// The last capital starts the next word, e.g. "HTMLText" is "htmlText".
+		upper--
+	}
+	return strings.ToLower(string(runes[:upper])) + string(runes[upper:])
+}
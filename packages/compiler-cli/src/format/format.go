@@ -0,0 +1,129 @@
+// Package format formats the templates of a project.
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/ml_parser"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// Options are the options of the formatter.
+type Options struct {
+	// Width is the length past which the lines are broken, when they can be. Defaults to 80.
+	Width int
+	// IndentSize is the number of spaces of an indentation level. Defaults to 2.
+	IndentSize int
+}
+
+func (o Options) withDefaults() Options {
+	if o.Width <= 0 {
+		o.Width = 80
+	}
+	if o.IndentSize <= 0 {
+		o.IndentSize = 2
+	}
+	return o
+}
+
+// FormatTemplate formats an external template.
+//
+// Only the whitespace which is not significant once the template is compiled without
+// preserveWhitespaces is changed: the formatted template renders the same DOM and has the same
+// i18n messages. The content of `<pre>`, `<textarea>`, `<script>` and `<style>` elements, of the
+// elements with the ngPreserveWhitespaces, ngNonBindable or i18n attributes and of ICU
+// expansions is kept as it is. The expressions of the bindings, interpolations and blocks are
+// respaced, when that is all that changes.
+func FormatTemplate(fileName string, template string, options Options) (string, error) {
+	p, nodes, err := parse(fileName, template, options, "")
+	if err != nil {
+		return "", fmt.Errorf("%s:%d:%d: %s", fileName, err.Span.Start.Line+1, err.Span.Start.Col+1, err.Msg)
+	}
+	lines, _, _ := p.children(nodes, 0)
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// FormatFile formats a template file (.html) or the inline templates of the components of a
+// TypeScript file (.ts), where only the template literals are changed. The inline templates of
+// the components with preserveWhitespaces are left as they are, as are other files.
+func FormatFile(fileName string, content string, options Options) (string, error) {
+	switch {
+	case strings.HasSuffix(fileName, ".html"):
+		return FormatTemplate(fileName, content, options)
+	case strings.HasSuffix(fileName, ".ts"):
+		return formatInlineTemplates(fileName, content, options)
+	}
+	return content, nil
+}
+
+func formatInlineTemplates(fileName string, content string, options Options) (string, error) {
+	var b strings.Builder
+	pos := 0
+	for _, template := range annotations.TemplateSources(reflection.ReflectSourceFile(fileName, content)) {
+		if template.Inline == nil || template.PreserveWhitespaces {
+			continue
+		}
+		literal, err := formatInlineTemplate(fileName, content, template.Inline, options)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(content[pos:template.Inline.Start])
+		b.WriteString(literal)
+		pos = template.Inline.End
+	}
+	b.WriteString(content[pos:])
+	return b.String(), nil
+}
+
+// formatInlineTemplate returns the string literal of a formatted inline template. A template which
+// fits on a line keeps its quotes, others are written in a template literal, indented one level
+// more than the line of the literal.
+func formatInlineTemplate(fileName string, content string, expr *reflection.Expression, options Options) (string, error) {
+	lineStart := strings.LastIndex(content[:expr.Start], "\n") + 1
+	indent := content[lineStart:expr.Start]
+	indent = indent[:len(indent)-len(strings.TrimLeft(indent, " \t"))]
+	base := indent + strings.Repeat(" ", options.withDefaults().IndentSize)
+
+	p, nodes, err := parse(fileName, expr.Value, options, base)
+	if err != nil {
+		line := strings.Count(content[:expr.Start], "\n") + err.Span.Start.Line
+		return "", fmt.Errorf("%s:%d: the inline template has errors: %s", fileName, line+1, err.Msg)
+	}
+	lines, gluedStart, gluedEnd := p.children(nodes, 0)
+	formatted := strings.Join(lines, "\n")
+	if quote := expr.Text[0]; !strings.Contains(formatted, "\n") && quote != '`' {
+		formatted = strings.ReplaceAll(formatted, `\`, `\\`)
+		return string(quote) + strings.ReplaceAll(formatted, string(quote), `\`+string(quote)) + string(quote), nil
+	}
+	formatted = strings.NewReplacer(`\`, `\\`, "`", "\\`", "${", "\\${").Replace(formatted)
+	if !strings.Contains(formatted, "\n") {
+		return "`" + formatted + "`", nil
+	}
+	// The whitespace around the template is only added where it is not significant.
+	if !gluedStart {
+		formatted = "\n" + base + formatted
+	}
+	if !gluedEnd {
+		formatted += "\n" + indent
+	}
+	return "`" + formatted + "`", nil
+}
+
+func parse(fileName string, template string, options Options, base string) (*printer, []ml_parser.Node, *util.ParseError) {
+	enabled := true
+	result := ml_parser.NewHtmlParser().Parse(template, fileName, &ml_parser.TokenizeOptions{
+		TokenizeExpansionForms: &enabled,
+		TokenizeBlocks:         &enabled,
+		TokenizeLet:            &enabled,
+	})
+	if len(result.Errors) > 0 {
+		return nil, nil, result.Errors[0]
+	}
+	return newPrinter(template, options.withDefaults(), base), result.RootNodes, nil
+}
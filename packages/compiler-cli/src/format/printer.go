@@ -0,0 +1,543 @@
+package format
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/ml_parser"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// verbatimTags are the elements whose whitespace is kept by the compiler.
+var verbatimTags = map[string]bool{
+	"pre":      true,
+	"template": true,
+	"textarea": true,
+	"script":   true,
+	"style":    true,
+}
+
+// verbatimAttributes are the attributes of the elements whose content is kept as it is: the
+// whitespace of ngPreserveWhitespaces elements is kept by the compiler, the text of ngNonBindable
+// elements is rendered as it is written, and the i18n messages of i18n elements include their
+// whitespace.
+var verbatimAttributes = map[string]bool{
+	ml_parser.PreserveWsAttrName: true,
+	"ngNonBindable":              true,
+	"i18n":                       true,
+}
+
+// connectedBlocks are the blocks which follow the closing brace of the previous block.
+var connectedBlocks = map[string]bool{
+	"else":        true,
+	"else if":     true,
+	"empty":       true,
+	"placeholder": true,
+	"loading":     true,
+	"error":       true,
+}
+
+var (
+	forOfParameter = regexp.MustCompile(`(?s)^(\S+)\s+of\s+(.*)$`)
+	trackParameter = regexp.MustCompile(`(?s)^track\s+(.*)$`)
+	asParameter    = regexp.MustCompile(`^as\s+(\S+)$`)
+)
+
+// printer lays out the nodes of a template in lines. The layouts of the nodes are built bottom-up
+// as lines whose first line is not indented, as it may follow other nodes, while the next ones
+// are.
+type printer struct {
+	source  string
+	options Options
+	// base is the indentation of the template, for inline templates.
+	base   string
+	lexer  *expression_parser.Lexer
+	parser *expression_parser.Parser
+	// flatElements are the elements laid out on a line, empty when they cannot be.
+	flatElements map[*ml_parser.Element]string
+}
+
+func newPrinter(source string, options Options, base string) *printer {
+	lexer := expression_parser.NewLexer()
+	return &printer{
+		source:  source,
+		options: options,
+		base:    base,
+		lexer:   lexer,
+		parser:  expression_parser.NewParser(lexer, false),
+
+		flatElements: make(map[*ml_parser.Element]string),
+	}
+}
+
+// item is a child of an element or block, once the text is split into words.
+type item struct {
+	// node is nil for words.
+	node ml_parser.Node
+	word string
+	// space reports whether whitespace precedes the item.
+	space bool
+}
+
+// isText reports whether the whitespace around the item is significant. The whitespace between
+// other nodes is removed by the compiler.
+func (it *item) isText() bool {
+	if it.node == nil {
+		return true
+	}
+	_, ok := it.node.(*ml_parser.Expansion)
+	return ok
+}
+
+// ownsLine reports whether the item is laid out on its own line in text.
+func (it *item) ownsLine() bool {
+	switch it.node.(type) {
+	case *ml_parser.Block, *ml_parser.LetDeclaration:
+		return true
+	}
+	return false
+}
+
+// content are the children of an element or block.
+type content struct {
+	items []*item
+	// trailing reports whether whitespace follows the last item.
+	trailing bool
+	// text reports whether some items are text, in which case the items fill the lines, otherwise
+	// every item is on its own line.
+	text bool
+}
+
+// gluedStart reports whether the content starts with text which is not separated from the start
+// tag by whitespace.
+func (c *content) gluedStart() bool {
+	return len(c.items) > 0 && c.items[0].isText() && !c.items[0].space
+}
+
+// gluedEnd reports whether the content ends with text which is not separated from the end tag by
+// whitespace.
+func (c *content) gluedEnd() bool {
+	return len(c.items) > 0 && c.items[len(c.items)-1].isText() && !c.trailing
+}
+
+// children lays out the children of the template, and reports whether they start or end with text
+// which is not separated from the template boundaries by whitespace.
+func (p *printer) children(nodes []ml_parser.Node, level int) ([]string, bool, bool) {
+	c := p.content(nodes)
+	if len(c.items) == 0 {
+		return nil, false, false
+	}
+	return p.lines(c, level), c.gluedStart(), c.gluedEnd()
+}
+
+func (p *printer) content(nodes []ml_parser.Node) *content {
+	c := &content{}
+	space := false
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			c.items = append(c.items, &item{word: word.String(), space: space})
+			c.text = true
+			space = false
+			word.Reset()
+		}
+	}
+	for _, node := range nodes {
+		text, ok := node.(*ml_parser.Text)
+		if !ok {
+			c.items = append(c.items, &item{node: node, space: space})
+			if _, ok := node.(*ml_parser.Expansion); ok {
+				c.text = true
+			}
+			space = false
+			continue
+		}
+		for _, token := range text.Tokens {
+			switch token.Type() {
+			case ml_parser.TokenTypeTEXT:
+				for _, r := range token.Parts()[0] {
+					if isWhitespace(r) {
+						flush()
+						space = true
+					} else {
+						word.WriteRune(r)
+					}
+				}
+			case ml_parser.TokenTypeINTERPOLATION:
+				word.WriteString(p.interpolation(token))
+			default:
+				word.WriteString(p.text(token.SourceSpan()))
+			}
+		}
+		flush()
+	}
+	c.trailing = space
+	return c
+}
+
+// lines lays out content at a level.
+func (p *printer) lines(c *content, level int) []string {
+	var lines []string
+	for i, it := range c.items {
+		if i == 0 {
+			lines = p.item(it, level)
+			continue
+		}
+		prev := c.items[i-1]
+		last := len(lines) - 1
+		if !it.space && (it.isText() || prev.isText()) {
+			// The item cannot be separated from the previous one.
+			itemLines := p.item(it, level)
+			lines[last] += itemLines[0]
+			lines = append(lines, itemLines[1:]...)
+			continue
+		}
+		if block, ok := it.node.(*ml_parser.Block); ok && connectedBlocks[block.Name] {
+			if _, ok := prev.node.(*ml_parser.Block); ok {
+				itemLines := p.item(it, level)
+				lines[last] += " " + itemLines[0]
+				lines = append(lines, itemLines[1:]...)
+				continue
+			}
+		}
+		if c.text && !it.ownsLine() && !prev.ownsLine() {
+			if flat, ok := p.flat(it); ok {
+				separator := ""
+				if it.space {
+					separator = " "
+				}
+				if p.width(lines, last, level)+len(separator)+utf8.RuneCountInString(flat) <= p.options.Width {
+					lines[last] += separator + flat
+					continue
+				}
+			}
+		}
+		itemLines := p.item(it, level)
+		lines = append(lines, p.indent(level)+itemLines[0])
+		lines = append(lines, itemLines[1:]...)
+	}
+	return lines
+}
+
+// flatContent lays out content on a line, if it can be.
+func (p *printer) flatContent(c *content) (string, bool) {
+	if !c.text && len(c.items) > 1 {
+		return "", false
+	}
+	var b strings.Builder
+	for i, it := range c.items {
+		flat, ok := p.flat(it)
+		if !ok {
+			return "", false
+		}
+		if it.space && (i > 0 || it.isText()) {
+			b.WriteString(" ")
+		}
+		b.WriteString(flat)
+	}
+	if c.trailing && c.items[len(c.items)-1].isText() {
+		b.WriteString(" ")
+	}
+	return b.String(), true
+}
+
+// item lays out an item at a level.
+func (p *printer) item(it *item, level int) []string {
+	switch node := it.node.(type) {
+	case nil:
+		return []string{it.word}
+	case *ml_parser.Element:
+		return p.element(node, level)
+	case *ml_parser.Block:
+		return p.block(node, level)
+	case *ml_parser.LetDeclaration:
+		return []string{"@let " + node.Name + " = " + p.expression(node.Value, false) + ";"}
+	}
+	return []string{p.text(it.node.SourceSpan())}
+}
+
+// flat lays out an item on a line, if it can be.
+func (p *printer) flat(it *item) (string, bool) {
+	switch node := it.node.(type) {
+	case nil:
+		return it.word, true
+	case *ml_parser.Element:
+		return p.flatElement(node)
+	case *ml_parser.Block, *ml_parser.LetDeclaration:
+		return "", false
+	}
+	text := p.text(it.node.SourceSpan())
+	return text, !strings.Contains(text, "\n")
+}
+
+func (p *printer) element(el *ml_parser.Element, level int) []string {
+	if flat, ok := p.flatElement(el); ok && p.fits(level, flat) {
+		return []string{flat}
+	}
+	tag := p.startTag(el)
+	open := []string{"<" + tag.name}
+	if flat := tag.flat(); p.fits(level, flat) {
+		open[0] = flat
+	} else {
+		for _, attr := range tag.attrs {
+			open = append(open, p.indent(level+1)+attr)
+		}
+		open = append(open, p.indent(level)+strings.TrimPrefix(tag.end, " "))
+	}
+	if el.IsSelfClosing || el.IsVoid {
+		return open
+	}
+	close := "</" + tag.name + ">"
+	if tag.verbatim {
+		open[len(open)-1] += tag.content + close
+		return open
+	}
+	c := p.content(el.Children)
+	if len(c.items) == 0 {
+		open[len(open)-1] += close
+		return open
+	}
+	return p.wrap(open, c, close, level)
+}
+
+// flatElement lays out an element on a line, if it can be.
+func (p *printer) flatElement(el *ml_parser.Element) (string, bool) {
+	if flat, ok := p.flatElements[el]; ok {
+		return flat, flat != ""
+	}
+	flat, ok := "", false
+	tag := p.startTag(el)
+	switch {
+	case strings.Contains(tag.flat(), "\n"):
+	case el.IsSelfClosing || el.IsVoid:
+		flat, ok = tag.flat(), true
+	case tag.verbatim:
+		flat = tag.flat() + tag.content + "</" + tag.name + ">"
+		ok = !strings.Contains(flat, "\n")
+	default:
+		var content string
+		if content, ok = p.flatContent(p.content(el.Children)); ok {
+			flat = tag.flat() + content + "</" + tag.name + ">"
+		}
+	}
+	if !ok {
+		flat = ""
+	}
+	p.flatElements[el] = flat
+	return flat, ok
+}
+
+// startTag is the start tag of an element.
+type startTag struct {
+	name  string
+	attrs []string
+	end   string
+	// verbatim reports whether the content of the element is kept as it is, in content.
+	verbatim bool
+	content  string
+}
+
+func (t *startTag) flat() string {
+	return "<" + strings.Join(append([]string{t.name}, t.attrs...), " ") + t.end
+}
+
+func (p *printer) startTag(el *ml_parser.Element) *startTag {
+	tag := &startTag{name: p.tagName(el), attrs: make([]string, len(el.Attrs)), end: ">"}
+	if el.IsSelfClosing {
+		tag.end = " />"
+	}
+	tag.verbatim = verbatimTags[strings.ToLower(tag.name)]
+	for i, attr := range el.Attrs {
+		tag.attrs[i] = p.attribute(attr)
+		tag.verbatim = tag.verbatim || verbatimAttributes[attr.Name]
+	}
+	if tag.verbatim && el.EndSourceSpan != nil {
+		tag.content = p.source[el.StartSourceSpan.End.Offset:el.EndSourceSpan.Start.Offset]
+	} else {
+		tag.verbatim = false
+	}
+	return tag
+}
+
+// block lays out a block. Blocks are always broken, unless their content is text which cannot be
+// separated from the braces.
+func (p *printer) block(block *ml_parser.Block, level int) []string {
+	start := "@" + block.Name
+	if len(block.Parameters) > 0 {
+		params := make([]string, len(block.Parameters))
+		for i, param := range block.Parameters {
+			params[i] = p.blockParameter(block.Name, i, param.Expression)
+		}
+		start += " (" + strings.Join(params, "; ") + ")"
+	}
+	start += " {"
+	c := p.content(block.Children)
+	if len(c.items) == 0 {
+		return []string{start + "}"}
+	}
+	return p.wrap([]string{start}, c, "}", level)
+}
+
+// wrap lays out content on the lines between a start and an end tag.
+func (p *printer) wrap(start []string, c *content, end string, level int) []string {
+	lines := start
+	body := p.lines(c, level+1)
+	if c.gluedStart() {
+		lines[len(lines)-1] += body[0]
+	} else {
+		lines = append(lines, p.indent(level+1)+body[0])
+	}
+	lines = append(lines, body[1:]...)
+	if c.gluedEnd() {
+		lines[len(lines)-1] += end
+	} else {
+		lines = append(lines, p.indent(level)+end)
+	}
+	return lines
+}
+
+func (p *printer) blockParameter(blockName string, i int, parameter string) string {
+	parameter = strings.TrimSpace(parameter)
+	switch {
+	case i == 0 && (blockName == "if" || blockName == "else if" || blockName == "switch" || blockName == "case"):
+		return p.expression(parameter, false)
+	case i == 0 && blockName == "for":
+		if m := forOfParameter.FindStringSubmatch(parameter); m != nil {
+			return m[1] + " of " + p.expression(m[2], false)
+		}
+	case blockName == "for":
+		if m := trackParameter.FindStringSubmatch(parameter); m != nil {
+			return "track " + p.expression(m[1], false)
+		}
+	case blockName == "if" || blockName == "else if":
+		if m := asParameter.FindStringSubmatch(parameter); m != nil {
+			return "as " + m[1]
+		}
+	}
+	return parameter
+}
+
+// attribute formats an attribute. The expressions of bindings and event handlers are formatted,
+// and the values are quoted with double quotes, unless they contain some.
+func (p *printer) attribute(attr *ml_parser.Attribute) string {
+	if attr.ValueSpan == nil || attr.KeySpan == nil {
+		return p.text(attr.SourceSpan())
+	}
+	name := p.text(attr.KeySpan)
+	raw := p.text(attr.ValueSpan)
+	value := raw
+	switch {
+	case strings.HasPrefix(name, "[") || strings.HasPrefix(name, "bind-") || strings.HasPrefix(name, "bindon-"):
+		value = p.expression(raw, false)
+	case strings.HasPrefix(name, "(") || strings.HasPrefix(name, "on-"):
+		value = p.expression(raw, true)
+	}
+	quote := `"`
+	if strings.Contains(value, quote) {
+		quote = "'"
+	}
+	if strings.Contains(value, quote) {
+		return p.text(attr.SourceSpan())
+	}
+	return name + "=" + quote + value + quote
+}
+
+// interpolation formats an interpolation of a text.
+func (p *printer) interpolation(token ml_parser.Token) string {
+	parts := token.Parts()
+	if len(parts) != 3 || parts[2] == "" {
+		return p.text(token.SourceSpan())
+	}
+	expression := p.expression(parts[1], false)
+	if expression == "" {
+		return p.text(token.SourceSpan())
+	}
+	return parts[0] + " " + expression + " " + parts[2]
+}
+
+// expression formats an expression, which is returned trimmed but otherwise unchanged when it
+// cannot be parsed, or when formatting it changes more than its whitespace, as the serialization
+// of some expressions is lossy, e.g. the arguments of pipes.
+func (p *printer) expression(text string, action bool) (formatted string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return text
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			formatted = text
+		}
+	}()
+	var ast *expression_parser.ASTWithSource
+	if action {
+		ast = p.parser.ParseAction(text, nil, 0)
+	} else {
+		ast = p.parser.ParseBinding(text, nil, 0)
+	}
+	if len(ast.Errors) > 0 {
+		return text
+	}
+	formatted = expression_parser.Serialize(ast)
+	if !p.sameTokens(text, formatted) {
+		return text
+	}
+	return formatted
+}
+
+// sameTokens reports whether two expressions only differ by their whitespace and quotes.
+func (p *printer) sameTokens(a, b string) bool {
+	tokensA, tokensB := p.lexer.Tokenize(a), p.lexer.Tokenize(b)
+	if len(tokensA) != len(tokensB) {
+		return false
+	}
+	for i, tokenA := range tokensA {
+		tokenB := tokensB[i]
+		if tokenA.Type != tokenB.Type || tokenA.StrValue != tokenB.StrValue ||
+			tokenA.NumValue != tokenB.NumValue || tokenA.StringKind != tokenB.StringKind {
+			return false
+		}
+	}
+	return true
+}
+
+// tagName returns the name of an element as it is written, with its namespace prefix.
+func (p *printer) tagName(el *ml_parser.Element) string {
+	tag := p.text(el.StartSourceSpan)[1:]
+	if i := strings.IndexAny(tag, " \t\n\r\f/>"); i >= 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
+
+func (p *printer) text(span *util.ParseSourceSpan) string {
+	return p.source[span.Start.Offset:span.End.Offset]
+}
+
+func (p *printer) indent(level int) string {
+	return p.base + strings.Repeat(" ", level*p.options.IndentSize)
+}
+
+// fits reports whether a line fits at a level.
+func (p *printer) fits(level int, line string) bool {
+	return !strings.Contains(line, "\n") && len(p.indent(level))+utf8.RuneCountInString(line) <= p.options.Width
+}
+
+// width returns the width of a line of a layout at a level.
+func (p *printer) width(lines []string, i int, level int) int {
+	if i == 0 {
+		return len(p.indent(level)) + utf8.RuneCountInString(lines[0])
+	}
+	return utf8.RuneCountInString(lines[i])
+}
+
+// isWhitespace reports whether a character is whitespace for the compiler, which, unlike
+// unicode.IsSpace, excludes non-breaking spaces.
+func isWhitespace(r rune) bool {
+	switch r {
+	case ' ', '\f', '\n', '\r', '\t', '\v', '\u1680', '\u180e', '\u2028', '\u2029', '\u202f', '\u205f', '\u3000', '\ufeff':
+		return true
+	}
+	return r >= '\u2000' && r <= '\u200a'
+}
@@ -0,0 +1,536 @@
+// Package migrations rewrites the sources of a project to newer Angular APIs.
+package migrations
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/ml_parser"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// Problem is a use of a structural directive which is left unchanged by a migration.
+type Problem struct {
+	FileName string
+	// Line and Col are zero-based.
+	Line, Col int
+	Message   string
+}
+
+func (p *Problem) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", p.FileName, p.Line+1, p.Col+1, p.Message)
+}
+
+// ControlFlowMigration is the result of migrating the templates of a file to the built-in control
+// flow.
+type ControlFlowMigration struct {
+	// Content is the file with its templates migrated.
+	Content string
+	// Migrated is the number of structural directives replaced by blocks.
+	Migrated int
+	Problems []*Problem
+}
+
+// MigrateControlFlow replaces the `*ngIf`, `*ngFor` and `[ngSwitch]` directives of the templates
+// of a file, an external template (.html) or the inline templates of the components of a
+// TypeScript file, with `@if`, `@for` and `@switch` blocks. Only the directives are rewritten, the
+// rest of the file is kept as it is. The uses of the directives which cannot be migrated are
+// reported and left unchanged.
+func MigrateControlFlow(fileName string, content string) *ControlFlowMigration {
+	m := &ControlFlowMigration{}
+	r := newRewriter(content)
+	switch {
+	case strings.HasSuffix(fileName, ".html"):
+		m.migrateTemplate(r, fileName, nil)
+	case strings.HasSuffix(fileName, ".ts"):
+		for _, expr := range annotations.InlineTemplates(reflection.ReflectSourceFile(fileName, content)) {
+			// Inline templates are parsed in place, so that the spans of the template point into the
+			// file.
+			start := expr.Start + 1
+			line := strings.Count(content[:start], "\n")
+			m.migrateTemplate(r, fileName, &ml_parser.LexerRange{
+				StartPos:  start,
+				StartLine: line,
+				StartCol:  start - strings.LastIndex(content[:start], "\n") - 1,
+				EndPos:    expr.End - 1,
+			})
+		}
+	}
+	m.Content = r.render(0, len(content))
+	return m
+}
+
+func (m *ControlFlowMigration) migrateTemplate(r *rewriter, fileName string, lexerRange *ml_parser.LexerRange) {
+	enabled := true
+	options := &ml_parser.TokenizeOptions{
+		TokenizeExpansionForms: &enabled,
+		TokenizeBlocks:         &enabled,
+		TokenizeLet:            &enabled,
+		Range:                  lexerRange,
+	}
+	if lexerRange != nil {
+		options.EscapedString = &enabled
+	}
+	result := ml_parser.NewHtmlParser().Parse(r.source, fileName, options)
+	if len(result.Errors) > 0 {
+		m.report(fileName, result.Errors[0].Span, "the template has errors and is not migrated: "+result.Errors[0].Msg)
+		return
+	}
+	c := &controlFlowMigrator{
+		ControlFlowMigration: m,
+		r:                    r,
+		fileName:             fileName,
+		templates:            make(map[string]*ml_parser.Element),
+		references:           make(map[string]int),
+		cases:                make(map[*ml_parser.Element]bool),
+		moved:                make(map[*ml_parser.Element]bool),
+	}
+	c.collect(result.RootNodes)
+	c.visitAll(result.RootNodes)
+}
+
+func (m *ControlFlowMigration) report(fileName string, span *util.ParseSourceSpan, message string) {
+	m.Problems = append(m.Problems, &Problem{FileName: fileName, Line: span.Start.Line, Col: span.Start.Col, Message: message})
+}
+
+// forContextVariables maps the context variables of NgForOf to the ones of `@for`.
+var forContextVariables = map[string]string{
+	"index": "$index",
+	"count": "$count",
+	"first": "$first",
+	"last":  "$last",
+	"even":  "$even",
+	"odd":   "$odd",
+}
+
+var identifier = regexp.MustCompile(`^[a-zA-Z_$][\w$]*$`)
+
+// controlFlowMigrator migrates a template.
+type controlFlowMigrator struct {
+	*ControlFlowMigration
+	r        *rewriter
+	fileName string
+	// templates are the `<ng-template>`s by reference name, nil when the name is declared more than
+	// once. references counts the uses of the names in bindings.
+	templates  map[string]*ml_parser.Element
+	references map[string]int
+	// cases are the `*ngSwitchCase` and `*ngSwitchDefault` elements of the migrated switches, which
+	// are migrated with them.
+	cases map[*ml_parser.Element]bool
+	// moved are the templates whose content has been moved into a block.
+	moved map[*ml_parser.Element]bool
+}
+
+// collect finds the templates and the bindings which may use them.
+func (c *controlFlowMigrator) collect(nodes []ml_parser.Node) {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *ml_parser.Element:
+			for _, attr := range n.Attrs {
+				if name, ok := referenceName(attr); ok && n.Name == "ng-template" {
+					if _, seen := c.templates[name]; seen {
+						c.templates[name] = nil
+					} else {
+						c.templates[name] = n
+					}
+				}
+				c.countReferences(attr.Value)
+			}
+			c.collect(n.Children)
+		case *ml_parser.Block:
+			for _, param := range n.Parameters {
+				c.countReferences(param.Expression)
+			}
+			c.collect(n.Children)
+		case *ml_parser.Text:
+			if strings.Contains(n.Value, "{{") {
+				c.countReferences(n.Value)
+			}
+		case *ml_parser.Expansion:
+			c.countReferences(n.SwitchValue)
+			for _, expansionCase := range n.Cases {
+				c.collect(expansionCase.Expression)
+			}
+		}
+	}
+}
+
+var identifiers = regexp.MustCompile(`[a-zA-Z_$][\w$]*`)
+
+func (c *controlFlowMigrator) countReferences(expression string) {
+	for _, name := range identifiers.FindAllString(expression, -1) {
+		c.references[name]++
+	}
+}
+
+// referenceName returns the name of a template reference declared by an attribute, `#name` or
+// `ref-name`.
+func referenceName(attr *ml_parser.Attribute) (string, bool) {
+	switch {
+	case strings.HasPrefix(attr.Name, "#"):
+		return attr.Name[1:], true
+	case strings.HasPrefix(attr.Name, "ref-"):
+		return attr.Name[4:], true
+	}
+	return "", false
+}
+
+func (c *controlFlowMigrator) visitAll(nodes []ml_parser.Node) {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *ml_parser.Element:
+			c.visitElement(n)
+		case *ml_parser.Block:
+			c.visitAll(n.Children)
+		case *ml_parser.Expansion:
+			for _, expansionCase := range n.Cases {
+				c.visitAll(expansionCase.Expression)
+			}
+		}
+	}
+}
+
+func (c *controlFlowMigrator) visitElement(el *ml_parser.Element) {
+	children := func() { c.visitAll(el.Children) }
+	body := children
+	if attr := findAttribute(el, "[ngSwitch]"); attr != nil {
+		body = func() { c.migrateSwitch(el, attr, children) }
+	}
+	if el.Name == "ng-template" {
+		for _, attr := range el.Attrs {
+			switch attr.Name {
+			case "[ngIf]", "ngIf", "ngFor", "[ngForOf]", "[ngSwitchCase]", "ngSwitchDefault":
+				c.report(c.fileName, attr.SourceSpan(), fmt.Sprintf("%s on <ng-template> is not migrated, use *%s on an element instead",
+					attr.Name, strings.TrimSuffix(strings.Trim(attr.Name, "[]"), "Of")))
+			}
+		}
+	}
+	for _, attr := range el.Attrs {
+		switch attr.Name {
+		case "*ngIf":
+			c.migrateIf(el, attr, body)
+			return
+		case "*ngFor":
+			c.migrateFor(el, attr, body)
+			return
+		case "*ngSwitchCase", "*ngSwitchDefault":
+			if !c.cases[el] {
+				if _, ok := c.cases[el]; !ok {
+					c.report(c.fileName, attr.SourceSpan(), attr.Name+" is not a direct child of an element with [ngSwitch]")
+				}
+				body()
+				return
+			}
+			open := "@default {"
+			if attr.Name == "*ngSwitchCase" {
+				open = fmt.Sprintf("@case (%s) {", strings.TrimSpace(c.value(attr)))
+			}
+			c.wrap(el, attr, []piece{text(open)}, []piece{text("}")}, body)
+			return
+		}
+	}
+	body()
+}
+
+// migrateIf replaces `*ngIf="condition; else elseTemplate"` by `@if (condition) {…} @else {…}`.
+func (c *controlFlowMigrator) migrateIf(el *ml_parser.Element, attr *ml_parser.Attribute, body func()) {
+	var condition, alias, thenName, elseName string
+	bindings, ok := c.parseBindings(attr)
+	for _, binding := range bindings {
+		switch b := binding.(type) {
+		case *expression_parser.ExpressionBinding:
+			if b.Value == nil {
+				continue
+			}
+			switch b.Key.Source {
+			case "ngIf":
+				condition = *b.Value.Source
+			case "ngIfThen":
+				thenName = *b.Value.Source
+			case "ngIfElse":
+				elseName = *b.Value.Source
+			default:
+				c.report(c.fileName, attr.SourceSpan(), "unknown *ngIf binding "+b.Key.Source)
+				ok = false
+			}
+		case *expression_parser.VariableBinding:
+			if b.Value != nil && b.Value.Source != "ngIf" && b.Value.Source != "$implicit" {
+				c.report(c.fileName, attr.SourceSpan(), "unknown *ngIf context variable "+b.Value.Source)
+				ok = false
+			}
+			alias = b.Key.Source
+		}
+	}
+	for _, name := range []string{thenName, elseName} {
+		if name != "" && !c.checkTemplate(el, attr, name) {
+			ok = false
+		}
+	}
+	if !ok || condition == "" {
+		body()
+		return
+	}
+	c.Migrated++
+
+	open := "@if (" + condition
+	if alias != "" {
+		open += "; as " + alias
+	}
+	open += ") {"
+	var close []piece
+	if elseName != "" {
+		close = append([]piece{text("} @else {")}, c.templateContent(elseName)...)
+	}
+	close = append(close, text("}"))
+	if thenName == "" {
+		c.wrap(el, attr, []piece{text(open)}, close, body)
+		return
+	}
+	// The content of the element is ignored with a then template.
+	pieces := append([]piece{text(open)}, c.templateContent(thenName)...)
+	c.r.replace(el.SourceSpan().Start.Offset, el.SourceSpan().End.Offset, append(pieces, close...)...)
+}
+
+// checkTemplate checks that the template named by an `*ngIf` can be moved into a block.
+func (c *controlFlowMigrator) checkTemplate(el *ml_parser.Element, attr *ml_parser.Attribute, name string) bool {
+	if !identifier.MatchString(name) {
+		c.report(c.fileName, attr.SourceSpan(), fmt.Sprintf("%s is not the name of an <ng-template>", name))
+		return false
+	}
+	template, ok := c.templates[name]
+	switch {
+	case !ok:
+		c.report(c.fileName, attr.SourceSpan(), fmt.Sprintf("<ng-template #%s> is not in the template", name))
+		return false
+	case template == nil:
+		c.report(c.fileName, attr.SourceSpan(), fmt.Sprintf("<ng-template #%s> is declared more than once", name))
+		return false
+	case contains(template, el):
+		c.report(c.fileName, attr.SourceSpan(), fmt.Sprintf("*ngIf is within <ng-template #%s>", name))
+		return false
+	}
+	for _, templateAttr := range template.Attrs {
+		if _, ok := referenceName(templateAttr); !ok {
+			c.report(c.fileName, attr.SourceSpan(), fmt.Sprintf("<ng-template #%s> has the attribute %s", name, templateAttr.Name))
+			return false
+		}
+	}
+	return true
+}
+
+// templateContent returns the content of a template checked by checkTemplate. Templates which
+// are only used by the `*ngIf` are moved into the block, the other ones are rendered by an outlet.
+func (c *controlFlowMigrator) templateContent(name string) []piece {
+	template := c.templates[name]
+	if c.references[name] > 1 || c.moved[template] {
+		return []piece{text(fmt.Sprintf(`<ng-container *ngTemplateOutlet="%s"></ng-container>`, name))}
+	}
+	c.moved[template] = true
+	c.r.remove(template.SourceSpan().Start.Offset, template.SourceSpan().End.Offset)
+	if template.EndSourceSpan == nil || template.IsSelfClosing {
+		return nil
+	}
+	return []piece{moved(template.StartSourceSpan.End.Offset, template.EndSourceSpan.Start.Offset)}
+}
+
+// migrateFor replaces `*ngFor="let item of items; trackBy: trackFn; index as i"` by
+// `@for (item of items; track trackFn($index, item); let i = $index) {…}`.
+func (c *controlFlowMigrator) migrateFor(el *ml_parser.Element, attr *ml_parser.Attribute, body func()) {
+	var item, items, trackBy string
+	var aliases []string
+	bindings, ok := c.parseBindings(attr)
+	for _, binding := range bindings {
+		switch b := binding.(type) {
+		case *expression_parser.ExpressionBinding:
+			if b.Value == nil {
+				continue
+			}
+			switch b.Key.Source {
+			case "ngForOf":
+				items = *b.Value.Source
+			case "ngForTrackBy":
+				trackBy = *b.Value.Source
+			default:
+				c.report(c.fileName, attr.SourceSpan(), "unknown *ngFor binding "+b.Key.Source)
+				ok = false
+			}
+		case *expression_parser.VariableBinding:
+			if b.Value == nil || b.Value.Source == "$implicit" {
+				if item != "" {
+					c.report(c.fileName, attr.SourceSpan(), "*ngFor declares the item more than once")
+					ok = false
+				}
+				item = b.Key.Source
+				continue
+			}
+			variable, known := forContextVariables[b.Value.Source]
+			if !known {
+				c.report(c.fileName, attr.SourceSpan(), "unknown *ngFor context variable "+b.Value.Source)
+				ok = false
+			}
+			aliases = append(aliases, b.Key.Source+" = "+variable)
+		}
+	}
+	if ok && (item == "" || items == "") {
+		c.report(c.fileName, attr.SourceSpan(), "*ngFor does not declare the item and the collection")
+		ok = false
+	}
+	if !ok {
+		body()
+		return
+	}
+	c.Migrated++
+
+	track := item
+	if trackBy != "" {
+		track = fmt.Sprintf("%s($index, %s)", trackBy, item)
+	}
+	open := fmt.Sprintf("@for (%s of %s; track %s", item, items, track)
+	if len(aliases) > 0 {
+		open += "; let " + strings.Join(aliases, ", ")
+	}
+	open += ") {"
+	c.wrap(el, attr, []piece{text(open)}, []piece{text("}")}, body)
+}
+
+// migrateSwitch puts the content of an element with `[ngSwitch]="value"` into a
+// `@switch (value) {…}` block. Its children must all be cases, which are migrated by visitElement.
+func (c *controlFlowMigrator) migrateSwitch(el *ml_parser.Element, attr *ml_parser.Attribute, children func()) {
+	var cases []*ml_parser.Element
+	ok := true
+	for _, child := range el.Children {
+		switch n := child.(type) {
+		case *ml_parser.Comment:
+			continue
+		case *ml_parser.Text:
+			if strings.TrimSpace(n.Value) == "" {
+				continue
+			}
+		case *ml_parser.Element:
+			if findAttribute(n, "*ngSwitchCase") != nil || findAttribute(n, "*ngSwitchDefault") != nil {
+				cases = append(cases, n)
+				continue
+			}
+		}
+		if ok {
+			c.report(c.fileName, child.SourceSpan(), "the children of [ngSwitch] must all be *ngSwitchCase or *ngSwitchDefault elements")
+			ok = false
+		}
+	}
+	for _, child := range cases {
+		c.cases[child] = ok
+	}
+	if !ok || !c.checkCases(el.Children, attr) {
+		for _, child := range cases {
+			c.cases[child] = false
+		}
+		children()
+		return
+	}
+	c.Migrated++
+
+	open := fmt.Sprintf("@switch (%s) {", strings.TrimSpace(c.value(attr)))
+	if isBareContainer(el) && el.EndSourceSpan != nil && !el.IsSelfClosing {
+		c.r.replace(el.StartSourceSpan.Start.Offset, el.StartSourceSpan.End.Offset, text(open))
+		children()
+		c.r.replace(el.EndSourceSpan.Start.Offset, el.EndSourceSpan.End.Offset, text("}"))
+		return
+	}
+	c.r.removeAttribute(attr.SourceSpan().Start.Offset, attr.SourceSpan().End.Offset)
+	c.r.insert(el.StartSourceSpan.End.Offset, text(open))
+	children()
+	if el.EndSourceSpan != nil {
+		c.r.insert(el.EndSourceSpan.Start.Offset, text("}"))
+	} else {
+		c.r.insert(el.StartSourceSpan.End.Offset, text("}"))
+	}
+}
+
+// checkCases checks that the cases of a switch are not nested deeper than its children, where
+// they would be left without their switch.
+func (c *controlFlowMigrator) checkCases(children []ml_parser.Node, attr *ml_parser.Attribute) bool {
+	for _, child := range children {
+		el, ok := child.(*ml_parser.Element)
+		if !ok || findAttribute(el, "[ngSwitch]") != nil {
+			continue
+		}
+		for _, grandChild := range el.Children {
+			nested, ok := grandChild.(*ml_parser.Element)
+			if !ok {
+				continue
+			}
+			if findAttribute(nested, "*ngSwitchCase") != nil || findAttribute(nested, "*ngSwitchDefault") != nil {
+				c.report(c.fileName, attr.SourceSpan(), "[ngSwitch] has cases which are not its direct children")
+				return false
+			}
+			if !c.checkCases([]ml_parser.Node{nested}, attr) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// wrap puts an element into a block, removing the directive attribute. A bare `<ng-container>` is
+// replaced by the block. body visits the children of the element, between the start and the end
+// of the block.
+func (c *controlFlowMigrator) wrap(el *ml_parser.Element, attr *ml_parser.Attribute, open []piece, close []piece, body func()) {
+	if isBareContainer(el) {
+		if el.EndSourceSpan == nil || el.IsSelfClosing {
+			c.r.replace(el.SourceSpan().Start.Offset, el.SourceSpan().End.Offset, append(open, close...)...)
+			return
+		}
+		c.r.replace(el.StartSourceSpan.Start.Offset, el.StartSourceSpan.End.Offset, open...)
+		body()
+		c.r.replace(el.EndSourceSpan.Start.Offset, el.EndSourceSpan.End.Offset, close...)
+		return
+	}
+	c.r.insert(el.SourceSpan().Start.Offset, open...)
+	c.r.removeAttribute(attr.SourceSpan().Start.Offset, attr.SourceSpan().End.Offset)
+	body()
+	c.r.insert(el.SourceSpan().End.Offset, close...)
+}
+
+// parseBindings parses the microsyntax of a structural directive.
+func (c *controlFlowMigrator) parseBindings(attr *ml_parser.Attribute) ([]expression_parser.TemplateBinding, bool) {
+	parser := expression_parser.NewParser(expression_parser.NewLexer(), false)
+	result := parser.ParseTemplateBindings(attr.Name[1:], c.value(attr), attr.SourceSpan(), 0, 0)
+	if len(result.Errors) > 0 {
+		c.report(c.fileName, attr.SourceSpan(), fmt.Sprintf("cannot parse %s: %s", attr.Name, result.Errors[0].Msg))
+		return nil, false
+	}
+	return result.TemplateBindings, true
+}
+
+// value returns the source text of the value of an attribute, which, unlike its value, can be
+// copied into the template.
+func (c *controlFlowMigrator) value(attr *ml_parser.Attribute) string {
+	if attr.ValueSpan == nil {
+		return ""
+	}
+	return c.r.source[attr.ValueSpan.Start.Offset:attr.ValueSpan.End.Offset]
+}
+
+func findAttribute(el *ml_parser.Element, name string) *ml_parser.Attribute {
+	for _, attr := range el.Attrs {
+		if attr.Name == name {
+			return attr
+		}
+	}
+	return nil
+}
+
+// isBareContainer reports whether an element is an `<ng-container>` with a single attribute, the
+// directive, which is not needed once the directive is a block.
+func isBareContainer(el *ml_parser.Element) bool {
+	return el.Name == "ng-container" && len(el.Attrs) == 1 && len(el.Directives) == 0
+}
+
+func contains(ancestor *ml_parser.Element, node ml_parser.Node) bool {
+	return ancestor.SourceSpan().Start.Offset <= node.SourceSpan().Start.Offset &&
+		node.SourceSpan().End.Offset <= ancestor.SourceSpan().End.Offset
+}
@@ -0,0 +1,116 @@
+package migrations
+
+import (
+	"sort"
+	"strings"
+)
+
+// piece is a part of the replacement of an edit: text, or a range of the source which is copied
+// with the edits within it applied, to move code.
+type piece struct {
+	text       string
+	start, end int
+	moved      bool
+}
+
+func text(s string) piece {
+	return piece{text: s}
+}
+
+func moved(start, end int) piece {
+	return piece{start: start, end: end, moved: true}
+}
+
+// edit replaces the range [start, end) of the source, inserting when it is empty.
+type edit struct {
+	start, end int
+	pieces     []piece
+}
+
+// rewriter collects the edits of a source and applies them. Edits either nest or are disjoint:
+// the edits within a replaced range are dropped, unless the range is moved elsewhere.
+type rewriter struct {
+	source string
+	edits  []*edit
+	sorted bool
+}
+
+func newRewriter(source string) *rewriter {
+	return &rewriter{source: source}
+}
+
+func (r *rewriter) replace(start, end int, pieces ...piece) {
+	r.edits = append(r.edits, &edit{start: start, end: end, pieces: pieces})
+	r.sorted = false
+}
+
+func (r *rewriter) insert(at int, pieces ...piece) {
+	r.replace(at, at, pieces...)
+}
+
+// remove deletes a range. When it is alone on its lines, the lines are deleted.
+func (r *rewriter) remove(start, end int) {
+	lineStart := start
+	for lineStart > 0 && isBlank(r.source[lineStart-1]) {
+		lineStart--
+	}
+	lineEnd := end
+	for lineEnd < len(r.source) && (isBlank(r.source[lineEnd]) || r.source[lineEnd] == '\r') {
+		lineEnd++
+	}
+	if (lineStart == 0 || r.source[lineStart-1] == '\n') && lineEnd < len(r.source) && r.source[lineEnd] == '\n' {
+		start, end = lineStart, lineEnd+1
+	}
+	r.replace(start, end)
+}
+
+// removeAttribute deletes an attribute of an element with the whitespace before it.
+func (r *rewriter) removeAttribute(start, end int) {
+	for start > 0 && isSpace(r.source[start-1]) {
+		start--
+	}
+	r.replace(start, end)
+}
+
+// render returns the range [start, end) of the source with the edits within it applied.
+// Insertions at end are part of the range.
+func (r *rewriter) render(start, end int) string {
+	if !r.sorted {
+		// Insertions go before the replacements starting at the same offset, each in the order
+		// they were made.
+		sort.SliceStable(r.edits, func(i, j int) bool {
+			a, b := r.edits[i], r.edits[j]
+			if a.start != b.start {
+				return a.start < b.start
+			}
+			return a.start == a.end && b.start != b.end
+		})
+		r.sorted = true
+	}
+	var b strings.Builder
+	pos := start
+	for _, e := range r.edits {
+		if e.start < pos || e.end > end {
+			continue
+		}
+		b.WriteString(r.source[pos:e.start])
+		for _, p := range e.pieces {
+			if p.moved {
+				b.WriteString(r.render(p.start, p.end))
+			} else {
+				b.WriteString(p.text)
+			}
+		}
+		pos = e.end
+	}
+	b.WriteString(r.source[pos:end])
+	return b.String()
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func isSpace(c byte) bool {
+	return isBlank(c) || c == '\n' || c == '\r'
+}
@@ -0,0 +1,97 @@
+// Package annotations analyzes the Angular decorators of reflected classes and compiles them into
+// the static definitions of the runtime, e.g. `ɵcmp` and `ɵfac`.
+package annotations
+
+import (
+	"fmt"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck/extended"
+	"ngc-go/packages/compiler/src/output"
+)
+
+// CompilationMode selects the kind of definitions which are emitted for decorated classes.
+type CompilationMode string
+
+const (
+	// CompilationModeFull emits definitions which the runtime executes directly, e.g.
+	// `ɵɵdefineComponent`.
+	CompilationModeFull CompilationMode = "full"
+	// CompilationModePartial emits stable declarations, e.g. `ɵɵngDeclareComponent`, which are
+	// linked into full definitions when an application consuming a library is built.
+	CompilationModePartial CompilationMode = "partial"
+)
+
+// ParseCompilationMode parses the value of `--compilation-mode`.
+func ParseCompilationMode(value string) (CompilationMode, error) {
+	switch mode := CompilationMode(value); mode {
+	case CompilationModeFull, CompilationModePartial:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown compilation mode %q, expected full or partial", value)
+}
+
+// CompileResult is a static field added to a compiled class.
+type CompileResult struct {
+	// Name is the name of the field, e.g. `ɵcmp`.
+	Name        string
+	Initializer output.OutputExpression
+	// Statements are emitted after the class.
+	Statements []output.OutputStatement
+	Type       output.Type
+}
+
+// CompiledClass holds the compilation results of a decorated class.
+type CompiledClass struct {
+	Class   *reflection.ClassDeclaration
+	Results []CompileResult
+	// Metadata records the decorators of the class for TestBed, e.g.
+	// `ɵɵngDeclareClassMetadata(...)`.
+	Metadata output.OutputExpression
+}
+
+// ResourceLoader reads the external resources of components: templates and stylesheets. Paths
+// are resolved against the directory of the component file.
+type ResourceLoader func(path string) (string, error)
+
+// Options configures a Compiler.
+type Options struct {
+	// RootDir is the directory file names are made relative to in the generated code.
+	RootDir string
+	// ResourceLoader reads external templates and stylesheets.
+	ResourceLoader ResourceLoader
+	// Hmr makes EmitFullModule emit an HMR initializer for each component, which applies the
+	// update modules of HmrUpdates to the running application.
+	Hmr bool
+	// TemplateCache reuses the templates parsed by earlier compilers. Templates are parsed
+	// anew when nil.
+	TemplateCache *TemplateCache
+	// DomOnly compiles the templates of the components declared by NgModules with the DOM-only
+	// instruction set when they match no directives, as is done for standalone components. The
+	// scope of the NgModule must not import NgModules of packages whose declaration files cannot
+	// be read, as their directives are unknown, nor elements which are not statically understood,
+	// e.g. `...SHARED`.
+	DomOnly bool
+	// ClosureCompiler annotates the code of the full compilation for the advanced optimizations
+	// of Closure Compiler: the static fields holding the definitions are marked `@nocollapse`, the
+	// constants of JavaScript files `@const`, and long strings shared by the definitions are
+	// returned by functions, which Closure does not inline at every use as it does strings.
+	ClosureCompiler bool
+	// CheckTemplates makes Analyze validate the templates of components against the DOM schema
+	// and the directives in their scopes, and report the unknown elements, properties and events.
+	// The templates of scopes importing NgModules whose directives are unknown are not checked.
+	CheckTemplates bool
+	// StrictNullChecks adds the null-safety checks of `strictTemplates` to CheckTemplates: safe
+	// navigation and non-null assertions on values which are never null, as far as the
+	// declarations of the members of components tell.
+	StrictNullChecks bool
+	// ExtendedDiagnostics runs the extended diagnostics with the given `extendedDiagnostics`
+	// options over the templates of components, as for CheckTemplates. Analyze reports the
+	// invalid options. They are not run when nil.
+	ExtendedDiagnostics *extended.Options
+	// TypeCheckBlocks makes Analyze generate the TypeScript type-check blocks of the templates of
+	// components, which TypeCheckFile returns by file. Type-checking them with tsc reports the
+	// type errors of the template expressions. As for CheckTemplates, the templates of scopes with
+	// unknown directives are left out.
+	TypeCheckBlocks bool
+}
@@ -0,0 +1,434 @@
+package annotations
+
+import (
+	"path"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck/extended"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/partial"
+	render3_injector_compiler "ngc-go/packages/compiler/src/render3/r3_injector_compiler"
+	render3_module_compiler "ngc-go/packages/compiler/src/render3/r3_module_compiler"
+	"ngc-go/packages/compiler/src/render3/view"
+	"ngc-go/packages/compiler/src/schema"
+)
+
+// decoratorKind is the Angular decorator of a class.
+type decoratorKind int
+
+const (
+	kindComponent decoratorKind = iota
+	kindDirective
+	kindPipe
+	kindNgModule
+	kindInjectable
+)
+
+// decoratorKinds maps the names of the class decorators in `@angular/core` to their kind.
+var decoratorKinds = map[string]decoratorKind{
+	"Component":  kindComponent,
+	"Directive":  kindDirective,
+	"Pipe":       kindPipe,
+	"NgModule":   kindNgModule,
+	"Injectable": kindInjectable,
+}
+
+// analyzedClass is a class with an Angular decorator and the metadata read from it.
+type analyzedClass struct {
+	file      *sourceFile
+	class     *reflection.ClassDeclaration
+	decorator *reflection.Decorator
+	kind      decoratorKind
+	// deps are the constructor dependencies: []render3.R3DependencyMetadata, "invalid" or nil.
+	deps interface{}
+
+	directive  *view.R3DirectiveMetadata
+	component  *componentAnalysis
+	pipe       *render3.R3PipeMetadata
+	ngModule   *ngModuleAnalysis
+	injectable *partial.R3InjectableMetadata
+	// matchable is the directive as seen by the template binder of other components.
+	matchable *directiveMeta
+	// template is the template of a component as tools see it, recorded even when it does not
+	// compile.
+	template *componentTemplate
+
+	metadata partial.R3ClassMetadata
+}
+
+// Compiler analyzes the decorated classes of a program and compiles them.
+type Compiler struct {
+	options Options
+	files   []*sourceFile
+	byName  map[string]*sourceFile
+	classes []*analyzedClass
+	byClass map[*reflection.ClassDeclaration]*analyzedClass
+	diags   []*diagnostics.Diagnostic
+	// reportedElements are the elements of the imports and exports reported for the scope of a
+	// component, which are not reported again for the other ones.
+	reportedElements map[*reflection.Expression]bool
+	// declarationFiles, externalClasses and typings cache the declaration files of packages, the
+	// classes read from them by file and name, and the entry points of packages by specifier.
+	declarationFiles map[string]*reflection.SourceFile
+	externalClasses  map[string]*externalClass
+	typings          map[string]string
+	// registry is the DOM schema the templates are checked against, created on first use.
+	registry *schema.DomElementSchemaRegistry
+	// typeCheckFiles are the type-check blocks of the components of the files.
+	typeCheckFiles map[*sourceFile]*typecheck.TypeCheckFile
+	// extendedChecker runs the extended diagnostics configured by Options.ExtendedDiagnostics.
+	extendedChecker *extended.ExtendedTemplateChecker
+}
+
+// NewCompiler creates a compiler for the given source files.
+func NewCompiler(files []*reflection.SourceFile, options Options) *Compiler {
+	c := &Compiler{
+		options: options,
+		byName:  make(map[string]*sourceFile),
+		byClass: make(map[*reflection.ClassDeclaration]*analyzedClass),
+
+		reportedElements: make(map[*reflection.Expression]bool),
+		declarationFiles: make(map[string]*reflection.SourceFile),
+		externalClasses:  make(map[string]*externalClass),
+		typings:          make(map[string]string),
+		typeCheckFiles:   make(map[*sourceFile]*typecheck.TypeCheckFile),
+	}
+	for _, sf := range files {
+		f := newSourceFile(sf)
+		c.files = append(c.files, f)
+		c.byName[path.Clean(sf.FileName)] = f
+	}
+	return c
+}
+
+// Analyze reads the decorators of all classes and resolves the template scopes of components.
+// It returns the problems found.
+func (c *Compiler) Analyze() []*diagnostics.Diagnostic {
+	for _, f := range c.files {
+		for _, class := range f.Classes {
+			c.registerClass(f, class)
+		}
+	}
+	// Decorators are read once all decorated classes are known, so that references between them
+	// can be resolved.
+	for _, ac := range c.classes {
+		c.analyzeClass(ac)
+	}
+	if c.options.ExtendedDiagnostics != nil {
+		var configDiags []*diagnostics.Diagnostic
+		c.extendedChecker, configDiags = extended.NewExtendedTemplateChecker(extended.AllChecks(), c.options.ExtendedDiagnostics)
+		c.diags = append(c.diags, configDiags...)
+	}
+	// Templates are bound once all directives and pipes are known.
+	for _, ac := range c.classes {
+		if ac.component != nil {
+			c.resolveComponentScope(ac)
+		}
+	}
+	return c.diags
+}
+
+// registerClass records a class if it has an Angular decorator.
+func (c *Compiler) registerClass(f *sourceFile, class *reflection.ClassDeclaration) {
+	var ac *analyzedClass
+	for _, dec := range class.Decorators {
+		kind, ok := decoratorKinds[f.coreName(dec.Name)]
+		if !ok {
+			continue
+		}
+		if ac != nil {
+			c.diags = append(c.diags, diagnostics.MakeDiagnostic(
+				diagnostics.DecoratorCollision, diagnostics.CategoryError, f.span(dec.Start, dec.End),
+				"Cannot combine Angular decorators on class '"+class.Name+"'."))
+			continue
+		}
+		ac = &analyzedClass{file: f, class: class, decorator: dec, kind: kind}
+	}
+	if ac == nil {
+		return
+	}
+	if class.Name == "" {
+		c.diags = append(c.diags, diagnostics.MakeDiagnostic(
+			diagnostics.DecoratorUnexpected, diagnostics.CategoryError, f.span(ac.decorator.Start, ac.decorator.End),
+			"Angular decorators are not supported on anonymous classes."))
+		return
+	}
+	c.classes = append(c.classes, ac)
+	c.byClass[class] = ac
+}
+
+// analyzeClass reads the Angular decorator of a class.
+func (c *Compiler) analyzeClass(ac *analyzedClass) {
+	ac.deps = c.constructorDeps(ac)
+	switch ac.kind {
+	case kindComponent:
+		c.analyzeComponent(ac)
+	case kindDirective:
+		c.analyzeDirective(ac)
+	case kindPipe:
+		c.analyzePipe(ac)
+	case kindNgModule:
+		c.analyzeNgModule(ac)
+	case kindInjectable:
+		c.analyzeInjectable(ac)
+	}
+	ac.metadata = c.classMetadata(ac)
+}
+
+// report records a problem at sf.Text[start:end].
+func (c *Compiler) report(f *sourceFile, code diagnostics.ErrorCode, start, end int, message string) {
+	c.diags = append(c.diags, diagnostics.MakeDiagnostic(code, diagnostics.CategoryError, f.span(start, end), message))
+}
+
+// decoratorArgument returns the object literal passed to a class decorator, which is nil when
+// the decorator is called without argument. ok is false when the decorator is used incorrectly,
+// which is reported.
+func (c *Compiler) decoratorArgument(ac *analyzedClass, required bool) (arg *reflection.Expression, ok bool) {
+	dec := ac.decorator
+	switch {
+	case dec.Args == nil:
+		c.report(ac.file, diagnostics.DecoratorNotCalled, dec.Start, dec.End, "Decorator '"+dec.Name+"' must be called.")
+		return nil, false
+	case len(dec.Args) == 0:
+		if required {
+			c.report(ac.file, diagnostics.DecoratorArityWrong, dec.Start, dec.End, "Decorator '"+dec.Name+"' expects exactly one argument.")
+			return nil, false
+		}
+		return nil, true
+	case len(dec.Args) > 1:
+		c.report(ac.file, diagnostics.DecoratorArityWrong, dec.Start, dec.End, "Decorator '"+dec.Name+"' expects at most one argument.")
+		return nil, false
+	case dec.Args[0].Kind != reflection.ExpressionObject:
+		arg := dec.Args[0]
+		c.report(ac.file, diagnostics.DecoratorArgNotLiteral, arg.Start, arg.End, "Decorator argument must be an object literal.")
+		return nil, false
+	}
+	return dec.Args[0], true
+}
+
+// resolveClass returns the analyzed class a local name of a file refers to, following relative
+// imports to the other files of the program.
+func (c *Compiler) resolveClass(f *sourceFile, name string) *analyzedClass {
+	if class := f.Class(name); class != nil {
+		return c.byClass[class]
+	}
+	imp, imported, ok := f.ImportOf(name)
+	if !ok || imported == "*" || !strings.HasPrefix(imp.Module, ".") {
+		return nil
+	}
+	target := c.resolveModule(f, imp.Module)
+	if target == nil {
+		return nil
+	}
+	for _, class := range target.Classes {
+		if class.Exported && (class.Name == imported || imported == "default" && class.Default) {
+			return c.byClass[class]
+		}
+	}
+	return nil
+}
+
+// resolveModule returns the file a relative module specifier refers to.
+func (c *Compiler) resolveModule(f *sourceFile, specifier string) *sourceFile {
+	base := path.Join(f.dir(), specifier)
+	base = strings.TrimSuffix(strings.TrimSuffix(base, ".js"), ".ts")
+	for _, candidate := range []string{base + ".ts", base + "/index.ts", base + ".js", base + "/index.js"} {
+		if target, ok := c.byName[candidate]; ok {
+			return target
+		}
+	}
+	return nil
+}
+
+// typeRef refers to a decorated class from the generated code of a file: through its local
+// name when the file declares or imports it, and through the module of the class otherwise.
+func (c *Compiler) typeRef(f *sourceFile, ac *analyzedClass) output.OutputExpression {
+	if ac.file == f {
+		return f.readVar(ac.class.Name)
+	}
+	for _, imp := range f.Imports {
+		for _, spec := range imp.Specifiers {
+			if !spec.TypeOnly && !imp.TypeOnly && c.resolveClass(f, spec.Name) == ac {
+				return f.readVar(spec.Name)
+			}
+		}
+	}
+	module := relativeModule(f.FileName, ac.file.FileName)
+	name := ac.class.Name
+	return output.NewExternalExpr(&output.ExternalReference{ModuleName: &module, Name: &name}, nil, nil, nil)
+}
+
+// reference refers to an element of an array of a decorator, e.g. `imports`: a decorated class
+// or any other expression.
+func (c *Compiler) reference(f *sourceFile, expr *reflection.Expression) render3.R3Reference {
+	var value output.OutputExpression
+	if expr.Kind == reflection.ExpressionIdentifier {
+		if ac := c.resolveClass(f, expr.Value); ac != nil {
+			value = c.typeRef(f, ac)
+		}
+	}
+	if value == nil {
+		value = f.wrap(expr)
+	}
+	return render3.R3Reference{Value: value, Type: value}
+}
+
+// relativeModule returns the specifier of the JavaScript module of a source file, relative to
+// the generated code of another one.
+func relativeModule(from, to string) string {
+	rel := relativePath(path.Dir(from), strings.TrimSuffix(strings.TrimSuffix(to, ".ts"), ".js")) + ".js"
+	if !strings.HasPrefix(rel, ".") {
+		rel = "./" + rel
+	}
+	return rel
+}
+
+// relativePath returns target relative to the directory dir.
+func relativePath(dir, target string) string {
+	dirParts := splitPath(dir)
+	targetParts := splitPath(target)
+	common := 0
+	for common < len(dirParts) && common < len(targetParts)-1 && dirParts[common] == targetParts[common] {
+		common++
+	}
+	parts := make([]string, 0, len(dirParts)-common+len(targetParts)-common)
+	for range dirParts[common:] {
+		parts = append(parts, "..")
+	}
+	return path.Join(append(parts, targetParts[common:]...)...)
+}
+
+func splitPath(p string) []string {
+	p = path.Clean(p)
+	if p == "." {
+		return nil
+	}
+	return strings.Split(strings.TrimPrefix(p, "/"), "/")
+}
+
+// CompilePartial compiles the decorated classes of a file into partial declarations, in the
+// order they are declared. Classes with errors are skipped.
+func (c *Compiler) CompilePartial(sf *reflection.SourceFile) []*CompiledClass {
+	var compiled []*CompiledClass
+	for _, class := range sf.Classes {
+		ac := c.byClass[class]
+		if ac == nil || !c.isValid(ac) {
+			continue
+		}
+		cc := &CompiledClass{Class: class}
+		fac := partial.CompileDeclareFactoryFunction(render3.R3ConstructorFactoryMetadata{
+			Name:              class.Name,
+			Type:              c.selfRef(ac),
+			TypeArgumentCount: typeArgumentCount(class),
+			Deps:              ac.deps,
+			Target:            factoryTarget(ac.kind),
+		})
+		cc.Results = append(cc.Results, CompileResult{Name: "ɵfac", Initializer: fac.Expression, Statements: fac.Statements, Type: fac.Type})
+
+		switch ac.kind {
+		case kindComponent:
+			res := partial.CompileDeclareComponentFromMetadata(&ac.component.meta, ac.component.template, ac.component.templateInfo)
+			cc.Results = append(cc.Results, CompileResult{Name: "ɵcmp", Initializer: res.Expression, Statements: res.Statements, Type: res.Type})
+		case kindDirective:
+			res := partial.CompileDeclareDirectiveFromMetadata(ac.directive)
+			cc.Results = append(cc.Results, CompileResult{Name: "ɵdir", Initializer: res.Expression, Statements: res.Statements, Type: res.Type})
+		case kindPipe:
+			res := partial.CompileDeclarePipeFromMetadata(*ac.pipe)
+			cc.Results = append(cc.Results, CompileResult{Name: "ɵpipe", Initializer: res.Expression, Statements: res.Statements, Type: res.Type})
+		case kindNgModule:
+			mod := partial.CompileDeclareNgModuleFromMetadata(&ac.ngModule.meta)
+			cc.Results = append(cc.Results, CompileResult{Name: "ɵmod", Initializer: mod.Expression, Statements: mod.Statements, Type: mod.Type})
+			inj := partial.CompileDeclareInjectorFromMetadata(ac.ngModule.injector)
+			cc.Results = append(cc.Results, CompileResult{Name: "ɵinj", Initializer: inj.Expression, Statements: inj.Statements, Type: inj.Type})
+		case kindInjectable:
+			res := partial.CompileDeclareInjectableFromMetadata(*ac.injectable)
+			cc.Results = append(cc.Results, CompileResult{Name: "ɵprov", Initializer: res.Expression, Statements: res.Statements, Type: res.Type})
+		}
+		cc.Metadata = partial.CompileDeclareClassMetadata(ac.metadata)
+		compiled = append(compiled, cc)
+	}
+	return compiled
+}
+
+// isValid reports whether the analysis of a class succeeded.
+func (c *Compiler) isValid(ac *analyzedClass) bool {
+	if ac.deps == "invalid" {
+		return false
+	}
+	switch ac.kind {
+	case kindComponent:
+		return ac.component != nil && ac.component.template != nil
+	case kindDirective:
+		return ac.directive != nil
+	case kindPipe:
+		return ac.pipe != nil
+	case kindNgModule:
+		return ac.ngModule != nil
+	case kindInjectable:
+		return ac.injectable != nil
+	}
+	return false
+}
+
+// References returns the local names of a file used by its generated code, in order of first
+// use. They must be imported by the generated code the same way the file declares or imports
+// them.
+func (c *Compiler) References(sf *reflection.SourceFile) []string {
+	if f, ok := c.byName[path.Clean(sf.FileName)]; ok {
+		return f.references
+	}
+	return nil
+}
+
+// selfRef refers to a decorated class from its own file.
+func (c *Compiler) selfRef(ac *analyzedClass) render3.R3Reference {
+	ref := ac.file.readVar(ac.class.Name)
+	return render3.R3Reference{Value: ref, Type: ref}
+}
+
+// typeArgumentCount returns the number of type parameters of a class.
+func typeArgumentCount(class *reflection.ClassDeclaration) int {
+	tokens := reflection.Tokenize(class.TypeParameters)
+	if len(tokens) == 1 {
+		return 0
+	}
+	count, depth := 1, 0
+	for _, tok := range tokens {
+		switch {
+		case tok.Is("<") || tok.Is("(") || tok.Is("[") || tok.Is("{"):
+			depth++
+		case tok.Is(">") || tok.Is(")") || tok.Is("]") || tok.Is("}"):
+			depth--
+		case tok.Is(">>"):
+			depth -= 2
+		case tok.Is(",") && depth == 0:
+			count++
+		}
+	}
+	return count
+}
+
+// ngModuleAnalysis is the metadata of an NgModule.
+type ngModuleAnalysis struct {
+	meta     render3_module_compiler.R3NgModuleMetadataGlobal
+	injector render3_injector_compiler.R3InjectorMetadata
+
+	declarations []*analyzedClass
+	// imports and exports are the elements of the `imports` and `exports` arrays, resolved to
+	// decorated classes when they are part of the program.
+	imports []moduleElement
+	exports []moduleElement
+}
+
+// moduleElement is an element of the `imports` or `exports` of an NgModule or standalone
+// component.
+type moduleElement struct {
+	// file is the file of the decorator.
+	file  *sourceFile
+	expr  *reflection.Expression
+	class *analyzedClass
+}
@@ -0,0 +1,309 @@
+package annotations
+
+import (
+	"fmt"
+	"path"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/core"
+	"ngc-go/packages/compiler/src/ml_parser"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/partial"
+	"ngc-go/packages/compiler/src/render3/view"
+)
+
+// componentAnalysis is the metadata of a component.
+type componentAnalysis struct {
+	meta         view.R3ComponentMetadata
+	template     *view.ParsedTemplate
+	templateInfo partial.DeclareComponentTemplateInfo
+	// imports are the elements of the `imports` of a standalone component.
+	imports []moduleElement
+}
+
+// viewEncapsulations maps the members of `ViewEncapsulation` to their values.
+var viewEncapsulations = map[string]core.ViewEncapsulation{
+	"ViewEncapsulation.Emulated":  core.ViewEncapsulationEmulated,
+	"ViewEncapsulation.None":      core.ViewEncapsulationNone,
+	"ViewEncapsulation.ShadowDom": core.ViewEncapsulationShadowDom,
+}
+
+// changeDetectionStrategies maps the members of `ChangeDetectionStrategy` to their values.
+var changeDetectionStrategies = map[string]core.ChangeDetectionStrategy{
+	"ChangeDetectionStrategy.OnPush":  core.ChangeDetectionStrategyOnPush,
+	"ChangeDetectionStrategy.Default": core.ChangeDetectionStrategyDefault,
+}
+
+// analyzeComponent reads the metadata of a `@Component` and parses its template.
+func (c *Compiler) analyzeComponent(ac *analyzedClass) {
+	f := ac.file
+	arg, ok := c.decoratorArgument(ac, true)
+	if !ok {
+		return
+	}
+	dirMeta := c.directiveMetadata(ac, arg)
+	ac.directive = dirMeta
+	analysis := &componentAnalysis{meta: view.R3ComponentMetadata{
+		R3DirectiveMetadata:     *dirMeta,
+		Encapsulation:           core.ViewEncapsulationEmulated,
+		Defer:                   view.R3ComponentDeferMetadata{Mode: view.DeferBlockDepsEmitModePerBlock, Blocks: map[*render3.DeferredBlock]*output.OutputExpression{}},
+		DeclarationListEmitMode: view.DeclarationListEmitModeDirect,
+		RelativeContextFilePath: c.relativeFileName(f.FileName),
+	}}
+	meta := &analysis.meta
+
+	if expr := arg.Property("encapsulation"); expr != nil {
+		if value, ok := viewEncapsulations[expr.Value]; ok && expr.Kind == reflection.ExpressionIdentifier && f.coreName(expr.Value) != "" {
+			meta.Encapsulation = value
+		} else {
+			c.report(f, diagnostics.ValueHasWrongType, expr.Start, expr.End, "encapsulation must be a member of ViewEncapsulation.")
+		}
+	}
+	if expr := arg.Property("changeDetection"); expr != nil {
+		if value, ok := changeDetectionStrategies[expr.Value]; ok && expr.Kind == reflection.ExpressionIdentifier && f.coreName(expr.Value) != "" {
+			meta.ChangeDetection = value
+		} else {
+			c.report(f, diagnostics.ValueHasWrongType, expr.Start, expr.End, "changeDetection must be a member of ChangeDetectionStrategy.")
+		}
+	}
+	if expr := arg.Property("animations"); expr != nil {
+		animations := f.wrap(expr)
+		meta.Animations = &animations
+	}
+	if expr := arg.Property("viewProviders"); expr != nil {
+		viewProviders := f.wrap(expr)
+		meta.ViewProviders = &viewProviders
+	}
+	for _, expr := range c.arrayProperty(f, arg, "imports") {
+		if !meta.IsStandalone {
+			c.report(f, diagnostics.ComponentNotStandalone, expr.Start, expr.End,
+				"'imports' is only valid on a component that is standalone.")
+			break
+		}
+		analysis.imports = append(analysis.imports, c.resolveElement(f, expr))
+	}
+	if meta.IsStandalone && arg.Property("imports") != nil {
+		rawImports := f.wrap(arg.Property("imports"))
+		meta.RawImports = &rawImports
+	}
+	ac.template = &componentTemplate{standalone: meta.IsStandalone, imports: analysis.imports}
+
+	styles, ok := c.componentStyles(ac, arg)
+	if !ok {
+		return
+	}
+	preserveWhitespaces := c.boolProperty(f, arg, "preserveWhitespaces", false)
+	template, info, ok := c.parseTemplate(ac, arg, preserveWhitespaces)
+	if !ok {
+		return
+	}
+	meta.Template = view.R3ComponentTemplateMetadata{
+		Nodes:               template.Nodes,
+		NgContentSelectors:  template.NgContentSelectors,
+		PreserveWhitespaces: &preserveWhitespaces,
+	}
+	meta.Styles = append(styles, template.Styles...)
+	analysis.template = template
+	analysis.templateInfo = info
+
+	ac.component = analysis
+	ac.matchable = newDirectiveMeta(ac, dirMeta)
+	ac.matchable.isComponent = true
+	ac.matchable.ngContentSelectors = template.NgContentSelectors
+	ac.matchable.preserveWhitespaces = preserveWhitespaces
+}
+
+// parseTemplate reads and parses the template of a component. Inline templates are parsed in
+// place, so that the spans of the template point into the component file.
+func (c *Compiler) parseTemplate(ac *analyzedClass, arg *reflection.Expression, preserveWhitespaces bool) (*view.ParsedTemplate, partial.DeclareComponentTemplateInfo, bool) {
+	f := ac.file
+	var info partial.DeclareComponentTemplateInfo
+	var template *view.ParsedTemplate
+	options := &view.ParseTemplateOptions{PreserveWhitespaces: &preserveWhitespaces}
+
+	if expr := arg.Property("template"); expr != nil {
+		content, ok := expr.StringValue()
+		if !ok {
+			c.report(f, diagnostics.ValueHasWrongType, expr.Start, expr.End, "template must be a string.")
+			return nil, info, false
+		}
+		escapedString := true
+		options.EscapedString = &escapedString
+		options.Range = &ml_parser.LexerRange{
+			StartPos:  expr.Start + 1,
+			StartLine: f.location(expr.Start + 1).Line,
+			StartCol:  f.location(expr.Start + 1).Col,
+			EndPos:    expr.End - 1,
+		}
+		ac.template.locate(f.Text, f.FileName, options)
+		template = c.options.TemplateCache.parse(f.Text, f.FileName, options)
+		info = partial.DeclareComponentTemplateInfo{
+			Content:                         content,
+			SourceUrl:                       f.FileName,
+			IsInline:                        true,
+			InlineTemplateLiteralExpression: output.NewLiteralExpr(content, output.InferredType, nil),
+		}
+	} else if templateUrl, ok := c.stringProperty(f, arg, "templateUrl"); ok {
+		expr := arg.Property("templateUrl")
+		templatePath := path.Join(f.dir(), templateUrl)
+		content, err := c.loadResource(templatePath)
+		if err != nil {
+			c.report(f, diagnostics.ComponentResourceNotFound, expr.Start, expr.End,
+				fmt.Sprintf("Could not find template file '%s'.", templateUrl))
+			return nil, info, false
+		}
+		ac.template.locate(content, templatePath, options)
+		template = c.options.TemplateCache.parse(content, templatePath, options)
+		info = partial.DeclareComponentTemplateInfo{Content: content, SourceUrl: templatePath}
+	} else {
+		if arg.Property("templateUrl") == nil {
+			c.report(f, diagnostics.ComponentMissingTemplate, ac.decorator.Start, ac.decorator.End,
+				"component is missing a template")
+		}
+		return nil, info, false
+	}
+
+	if len(template.Errors) > 0 {
+		c.diags = append(c.diags, diagnostics.FromParseErrors(template.Errors, diagnostics.TemplateParseError)...)
+		return nil, info, false
+	}
+	return template, info, true
+}
+
+// componentStyles reads the inline styles and the stylesheets of a component.
+func (c *Compiler) componentStyles(ac *analyzedClass, arg *reflection.Expression) ([]string, bool) {
+	f := ac.file
+	var styles []string
+	ok := true
+	if expr := arg.Property("styles"); expr != nil {
+		elements := []*reflection.Expression{expr}
+		if expr.Kind == reflection.ExpressionArray {
+			elements = expr.Elements
+		}
+		for _, element := range elements {
+			if style, isString := element.StringValue(); isString {
+				styles = append(styles, style)
+			} else {
+				c.report(f, diagnostics.ValueHasWrongType, element.Start, element.End, "styles must be a string or an array of strings.")
+				ok = false
+			}
+		}
+	}
+
+	var styleUrls []*reflection.Expression
+	if expr := arg.Property("styleUrl"); expr != nil {
+		styleUrls = append(styleUrls, expr)
+	}
+	styleUrls = append(styleUrls, c.arrayProperty(f, arg, "styleUrls")...)
+	for _, expr := range styleUrls {
+		styleUrl, isString := expr.StringValue()
+		if !isString {
+			c.report(f, diagnostics.ComponentInvalidStyleUrls, expr.Start, expr.End, "styleUrl must be a string.")
+			ok = false
+			continue
+		}
+		style, err := c.loadResource(path.Join(f.dir(), styleUrl))
+		if err != nil {
+			c.report(f, diagnostics.ComponentResourceNotFound, expr.Start, expr.End,
+				fmt.Sprintf("Could not find stylesheet file '%s'.", styleUrl))
+			ok = false
+			continue
+		}
+		styles = append(styles, style)
+	}
+	return styles, ok
+}
+
+// ResourceFiles returns the paths of the external templates and stylesheets of the components
+// of a file, as the compiler resolves them.
+func ResourceFiles(sf *reflection.SourceFile) []string {
+	f := newSourceFile(sf)
+	var files []string
+	for _, class := range sf.Classes {
+		for _, dec := range class.Decorators {
+			if f.coreName(dec.Name) != "Component" || len(dec.Args) == 0 {
+				continue
+			}
+			arg := dec.Args[0]
+			urls := []*reflection.Expression{arg.Property("templateUrl"), arg.Property("styleUrl")}
+			if styleUrls := arg.Property("styleUrls"); styleUrls != nil {
+				urls = append(urls, styleUrls.Elements...)
+			}
+			for _, expr := range urls {
+				if url, ok := expr.StringValue(); ok {
+					files = append(files, path.Join(f.dir(), url))
+				}
+			}
+		}
+	}
+	return files
+}
+
+// TemplateSource is where the template of a component is written, for the tools rewriting
+// templates, which only read the files.
+type TemplateSource struct {
+	// ClassName is the component class.
+	ClassName string
+	// Inline is the string literal of an inline template, nil for an external template. Its
+	// offsets include the quotes.
+	Inline *reflection.Expression
+	// URL is the path of an external template, as the compiler resolves it.
+	URL string
+	// PreserveWhitespaces is the preserveWhitespaces option of the component.
+	PreserveWhitespaces bool
+}
+
+// TemplateSources returns the statically known templates of the components of a file.
+func TemplateSources(sf *reflection.SourceFile) []*TemplateSource {
+	f := newSourceFile(sf)
+	var templates []*TemplateSource
+	for _, class := range sf.Classes {
+		for _, dec := range class.Decorators {
+			if f.coreName(dec.Name) != "Component" || len(dec.Args) == 0 {
+				continue
+			}
+			arg := dec.Args[0]
+			template := &TemplateSource{ClassName: class.Name}
+			template.PreserveWhitespaces, _ = arg.Property("preserveWhitespaces").BoolValue()
+			if expr := arg.Property("template"); expr != nil && expr.Kind == reflection.ExpressionString {
+				template.Inline = expr
+			} else if url, ok := arg.Property("templateUrl").StringValue(); ok {
+				template.URL = path.Join(f.dir(), url)
+			} else {
+				continue
+			}
+			templates = append(templates, template)
+		}
+	}
+	return templates
+}
+
+// InlineTemplates returns the string literals of the inline templates of the components of a
+// file. Their offsets include the quotes.
+func InlineTemplates(sf *reflection.SourceFile) []*reflection.Expression {
+	var templates []*reflection.Expression
+	for _, template := range TemplateSources(sf) {
+		if template.Inline != nil {
+			templates = append(templates, template.Inline)
+		}
+	}
+	return templates
+}
+
+// loadResource reads an external resource of a component.
+func (c *Compiler) loadResource(resourcePath string) (string, error) {
+	if c.options.ResourceLoader == nil {
+		return "", fmt.Errorf("no resource loader to read %s", resourcePath)
+	}
+	return c.options.ResourceLoader(resourcePath)
+}
+
+// relativeFileName returns a file name relative to the root directory.
+func (c *Compiler) relativeFileName(fileName string) string {
+	if c.options.RootDir == "" {
+		return fileName
+	}
+	return relativePath(c.options.RootDir, fileName)
+}
@@ -0,0 +1,592 @@
+package annotations
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/output"
+)
+
+// EmitDeclarations prints the declaration file (`.d.ts`) of a source file, as tsc does with
+// `declaration` enabled, with the definitions of its decorated classes declared as typed static
+// fields, e.g. `static ɵcmp: i0.ɵɵComponentDeclaration<...>;`. These types are what the
+// template type-checker of consuming applications reads.
+//
+// Members without a type annotation are typed from their initializer when it is a literal, a
+// `new` expression or a call of the signal and injection APIs of `@angular/core`. Methods
+// without a return type annotation return `void` when they don't return a value. The other types
+// would need a type checker: they are reported as DeclarationTypeNotInferred errors, and printed
+// as `any`.
+func (c *Compiler) EmitDeclarations(sf *reflection.SourceFile) (string, []*diagnostics.Diagnostic) {
+	f := c.byName[path.Clean(sf.FileName)]
+	if f == nil {
+		f = newSourceFile(sf)
+	}
+	results := make(map[*reflection.ClassDeclaration][]CompileResult)
+	for _, cc := range c.CompileFull(sf, constant.NewConstantPool(false)) {
+		results[cc.Class] = cc.Results
+	}
+
+	d := &declarationPrinter{f: f, emitter: output.NewTsEmitterVisitor(), exportedLocally: make(map[string]bool)}
+	for _, stmt := range sf.Statements {
+		d.readExportList(stmt)
+	}
+
+	type declaration struct {
+		start int
+		text  string
+	}
+	var declarations []declaration
+	for _, class := range sf.Classes {
+		declarations = append(declarations, declaration{class.Start, d.class(class, results[class])})
+	}
+	for i, stmt := range sf.Statements {
+		if text := d.statement(stmt, sf.Statements[:i]); text != "" {
+			declarations = append(declarations, declaration{stmt.Start, text})
+		}
+	}
+	sort.SliceStable(declarations, func(i, j int) bool { return declarations[i].start < declarations[j].start })
+
+	var lines []string
+	for _, imp := range sf.Imports {
+		if imp.DefaultName != "" || imp.NamespaceName != "" || len(imp.Specifiers) > 0 {
+			lines = append(lines, sf.Text[imp.Start:imp.End])
+		}
+	}
+	// The namespaces are only known once the declarations are printed.
+	lines = append(lines, output.ImportDeclarations(d.emitter.ImportsWithPrefixes())...)
+	for _, decl := range declarations {
+		lines = append(lines, decl.text)
+	}
+	if len(declarations) == 0 {
+		// Keep the file a module.
+		lines = append(lines, "export {};")
+	}
+	return strings.Join(lines, "\n") + "\n", d.diags
+}
+
+// declarationPrinter prints the declarations of a source file.
+type declarationPrinter struct {
+	f       *sourceFile
+	emitter *output.TsEmitterVisitor
+	// exportedLocally are the local names exported by export lists, e.g. `export { a as b };`,
+	// whose declarations must be kept.
+	exportedLocally map[string]bool
+	diags           []*diagnostics.Diagnostic
+}
+
+// notInferred reports a type which is only known from the expression at Text[start:end].
+func (d *declarationPrinter) notInferred(start, end int, message string) {
+	d.diags = append(d.diags, diagnostics.MakeDiagnostic(diagnostics.DeclarationTypeNotInferred,
+		diagnostics.CategoryError, d.f.span(start, end), message))
+}
+
+// readExportList records the local names exported by an export list without module specifier.
+func (d *declarationPrinter) readExportList(stmt *reflection.Statement) {
+	if stmt.Kind != reflection.StatementExport {
+		return
+	}
+	tokens := reflection.Tokenize(d.f.Text[stmt.KeywordStart:stmt.End])
+	for _, tok := range tokens {
+		if tok.Is("from") {
+			return
+		}
+	}
+	expectName := false
+	for _, tok := range tokens {
+		switch {
+		case tok.Is("{") || tok.Is(","):
+			expectName = true
+		case expectName && tok.Kind == reflection.TokenIdentifier && !tok.Is("type"):
+			d.exportedLocally[tok.Text] = true
+			expectName = false
+		}
+	}
+}
+
+// class prints the declaration of a class, along with the static fields of its definitions.
+func (d *declarationPrinter) class(class *reflection.ClassDeclaration, results []CompileResult) string {
+	var header strings.Builder
+	switch {
+	case class.Default:
+		header.WriteString("export default ")
+	case class.Exported:
+		header.WriteString("export declare ")
+	default:
+		header.WriteString("declare ")
+	}
+	if class.Abstract {
+		header.WriteString("abstract ")
+	}
+	header.WriteString("class")
+	if class.Name != "" {
+		header.WriteString(" " + class.Name)
+	}
+	if class.TypeParameters != "" {
+		header.WriteString("<" + class.TypeParameters + ">")
+	}
+	if class.Extends != "" {
+		header.WriteString(" extends " + class.Extends)
+	}
+	if class.Implements != "" {
+		header.WriteString(" implements " + class.Implements)
+	}
+
+	ctx := output.NewEmitterVisitorContext(1)
+	for _, member := range class.Members {
+		if strings.HasPrefix(member.Name, "#") {
+			ctx.Println(nil, "#private;")
+			break
+		}
+	}
+	if ctor := class.Constructor; ctor != nil {
+		for _, param := range ctor.Parameters {
+			if len(param.Modifiers) > 0 && param.Name != "" {
+				d.property(param.Name, param.Modifiers, param.Optional, param.Type, param.Initializer, ctx)
+			}
+		}
+	}
+
+	printed := make(map[string]bool)
+	ctorPrinted := class.Constructor == nil
+	for i, member := range class.Members {
+		if !ctorPrinted && member.Start > class.Constructor.Start {
+			ctx.Println(nil, "constructor("+d.parameters(class.Constructor.Parameters)+");")
+			ctorPrinted = true
+		}
+		if member.Name == "" || strings.HasPrefix(member.Name, "#") {
+			// Computed names are not understood, private names are covered by `#private`.
+			continue
+		}
+		key := fmt.Sprintf("%t %s", member.IsStatic, member.Name)
+		if hasModifier(member.Modifiers, "private") {
+			// The types of private members are not part of the API.
+			if !printed[key] {
+				ctx.Println(nil, modifiers(member.Modifiers)+output.EscapeIdentifier(member.Name, false, false)+";")
+				printed[key] = true
+			}
+			continue
+		}
+		switch member.Kind {
+		case reflection.MemberKindProperty:
+			d.property(member.Name, member.Modifiers, member.Optional, member.Type, member.Initializer, ctx)
+		case reflection.MemberKindMethod:
+			if member.HasBody && hasOverloads(class.Members[:i], member) {
+				// Implementations of overloaded methods are not part of the API.
+				continue
+			}
+			ctx.Print(nil, modifiers(member.Modifiers)+output.EscapeIdentifier(member.Name, false, false), false)
+			if member.Optional {
+				ctx.Print(nil, "?", false)
+			}
+			if member.TypeParameters != "" {
+				ctx.Print(nil, "<"+member.TypeParameters+">", false)
+			}
+			ctx.Print(nil, "("+d.parameters(member.Parameters)+"): ", false)
+			ctx.Print(nil, d.returnType(member.Name, member.ReturnType, hasModifier(member.Modifiers, "async"), member.Start, member.End), false)
+			ctx.Println(nil, ";")
+		case reflection.MemberKindGetter:
+			returnType := member.ReturnType
+			if returnType == "" {
+				returnType = accessorType(class.Members, member)
+			}
+			if returnType == "" && member.HasBody {
+				d.notInferred(member.Start, member.End, fmt.Sprintf("The type of the accessor '%s' cannot be inferred for the declaration file, add a return type annotation.", member.Name))
+			}
+			ctx.Println(nil, modifiers(member.Modifiers)+"get "+output.EscapeIdentifier(member.Name, false, false)+"(): "+orAny(returnType)+";")
+		case reflection.MemberKindSetter:
+			ctx.Println(nil, modifiers(member.Modifiers)+"set "+output.EscapeIdentifier(member.Name, false, false)+"("+d.parameters(member.Parameters)+");")
+		}
+	}
+	if !ctorPrinted {
+		ctx.Println(nil, "constructor("+d.parameters(class.Constructor.Parameters)+");")
+	}
+
+	for _, res := range results {
+		d.emitter.EmitStaticField(res.Name, res.Type, nil, ctx)
+	}
+
+	body := ctx.ToSource()
+	if strings.TrimSpace(body) == "" {
+		return header.String() + " {\n}"
+	}
+	return header.String() + " {\n" + body + "\n}"
+}
+
+// property prints a property declaration.
+func (d *declarationPrinter) property(name string, mods []string, optional bool, typ string, initializer *reflection.Expression, ctx *output.EmitterVisitorContext) {
+	ctx.Print(nil, modifiers(mods)+output.EscapeIdentifier(name, false, false), false)
+	if hasModifier(mods, "private") {
+		ctx.Println(nil, ";")
+		return
+	}
+	if optional {
+		ctx.Print(nil, "?", false)
+	}
+	ctx.Print(nil, ": "+d.typeText(name, typ, initializer), false)
+	ctx.Println(nil, ";")
+}
+
+// statement prints the declaration of a top-level statement, or returns an empty string when it
+// is not part of the declaration file. previous are the statements preceding it.
+func (d *declarationPrinter) statement(stmt *reflection.Statement, previous []*reflection.Statement) string {
+	text := d.f.Text[stmt.KeywordStart:stmt.End]
+	export := ""
+	if stmt.Exported {
+		export = "export "
+	}
+	switch stmt.Kind {
+	case reflection.StatementExport:
+		return withSemicolon(d.f.Text[stmt.Start:stmt.End])
+	case reflection.StatementInterface:
+		return export + text
+	case reflection.StatementTypeAlias:
+		return export + withSemicolon(text)
+	case reflection.StatementEnum:
+		return export + "declare " + text
+	}
+
+	if !stmt.Exported && !d.exportedLocally[stmt.Name] || stmt.Name == "" {
+		// Local variables and functions are only known to the file.
+		return ""
+	}
+	switch stmt.Kind {
+	case reflection.StatementVariable:
+		init := stmt.Initializer
+		if stmt.Type == "" && stmt.Keyword == "const" && init != nil && !strings.HasPrefix(init.Text, "`") &&
+			(init.Kind == reflection.ExpressionString || init.Kind == reflection.ExpressionNumber || init.Kind == reflection.ExpressionBoolean) {
+			// Constants keep their literal type.
+			return fmt.Sprintf("%sdeclare const %s = %s;", export, stmt.Name, init.Text)
+		}
+		return fmt.Sprintf("%sdeclare %s %s: %s;", export, stmt.Keyword, stmt.Name, d.typeText(stmt.Name, stmt.Type, init))
+	case reflection.StatementFunction:
+		if stmt.HasBody && hasOverloadedFunction(previous, stmt) {
+			// Implementations of overloaded functions are not part of the API.
+			return ""
+		}
+		typeParams := ""
+		if stmt.TypeParameters != "" {
+			typeParams = "<" + stmt.TypeParameters + ">"
+		}
+		return fmt.Sprintf("%sdeclare function %s%s(%s): %s;", export, stmt.Name, typeParams,
+			d.parameters(stmt.Parameters), d.returnType(stmt.Name, stmt.ReturnType, stmt.Async, stmt.KeywordStart, stmt.End))
+	}
+	return ""
+}
+
+// parameters prints the parameters of a signature, without decorators, modifiers and default
+// values. Destructuring parameters are named `__<index>`, like tsc does.
+func (d *declarationPrinter) parameters(params []*reflection.CtorParameter) string {
+	var printed []string
+	for i, param := range params {
+		name := param.Name
+		if name == "" {
+			name = fmt.Sprintf("__%d", i)
+		}
+		typ := d.typeText(name, param.Type, param.Initializer)
+		switch {
+		case param.Rest:
+			name = "..." + name
+			if param.Type == "" {
+				typ = "any[]"
+			}
+		case param.Optional:
+			name += "?"
+		}
+		printed = append(printed, name+": "+typ)
+	}
+	return strings.Join(printed, ", ")
+}
+
+// returnType returns the return type of a method or function: its annotation, or `void` when
+// its body in Text[start:end] doesn't return a value, wrapped in a `Promise` for async
+// functions. The types of the returned values are not inferred.
+func (d *declarationPrinter) returnType(name string, annotation string, async bool, start, end int) string {
+	if annotation != "" {
+		return annotation
+	}
+	returnType := "void"
+	tokens := reflection.Tokenize(d.f.Text[start:end])
+	for i, tok := range tokens {
+		if !tok.Is("return") {
+			continue
+		}
+		if next := tokens[i+1]; !next.NewLineBefore && !next.Is(";") && !next.Is("}") {
+			d.notInferred(start+tok.Start, start+tok.End, fmt.Sprintf("The return type of '%s' cannot be inferred for the declaration file, add a return type annotation.", name))
+			returnType = "any"
+			break
+		}
+	}
+	if async {
+		return "Promise<" + returnType + ">"
+	}
+	return returnType
+}
+
+// typeText prints the type annotation of a declaration, or the type inferred from its
+// initializer without one.
+func (d *declarationPrinter) typeText(name string, annotation string, initializer *reflection.Expression) string {
+	if annotation != "" {
+		return annotation
+	}
+	typ := d.inferType(initializer)
+	if typ == nil && initializer != nil {
+		d.notInferred(initializer.Start, initializer.End, fmt.Sprintf("The type of '%s' cannot be inferred from its initializer for the declaration file, add a type annotation.", name))
+	}
+	ctx := output.NewEmitterVisitorContext(0)
+	d.emitter.VisitType(typ, ctx, "any")
+	return ctx.ToSource()
+}
+
+// inferType returns the type of an initializer, as far as it can be known without a type
+// checker, or nil.
+func (d *declarationPrinter) inferType(init *reflection.Expression) output.Type {
+	if init == nil {
+		return nil
+	}
+	switch init.Kind {
+	case reflection.ExpressionString:
+		return output.StringType
+	case reflection.ExpressionNumber:
+		return output.NumberType
+	case reflection.ExpressionBoolean:
+		return output.BoolType
+	case reflection.ExpressionCall:
+		return d.inferCallType(init)
+	case reflection.ExpressionOther:
+		return d.inferNewType(init)
+	}
+	return nil
+}
+
+// inferCallType returns the type of a call of the signal and injection APIs of `@angular/core`,
+// e.g. `i0.InputSignal<string>` for `input(”)`.
+func (d *declarationPrinter) inferCallType(call *reflection.Expression) output.Type {
+	if call.Callee.Kind != reflection.ExpressionIdentifier {
+		return nil
+	}
+	arg := func(i int) *reflection.Expression {
+		if i < len(call.Elements) {
+			return call.Elements[i]
+		}
+		return nil
+	}
+	// valueType is the type argument of the call, or the type of its initial value.
+	valueType := func(initial *reflection.Expression) output.Type {
+		if call.TypeArguments != "" {
+			return sourceType(call.TypeArguments)
+		}
+		return d.inferType(initial)
+	}
+
+	switch api := d.f.coreName(call.Callee.Value); api {
+	case "input", "input.required", "model", "model.required":
+		required := strings.HasSuffix(api, ".required")
+		initial, options := arg(0), arg(1)
+		if required {
+			initial, options = nil, arg(0)
+		}
+		typ := valueType(initial)
+		if !required && initial == nil {
+			if call.TypeArguments == "" {
+				typ = sourceType("undefined")
+			} else {
+				typ = sourceType(call.TypeArguments + " | undefined")
+			}
+		}
+		if strings.HasPrefix(api, "model") {
+			return coreType("ModelSignal", typ)
+		}
+		if options.Property("transform") != nil {
+			if strings.Contains(call.TypeArguments, ",") {
+				return coreType("InputSignalWithTransform", sourceType(call.TypeArguments))
+			}
+			return coreType("InputSignalWithTransform", typ, output.DynamicType)
+		}
+		return coreType("InputSignal", typ)
+	case "output":
+		if call.TypeArguments == "" {
+			return coreType("OutputEmitterRef", sourceType("void"))
+		}
+		return coreType("OutputEmitterRef", sourceType(call.TypeArguments))
+	case "signal":
+		return coreType("WritableSignal", valueType(arg(0)))
+	case "computed":
+		return coreType("Signal", valueType(nil))
+	case "viewChild", "contentChild":
+		return coreType("Signal", sourceType(orAny(call.TypeArguments)+" | undefined"))
+	case "viewChild.required", "contentChild.required":
+		return coreType("Signal", valueType(nil))
+	case "viewChildren", "contentChildren":
+		return coreType("Signal", sourceType("readonly "+orAny(call.TypeArguments)+"[]"))
+	case "inject":
+		typ := call.TypeArguments
+		if token := arg(0); typ == "" {
+			if token == nil || token.Kind != reflection.ExpressionIdentifier || !d.isClass(token.Value) {
+				return nil
+			}
+			typ = token.Value
+		}
+		if optional, _ := arg(1).Property("optional").BoolValue(); optional {
+			typ += " | null"
+		}
+		return sourceType(typ)
+	}
+	return nil
+}
+
+// coreGenericDefaults are the type arguments of the generic classes of `@angular/core` which
+// are constructed without any.
+var coreGenericDefaults = map[string]string{
+	"EventEmitter":   "any",
+	"InjectionToken": "unknown",
+}
+
+// inferNewType returns the type of a `new` expression, e.g. `EventEmitter<string>` for
+// `new EventEmitter<string>()`.
+func (d *declarationPrinter) inferNewType(init *reflection.Expression) output.Type {
+	tokens := reflection.Tokenize(init.Text)
+	if !tokens[0].Is("new") || tokens[1].Kind != reflection.TokenIdentifier {
+		return nil
+	}
+	name := tokens[1].Text
+	i := 2
+	for tokens[i].Is(".") && tokens[i+1].Kind == reflection.TokenIdentifier {
+		name += "." + tokens[i+1].Text
+		i += 2
+	}
+	switch {
+	case tokens[i].Is("<"):
+		depth := 0
+		for j := i; tokens[j].Kind != reflection.TokenEOF; j++ {
+			switch {
+			case tokens[j].Is("<"):
+				depth++
+			case tokens[j].Is(">"):
+				depth--
+			case tokens[j].Is(">>"):
+				depth -= 2
+			}
+			if depth <= 0 {
+				return sourceType(name + "<" + strings.TrimSpace(init.Text[tokens[i].End:tokens[j].Start]) + ">")
+			}
+		}
+		return nil
+	case !tokens[i].Is("(") && tokens[i].Kind != reflection.TokenEOF:
+		// Not a plain construction, e.g. `new Foo().bar`.
+		return nil
+	}
+	if args, ok := coreGenericDefaults[d.f.coreName(name)]; ok {
+		return sourceType(name + "<" + args + ">")
+	}
+	return sourceType(name)
+}
+
+// isClass reports whether a name refers to a class, i.e. a class of the file or an imported name
+// which is not a constant, e.g. `HttpClient` but not `API_URL`.
+func (d *declarationPrinter) isClass(name string) bool {
+	if d.f.Class(name) != nil {
+		return true
+	}
+	if _, _, ok := d.f.ImportOf(rootName(name)); !ok {
+		return false
+	}
+	last := name[strings.LastIndex(name, ".")+1:]
+	return last != strings.ToUpper(last) && last[:1] == strings.ToUpper(last[:1])
+}
+
+// coreType is the type `i0.<name><typeParams>` of `@angular/core`.
+func coreType(name string, typeParams ...output.Type) output.Type {
+	module := angularCore
+	for i, param := range typeParams {
+		if param == nil {
+			typeParams[i] = output.DynamicType
+		}
+	}
+	ref := output.NewExternalExpr(&output.ExternalReference{ModuleName: &module, Name: &name}, nil, typeParams, nil)
+	return output.NewExpressionType(ref, output.TypeModifierNone, nil)
+}
+
+// sourceType is a type given as source text.
+func sourceType(text string) output.Type {
+	if text == "" {
+		return nil
+	}
+	return output.NewTransplantedType(text, output.TypeModifierNone)
+}
+
+// hasOverloads reports whether a method has overload signatures among the preceding members.
+func hasOverloads(previous []*reflection.ClassMember, method *reflection.ClassMember) bool {
+	for _, member := range previous {
+		if member.Kind == reflection.MemberKindMethod && member.Name == method.Name && member.IsStatic == method.IsStatic && !member.HasBody {
+			return true
+		}
+	}
+	return false
+}
+
+// hasOverloadedFunction reports whether a function has overload signatures among the preceding
+// statements.
+func hasOverloadedFunction(previous []*reflection.Statement, fn *reflection.Statement) bool {
+	for _, stmt := range previous {
+		if stmt.Kind == reflection.StatementFunction && stmt.Name == fn.Name && !stmt.HasBody {
+			return true
+		}
+	}
+	return false
+}
+
+// accessorType returns the type of the setter of the same property as a getter, if any.
+func accessorType(members []*reflection.ClassMember, getter *reflection.ClassMember) string {
+	for _, member := range members {
+		if member.Kind == reflection.MemberKindSetter && member.Name == getter.Name && member.IsStatic == getter.IsStatic {
+			return member.Type
+		}
+	}
+	return ""
+}
+
+// declarationModifiers are the modifiers which are kept in declaration files.
+var declarationModifiers = map[string]bool{
+	"private": true, "protected": true, "static": true, "readonly": true, "abstract": true,
+}
+
+// modifiers prints the modifiers of a member which are kept in declaration files, followed by
+// a space.
+func modifiers(mods []string) string {
+	var b strings.Builder
+	for _, mod := range mods {
+		if declarationModifiers[mod] {
+			b.WriteString(mod + " ")
+		}
+	}
+	return b.String()
+}
+
+func hasModifier(mods []string, mod string) bool {
+	for _, m := range mods {
+		if m == mod {
+			return true
+		}
+	}
+	return false
+}
+
+func orAny(typ string) string {
+	if typ == "" {
+		return "any"
+	}
+	return typ
+}
+
+func withSemicolon(text string) string {
+	if strings.HasSuffix(text, ";") {
+		return text
+	}
+	return text + ";"
+}
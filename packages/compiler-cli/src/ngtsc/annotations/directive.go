@@ -0,0 +1,385 @@
+package annotations
+
+import (
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/view"
+	view_compiler "ngc-go/packages/compiler/src/render3/view/compiler"
+)
+
+// analyzeDirective reads the metadata of a `@Directive`.
+func (c *Compiler) analyzeDirective(ac *analyzedClass) {
+	arg, ok := c.decoratorArgument(ac, false)
+	if !ok {
+		return
+	}
+	meta := c.directiveMetadata(ac, arg)
+	if arg != nil && meta.Selector == nil {
+		c.report(ac.file, diagnostics.DirectiveMissingSelector, ac.decorator.Start, ac.decorator.End,
+			"Directive "+ac.class.Name+" has no selector, please add it!")
+		return
+	}
+	ac.directive = meta
+	ac.matchable = newDirectiveMeta(ac, meta)
+}
+
+// directiveMetadata reads the metadata shared by directives and components. arg is the decorator
+// argument, nil for abstract directives.
+func (c *Compiler) directiveMetadata(ac *analyzedClass, arg *reflection.Expression) *view.R3DirectiveMetadata {
+	f := ac.file
+	class := ac.class
+	meta := &view.R3DirectiveMetadata{
+		Name:              class.Name,
+		Type:              c.selfRef(ac),
+		TypeArgumentCount: typeArgumentCount(class),
+		TypeSourceSpan:    f.span(class.KeywordStart, class.BodyStart),
+		Deps:              ac.deps,
+		Inputs:            make(map[string]view.R3InputMetadata),
+		Outputs:           make(map[string]string),
+		UsesInheritance:   class.Extends != "",
+		IsStandalone:      c.boolProperty(f, arg, "standalone", true),
+		IsSignal:          c.boolProperty(f, arg, "signals", false),
+	}
+
+	if selector, ok := c.stringProperty(f, arg, "selector"); ok {
+		selector = strings.TrimSpace(selector)
+		meta.Selector = &selector
+	}
+	if exportAs, ok := c.stringProperty(f, arg, "exportAs"); ok {
+		for _, name := range strings.Split(exportAs, ",") {
+			meta.ExportAs = append(meta.ExportAs, strings.TrimSpace(name))
+		}
+	}
+	if providers := arg.Property("providers"); providers != nil {
+		expr := f.wrap(providers)
+		meta.Providers = &expr
+	}
+
+	for _, input := range c.arrayProperty(f, arg, "inputs") {
+		c.readInputMapping(f, input, meta)
+	}
+	for _, out := range c.arrayProperty(f, arg, "outputs") {
+		if value, ok := out.StringValue(); ok {
+			name, alias := parseMapping(value)
+			meta.Outputs[name] = alias
+		} else {
+			c.report(f, diagnostics.ValueHasWrongType, out.Start, out.End, "Output must be a string.")
+		}
+	}
+
+	host := make(map[string]interface{})
+	if hostExpr := arg.Property("host"); hostExpr != nil {
+		if hostExpr.Kind != reflection.ExpressionObject {
+			c.report(f, diagnostics.ValueHasWrongType, hostExpr.Start, hostExpr.End, "Decorator host metadata must be an object.")
+		}
+		for _, prop := range hostExpr.Properties {
+			if value, ok := prop.Value.StringValue(); ok && prop.Name != "" {
+				host[prop.Name] = value
+			} else {
+				c.report(f, diagnostics.ValueHasWrongType, prop.Start, prop.End, "Decorator host metadata must be a string -> string object.")
+			}
+		}
+	}
+
+	for _, member := range class.Members {
+		if member.IsStatic {
+			continue
+		}
+		if member.Name == "ngOnChanges" && member.Kind == reflection.MemberKindMethod {
+			meta.Lifecycle.UsesOnChanges = true
+		}
+		c.readMemberDecorators(f, member, meta, host)
+		c.readInitializerApi(f, member, meta)
+	}
+
+	parsed := view_compiler.ParseHostBindings(host)
+	meta.Host = view.R3HostMetadata{
+		Attributes:        parsed.Attributes,
+		Listeners:         parsed.Listeners,
+		Properties:        parsed.Properties,
+		SpecialAttributes: parsed.SpecialAttributes,
+	}
+
+	for _, hostDirective := range c.arrayProperty(f, arg, "hostDirectives") {
+		meta.HostDirectives = append(meta.HostDirectives, c.hostDirective(f, hostDirective))
+	}
+	return meta
+}
+
+// readInputMapping reads an element of the `inputs` of a decorator: `'name'`, `'name: alias'`
+// or `{name, alias, required, transform}`.
+func (c *Compiler) readInputMapping(f *sourceFile, input *reflection.Expression, meta *view.R3DirectiveMetadata) {
+	if value, ok := input.StringValue(); ok {
+		name, alias := parseMapping(value)
+		meta.Inputs[name] = view.R3InputMetadata{ClassPropertyName: name, BindingPropertyName: alias}
+		return
+	}
+	name, ok := c.stringProperty(f, input, "name")
+	if input.Kind != reflection.ExpressionObject || !ok {
+		c.report(f, diagnostics.ValueHasWrongType, input.Start, input.End, "Input must be a string or an object with a name.")
+		return
+	}
+	meta.Inputs[name] = c.inputOptions(f, name, input)
+}
+
+// inputOptions reads the options of a decorator input: `{alias, required, transform}`.
+func (c *Compiler) inputOptions(f *sourceFile, name string, options *reflection.Expression) view.R3InputMetadata {
+	input := view.R3InputMetadata{ClassPropertyName: name, BindingPropertyName: name}
+	if alias, ok := c.stringProperty(f, options, "alias"); ok {
+		input.BindingPropertyName = alias
+	}
+	input.Required = c.boolProperty(f, options, "required", false)
+	if transform := options.Property("transform"); transform != nil {
+		expr := f.wrap(transform)
+		input.TransformFunction = &expr
+	}
+	return input
+}
+
+// readMemberDecorators reads the Angular decorators of a class member.
+func (c *Compiler) readMemberDecorators(f *sourceFile, member *reflection.ClassMember, meta *view.R3DirectiveMetadata, host map[string]interface{}) {
+	for _, dec := range member.Decorators {
+		var first *reflection.Expression
+		if len(dec.Args) > 0 {
+			first = dec.Args[0]
+		}
+		switch name := f.coreName(dec.Name); name {
+		case "Input":
+			input := view.R3InputMetadata{ClassPropertyName: member.Name, BindingPropertyName: member.Name}
+			if alias, ok := first.StringValue(); ok {
+				input.BindingPropertyName = alias
+			} else if first != nil {
+				input = c.inputOptions(f, member.Name, first)
+			}
+			meta.Inputs[member.Name] = input
+		case "Output":
+			alias := member.Name
+			if value, ok := first.StringValue(); ok {
+				alias = value
+			}
+			meta.Outputs[member.Name] = alias
+		case "HostBinding":
+			target := member.Name
+			if value, ok := first.StringValue(); ok {
+				target = value
+			}
+			host["["+target+"]"] = member.Name
+		case "HostListener":
+			event, ok := first.StringValue()
+			if !ok {
+				c.report(f, diagnostics.DecoratorArityWrong, dec.Start, dec.End, "@HostListener must have an event name.")
+				continue
+			}
+			var args []string
+			if len(dec.Args) > 1 {
+				for _, arg := range dec.Args[1].Elements {
+					if value, ok := arg.StringValue(); ok {
+						args = append(args, value)
+					}
+				}
+			}
+			host["("+event+")"] = member.Name + "(" + strings.Join(args, ",") + ")"
+		case "ViewChild", "ViewChildren", "ContentChild", "ContentChildren":
+			if first == nil {
+				c.report(f, diagnostics.DecoratorArityWrong, dec.Start, dec.End, "@"+name+" must have arguments.")
+				continue
+			}
+			var options *reflection.Expression
+			if len(dec.Args) > 1 {
+				options = dec.Args[1]
+			}
+			c.addQuery(f, meta, member.Name, name, first, options, false)
+		}
+	}
+}
+
+// readInitializerApi reads members initialized by the signal APIs of `@angular/core`, e.g.
+// `value = input.required<string>()` or `changed = output()`.
+func (c *Compiler) readInitializerApi(f *sourceFile, member *reflection.ClassMember, meta *view.R3DirectiveMetadata) {
+	call := member.Initializer
+	if call == nil || call.Kind != reflection.ExpressionCall || call.Callee.Kind != reflection.ExpressionIdentifier {
+		return
+	}
+	arg := func(i int) *reflection.Expression {
+		if i < len(call.Elements) {
+			return call.Elements[i]
+		}
+		return nil
+	}
+
+	api := f.coreName(call.Callee.Value)
+	if imp, imported, ok := f.ImportOf(rootName(call.Callee.Value)); ok && imp.Module == angularCore+"/rxjs-interop" {
+		api = imported
+	}
+	switch api {
+	case "input", "input.required", "model", "model.required":
+		required := strings.HasSuffix(api, ".required")
+		options := arg(1)
+		if required {
+			options = arg(0)
+		}
+		alias := member.Name
+		if value, ok := c.stringProperty(f, options, "alias"); ok {
+			alias = value
+		}
+		meta.Inputs[member.Name] = view.R3InputMetadata{
+			ClassPropertyName:   member.Name,
+			BindingPropertyName: alias,
+			Required:            required,
+			IsSignal:            true,
+		}
+		if strings.HasPrefix(api, "model") {
+			meta.Outputs[member.Name+"Change"] = alias + "Change"
+		}
+	case "output", "outputFromObservable":
+		options := arg(0)
+		if api == "outputFromObservable" {
+			options = arg(1)
+		}
+		alias := member.Name
+		if value, ok := c.stringProperty(f, options, "alias"); ok {
+			alias = value
+		}
+		meta.Outputs[member.Name] = alias
+	case "viewChild", "viewChild.required", "viewChildren", "contentChild", "contentChild.required", "contentChildren":
+		if arg(0) == nil {
+			c.report(f, diagnostics.ValueHasWrongType, call.Start, call.End, api+" must have a locator.")
+			return
+		}
+		kind := strings.TrimSuffix(api, ".required")
+		c.addQuery(f, meta, member.Name, strings.ToUpper(kind[:1])+kind[1:], arg(0), arg(1), true)
+	}
+}
+
+// addQuery adds a view or content query. kind is the name of the decorator, e.g. `ViewChild`.
+func (c *Compiler) addQuery(f *sourceFile, meta *view.R3DirectiveMetadata, propertyName string, kind string, predicate, options *reflection.Expression, isSignal bool) {
+	isContent := strings.HasPrefix(kind, "Content")
+	first := !strings.HasSuffix(kind, "Children")
+	query := view.R3QueryMetadata{
+		PropertyName:            propertyName,
+		First:                   first,
+		Descendants:             !isContent || first,
+		EmitDistinctChangesOnly: true,
+		IsSignal:                isSignal,
+	}
+
+	if selectors, ok := predicate.StringValue(); ok {
+		var names []string
+		for _, name := range strings.Split(selectors, ",") {
+			names = append(names, strings.TrimSpace(name))
+		}
+		query.Predicate = names
+	} else {
+		ref := c.reference(f, predicate)
+		query.Predicate = render3.MaybeForwardRefExpression{Expression: ref.Value, ForwardRef: render3.ForwardRefHandlingNone}
+	}
+
+	if read := options.Property("read"); read != nil {
+		expr := c.reference(f, read).Value
+		query.Read = &expr
+	}
+	query.Descendants = c.boolProperty(f, options, "descendants", query.Descendants)
+	query.EmitDistinctChangesOnly = c.boolProperty(f, options, "emitDistinctChangesOnly", query.EmitDistinctChangesOnly)
+	if !isSignal {
+		query.Static = c.boolProperty(f, options, "static", false)
+	}
+
+	if isContent {
+		meta.Queries = append(meta.Queries, query)
+	} else {
+		meta.ViewQueries = append(meta.ViewQueries, query)
+	}
+}
+
+// hostDirective reads an element of `hostDirectives`: a directive or
+// `{directive, inputs, outputs}`.
+func (c *Compiler) hostDirective(f *sourceFile, expr *reflection.Expression) view.R3HostDirectiveMetadata {
+	if expr.Kind != reflection.ExpressionObject {
+		return view.R3HostDirectiveMetadata{Directive: c.reference(f, expr)}
+	}
+	hostDirective := view.R3HostDirectiveMetadata{}
+	if directive := expr.Property("directive"); directive != nil {
+		hostDirective.Directive = c.reference(f, directive)
+	} else {
+		c.report(f, diagnostics.HostDirectiveInvalid, expr.Start, expr.End, "Host directive must have a directive.")
+		value := output.OutputExpression(output.NewLiteralExpr(nil, nil, nil))
+		hostDirective.Directive = render3.R3Reference{Value: value, Type: value}
+	}
+	readMappings := func(name string) map[string]string {
+		elements := c.arrayProperty(f, expr, name)
+		if elements == nil {
+			return nil
+		}
+		mappings := make(map[string]string)
+		for _, element := range elements {
+			if value, ok := element.StringValue(); ok {
+				name, alias := parseMapping(value)
+				mappings[name] = alias
+			}
+		}
+		return mappings
+	}
+	hostDirective.Inputs = readMappings("inputs")
+	hostDirective.Outputs = readMappings("outputs")
+	return hostDirective
+}
+
+// parseMapping splits `name: alias` into its parts. The alias defaults to the name.
+func parseMapping(value string) (string, string) {
+	name, alias, found := strings.Cut(value, ":")
+	name = strings.TrimSpace(name)
+	if !found {
+		return name, name
+	}
+	return name, strings.TrimSpace(alias)
+}
+
+// stringProperty reads a string property of an object literal. A property with another type
+// is reported.
+func (c *Compiler) stringProperty(f *sourceFile, obj *reflection.Expression, name string) (string, bool) {
+	expr := obj.Property(name)
+	if expr == nil {
+		return "", false
+	}
+	value, ok := expr.StringValue()
+	if !ok {
+		c.report(f, diagnostics.ValueHasWrongType, expr.Start, expr.End, "'"+name+"' must be a string.")
+	}
+	return value, ok
+}
+
+// boolProperty reads a boolean property of an object literal, returning defaultValue when it is
+// absent. A property with another type is reported.
+func (c *Compiler) boolProperty(f *sourceFile, obj *reflection.Expression, name string, defaultValue bool) bool {
+	expr := obj.Property(name)
+	if expr == nil {
+		return defaultValue
+	}
+	value, ok := expr.BoolValue()
+	if !ok {
+		c.report(f, diagnostics.ValueHasWrongType, expr.Start, expr.End, "'"+name+"' must be a boolean.")
+		return defaultValue
+	}
+	return value
+}
+
+// arrayProperty returns the elements of an array property of an object literal. A property with
+// another type is reported.
+func (c *Compiler) arrayProperty(f *sourceFile, obj *reflection.Expression, name string) []*reflection.Expression {
+	expr := obj.Property(name)
+	if expr == nil {
+		return nil
+	}
+	if expr.Kind != reflection.ExpressionArray {
+		c.report(f, diagnostics.ValueHasWrongType, expr.Start, expr.End, "'"+name+"' must be an array.")
+		return nil
+	}
+	if expr.Elements == nil {
+		return []*reflection.Expression{}
+	}
+	return expr.Elements
+}
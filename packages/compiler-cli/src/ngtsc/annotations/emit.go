@@ -0,0 +1,103 @@
+package annotations
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/output"
+)
+
+// EmitPartialModule prints the ES module which adds the partial declarations to the decorated
+// classes of a file. The module imports the classes from the JavaScript module of the file,
+// `./<name>.js` next to it, and re-exports everything from there. The classes of that module must
+// not be decorated, see EmitUndecorated. It returns an empty string when
+// the file has no class to compile.
+func (c *Compiler) EmitPartialModule(sf *reflection.SourceFile) string {
+	compiled := c.CompilePartial(sf)
+	if len(compiled) == 0 {
+		return ""
+	}
+
+	var stmts []output.OutputStatement
+	for _, cc := range compiled {
+		class := output.NewReadVarExpr(cc.Class.Name, nil, nil)
+		for _, res := range cc.Results {
+			field := output.NewReadPropExpr(class, res.Name, nil, nil)
+			stmts = append(stmts, output.NewExpressionStatement(field.Set(res.Initializer), nil, nil))
+			stmts = append(stmts, res.Statements...)
+		}
+		stmts = append(stmts, output.NewExpressionStatement(cc.Metadata, nil, nil))
+	}
+
+	self := "./" + strings.TrimSuffix(path.Base(sf.FileName), ".ts") + ".js"
+	preamble := importDeclarations(sf, c.References(sf), self)
+	source := output.JavaScriptEmitter{}.EmitStatements(sf.FileName, stmts, preamble)
+	return source + fmt.Sprintf("export * from '%s';\n", self)
+}
+
+// EmitUndecorated prints a source file without the Angular decorators of the classes which
+// CompilePartial compiles, for tsc to emit the JavaScript module EmitPartialModule imports them
+// from: the decorators would define the definitions as getters, which the declarations cannot be
+// assigned to. The rest of the file is unchanged.
+func (c *Compiler) EmitUndecorated(sf *reflection.SourceFile) string {
+	var edits []sourceEdit
+	for _, class := range sf.Classes {
+		if ac := c.byClass[class]; ac != nil && c.isValid(ac) {
+			edits = append(edits, c.decoratorEdits(ac)...)
+		}
+	}
+	return applyEdits(sf.Text, edits)
+}
+
+// importDeclarations imports the local names used by generated code the way the source file
+// gets them: from its own JavaScript module when it declares them, and from the module it
+// imports them from otherwise.
+func importDeclarations(sf *reflection.SourceFile, names []string, self string) string {
+	var modules []string
+	specifiers := make(map[string][]string)
+	var lines []string
+	for _, name := range names {
+		imp, imported, ok := sf.ImportOf(name)
+		if !ok {
+			if _, seen := specifiers[self]; !seen {
+				modules = append(modules, self)
+			}
+			specifiers[self] = append(specifiers[self], name)
+			continue
+		}
+		module := moduleSpecifier(imp.Module)
+		switch imported {
+		case "*":
+			lines = append(lines, fmt.Sprintf("import * as %s from '%s';", name, module))
+		case "default":
+			lines = append(lines, fmt.Sprintf("import %s from '%s';", name, module))
+		default:
+			spec := name
+			if imported != name {
+				spec = imported + " as " + name
+			}
+			if _, seen := specifiers[module]; !seen {
+				modules = append(modules, module)
+			}
+			specifiers[module] = append(specifiers[module], spec)
+		}
+	}
+	for _, module := range modules {
+		lines = append(lines, fmt.Sprintf("import { %s } from '%s';", strings.Join(specifiers[module], ", "), module))
+	}
+	if lines == nil {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// moduleSpecifier adds the `.js` extension to relative module specifiers, which ES modules
+// require.
+func moduleSpecifier(module string) string {
+	if !strings.HasPrefix(module, ".") || path.Ext(module) == ".js" || path.Ext(module) == ".mjs" {
+		return module
+	}
+	return strings.TrimSuffix(module, ".ts") + ".js"
+}
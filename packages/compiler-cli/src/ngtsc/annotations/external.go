@@ -0,0 +1,498 @@
+package annotations
+
+import (
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+// externalClass is a directive, component, pipe or NgModule of a package outside of the program,
+// read from the declarations of its definitions in the declaration files of the package, e.g.
+// `static ɵdir: i0.ɵɵDirectiveDeclaration<NgIf<any>, "[ngIf]", ...>`.
+type externalClass struct {
+	name string
+	// fileName is the declaration file of the class, start and end its span there.
+	fileName   string
+	start, end int
+	kind       decoratorKind
+
+	// matchable is the directive or component as seen by the template binder, nil when it has no
+	// selector. inputs and outputs are sorted by class property.
+	matchable *directiveMeta
+	inputs    []*ScopeBinding
+	outputs   []*ScopeBinding
+	pipeName  string
+	// exports are the directives, components and pipes exported by an NgModule, including the
+	// ones of the NgModules it re-exports. complete is unset when some of them are unknown.
+	exports  []*externalClass
+	complete bool
+}
+
+// definitionKinds maps the types declaring the definitions of classes to their kind.
+var definitionKinds = map[string]decoratorKind{
+	"ɵɵDirectiveDeclaration": kindDirective,
+	"ɵɵComponentDeclaration": kindComponent,
+	"ɵɵPipeDeclaration":      kindPipe,
+	"ɵɵNgModuleDeclaration":  kindNgModule,
+}
+
+// maxDeclarationDepth bounds the chains of imports and re-exports followed to find a class.
+const maxDeclarationDepth = 16
+
+// packageClass returns the class a file of the program imports by name from a package, nil when
+// it is not a directive, component, pipe or NgModule, or when the declaration files of the
+// package cannot be read.
+func (c *Compiler) packageClass(f *sourceFile, name string) *externalClass {
+	imp, imported, ok := f.ImportOf(name)
+	if !ok || imp.TypeOnly || imported == "*" || imported == "default" || strings.HasPrefix(imp.Module, ".") {
+		return nil
+	}
+	typings := c.packageTypings(imp.Module)
+	if typings == "" {
+		return nil
+	}
+	return c.declaredClass(typings, imported, 0)
+}
+
+// declaredClass returns the class exported by a declaration file under a name, following its
+// imports and re-exports.
+func (c *Compiler) declaredClass(fileName string, name string, depth int) *externalClass {
+	key := fileName + "#" + name
+	if ext, ok := c.externalClasses[key]; ok {
+		return ext
+	}
+	var ext *externalClass
+	if depth < maxDeclarationDepth {
+		// Cycles of re-exports end on the nil recorded here.
+		c.externalClasses[key] = nil
+		ext = c.findDeclaredClass(fileName, name, depth)
+	}
+	c.externalClasses[key] = ext
+	return ext
+}
+
+func (c *Compiler) findDeclaredClass(fileName string, name string, depth int) *externalClass {
+	sf := c.declarationFile(fileName)
+	if sf == nil {
+		return nil
+	}
+	if class := sf.Class(name); class != nil {
+		return c.newExternalClass(sf, class, depth)
+	}
+	if imp, imported, ok := sf.ImportOf(name); ok {
+		if imported == "*" || imported == "default" {
+			return nil
+		}
+		if target := c.declarationTarget(fileName, imp.Module); target != "" {
+			return c.declaredClass(target, imported, depth+1)
+		}
+		return nil
+	}
+	for _, stmt := range sf.Statements {
+		if stmt.Kind != reflection.StatementExport {
+			continue
+		}
+		exported, module, ok := reExport(sf.Text[stmt.Start:stmt.End], name)
+		if !ok {
+			continue
+		}
+		target := fileName
+		if module != "" {
+			target = c.declarationTarget(fileName, module)
+		}
+		if target == "" || target == fileName && exported == name {
+			continue
+		}
+		if ext := c.declaredClass(target, exported, depth+1); ext != nil {
+			return ext
+		}
+	}
+	return nil
+}
+
+// reExport reads an export statement, e.g. `export { A as B } from './a'` or `export * from
+// './a'`, returning the name under which the exported name comes from module, empty for the
+// statement's own file. ok is false when the statement does not export the name.
+func reExport(text string, name string) (exported string, module string, ok bool) {
+	tokens := reflection.Tokenize(text)
+	if len(tokens) < 2 || !tokens[0].Is("export") {
+		return "", "", false
+	}
+	i := 1
+	if tokens[i].Is("type") {
+		return "", "", false
+	}
+	switch {
+	case tokens[i].Is("*"):
+		i++
+		if !tokens[i].Is("from") || tokens[i+1].Kind != reflection.TokenString {
+			return "", "", false
+		}
+		module, _ = reflection.ParseExpression(tokens[i+1].Text).StringValue()
+		return name, module, true
+	case tokens[i].Is("{"):
+		for i++; i < len(tokens) && !tokens[i].Is("}"); i++ {
+			if tokens[i].Kind != reflection.TokenIdentifier || tokens[i].Is("type") && tokens[i+1].Kind == reflection.TokenIdentifier {
+				continue
+			}
+			local, alias := tokens[i].Text, tokens[i].Text
+			if tokens[i+1].Is("as") && tokens[i+2].Kind == reflection.TokenIdentifier {
+				alias = tokens[i+2].Text
+				i += 2
+			}
+			if alias == name {
+				exported, ok = local, true
+			}
+		}
+		if !ok {
+			return "", "", false
+		}
+		if i+2 < len(tokens) && tokens[i+1].Is("from") && tokens[i+2].Kind == reflection.TokenString {
+			module, _ = reflection.ParseExpression(tokens[i+2].Text).StringValue()
+		}
+		return exported, module, true
+	}
+	return "", "", false
+}
+
+// newExternalClass reads the definitions a class of a declaration file declares.
+func (c *Compiler) newExternalClass(sf *reflection.SourceFile, class *reflection.ClassDeclaration, depth int) *externalClass {
+	for _, member := range class.Members {
+		if !member.IsStatic {
+			continue
+		}
+		typeName, args := typeArguments(member.Type)
+		if dot := strings.LastIndex(typeName, "."); dot >= 0 {
+			typeName = typeName[dot+1:]
+		}
+		kind, ok := definitionKinds[typeName]
+		if !ok {
+			continue
+		}
+		ext := &externalClass{name: class.Name, fileName: sf.FileName, start: class.KeywordStart, end: class.End, kind: kind}
+		switch kind {
+		case kindDirective, kindComponent:
+			ext.readDirective(class, args)
+		case kindPipe:
+			if len(args) > 1 {
+				ext.pipeName, _ = reflection.ParseExpression(args[1]).StringValue()
+			}
+		case kindNgModule:
+			ext.complete = true
+			if len(args) > 3 {
+				c.readModuleExports(ext, sf, args[3], depth, map[*externalClass]bool{})
+			}
+		}
+		return ext
+	}
+	return nil
+}
+
+// readDirective reads the type arguments of `ɵɵDirectiveDeclaration` or `ɵɵComponentDeclaration`:
+// the class, selector, exportAs, inputs, outputs, queries and, for components, the content
+// selectors.
+func (ext *externalClass) readDirective(class *reflection.ClassDeclaration, args []string) {
+	if len(args) < 5 {
+		return
+	}
+	selector, ok := reflection.ParseExpression(args[1]).StringValue()
+	if !ok {
+		return
+	}
+	dir := &directiveMeta{
+		name:        class.Name,
+		selector:    &selector,
+		isComponent: ext.kind == kindComponent,
+		inputs:      make(bindingNames),
+		outputs:     make(bindingNames),
+		exportAs:    stringTuple(args[2]),
+	}
+	ext.inputs = bindingMap(args[3])
+	ext.outputs = bindingMap(args[4])
+	for _, input := range ext.inputs {
+		dir.inputs[input.Name] = input.Property
+	}
+	for _, output := range ext.outputs {
+		dir.outputs[output.Name] = output.Property
+	}
+	if ext.kind == kindComponent && len(args) > 6 {
+		dir.ngContentSelectors = stringTuple(args[6])
+	}
+	if ctor := class.Constructor; ctor != nil {
+		for _, param := range ctor.Parameters {
+			if name := param.TypeName(); name == "TemplateRef" || strings.HasSuffix(name, ".TemplateRef") {
+				dir.isStructural = true
+			}
+		}
+	}
+	ext.matchable = dir
+}
+
+// readModuleExports adds the directives, components and pipes of the exports of an NgModule,
+// `[typeof NgIf, typeof i1.NgClass]`, to ext. The names are resolved in the declaration file sf.
+func (c *Compiler) readModuleExports(ext *externalClass, sf *reflection.SourceFile, exports string, depth int, visited map[*externalClass]bool) {
+	tokens := reflection.Tokenize(exports)
+	for i := 0; i < len(tokens); i++ {
+		if !tokens[i].Is("typeof") {
+			continue
+		}
+		var names []string
+		for i++; i < len(tokens) && tokens[i].Kind == reflection.TokenIdentifier; i += 2 {
+			names = append(names, tokens[i].Text)
+			if !tokens[i+1].Is(".") {
+				break
+			}
+		}
+		exported := c.referencedClass(sf, names, depth+1)
+		switch {
+		case exported == nil:
+			ext.complete = false
+		case exported.kind == kindNgModule:
+			if !visited[exported] {
+				visited[exported] = true
+				ext.exports = append(ext.exports, exported.exports...)
+				ext.complete = ext.complete && exported.complete
+			}
+		default:
+			ext.exports = append(ext.exports, exported)
+		}
+	}
+}
+
+// referencedClass resolves a name of a declaration file, e.g. `NgIf` or `i1.NgClass`.
+func (c *Compiler) referencedClass(sf *reflection.SourceFile, names []string, depth int) *externalClass {
+	switch len(names) {
+	case 1:
+		return c.declaredClass(sf.FileName, names[0], depth)
+	case 2:
+		for _, imp := range sf.Imports {
+			if imp.NamespaceName == names[0] {
+				if target := c.declarationTarget(sf.FileName, imp.Module); target != "" {
+					return c.declaredClass(target, names[1], depth)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// declarationFile reads and reflects a declaration file, nil when it cannot be read.
+func (c *Compiler) declarationFile(fileName string) *reflection.SourceFile {
+	if sf, ok := c.declarationFiles[fileName]; ok {
+		return sf
+	}
+	var sf *reflection.SourceFile
+	if text, ok := c.readFile(fileName); ok {
+		sf = reflection.ReflectSourceFile(fileName, text)
+	}
+	c.declarationFiles[fileName] = sf
+	return sf
+}
+
+// declarationTarget returns the declaration file a module specifier of a declaration file refers
+// to, e.g. `./common_module.d-CGcJCPNP.d.ts` for `./common_module.d-CGcJCPNP.js`.
+func (c *Compiler) declarationTarget(fileName string, specifier string) string {
+	if !strings.HasPrefix(specifier, ".") {
+		return c.packageTypings(specifier)
+	}
+	base := path.Join(path.Dir(fileName), specifier)
+	base = strings.TrimSuffix(strings.TrimSuffix(base, ".js"), ".mjs")
+	for _, candidate := range []string{base + ".d.ts", base + "/index.d.ts", base} {
+		if strings.HasSuffix(candidate, ".d.ts") && c.declarationFile(candidate) != nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// packageTypings returns the declaration file of the entry point of a package, e.g.
+// `node_modules/@angular/common/index.d.ts`, looked up in the node_modules directories of the
+// root directory and its parents. It is empty when there is none.
+func (c *Compiler) packageTypings(module string) string {
+	if typings, ok := c.typings[module]; ok {
+		return typings
+	}
+	parts := strings.Split(module, "/")
+	pkgLen := 1
+	if strings.HasPrefix(module, "@") {
+		pkgLen = 2
+	}
+	typings := ""
+	if len(parts) >= pkgLen {
+		pkg, subpath := strings.Join(parts[:pkgLen], "/"), strings.Join(parts[pkgLen:], "/")
+		for dir := path.Clean(c.options.RootDir); typings == ""; dir = path.Dir(dir) {
+			typings = c.entryPointTypings(path.Join(dir, "node_modules", pkg), subpath)
+			if path.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+	c.typings[module] = typings
+	return typings
+}
+
+// entryPointTypings returns the declaration file of an entry point of the package in pkgDir, the
+// main one when subpath is empty.
+func (c *Compiler) entryPointTypings(pkgDir string, subpath string) string {
+	entryDir := path.Join(pkgDir, subpath)
+	var candidates []string
+	if manifest, ok := c.readManifest(entryDir); ok {
+		for _, typings := range []string{manifest.Typings, manifest.Types} {
+			if typings != "" {
+				candidates = append(candidates, path.Join(entryDir, typings))
+			}
+		}
+	}
+	candidates = append(candidates, path.Join(entryDir, "index.d.ts"))
+	if manifest, ok := c.readManifest(pkgDir); ok {
+		key := "."
+		if subpath != "" {
+			key = "./" + subpath
+		}
+		if types := exportTypes(manifest.Exports[key]); types != "" {
+			candidates = append(candidates, path.Join(pkgDir, types))
+		}
+	}
+	for _, candidate := range candidates {
+		if c.declarationFile(candidate) != nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// packageManifest is the part of a package.json locating the declaration files of a package.
+type packageManifest struct {
+	Typings string                     `json:"typings"`
+	Types   string                     `json:"types"`
+	Exports map[string]json.RawMessage `json:"exports"`
+}
+
+func (c *Compiler) readManifest(dir string) (*packageManifest, bool) {
+	text, ok := c.readFile(path.Join(dir, "package.json"))
+	if !ok {
+		return nil, false
+	}
+	var manifest packageManifest
+	if err := json.Unmarshal([]byte(text), &manifest); err != nil {
+		return nil, false
+	}
+	return &manifest, true
+}
+
+// exportTypes returns the `types` condition of an entry of the `exports` of a package.json.
+func exportTypes(entry json.RawMessage) string {
+	var conditions map[string]json.RawMessage
+	if len(entry) == 0 || json.Unmarshal(entry, &conditions) != nil {
+		return ""
+	}
+	var types string
+	if json.Unmarshal(conditions["types"], &types) == nil {
+		return types
+	}
+	return ""
+}
+
+// readFile reads a file of a package through the resource loader.
+func (c *Compiler) readFile(fileName string) (string, bool) {
+	if c.options.ResourceLoader == nil {
+		return "", false
+	}
+	text, err := c.options.ResourceLoader(fileName)
+	return text, err == nil
+}
+
+// typeArguments splits a generic type, e.g. `i0.ɵɵPipeDeclaration<P, "p", true>`, into its name
+// and the text of its type arguments.
+func typeArguments(typ string) (string, []string) {
+	tokens := reflection.Tokenize(typ)
+	open := -1
+	for i, tok := range tokens {
+		if tok.Is("<") {
+			open = i
+			break
+		}
+	}
+	if open < 0 {
+		return strings.TrimSpace(typ), nil
+	}
+	name := strings.TrimSpace(typ[:tokens[open].Start])
+	var args []string
+	depth, start := 0, tokens[open].End
+	for _, tok := range tokens[open:] {
+		switch {
+		case tok.Is("<") || tok.Is("(") || tok.Is("[") || tok.Is("{"):
+			depth++
+		case tok.Is(">") || tok.Is(")") || tok.Is("]") || tok.Is("}"):
+			depth--
+		case tok.Is(">>"):
+			depth -= 2
+		case tok.Is(",") && depth == 1:
+			args = append(args, strings.TrimSpace(typ[start:tok.Start]))
+			start = tok.End
+			continue
+		default:
+			continue
+		}
+		if depth <= 0 {
+			return name, append(args, strings.TrimSpace(typ[start:tok.Start]))
+		}
+	}
+	return name, args
+}
+
+// stringTuple reads a tuple of string literal types, e.g. `["ngForm"]`, nil for `never`.
+func stringTuple(typ string) []string {
+	var values []string
+	for _, tok := range reflection.Tokenize(typ) {
+		if tok.Kind == reflection.TokenString {
+			value, _ := reflection.ParseExpression(tok.Text).StringValue()
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// bindingMap reads the inputs or outputs of a directive declaration, keyed by class property:
+// `{ "ngIf": "ngIf"; }`, or `{ "ngIf": { "alias": "ngIf"; "required": false; }; }` for inputs
+// since Angular 16.
+func bindingMap(typ string) []*ScopeBinding {
+	tokens := reflection.Tokenize(typ)
+	var bindings []*ScopeBinding
+	key := func(tok reflection.Token) string {
+		if tok.Kind == reflection.TokenString {
+			value, _ := reflection.ParseExpression(tok.Text).StringValue()
+			return value
+		}
+		return tok.Text
+	}
+	for i := 1; i+2 < len(tokens); i++ {
+		if tokens[i+1].Is(":") && (tokens[i-1].Is("{") || tokens[i-1].Is(";")) {
+			binding := &ScopeBinding{Property: key(tokens[i]), Name: key(tokens[i])}
+			switch value := tokens[i+2]; {
+			case value.Kind == reflection.TokenString:
+				binding.Name = key(value)
+				i += 2
+			case value.Is("{"):
+				j := i + 3
+				for ; j < len(tokens) && !tokens[j].Is("}"); j++ {
+					if j+2 < len(tokens) && tokens[j+1].Is(":") {
+						switch key(tokens[j]) {
+						case "alias":
+							binding.Name = key(tokens[j+2])
+						case "required":
+							binding.Required = tokens[j+2].Is("true")
+						}
+					}
+				}
+				i = j
+			}
+			bindings = append(bindings, binding)
+		}
+	}
+	sort.SliceStable(bindings, func(i, j int) bool { return bindings[i].Property < bindings[j].Property })
+	return bindings
+}
@@ -0,0 +1,164 @@
+package annotations
+
+import (
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/facade"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/partial"
+)
+
+// constructorDeps reads the dependencies of the constructor of a class. It returns nil when the
+// class inherits its constructor and "invalid" when a parameter has no injection token.
+func (c *Compiler) constructorDeps(ac *analyzedClass) interface{} {
+	f := ac.file
+	ctor := ac.class.Constructor
+	if ctor == nil {
+		if ac.class.Extends != "" {
+			return nil
+		}
+		return []render3.R3DependencyMetadata{}
+	}
+
+	deps := make([]render3.R3DependencyMetadata, 0, len(ctor.Parameters))
+	valid := true
+	for _, param := range ctor.Parameters {
+		var dep render3.R3DependencyMetadata
+		for _, dec := range param.Decorators {
+			switch name := f.coreName(dec.Name); name {
+			case "Inject", "Attribute":
+				if len(dec.Args) != 1 {
+					c.report(f, diagnostics.DecoratorArityWrong, dec.Start, dec.End, "Decorator '@"+name+"' expects exactly one argument.")
+					continue
+				}
+				dep.Token = f.wrap(dec.Args[0])
+				if value, ok := dec.Args[0].StringValue(); ok && name == "Attribute" {
+					dep.AttributeNameType = output.NewLiteralExpr(value, nil, nil)
+				}
+			case "Optional":
+				dep.Optional = true
+			case "Self":
+				dep.Self = true
+			case "SkipSelf":
+				dep.SkipSelf = true
+			case "Host":
+				dep.Host = true
+			}
+		}
+		if dep.Token == nil {
+			if typeName := param.TypeName(); typeName != "" && f.isValue(rootName(typeName)) {
+				dep.Token = f.wrapText(typeName)
+			}
+		}
+		if dep.Token == nil {
+			c.report(f, diagnostics.ParamMissingToken, ctor.Start, ctor.End,
+				"No suitable injection token for parameter '"+param.Name+"' of class '"+ac.class.Name+"'.\n"+
+					"  Consider using the @Inject decorator to specify an injection token.")
+			valid = false
+			continue
+		}
+		deps = append(deps, dep)
+	}
+	if !valid {
+		return "invalid"
+	}
+	return deps
+}
+
+// rootName returns the first identifier of a property chain.
+func rootName(name string) string {
+	root, _, _ := strings.Cut(name, ".")
+	return root
+}
+
+// factoryTarget returns the factory target of a decorator kind.
+func factoryTarget(kind decoratorKind) facade.FactoryTarget {
+	switch kind {
+	case kindComponent:
+		return facade.FactoryTargetComponent
+	case kindPipe:
+		return facade.FactoryTargetPipe
+	case kindNgModule:
+		return facade.FactoryTargetNgModule
+	case kindInjectable:
+		return facade.FactoryTargetInjectable
+	}
+	return facade.FactoryTargetDirective
+}
+
+// classMetadata records the Angular decorators of a class, its constructor parameters and its
+// members, which TestBed uses to recompile classes with overrides.
+func (c *Compiler) classMetadata(ac *analyzedClass) partial.R3ClassMetadata {
+	f := ac.file
+	meta := partial.R3ClassMetadata{
+		Type:       f.readVar(ac.class.Name),
+		Decorators: output.NewLiteralArrayExpr([]output.OutputExpression{decoratorMetadata(f, ac.decorator)}, nil, nil),
+	}
+
+	if ctor := ac.class.Constructor; ctor != nil {
+		params := make([]output.OutputExpression, len(ctor.Parameters))
+		for i, param := range ctor.Parameters {
+			var typ output.OutputExpression = output.NewWrappedNodeExpr("undefined", nil, nil)
+			if typeName := param.TypeName(); typeName != "" && f.isValue(rootName(typeName)) {
+				typ = f.wrapText(typeName)
+			}
+			entries := []*output.LiteralMapEntry{output.NewLiteralMapEntry("type", typ, false)}
+			if decorators := angularDecorators(f, param.Decorators); decorators != nil {
+				entries = append(entries, output.NewLiteralMapEntry("decorators", decorators, false))
+			}
+			params[i] = output.NewLiteralMapExpr(entries, nil, nil)
+		}
+		var ctorParameters output.OutputExpression = output.NewArrowFunctionExpr(
+			nil, output.NewLiteralArrayExpr(params, nil, nil), nil, nil)
+		meta.CtorParameters = &ctorParameters
+	}
+
+	var props []*output.LiteralMapEntry
+	for _, member := range ac.class.Members {
+		if decorators := angularDecorators(f, member.Decorators); decorators != nil {
+			props = append(props, output.NewLiteralMapEntry(member.Name, decorators, !isIdentifierName(member.Name)))
+		}
+	}
+	if props != nil {
+		var propDecorators output.OutputExpression = output.NewLiteralMapExpr(props, nil, nil)
+		meta.PropDecorators = &propDecorators
+	}
+	return meta
+}
+
+// angularDecorators returns the metadata of the decorators from `@angular/core`, or nil when
+// there are none.
+func angularDecorators(f *sourceFile, decorators []*reflection.Decorator) output.OutputExpression {
+	var entries []output.OutputExpression
+	for _, dec := range decorators {
+		if f.coreName(dec.Name) != "" {
+			entries = append(entries, decoratorMetadata(f, dec))
+		}
+	}
+	if entries == nil {
+		return nil
+	}
+	return output.NewLiteralArrayExpr(entries, nil, nil)
+}
+
+// decoratorMetadata returns `{type: Decorator, args: [...]}` for a decorator.
+func decoratorMetadata(f *sourceFile, dec *reflection.Decorator) output.OutputExpression {
+	entries := []*output.LiteralMapEntry{output.NewLiteralMapEntry("type", f.wrapText(dec.Name), false)}
+	if len(dec.Args) > 0 {
+		args := make([]output.OutputExpression, len(dec.Args))
+		for i, arg := range dec.Args {
+			args[i] = f.wrap(arg)
+		}
+		entries = append(entries, output.NewLiteralMapEntry("args", output.NewLiteralArrayExpr(args, nil, nil), false))
+	}
+	return output.NewLiteralMapExpr(entries, nil, nil)
+}
+
+// isIdentifierName reports whether a property name can be written without quotes.
+func isIdentifierName(name string) bool {
+	tokens := reflection.Tokenize(name)
+	return len(tokens) == 2 && tokens[0].Kind == reflection.TokenIdentifier && tokens[0].Text == name && name[0] != '#'
+}
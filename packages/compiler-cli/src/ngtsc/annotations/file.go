@@ -0,0 +1,152 @@
+package annotations
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// angularCore is the module the Angular decorators and initializer APIs are imported from.
+const angularCore = "@angular/core"
+
+// sourceFile is a reflected source file along with the local names its generated code refers
+// to. Expressions of decorators are copied verbatim into the generated code, so the names they
+// use must be imported again from where the source file got them.
+type sourceFile struct {
+	*reflection.SourceFile
+	parseFile  *util.ParseSourceFile
+	lineStarts []int
+
+	references []string
+	referenced map[string]bool
+}
+
+func newSourceFile(sf *reflection.SourceFile) *sourceFile {
+	f := &sourceFile{
+		SourceFile: sf,
+		parseFile:  util.NewParseSourceFile(sf.Text, sf.FileName),
+		lineStarts: []int{0},
+		referenced: make(map[string]bool),
+	}
+	for i := 0; i < len(sf.Text); i++ {
+		if sf.Text[i] == '\n' {
+			f.lineStarts = append(f.lineStarts, i+1)
+		}
+	}
+	return f
+}
+
+// span returns the source span of sf.Text[start:end].
+func (f *sourceFile) span(start, end int) *util.ParseSourceSpan {
+	return util.NewParseSourceSpan(f.location(start), f.location(end), nil, nil)
+}
+
+func (f *sourceFile) location(offset int) *util.ParseLocation {
+	if offset > len(f.Text) {
+		offset = len(f.Text)
+	}
+	line := sort.Search(len(f.lineStarts), func(i int) bool { return f.lineStarts[i] > offset }) - 1
+	return util.NewParseLocation(f.parseFile, offset, line, offset-f.lineStarts[line])
+}
+
+// coreName returns the name an identifier or property chain has in `@angular/core`, or an
+// empty string when it doesn't come from there, e.g. `Component` for `ng.Component` given
+// `import * as ng from '@angular/core'`.
+func (f *sourceFile) coreName(name string) string {
+	root, rest, dotted := strings.Cut(name, ".")
+	imp, imported, ok := f.ImportOf(root)
+	if !ok || imp.Module != angularCore {
+		return ""
+	}
+	switch {
+	case imported == "*" && dotted:
+		return rest
+	case imported == "*" || imported == "default":
+		return ""
+	case dotted:
+		return imported + "." + rest
+	}
+	return imported
+}
+
+// isValue reports whether a local name exists at runtime and can be imported by the generated
+// code: it is imported by a declaration which is not type-only, or exported by the file.
+func (f *sourceFile) isValue(name string) bool {
+	imp, _, ok := f.ImportOf(name)
+	if ok {
+		if imp.TypeOnly {
+			return false
+		}
+		for _, spec := range imp.Specifiers {
+			if spec.Name == name {
+				return !spec.TypeOnly
+			}
+		}
+		return true
+	}
+	return f.IsExported(name)
+}
+
+// reference records that the generated code uses a local name of the file.
+func (f *sourceFile) reference(name string) {
+	if f.referenced[name] || !f.isValue(name) {
+		return
+	}
+	f.referenced[name] = true
+	f.references = append(f.references, name)
+}
+
+// wrap copies an expression of the source into the generated code.
+func (f *sourceFile) wrap(expr *reflection.Expression) output.OutputExpression {
+	return f.wrapText(expr.Text)
+}
+
+// wrapText copies source text into the generated code, recording the local names it uses.
+func (f *sourceFile) wrapText(text string) output.OutputExpression {
+	for _, name := range freeIdentifiers(text) {
+		f.reference(name)
+	}
+	return output.NewWrappedNodeExpr(text, nil, nil)
+}
+
+// readVar refers to a local name of the file.
+func (f *sourceFile) readVar(name string) output.OutputExpression {
+	f.reference(name)
+	return output.NewReadVarExpr(name, nil, nil)
+}
+
+// dir returns the directory of the file.
+func (f *sourceFile) dir() string {
+	return path.Dir(f.FileName)
+}
+
+// notFreeIdentifiers are the keywords that can appear where an identifier is expected.
+var notFreeIdentifiers = map[string]bool{
+	"new": true, "function": true, "return": true, "true": true, "false": true, "null": true,
+	"undefined": true, "this": true, "typeof": true, "void": true, "in": true, "of": true,
+	"instanceof": true, "async": true, "await": true, "const": true, "let": true, "var": true,
+}
+
+// freeIdentifiers returns the identifiers of an expression which may refer to declarations of
+// the enclosing file: identifiers which are neither property names nor object literal keys.
+func freeIdentifiers(text string) []string {
+	tokens := reflection.Tokenize(text)
+	var names []string
+	for i, tok := range tokens {
+		if tok.Kind != reflection.TokenIdentifier || notFreeIdentifiers[tok.Text] {
+			continue
+		}
+		if i > 0 && (tokens[i-1].Is(".") || tokens[i-1].Is("?.")) {
+			continue
+		}
+		if i > 0 && i+1 < len(tokens) && tokens[i+1].Is(":") && (tokens[i-1].Is("{") || tokens[i-1].Is(",")) {
+			continue
+		}
+		names = append(names, tok.Text)
+	}
+	return names
+}
@@ -0,0 +1,118 @@
+package annotations
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	render3_class_metadata_compiler "ngc-go/packages/compiler/src/render3/r3_class_metadata_compiler"
+	render3_injectable_compiler "ngc-go/packages/compiler/src/render3/r3_injectable_compiler"
+	render3_injector_compiler "ngc-go/packages/compiler/src/render3/r3_injector_compiler"
+	render3_module_compiler "ngc-go/packages/compiler/src/render3/r3_module_compiler"
+	"ngc-go/packages/compiler/src/render3/view"
+	view_compiler "ngc-go/packages/compiler/src/render3/view/compiler"
+)
+
+// CompileFull compiles the decorated classes of a file into the definitions the runtime
+// executes, in the order they are declared. Constants shared by the definitions, e.g. the
+// template functions of embedded views, are added to the constant pool. Classes with errors are
+// skipped.
+func (c *Compiler) CompileFull(sf *reflection.SourceFile, constantPool *constant.ConstantPool) []*CompiledClass {
+	var compiled []*CompiledClass
+	for _, class := range sf.Classes {
+		ac := c.byClass[class]
+		if ac == nil || !c.isValid(ac) {
+			continue
+		}
+		cc := &CompiledClass{Class: class, Results: c.compileFullResults(ac, constantPool)}
+		cc.Metadata = render3_class_metadata_compiler.CompileClassMetadata(ac.metadata)
+		compiled = append(compiled, cc)
+	}
+	return compiled
+}
+
+// compileFullResults compiles the static fields of a class in full compilation mode.
+func (c *Compiler) compileFullResults(ac *analyzedClass, constantPool *constant.ConstantPool) []CompileResult {
+	fac := render3.CompileFactoryFunction(&render3.R3ConstructorFactoryMetadata{
+		Name:              ac.class.Name,
+		Type:              c.selfRef(ac),
+		TypeArgumentCount: typeArgumentCount(ac.class),
+		Deps:              ac.deps,
+		Target:            factoryTarget(ac.kind),
+	})
+	results := []CompileResult{{Name: "ɵfac", Initializer: fac.Expression, Statements: fac.Statements, Type: fac.Type}}
+
+	switch ac.kind {
+	case kindComponent:
+		res := view_compiler.CompileComponentFromMetadata(&ac.component.meta, constantPool, *view.MakeBindingParser(false))
+		results = append(results, CompileResult{Name: "ɵcmp", Initializer: res.Expression, Statements: res.Statements, Type: res.Type})
+	case kindDirective:
+		res := view_compiler.CompileDirectiveFromMetadata(ac.directive, constantPool, *view.MakeBindingParser(false))
+		results = append(results, CompileResult{Name: "ɵdir", Initializer: res.Expression, Statements: res.Statements, Type: res.Type})
+	case kindPipe:
+		res := render3.CompilePipeFromMetadata(*ac.pipe)
+		results = append(results, CompileResult{Name: "ɵpipe", Initializer: res.Expression, Statements: res.Statements, Type: res.Type})
+	case kindNgModule:
+		mod := render3_module_compiler.CompileNgModule(&ac.ngModule.meta)
+		results = append(results, CompileResult{Name: "ɵmod", Initializer: mod.Expression, Statements: mod.Statements, Type: mod.Type})
+		inj := render3_injector_compiler.CompileInjector(ac.ngModule.injector)
+		results = append(results, CompileResult{Name: "ɵinj", Initializer: inj.Expression, Statements: inj.Statements, Type: inj.Type})
+	case kindInjectable:
+		res := render3_injectable_compiler.CompileInjectable(*ac.injectable, true)
+		results = append(results, CompileResult{Name: "ɵprov", Initializer: res.Expression, Statements: res.Statements, Type: res.Type})
+	}
+	return results
+}
+
+// EmitFullModule prints the ES module which adds the full definitions to the decorated classes
+// of a file. Like EmitPartialModule, it imports the classes from `./<name>.js` and re-exports
+// everything from there. With Options.Hmr, each component is followed by its HMR initializer.
+// It returns an empty string when the file has no class to compile.
+func (c *Compiler) EmitFullModule(sf *reflection.SourceFile) string {
+	constantPool := c.newConstantPool()
+	compiled := c.CompileFull(sf, constantPool)
+	if len(compiled) == 0 {
+		return ""
+	}
+
+	var stmts []output.OutputStatement
+	for _, cc := range compiled {
+		class := output.NewReadVarExpr(cc.Class.Name, nil, nil)
+		for _, res := range cc.Results {
+			field := output.NewReadPropExpr(class, res.Name, nil, nil)
+			stmts = append(stmts, output.NewExpressionStatement(field.Set(res.Initializer), nil, c.fieldComments()))
+			stmts = append(stmts, res.Statements...)
+		}
+		stmts = append(stmts, output.NewExpressionStatement(cc.Metadata, nil, nil))
+		if ac := c.byClass[cc.Class]; c.options.Hmr && ac.kind == kindComponent {
+			stmts = append(stmts, output.NewExpressionStatement(render3.CompileHmrInitializer(c.hmrUpdate(ac).meta), nil, nil))
+		}
+	}
+	stmts = append(constantPool.GetStatements(), stmts...)
+
+	self := "./" + strings.TrimSuffix(path.Base(sf.FileName), ".ts") + ".js"
+	preamble := importDeclarations(sf, c.References(sf), self)
+	emitter := output.JavaScriptEmitter{AnnotateForClosureCompiler: c.options.ClosureCompiler}
+	source := emitter.EmitStatements(sf.FileName, stmts, preamble)
+	return source + fmt.Sprintf("export * from '%s';\n", self)
+}
+
+// newConstantPool returns the pool of the constants shared by the definitions of a file.
+func (c *Compiler) newConstantPool() *constant.ConstantPool {
+	return constant.NewConstantPool(c.options.ClosureCompiler)
+}
+
+// fieldComments returns the comments of the assignments of the static fields holding the
+// definitions. Closure Compiler would otherwise collapse the fields into variables, out of the
+// reach of the runtime, which reads them from the classes.
+func (c *Compiler) fieldComments() []*output.LeadingComment {
+	if !c.options.ClosureCompiler {
+		return nil
+	}
+	nocollapse := "nocollapse"
+	return []*output.LeadingComment{{JSDoc: output.NewJSDocComment([]output.JSDocTag{{TagName: &nocollapse}})}}
+}
@@ -0,0 +1,101 @@
+package annotations
+
+import (
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+// HmrUpdate is the update module of a component for hot module replacement. The HMR
+// initializer of the component imports it when notified of a change, and calls its default
+// export, `<Class>_UpdateMetadata`, to replace the definition of the running component.
+type HmrUpdate struct {
+	// ID identifies the component in update notifications and update module URLs.
+	ID        string
+	ClassName string
+	FileName  string
+	// Module is the source of the update module.
+	Module string
+	// Dependencies are the module names of the namespaces and the local names which the
+	// initializer passes to the update function, in order. An update module can only be applied
+	// by an initializer emitted with the same dependencies.
+	Dependencies []string
+}
+
+// hmrUpdateResult is the update function of a component with the metadata its initializer is
+// compiled from.
+type hmrUpdateResult struct {
+	fn   *output.DeclareFunctionStmt
+	meta render3.R3HmrMetadata
+}
+
+// HmrUpdates compiles the update modules of the components of a file.
+func (c *Compiler) HmrUpdates(sf *reflection.SourceFile) []*HmrUpdate {
+	var updates []*HmrUpdate
+	for _, class := range sf.Classes {
+		ac := c.byClass[class]
+		if ac == nil || ac.kind != kindComponent || !c.isValid(ac) {
+			continue
+		}
+		res := c.hmrUpdate(ac)
+		update := &HmrUpdate{
+			ID:        render3.HmrComponentID(res.meta),
+			ClassName: class.Name,
+			FileName:  sf.FileName,
+			Module:    "export default " + emitStatement(output.NewJsEmitterVisitor(), res.fn),
+		}
+		for _, dep := range res.meta.NamespaceDependencies {
+			update.Dependencies = append(update.Dependencies, dep.ModuleName)
+		}
+		for _, dep := range res.meta.LocalDependencies {
+			update.Dependencies = append(update.Dependencies, dep.Name)
+		}
+		updates = append(updates, update)
+	}
+	return updates
+}
+
+// hmrUpdate compiles the update function of a component. The function can't import anything:
+// the namespaces the definitions use are passed in by the initializer, along with the local
+// names of the file the generated code refers to.
+func (c *Compiler) hmrUpdate(ac *analyzedClass) hmrUpdateResult {
+	constantPool := constant.NewConstantPool(false)
+	var definitions []render3.R3HmrDefinition
+	for _, res := range c.compileFullResults(ac, constantPool) {
+		definitions = append(definitions, render3.R3HmrDefinition{Name: res.Name, Initializer: res.Initializer, Statements: res.Statements})
+	}
+	meta := render3.R3HmrMetadata{
+		Type:      c.selfRef(ac).Value,
+		ClassName: ac.class.Name,
+		FilePath:  c.relativeFileName(ac.file.FileName),
+	}
+	for _, name := range ac.file.references {
+		if name != ac.class.Name {
+			meta.LocalDependencies = append(meta.LocalDependencies, render3.R3HmrLocalDependency{
+				Name:                  name,
+				RuntimeRepresentation: output.NewReadVarExpr(name, nil, nil),
+			})
+		}
+	}
+
+	// The namespaces are the modules the emitter would import, in order of first use. Declaring
+	// them first doesn't change that order, as the declarations don't refer to any module.
+	constants := constantPool.GetStatements()
+	imports := output.NewImportManager()
+	imports.CollectStatements([]output.OutputStatement{render3.CompileHmrUpdateCallback(definitions, constants, meta)})
+	for _, imp := range imports.ImportsWithPrefixes() {
+		meta.NamespaceDependencies = append(meta.NamespaceDependencies, render3.R3HmrNamespaceDependency{
+			ModuleName:   imp.ModuleName,
+			AssignedName: imp.Prefix,
+		})
+	}
+	return hmrUpdateResult{fn: render3.CompileHmrUpdateCallback(definitions, constants, meta), meta: meta}
+}
+
+// emitStatement prints a statement with the given emitter.
+func emitStatement(emitter *output.JsEmitterVisitor, stmt output.OutputStatement) string {
+	ctx := output.CreateRootEmitterVisitorContext()
+	emitter.VisitAllStatements([]output.OutputStatement{stmt}, ctx)
+	return ctx.ToSource() + "\n"
+}
@@ -0,0 +1,52 @@
+package annotations
+
+import (
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/partial"
+)
+
+// analyzeInjectable reads the metadata of an `@Injectable`.
+func (c *Compiler) analyzeInjectable(ac *analyzedClass) {
+	f := ac.file
+	arg, ok := c.decoratorArgument(ac, false)
+	if !ok {
+		return
+	}
+	meta := &partial.R3InjectableMetadata{
+		Name:              ac.class.Name,
+		Type:              c.selfRef(ac),
+		TypeArgumentCount: typeArgumentCount(ac.class),
+		ProvidedIn:        render3.MaybeForwardRefExpression{Expression: output.NewLiteralExpr(nil, nil, nil), ForwardRef: render3.ForwardRefHandlingNone},
+	}
+	if expr := arg.Property("providedIn"); expr != nil {
+		if value, ok := expr.StringValue(); ok {
+			meta.ProvidedIn.Expression = output.NewLiteralExpr(value, nil, nil)
+		} else {
+			meta.ProvidedIn.Expression = f.wrap(expr)
+		}
+	}
+	maybeForwardRef := func(name string) *render3.MaybeForwardRefExpression {
+		expr := arg.Property(name)
+		if expr == nil {
+			return nil
+		}
+		return &render3.MaybeForwardRefExpression{Expression: f.wrap(expr), ForwardRef: render3.ForwardRefHandlingNone}
+	}
+	meta.UseClass = maybeForwardRef("useClass")
+	meta.UseExisting = maybeForwardRef("useExisting")
+	meta.UseValue = maybeForwardRef("useValue")
+	if expr := arg.Property("useFactory"); expr != nil {
+		useFactory := f.wrap(expr)
+		meta.UseFactory = &useFactory
+	}
+	// Explicit deps are only used along with `useClass` or `useFactory`.
+	if elements := c.arrayProperty(f, arg, "deps"); elements != nil {
+		deps := make([]render3.R3DependencyMetadata, len(elements))
+		for i, element := range elements {
+			deps[i] = render3.R3DependencyMetadata{Token: f.wrap(element)}
+		}
+		meta.Deps = &deps
+	}
+	ac.injectable = meta
+}
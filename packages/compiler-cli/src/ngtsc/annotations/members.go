@@ -0,0 +1,130 @@
+package annotations
+
+import (
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck/extended"
+)
+
+// signalFunctions are the functions of `@angular/core` creating signals.
+var signalFunctions = map[string]bool{
+	"signal": true, "computed": true, "linkedSignal": true,
+	"input": true, "input.required": true, "model": true, "model.required": true,
+	"viewChild": true, "viewChild.required": true, "viewChildren": true,
+	"contentChild": true, "contentChild.required": true, "contentChildren": true,
+}
+
+// signalTypes are the types of `@angular/core` of signals.
+var signalTypes = map[string]bool{
+	"Signal": true, "WritableSignal": true, "InputSignal": true, "InputSignalWithTransform": true, "ModelSignal": true,
+}
+
+// componentMembers describes the members a component class declares itself, as far as their
+// declarations tell without a type checker: methods, signals and the nullability of the types
+// of properties. Inherited members and the members of their values are unknown.
+func componentMembers(ac *analyzedClass) extended.Members {
+	members := make(extended.Members)
+	for _, member := range ac.class.Members {
+		if member.IsStatic || member.Kind == reflection.MemberKindSetter {
+			continue
+		}
+		switch member.Kind {
+		case reflection.MemberKindMethod:
+			members[member.Name] = extended.Member{Kind: extended.MemberMethod, Nullability: typecheck.NonNullable}
+		case reflection.MemberKindGetter:
+			members[member.Name] = extended.Member{Kind: extended.MemberProperty, Nullability: typeNullability(member.ReturnType)}
+		default:
+			members[member.Name] = propertyMember(ac.file, member)
+		}
+	}
+	return members
+}
+
+// propertyMember describes a property from its type annotation, or else its initializer.
+func propertyMember(f *sourceFile, member *reflection.ClassMember) extended.Member {
+	init := member.Initializer
+	typeName, _, _ := strings.Cut(strings.TrimSpace(member.Type), "<")
+	switch {
+	case signalTypes[f.coreName(typeName)],
+		init != nil && init.Kind == reflection.ExpressionCall && init.Callee != nil && signalFunctions[f.coreName(init.Callee.Value)]:
+		return extended.Member{Kind: extended.MemberSignal, Nullability: typecheck.NonNullable}
+	case isFunction(member.Type), member.Type == "" && init != nil && init.Kind == reflection.ExpressionOther && isFunction(init.Text):
+		return extended.Member{Kind: extended.MemberMethod, Nullability: typecheck.NonNullable}
+	case member.Optional:
+		return extended.Member{Kind: extended.MemberProperty, Nullability: typecheck.Nullable}
+	case member.Type != "":
+		return extended.Member{Kind: extended.MemberProperty, Nullability: typeNullability(member.Type)}
+	case init == nil:
+		return extended.Member{Kind: extended.MemberProperty}
+	}
+	switch init.Kind {
+	case reflection.ExpressionNull:
+		return extended.Member{Kind: extended.MemberProperty, Nullability: typecheck.Nullable}
+	case reflection.ExpressionString, reflection.ExpressionNumber, reflection.ExpressionBoolean,
+		reflection.ExpressionArray, reflection.ExpressionObject:
+		return extended.Member{Kind: extended.MemberProperty, Nullability: typecheck.NonNullable}
+	}
+	return extended.Member{Kind: extended.MemberProperty}
+}
+
+// isFunction reports whether an initializer or a type is a function, e.g. `(a) => a + 1` or
+// `() => void`: it has a `=>` which is not nested in brackets.
+func isFunction(text string) bool {
+	depth := 0
+	for _, tok := range reflection.Tokenize(text) {
+		switch {
+		case tok.Is("(") || tok.Is("[") || tok.Is("{"):
+			depth++
+		case tok.Is(")") || tok.Is("]") || tok.Is("}"):
+			depth--
+		case tok.Is("=>") && depth == 0:
+			return true
+		}
+	}
+	return false
+}
+
+// typeNullability returns whether a type annotation admits `null` or `undefined`: it does when
+// one of the members of the union is `null`, `undefined` or `void`, and may when it is `any` or
+// `unknown`, in which case the nullability is unknown.
+func typeNullability(text string) typecheck.Nullability {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return typecheck.NullabilityUnknown
+	}
+	nullability := typecheck.NonNullable
+	for _, member := range splitUnion(text) {
+		switch strings.TrimSpace(member) {
+		case "null", "undefined", "void":
+			return typecheck.Nullable
+		case "any", "unknown":
+			nullability = typecheck.NullabilityUnknown
+		}
+	}
+	return nullability
+}
+
+// splitUnion splits a type at the `|` which are not nested in brackets.
+func splitUnion(text string) []string {
+	var members []string
+	depth, start := 0, 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '<', '(', '[', '{':
+			depth++
+		case '>', ')', ']', '}':
+			// The `>` of `=>` closes nothing.
+			if !(text[i] == '>' && i > 0 && text[i-1] == '=') {
+				depth--
+			}
+		case '|':
+			if depth == 0 {
+				members = append(members, text[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(members, text[start:])
+}
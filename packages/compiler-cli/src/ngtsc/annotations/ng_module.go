@@ -0,0 +1,128 @@
+package annotations
+
+import (
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	render3_injector_compiler "ngc-go/packages/compiler/src/render3/r3_injector_compiler"
+	render3_module_compiler "ngc-go/packages/compiler/src/render3/r3_module_compiler"
+)
+
+// analyzeNgModule reads the metadata of an `@NgModule`.
+func (c *Compiler) analyzeNgModule(ac *analyzedClass) {
+	f := ac.file
+	arg, ok := c.decoratorArgument(ac, false)
+	if !ok {
+		return
+	}
+	ref := c.selfRef(ac)
+	analysis := &ngModuleAnalysis{
+		meta: render3_module_compiler.R3NgModuleMetadataGlobal{
+			R3NgModuleMetadataCommon: render3_module_compiler.R3NgModuleMetadataCommon{
+				Kind:              render3_module_compiler.R3NgModuleMetadataKindGlobal,
+				Type:              ref,
+				SelectorScopeMode: render3_module_compiler.R3SelectorScopeModeInline,
+			},
+		},
+		injector: render3_injector_compiler.R3InjectorMetadata{Name: ac.class.Name, Type: ref},
+	}
+	meta := &analysis.meta
+
+	for _, expr := range c.arrayProperty(f, arg, "declarations") {
+		declaration := c.resolveElement(f, expr).class
+		if declaration != nil && declaration.kind != kindComponent && declaration.kind != kindDirective && declaration.kind != kindPipe {
+			c.report(f, diagnostics.NgmoduleInvalidDeclaration, expr.Start, expr.End,
+				"The class '"+declaration.class.Name+"' is listed in the declarations of the NgModule '"+ac.class.Name+
+					"', but is not a directive, a component, or a pipe.")
+			continue
+		}
+		if declaration != nil && isStandaloneDeclaration(declaration) {
+			c.report(f, diagnostics.NgmoduleDeclarationIsStandalone, expr.Start, expr.End,
+				declarationKindNames[declaration.kind]+" "+declaration.class.Name+
+					" is standalone, and cannot be declared in an NgModule. Did you mean to import it instead?")
+			continue
+		}
+		if declaration != nil {
+			analysis.declarations = append(analysis.declarations, declaration)
+		}
+		meta.Declarations = append(meta.Declarations, c.reference(f, expr))
+	}
+	for _, expr := range c.arrayProperty(f, arg, "imports") {
+		analysis.imports = append(analysis.imports, c.resolveElement(f, expr))
+		meta.Imports = append(meta.Imports, c.reference(f, expr))
+	}
+	for _, expr := range c.arrayProperty(f, arg, "exports") {
+		analysis.exports = append(analysis.exports, c.resolveElement(f, expr))
+		meta.Exports = append(meta.Exports, c.reference(f, expr))
+	}
+	for _, expr := range c.arrayProperty(f, arg, "bootstrap") {
+		meta.Bootstrap = append(meta.Bootstrap, c.reference(f, expr))
+	}
+	for _, expr := range c.arrayProperty(f, arg, "schemas") {
+		schema := f.wrap(expr)
+		meta.Schemas = append(meta.Schemas, render3.R3Reference{Value: schema, Type: schema})
+	}
+	if expr := arg.Property("id"); expr != nil {
+		meta.ID = f.wrap(expr)
+	}
+	for _, refs := range [][]render3.R3Reference{meta.Declarations, meta.Imports, meta.Exports, meta.Bootstrap} {
+		for _, r := range refs {
+			if c.isForwardDeclaration(ac, r.Value) {
+				meta.ContainsForwardDecls = true
+			}
+		}
+	}
+
+	if expr := arg.Property("providers"); expr != nil {
+		analysis.injector.Providers = f.wrap(expr)
+	}
+	// The injector only needs the imports which may carry providers: NgModules and standalone
+	// components, or any expression it cannot tell apart from them.
+	for _, element := range analysis.imports {
+		if element.class == nil || element.class.kind == kindNgModule || element.class.kind == kindComponent {
+			analysis.injector.Imports = append(analysis.injector.Imports, f.wrap(element.expr))
+		}
+	}
+	ac.ngModule = analysis
+}
+
+// declarationKindNames names the kinds of the declarations of NgModules in diagnostics.
+var declarationKindNames = map[decoratorKind]string{
+	kindComponent: "Component",
+	kindDirective: "Directive",
+	kindPipe:      "Pipe",
+}
+
+// isStandaloneDeclaration reads whether a directive, component or pipe is standalone from its
+// decorator, as it may not be analyzed yet. A `standalone` property which is not a boolean is
+// reported by the analysis of the class.
+func isStandaloneDeclaration(ac *analyzedClass) bool {
+	if len(ac.decorator.Args) != 1 {
+		return true
+	}
+	if standalone, ok := ac.decorator.Args[0].Property("standalone").BoolValue(); ok {
+		return standalone
+	}
+	return true
+}
+
+// resolveElement resolves an element of an array of a decorator to a class of the program.
+func (c *Compiler) resolveElement(f *sourceFile, expr *reflection.Expression) moduleElement {
+	element := moduleElement{file: f, expr: expr}
+	if expr.Kind == reflection.ExpressionIdentifier {
+		element.class = c.resolveClass(f, expr.Value)
+	}
+	return element
+}
+
+// isForwardDeclaration reports whether a reference points at a class declared later in the file
+// of ac.
+func (c *Compiler) isForwardDeclaration(ac *analyzedClass, ref output.OutputExpression) bool {
+	readVar, ok := ref.(*output.ReadVarExpr)
+	if !ok {
+		return false
+	}
+	class := ac.file.Class(readVar.Name)
+	return class != nil && class.Start > ac.class.Start
+}
@@ -0,0 +1,34 @@
+package annotations
+
+import (
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+// analyzePipe reads the metadata of a `@Pipe`.
+func (c *Compiler) analyzePipe(ac *analyzedClass) {
+	f := ac.file
+	arg, ok := c.decoratorArgument(ac, true)
+	if !ok {
+		return
+	}
+	name, ok := c.stringProperty(f, arg, "name")
+	if !ok {
+		if arg.Property("name") == nil {
+			c.report(f, diagnostics.PipeMissingName, ac.decorator.Start, ac.decorator.End, "@Pipe decorator is missing name field")
+		}
+		return
+	}
+	meta := &render3.R3PipeMetadata{
+		Name:              ac.class.Name,
+		Type:              c.selfRef(ac),
+		TypeArgumentCount: typeArgumentCount(ac.class),
+		PipeName:          &name,
+		Pure:              c.boolProperty(f, arg, "pure", true),
+		IsStandalone:      c.boolProperty(f, arg, "standalone", true),
+	}
+	if deps, ok := ac.deps.([]render3.R3DependencyMetadata); ok {
+		meta.Deps = deps
+	}
+	ac.pipe = meta
+}
@@ -0,0 +1,588 @@
+package annotations
+
+import (
+	"sort"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck/extended"
+	"ngc-go/packages/compiler/src/css"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/view"
+	view_compiler "ngc-go/packages/compiler/src/render3/view/compiler"
+	"ngc-go/packages/compiler/src/schema"
+)
+
+// bindingNames maps the binding property names of inputs or outputs to their class properties.
+type bindingNames map[string]string
+
+func (n bindingNames) HasBindingPropertyName(propertyName string) bool {
+	_, ok := n[propertyName]
+	return ok
+}
+
+// directiveMeta is a directive or component as seen by the template binder.
+type directiveMeta struct {
+	name                string
+	selector            *string
+	isComponent         bool
+	inputs              bindingNames
+	outputs             bindingNames
+	exportAs            []string
+	isStructural        bool
+	ngContentSelectors  []string
+	preserveWhitespaces bool
+}
+
+func newDirectiveMeta(ac *analyzedClass, meta *view.R3DirectiveMetadata) *directiveMeta {
+	dir := &directiveMeta{
+		name:     ac.class.Name,
+		selector: meta.Selector,
+		inputs:   make(bindingNames),
+		outputs:  make(bindingNames),
+		exportAs: meta.ExportAs,
+	}
+	for _, input := range meta.Inputs {
+		dir.inputs[input.BindingPropertyName] = input.ClassPropertyName
+	}
+	for property, alias := range meta.Outputs {
+		dir.outputs[alias] = property
+	}
+	// Structural directives inject the template they are applied to.
+	if ctor := ac.class.Constructor; ctor != nil {
+		for _, param := range ctor.Parameters {
+			if ac.file.coreName(param.TypeName()) == "TemplateRef" {
+				dir.isStructural = true
+			}
+		}
+	}
+	return dir
+}
+
+func (d *directiveMeta) Name() string                                             { return d.name }
+func (d *directiveMeta) Selector() *string                                        { return d.selector }
+func (d *directiveMeta) IsComponent() bool                                        { return d.isComponent }
+func (d *directiveMeta) Inputs() view.InputOutputPropertySet                      { return d.inputs }
+func (d *directiveMeta) Outputs() view.InputOutputPropertySet                     { return d.outputs }
+func (d *directiveMeta) ExportAs() []string                                       { return d.exportAs }
+func (d *directiveMeta) IsStructural() bool                                       { return d.isStructural }
+func (d *directiveMeta) NgContentSelectors() []string                             { return d.ngContentSelectors }
+func (d *directiveMeta) PreserveWhitespaces() bool                                { return d.preserveWhitespaces }
+func (d *directiveMeta) AnimationTriggerNames() *view.LegacyAnimationTriggerNames { return nil }
+func (d *directiveMeta) InputFieldName(bindingName string) string                 { return d.inputs[bindingName] }
+func (d *directiveMeta) OutputFieldName(bindingName string) string                { return d.outputs[bindingName] }
+
+// templateScope is the set of directives, pipes and NgModules a component template can use.
+type templateScope struct {
+	directives []*analyzedClass
+	pipes      []*analyzedClass
+	// modules are NgModules outside of the program and the elements of the imports which are not
+	// statically understood, e.g. `SHARED[0]`. They are kept as dependencies so that the runtime
+	// can resolve them.
+	modules []output.OutputExpression
+	// incomplete is set when the scope may hold directives the compiler does not know, brought by
+	// the elements of the imports which are not classes of the program.
+	incomplete bool
+	// programValues is set when modules refers to values of other files of the program, which may
+	// not be evaluated yet when the component is defined since the files can import each other.
+	programValues bool
+	// external are the directives, components and pipes of packages, read from their declaration
+	// files.
+	external []*externalDependency
+	seen     map[*analyzedClass]bool
+}
+
+// externalDependency is a class of a package in a template scope. ref is nil for the classes
+// exported by an NgModule of a package, which the NgModule in modules brings at runtime.
+type externalDependency struct {
+	class *externalClass
+	ref   output.OutputExpression
+}
+
+// addExternal adds a class of a package to a scope, or its reference when it is already there
+// through an NgModule.
+func (s *templateScope) addExternal(ext *externalClass, ref output.OutputExpression) {
+	for _, dep := range s.external {
+		if dep.class == ext {
+			if dep.ref == nil {
+				dep.ref = ref
+			}
+			return
+		}
+	}
+	if ext.kind == kindPipe || ext.matchable != nil {
+		s.external = append(s.external, &externalDependency{class: ext, ref: ref})
+	}
+}
+
+func (s *templateScope) add(ac *analyzedClass) {
+	if ac == nil || s.seen[ac] {
+		return
+	}
+	s.seen[ac] = true
+	switch {
+	case ac.matchable != nil && ac.matchable.selector != nil:
+		s.directives = append(s.directives, ac)
+	case ac.kind == kindPipe && ac.pipe != nil:
+		s.pipes = append(s.pipes, ac)
+	}
+}
+
+// resolveComponentScope computes the template scope of a component, binds its template against
+// it and records the directives and pipes it uses as dependencies.
+func (c *Compiler) resolveComponentScope(ac *analyzedClass) {
+	comp := ac.component
+	f := ac.file
+	scope := c.componentScope(ac, comp.meta.IsStandalone, comp.imports)
+	matcher := scope.matcher()
+	bound := view.NewR3TargetBinder(matcher).Bind(&view.Target{Template: comp.template.Nodes})
+	if c.options.CheckTemplates && !scope.incomplete {
+		c.checkTemplate(ac, bound)
+	}
+	if c.extendedChecker != nil && !scope.incomplete {
+		c.diags = append(c.diags, c.extendedChecker.GetDiagnosticsForTemplate(extended.TemplateInfo{
+			Bound:            bound,
+			HostIsStandalone: comp.meta.IsStandalone,
+			Types:            componentMembers(ac),
+		})...)
+	}
+	if c.options.TypeCheckBlocks && !scope.incomplete {
+		c.addTypeCheckBlock(ac, scope, bound)
+	}
+
+	usedDirectives := usedDirectiveSet(bound.GetUsedDirectives())
+	eagerDirectives := usedDirectiveSet(bound.GetEagerlyUsedDirectives())
+	usedPipes := usedPipeSet(bound.GetUsedPipes())
+	eagerPipes := usedPipeSet(bound.GetEagerlyUsedPipes())
+
+	// Dependencies only used within `@defer` blocks are left out of the dependencies of the
+	// component and provided by the resolver functions of the blocks using them instead.
+	var deferred []*analyzedClass
+	meta := &comp.meta
+	meta.Declarations = nil
+	for _, dir := range scope.directives {
+		if !usedDirectives[dir.matchable] {
+			continue
+		}
+		c.checkForwardReference(ac, dir)
+		meta.HasDirectiveDependencies = true
+		if !eagerDirectives[dir.matchable] {
+			deferred = append(deferred, dir)
+			continue
+		}
+		dirMeta := dir.directive
+		dep := view.R3DirectiveDependencyMetadata{
+			R3TemplateDependency: view.R3TemplateDependency{Kind: view.R3TemplateDependencyKindDirective, Type: c.typeRef(f, dir)},
+			Selector:             *dirMeta.Selector,
+			ExportAs:             dirMeta.ExportAs,
+			IsComponent:          dir.matchable.isComponent,
+		}
+		for _, name := range sortedInputNames(dirMeta) {
+			dep.Inputs = append(dep.Inputs, dirMeta.Inputs[name].BindingPropertyName)
+		}
+		for _, name := range sortedOutputNames(dirMeta) {
+			dep.Outputs = append(dep.Outputs, dirMeta.Outputs[name])
+		}
+		meta.Declarations = append(meta.Declarations, dep)
+	}
+	for _, pipe := range scope.pipes {
+		if pipe.pipe.PipeName == nil || !usedPipes[*pipe.pipe.PipeName] {
+			continue
+		}
+		c.checkForwardReference(ac, pipe)
+		if !eagerPipes[*pipe.pipe.PipeName] {
+			deferred = append(deferred, pipe)
+			continue
+		}
+		meta.Declarations = append(meta.Declarations, view.R3PipeDependencyMetadata{
+			R3TemplateDependency: view.R3TemplateDependency{Kind: view.R3TemplateDependencyKindPipe, Type: c.typeRef(f, pipe)},
+			Name:                 *pipe.pipe.PipeName,
+		})
+	}
+	// The classes of packages are referenced eagerly, their packages are loaded anyway.
+	for _, dep := range scope.external {
+		ext := dep.class
+		if ext.kind == kindPipe {
+			if dep.ref != nil && usedPipes[ext.pipeName] {
+				meta.Declarations = append(meta.Declarations, view.R3PipeDependencyMetadata{
+					R3TemplateDependency: view.R3TemplateDependency{Kind: view.R3TemplateDependencyKindPipe, Type: dep.ref},
+					Name:                 ext.pipeName,
+				})
+			}
+			continue
+		}
+		if !usedDirectives[ext.matchable] {
+			continue
+		}
+		meta.HasDirectiveDependencies = true
+		if dep.ref == nil {
+			continue
+		}
+		dir := view.R3DirectiveDependencyMetadata{
+			R3TemplateDependency: view.R3TemplateDependency{Kind: view.R3TemplateDependencyKindDirective, Type: dep.ref},
+			Selector:             *ext.matchable.selector,
+			ExportAs:             ext.matchable.exportAs,
+			IsComponent:          ext.matchable.isComponent,
+		}
+		for _, input := range ext.inputs {
+			dir.Inputs = append(dir.Inputs, input.Name)
+		}
+		for _, output := range ext.outputs {
+			dir.Outputs = append(dir.Outputs, output.Name)
+		}
+		meta.Declarations = append(meta.Declarations, dir)
+	}
+	meta.Defer.Blocks = make(map[*render3.DeferredBlock]*output.OutputExpression)
+	for _, block := range bound.GetDeferBlocks() {
+		meta.Defer.Blocks[block] = c.deferResolverFn(f, matcher, block, deferred)
+	}
+	// NgModules whose exports were read from their declaration files only bring the directives
+	// matched above.
+	for _, module := range scope.modules {
+		meta.Declarations = append(meta.Declarations, view.R3NgModuleDependencyMetadata{
+			R3TemplateDependency: view.R3TemplateDependency{Kind: view.R3TemplateDependencyKindNgModule, Type: module},
+		})
+		meta.HasDirectiveDependencies = meta.HasDirectiveDependencies || scope.incomplete
+	}
+	if scope.programValues {
+		meta.DeclarationListEmitMode = view.DeclarationListEmitModeClosure
+	}
+	meta.DomOnly = c.options.DomOnly && !meta.IsStandalone && !scope.incomplete && c.declaringModule(ac) != nil
+}
+
+// checkTemplate reports the elements, properties and events of the bound template of a component
+// which neither the DOM schema nor the directives in its scope know about and, with
+// Options.StrictNullChecks, the null-safe operators applied to values which are never null.
+func (c *Compiler) checkTemplate(ac *analyzedClass, bound view.BoundTarget) {
+	if c.registry == nil {
+		c.registry = schema.NewDomElementSchemaRegistry()
+	}
+	options := &typecheck.TemplateCheckOptions{
+		Schemas:          c.componentSchemas(ac),
+		HostIsStandalone: ac.component.meta.IsStandalone,
+		Registry:         c.registry,
+		StrictNullChecks: c.options.StrictNullChecks,
+	}
+	if options.StrictNullChecks {
+		options.Nullability = componentMembers(ac)
+	}
+	c.diags = append(c.diags, typecheck.CheckTemplate(bound, options)...)
+}
+
+// DomOnlyComponents returns the names of the components whose templates are compiled with the
+// DOM-only instruction set, in the order of their files. It must be called after Analyze.
+func (c *Compiler) DomOnlyComponents() []string {
+	var names []string
+	for _, ac := range c.classes {
+		if ac.component != nil && view_compiler.UsesDomOnlyMode(&ac.component.meta) {
+			names = append(names, ac.class.Name)
+		}
+	}
+	return names
+}
+
+// deferResolverFn compiles the function resolving the deferred dependencies used within a
+// `@defer` block, or returns nil when the block uses none of them.
+func (c *Compiler) deferResolverFn(f *sourceFile, matcher view.DirectiveMatcher, block *render3.DeferredBlock, deferred []*analyzedClass) *output.OutputExpression {
+	bound := view.NewR3TargetBinder(matcher).Bind(&view.Target{Template: block.Children})
+	usedDirectives := usedDirectiveSet(bound.GetUsedDirectives())
+	usedPipes := usedPipeSet(bound.GetUsedPipes())
+	var deps []view.R3DeferPerBlockDependency
+	for _, dep := range deferred {
+		used := dep.matchable != nil && usedDirectives[dep.matchable]
+		if dep.kind == kindPipe {
+			used = usedPipes[*dep.pipe.PipeName]
+		}
+		if !used {
+			continue
+		}
+		// Dependencies are referenced eagerly until imports that can be dropped are detected.
+		deps = append(deps, view.R3DeferPerBlockDependency{TypeReference: c.typeRef(f, dep), SymbolName: dep.class.Name})
+	}
+	if len(deps) == 0 {
+		return nil
+	}
+	fn := output.OutputExpression(view_compiler.CompileDeferResolverFunction(&view.R3DeferResolverFunctionMetadata{
+		Mode:                 view.DeferBlockDepsEmitModePerBlock,
+		PerBlockDependencies: deps,
+	}))
+	return &fn
+}
+
+func usedDirectiveSet(dirs []interface{}) map[view.DirectiveMeta]bool {
+	set := make(map[view.DirectiveMeta]bool)
+	for _, dir := range dirs {
+		if meta, ok := dir.(view.DirectiveMeta); ok {
+			set[meta] = true
+		}
+	}
+	return set
+}
+
+func usedPipeSet(names []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// componentScope computes the template scope of a component from the `imports` of a standalone
+// component or the NgModule declaring it.
+func (c *Compiler) componentScope(ac *analyzedClass, standalone bool, imports []moduleElement) *templateScope {
+	scope := &templateScope{seen: make(map[*analyzedClass]bool)}
+	if standalone {
+		// Standalone components can use themselves recursively.
+		scope.add(ac)
+		for _, element := range imports {
+			c.addToScope(ac.file, scope, element)
+		}
+	} else if module := c.declaringModule(ac); module != nil {
+		for _, declaration := range module.ngModule.declarations {
+			scope.add(declaration)
+		}
+		for _, element := range module.ngModule.imports {
+			c.addToScope(ac.file, scope, element)
+		}
+		// The exports do not change the scope, but one the compiler does not understand hints at
+		// a declaration it missed just as well.
+		for _, element := range module.ngModule.exports {
+			if element.class == nil {
+				scope.incomplete = true
+			}
+		}
+	}
+	return scope
+}
+
+// matcher returns the selector matcher of the directives of a scope, as used by the binder.
+func (s *templateScope) matcher() *css.SelectorMatcher[view.DirectiveMeta] {
+	matcher := css.NewSelectorMatcher[view.DirectiveMeta]()
+	for _, dir := range s.directives {
+		selectors, err := css.ParseCssSelector(*dir.matchable.selector)
+		if err != nil {
+			continue
+		}
+		var meta view.DirectiveMeta = dir.matchable
+		matcher.AddSelectables(selectors, &meta)
+	}
+	for _, dep := range s.external {
+		if dep.class.matchable == nil {
+			continue
+		}
+		selectors, err := css.ParseCssSelector(*dep.class.matchable.selector)
+		if err != nil {
+			continue
+		}
+		var meta view.DirectiveMeta = dep.class.matchable
+		matcher.AddSelectables(selectors, &meta)
+	}
+	return matcher
+}
+
+// addToScope adds an element of the `imports` of a standalone component or NgModule to a
+// template scope. f is the file of the component whose template the scope is for.
+func (c *Compiler) addToScope(f *sourceFile, scope *templateScope, element moduleElement) {
+	switch {
+	case element.class == nil:
+		c.addModuleDependency(f, scope, element, make(map[*analyzedClass]bool))
+	case element.class.kind == kindNgModule:
+		c.addExportScope(f, scope, element.class, make(map[*analyzedClass]bool))
+	default:
+		scope.add(element.class)
+	}
+}
+
+// addExportScope adds the directives and pipes exported by an NgModule of the program, including
+// the ones of the NgModules it re-exports.
+func (c *Compiler) addExportScope(f *sourceFile, scope *templateScope, module *analyzedClass, visited map[*analyzedClass]bool) {
+	if visited[module] || module.ngModule == nil {
+		return
+	}
+	visited[module] = true
+	for _, element := range module.ngModule.exports {
+		switch {
+		case element.class == nil:
+			c.addModuleDependency(f, scope, element, visited)
+		case element.class.kind == kindNgModule:
+			c.addExportScope(f, scope, element.class, visited)
+		default:
+			scope.add(element.class)
+		}
+	}
+}
+
+// addModuleDependency adds an element of the `imports` or `exports` which is not a class of the
+// program to a template scope, as a dependency of the component whose template the scope is for.
+// `RouterModule.forChild(routes)` and the like stand for the NgModule they are called on, whose
+// ModuleWithProviders they return, and spreads, e.g. `...SHARED`, for their array: the runtime
+// flattens the arrays of dependencies when it collects their providers. The directives and pipes
+// of packages are read from their declaration files when they can be.
+func (c *Compiler) addModuleDependency(f *sourceFile, scope *templateScope, element moduleElement, visited map[*analyzedClass]bool) {
+	if expr := element.expr; expr.Kind == reflection.ExpressionOther && strings.HasPrefix(expr.Text, "...") {
+		operand := strings.TrimSpace(expr.Text[3:])
+		element.expr = reflection.ParseExpression(operand)
+		element.expr.Start, element.expr.End = expr.End-len(operand), expr.End
+	}
+	if callee := element.expr.Callee; element.expr.Kind == reflection.ExpressionCall && callee.Kind == reflection.ExpressionIdentifier &&
+		strings.Contains(callee.Value, ".") {
+		name := rootName(callee.Value)
+		module := moduleElement{file: element.file, expr: &reflection.Expression{
+			Kind: reflection.ExpressionIdentifier, Text: name, Value: name, Start: callee.Start, End: callee.Start + len(name),
+		}}
+		switch class := c.resolveClass(element.file, name); {
+		case class == nil:
+			element = module
+		case class.kind == kindNgModule:
+			c.addExportScope(f, scope, class, visited)
+			return
+		}
+	}
+	ref := c.externalModuleRef(f, element)
+	switch ext := c.elementPackageClass(element); {
+	case ext == nil:
+		scope.incomplete = true
+	case ext.kind == kindNgModule:
+		for _, exported := range ext.exports {
+			scope.addExternal(exported, nil)
+		}
+		scope.incomplete = scope.incomplete || !ext.complete
+	case ref != nil:
+		scope.addExternal(ext, ref)
+		return
+	default:
+		scope.incomplete = true
+	}
+	if ref == nil {
+		ref = c.opaqueModuleRef(f, element)
+	}
+	if ref == nil || containsExpr(scope.modules, ref) {
+		return
+	}
+	if ext, ok := ref.(*output.ExternalExpr); ok && strings.HasPrefix(*ext.Value.ModuleName, ".") {
+		scope.programValues = true
+	}
+	scope.modules = append(scope.modules, ref)
+}
+
+// externalModuleRef refers to an NgModule outside of the program, e.g. `CommonModule`, or to a
+// value exported by a file of the program, e.g. an array of NgModules, from the generated code of
+// f. It returns nil when the element is not such an identifier.
+func (c *Compiler) externalModuleRef(f *sourceFile, element moduleElement) output.OutputExpression {
+	expr := element.expr
+	if expr.Kind != reflection.ExpressionIdentifier {
+		return nil
+	}
+	source := element.file
+	if source == f {
+		if f.isValue(rootName(expr.Value)) {
+			return f.readVar(expr.Value)
+		}
+		return nil
+	}
+	if strings.Contains(expr.Value, ".") {
+		return nil
+	}
+	imp, imported, ok := source.ImportOf(expr.Value)
+	if !ok {
+		if !source.IsExported(expr.Value) {
+			return nil
+		}
+		module := relativeModule(f.FileName, source.FileName)
+		return output.NewExternalExpr(&output.ExternalReference{ModuleName: &module, Name: &expr.Value}, nil, nil, nil)
+	}
+	if imp.TypeOnly || imported == "*" || imported == "default" {
+		return nil
+	}
+	module := imp.Module
+	if module[0] == '.' {
+		target := c.resolveModule(source, module)
+		if target == nil {
+			return nil
+		}
+		module = relativeModule(f.FileName, target.FileName)
+	}
+	return output.NewExternalExpr(&output.ExternalReference{ModuleName: &module, Name: &imported}, nil, nil, nil)
+}
+
+// elementPackageClass returns the class of a package an element of the `imports` or `exports`
+// names, nil when it is not one or when its declaration files cannot be read.
+func (c *Compiler) elementPackageClass(element moduleElement) *externalClass {
+	expr := element.expr
+	if expr.Kind != reflection.ExpressionIdentifier || strings.Contains(expr.Value, ".") {
+		return nil
+	}
+	return c.packageClass(element.file, expr.Value)
+}
+
+// opaqueModuleRef copies an element the compiler does not understand, e.g. `SHARED[0]`, into the
+// dependencies of a component declared in the same file. Other files cannot use the names of the
+// element, so it is reported and nil is returned for them.
+func (c *Compiler) opaqueModuleRef(f *sourceFile, element moduleElement) output.OutputExpression {
+	if element.file == f {
+		return f.wrap(element.expr)
+	}
+	if !c.reportedElements[element.expr] {
+		c.reportedElements[element.expr] = true
+		expr := element.expr
+		c.diags = append(c.diags, diagnostics.MakeDiagnostic(
+			diagnostics.NgmoduleInvalidImport, diagnostics.CategoryWarning, element.file.span(expr.Start, expr.End),
+			"'"+expr.Text+"' is not statically analyzable, so the components declared in other files do not depend on it. "+
+				"List the NgModules it holds instead."))
+	}
+	return nil
+}
+
+// containsExpr reports whether an external reference is already in a list.
+func containsExpr(exprs []output.OutputExpression, expr output.OutputExpression) bool {
+	for _, existing := range exprs {
+		if existing.IsEquivalent(expr) {
+			return true
+		}
+	}
+	return false
+}
+
+// declaringModule returns the NgModule of the program which declares a class.
+func (c *Compiler) declaringModule(ac *analyzedClass) *analyzedClass {
+	for _, module := range c.classes {
+		if module.ngModule == nil {
+			continue
+		}
+		for _, declaration := range module.ngModule.declarations {
+			if declaration == ac {
+				return module
+			}
+		}
+	}
+	return nil
+}
+
+// checkForwardReference switches a component to emitting its dependencies in a closure when one
+// of them is declared later in the same file.
+func (c *Compiler) checkForwardReference(ac *analyzedClass, dep *analyzedClass) {
+	if dep.file == ac.file && dep.class.Start > ac.class.Start {
+		ac.component.meta.DeclarationListEmitMode = view.DeclarationListEmitModeClosure
+	}
+}
+
+func sortedInputNames(meta *view.R3DirectiveMetadata) []string {
+	names := make([]string, 0, len(meta.Inputs))
+	for name := range meta.Inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedOutputNames(meta *view.R3DirectiveMetadata) []string {
+	names := make([]string, 0, len(meta.Outputs))
+	for name := range meta.Outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,64 @@
+package annotations
+
+import (
+	"sync"
+
+	"ngc-go/packages/compiler/src/render3/view"
+)
+
+// TemplateCache keeps the parsed templates of components across compilations, for long-lived
+// processes compiling the same project again and again. It keeps the last template parsed at
+// each location, an external template file or the position of an inline template in its
+// component file, and reuses it while its text and the options it was parsed with are
+// unchanged. It is safe for concurrent use.
+type TemplateCache struct {
+	mu        sync.Mutex
+	templates map[templateLocation]*cachedTemplate
+}
+
+// templateLocation is where a template is read from. The offset of external templates is 0.
+type templateLocation struct {
+	url    string
+	offset int
+}
+
+type cachedTemplate struct {
+	content             string
+	line, col           int
+	preserveWhitespaces bool
+	template            *view.ParsedTemplate
+}
+
+// NewTemplateCache creates an empty TemplateCache.
+func NewTemplateCache() *TemplateCache {
+	return &TemplateCache{templates: make(map[templateLocation]*cachedTemplate)}
+}
+
+// parse parses a template like view.ParseTemplate, returning the cached template when there is
+// one. A nil cache parses every template.
+func (tc *TemplateCache) parse(text string, url string, options *view.ParseTemplateOptions) *view.ParsedTemplate {
+	if tc == nil {
+		return view.ParseTemplate(text, url, options)
+	}
+	entry := &cachedTemplate{content: text, preserveWhitespaces: *options.PreserveWhitespaces}
+	location := templateLocation{url: url}
+	if r := options.Range; r != nil {
+		location.offset = r.StartPos
+		entry.content = text[r.StartPos:r.EndPos]
+		entry.line, entry.col = r.StartLine, r.StartCol
+	}
+
+	tc.mu.Lock()
+	cached := tc.templates[location]
+	tc.mu.Unlock()
+	if cached != nil && cached.content == entry.content && cached.line == entry.line &&
+		cached.col == entry.col && cached.preserveWhitespaces == entry.preserveWhitespaces {
+		return cached.template
+	}
+
+	entry.template = view.ParseTemplate(text, url, options)
+	tc.mu.Lock()
+	tc.templates[location] = entry
+	tc.mu.Unlock()
+	return entry.template
+}
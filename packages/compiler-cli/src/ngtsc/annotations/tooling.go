@@ -0,0 +1,270 @@
+package annotations
+
+import (
+	"path"
+	"sort"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/core"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/view"
+)
+
+// componentTemplate is where the template of a component is and what it can use.
+type componentTemplate struct {
+	standalone bool
+	imports    []moduleElement
+	// content and url are the text the template is parsed from, the component file for inline
+	// templates, and its name. options is nil until the template is found.
+	content string
+	url     string
+	options *view.ParseTemplateOptions
+}
+
+// locate records the text and options a template is parsed with.
+func (t *componentTemplate) locate(content string, url string, options *view.ParseTemplateOptions) {
+	copied := *options
+	t.content, t.url, t.options = content, url, &copied
+}
+
+// ComponentTemplate is the template of a component, for the tools working on templates, such as
+// the language service. Unlike the compiler, they get a template even when it has errors.
+type ComponentTemplate struct {
+	// ClassName and FileName are the component class and its file.
+	ClassName string
+	FileName  string
+	// TemplateURL is the file of the template, FileName for inline templates.
+	TemplateURL string
+	Inline      bool
+	// Start and End are the offsets of the template in TemplateURL.
+	Start, End int
+	// Standalone is set for the templates of standalone components.
+	Standalone bool
+	// Directives are the directives and components the template can use, sorted by name.
+	Directives []*ScopeDirective
+	// Complete is set when Directives are all the directives the template can use, which are
+	// unknown when it imports NgModules whose declaration files cannot be read, or elements which
+	// are not statically understood.
+	Complete bool
+	// Schemas are the schemas of the component or of the NgModule declaring it.
+	Schemas []*core.SchemaMetadata
+
+	content string
+	options view.ParseTemplateOptions
+	scope   *templateScope
+	byMeta  map[view.DirectiveMeta]*ScopeDirective
+}
+
+// ScopeDirective is a directive or component of a template scope.
+type ScopeDirective struct {
+	Name     string
+	FileName string
+	// Start and End are the offsets of the class declaration in FileName.
+	Start, End  int
+	Selector    string
+	IsComponent bool
+	ExportAs    []string
+	// Inputs and Outputs are sorted by binding name.
+	Inputs  []*ScopeBinding
+	Outputs []*ScopeBinding
+}
+
+// ScopeBinding is an input or output of a directive.
+type ScopeBinding struct {
+	// Name is the name templates bind to, Property the class property.
+	Name     string
+	Property string
+	Required bool
+	// Start and End are the offsets of the property declaration in the file of the directive,
+	// those of the class when it is not declared by the class itself.
+	Start, End int
+}
+
+// Templates returns the templates of the components which are in a file: the inline templates
+// of the components it declares, or the components using it as external template. It must be
+// called after Analyze.
+func (c *Compiler) Templates(fileName string) []*ComponentTemplate {
+	fileName = path.Clean(fileName)
+	var templates []*ComponentTemplate
+	for _, ac := range c.classes {
+		t := ac.template
+		if t == nil || t.options == nil || (path.Clean(t.url) != fileName && path.Clean(ac.file.FileName) != fileName) {
+			continue
+		}
+		templates = append(templates, c.componentTemplate(ac))
+	}
+	return templates
+}
+
+// UnownedTemplate returns a template file which no component uses, for the tools working on
+// templates: it is parsed with the default options of the compiler and bound against an empty
+// scope, which is not Complete. ClassName and FileName are empty.
+func UnownedTemplate(fileName string, content string) *ComponentTemplate {
+	template := &ComponentTemplate{
+		TemplateURL: fileName,
+		End:         len(content),
+		content:     content,
+		scope:       &templateScope{},
+		byMeta:      make(map[view.DirectiveMeta]*ScopeDirective),
+	}
+	preserveWhitespaces, alwaysAttempt, collectComments := false, true, true
+	template.options.PreserveWhitespaces = &preserveWhitespaces
+	template.options.AlwaysAttemptHtmlToR3AstConversion = &alwaysAttempt
+	template.options.CollectCommentNodes = &collectComments
+	return template
+}
+
+func (c *Compiler) componentTemplate(ac *analyzedClass) *ComponentTemplate {
+	t := ac.template
+	template := &ComponentTemplate{
+		ClassName:   ac.class.Name,
+		FileName:    ac.file.FileName,
+		TemplateURL: t.url,
+		End:         len(t.content),
+		Standalone:  t.standalone,
+		Schemas:     c.componentSchemas(ac),
+		content:     t.content,
+		options:     *t.options,
+		scope:       c.componentScope(ac, t.standalone, t.imports),
+		byMeta:      make(map[view.DirectiveMeta]*ScopeDirective),
+	}
+	template.Complete = !template.scope.incomplete
+	if r := t.options.Range; r != nil {
+		template.Inline = true
+		template.Start, template.End = r.StartPos, r.EndPos
+	}
+	alwaysAttempt, collectComments := true, true
+	template.options.AlwaysAttemptHtmlToR3AstConversion = &alwaysAttempt
+	template.options.CollectCommentNodes = &collectComments
+	for _, dir := range template.scope.directives {
+		scopeDir := newScopeDirective(dir)
+		template.Directives = append(template.Directives, scopeDir)
+		template.byMeta[dir.matchable] = scopeDir
+	}
+	for _, dep := range template.scope.external {
+		if dep.class.matchable == nil {
+			continue
+		}
+		scopeDir := newExternalScopeDirective(dep.class)
+		template.Directives = append(template.Directives, scopeDir)
+		template.byMeta[dep.class.matchable] = scopeDir
+	}
+	sort.SliceStable(template.Directives, func(i, j int) bool {
+		return template.Directives[i].Name < template.Directives[j].Name
+	})
+	return template
+}
+
+// componentSchemas reads the schemas of a standalone component, or of the NgModule declaring it.
+func (c *Compiler) componentSchemas(ac *analyzedClass) []*core.SchemaMetadata {
+	owner := ac
+	if !ac.template.standalone {
+		owner = c.declaringModule(ac)
+	}
+	if owner == nil || len(owner.decorator.Args) == 0 {
+		return nil
+	}
+	schemas := owner.decorator.Args[0].Property("schemas")
+	if schemas == nil || schemas.Kind != reflection.ExpressionArray {
+		return nil
+	}
+	var metas []*core.SchemaMetadata
+	for _, expr := range schemas.Elements {
+		switch owner.file.coreName(expr.Value) {
+		case "CUSTOM_ELEMENTS_SCHEMA":
+			metas = append(metas, &core.CUSTOM_ELEMENTS_SCHEMA)
+		case "NO_ERRORS_SCHEMA":
+			metas = append(metas, &core.NO_ERRORS_SCHEMA)
+		}
+	}
+	return metas
+}
+
+func newScopeDirective(ac *analyzedClass) *ScopeDirective {
+	meta := ac.directive
+	dir := &ScopeDirective{
+		Name:        ac.class.Name,
+		FileName:    ac.file.FileName,
+		Start:       ac.class.KeywordStart,
+		End:         ac.class.End,
+		Selector:    *ac.matchable.selector,
+		IsComponent: ac.matchable.isComponent,
+		ExportAs:    meta.ExportAs,
+	}
+	for _, name := range sortedInputNames(meta) {
+		input := meta.Inputs[name]
+		binding := &ScopeBinding{Name: input.BindingPropertyName, Property: input.ClassPropertyName, Required: input.Required}
+		binding.Start, binding.End = memberSpan(ac.class, input.ClassPropertyName)
+		dir.Inputs = append(dir.Inputs, binding)
+	}
+	for _, name := range sortedOutputNames(meta) {
+		binding := &ScopeBinding{Name: meta.Outputs[name], Property: name}
+		binding.Start, binding.End = memberSpan(ac.class, name)
+		dir.Outputs = append(dir.Outputs, binding)
+	}
+	sort.SliceStable(dir.Inputs, func(i, j int) bool { return dir.Inputs[i].Name < dir.Inputs[j].Name })
+	sort.SliceStable(dir.Outputs, func(i, j int) bool { return dir.Outputs[i].Name < dir.Outputs[j].Name })
+	return dir
+}
+
+// newExternalScopeDirective describes a directive of a package, located in its declaration file.
+func newExternalScopeDirective(ext *externalClass) *ScopeDirective {
+	dir := &ScopeDirective{
+		Name:        ext.name,
+		FileName:    ext.fileName,
+		Start:       ext.start,
+		End:         ext.end,
+		Selector:    *ext.matchable.selector,
+		IsComponent: ext.matchable.isComponent,
+		ExportAs:    ext.matchable.exportAs,
+	}
+	// The declaration files declare the inputs and outputs in the types of the definitions, so
+	// they point at the class.
+	for _, input := range ext.inputs {
+		binding := *input
+		binding.Start, binding.End = ext.start, ext.end
+		dir.Inputs = append(dir.Inputs, &binding)
+	}
+	for _, output := range ext.outputs {
+		binding := *output
+		binding.Start, binding.End = ext.start, ext.end
+		dir.Outputs = append(dir.Outputs, &binding)
+	}
+	sort.SliceStable(dir.Inputs, func(i, j int) bool { return dir.Inputs[i].Name < dir.Inputs[j].Name })
+	sort.SliceStable(dir.Outputs, func(i, j int) bool { return dir.Outputs[i].Name < dir.Outputs[j].Name })
+	return dir
+}
+
+// memberSpan returns the span of a member of a class, or of the class when it has no such
+// member, e.g. an input inherited from a base class.
+func memberSpan(class *reflection.ClassDeclaration, name string) (int, int) {
+	for _, member := range class.Members {
+		if member.Name == name {
+			return member.Start, member.End
+		}
+	}
+	return class.KeywordStart, class.End
+}
+
+// Parse parses the template like the compiler does, except that the R3 AST is built despite
+// HTML errors and that comments are collected. The spans of inline templates point into their
+// component file.
+func (t *ComponentTemplate) Parse() *view.ParsedTemplate {
+	options := t.options
+	return view.ParseTemplate(t.content, t.TemplateURL, &options)
+}
+
+// Bind binds the nodes of the template against the directives of its scope.
+func (t *ComponentTemplate) Bind(nodes []render3.Node) view.BoundTarget {
+	return view.NewR3TargetBinder(t.scope.matcher()).Bind(&view.Target{Template: nodes})
+}
+
+// Directive returns the scope directive of a directive matched by the binder, nil for anything
+// else.
+func (t *ComponentTemplate) Directive(dir interface{}) *ScopeDirective {
+	meta, ok := dir.(view.DirectiveMeta)
+	if !ok {
+		return nil
+	}
+	return t.byMeta[meta]
+}
@@ -0,0 +1,133 @@
+package annotations
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/output"
+)
+
+// TransformResult is a source file rewritten by TransformSource.
+type TransformResult struct {
+	Code string
+	// SourceMap maps the code to the source file, which it embeds. It is nil when the file is
+	// unchanged.
+	SourceMap *output.SourceMap
+}
+
+// TransformSource rewrites a `.ts` or `.js` source file so that its decorated classes carry
+// their full definitions, the way the runtime loads them: each class is followed by the
+// assignments of its static fields, e.g. `GreetComponent.ɵcmp = i0.ɵɵdefineComponent(...)`, and
+// by `ɵsetClassMetadata`, which only runs when `ngDevMode` is set. The Angular decorators are
+// removed. The rest of the file is preserved byte for byte; the namespace imports of the
+// generated code follow its imports and the shared constants precede the first class.
+//
+// TypeScript files keep their types, but the definitions are not declared on the classes, so
+// the code is meant for bundlers which strip types rather than for type-checking; EmitTypeScript
+// declares them as typed static fields instead.
+func (c *Compiler) TransformSource(sf *reflection.SourceFile) (*TransformResult, error) {
+	constantPool := c.newConstantPool()
+	compiled := c.CompileFull(sf, constantPool)
+	if len(compiled) == 0 {
+		return &TransformResult{Code: sf.Text}, nil
+	}
+
+	js := output.NewJsEmitterVisitor()
+	js.AnnotateForClosureCompiler = c.options.ClosureCompiler
+	var emitter statementEmitter = js
+	if strings.HasSuffix(sf.FileName, ".ts") {
+		emitter = output.NewTsEmitterVisitor()
+	}
+	var edits []sourceEdit
+	for _, cc := range compiled {
+		edits = append(edits, c.decoratorEdits(c.byClass[cc.Class])...)
+
+		class := output.NewReadVarExpr(cc.Class.Name, nil, nil)
+		var stmts []output.OutputStatement
+		for _, res := range cc.Results {
+			field := output.NewReadPropExpr(class, res.Name, nil, nil)
+			stmts = append(stmts, output.NewExpressionStatement(field.Set(res.Initializer), nil, c.fieldComments()))
+			stmts = append(stmts, res.Statements...)
+		}
+		stmts = append(stmts, output.NewExpressionStatement(cc.Metadata, nil, nil))
+		ctx := output.CreateRootEmitterVisitorContext()
+		emitter.VisitAllStatements(stmts, ctx)
+		edits = append(edits, sourceEdit{start: cc.Class.End, end: cc.Class.End, text: "\n" + ctx.ToSource()})
+	}
+	edits = append(edits, moduleEdits(sf, compiled, constantPool, emitter)...)
+
+	chunks := editChunks(sf.Text, edits)
+	var code strings.Builder
+	for _, ch := range chunks {
+		code.WriteString(ch.text)
+	}
+	sourceMap, err := chunksSourceMap(path.Base(sf.FileName), sf.Text, chunks)
+	if err != nil {
+		return nil, err
+	}
+	return &TransformResult{Code: code.String(), SourceMap: sourceMap}, nil
+}
+
+// chunk is a part of an edited text: text of the original starting at offset, or inserted text
+// when offset is -1.
+type chunk struct {
+	text   string
+	offset int
+}
+
+// chunksSourceMap maps the lines of an edited text to the original, named sourceURL. Every run
+// of original text on a line is mapped to where it starts in the original, and inserted text is
+// left unmapped.
+func chunksSourceMap(sourceURL, text string, chunks []chunk) (*output.SourceMap, error) {
+	var lineStarts []int
+	for i := range text {
+		if i == 0 || text[i-1] == '\n' {
+			lineStarts = append(lineStarts, i)
+		}
+	}
+
+	gen := output.NewSourceMapGenerator(&sourceURL)
+	gen.AddSource(sourceURL, &text)
+	gen.AddLine()
+	col := 0
+	for _, ch := range chunks {
+		offset := ch.offset
+		for i, line := range strings.Split(ch.text, "\n") {
+			if i > 0 {
+				gen.AddLine()
+				col = 0
+			}
+			if line != "" {
+				var err error
+				if ch.offset < 0 {
+					err = gen.AddMapping(col, nil, nil, nil)
+				} else {
+					srcLine := sort.SearchInts(lineStarts, offset+1) - 1
+					srcCol := utf16Len(text[lineStarts[srcLine]:offset])
+					err = gen.AddMapping(col, &sourceURL, &srcLine, &srcCol)
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+			col += utf16Len(line)
+			offset += len(line) + 1
+		}
+	}
+	return gen.ToJSON()
+}
+
+// utf16Len returns the length of a string in UTF-16 code units, which source map columns count.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r > 0xFFFF {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,69 @@
+package annotations
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck"
+	"ngc-go/packages/compiler/src/render3/view"
+)
+
+// addTypeCheckBlock generates the type-check block of the bound template of a component into the
+// type-check file of its source file. Like the full modules, the file sits next to the tsc output
+// of the source files and imports the classes from there.
+func (c *Compiler) addTypeCheckBlock(ac *analyzedClass, scope *templateScope, bound view.BoundTarget) {
+	f := ac.file
+	file := c.typeCheckFiles[f]
+	if file == nil {
+		file = typecheck.NewTypeCheckFile(strings.TrimSuffix(f.FileName, ".ts") + ".ngtypecheck.ts")
+		c.typeCheckFiles[f] = file
+	}
+	meta := &typecheck.TypeCheckBlockMetadata{ComponentName: ac.class.Name, Pipes: make(map[string]string)}
+	file.Imports[ac.class.Name] = relativeModule(f.FileName, f.FileName)
+	for _, dir := range scope.directives {
+		file.Imports[dir.class.Name] = relativeModule(f.FileName, dir.file.FileName)
+	}
+	for _, pipe := range scope.pipes {
+		meta.Pipes[*pipe.pipe.PipeName] = pipe.class.Name
+		file.Imports[pipe.class.Name] = relativeModule(f.FileName, pipe.file.FileName)
+	}
+	for _, dep := range scope.external {
+		ext := dep.class
+		if ext.kind == kindPipe {
+			meta.Pipes[ext.pipeName] = ext.name
+		}
+		if module := c.packageSpecifier(ext.fileName); module != "" {
+			file.Imports[ext.name] = module
+		}
+	}
+	block := file.AddTypeCheckBlock(bound, meta)
+	c.diags = append(c.diags, block.Diagnostics...)
+}
+
+// packageSpecifier returns the specifier of the entry point of a package whose declaration file
+// is fileName, among the ones the compiler read, or "" when there is none.
+func (c *Compiler) packageSpecifier(fileName string) string {
+	var specifiers []string
+	for specifier, typings := range c.typings {
+		if typings != "" && path.Clean(typings) == path.Clean(fileName) {
+			specifiers = append(specifiers, specifier)
+		}
+	}
+	if len(specifiers) == 0 {
+		return ""
+	}
+	sort.Strings(specifiers)
+	return specifiers[0]
+}
+
+// TypeCheckFile returns the type-check blocks of the component templates of a file, generated
+// with Options.TypeCheckBlocks, or nil when it has none. It must be called after Analyze.
+func (c *Compiler) TypeCheckFile(sf *reflection.SourceFile) *typecheck.TypeCheckFile {
+	f := c.byName[path.Clean(sf.FileName)]
+	if f == nil {
+		return nil
+	}
+	return c.typeCheckFiles[f]
+}
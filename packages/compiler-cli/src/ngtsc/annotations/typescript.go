@@ -0,0 +1,158 @@
+package annotations
+
+import (
+	"sort"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/output"
+)
+
+// sourceEdit replaces Text[start:end] of a source file.
+type sourceEdit struct {
+	start, end int
+	text       string
+}
+
+// EmitTypeScript prints a source file with the full definitions added to its decorated classes
+// as typed static fields, e.g. `static ɵcmp: i0.ɵɵComponentDeclaration<...> = ...`. The Angular
+// decorators are removed, as their metadata is recorded by `ɵsetClassMetadata` after the class.
+// The rest of the file is unchanged; the namespace imports of the generated code follow its
+// imports and the shared constants precede the first class. It returns the source unchanged
+// when the file has no class to compile.
+func (c *Compiler) EmitTypeScript(sf *reflection.SourceFile) string {
+	constantPool := c.newConstantPool()
+	compiled := c.CompileFull(sf, constantPool)
+	if len(compiled) == 0 {
+		return sf.Text
+	}
+
+	emitter := output.NewTsEmitterVisitor()
+	var edits []sourceEdit
+	for _, cc := range compiled {
+		ac := c.byClass[cc.Class]
+		edits = append(edits, c.decoratorEdits(ac)...)
+
+		fields := output.NewEmitterVisitorContext(1)
+		for _, res := range cc.Results {
+			for _, comment := range c.fieldComments() {
+				fields.Print(nil, comment.JSDoc.InlineString()+" ", false)
+			}
+			emitter.EmitStaticField(res.Name, res.Type, res.Initializer, fields)
+		}
+		bodyEnd := cc.Class.End - 1
+		text := fields.ToSource() + "\n"
+		if !strings.HasSuffix(sf.Text[:bodyEnd], "\n") {
+			text = "\n" + text
+		}
+		edits = append(edits, sourceEdit{start: bodyEnd, end: bodyEnd, text: text})
+
+		stmts := output.CreateRootEmitterVisitorContext()
+		for _, res := range cc.Results {
+			emitter.VisitAllStatements(res.Statements, stmts)
+		}
+		emitter.VisitAllStatements([]output.OutputStatement{output.NewExpressionStatement(cc.Metadata, nil, nil)}, stmts)
+		edits = append(edits, sourceEdit{start: cc.Class.End, end: cc.Class.End, text: "\n" + stmts.ToSource()})
+	}
+
+	edits = append(edits, moduleEdits(sf, compiled, constantPool, emitter)...)
+	return applyEdits(sf.Text, edits)
+}
+
+// statementEmitter prints the generated code of a file, sharing the namespace imports of its
+// references.
+type statementEmitter interface {
+	VisitAllStatements(stmts []output.OutputStatement, ctx *output.EmitterVisitorContext)
+	ImportsWithPrefixes() []output.ImportWithPrefix
+}
+
+// moduleEdits inserts the shared constants of the compiled classes of a file before its first
+// class, and the namespace imports of the generated code after its imports. It is called once
+// everything else is emitted, as the namespaces are only known then.
+func moduleEdits(sf *reflection.SourceFile, compiled []*CompiledClass, constantPool *constant.ConstantPool, emitter statementEmitter) []sourceEdit {
+	var edits []sourceEdit
+	if constants := constantPool.GetStatements(); len(constants) > 0 {
+		ctx := output.CreateRootEmitterVisitorContext()
+		emitter.VisitAllStatements(constants, ctx)
+		edits = append(edits, sourceEdit{start: compiled[0].Class.Start, end: compiled[0].Class.Start, text: ctx.ToSource() + "\n"})
+	}
+
+	importsEnd := 0
+	for _, imp := range sf.Imports {
+		if imp.End > importsEnd {
+			importsEnd = imp.End
+		}
+	}
+	imports := strings.Join(output.ImportDeclarations(emitter.ImportsWithPrefixes()), "\n")
+	if importsEnd > 0 {
+		imports = "\n" + imports
+	} else {
+		imports += "\n"
+	}
+	return append(edits, sourceEdit{start: importsEnd, end: importsEnd, text: imports})
+}
+
+// decoratorEdits removes the Angular decorators of a class, its members and its constructor
+// parameters, along with the whitespace following them.
+func (c *Compiler) decoratorEdits(ac *analyzedClass) []sourceEdit {
+	f := ac.file
+	decorators := []*reflection.Decorator{ac.decorator}
+	for _, member := range ac.class.Members {
+		decorators = append(decorators, member.Decorators...)
+	}
+	if ctor := ac.class.Constructor; ctor != nil {
+		for _, param := range ctor.Parameters {
+			decorators = append(decorators, param.Decorators...)
+		}
+	}
+
+	var edits []sourceEdit
+	for _, dec := range decorators {
+		if dec != ac.decorator && f.coreName(dec.Name) == "" {
+			continue
+		}
+		end := dec.End
+		for end < len(f.Text) && strings.ContainsRune(" \t\r\n", rune(f.Text[end])) {
+			end++
+		}
+		edits = append(edits, sourceEdit{start: dec.Start, end: end})
+	}
+	return edits
+}
+
+// applyEdits applies non-overlapping edits to a text.
+func applyEdits(text string, edits []sourceEdit) string {
+	var b strings.Builder
+	for _, ch := range editChunks(text, edits) {
+		b.WriteString(ch.text)
+	}
+	return b.String()
+}
+
+// editChunks splits the result of applying non-overlapping edits to a text into the parts kept
+// from the text and the inserted ones. Insertions precede the replacements starting at the same
+// offset, and are applied in the order they are given.
+func editChunks(text string, edits []sourceEdit) []chunk {
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start < edits[j].start
+		}
+		return edits[i].end < edits[j].end
+	})
+	var chunks []chunk
+	last := 0
+	for _, edit := range edits {
+		if edit.start > last {
+			chunks = append(chunks, chunk{text: text[last:edit.start], offset: last})
+		}
+		if edit.text != "" {
+			chunks = append(chunks, chunk{text: edit.text, offset: -1})
+		}
+		last = edit.end
+	}
+	if last < len(text) {
+		chunks = append(chunks, chunk{text: text[last:], offset: last})
+	}
+	return chunks
+}
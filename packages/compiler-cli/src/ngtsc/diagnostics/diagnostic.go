@@ -0,0 +1,207 @@
+package diagnostics
+
+import (
+	"fmt"
+	"sort"
+
+	"ngc-go/packages/compiler/src/util"
+)
+
+// Category is the severity of a diagnostic.
+type Category int
+
+const (
+	// CategoryError fails the compilation.
+	CategoryError Category = iota
+	// CategoryWarning is reported but does not fail the compilation.
+	CategoryWarning
+	// CategorySuggestion is an optional improvement, e.g. from an extended diagnostic.
+	CategorySuggestion
+	// CategoryMessage is purely informational.
+	CategoryMessage
+)
+
+// String returns the lowercase name of the category as used in the text and JSON output.
+func (c Category) String() string {
+	switch c {
+	case CategoryError:
+		return "error"
+	case CategoryWarning:
+		return "warning"
+	case CategorySuggestion:
+		return "suggestion"
+	case CategoryMessage:
+		return "message"
+	}
+	return "unknown"
+}
+
+// ParseCategory parses the lowercase name of a category.
+func ParseCategory(value string) (Category, error) {
+	switch value {
+	case "error":
+		return CategoryError, nil
+	case "warning":
+		return CategoryWarning, nil
+	case "suggestion":
+		return CategorySuggestion, nil
+	case "message":
+		return CategoryMessage, nil
+	}
+	return CategoryError, fmt.Errorf("unknown diagnostic category %q", value)
+}
+
+// RelatedInformation points at a secondary location which helps understanding a diagnostic,
+// e.g. the component declaring the template an error was found in.
+type RelatedInformation struct {
+	Message string
+	Span    *util.ParseSourceSpan
+	// File is used when no span is available.
+	File string
+}
+
+// Diagnostic is a single problem reported by the compiler.
+type Diagnostic struct {
+	Code     ErrorCode
+	Category Category
+	Message  string
+	// Span is the location of the problem. It is nil for diagnostics which are not tied to a
+	// specific position, in which case File may still name the file they relate to.
+	Span               *util.ParseSourceSpan
+	File               string
+	RelatedInformation []*RelatedInformation
+
+	// FromTypeScript marks diagnostics reported by the TypeScript compiler, e.g. while checking a
+	// type-check block. Their Code is a TypeScript error code, displayed as `TS2339`.
+	FromTypeScript bool
+}
+
+// MakeDiagnostic creates a new diagnostic at the given span.
+func MakeDiagnostic(
+	code ErrorCode,
+	category Category,
+	span *util.ParseSourceSpan,
+	message string,
+	relatedInformation ...*RelatedInformation,
+) *Diagnostic {
+	diag := &Diagnostic{
+		Code:               code,
+		Category:           category,
+		Message:            message,
+		Span:               span,
+		RelatedInformation: relatedInformation,
+	}
+	if span != nil && span.Start != nil && span.Start.File != nil {
+		diag.File = span.Start.File.URL
+	}
+	return diag
+}
+
+// MakeFileDiagnostic creates a new diagnostic which relates to a whole file.
+func MakeFileDiagnostic(code ErrorCode, category Category, file string, message string) *Diagnostic {
+	return &Diagnostic{
+		Code:     code,
+		Category: category,
+		Message:  message,
+		File:     file,
+	}
+}
+
+// MakeRelatedInformation creates a related location for a diagnostic.
+func MakeRelatedInformation(span *util.ParseSourceSpan, message string) *RelatedInformation {
+	info := &RelatedInformation{Message: message, Span: span}
+	if span != nil && span.Start != nil && span.Start.File != nil {
+		info.File = span.Start.File.URL
+	}
+	return info
+}
+
+// FromParseError converts an error produced by one of the parsers into a diagnostic with the
+// given code. Parse warnings become warnings.
+func FromParseError(err *util.ParseError, code ErrorCode) *Diagnostic {
+	category := CategoryError
+	if err.Level == util.ParseErrorLevelWarning {
+		category = CategoryWarning
+	}
+	return MakeDiagnostic(code, category, err.Span, err.Msg)
+}
+
+// FromParseErrors converts all the given parse errors using `FromParseError`.
+func FromParseErrors(errors []*util.ParseError, code ErrorCode) []*Diagnostic {
+	diags := make([]*Diagnostic, 0, len(errors))
+	for _, err := range errors {
+		diags = append(diags, FromParseError(err, code))
+	}
+	return diags
+}
+
+// DisplayCode returns the code as it is shown to users, e.g. `NG8001` or `TS2339`.
+func (d *Diagnostic) DisplayCode() string {
+	if d.FromTypeScript {
+		return fmt.Sprintf("TS%d", int(d.Code))
+	}
+	return NgErrorCode(d.Code)
+}
+
+// Error implements the error interface so that a diagnostic can be returned as a fatal error.
+func (d *Diagnostic) Error() string {
+	return d.String()
+}
+
+// String returns a single-line representation of the diagnostic in the form
+// `file:line:col - error NG8001: message`.
+func (d *Diagnostic) String() string {
+	return fmt.Sprintf("%s%s %s: %s", locationPrefix(d.File, d.Span), d.Category, d.DisplayCode(), d.Message)
+}
+
+// locationPrefix returns `file:line:col - ` for the given location, or an empty string when
+// there is no location. Lines and columns are 1-based.
+func locationPrefix(file string, span *util.ParseSourceSpan) string {
+	if span != nil && span.Start != nil {
+		return fmt.Sprintf("%s:%d:%d - ", file, span.Start.Line+1, span.Start.Col+1)
+	}
+	if file != "" {
+		return file + " - "
+	}
+	return ""
+}
+
+// HasErrors reports whether any of the diagnostics is an error.
+func HasErrors(diags []*Diagnostic) bool {
+	for _, diag := range diags {
+		if diag.Category == CategoryError {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the number of diagnostics of the given category.
+func Count(diags []*Diagnostic, category Category) int {
+	count := 0
+	for _, diag := range diags {
+		if diag.Category == category {
+			count++
+		}
+	}
+	return count
+}
+
+// Sort orders diagnostics by file and then by position, keeping the original order of
+// diagnostics reported at the same location.
+func Sort(diags []*Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return offsetOf(a.Span) < offsetOf(b.Span)
+	})
+}
+
+func offsetOf(span *util.ParseSourceSpan) int {
+	if span == nil || span.Start == nil {
+		return -1
+	}
+	return span.Start.Offset
+}
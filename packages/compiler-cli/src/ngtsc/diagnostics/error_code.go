@@ -0,0 +1,237 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorCode is the stable numeric code of a compiler diagnostic.
+//
+// The values mirror the `ErrorCode` enum of the Angular compiler so that a code reported by
+// ngc-go means the same thing as the corresponding `NG####` code reported by `ngc`. Codes which
+// have no Angular equivalent are allocated in the 9000 range.
+type ErrorCode int
+
+const (
+	DecoratorArgNotLiteral                   ErrorCode = 1001
+	DecoratorArityWrong                      ErrorCode = 1002
+	DecoratorNotCalled                       ErrorCode = 1003
+	DecoratorUnexpected                      ErrorCode = 1005
+	DecoratorCollision                       ErrorCode = 1006
+	ValueHasWrongType                        ErrorCode = 1010
+	ValueNotLiteral                          ErrorCode = 1011
+	DuplicateDecoratedProperties             ErrorCode = 1012
+	InitializerApiWithDisallowedDecorator    ErrorCode = 1050
+	InitializerApiDecoratorMetadataCollision ErrorCode = 1051
+	IncorrectlyDeclaredOnStaticMember        ErrorCode = 1100
+
+	ComponentMissingTemplate             ErrorCode = 2001
+	PipeMissingName                      ErrorCode = 2002
+	ParamMissingToken                    ErrorCode = 2003
+	DirectiveMissingSelector             ErrorCode = 2004
+	UndecoratedProvider                  ErrorCode = 2005
+	DirectiveInheritsUndecoratedCtor     ErrorCode = 2006
+	UndecoratedClassUsingAngularFeatures ErrorCode = 2007
+	ComponentResourceNotFound            ErrorCode = 2008
+	ComponentInvalidShadowDomSelector    ErrorCode = 2009
+	ComponentNotStandalone               ErrorCode = 2010
+	ComponentImportNotStandalone         ErrorCode = 2011
+	ComponentUnknownImport               ErrorCode = 2012
+	HostDirectiveInvalid                 ErrorCode = 2013
+	ComponentInvalidStyleUrls            ErrorCode = 2021
+
+	SymbolNotExported       ErrorCode = 3001
+	ImportCycleDetected     ErrorCode = 3003
+	ImportGenerationFailure ErrorCode = 3004
+
+	ConfigFlatModuleNoIndex                           ErrorCode = 4001
+	ConfigStrictTemplatesImpliesFullTemplateTypecheck ErrorCode = 4002
+	ConfigExtendedDiagnosticsImpliesStrictTemplates   ErrorCode = 4003
+	ConfigExtendedDiagnosticsUnknownCategoryLabel     ErrorCode = 4004
+	ConfigExtendedDiagnosticsUnknownCheck             ErrorCode = 4005
+
+	HostBindingParseError ErrorCode = 5001
+	TemplateParseError    ErrorCode = 5002
+
+	NgmoduleInvalidDeclaration                ErrorCode = 6001
+	NgmoduleInvalidImport                     ErrorCode = 6002
+	NgmoduleInvalidExport                     ErrorCode = 6003
+	NgmoduleInvalidReexport                   ErrorCode = 6004
+	NgmoduleModuleWithProvidersMissingGeneric ErrorCode = 6005
+	NgmoduleReexportNameCollision             ErrorCode = 6006
+	NgmoduleDeclarationNotUnique              ErrorCode = 6007
+	NgmoduleDeclarationIsStandalone           ErrorCode = 6008
+
+	SchemaInvalidElement                   ErrorCode = 8001
+	SchemaInvalidAttribute                 ErrorCode = 8002
+	MissingReferenceTarget                 ErrorCode = 8003
+	MissingPipe                            ErrorCode = 8004
+	WriteToReadOnlyVariable                ErrorCode = 8005
+	DuplicateVariableDeclaration           ErrorCode = 8006
+	SplitTwoWayBinding                     ErrorCode = 8007
+	MissingRequiredInputs                  ErrorCode = 8008
+	IllegalForLoopTrackAccess              ErrorCode = 8009
+	InaccessibleDeferredTriggerElement     ErrorCode = 8010
+	ControlFlowPreventingContentProjection ErrorCode = 8011
+	DeferredPipeUsedEagerly                ErrorCode = 8012
+	DeferredDirectiveUsedEagerly           ErrorCode = 8013
+	DeferredDependencyImportedEagerly      ErrorCode = 8014
+	IllegalLetWrite                        ErrorCode = 8015
+	LetUsedBeforeDefinition                ErrorCode = 8016
+	ConflictingLetDeclaration              ErrorCode = 8017
+
+	InvalidBananaInBox              ErrorCode = 8101
+	NullishCoalescingNotNullable    ErrorCode = 8102
+	MissingControlFlowDirective     ErrorCode = 8103
+	TextAttributeNotBinding         ErrorCode = 8104
+	MissingNgforofLet               ErrorCode = 8105
+	SuffixNotSupported              ErrorCode = 8106
+	OptionalChainNotNullable        ErrorCode = 8107
+	SkipHydrationNotStatic          ErrorCode = 8108
+	InterpolatedSignalNotInvoked    ErrorCode = 8109
+	UnsupportedInitializerApiUsage  ErrorCode = 8110
+	UninvokedFunctionInEventBinding ErrorCode = 8111
+	UnusedLetDeclaration            ErrorCode = 8112
+	UnusedStandaloneImports         ErrorCode = 8113
+	MissingStructuralDirective      ErrorCode = 8116
+
+	InlineTcbRequired      ErrorCode = 8900
+	InlineTypeCtorRequired ErrorCode = 8901
+
+	InjectableDuplicateProv ErrorCode = 9001
+
+	// SchemaInvalidEvent is reported for event bindings that are neither outputs of a directive
+	// nor events known to the DOM schema. It has no `ngc` equivalent.
+	SchemaInvalidEvent ErrorCode = 9101
+	// UnnecessaryNonNullAssertion is reported in strict null checking mode for non-null
+	// assertions (`!`) on values which are never null. It has no `ngc` equivalent.
+	UnnecessaryNonNullAssertion ErrorCode = 9102
+	// DeclarationTypeNotInferred is reported by the declaration files for the types which are
+	// only known from an expression, e.g. the return type of a method without annotation. It has
+	// no `ngc` equivalent: tsc reports them with `isolatedDeclarations`.
+	DeclarationTypeNotInferred ErrorCode = 9103
+)
+
+// errorCodeNames holds the enum-style names of the error codes, used by machine-readable output
+// formats as rule names.
+var errorCodeNames = map[ErrorCode]string{
+	DecoratorArgNotLiteral:                   "DECORATOR_ARG_NOT_LITERAL",
+	DecoratorArityWrong:                      "DECORATOR_ARITY_WRONG",
+	DecoratorNotCalled:                       "DECORATOR_NOT_CALLED",
+	DecoratorUnexpected:                      "DECORATOR_UNEXPECTED",
+	DecoratorCollision:                       "DECORATOR_COLLISION",
+	ValueHasWrongType:                        "VALUE_HAS_WRONG_TYPE",
+	ValueNotLiteral:                          "VALUE_NOT_LITERAL",
+	DuplicateDecoratedProperties:             "DUPLICATE_DECORATED_PROPERTIES",
+	InitializerApiWithDisallowedDecorator:    "INITIALIZER_API_WITH_DISALLOWED_DECORATOR",
+	InitializerApiDecoratorMetadataCollision: "INITIALIZER_API_DECORATOR_METADATA_COLLISION",
+	IncorrectlyDeclaredOnStaticMember:        "INCORRECTLY_DECLARED_ON_STATIC_MEMBER",
+
+	ComponentMissingTemplate:             "COMPONENT_MISSING_TEMPLATE",
+	PipeMissingName:                      "PIPE_MISSING_NAME",
+	ParamMissingToken:                    "PARAM_MISSING_TOKEN",
+	DirectiveMissingSelector:             "DIRECTIVE_MISSING_SELECTOR",
+	UndecoratedProvider:                  "UNDECORATED_PROVIDER",
+	DirectiveInheritsUndecoratedCtor:     "DIRECTIVE_INHERITS_UNDECORATED_CTOR",
+	UndecoratedClassUsingAngularFeatures: "UNDECORATED_CLASS_USING_ANGULAR_FEATURES",
+	ComponentResourceNotFound:            "COMPONENT_RESOURCE_NOT_FOUND",
+	ComponentInvalidShadowDomSelector:    "COMPONENT_INVALID_SHADOW_DOM_SELECTOR",
+	ComponentNotStandalone:               "COMPONENT_NOT_STANDALONE",
+	ComponentImportNotStandalone:         "COMPONENT_IMPORT_NOT_STANDALONE",
+	ComponentUnknownImport:               "COMPONENT_UNKNOWN_IMPORT",
+	HostDirectiveInvalid:                 "HOST_DIRECTIVE_INVALID",
+	ComponentInvalidStyleUrls:            "COMPONENT_INVALID_STYLE_URLS",
+
+	SymbolNotExported:       "SYMBOL_NOT_EXPORTED",
+	ImportCycleDetected:     "IMPORT_CYCLE_DETECTED",
+	ImportGenerationFailure: "IMPORT_GENERATION_FAILURE",
+
+	ConfigFlatModuleNoIndex:                           "CONFIG_FLAT_MODULE_NO_INDEX",
+	ConfigStrictTemplatesImpliesFullTemplateTypecheck: "CONFIG_STRICT_TEMPLATES_IMPLIES_FULL_TEMPLATE_TYPECHECK",
+	ConfigExtendedDiagnosticsImpliesStrictTemplates:   "CONFIG_EXTENDED_DIAGNOSTICS_IMPLIES_STRICT_TEMPLATES",
+	ConfigExtendedDiagnosticsUnknownCategoryLabel:     "CONFIG_EXTENDED_DIAGNOSTICS_UNKNOWN_CATEGORY_LABEL",
+	ConfigExtendedDiagnosticsUnknownCheck:             "CONFIG_EXTENDED_DIAGNOSTICS_UNKNOWN_CHECK",
+
+	HostBindingParseError: "HOST_BINDING_PARSE_ERROR",
+	TemplateParseError:    "TEMPLATE_PARSE_ERROR",
+
+	NgmoduleInvalidDeclaration:                "NGMODULE_INVALID_DECLARATION",
+	NgmoduleInvalidImport:                     "NGMODULE_INVALID_IMPORT",
+	NgmoduleInvalidExport:                     "NGMODULE_INVALID_EXPORT",
+	NgmoduleInvalidReexport:                   "NGMODULE_INVALID_REEXPORT",
+	NgmoduleModuleWithProvidersMissingGeneric: "NGMODULE_MODULE_WITH_PROVIDERS_MISSING_GENERIC",
+	NgmoduleReexportNameCollision:             "NGMODULE_REEXPORT_NAME_COLLISION",
+	NgmoduleDeclarationNotUnique:              "NGMODULE_DECLARATION_NOT_UNIQUE",
+	NgmoduleDeclarationIsStandalone:           "NGMODULE_DECLARATION_IS_STANDALONE",
+
+	SchemaInvalidElement:                   "SCHEMA_INVALID_ELEMENT",
+	SchemaInvalidAttribute:                 "SCHEMA_INVALID_ATTRIBUTE",
+	MissingReferenceTarget:                 "MISSING_REFERENCE_TARGET",
+	MissingPipe:                            "MISSING_PIPE",
+	WriteToReadOnlyVariable:                "WRITE_TO_READ_ONLY_VARIABLE",
+	DuplicateVariableDeclaration:           "DUPLICATE_VARIABLE_DECLARATION",
+	SplitTwoWayBinding:                     "SPLIT_TWO_WAY_BINDING",
+	MissingRequiredInputs:                  "MISSING_REQUIRED_INPUTS",
+	IllegalForLoopTrackAccess:              "ILLEGAL_FOR_LOOP_TRACK_ACCESS",
+	InaccessibleDeferredTriggerElement:     "INACCESSIBLE_DEFERRED_TRIGGER_ELEMENT",
+	ControlFlowPreventingContentProjection: "CONTROL_FLOW_PREVENTING_CONTENT_PROJECTION",
+	DeferredPipeUsedEagerly:                "DEFERRED_PIPE_USED_EAGERLY",
+	DeferredDirectiveUsedEagerly:           "DEFERRED_DIRECTIVE_USED_EAGERLY",
+	DeferredDependencyImportedEagerly:      "DEFERRED_DEPENDENCY_IMPORTED_EAGERLY",
+	IllegalLetWrite:                        "ILLEGAL_LET_WRITE",
+	LetUsedBeforeDefinition:                "LET_USED_BEFORE_DEFINITION",
+	ConflictingLetDeclaration:              "CONFLICTING_LET_DECLARATION",
+
+	InvalidBananaInBox:              "INVALID_BANANA_IN_BOX",
+	NullishCoalescingNotNullable:    "NULLISH_COALESCING_NOT_NULLABLE",
+	MissingControlFlowDirective:     "MISSING_CONTROL_FLOW_DIRECTIVE",
+	TextAttributeNotBinding:         "TEXT_ATTRIBUTE_NOT_BINDING",
+	MissingNgforofLet:               "MISSING_NGFOROF_LET",
+	SuffixNotSupported:              "SUFFIX_NOT_SUPPORTED",
+	OptionalChainNotNullable:        "OPTIONAL_CHAIN_NOT_NULLABLE",
+	SkipHydrationNotStatic:          "SKIP_HYDRATION_NOT_STATIC",
+	InterpolatedSignalNotInvoked:    "INTERPOLATED_SIGNAL_NOT_INVOKED",
+	UnsupportedInitializerApiUsage:  "UNSUPPORTED_INITIALIZER_API_USAGE",
+	UninvokedFunctionInEventBinding: "UNINVOKED_FUNCTION_IN_EVENT_BINDING",
+	UnusedLetDeclaration:            "UNUSED_LET_DECLARATION",
+	UnusedStandaloneImports:         "UNUSED_STANDALONE_IMPORTS",
+	MissingStructuralDirective:      "MISSING_STRUCTURAL_DIRECTIVE",
+
+	InlineTcbRequired:      "INLINE_TCB_REQUIRED",
+	InlineTypeCtorRequired: "INLINE_TYPE_CTOR_REQUIRED",
+
+	InjectableDuplicateProv: "INJECTABLE_DUPLICATE_PROV",
+
+	SchemaInvalidEvent:          "SCHEMA_INVALID_EVENT",
+	UnnecessaryNonNullAssertion: "UNNECESSARY_NON_NULL_ASSERTION",
+	DeclarationTypeNotInferred:  "DECLARATION_TYPE_NOT_INFERRED",
+}
+
+// NgErrorCode returns the code as it is shown to users, e.g. `NG8001`.
+func NgErrorCode(code ErrorCode) string {
+	return fmt.Sprintf("NG%d", int(code))
+}
+
+// String returns the enum-style name of the code, e.g. `SCHEMA_INVALID_ELEMENT`.
+func (c ErrorCode) String() string {
+	if name, ok := errorCodeNames[c]; ok {
+		return name
+	}
+	return NgErrorCode(c)
+}
+
+// ParseErrorCode parses a code given either as a number (`8001`), with the `NG` prefix
+// (`NG8001`) or by name (`SCHEMA_INVALID_ELEMENT`).
+func ParseErrorCode(value string) (ErrorCode, error) {
+	trimmed := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "NG")
+	var code int
+	if _, err := fmt.Sscanf(trimmed, "%d", &code); err == nil && fmt.Sprint(code) == trimmed {
+		return ErrorCode(code), nil
+	}
+	for code, name := range errorCodeNames {
+		if strings.EqualFold(name, strings.TrimSpace(value)) {
+			return code, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown error code %q", value)
+}
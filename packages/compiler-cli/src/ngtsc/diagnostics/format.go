@@ -0,0 +1,430 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ngc-go/packages/compiler/src/util"
+)
+
+// Format is an output format for diagnostics.
+type Format string
+
+const (
+	// FormatText renders diagnostics for humans, with a code frame for each location.
+	FormatText Format = "text"
+	// FormatJSON renders diagnostics as a single JSON document.
+	FormatJSON Format = "json"
+	// FormatSARIF renders diagnostics as a SARIF 2.1.0 log, understood by most CI systems.
+	FormatSARIF Format = "sarif"
+)
+
+// ParseFormat parses the value of `--diagnostics-format`.
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case FormatText, FormatJSON, FormatSARIF:
+		return Format(value), nil
+	}
+	return "", fmt.Errorf("unknown diagnostics format %q (expected text, json or sarif)", value)
+}
+
+// FormatOptions configures `WriteDiagnostics`.
+type FormatOptions struct {
+	Format Format
+	// BaseDir is the directory file names are reported relative to. File names are reported as
+	// they are when empty.
+	BaseDir string
+	// ToolVersion is reported as the driver version in SARIF output.
+	ToolVersion string
+}
+
+// WriteDiagnostics writes the diagnostics to w in the requested format.
+func WriteDiagnostics(w io.Writer, diags []*Diagnostic, options FormatOptions) error {
+	switch options.Format {
+	case FormatText, "":
+		_, err := io.WriteString(w, FormatDiagnosticsWithContext(diags, options.BaseDir))
+		return err
+	case FormatJSON:
+		return writeJSON(w, toJSONReport(diags, options.BaseDir))
+	case FormatSARIF:
+		return writeJSON(w, toSarifLog(diags, options))
+	}
+	return fmt.Errorf("unknown diagnostics format %q", options.Format)
+}
+
+func writeJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(value)
+}
+
+// JSONDiagnostics returns the diagnostics as listed by FormatJSON, for embedding them in other
+// JSON documents.
+func JSONDiagnostics(diags []*Diagnostic, baseDir string) interface{} {
+	return toJSONReport(diags, baseDir).Diagnostics
+}
+
+// relativeFile returns the file name relative to baseDir, using forward slashes.
+func relativeFile(file string, baseDir string) string {
+	if file == "" || baseDir == "" {
+		return filepath.ToSlash(file)
+	}
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+	absBase, err := filepath.Abs(baseDir)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+	rel, err := filepath.Rel(absBase, absFile)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// FormatDiagnosticsWithContext renders diagnostics for a terminal. Every diagnostic is followed
+// by a code frame of the offending source, and the output ends with a summary line:
+//
+//	src/app/app.component.html:3:5 - error NG8001: 'foo-bar' is not a known element:
+//
+//	2 <div>
+//	3   <foo-bar></foo-bar>
+//	    ~~~~~~~~~
+func FormatDiagnosticsWithContext(diags []*Diagnostic, baseDir string) string {
+	var sb strings.Builder
+	for _, diag := range diags {
+		sb.WriteString(locationPrefix(relativeFile(diag.File, baseDir), diag.Span))
+		sb.WriteString(fmt.Sprintf("%s %s: %s\n", diag.Category, diag.DisplayCode(), diag.Message))
+		if frame := codeFrame(diag.Span, ""); frame != "" {
+			sb.WriteString("\n")
+			sb.WriteString(frame)
+		}
+		for _, info := range diag.RelatedInformation {
+			sb.WriteString("\n  ")
+			sb.WriteString(strings.TrimSuffix(locationPrefix(relativeFile(info.File, baseDir), info.Span), " - "))
+			sb.WriteString("\n")
+			if frame := codeFrame(info.Span, "    "); frame != "" {
+				sb.WriteString(frame)
+			}
+			sb.WriteString("    ")
+			sb.WriteString(strings.ReplaceAll(info.Message, "\n", "\n    "))
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(summary(diags))
+	return sb.String()
+}
+
+// summary returns e.g. `Found 2 errors and 1 warning.`, or an empty string when there are no
+// diagnostics.
+func summary(diags []*Diagnostic) string {
+	if len(diags) == 0 {
+		return ""
+	}
+	parts := []string{}
+	for _, category := range []Category{CategoryError, CategoryWarning, CategorySuggestion, CategoryMessage} {
+		count := Count(diags, category)
+		if count == 0 {
+			continue
+		}
+		label := category.String()
+		if count > 1 {
+			label += "s"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", count, label))
+	}
+	if len(parts) > 1 {
+		parts = append(parts[:len(parts)-2], parts[len(parts)-2]+" and "+parts[len(parts)-1])
+	}
+	return fmt.Sprintf("Found %s.\n", strings.Join(parts, ", "))
+}
+
+// codeFrame renders the source line of the span together with the line before it, underlining
+// the span with `~`. The source is extracted with `ParseLocation.GetContext`.
+func codeFrame(span *util.ParseSourceSpan, indent string) string {
+	if span == nil || span.Start == nil || span.Start.File == nil || span.Start.File.Content == "" {
+		return ""
+	}
+	start := span.Start
+	ctx := start.GetContext(500, 2)
+	if ctx == nil {
+		return ""
+	}
+
+	// `Before` ends with the text preceding the span on its line and, if there is one, starts
+	// with the previous line. `After` starts with the rest of the line.
+	beforeLines := strings.Split(ctx.Before, "\n")
+	linePrefix := beforeLines[len(beforeLines)-1]
+	lineSuffix := ctx.After
+	if idx := strings.Index(lineSuffix, "\n"); idx >= 0 {
+		lineSuffix = lineSuffix[:idx]
+	}
+	line := strings.TrimSuffix(linePrefix+lineSuffix, "\r")
+
+	lineNumber := start.Line + 1
+	gutterWidth := len(fmt.Sprint(lineNumber))
+
+	var sb strings.Builder
+	if len(beforeLines) >= 2 && start.Line > 0 {
+		previous := strings.TrimSuffix(beforeLines[len(beforeLines)-2], "\r")
+		sb.WriteString(fmt.Sprintf("%s%*d %s\n", indent, gutterWidth, lineNumber-1, previous))
+	}
+	sb.WriteString(fmt.Sprintf("%s%*d %s\n", indent, gutterWidth, lineNumber, line))
+
+	// Underline up to the end of the span, or to the end of the line for multi-line spans.
+	length := len(line) - len(linePrefix)
+	if span.End != nil && span.End.Offset >= start.Offset && span.End.Line == start.Line {
+		length = span.End.Offset - start.Offset
+	}
+	if length < 1 {
+		length = 1
+	}
+	// Keep tabs in the padding so that the marker lines up with the source.
+	padding := strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return '\t'
+		}
+		return ' '
+	}, linePrefix)
+	sb.WriteString(fmt.Sprintf("%s%s %s%s\n", indent, strings.Repeat(" ", gutterWidth), padding, strings.Repeat("~", length)))
+	return sb.String()
+}
+
+// jsonReport is the document written by `--diagnostics-format=json`.
+type jsonReport struct {
+	Diagnostics     []*jsonDiagnostic `json:"diagnostics"`
+	ErrorCount      int               `json:"errorCount"`
+	WarningCount    int               `json:"warningCount"`
+	SuggestionCount int               `json:"suggestionCount"`
+}
+
+type jsonDiagnostic struct {
+	Code               string             `json:"code"`
+	Name               string             `json:"name"`
+	Category           string             `json:"category"`
+	Message            string             `json:"message"`
+	File               string             `json:"file,omitempty"`
+	Start              *jsonPosition      `json:"start,omitempty"`
+	End                *jsonPosition      `json:"end,omitempty"`
+	RelatedInformation []*jsonRelatedInfo `json:"relatedInformation,omitempty"`
+}
+
+type jsonRelatedInfo struct {
+	Message string        `json:"message"`
+	File    string        `json:"file,omitempty"`
+	Start   *jsonPosition `json:"start,omitempty"`
+	End     *jsonPosition `json:"end,omitempty"`
+}
+
+// jsonPosition is a position in a file. Lines and columns are 1-based, the offset is 0-based.
+type jsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+func toJSONPosition(location *util.ParseLocation) *jsonPosition {
+	if location == nil {
+		return nil
+	}
+	return &jsonPosition{Line: location.Line + 1, Column: location.Col + 1, Offset: location.Offset}
+}
+
+func spanPositions(span *util.ParseSourceSpan) (*jsonPosition, *jsonPosition) {
+	if span == nil {
+		return nil, nil
+	}
+	return toJSONPosition(span.Start), toJSONPosition(span.End)
+}
+
+func toJSONReport(diags []*Diagnostic, baseDir string) *jsonReport {
+	report := &jsonReport{
+		Diagnostics:     make([]*jsonDiagnostic, 0, len(diags)),
+		ErrorCount:      Count(diags, CategoryError),
+		WarningCount:    Count(diags, CategoryWarning),
+		SuggestionCount: Count(diags, CategorySuggestion),
+	}
+	for _, diag := range diags {
+		start, end := spanPositions(diag.Span)
+		entry := &jsonDiagnostic{
+			Code:     diag.DisplayCode(),
+			Name:     codeName(diag),
+			Category: diag.Category.String(),
+			Message:  diag.Message,
+			File:     relativeFile(diag.File, baseDir),
+			Start:    start,
+			End:      end,
+		}
+		for _, info := range diag.RelatedInformation {
+			start, end := spanPositions(info.Span)
+			entry.RelatedInformation = append(entry.RelatedInformation, &jsonRelatedInfo{
+				Message: info.Message,
+				File:    relativeFile(info.File, baseDir),
+				Start:   start,
+				End:     end,
+			})
+		}
+		report.Diagnostics = append(report.Diagnostics, entry)
+	}
+	return report
+}
+
+// The SARIF types below cover the subset of SARIF 2.1.0 produced by the compiler.
+
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    *sarifTool     `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver *sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string       `json:"name"`
+	Version string       `json:"version,omitempty"`
+	Rules   []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleID           string           `json:"ruleId"`
+	RuleIndex        int              `json:"ruleIndex"`
+	Level            string           `json:"level"`
+	Message          *sarifMessage    `json:"message"`
+	Locations        []*sarifLocation `json:"locations,omitempty"`
+	RelatedLocations []*sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	ID               *int                   `json:"id,omitempty"`
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	Message          *sarifMessage          `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation *sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion           `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// codeName returns the enum-style name of the code of a diagnostic. TypeScript codes have no
+// name, so their display code is used.
+func codeName(diag *Diagnostic) string {
+	if diag.FromTypeScript {
+		return diag.DisplayCode()
+	}
+	return diag.Code.String()
+}
+
+func sarifLevel(category Category) string {
+	switch category {
+	case CategoryError:
+		return "error"
+	case CategoryWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+func toSarifLocation(file string, span *util.ParseSourceSpan, baseDir string) *sarifPhysicalLocation {
+	if file == "" {
+		return nil
+	}
+	location := &sarifPhysicalLocation{ArtifactLocation: &sarifArtifactLocation{URI: relativeFile(file, baseDir)}}
+	if span != nil && span.Start != nil {
+		location.Region = &sarifRegion{StartLine: span.Start.Line + 1, StartColumn: span.Start.Col + 1}
+		if span.End != nil {
+			location.Region.EndLine = span.End.Line + 1
+			location.Region.EndColumn = span.End.Col + 1
+		}
+	}
+	return location
+}
+
+func toSarifLog(diags []*Diagnostic, options FormatOptions) *sarifLog {
+	// Every code that is reported becomes a rule of the driver, ordered by code.
+	ruleIndex := map[string]int{}
+	ruleNames := map[string]string{}
+	codes := []string{}
+	for _, diag := range diags {
+		code := diag.DisplayCode()
+		if _, ok := ruleNames[code]; !ok {
+			ruleNames[code] = codeName(diag)
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	rules := make([]*sarifRule, 0, len(codes))
+	for i, code := range codes {
+		ruleIndex[code] = i
+		rules = append(rules, &sarifRule{ID: code, Name: ruleNames[code]})
+	}
+
+	results := make([]*sarifResult, 0, len(diags))
+	for _, diag := range diags {
+		result := &sarifResult{
+			RuleID:    diag.DisplayCode(),
+			RuleIndex: ruleIndex[diag.DisplayCode()],
+			Level:     sarifLevel(diag.Category),
+			Message:   &sarifMessage{Text: diag.Message},
+		}
+		if location := toSarifLocation(diag.File, diag.Span, options.BaseDir); location != nil {
+			result.Locations = []*sarifLocation{{PhysicalLocation: location}}
+		}
+		for i, info := range diag.RelatedInformation {
+			id := i
+			result.RelatedLocations = append(result.RelatedLocations, &sarifLocation{
+				ID:               &id,
+				PhysicalLocation: toSarifLocation(info.File, info.Span, options.BaseDir),
+				Message:          &sarifMessage{Text: info.Message},
+			})
+		}
+		results = append(results, result)
+	}
+
+	return &sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []*sarifRun{{
+			Tool: &sarifTool{Driver: &sarifDriver{
+				Name:    "ngc-go",
+				Version: options.ToolVersion,
+				Rules:   rules,
+			}},
+			Results: results,
+		}},
+	}
+}
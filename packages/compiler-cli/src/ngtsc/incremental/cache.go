@@ -0,0 +1,181 @@
+package incremental
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// DefaultCacheDir is the directory of the cache in a project.
+const DefaultCacheDir = ".ngc-go-cache"
+
+// Entry is the compilation result of a source file.
+type Entry struct {
+	Code string
+	// Map is the source map of the code, if any.
+	Map         *output.SourceMap
+	Diagnostics []*diagnostics.Diagnostic
+}
+
+// Cache stores compilation results in a directory, one file per key. Entries are never updated,
+// as their keys change with their inputs; Clear removes the stale ones along with the others.
+type Cache struct {
+	dir string
+}
+
+// NewCache creates a Cache stored in dir, which is created by the first Put.
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// cachedEntry is the JSON document stored for an Entry.
+type cachedEntry struct {
+	Code        string              `json:"code"`
+	Map         *output.SourceMap   `json:"map,omitempty"`
+	Diagnostics []*cachedDiagnostic `json:"diagnostics,omitempty"`
+}
+
+type cachedDiagnostic struct {
+	Code               diagnostics.ErrorCode `json:"code"`
+	Category           diagnostics.Category  `json:"category"`
+	Message            string                `json:"message"`
+	File               string                `json:"file,omitempty"`
+	Span               *cachedSpan           `json:"span,omitempty"`
+	RelatedInformation []*cachedRelatedInfo  `json:"relatedInformation,omitempty"`
+	FromTypeScript     bool                  `json:"fromTypeScript,omitempty"`
+}
+
+type cachedRelatedInfo struct {
+	Message string      `json:"message"`
+	File    string      `json:"file,omitempty"`
+	Span    *cachedSpan `json:"span,omitempty"`
+}
+
+// cachedSpan is a span in the file of its diagnostic, which is read again when the entry is.
+type cachedSpan struct {
+	Start cachedLocation `json:"start"`
+	End   cachedLocation `json:"end"`
+}
+
+type cachedLocation struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Col    int `json:"col"`
+}
+
+// Get returns the entry stored for a key. Entries which cannot be read are missing.
+func (c *Cache) Get(key string) (*Entry, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var cached cachedEntry
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, false
+	}
+	files := make(map[string]*util.ParseSourceFile)
+	entry := &Entry{Code: cached.Code, Map: cached.Map}
+	for _, d := range cached.Diagnostics {
+		diag := &diagnostics.Diagnostic{
+			Code:           d.Code,
+			Category:       d.Category,
+			Message:        d.Message,
+			File:           d.File,
+			Span:           d.Span.toSpan(d.File, files),
+			FromTypeScript: d.FromTypeScript,
+		}
+		for _, info := range d.RelatedInformation {
+			diag.RelatedInformation = append(diag.RelatedInformation, &diagnostics.RelatedInformation{
+				Message: info.Message,
+				File:    info.File,
+				Span:    info.Span.toSpan(info.File, files),
+			})
+		}
+		entry.Diagnostics = append(entry.Diagnostics, diag)
+	}
+	return entry, true
+}
+
+// Put stores the entry of a key. The entry is written to a temporary file first, so that
+// concurrent builds never read partial entries.
+func (c *Cache) Put(key string, entry *Entry) error {
+	cached := cachedEntry{Code: entry.Code, Map: entry.Map}
+	for _, diag := range entry.Diagnostics {
+		d := &cachedDiagnostic{
+			Code:           diag.Code,
+			Category:       diag.Category,
+			Message:        diag.Message,
+			File:           diag.File,
+			Span:           newCachedSpan(diag.Span),
+			FromTypeScript: diag.FromTypeScript,
+		}
+		for _, info := range diag.RelatedInformation {
+			d.RelatedInformation = append(d.RelatedInformation, &cachedRelatedInfo{
+				Message: info.Message,
+				File:    info.File,
+				Span:    newCachedSpan(info.Span),
+			})
+		}
+		cached.Diagnostics = append(cached.Diagnostics, d)
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// Clear removes all entries.
+func (c *Cache) Clear() error {
+	return os.RemoveAll(c.dir)
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func newCachedSpan(span *util.ParseSourceSpan) *cachedSpan {
+	if span == nil || span.Start == nil || span.End == nil {
+		return nil
+	}
+	return &cachedSpan{
+		Start: cachedLocation{Offset: span.Start.Offset, Line: span.Start.Line, Col: span.Start.Col},
+		End:   cachedLocation{Offset: span.End.Offset, Line: span.End.Line, Col: span.End.Col},
+	}
+}
+
+// toSpan restores a span in a file, reading each file once.
+func (s *cachedSpan) toSpan(fileName string, files map[string]*util.ParseSourceFile) *util.ParseSourceSpan {
+	if s == nil {
+		return nil
+	}
+	file, ok := files[fileName]
+	if !ok {
+		content, _ := os.ReadFile(fileName)
+		file = util.NewParseSourceFile(string(content), fileName)
+		files[fileName] = file
+	}
+	start := util.NewParseLocation(file, s.Start.Offset, s.Start.Line, s.Start.Col)
+	end := util.NewParseLocation(file, s.End.Offset, s.End.Line, s.End.Col)
+	return util.NewParseSourceSpan(start, end, nil, nil)
+}
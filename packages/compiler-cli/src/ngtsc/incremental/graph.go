@@ -0,0 +1,119 @@
+// Package incremental lets builds skip the source files whose inputs have not changed since an
+// earlier build, by caching their compilation results on disk.
+package incremental
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+// Graph is the import graph of the source files of a project, through relative module
+// specifiers, which is how the compiler resolves the classes a file refers to.
+type Graph struct {
+	files     map[string]*reflection.SourceFile
+	imports   map[string][]string
+	importers map[string][]string
+}
+
+// NewGraph builds the import graph of the given files. Imports of other files are ignored.
+func NewGraph(files []*reflection.SourceFile) *Graph {
+	g := &Graph{
+		files:     make(map[string]*reflection.SourceFile),
+		imports:   make(map[string][]string),
+		importers: make(map[string][]string),
+	}
+	for _, sf := range files {
+		g.files[path.Clean(sf.FileName)] = sf
+	}
+	for name, sf := range g.files {
+		for _, imp := range sf.Imports {
+			if target := g.resolve(name, imp.Module); target != "" {
+				g.imports[name] = append(g.imports[name], target)
+				g.importers[target] = append(g.importers[target], name)
+			}
+		}
+	}
+	return g
+}
+
+// resolve returns the file a module specifier refers to, like the compiler does.
+func (g *Graph) resolve(from string, specifier string) string {
+	if !strings.HasPrefix(specifier, ".") {
+		return ""
+	}
+	base := path.Join(path.Dir(from), specifier)
+	base = strings.TrimSuffix(strings.TrimSuffix(base, ".js"), ".ts")
+	for _, candidate := range []string{base + ".ts", base + "/index.ts", base + ".js", base + "/index.js"} {
+		if _, ok := g.files[candidate]; ok {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Dependencies returns the files the compilation of a file depends on, including itself: the
+// files it imports, directly or not, which declare the classes its templates use, and the
+// files importing it along with their own imports, which declare the NgModules declaring its
+// components. The names are sorted.
+func (g *Graph) Dependencies(fileName string) []string {
+	fileName = path.Clean(fileName)
+	seen := make(map[string]bool)
+	g.addImports(fileName, seen)
+	for _, importer := range g.importers[fileName] {
+		g.addImports(importer, seen)
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (g *Graph) addImports(fileName string, seen map[string]bool) {
+	if seen[fileName] {
+		return
+	}
+	seen[fileName] = true
+	for _, imported := range g.imports[fileName] {
+		g.addImports(imported, seen)
+	}
+}
+
+// Key returns the cache key of the compilation of a file: a hash of salt, which identifies the
+// compiler and its options, of the files it depends on, and of the external templates and
+// stylesheets of its components, read by readResource. Resources which cannot be read are
+// hashed as such, so that the key changes when they appear.
+func (g *Graph) Key(fileName string, salt string, readResource func(path string) (string, error)) string {
+	h := sha256.New()
+	write := func(parts ...string) {
+		for _, part := range parts {
+			// The lengths keep the boundaries between parts unambiguous.
+			fmt.Fprintf(h, "%d:%s", len(part), part)
+		}
+	}
+	write(salt)
+	for _, name := range g.Dependencies(fileName) {
+		if sf := g.files[name]; sf != nil {
+			write("file", name, sf.Text)
+		}
+	}
+	if sf := g.files[path.Clean(fileName)]; sf != nil {
+		for _, resource := range annotations.ResourceFiles(sf) {
+			content, err := readResource(resource)
+			if err != nil {
+				write("missing", resource)
+				continue
+			}
+			write("resource", resource, content)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,413 @@
+package reflection
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ExpressionKind is the kind of an Expression.
+type ExpressionKind int
+
+const (
+	// ExpressionOther is any expression which is not statically understood. Only its text is
+	// known.
+	ExpressionOther ExpressionKind = iota
+	// ExpressionString is a string literal, or a template literal without substitutions.
+	ExpressionString
+	// ExpressionNumber is a numeric literal.
+	ExpressionNumber
+	// ExpressionBoolean is `true` or `false`.
+	ExpressionBoolean
+	// ExpressionNull is `null` or `undefined`.
+	ExpressionNull
+	// ExpressionIdentifier is an identifier or a chain of property reads on one, e.g.
+	// `ChangeDetectionStrategy.OnPush`.
+	ExpressionIdentifier
+	// ExpressionArray is an array literal.
+	ExpressionArray
+	// ExpressionObject is an object literal.
+	ExpressionObject
+	// ExpressionCall is a call of an identifier or property chain, e.g. `input.required<string>()`.
+	ExpressionCall
+)
+
+// Expression is an expression of a TypeScript source, as far as it is statically understood.
+type Expression struct {
+	Kind ExpressionKind
+	// Text is the source text of the expression.
+	Text  string
+	Start int
+	End   int
+
+	// Value is the cooked value of strings, the text of numbers and booleans, and the dotted path
+	// of identifiers.
+	Value string
+
+	// Elements are the elements of arrays and the arguments of calls.
+	Elements []*Expression
+	// Properties are the properties of objects.
+	Properties []*Property
+
+	// Callee is the function called by calls.
+	Callee *Expression
+	// TypeArguments is the source text of the type arguments of calls, without the angle
+	// brackets, e.g. `string` for `input<string>()`.
+	TypeArguments string
+}
+
+// Property is a property of an object literal.
+type Property struct {
+	// Name is the name of the property, or empty for spread elements and computed names.
+	Name  string
+	Value *Expression
+	// Shorthand is set for `{name}`.
+	Shorthand bool
+	Start     int
+	End       int
+}
+
+// Property returns the value of the property with the given name of an object literal, or nil.
+func (e *Expression) Property(name string) *Expression {
+	if e == nil || e.Kind != ExpressionObject {
+		return nil
+	}
+	for _, prop := range e.Properties {
+		if prop.Name == name {
+			return prop.Value
+		}
+	}
+	return nil
+}
+
+// StringValue returns the value of a string literal.
+func (e *Expression) StringValue() (string, bool) {
+	if e == nil || e.Kind != ExpressionString {
+		return "", false
+	}
+	return e.Value, true
+}
+
+// BoolValue returns the value of a boolean literal.
+func (e *Expression) BoolValue() (bool, bool) {
+	if e == nil || e.Kind != ExpressionBoolean {
+		return false, false
+	}
+	return e.Value == "true", true
+}
+
+// ParseExpression parses a standalone expression, e.g. a decorator argument taken from a
+// larger source. Offsets are relative to text.
+func ParseExpression(text string) *Expression {
+	p := &expressionParser{text: text, tokens: Tokenize(text)}
+	expr, _ := p.parse(0)
+	return expr
+}
+
+type expressionParser struct {
+	text   string
+	tokens []Token
+}
+
+// parse parses the expression starting at token i and returns it together with the index of the
+// token following it: a `,`, a closing bracket, a `;`, or EOF.
+func (p *expressionParser) parse(i int) (*Expression, int) {
+	end := p.skipExpression(i)
+	return p.classify(i, end), end
+}
+
+// skipExpression returns the index of the first token after the expression starting at i.
+func (p *expressionParser) skipExpression(i int) int {
+	depth := 0
+	for ; i < len(p.tokens); i++ {
+		tok := p.tokens[i]
+		if tok.Kind == TokenEOF {
+			return i
+		}
+		if tok.Kind != TokenPunctuation {
+			continue
+		}
+		switch tok.Text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case ",", ";":
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return i
+}
+
+// matching returns the index of the bracket closing the one at i, or the index of EOF.
+func (p *expressionParser) matching(i int) int {
+	depth := 0
+	for ; i < len(p.tokens); i++ {
+		tok := p.tokens[i]
+		if tok.Kind == TokenEOF {
+			return i
+		}
+		if tok.Kind != TokenPunctuation {
+			continue
+		}
+		switch tok.Text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return i
+}
+
+// classify builds the expression made of tokens [start, end).
+func (p *expressionParser) classify(start, end int) *Expression {
+	expr := &Expression{Kind: ExpressionOther}
+	if start >= end {
+		pos := p.tokens[start].Start
+		expr.Start, expr.End = pos, pos
+		return expr
+	}
+	expr.Start = p.tokens[start].Start
+	expr.End = p.tokens[end-1].End
+	expr.Text = p.text[expr.Start:expr.End]
+
+	first := p.tokens[start]
+	if end-start == 1 {
+		switch first.Kind {
+		case TokenString:
+			expr.Kind, expr.Value = ExpressionString, CookString(first.Text)
+		case TokenTemplate:
+			if !strings.Contains(first.Text, "${") {
+				expr.Kind, expr.Value = ExpressionString, CookString(first.Text)
+			}
+		case TokenNumber:
+			expr.Kind, expr.Value = ExpressionNumber, first.Text
+		case TokenIdentifier:
+			switch first.Text {
+			case "true", "false":
+				expr.Kind, expr.Value = ExpressionBoolean, first.Text
+			case "null", "undefined":
+				expr.Kind, expr.Value = ExpressionNull, first.Text
+			default:
+				expr.Kind, expr.Value = ExpressionIdentifier, first.Text
+			}
+		}
+		return expr
+	}
+
+	if first.Is("[") && p.matching(start) == end-1 {
+		expr.Kind = ExpressionArray
+		expr.Elements = p.parseList(start+1, end-1)
+		return expr
+	}
+	if first.Is("{") && p.matching(start) == end-1 {
+		expr.Kind = ExpressionObject
+		expr.Properties = p.parseProperties(start+1, end-1)
+		return expr
+	}
+	if first.Is("-") && end-start == 2 && p.tokens[start+1].Kind == TokenNumber {
+		expr.Kind, expr.Value = ExpressionNumber, expr.Text
+		return expr
+	}
+
+	// Identifiers, property chains and calls of them.
+	i, path := p.parsePath(start, end)
+	if i == start {
+		return expr
+	}
+	if i == end {
+		expr.Kind, expr.Value = ExpressionIdentifier, path
+		return expr
+	}
+	callee := p.classify(start, i)
+	if p.tokens[i].Is("<") {
+		closing := p.matchingAngle(i, end)
+		if closing < 0 {
+			return expr
+		}
+		expr.TypeArguments = strings.TrimSpace(p.text[p.tokens[i].End:p.tokens[closing].Start])
+		i = closing + 1
+	}
+	if i < end && p.tokens[i].Is("(") && p.matching(i) == end-1 {
+		expr.Kind = ExpressionCall
+		expr.Callee = callee
+		expr.Elements = p.parseList(i+1, end-1)
+		return expr
+	}
+	expr.TypeArguments = ""
+	return expr
+}
+
+// parsePath consumes `a.b.c` starting at start and returns the index after it with its path.
+func (p *expressionParser) parsePath(start, end int) (int, string) {
+	if p.tokens[start].Kind != TokenIdentifier {
+		return start, ""
+	}
+	path := p.tokens[start].Text
+	i := start + 1
+	for i+1 < end && p.tokens[i].Is(".") && p.tokens[i+1].Kind == TokenIdentifier {
+		path += "." + p.tokens[i+1].Text
+		i += 2
+	}
+	return i, path
+}
+
+// matchingAngle returns the index of the `>` closing type arguments at i, or -1.
+func (p *expressionParser) matchingAngle(i, end int) int {
+	depth := 0
+	for ; i < end; i++ {
+		switch p.tokens[i].Text {
+		case "<":
+			depth++
+		case ">":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case ">>":
+			depth -= 2
+			if depth <= 0 {
+				return i
+			}
+		case "(", ")", ";", "{", "}":
+			return -1
+		}
+	}
+	return -1
+}
+
+// parseList parses comma separated expressions in tokens [start, end).
+func (p *expressionParser) parseList(start, end int) []*Expression {
+	var list []*Expression
+	for i := start; i < end; {
+		next := p.skipExpression(i)
+		if next > end {
+			next = end
+		}
+		if next > i {
+			list = append(list, p.classify(i, next))
+		}
+		i = next + 1
+	}
+	return list
+}
+
+// parseProperties parses the properties of an object literal in tokens [start, end).
+func (p *expressionParser) parseProperties(start, end int) []*Property {
+	var props []*Property
+	for i := start; i < end; {
+		next := p.skipExpression(i)
+		if next > end {
+			next = end
+		}
+		if next > i {
+			props = append(props, p.parseProperty(i, next))
+		}
+		i = next + 1
+	}
+	return props
+}
+
+func (p *expressionParser) parseProperty(start, end int) *Property {
+	prop := &Property{Start: p.tokens[start].Start, End: p.tokens[end-1].End}
+	key := p.tokens[start]
+	var name string
+	switch key.Kind {
+	case TokenIdentifier, TokenNumber:
+		name = key.Text
+	case TokenString:
+		name = CookString(key.Text)
+	}
+	switch {
+	case name != "" && start+1 < end && p.tokens[start+1].Is(":"):
+		prop.Name = name
+		prop.Value = p.classify(start+2, end)
+	case name != "" && start+1 == end:
+		prop.Name = name
+		prop.Shorthand = true
+		prop.Value = p.classify(start, end)
+	default:
+		// Spread elements, methods, accessors and computed names are only known by their text.
+		prop.Value = p.classify(start, end)
+		prop.Value.Kind = ExpressionOther
+	}
+	return prop
+}
+
+// CookString returns the value of a string or template literal given its source text,
+// including the quotes.
+func CookString(literal string) string {
+	if len(literal) < 2 {
+		return ""
+	}
+	body := literal[1 : len(literal)-1]
+	if !strings.Contains(body, "\\") {
+		return body
+	}
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c != '\\' || i+1 == len(body) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch e := body[i]; e {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'v':
+			b.WriteByte('\v')
+		case '0':
+			b.WriteByte(0)
+		case '\n':
+			// Line continuation.
+		case '\r':
+			if i+1 < len(body) && body[i+1] == '\n' {
+				i++
+			}
+		case 'x':
+			if i+2 < len(body) {
+				if v, err := strconv.ParseUint(body[i+1:i+3], 16, 8); err == nil {
+					b.WriteRune(rune(v))
+					i += 2
+					continue
+				}
+			}
+			b.WriteByte(e)
+		case 'u':
+			hex := ""
+			if i+1 < len(body) && body[i+1] == '{' {
+				if closing := strings.IndexByte(body[i:], '}'); closing > 0 {
+					hex = body[i+2 : i+closing]
+					i += closing
+				}
+			} else if i+4 < len(body) {
+				hex = body[i+1 : i+5]
+				i += 4
+			}
+			if v, err := strconv.ParseUint(hex, 16, 32); err == nil && utf8.ValidRune(rune(v)) {
+				b.WriteRune(rune(v))
+			}
+		default:
+			b.WriteByte(e)
+		}
+	}
+	return b.String()
+}
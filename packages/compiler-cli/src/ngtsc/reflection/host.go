@@ -0,0 +1,1008 @@
+package reflection
+
+import (
+	"strings"
+)
+
+// SourceFile is the reflected content of a TypeScript source.
+type SourceFile struct {
+	FileName string
+	Text     string
+	Imports  []*Import
+	Classes  []*ClassDeclaration
+	// Exports are the names of the exported top-level variables, functions and enums. Exported
+	// classes are found in Classes.
+	Exports []string
+	// Statements are the top-level declarations other than imports and classes which declaration
+	// files need, in source order.
+	Statements []*Statement
+}
+
+// Import is an import declaration. Side-effect imports have no names.
+type Import struct {
+	// Module is the module specifier, e.g. `@angular/core` or `./foo`.
+	Module string
+	// DefaultName is the local name of the default import, if any.
+	DefaultName string
+	// NamespaceName is the local name of a namespace import (`* as ns`), if any.
+	NamespaceName string
+	Specifiers    []ImportSpecifier
+	// TypeOnly is set for `import type` declarations, which don't exist at runtime.
+	TypeOnly   bool
+	Start, End int
+}
+
+// ImportSpecifier is a named import, e.g. `Foo as Bar`.
+type ImportSpecifier struct {
+	// Name is the local name.
+	Name string
+	// PropertyName is the name exported by the module, which differs from Name for renamed
+	// imports.
+	PropertyName string
+	TypeOnly     bool
+}
+
+// Decorator is a decorator of a class, member or constructor parameter.
+type Decorator struct {
+	// Name is the decorator expression, e.g. `Component` or `core.Component`.
+	Name string
+	// Args are the arguments of the decorator call. They are nil for decorators which are not
+	// called, e.g. `@Foo`.
+	Args []*Expression
+	// Start and End delimit the decorator in the source, from `@` up to its closing parenthesis.
+	Start, End int
+}
+
+// ClassDeclaration is a top-level class of a source file.
+type ClassDeclaration struct {
+	Name     string
+	Exported bool
+	Default  bool
+	Abstract bool
+
+	Decorators []*Decorator
+	Members    []*ClassMember
+	// Constructor is nil when the class doesn't declare a constructor.
+	Constructor *Constructor
+
+	// Extends is the text of the `extends` clause expression, empty without one.
+	Extends string
+	// Implements is the text of the types of the `implements` clause, empty without one.
+	Implements string
+	// TypeParameters is the text between the angle brackets of the class type parameters.
+	TypeParameters string
+
+	// Start is the offset of the first decorator or modifier of the class.
+	Start int
+	// KeywordStart is the offset of the `class` keyword.
+	KeywordStart int
+	// BodyStart is the offset of the `{` opening the class body, End the offset after the
+	// closing `}`.
+	BodyStart int
+	End       int
+}
+
+// ClassMemberKind is the kind of a class member.
+type ClassMemberKind int
+
+const (
+	// MemberKindProperty is a property declaration.
+	MemberKindProperty ClassMemberKind = iota
+	// MemberKindMethod is a method declaration.
+	MemberKindMethod
+	// MemberKindGetter is a `get` accessor.
+	MemberKindGetter
+	// MemberKindSetter is a `set` accessor.
+	MemberKindSetter
+)
+
+// ClassMember is a member of a class other than its constructor.
+type ClassMember struct {
+	Name       string
+	Kind       ClassMemberKind
+	Decorators []*Decorator
+	// Modifiers are the keywords preceding the name, e.g. `private` and `readonly`.
+	Modifiers []string
+	IsStatic  bool
+	// Optional is set for members declared with a question mark, e.g. `label?: string`.
+	Optional bool
+	// Type is the text of the type annotation of properties and the parameter of setters.
+	Type string
+	// Initializer is the initializer of properties, nil without one.
+	Initializer *Expression
+	// TypeParameters, Parameters and ReturnType are the signature of methods and accessors.
+	// ReturnType is empty without an annotation.
+	TypeParameters string
+	Parameters     []*CtorParameter
+	ReturnType     string
+	// HasBody is set for methods and accessors with a body, as opposed to overload signatures.
+	HasBody    bool
+	Start, End int
+}
+
+// Constructor is the constructor of a class.
+type Constructor struct {
+	Parameters []*CtorParameter
+	Start, End int
+}
+
+// CtorParameter is a parameter of a constructor, or of another function.
+type CtorParameter struct {
+	// Name is empty for destructuring parameters.
+	Name       string
+	Decorators []*Decorator
+	// Modifiers are the keywords of parameter properties, e.g. `private`.
+	Modifiers []string
+	// Rest is set for rest parameters, e.g. `...args`.
+	Rest bool
+	// Type is the text of the type annotation, empty without one.
+	Type     string
+	Optional bool
+	// Initializer is the default value, nil without one.
+	Initializer *Expression
+}
+
+// StatementKind is the kind of a top-level Statement.
+type StatementKind int
+
+const (
+	// StatementVariable is a `const`, `let` or `var` declaration. Only its first declarator is
+	// read.
+	StatementVariable StatementKind = iota
+	// StatementFunction is a function declaration or overload signature.
+	StatementFunction
+	// StatementEnum is an enum declaration, including `const enum`.
+	StatementEnum
+	// StatementInterface is an interface declaration.
+	StatementInterface
+	// StatementTypeAlias is a type alias declaration.
+	StatementTypeAlias
+	// StatementExport is an export declaration which doesn't declare anything, e.g.
+	// `export * from './foo';` or `export { a as b };`.
+	StatementExport
+)
+
+// Statement is a top-level declaration other than an import or a class.
+type Statement struct {
+	Kind StatementKind
+	// Name is the declared name. It is empty for StatementExport.
+	Name     string
+	Exported bool
+	// Declare is set for ambient declarations, e.g. `declare const ngDevMode: boolean;`.
+	Declare bool
+	// Keyword is the keyword of variables (`const`, `let` or `var`) and enums (`enum` or
+	// `const enum`).
+	Keyword string
+
+	// Type and Initializer are the type annotation and the initializer of variables.
+	Type        string
+	Initializer *Expression
+
+	// TypeParameters, Parameters and ReturnType are the signature of functions. ReturnType is
+	// empty without an annotation.
+	TypeParameters string
+	Parameters     []*CtorParameter
+	ReturnType     string
+	Async          bool
+	// HasBody is set for functions with a body, as opposed to overload signatures.
+	HasBody bool
+
+	// Start is the offset of the first modifier of the statement, KeywordStart the offset after
+	// its modifiers, e.g. of `interface`. End is the offset after the statement.
+	Start        int
+	KeywordStart int
+	End          int
+}
+
+// TypeName returns the name of the type referenced by the annotation of the parameter, without
+// type arguments or `| null`, e.g. `HttpClient` for `HttpClient | null`. It is empty when the
+// annotation is not a plain type reference.
+func (p *CtorParameter) TypeName() string {
+	return TypeReferenceName(p.Type)
+}
+
+// TypeReferenceName returns the name of the type referenced by a type annotation, see
+// CtorParameter.TypeName.
+func TypeReferenceName(typ string) string {
+	var candidates []string
+	for _, part := range strings.Split(typ, "|") {
+		part = strings.TrimSpace(part)
+		if part != "null" && part != "undefined" && part != "" {
+			candidates = append(candidates, part)
+		}
+	}
+	if len(candidates) != 1 {
+		return ""
+	}
+	name := candidates[0]
+	if i := strings.IndexByte(name, '<'); i >= 0 {
+		name = strings.TrimSpace(name[:i])
+	}
+	for _, segment := range strings.Split(name, ".") {
+		if segment == "" || !isIdentifierStart(rune(segment[0])) {
+			return ""
+		}
+		for _, r := range segment {
+			if !isIdentifierPart(r) {
+				return ""
+			}
+		}
+	}
+	return name
+}
+
+// ImportOf returns the import declaring a local name, along with the name it has in the
+// imported module: `default` for default imports and `*` for namespace imports.
+func (sf *SourceFile) ImportOf(localName string) (*Import, string, bool) {
+	for _, imp := range sf.Imports {
+		if imp.DefaultName == localName {
+			return imp, "default", true
+		}
+		if imp.NamespaceName == localName {
+			return imp, "*", true
+		}
+		for _, spec := range imp.Specifiers {
+			if spec.Name == localName {
+				return imp, spec.PropertyName, true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// Class returns the top-level class with the given name, or nil.
+func (sf *SourceFile) Class(name string) *ClassDeclaration {
+	for _, class := range sf.Classes {
+		if class.Name == name {
+			return class
+		}
+	}
+	return nil
+}
+
+// IsExported reports whether the source file exports a top-level value with the given name.
+func (sf *SourceFile) IsExported(name string) bool {
+	for _, exported := range sf.Exports {
+		if exported == name {
+			return true
+		}
+	}
+	class := sf.Class(name)
+	return class != nil && class.Exported
+}
+
+// Decorator returns the decorator of the class with the given name, or nil.
+func (c *ClassDeclaration) Decorator(name string) *Decorator {
+	for _, dec := range c.Decorators {
+		if dec.Name == name {
+			return dec
+		}
+	}
+	return nil
+}
+
+// ReflectSourceFile reads the imports and top-level classes of a TypeScript source.
+func ReflectSourceFile(fileName string, text string) *SourceFile {
+	r := &reflector{
+		expressionParser: expressionParser{text: text, tokens: Tokenize(text)},
+		sf:               &SourceFile{FileName: fileName, Text: text},
+	}
+	r.reflectStatements()
+	return r.sf
+}
+
+type reflector struct {
+	expressionParser
+	sf *SourceFile
+}
+
+func (r *reflector) tok(i int) Token {
+	if i >= len(r.tokens) {
+		return r.tokens[len(r.tokens)-1]
+	}
+	return r.tokens[i]
+}
+
+func (r *reflector) reflectStatements() {
+	var decorators []*Decorator
+	start := -1
+	for i := 0; r.tok(i).Kind != TokenEOF; {
+		tok := r.tok(i)
+		switch {
+		case tok.Is("import") && !r.tok(i+1).Is("(") && !r.tok(i+1).Is(".") && len(decorators) == 0:
+			i = r.reflectImport(i)
+			start = -1
+		case tok.Is("@"):
+			if start < 0 {
+				start = tok.Start
+			}
+			var dec *Decorator
+			dec, i = r.reflectDecorator(i)
+			decorators = append(decorators, dec)
+		case tok.Is("export") || tok.Is("default") || tok.Is("abstract") || tok.Is("declare"):
+			if tok.Is("export") {
+				if name := r.exportedName(i + 1); name != "" {
+					r.sf.Exports = append(r.sf.Exports, name)
+				}
+			}
+			if start < 0 {
+				start = tok.Start
+			}
+			i++
+		case tok.Is("class") && (start >= 0 || r.statementStart(i)):
+			if start < 0 {
+				start = tok.Start
+			}
+			var class *ClassDeclaration
+			class, i = r.reflectClass(i, start, decorators)
+			r.sf.Classes = append(r.sf.Classes, class)
+			decorators, start = nil, -1
+		case len(decorators) == 0 && (start >= 0 || r.topLevelStatementStart(i)) && r.declarationStart(i, start):
+			var stmt *Statement
+			stmt, i = r.reflectStatement(i, start)
+			if stmt != nil {
+				r.sf.Statements = append(r.sf.Statements, stmt)
+			}
+			decorators, start = nil, -1
+		case tok.Is("(") || tok.Is("[") || tok.Is("{"):
+			i = r.matching(i) + 1
+			decorators, start = nil, -1
+		default:
+			i++
+			decorators, start = nil, -1
+		}
+	}
+}
+
+// exportedName returns the name declared by the variable, function or enum declaration at i,
+// which follows an `export` keyword.
+func (r *reflector) exportedName(i int) string {
+	if r.tok(i).Is("declare") || r.tok(i).Is("async") {
+		i++
+	}
+	switch {
+	case r.tok(i).Is("const") && r.tok(i+1).Is("enum"):
+		i += 2
+	case r.tok(i).Is("const") || r.tok(i).Is("let") || r.tok(i).Is("var") || r.tok(i).Is("enum"):
+		i++
+	case r.tok(i).Is("function"):
+		i++
+		if r.tok(i).Is("*") {
+			i++
+		}
+	default:
+		return ""
+	}
+	if r.tok(i).Kind != TokenIdentifier {
+		return ""
+	}
+	return r.tok(i).Text
+}
+
+// statementStart reports whether the token at i starts a statement, so that `class` is a class
+// declaration rather than e.g. a class expression.
+func (r *reflector) statementStart(i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := r.tok(i - 1)
+	return prev.Is(";") || prev.Is("}") || prev.Is(")") && r.tok(i).NewLineBefore
+}
+
+// topLevelStatementStart is like statementStart, but also allows statements which are only
+// separated from the previous one by a line break.
+func (r *reflector) topLevelStatementStart(i int) bool {
+	if r.statementStart(i) {
+		return true
+	}
+	prev := r.tok(i - 1)
+	return r.tok(i).NewLineBefore && (prev.Kind != TokenPunctuation || prev.Is(")") || prev.Is("]"))
+}
+
+// declarationStart reports whether the token at i starts a Statement. start is the offset of the
+// modifiers preceding it, or -1.
+func (r *reflector) declarationStart(i int, start int) bool {
+	tok, next := r.tok(i), r.tok(i+1)
+	switch {
+	case tok.Is("type") && (next.Is("{") || next.Is("*")):
+		// `export type { Foo } from './foo';`
+		return start >= 0 && r.tok(i-1).Is("export")
+	case tok.Is("interface") || tok.Is("enum") || tok.Is("type"):
+		return next.Kind == TokenIdentifier
+	case tok.Is("const"):
+		return next.Kind == TokenIdentifier || next.Is("{") || next.Is("[")
+	case tok.Is("let") || tok.Is("var"):
+		return next.Kind == TokenIdentifier
+	case tok.Is("function"):
+		return true
+	case tok.Is("async"):
+		return next.Is("function") && !next.NewLineBefore
+	case tok.Is("*") || tok.Is("{"):
+		// Re-exports and export lists.
+		return start >= 0 && r.tok(i-1).Is("export")
+	}
+	return false
+}
+
+// reflectStatement reads the declaration at i, whose modifiers start at start (or -1), and
+// returns the index after it. It returns a nil statement for default exports.
+func (r *reflector) reflectStatement(i int, start int) (*Statement, int) {
+	stmt := &Statement{Start: start, KeywordStart: r.tok(i).Start}
+	if start < 0 {
+		stmt.Start = stmt.KeywordStart
+	}
+	for j := i - 1; j >= 0 && r.tok(j).Start >= stmt.Start; j-- {
+		switch r.tok(j).Text {
+		case "export":
+			stmt.Exported = true
+		case "declare":
+			stmt.Declare = true
+		case "default":
+			return nil, i + 1
+		}
+	}
+
+	tok := r.tok(i)
+	switch {
+	case tok.Is("*") || tok.Is("{") || tok.Is("type") && (r.tok(i+1).Is("{") || r.tok(i+1).Is("*")):
+		stmt.Kind = StatementExport
+		if tok.Is("type") {
+			i++
+		}
+		if r.tok(i).Is("{") {
+			i = r.matching(i) + 1
+		} else if i++; r.tok(i).Is("as") {
+			i += 2
+		}
+		if r.tok(i).Is("from") && r.tok(i+1).Kind == TokenString {
+			i += 2
+			if (r.tok(i).Is("with") || r.tok(i).Is("assert")) && r.tok(i+1).Is("{") && !r.tok(i).NewLineBefore {
+				i = r.matching(i+1) + 1
+			}
+		}
+	case tok.Is("interface"):
+		stmt.Kind, stmt.Name = StatementInterface, r.tok(i+1).Text
+		i += 2
+		for r.tok(i).Kind != TokenEOF && !r.tok(i).Is("{") {
+			if r.tok(i).Is("<") {
+				if closing := r.matchingAngle(i, len(r.tokens)); closing > 0 {
+					i = closing
+				}
+			}
+			i++
+		}
+		i = r.matching(i) + 1
+	case tok.Is("type"):
+		stmt.Kind, stmt.Name = StatementTypeAlias, r.tok(i+1).Text
+		i += 2
+		if r.tok(i).Is("<") {
+			if closing := r.matchingAngle(i, len(r.tokens)); closing > 0 {
+				i = closing + 1
+			}
+		}
+		if r.tok(i).Is("=") {
+			i = r.skipType(i+1, len(r.tokens)-1)
+		}
+	case tok.Is("enum") || tok.Is("const") && r.tok(i+1).Is("enum"):
+		stmt.Kind, stmt.Keyword = StatementEnum, "enum"
+		if tok.Is("const") {
+			stmt.Keyword = "const enum"
+			i++
+		}
+		stmt.Name = r.tok(i + 1).Text
+		i += 2
+		if r.tok(i).Is("{") {
+			i = r.matching(i) + 1
+		}
+	case tok.Is("const") || tok.Is("let") || tok.Is("var"):
+		stmt.Kind, stmt.Keyword = StatementVariable, tok.Text
+		i++
+		if r.tok(i).Kind == TokenIdentifier {
+			stmt.Name = r.tok(i).Text
+			i++
+		} else {
+			// Destructuring declarations are not understood.
+			i = r.matching(i) + 1
+		}
+		if r.tok(i).Is("!") {
+			i++
+		}
+		if r.tok(i).Is(":") {
+			typeEnd := r.skipType(i+1, len(r.tokens)-1)
+			stmt.Type = strings.TrimSpace(r.text[r.tok(i).End:r.tok(typeEnd).Start])
+			i = typeEnd
+		}
+		if r.tok(i).Is("=") {
+			valueEnd := r.skipInitializer(i+1, len(r.tokens)-1)
+			stmt.Initializer = r.classify(i+1, valueEnd)
+			i = valueEnd
+		}
+	default:
+		stmt.Kind = StatementFunction
+		if tok.Is("async") {
+			stmt.Async = true
+			i++
+		}
+		i++
+		if r.tok(i).Is("*") {
+			i++
+		}
+		stmt.Name = r.tok(i).Text
+		i++
+		if r.tok(i).Is("<") {
+			if closing := r.matchingAngle(i, len(r.tokens)); closing > 0 {
+				stmt.TypeParameters = strings.TrimSpace(r.text[r.tok(i).End:r.tok(closing).Start])
+				i = closing + 1
+			}
+		}
+		if !r.tok(i).Is("(") {
+			return nil, i
+		}
+		paramsEnd := r.matching(i)
+		stmt.Parameters = r.reflectParameters(i+1, paramsEnd)
+		i = paramsEnd + 1
+		returnTypeStart := -1
+		if r.tok(i).Is(":") {
+			returnTypeStart = r.tok(i).End
+		}
+		for r.tok(i).Kind != TokenEOF && !r.tok(i).Is("{") && !r.tok(i).Is(";") {
+			if r.tok(i).Is("(") || r.tok(i).Is("[") {
+				i = r.matching(i)
+			}
+			i++
+			if r.tok(i).NewLineBefore && !r.tok(i).Is("{") && r.tok(i-1).Kind != TokenPunctuation {
+				break
+			}
+		}
+		if returnTypeStart >= 0 {
+			stmt.ReturnType = strings.TrimSpace(r.text[returnTypeStart:r.tok(i).Start])
+		}
+		if r.tok(i).Is("{") {
+			stmt.HasBody = true
+			i = r.matching(i) + 1
+		}
+	}
+	if r.tok(i).Is(";") {
+		i++
+	}
+	stmt.End = r.tok(i - 1).End
+	return stmt, i
+}
+
+// reflectImport reads the import declaration at i and returns the index after it.
+func (r *reflector) reflectImport(i int) int {
+	imp := &Import{Start: r.tok(i).Start}
+	i++
+	if r.tok(i).Is("type") && !r.tok(i+1).Is("from") && !r.tok(i+1).Is(",") {
+		imp.TypeOnly = true
+		i++
+	}
+	for r.tok(i).Kind != TokenEOF && r.tok(i).Kind != TokenString {
+		tok := r.tok(i)
+		switch {
+		case tok.Is("*") && r.tok(i+1).Is("as"):
+			imp.NamespaceName = r.tok(i + 2).Text
+			i += 3
+		case tok.Is("{"):
+			end := r.matching(i)
+			imp.Specifiers = r.reflectImportSpecifiers(i+1, end)
+			i = end + 1
+		case tok.Is("from") || tok.Is(","):
+			i++
+		case tok.Kind == TokenIdentifier:
+			imp.DefaultName = tok.Text
+			i++
+		default:
+			// Not an import declaration we understand.
+			return i + 1
+		}
+	}
+	if r.tok(i).Kind == TokenString {
+		imp.Module = CookString(r.tok(i).Text)
+		imp.End = r.tok(i).End
+		i++
+	}
+	// Import attributes, e.g. `with { type: 'json' }`.
+	if (r.tok(i).Is("with") || r.tok(i).Is("assert")) && r.tok(i+1).Is("{") && !r.tok(i).NewLineBefore {
+		i = r.matching(i+1) + 1
+		imp.End = r.tok(i - 1).End
+	}
+	if r.tok(i).Is(";") {
+		imp.End = r.tok(i).End
+		i++
+	}
+	r.sf.Imports = append(r.sf.Imports, imp)
+	return i
+}
+
+func (r *reflector) reflectImportSpecifiers(start, end int) []ImportSpecifier {
+	var specs []ImportSpecifier
+	for i := start; i < end; {
+		spec := ImportSpecifier{}
+		if r.tok(i).Is("type") && r.tok(i+1).Kind == TokenIdentifier && !r.tok(i+1).Is("as") {
+			spec.TypeOnly = true
+			i++
+		}
+		name := r.tok(i).Text
+		if r.tok(i).Kind == TokenString {
+			name = CookString(name)
+		}
+		spec.Name, spec.PropertyName = name, name
+		i++
+		if r.tok(i).Is("as") {
+			spec.Name = r.tok(i + 1).Text
+			i += 2
+		}
+		if spec.Name != "" {
+			specs = append(specs, spec)
+		}
+		for i < end && !r.tok(i).Is(",") {
+			i++
+		}
+		i++
+	}
+	return specs
+}
+
+// reflectDecorator reads the decorator at i (the `@` token) and returns the index after it.
+func (r *reflector) reflectDecorator(i int) (*Decorator, int) {
+	dec := &Decorator{Start: r.tok(i).Start, End: r.tok(i).End}
+	i++
+	if r.tok(i).Is("(") {
+		// `@(expression)` decorators are not understood.
+		end := r.matching(i)
+		dec.End = r.tok(end).End
+		return dec, end + 1
+	}
+	next, path := r.parsePath(i, len(r.tokens))
+	dec.Name = path
+	if next > i {
+		dec.End = r.tok(next - 1).End
+	}
+	i = next
+	if r.tok(i).Is("(") {
+		end := r.matching(i)
+		dec.Args = r.parseList(i+1, end)
+		if dec.Args == nil {
+			dec.Args = []*Expression{}
+		}
+		dec.End = r.tok(end).End
+		i = end + 1
+	}
+	return dec, i
+}
+
+// reflectClass reads the class declaration whose `class` keyword is at i.
+func (r *reflector) reflectClass(i int, start int, decorators []*Decorator) (*ClassDeclaration, int) {
+	class := &ClassDeclaration{Start: start, KeywordStart: r.tok(i).Start, Decorators: decorators}
+	for j := i - 1; j >= 0 && r.tok(j).Start >= start; j-- {
+		switch r.tok(j).Text {
+		case "export":
+			class.Exported = true
+		case "default":
+			class.Default = true
+		case "abstract":
+			class.Abstract = true
+		}
+	}
+	i++
+	if tok := r.tok(i); tok.Kind == TokenIdentifier && tok.Text != "extends" && tok.Text != "implements" {
+		class.Name = tok.Text
+		i++
+	}
+	if r.tok(i).Is("<") {
+		end := r.matchingAngle(i, len(r.tokens))
+		if end < 0 {
+			end = i
+		}
+		class.TypeParameters = strings.TrimSpace(r.text[r.tok(i).End:r.tok(end).Start])
+		i = end + 1
+	}
+	for r.tok(i).Kind != TokenEOF && !r.tok(i).Is("{") {
+		if r.tok(i).Is("extends") {
+			j := r.heritageClauseEnd(i + 1)
+			class.Extends = strings.TrimSpace(r.text[r.tok(i).End:r.tok(j).Start])
+			i = j
+			continue
+		}
+		if r.tok(i).Is("implements") {
+			j := r.heritageClauseEnd(i + 1)
+			class.Implements = strings.TrimSpace(r.text[r.tok(i).End:r.tok(j).Start])
+			i = j
+			continue
+		}
+		i++
+	}
+	class.BodyStart = r.tok(i).Start
+	end := r.matching(i)
+	class.End = r.tok(end).End
+	r.reflectMembers(class, i+1, end)
+	return class, end + 1
+}
+
+// heritageClauseEnd returns the index of the token ending the `extends` or `implements` clause
+// whose types start at i: the `{` of the class body or the keyword of the next clause. Brackets
+// are skipped, as well as the type arguments, which can contain type literals.
+func (r *reflector) heritageClauseEnd(i int) int {
+	depth := 0
+	for ; r.tok(i).Kind != TokenEOF; i++ {
+		tok := r.tok(i)
+		switch {
+		case depth == 0 && (tok.Is("{") || tok.Is("implements")):
+			return i
+		case tok.Is("(") || tok.Is("[") || tok.Is("{"):
+			i = r.matching(i)
+		case tok.Is("<"):
+			depth++
+		case tok.Is(">"):
+			depth--
+		case tok.Is(">>"):
+			depth -= 2
+		}
+	}
+	return i
+}
+
+var memberModifiers = map[string]bool{
+	"public": true, "private": true, "protected": true, "readonly": true, "static": true,
+	"override": true, "declare": true, "abstract": true, "accessor": true, "async": true,
+}
+
+// isMemberName reports whether the token at i can be the name of a member, so that a keyword
+// before it is a modifier.
+func (r *reflector) isMemberName(i int) bool {
+	tok := r.tok(i)
+	return tok.Kind == TokenIdentifier || tok.Kind == TokenString || tok.Kind == TokenNumber ||
+		tok.Is("[") || tok.Is("*")
+}
+
+func (r *reflector) reflectMembers(class *ClassDeclaration, start, end int) {
+	for i := start; i < end; {
+		if r.tok(i).Is(";") {
+			i++
+			continue
+		}
+		member := &ClassMember{Start: r.tok(i).Start}
+		for r.tok(i).Is("@") {
+			var dec *Decorator
+			dec, i = r.reflectDecorator(i)
+			member.Decorators = append(member.Decorators, dec)
+		}
+		kind := MemberKindProperty
+		for i < end {
+			tok := r.tok(i)
+			if tok.Is("static") && r.tok(i+1).Is("{") {
+				// Static initialization block.
+				i = r.matching(i+1) + 1
+				member = nil
+				break
+			}
+			if memberModifiers[tok.Text] && tok.Kind == TokenIdentifier && r.isMemberName(i+1) {
+				if tok.Text == "static" {
+					member.IsStatic = true
+				}
+				member.Modifiers = append(member.Modifiers, tok.Text)
+				i++
+				continue
+			}
+			if (tok.Is("get") || tok.Is("set")) && r.isMemberName(i+1) && !r.tok(i+1).Is("*") {
+				kind = MemberKindGetter
+				if tok.Is("set") {
+					kind = MemberKindSetter
+				}
+				i++
+				continue
+			}
+			if tok.Is("*") {
+				i++
+				continue
+			}
+			break
+		}
+		if member == nil {
+			continue
+		}
+
+		name := r.tok(i)
+		switch {
+		case name.Is("["):
+			i = r.matching(i) + 1
+		case name.Kind == TokenString:
+			member.Name = CookString(name.Text)
+			i++
+		default:
+			member.Name = name.Text
+			i++
+		}
+		if r.tok(i).Is("?") || r.tok(i).Is("!") {
+			member.Optional = r.tok(i).Is("?")
+			i++
+		}
+
+		if r.tok(i).Is("(") || r.tok(i).Is("<") {
+			if r.tok(i).Is("<") {
+				if closing := r.matchingAngle(i, end); closing > 0 {
+					member.TypeParameters = strings.TrimSpace(r.text[r.tok(i).End:r.tok(closing).Start])
+					i = closing + 1
+				}
+			}
+			paramsEnd := r.matching(i)
+			member.Parameters = r.reflectParameters(i+1, paramsEnd)
+			if member.Name == "constructor" && kind == MemberKindProperty {
+				class.Constructor = &Constructor{Parameters: member.Parameters, Start: member.Start}
+			} else if kind == MemberKindSetter && len(member.Parameters) > 0 {
+				member.Type = member.Parameters[0].Type
+			}
+			i = paramsEnd + 1
+			// Return type annotation, up to the body or the end of an overload signature.
+			returnTypeStart := -1
+			if r.tok(i).Is(":") {
+				returnTypeStart = r.tok(i).End
+			}
+			for i < end && !r.tok(i).Is("{") && !r.tok(i).Is(";") {
+				if r.tok(i).Is("(") || r.tok(i).Is("[") {
+					i = r.matching(i)
+				}
+				i++
+				if r.tok(i).NewLineBefore && !r.tok(i).Is("{") && r.tok(i-1).Kind != TokenPunctuation {
+					break
+				}
+			}
+			if returnTypeStart >= 0 {
+				member.ReturnType = strings.TrimSpace(r.text[returnTypeStart:r.tok(i).Start])
+			}
+			if r.tok(i).Is("{") {
+				member.HasBody = true
+				i = r.matching(i)
+			}
+			member.End = r.tok(i).End
+			if r.tok(i).Is(";") || r.tok(i).Is("}") {
+				i++
+			}
+			if member.Name == "constructor" && kind == MemberKindProperty {
+				class.Constructor.End = member.End
+				continue
+			}
+			if kind == MemberKindProperty {
+				kind = MemberKindMethod
+			}
+			member.Kind = kind
+			class.Members = append(class.Members, member)
+			continue
+		}
+
+		member.Kind = MemberKindProperty
+		if r.tok(i).Is(":") {
+			typeEnd := r.skipType(i+1, end)
+			member.Type = strings.TrimSpace(r.text[r.tok(i).End:r.tok(typeEnd).Start])
+			i = typeEnd
+		}
+		if r.tok(i).Is("=") {
+			valueEnd := r.skipInitializer(i+1, end)
+			member.Initializer = r.classify(i+1, valueEnd)
+			i = valueEnd
+		}
+		member.End = r.tok(i - 1).End
+		if r.tok(i).Is(";") {
+			member.End = r.tok(i).End
+			i++
+		}
+		class.Members = append(class.Members, member)
+	}
+}
+
+// skipType returns the index of the first token after the type annotation starting at i: the
+// `=`, `;`, `,` or `)` following it, or the first token of the next member.
+func (r *reflector) skipType(i, end int) int {
+	angle := 0
+	for ; i < end; i++ {
+		tok := r.tok(i)
+		switch {
+		case tok.Is("(") || tok.Is("[") || tok.Is("{"):
+			i = r.matching(i)
+			continue
+		case tok.Is("<"):
+			angle++
+		case tok.Is(">"):
+			angle--
+		case tok.Is(">>"):
+			angle -= 2
+		case angle > 0:
+			continue
+		case tok.Is("=") || tok.Is(";") || tok.Is(",") || tok.Is(")"):
+			return i
+		}
+		if angle <= 0 && i+1 < end && r.tok(i+1).NewLineBefore && !continuesType(r.tok(i), r.tok(i+1)) {
+			return i + 1
+		}
+	}
+	return end
+}
+
+func continuesType(prev, next Token) bool {
+	if prev.Is("|") || prev.Is("&") || prev.Is("=>") || prev.Is(":") || prev.Is(".") {
+		return true
+	}
+	return next.Is("|") || next.Is("&") || next.Is("=>") || next.Is(".") || next.Is("[")
+}
+
+// skipInitializer returns the index of the first token after the initializer starting at i,
+// taking automatic semicolon insertion into account.
+func (r *reflector) skipInitializer(i, end int) int {
+	for ; i < end; i++ {
+		tok := r.tok(i)
+		if tok.Is(";") {
+			return i
+		}
+		if tok.Is("(") || tok.Is("[") || tok.Is("{") {
+			i = r.matching(i)
+		}
+		if i+1 < end && r.tok(i+1).NewLineBefore && !continuesExpression(r.tok(i), r.tok(i+1)) {
+			return i + 1
+		}
+	}
+	return end
+}
+
+// continuesExpression reports whether an expression goes on across a line break between prev
+// and next.
+func continuesExpression(prev, next Token) bool {
+	if prev.Kind == TokenPunctuation && !prev.Is(")") && !prev.Is("]") && !prev.Is("}") &&
+		!prev.Is("++") && !prev.Is("--") {
+		return true
+	}
+	if next.Kind == TokenPunctuation {
+		switch next.Text {
+		case "(", "[", "@", "*", "#":
+			return false
+		}
+		return !next.Is("{") && !next.Is("++") && !next.Is("--")
+	}
+	return next.Is("as") || next.Is("satisfies") || next.Is("instanceof") || next.Is("in")
+}
+
+// reflectParameters reads the parameters of a function in tokens [start, end).
+func (r *reflector) reflectParameters(start, end int) []*CtorParameter {
+	var params []*CtorParameter
+	for i := start; i < end; {
+		param := &CtorParameter{}
+		for r.tok(i).Is("@") {
+			var dec *Decorator
+			dec, i = r.reflectDecorator(i)
+			param.Decorators = append(param.Decorators, dec)
+		}
+		for memberModifiers[r.tok(i).Text] && r.tok(i+1).Kind == TokenIdentifier {
+			param.Modifiers = append(param.Modifiers, r.tok(i).Text)
+			i++
+		}
+		if r.tok(i).Is("...") {
+			param.Rest = true
+			i++
+		}
+		if r.tok(i).Is("{") || r.tok(i).Is("[") {
+			i = r.matching(i) + 1
+		} else {
+			param.Name = r.tok(i).Text
+			i++
+		}
+		if r.tok(i).Is("?") {
+			param.Optional = true
+			i++
+		}
+		if r.tok(i).Is(":") {
+			typeEnd := r.skipType(i+1, end)
+			param.Type = strings.TrimSpace(r.text[r.tok(i).End:r.tok(typeEnd).Start])
+			i = typeEnd
+		}
+		if r.tok(i).Is("=") {
+			param.Optional = true
+			param.Initializer, i = r.parse(i + 1)
+		}
+		for i < end && !r.tok(i).Is(",") {
+			i++
+		}
+		i++
+		params = append(params, param)
+	}
+	return params
+}
@@ -0,0 +1,283 @@
+// Package reflection reads the declarations the compiler needs from TypeScript sources:
+// imports, classes, their decorators, members and constructor parameters.
+//
+// It is not a TypeScript parser. Sources are tokenized and only the structure around decorated
+// classes is understood; everything else is skipped while keeping track of nesting.
+package reflection
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind is the kind of a token of a TypeScript source.
+type TokenKind int
+
+const (
+	// TokenEOF ends every token stream.
+	TokenEOF TokenKind = iota
+	// TokenIdentifier is an identifier or keyword, including private names (`#x`).
+	TokenIdentifier
+	// TokenString is a single or double quoted string literal.
+	TokenString
+	// TokenTemplate is a template literal, including its substitutions.
+	TokenTemplate
+	// TokenNumber is a numeric literal.
+	TokenNumber
+	// TokenRegExp is a regular expression literal.
+	TokenRegExp
+	// TokenPunctuation is an operator or punctuation.
+	TokenPunctuation
+)
+
+// Token is a token of a TypeScript source. Start and End are byte offsets into the source.
+type Token struct {
+	Kind  TokenKind
+	Text  string
+	Start int
+	End   int
+	// NewLineBefore is set when a line break separates the token from the previous one, which
+	// matters for automatic semicolon insertion.
+	NewLineBefore bool
+}
+
+// Is reports whether the token is the given punctuation or identifier.
+func (t Token) Is(text string) bool {
+	return (t.Kind == TokenPunctuation || t.Kind == TokenIdentifier) && t.Text == text
+}
+
+// punctuations are the multi-character punctuations, longest first.
+var punctuations = []string{
+	">>>=", "...", "===", "!==", "**=", "<<=", ">>=", "&&=", "||=", "??=",
+	"=>", "==", "!=", "<=", ">=", "&&", "||", "??", "?.", "++", "--", "+=", "-=", "*=", "/=",
+	"%=", "&=", "|=", "^=", "**", "<<",
+}
+
+// Tokenize splits a TypeScript source into tokens, skipping whitespace and comments. The last
+// token is always TokenEOF. Unterminated literals and comments extend to the end of the source.
+func Tokenize(text string) []Token {
+	s := &scanner{text: text}
+	var tokens []Token
+	for {
+		tok := s.next(tokens)
+		tokens = append(tokens, tok)
+		if tok.Kind == TokenEOF {
+			return tokens
+		}
+	}
+}
+
+type scanner struct {
+	text string
+	pos  int
+}
+
+func (s *scanner) next(previous []Token) Token {
+	newLine := s.skipTrivia()
+	start := s.pos
+	if s.pos >= len(s.text) {
+		return Token{Kind: TokenEOF, Start: start, End: start, NewLineBefore: newLine}
+	}
+	tok := Token{Start: start, NewLineBefore: newLine}
+	c := s.text[s.pos]
+	switch {
+	case c == '\'' || c == '"':
+		tok.Kind = TokenString
+		s.skipString(c)
+	case c == '`':
+		tok.Kind = TokenTemplate
+		s.skipTemplate()
+	case c >= '0' && c <= '9' || c == '.' && s.pos+1 < len(s.text) && isDigit(s.text[s.pos+1]):
+		tok.Kind = TokenNumber
+		s.pos++
+		for s.pos < len(s.text) && (isIdentifierPart(s.peekRune()) || s.text[s.pos] == '.') {
+			s.advanceRune()
+		}
+	case c == '#' || isIdentifierStart(s.peekRune()):
+		tok.Kind = TokenIdentifier
+		s.advanceRune()
+		for s.pos < len(s.text) && isIdentifierPart(s.peekRune()) {
+			s.advanceRune()
+		}
+	case c == '/' && regExpAllowed(previous):
+		tok.Kind = TokenRegExp
+		s.skipRegExp()
+	default:
+		tok.Kind = TokenPunctuation
+		s.pos++
+		for _, p := range punctuations {
+			if strings.HasPrefix(s.text[start:], p) {
+				s.pos = start + len(p)
+				break
+			}
+		}
+	}
+	tok.End = s.pos
+	tok.Text = s.text[start:s.pos]
+	return tok
+}
+
+// skipTrivia skips whitespace and comments and reports whether they contained a line break.
+func (s *scanner) skipTrivia() bool {
+	newLine := false
+	for s.pos < len(s.text) {
+		switch {
+		case s.text[s.pos] == '\n':
+			newLine = true
+			s.pos++
+		case s.text[s.pos] == ' ' || s.text[s.pos] == '\t' || s.text[s.pos] == '\r':
+			s.pos++
+		case strings.HasPrefix(s.text[s.pos:], "//"):
+			end := strings.IndexByte(s.text[s.pos:], '\n')
+			if end < 0 {
+				s.pos = len(s.text)
+			} else {
+				s.pos += end
+			}
+		case strings.HasPrefix(s.text[s.pos:], "/*"):
+			end := strings.Index(s.text[s.pos+2:], "*/")
+			if end < 0 {
+				end = len(s.text) - s.pos - 2
+			} else {
+				end += 2
+			}
+			if strings.Contains(s.text[s.pos:s.pos+2+end], "\n") {
+				newLine = true
+			}
+			s.pos += 2 + end
+		default:
+			r := s.peekRune()
+			if !unicode.IsSpace(r) {
+				return newLine
+			}
+			s.advanceRune()
+		}
+	}
+	return newLine
+}
+
+func (s *scanner) skipString(quote byte) {
+	s.pos++
+	for s.pos < len(s.text) {
+		switch s.text[s.pos] {
+		case '\\':
+			s.pos += 2
+		case quote:
+			s.pos++
+			return
+		case '\n':
+			return
+		default:
+			s.pos++
+		}
+	}
+	s.pos = len(s.text)
+}
+
+// skipTemplate skips a template literal, including nested literals in its substitutions.
+func (s *scanner) skipTemplate() {
+	s.pos++
+	for s.pos < len(s.text) {
+		switch {
+		case s.text[s.pos] == '\\':
+			s.pos += 2
+		case s.text[s.pos] == '`':
+			s.pos++
+			return
+		case strings.HasPrefix(s.text[s.pos:], "${"):
+			s.pos += 2
+			s.skipSubstitution()
+		default:
+			s.pos++
+		}
+	}
+	s.pos = len(s.text)
+}
+
+func (s *scanner) skipSubstitution() {
+	depth := 0
+	var previous []Token
+	for s.pos < len(s.text) {
+		tok := s.next(previous)
+		switch {
+		case tok.Kind == TokenEOF:
+			return
+		case tok.Is("{"):
+			depth++
+		case tok.Is("}"):
+			if depth == 0 {
+				return
+			}
+			depth--
+		}
+		previous = append(previous[:0], tok)
+	}
+}
+
+func (s *scanner) skipRegExp() {
+	s.pos++
+	inClass := false
+	for s.pos < len(s.text) {
+		switch c := s.text[s.pos]; {
+		case c == '\\':
+			s.pos += 2
+			continue
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '/' && !inClass:
+			s.pos++
+			for s.pos < len(s.text) && isIdentifierPart(s.peekRune()) {
+				s.advanceRune()
+			}
+			return
+		case c == '\n':
+			return
+		}
+		s.pos++
+	}
+}
+
+func (s *scanner) peekRune() rune {
+	r, _ := utf8.DecodeRuneInString(s.text[s.pos:])
+	return r
+}
+
+func (s *scanner) advanceRune() {
+	_, size := utf8.DecodeRuneInString(s.text[s.pos:])
+	s.pos += size
+}
+
+// regExpAllowed reports whether a `/` after the given tokens starts a regular expression rather
+// than a division.
+func regExpAllowed(previous []Token) bool {
+	if len(previous) == 0 {
+		return true
+	}
+	last := previous[len(previous)-1]
+	switch last.Kind {
+	case TokenNumber, TokenString, TokenTemplate, TokenRegExp:
+		return false
+	case TokenIdentifier:
+		switch last.Text {
+		case "return", "typeof", "instanceof", "in", "of", "new", "delete", "void", "throw", "case", "do", "else", "yield", "await":
+			return true
+		}
+		return false
+	}
+	return last.Text != ")" && last.Text != "]" && last.Text != "}"
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '$' || r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || unicode.IsDigit(r) || r == '\u200c' || r == '\u200d'
+}
@@ -0,0 +1,220 @@
+package typecheck
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/core"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/schema"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// removeXhtmlRegex strips the `:xhtml:` namespace that HTML elements inside an SVG
+// `foreignObject` are declared in.
+var removeXhtmlRegex = regexp.MustCompile(`^:xhtml:`)
+
+// DomSchemaChecker checks every non-Angular element/property processed in a template and
+// potentially produces diagnostics related to improper usage.
+//
+// A `DomSchemaChecker`'s job is to check DOM nodes and their attributes written used in templates
+// and produce diagnostics if certain conditions are not met.
+type DomSchemaChecker interface {
+	// Diagnostics returns the diagnostics produced so far.
+	Diagnostics() []*diagnostics.Diagnostic
+
+	// CheckElement checks a non-Angular element and records any diagnostics about it.
+	CheckElement(element *render3.Element, schemas []*core.SchemaMetadata, hostIsStandalone bool)
+
+	// CheckTemplateElementProperty checks a property binding on an element or template, named by
+	// its tag, and records any diagnostics about it.
+	CheckTemplateElementProperty(
+		tagName string,
+		name string,
+		span *util.ParseSourceSpan,
+		schemas []*core.SchemaMetadata,
+		hostIsStandalone bool,
+	)
+
+	// CheckTemplateElementEvent checks an event binding on an element or template, named by its
+	// tag, and records any diagnostics about it.
+	CheckTemplateElementEvent(
+		tagName string,
+		name string,
+		span *util.ParseSourceSpan,
+		schemas []*core.SchemaMetadata,
+		hostIsStandalone bool,
+	)
+}
+
+// RegistryDomSchemaChecker checks non-Angular elements and properties against the
+// `DomElementSchemaRegistry`, a schema maintained by the Angular team via extraction from a
+// browser DOM.
+type RegistryDomSchemaChecker struct {
+	registry    *schema.DomElementSchemaRegistry
+	diagnostics []*diagnostics.Diagnostic
+
+	// knownEvents caches the event names of each element, keyed by lowercase tag name.
+	knownEvents map[string]map[string]bool
+}
+
+// NewRegistryDomSchemaChecker creates a new RegistryDomSchemaChecker. A nil registry
+// creates a fresh `DomElementSchemaRegistry`.
+func NewRegistryDomSchemaChecker(registry *schema.DomElementSchemaRegistry) *RegistryDomSchemaChecker {
+	if registry == nil {
+		registry = schema.NewDomElementSchemaRegistry()
+	}
+	return &RegistryDomSchemaChecker{
+		registry:    registry,
+		diagnostics: []*diagnostics.Diagnostic{},
+		knownEvents: make(map[string]map[string]bool),
+	}
+}
+
+// Diagnostics returns the diagnostics produced so far.
+func (c *RegistryDomSchemaChecker) Diagnostics() []*diagnostics.Diagnostic {
+	return c.diagnostics
+}
+
+// CheckElement checks a non-Angular element and records any diagnostics about it.
+func (c *RegistryDomSchemaChecker) CheckElement(
+	element *render3.Element,
+	schemas []*core.SchemaMetadata,
+	hostIsStandalone bool,
+) {
+	// HTML elements inside an SVG `foreignObject` are declared in the `xhtml` namespace.
+	// We need to strip it before handing it over to the registry because all HTML tag names
+	// in the registry are without a namespace.
+	name := removeXhtmlRegex.ReplaceAllString(element.Name, "")
+
+	if c.registry.HasElement(name, schemas) {
+		return
+	}
+
+	schemasName := fmt.Sprintf("'%s.schemas'", hostDecorator(hostIsStandalone))
+	errorMsg := fmt.Sprintf("'%s' is not a known element:\n", name)
+	errorMsg += fmt.Sprintf("1. If '%s' is an Angular component, then verify that it is %s.\n",
+		name, hostDeclarationHint(hostIsStandalone))
+	if strings.Contains(name, "-") {
+		errorMsg += fmt.Sprintf(
+			"2. If '%s' is a Web Component then add 'CUSTOM_ELEMENTS_SCHEMA' to the %s of this component to suppress this message.",
+			name, schemasName)
+	} else {
+		errorMsg += fmt.Sprintf(
+			"2. To allow any element add 'NO_ERRORS_SCHEMA' to the %s of this component.", schemasName)
+	}
+
+	c.diagnostics = append(c.diagnostics, diagnostics.MakeDiagnostic(
+		diagnostics.SchemaInvalidElement, diagnostics.CategoryError, element.StartSourceSpan, errorMsg))
+}
+
+// CheckTemplateElementProperty checks a property binding on an element or template, named by its
+// tag, and records any diagnostics about it.
+func (c *RegistryDomSchemaChecker) CheckTemplateElementProperty(
+	tagName string,
+	name string,
+	span *util.ParseSourceSpan,
+	schemas []*core.SchemaMetadata,
+	hostIsStandalone bool,
+) {
+	if c.registry.HasProperty(tagName, name, schemas) {
+		return
+	}
+
+	decorator := hostDecorator(hostIsStandalone)
+	schemasName := fmt.Sprintf("'%s.schemas'", decorator)
+	errorMsg := fmt.Sprintf("Can't bind to '%s' since it isn't a known property of '%s'.", name, tagName)
+	if strings.HasPrefix(tagName, "ng-") {
+		errorMsg += fmt.Sprintf(
+			"\n1. If '%s' is an Angular directive, then add 'CommonModule' to the '%s.imports' of this component."+
+				"\n2. To allow any property add 'NO_ERRORS_SCHEMA' to the %s of this component.",
+			name, decorator, schemasName)
+	} else if strings.Contains(tagName, "-") {
+		errorMsg += fmt.Sprintf(
+			"\n1. If '%s' is an Angular component and it has '%s' input, then verify that it is %s."+
+				"\n2. If '%s' is a Web Component then add 'CUSTOM_ELEMENTS_SCHEMA' to the %s of this component to suppress this message."+
+				"\n3. To allow any property add 'NO_ERRORS_SCHEMA' to the %s of this component.",
+			tagName, name, hostDeclarationHint(hostIsStandalone), tagName, schemasName, schemasName)
+	}
+
+	c.diagnostics = append(c.diagnostics, diagnostics.MakeDiagnostic(
+		diagnostics.SchemaInvalidAttribute, diagnostics.CategoryError, span, errorMsg))
+}
+
+// CheckTemplateElementEvent checks an event binding on an element or template, named by its tag,
+// and records any diagnostics about it.
+//
+// Unlike unknown elements and properties, an unknown event is not necessarily a mistake since
+// any element can dispatch custom events, so it is reported as a warning.
+func (c *RegistryDomSchemaChecker) CheckTemplateElementEvent(
+	tagName string,
+	name string,
+	span *util.ParseSourceSpan,
+	schemas []*core.SchemaMetadata,
+	hostIsStandalone bool,
+) {
+	for _, s := range schemas {
+		if s.Name == core.NO_ERRORS_SCHEMA.Name {
+			return
+		}
+		if s.Name == core.CUSTOM_ELEMENTS_SCHEMA.Name && strings.Contains(tagName, "-") {
+			return
+		}
+	}
+
+	// Key events such as `keydown.enter` are matched on the underlying DOM event.
+	eventName := name
+	if idx := strings.Index(eventName, "."); idx > 0 {
+		eventName = eventName[:idx]
+	}
+	if c.isKnownEvent(tagName, eventName) {
+		return
+	}
+
+	errorMsg := fmt.Sprintf("'%s' is not a known event of '%s'.\n", name, tagName)
+	errorMsg += fmt.Sprintf("1. If '%s' is an output of an Angular directive, then verify that the directive is %s.\n",
+		name, hostDeclarationHint(hostIsStandalone))
+	errorMsg += fmt.Sprintf("2. If '%s' is a custom DOM event dispatched by your own code, this warning can be ignored.", name)
+
+	c.diagnostics = append(c.diagnostics, diagnostics.MakeDiagnostic(
+		diagnostics.SchemaInvalidEvent, diagnostics.CategoryWarning, span, errorMsg))
+}
+
+// isKnownEvent reports whether the element, or any DOM element, declares the given event.
+func (c *RegistryDomSchemaChecker) isKnownEvent(tagName string, eventName string) bool {
+	tagName = removeXhtmlRegex.ReplaceAllString(strings.ToLower(tagName), "")
+	events, ok := c.knownEvents[tagName]
+	if !ok {
+		events = make(map[string]bool)
+		for _, event := range c.registry.AllKnownEventsOfElement(tagName) {
+			events[event] = true
+		}
+		// Unknown (custom) elements still receive every event declared on `HTMLElement`.
+		if len(events) == 0 {
+			for _, event := range c.registry.AllKnownEventsOfElement("unknown") {
+				events[event] = true
+			}
+		}
+		c.knownEvents[tagName] = events
+	}
+	return events[eventName]
+}
+
+// hostDecorator returns the decorator under which the schemas and imports of the host are
+// configured.
+func hostDecorator(hostIsStandalone bool) string {
+	if hostIsStandalone {
+		return "@Component"
+	}
+	return "@NgModule"
+}
+
+// hostDeclarationHint describes where a missing component or directive needs to be declared.
+func hostDeclarationHint(hostIsStandalone bool) string {
+	if hostIsStandalone {
+		return "included in the '@Component.imports' of this component"
+	}
+	return "part of this module"
+}
@@ -0,0 +1,270 @@
+package typecheck
+
+import (
+	"fmt"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+// translateExpression writes the TypeScript equivalent of a template expression.
+//
+// Reads of template entities (variables, references and `@let` declarations) are resolved
+// to the identifiers declared for them in the type-check block, everything else is read from
+// the component instance (`this`). Every expression is followed by its template span so that
+// TypeScript diagnostics can be mapped back to the template.
+func (g *tcbGenerator) translateExpression(ast expression_parser.AST) {
+	w := g.w
+	start := w.len()
+
+	switch e := ast.(type) {
+	case nil:
+		w.write("undefined")
+		return
+	case *expression_parser.ASTWithSource:
+		g.translateExpression(e.AST)
+		return
+	case *expression_parser.EmptyExpr:
+		w.write("undefined")
+		return
+	case *expression_parser.ThisReceiver:
+		w.write("this")
+		return
+	case *expression_parser.ImplicitReceiver:
+		w.write("this")
+		return
+	case *expression_parser.LiteralPrimitive:
+		g.translateLiteral(e.Value)
+		return
+
+	case *expression_parser.PropertyRead:
+		if _, isThis := e.Receiver.(*expression_parser.ThisReceiver); isThis {
+			w.write("this", propertyAccess(e.Name))
+		} else if _, isImplicit := e.Receiver.(*expression_parser.ImplicitReceiver); isImplicit {
+			w.write(g.resolveImplicitRead(e))
+		} else {
+			g.translateExpression(e.Receiver)
+			w.write(propertyAccess(e.Name))
+		}
+	case *expression_parser.SafePropertyRead:
+		g.translateExpression(e.Receiver)
+		if isIdentifier(e.Name) {
+			w.write("?.", e.Name)
+		} else {
+			w.write("?.[", quoteString(e.Name), "]")
+		}
+	case *expression_parser.KeyedRead:
+		g.translateExpression(e.Receiver)
+		w.write("[")
+		g.translateExpression(e.Key)
+		w.write("]")
+	case *expression_parser.SafeKeyedRead:
+		g.translateExpression(e.Receiver)
+		w.write("?.[")
+		g.translateExpression(e.Key)
+		w.write("]")
+	case *expression_parser.Call:
+		g.translateExpression(e.Receiver)
+		g.translateArguments(e.Args)
+	case *expression_parser.SafeCall:
+		g.translateExpression(e.Receiver)
+		w.write("?.")
+		g.translateArguments(e.Args)
+	case *expression_parser.BindingPipe:
+		g.translatePipe(e)
+
+	case *expression_parser.Unary:
+		w.write("(", e.Operator)
+		g.translateExpression(e.Expr)
+		w.write(")")
+	case *expression_parser.Binary:
+		if expression_parser.IsAssignmentOperation(e.Operation) {
+			g.checkWrite(e.Left)
+		}
+		w.write("(")
+		g.translateExpression(e.Left)
+		w.write(" ", e.Operation, " ")
+		g.translateExpression(e.Right)
+		w.write(")")
+	case *expression_parser.Conditional:
+		w.write("(")
+		g.translateExpression(e.Condition)
+		w.write(" ? ")
+		g.translateExpression(e.TrueExp)
+		w.write(" : ")
+		g.translateExpression(e.FalseExp)
+		w.write(")")
+	case *expression_parser.PrefixNot:
+		w.write("!(")
+		g.translateExpression(e.Expression)
+		w.write(")")
+	case *expression_parser.TypeofExpression:
+		w.write("typeof (")
+		g.translateExpression(e.Expression)
+		w.write(")")
+	case *expression_parser.VoidExpression:
+		w.write("void (")
+		g.translateExpression(e.Expression)
+		w.write(")")
+	case *expression_parser.NonNullAssert:
+		w.write("(")
+		g.translateExpression(e.Expression)
+		w.write(")!")
+	case *expression_parser.ParenthesizedExpression:
+		w.write("(")
+		g.translateExpression(e.Expression)
+		w.write(")")
+
+	case *expression_parser.LiteralArray:
+		w.write("[")
+		for i, value := range e.Expressions {
+			if i > 0 {
+				w.write(", ")
+			}
+			g.translateExpression(value)
+		}
+		w.write("]")
+	case *expression_parser.LiteralMap:
+		w.write("{")
+		for i, key := range e.Keys {
+			if i > 0 {
+				w.write(", ")
+			}
+			w.write(quoteString(key.Key), ": ")
+			g.translateExpression(e.Values[i])
+		}
+		w.write("}")
+	case *expression_parser.Interpolation:
+		w.write("(\"\"")
+		for _, value := range e.Expressions {
+			w.write(" + ")
+			g.translateExpression(value)
+		}
+		w.write(")")
+	case *expression_parser.TemplateLiteral:
+		g.translateTemplateLiteral(e)
+	case *expression_parser.TaggedTemplateLiteral:
+		g.translateExpression(e.Tag)
+		g.translateTemplateLiteral(e.Template)
+	case *expression_parser.RegularExpressionLiteral:
+		w.write("/", e.Body, "/")
+		if e.Flags != nil {
+			w.write(*e.Flags)
+		}
+	case *expression_parser.Chain:
+		w.write("(")
+		for i, value := range e.Expressions {
+			if i > 0 {
+				w.write(", ")
+			}
+			g.translateExpression(value)
+		}
+		w.write(")")
+
+	default:
+		// Unknown expressions are typed as `any` so that they don't produce false positives.
+		w.write("(null as any)")
+	}
+
+	w.addSpanInfo(start, ast.SourceSpan())
+}
+
+func (g *tcbGenerator) translateArguments(args []expression_parser.AST) {
+	g.w.write("(")
+	for i, arg := range args {
+		if i > 0 {
+			g.w.write(", ")
+		}
+		g.translateExpression(arg)
+	}
+	g.w.write(")")
+}
+
+func (g *tcbGenerator) translateLiteral(value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		g.w.write("null")
+	case expression_parser.UndefinedValue:
+		g.w.write("undefined")
+	case string:
+		g.w.write(quoteString(v))
+	case bool:
+		g.w.write(fmt.Sprint(v))
+	default:
+		g.w.write(formatNumber(v))
+	}
+}
+
+func (g *tcbGenerator) translateTemplateLiteral(literal *expression_parser.TemplateLiteral) {
+	g.w.write("`")
+	for i, element := range literal.Elements {
+		g.w.write(strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${").Replace(element.Text))
+		if i < len(literal.Expressions) {
+			g.w.write("${")
+			g.translateExpression(literal.Expressions[i])
+			g.w.write("}")
+		}
+	}
+	g.w.write("`")
+}
+
+// translatePipe writes a call to the `transform` method of the pipe's instance.
+func (g *tcbGenerator) translatePipe(pipe *expression_parser.BindingPipe) {
+	pipeVar := g.pipeInstance(pipe)
+	g.w.write(pipeVar, ".transform(")
+	g.translateExpression(pipe.Exp)
+	for _, arg := range pipe.Args {
+		g.w.write(", ")
+		g.translateExpression(arg)
+	}
+	g.w.write(")")
+}
+
+// resolveImplicitRead resolves a read from the implicit receiver: template entities resolve to
+// their declarations in the type-check block, `$event` in a listener to its parameter and
+// everything else to a property of the component.
+func (g *tcbGenerator) resolveImplicitRead(read *expression_parser.PropertyRead) string {
+	switch target := g.bound.GetExpressionTarget(read).(type) {
+	case *render3.Variable:
+		if name, ok := g.variables[target]; ok {
+			return name
+		}
+	case *render3.LetDeclaration:
+		if name, ok := g.lets[target]; ok {
+			return name
+		}
+	case *render3.Reference:
+		return g.resolveReference(target)
+	}
+	if read.Name == "$event" && g.inListener {
+		return "$event"
+	}
+	return "this" + propertyAccess(read.Name)
+}
+
+// checkWrite reports writes to template entities, which are read-only.
+func (g *tcbGenerator) checkWrite(target expression_parser.AST) {
+	read, ok := target.(*expression_parser.PropertyRead)
+	if !ok {
+		return
+	}
+	if _, isImplicit := read.Receiver.(*expression_parser.ImplicitReceiver); !isImplicit {
+		return
+	}
+	span := g.locateAbsolute(read.SourceSpan())
+	switch entity := g.bound.GetExpressionTarget(read).(type) {
+	case *render3.Variable:
+		g.addDiagnostic(diagnostics.WriteToReadOnlyVariable, span, fmt.Sprintf(
+			"Cannot use variable '%s' as the left-hand side of an assignment expression. Template variables are read-only.",
+			entity.Name))
+	case *render3.Reference:
+		g.addDiagnostic(diagnostics.WriteToReadOnlyVariable, span, fmt.Sprintf(
+			"Cannot use reference '%s' as the left-hand side of an assignment expression. Template references are read-only.",
+			entity.Name))
+	case *render3.LetDeclaration:
+		g.addDiagnostic(diagnostics.IllegalLetWrite, span, fmt.Sprintf(
+			"Cannot assign to @let declaration '%s'.", entity.Name))
+	}
+}
@@ -0,0 +1,186 @@
+// Package extended implements Angular's extended template diagnostics: checks which report
+// templates that are valid, but most likely don't do what their author intended.
+package extended
+
+import (
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck"
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/view"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// TemplateCheck is a single extended diagnostic.
+type TemplateCheck interface {
+	// Code is the error code of the diagnostics reported by the check.
+	Code() diagnostics.ErrorCode
+	// Name is the name of the check in the `extendedDiagnostics` options, e.g.
+	// `invalidBananaInBox`.
+	Name() string
+	// Run returns the diagnostics of the check for a template. Diagnostics should be created
+	// with `TemplateContext.MakeTemplateDiagnostic`, which applies the configured category.
+	Run(ctx *TemplateContext) []*diagnostics.Diagnostic
+}
+
+// NodeCheck is implemented by checks which only look at one node or expression at a time.
+// `VisitTemplate` runs them over the whole template.
+type NodeCheck interface {
+	// VisitNode returns the diagnostics for a node of the template. The node is either a
+	// `render3.Node`, an attribute, event, variable or reference of an element, or an
+	// `expression_parser.AST` of one of its bindings.
+	VisitNode(ctx *TemplateContext, node interface{}) []*diagnostics.Diagnostic
+}
+
+// MemberKind is the kind of a member of the component class.
+type MemberKind int
+
+const (
+	// MemberUnknown is used when nothing is known about the member.
+	MemberUnknown MemberKind = iota
+	// MemberProperty is a plain property.
+	MemberProperty
+	// MemberMethod is a method, or a property holding a function.
+	MemberMethod
+	// MemberSignal is a property holding a signal, which has to be invoked to be read.
+	MemberSignal
+)
+
+// TypeInfo provides what the checks need to know about the members of the component, which is
+// only known to the TypeScript program declaring it.
+type TypeInfo interface {
+	typecheck.NullabilityResolver
+	// MemberKind returns the kind of the member at the given path of the component, e.g.
+	// `["user", "name"]` for `user.name`.
+	MemberKind(path []string) MemberKind
+}
+
+// Member describes a member of the component in a `Members` map.
+type Member struct {
+	Kind        MemberKind
+	Nullability typecheck.Nullability
+}
+
+// Members is a `TypeInfo` backed by a map from dotted member paths to their description.
+type Members map[string]Member
+
+// Nullability implements TypeInfo.
+func (m Members) Nullability(path []string) typecheck.Nullability {
+	return m[strings.Join(path, ".")].Nullability
+}
+
+// MemberKind implements TypeInfo.
+func (m Members) MemberKind(path []string) MemberKind {
+	return m[strings.Join(path, ".")].Kind
+}
+
+// TemplateInfo is the template of a component to check.
+type TemplateInfo struct {
+	Bound view.BoundTarget
+
+	// HostIsStandalone is whether the component owning the template is standalone. Checks for
+	// missing imports only apply to standalone components.
+	HostIsStandalone bool
+
+	// Types resolves the members of the component. Checks which depend on types don't report
+	// anything when nil.
+	Types TypeInfo
+}
+
+// TemplateContext is passed to checks while they run over a template.
+type TemplateContext struct {
+	TemplateInfo
+
+	category    diagnostics.Category
+	code        diagnostics.ErrorCode
+	file        *util.ParseSourceFile
+	nullability *typecheck.NullabilityAnalysis
+}
+
+// Nodes returns the top-level nodes of the template.
+func (ctx *TemplateContext) Nodes() []render3.Node {
+	if target := ctx.Bound.Target(); target != nil {
+		return target.Template
+	}
+	return nil
+}
+
+// MakeTemplateDiagnostic creates a diagnostic of the running check at the given span, with the
+// category configured for the check.
+func (ctx *TemplateContext) MakeTemplateDiagnostic(span *util.ParseSourceSpan, message string) *diagnostics.Diagnostic {
+	return diagnostics.MakeDiagnostic(ctx.code, ctx.category, span, message)
+}
+
+// Span converts the absolute span of an expression into a span of the template file.
+func (ctx *TemplateContext) Span(span *expression_parser.AbsoluteSourceSpan) *util.ParseSourceSpan {
+	if span == nil || ctx.file == nil {
+		return nil
+	}
+	start := util.NewParseLocation(ctx.file, 0, 0, 0).MoveBy(span.Start)
+	return util.NewParseSourceSpan(start, start.MoveBy(span.End-span.Start), nil, nil)
+}
+
+// Text returns the source text of an expression.
+func (ctx *TemplateContext) Text(ast expression_parser.AST) string {
+	span := ast.SourceSpan()
+	if span == nil || ctx.file == nil || span.Start < 0 || span.End > len(ctx.file.Content) || span.Start > span.End {
+		return ""
+	}
+	return ctx.file.Content[span.Start:span.End]
+}
+
+// Nullability returns the nullability of an expression of the template. It is unknown for all
+// expressions without TypeInfo.
+func (ctx *TemplateContext) Nullability(ast expression_parser.AST) typecheck.Nullability {
+	if ctx.Types == nil {
+		return typecheck.NullabilityUnknown
+	}
+	if ctx.nullability == nil {
+		ctx.nullability = typecheck.AnalyzeNullability(ctx.Bound, ctx.Types)
+	}
+	return ctx.nullability.Of(ast)
+}
+
+// MemberKindOf returns the kind of the component member read by an expression, e.g. for `user`
+// or `this.user.name`. It is unknown for reads of template variables and other expressions.
+func (ctx *TemplateContext) MemberKindOf(ast expression_parser.AST) MemberKind {
+	if ctx.Types == nil {
+		return MemberUnknown
+	}
+	path, ok := ctx.memberPath(ast)
+	if !ok {
+		return MemberUnknown
+	}
+	return ctx.Types.MemberKind(path)
+}
+
+func (ctx *TemplateContext) memberPath(ast expression_parser.AST) ([]string, bool) {
+	var receiver expression_parser.AST
+	var name string
+	switch e := ast.(type) {
+	case *expression_parser.ASTWithSource:
+		return ctx.memberPath(e.AST)
+	case *expression_parser.PropertyRead:
+		receiver, name = e.Receiver, e.Name
+	case *expression_parser.SafePropertyRead:
+		receiver, name = e.Receiver, e.Name
+	default:
+		return nil, false
+	}
+	switch receiver.(type) {
+	case *expression_parser.ThisReceiver:
+		return []string{name}, true
+	case *expression_parser.ImplicitReceiver:
+		if ctx.Bound.GetExpressionTarget(ast) != nil {
+			return nil, false
+		}
+		return []string{name}, true
+	}
+	path, ok := ctx.memberPath(receiver)
+	if !ok {
+		return nil, false
+	}
+	return append(path, name), true
+}
@@ -0,0 +1,16 @@
+package extended
+
+// AllChecks returns all the extended diagnostics shipped with the compiler.
+func AllChecks() []TemplateCheck {
+	return []TemplateCheck{
+		InvalidBananaInBoxCheck{},
+		NullishCoalescingNotNullableCheck{},
+		MissingControlFlowDirectiveCheck{},
+		MissingStructuralDirectiveCheck{},
+		TextAttributeNotBindingCheck{},
+		SkipHydrationNotStaticCheck{},
+		InterpolatedSignalNotInvokedCheck{},
+		UninvokedFunctionInEventBindingCheck{},
+		UnusedLetDeclarationCheck{},
+	}
+}
@@ -0,0 +1,440 @@
+package extended
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck"
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// DiagnosticCategoryLabel is the severity of a check in the `extendedDiagnostics` options.
+type DiagnosticCategoryLabel string
+
+const (
+	// CategoryLabelWarning reports the diagnostics of a check as warnings.
+	CategoryLabelWarning DiagnosticCategoryLabel = "warning"
+	// CategoryLabelError reports the diagnostics of a check as errors.
+	CategoryLabelError DiagnosticCategoryLabel = "error"
+	// CategoryLabelSuppress disables a check.
+	CategoryLabelSuppress DiagnosticCategoryLabel = "suppress"
+)
+
+// Options mirrors the `extendedDiagnostics` compiler options.
+type Options struct {
+	// DefaultCategory applies to all checks that are not configured in Checks. Defaults to
+	// warning.
+	DefaultCategory DiagnosticCategoryLabel
+	// Checks configures individual checks by name, e.g. `{"invalidBananaInBox": "error"}`.
+	Checks map[string]DiagnosticCategoryLabel
+}
+
+// ExtendedTemplateChecker runs a set of extended diagnostics over component templates.
+type ExtendedTemplateChecker struct {
+	checks     []TemplateCheck
+	categories map[TemplateCheck]diagnostics.Category
+}
+
+// NewExtendedTemplateChecker creates a checker running the given checks with the given options.
+// Checks which are suppressed by the options are not run. Invalid options are reported as
+// diagnostics.
+func NewExtendedTemplateChecker(checks []TemplateCheck, options *Options) (*ExtendedTemplateChecker, []*diagnostics.Diagnostic) {
+	if options == nil {
+		options = &Options{}
+	}
+	configDiags := validateOptions(checks, options)
+
+	checker := &ExtendedTemplateChecker{categories: make(map[TemplateCheck]diagnostics.Category)}
+	for _, check := range checks {
+		label, ok := options.Checks[check.Name()]
+		if !ok {
+			label = options.DefaultCategory
+		}
+		if label == "" {
+			label = CategoryLabelWarning
+		}
+		category, enabled := categoryOf(label)
+		if !enabled {
+			continue
+		}
+		checker.checks = append(checker.checks, check)
+		checker.categories[check] = category
+	}
+	return checker, configDiags
+}
+
+// categoryOf returns the category of a label, and false for suppressed checks.
+func categoryOf(label DiagnosticCategoryLabel) (diagnostics.Category, bool) {
+	switch label {
+	case CategoryLabelError:
+		return diagnostics.CategoryError, true
+	case CategoryLabelSuppress:
+		return diagnostics.CategoryWarning, false
+	}
+	return diagnostics.CategoryWarning, true
+}
+
+func validateOptions(checks []TemplateCheck, options *Options) []*diagnostics.Diagnostic {
+	var diags []*diagnostics.Diagnostic
+	isValidLabel := func(label DiagnosticCategoryLabel) bool {
+		return label == CategoryLabelWarning || label == CategoryLabelError || label == CategoryLabelSuppress
+	}
+	allowed := fmt.Sprintf("%s, %s or %s", CategoryLabelWarning, CategoryLabelError, CategoryLabelSuppress)
+	if options.DefaultCategory != "" && !isValidLabel(options.DefaultCategory) {
+		diags = append(diags, diagnostics.MakeFileDiagnostic(diagnostics.ConfigExtendedDiagnosticsUnknownCategoryLabel,
+			diagnostics.CategoryError, "", fmt.Sprintf(
+				"Angular compiler option \"extendedDiagnostics.defaultCategory\" has an unknown diagnostic category: \"%s\".\n\nAllowed diagnostic categories are:\n%s",
+				options.DefaultCategory, allowed)))
+	}
+
+	known := make(map[string]bool)
+	for _, check := range checks {
+		known[check.Name()] = true
+	}
+	names := make([]string, 0, len(options.Checks))
+	for name := range options.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !known[name] {
+			knownNames := make([]string, 0, len(known))
+			for knownName := range known {
+				knownNames = append(knownNames, knownName)
+			}
+			sort.Strings(knownNames)
+			diags = append(diags, diagnostics.MakeFileDiagnostic(diagnostics.ConfigExtendedDiagnosticsUnknownCheck,
+				diagnostics.CategoryError, "", fmt.Sprintf(
+					"Angular compiler option \"extendedDiagnostics.checks\" has an unknown check: \"%s\".\n\nAllowed check names are:\n%s",
+					name, strings.Join(knownNames, "\n"))))
+			continue
+		}
+		if label := options.Checks[name]; !isValidLabel(label) {
+			diags = append(diags, diagnostics.MakeFileDiagnostic(diagnostics.ConfigExtendedDiagnosticsUnknownCategoryLabel,
+				diagnostics.CategoryError, "", fmt.Sprintf(
+					"Angular compiler option \"extendedDiagnostics.checks['%s']\" has an unknown diagnostic category: \"%s\".\n\nAllowed diagnostic categories are:\n%s",
+					name, label, allowed)))
+		}
+	}
+	return diags
+}
+
+// GetDiagnosticsForTemplate runs all enabled checks over a template.
+func (c *ExtendedTemplateChecker) GetDiagnosticsForTemplate(info TemplateInfo) []*diagnostics.Diagnostic {
+	ctx := &TemplateContext{TemplateInfo: info}
+	for _, node := range ctx.Nodes() {
+		if span := node.SourceSpan(); span != nil && span.Start != nil && span.Start.File != nil {
+			ctx.file = span.Start.File
+			break
+		}
+	}
+
+	var diags []*diagnostics.Diagnostic
+	for _, check := range c.checks {
+		ctx.code = check.Code()
+		ctx.category = c.categories[check]
+		diags = append(diags, check.Run(ctx)...)
+	}
+	return diags
+}
+
+// VisitTemplate runs a NodeCheck over every node and expression of the template.
+func VisitTemplate(ctx *TemplateContext, check NodeCheck) []*diagnostics.Diagnostic {
+	visitor := &templateVisitor{ctx: ctx, check: check}
+	visitor.visitAll(ctx.Nodes())
+	return visitor.diagnostics
+}
+
+// templateVisitor walks all nodes of a template, including the attributes, bindings and
+// variables of elements and the expressions of all bindings, and hands them to a NodeCheck.
+type templateVisitor struct {
+	ctx         *TemplateContext
+	check       NodeCheck
+	diagnostics []*diagnostics.Diagnostic
+}
+
+func (v *templateVisitor) visitAll(nodes []render3.Node) {
+	for _, node := range nodes {
+		if node != nil {
+			node.Visit(v)
+		}
+	}
+}
+
+func (v *templateVisitor) report(node interface{}) {
+	v.diagnostics = append(v.diagnostics, v.check.VisitNode(v.ctx, node)...)
+}
+
+func (v *templateVisitor) visitExpression(ast expression_parser.AST) {
+	typecheck.WalkExpression(ast, func(node expression_parser.AST) {
+		v.report(node)
+	})
+}
+
+// Visit visits a node
+func (v *templateVisitor) Visit(node render3.Node) interface{} {
+	return node.Visit(v)
+}
+
+func (v *templateVisitor) VisitElement(element *render3.Element) interface{} {
+	v.report(element)
+	v.visitAttributes(element.Attributes, element.Inputs, element.Outputs)
+	for _, directive := range element.Directives {
+		directive.Visit(v)
+	}
+	for _, ref := range element.References {
+		v.report(ref)
+	}
+	v.visitAll(element.Children)
+	return nil
+}
+
+func (v *templateVisitor) VisitTemplate(template *render3.Template) interface{} {
+	v.report(template)
+	v.visitAttributes(template.Attributes, template.Inputs, template.Outputs)
+	for _, attr := range template.TemplateAttrs {
+		switch a := attr.(type) {
+		case *render3.TextAttribute:
+			v.VisitTextAttribute(a)
+		case *render3.BoundAttribute:
+			v.VisitBoundAttribute(a)
+		}
+	}
+	for _, directive := range template.Directives {
+		directive.Visit(v)
+	}
+	for _, ref := range template.References {
+		v.report(ref)
+	}
+	for _, variable := range template.Variables {
+		v.report(variable)
+	}
+	v.visitAll(template.Children)
+	return nil
+}
+
+func (v *templateVisitor) visitAttributes(attributes []*render3.TextAttribute, inputs []*render3.BoundAttribute, outputs []*render3.BoundEvent) {
+	for _, attr := range attributes {
+		v.VisitTextAttribute(attr)
+	}
+	for _, input := range inputs {
+		v.VisitBoundAttribute(input)
+	}
+	for _, output := range outputs {
+		v.VisitBoundEvent(output)
+	}
+}
+
+func (v *templateVisitor) VisitContent(content *render3.Content) interface{} {
+	v.report(content)
+	for _, attr := range content.Attributes {
+		v.VisitTextAttribute(attr)
+	}
+	v.visitAll(content.Children)
+	return nil
+}
+
+func (v *templateVisitor) VisitVariable(variable *render3.Variable) interface{} {
+	v.report(variable)
+	return nil
+}
+
+func (v *templateVisitor) VisitReference(reference *render3.Reference) interface{} {
+	v.report(reference)
+	return nil
+}
+
+func (v *templateVisitor) VisitTextAttribute(attribute *render3.TextAttribute) interface{} {
+	v.report(attribute)
+	return nil
+}
+
+func (v *templateVisitor) VisitBoundAttribute(attribute *render3.BoundAttribute) interface{} {
+	v.report(attribute)
+	v.visitExpression(attribute.Value)
+	return nil
+}
+
+func (v *templateVisitor) VisitBoundEvent(event *render3.BoundEvent) interface{} {
+	v.report(event)
+	v.visitExpression(event.Handler)
+	return nil
+}
+
+func (v *templateVisitor) VisitText(text *render3.Text) interface{} {
+	v.report(text)
+	return nil
+}
+
+func (v *templateVisitor) VisitBoundText(text *render3.BoundText) interface{} {
+	v.report(text)
+	v.visitExpression(text.Value)
+	return nil
+}
+
+func (v *templateVisitor) VisitIcu(icu *render3.Icu) interface{} {
+	v.report(icu)
+	for _, boundText := range icu.Vars {
+		v.VisitBoundText(boundText)
+	}
+	for _, placeholder := range icu.Placeholders {
+		if node, ok := placeholder.(render3.Node); ok {
+			node.Visit(v)
+		}
+	}
+	return nil
+}
+
+func (v *templateVisitor) VisitDeferredBlock(deferred *render3.DeferredBlock) interface{} {
+	v.report(deferred)
+	for _, triggers := range []*render3.DeferredBlockTriggers{deferred.Triggers, deferred.PrefetchTriggers, deferred.HydrateTriggers} {
+		if triggers != nil && triggers.When != nil {
+			v.visitExpression(triggers.When.Value)
+		}
+	}
+	v.visitAll(deferred.Children)
+	if deferred.Placeholder != nil {
+		v.VisitDeferredBlockPlaceholder(deferred.Placeholder)
+	}
+	if deferred.Loading != nil {
+		v.VisitDeferredBlockLoading(deferred.Loading)
+	}
+	if deferred.Error != nil {
+		v.VisitDeferredBlockError(deferred.Error)
+	}
+	return nil
+}
+
+func (v *templateVisitor) VisitDeferredBlockPlaceholder(block *render3.DeferredBlockPlaceholder) interface{} {
+	v.report(block)
+	v.visitAll(block.Children)
+	return nil
+}
+
+func (v *templateVisitor) VisitDeferredBlockError(block *render3.DeferredBlockError) interface{} {
+	v.report(block)
+	v.visitAll(block.Children)
+	return nil
+}
+
+func (v *templateVisitor) VisitDeferredBlockLoading(block *render3.DeferredBlockLoading) interface{} {
+	v.report(block)
+	v.visitAll(block.Children)
+	return nil
+}
+
+func (v *templateVisitor) VisitDeferredTrigger(trigger *render3.DeferredTrigger) interface{} {
+	return nil
+}
+
+func (v *templateVisitor) VisitSwitchBlock(block *render3.SwitchBlock) interface{} {
+	v.report(block)
+	v.visitExpression(block.Expression)
+	for _, switchCase := range block.Cases {
+		v.VisitSwitchBlockCase(switchCase)
+	}
+	return nil
+}
+
+func (v *templateVisitor) VisitSwitchBlockCase(block *render3.SwitchBlockCase) interface{} {
+	v.report(block)
+	if block.Expression != nil {
+		v.visitExpression(block.Expression)
+	}
+	v.visitAll(block.Children)
+	return nil
+}
+
+func (v *templateVisitor) VisitForLoopBlock(block *render3.ForLoopBlock) interface{} {
+	v.report(block)
+	if block.Item != nil {
+		v.report(block.Item)
+	}
+	for _, variable := range block.ContextVariables {
+		v.report(variable)
+	}
+	if block.Expression != nil {
+		v.visitExpression(block.Expression)
+	}
+	if block.TrackBy != nil {
+		v.visitExpression(block.TrackBy)
+	}
+	v.visitAll(block.Children)
+	if block.Empty != nil {
+		v.VisitForLoopBlockEmpty(block.Empty)
+	}
+	return nil
+}
+
+func (v *templateVisitor) VisitForLoopBlockEmpty(block *render3.ForLoopBlockEmpty) interface{} {
+	v.report(block)
+	v.visitAll(block.Children)
+	return nil
+}
+
+func (v *templateVisitor) VisitIfBlock(block *render3.IfBlock) interface{} {
+	v.report(block)
+	for _, branch := range block.Branches {
+		v.VisitIfBlockBranch(branch)
+	}
+	return nil
+}
+
+func (v *templateVisitor) VisitIfBlockBranch(block *render3.IfBlockBranch) interface{} {
+	v.report(block)
+	if block.Expression != nil {
+		v.visitExpression(block.Expression)
+	}
+	if block.ExpressionAlias != nil {
+		v.report(block.ExpressionAlias)
+	}
+	v.visitAll(block.Children)
+	return nil
+}
+
+func (v *templateVisitor) VisitUnknownBlock(block *render3.UnknownBlock) interface{} {
+	v.report(block)
+	return nil
+}
+
+func (v *templateVisitor) VisitLetDeclaration(decl *render3.LetDeclaration) interface{} {
+	v.report(decl)
+	v.visitExpression(decl.Value)
+	return nil
+}
+
+func (v *templateVisitor) VisitComponent(component *render3.Component) interface{} {
+	v.report(component)
+	v.visitAttributes(component.Attributes, component.Inputs, component.Outputs)
+	for _, directive := range component.Directives {
+		directive.Visit(v)
+	}
+	for _, ref := range component.References {
+		v.report(ref)
+	}
+	v.visitAll(component.Children)
+	return nil
+}
+
+func (v *templateVisitor) VisitDirective(directive *render3.Directive) interface{} {
+	v.report(directive)
+	v.visitAttributes(directive.Attributes, directive.Inputs, directive.Outputs)
+	for _, ref := range directive.References {
+		v.report(ref)
+	}
+	return nil
+}
+
+// spanOf returns the first non-nil span.
+func spanOf(spans ...*util.ParseSourceSpan) *util.ParseSourceSpan {
+	for _, span := range spans {
+		if span != nil {
+			return span
+		}
+	}
+	return nil
+}
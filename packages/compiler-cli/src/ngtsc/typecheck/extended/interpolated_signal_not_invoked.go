@@ -0,0 +1,82 @@
+package extended
+
+import (
+	"fmt"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+// InterpolatedSignalNotInvokedCheck reports signals which are interpolated, or bound to an
+// attribute, class or style, without being invoked: `{{ count }}` renders the signal function
+// instead of its value. It needs TypeInfo.
+type InterpolatedSignalNotInvokedCheck struct{}
+
+func (InterpolatedSignalNotInvokedCheck) Code() diagnostics.ErrorCode {
+	return diagnostics.InterpolatedSignalNotInvoked
+}
+func (InterpolatedSignalNotInvokedCheck) Name() string { return "interpolatedSignalNotInvoked" }
+
+func (c InterpolatedSignalNotInvokedCheck) Run(ctx *TemplateContext) []*diagnostics.Diagnostic {
+	if ctx.Types == nil {
+		return nil
+	}
+	return VisitTemplate(ctx, c)
+}
+
+func (InterpolatedSignalNotInvokedCheck) VisitNode(ctx *TemplateContext, node interface{}) []*diagnostics.Diagnostic {
+	var expressions []expression_parser.AST
+	switch n := node.(type) {
+	case *expression_parser.Interpolation:
+		expressions = n.Expressions
+	case *render3.BoundAttribute:
+		// Directive inputs may well accept signals.
+		if !isDomOnlyBinding(n) || ctx.Bound.GetConsumerOfBinding(n) != nil && !isElement(ctx.Bound.GetConsumerOfBinding(n)) {
+			return nil
+		}
+		value := n.Value
+		if withSource, ok := value.(*expression_parser.ASTWithSource); ok {
+			value = withSource.AST
+		}
+		if _, isInterpolation := value.(*expression_parser.Interpolation); isInterpolation {
+			// Reported through the interpolation itself.
+			return nil
+		}
+		expressions = []expression_parser.AST{value}
+	default:
+		return nil
+	}
+
+	var diags []*diagnostics.Diagnostic
+	for _, expr := range expressions {
+		switch expr.(type) {
+		case *expression_parser.PropertyRead, *expression_parser.SafePropertyRead:
+		default:
+			continue
+		}
+		if ctx.MemberKindOf(expr) != MemberSignal {
+			continue
+		}
+		text := ctx.Text(expr)
+		diags = append(diags, ctx.MakeTemplateDiagnostic(ctx.Span(expr.SourceSpan()), fmt.Sprintf(
+			"%s is a function and should be invoked: %s()", text, text)))
+	}
+	return diags
+}
+
+// isDomOnlyBinding reports whether a binding can only target the DOM: attribute, class and
+// style bindings.
+func isDomOnlyBinding(attr *render3.BoundAttribute) bool {
+	switch attr.Type {
+	case expression_parser.BindingTypeAttribute, expression_parser.BindingTypeClass, expression_parser.BindingTypeStyle:
+		return true
+	}
+	return strings.HasPrefix(attr.Name, "attr.")
+}
+
+func isElement(node interface{}) bool {
+	_, ok := node.(*render3.Element)
+	return ok
+}
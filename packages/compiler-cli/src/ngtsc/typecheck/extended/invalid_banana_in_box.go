@@ -0,0 +1,33 @@
+package extended
+
+import (
+	"fmt"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+// InvalidBananaInBoxCheck reports two-way bindings written as `([x])` instead of `[(x)]`, which
+// Angular parses as an event binding for an event named `[x]`.
+type InvalidBananaInBoxCheck struct{}
+
+func (InvalidBananaInBoxCheck) Code() diagnostics.ErrorCode { return diagnostics.InvalidBananaInBox }
+func (InvalidBananaInBoxCheck) Name() string                { return "invalidBananaInBox" }
+
+func (c InvalidBananaInBoxCheck) Run(ctx *TemplateContext) []*diagnostics.Diagnostic {
+	return VisitTemplate(ctx, c)
+}
+
+func (InvalidBananaInBoxCheck) VisitNode(ctx *TemplateContext, node interface{}) []*diagnostics.Diagnostic {
+	event, ok := node.(*render3.BoundEvent)
+	if !ok || !strings.HasPrefix(event.Name, "[") || !strings.HasSuffix(event.Name, "]") {
+		return nil
+	}
+	span := event.SourceSpan()
+	boundSyntax := span.String()
+	expected := strings.Replace(boundSyntax, "("+event.Name+")", "[("+event.Name[1:len(event.Name)-1]+")]", 1)
+	return []*diagnostics.Diagnostic{ctx.MakeTemplateDiagnostic(span, fmt.Sprintf(
+		"In the two-way binding syntax the parentheses should be inside the brackets, ex. '%s'. Find more at https://angular.dev/guide/templates/two-way-binding",
+		expected))}
+}
@@ -0,0 +1,76 @@
+package extended
+
+import (
+	"fmt"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// knownControlFlowDirective describes a structural directive of `CommonModule` and the block
+// replacing it.
+type knownControlFlowDirective struct {
+	directive string
+	builtIn   string
+}
+
+var knownControlFlowDirectives = map[string]knownControlFlowDirective{
+	"ngIf":            {directive: "NgIf", builtIn: "@if"},
+	"ngFor":           {directive: "NgFor", builtIn: "@for"},
+	"ngSwitchCase":    {directive: "NgSwitchCase", builtIn: "@switch with @case"},
+	"ngSwitchDefault": {directive: "NgSwitchDefault", builtIn: "@switch with @default"},
+}
+
+// MissingControlFlowDirectiveCheck reports `*ngIf`, `*ngFor`, `*ngSwitchCase` and
+// `*ngSwitchDefault` in standalone components which import neither the directive nor
+// `CommonModule`, in which case the template is silently not rendered as intended.
+type MissingControlFlowDirectiveCheck struct{}
+
+func (MissingControlFlowDirectiveCheck) Code() diagnostics.ErrorCode {
+	return diagnostics.MissingControlFlowDirective
+}
+func (MissingControlFlowDirectiveCheck) Name() string { return "missingControlFlowDirective" }
+
+func (c MissingControlFlowDirectiveCheck) Run(ctx *TemplateContext) []*diagnostics.Diagnostic {
+	// Components declared in NgModules get their directives from the module, which is not
+	// known here.
+	if !ctx.HostIsStandalone {
+		return nil
+	}
+	return VisitTemplate(ctx, c)
+}
+
+func (MissingControlFlowDirectiveCheck) VisitNode(ctx *TemplateContext, node interface{}) []*diagnostics.Diagnostic {
+	// The template attributes of an explicit <ng-template>, which has no tag name, are its bindings.
+	template, ok := node.(*render3.Template)
+	if !ok || template.TagName == nil {
+		return nil
+	}
+	for _, attr := range template.TemplateAttrs {
+		name, span := templateAttrName(attr)
+		known, isKnown := knownControlFlowDirectives[name]
+		if !isKnown {
+			continue
+		}
+		if len(ctx.Bound.GetDirectivesOfNode(template)) > 0 {
+			return nil
+		}
+		return []*diagnostics.Diagnostic{ctx.MakeTemplateDiagnostic(span, fmt.Sprintf(
+			"The `*%s` directive was used in the template, but neither the `%s` directive nor the `CommonModule` was imported. "+
+				"Use Angular's built-in control flow %s or make sure that either the `%s` directive or the `CommonModule` is included in the `@Component.imports` array of this component.",
+			name, known.directive, known.builtIn, known.directive))}
+	}
+	return nil
+}
+
+// templateAttrName returns the name and span of an attribute of a structural directive.
+func templateAttrName(attr interface{}) (string, *util.ParseSourceSpan) {
+	switch a := attr.(type) {
+	case *render3.TextAttribute:
+		return a.Name, spanOf(a.KeySpan, a.SourceSpan())
+	case *render3.BoundAttribute:
+		return a.Name, spanOf(a.KeySpan, a.SourceSpan())
+	}
+	return "", nil
+}
@@ -0,0 +1,47 @@
+package extended
+
+import (
+	"fmt"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+// MissingStructuralDirectiveCheck reports structural directives (`*foo`) used in standalone
+// components that don't import any directive matching them. Directives of `CommonModule` are
+// reported by MissingControlFlowDirectiveCheck instead.
+type MissingStructuralDirectiveCheck struct{}
+
+func (MissingStructuralDirectiveCheck) Code() diagnostics.ErrorCode {
+	return diagnostics.MissingStructuralDirective
+}
+func (MissingStructuralDirectiveCheck) Name() string { return "missingStructuralDirective" }
+
+func (c MissingStructuralDirectiveCheck) Run(ctx *TemplateContext) []*diagnostics.Diagnostic {
+	if !ctx.HostIsStandalone {
+		return nil
+	}
+	return VisitTemplate(ctx, c)
+}
+
+func (MissingStructuralDirectiveCheck) VisitNode(ctx *TemplateContext, node interface{}) []*diagnostics.Diagnostic {
+	// The template attributes of an explicit <ng-template>, which has no tag name, are its bindings.
+	template, ok := node.(*render3.Template)
+	if !ok || template.TagName == nil || len(template.TemplateAttrs) == 0 {
+		return nil
+	}
+	for _, attr := range template.TemplateAttrs {
+		name, span := templateAttrName(attr)
+		if _, isControlFlow := knownControlFlowDirectives[name]; isControlFlow {
+			continue
+		}
+		if len(ctx.Bound.GetDirectivesOfNode(template)) > 0 {
+			return nil
+		}
+		return []*diagnostics.Diagnostic{ctx.MakeTemplateDiagnostic(span, fmt.Sprintf(
+			"A structural directive `%s` was used in the template without a corresponding import in the component. "+
+				"Make sure that the directive is included in the `@Component.imports` array of this component.",
+			name))}
+	}
+	return nil
+}
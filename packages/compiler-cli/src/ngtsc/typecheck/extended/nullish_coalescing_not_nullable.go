@@ -0,0 +1,32 @@
+package extended
+
+import (
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck"
+	"ngc-go/packages/compiler/src/expression_parser"
+)
+
+// NullishCoalescingNotNullableCheck reports `??` operations whose left side is never null, so
+// that the right side is never used. It needs TypeInfo.
+type NullishCoalescingNotNullableCheck struct{}
+
+func (NullishCoalescingNotNullableCheck) Code() diagnostics.ErrorCode {
+	return diagnostics.NullishCoalescingNotNullable
+}
+func (NullishCoalescingNotNullableCheck) Name() string { return "nullishCoalescingNotNullable" }
+
+func (c NullishCoalescingNotNullableCheck) Run(ctx *TemplateContext) []*diagnostics.Diagnostic {
+	if ctx.Types == nil {
+		return nil
+	}
+	return VisitTemplate(ctx, c)
+}
+
+func (NullishCoalescingNotNullableCheck) VisitNode(ctx *TemplateContext, node interface{}) []*diagnostics.Diagnostic {
+	binary, ok := node.(*expression_parser.Binary)
+	if !ok || binary.Operation != "??" || ctx.Nullability(binary.Left) != typecheck.NonNullable {
+		return nil
+	}
+	return []*diagnostics.Diagnostic{ctx.MakeTemplateDiagnostic(ctx.Span(binary.SourceSpan()),
+		"the left side of this nullish coalescing operation does not include 'null' or 'undefined' in its type, therefore the '??' operator can be safely removed.")}
+}
@@ -0,0 +1,37 @@
+package extended
+
+import (
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+const ngSkipHydrationAttrName = "ngSkipHydration"
+
+// SkipHydrationNotStaticCheck reports `ngSkipHydration` used as a binding or with a value
+// other than `"true"` or `""`. The attribute is only read statically by the compiler.
+type SkipHydrationNotStaticCheck struct{}
+
+func (SkipHydrationNotStaticCheck) Code() diagnostics.ErrorCode {
+	return diagnostics.SkipHydrationNotStatic
+}
+func (SkipHydrationNotStaticCheck) Name() string { return "skipHydrationNotStatic" }
+
+func (c SkipHydrationNotStaticCheck) Run(ctx *TemplateContext) []*diagnostics.Diagnostic {
+	return VisitTemplate(ctx, c)
+}
+
+func (SkipHydrationNotStaticCheck) VisitNode(ctx *TemplateContext, node interface{}) []*diagnostics.Diagnostic {
+	switch n := node.(type) {
+	case *render3.BoundAttribute:
+		if n.Name == ngSkipHydrationAttrName {
+			return []*diagnostics.Diagnostic{ctx.MakeTemplateDiagnostic(n.SourceSpan(),
+				"ngSkipHydration should not be used as a binding.")}
+		}
+	case *render3.TextAttribute:
+		if n.Name == ngSkipHydrationAttrName && n.Value != "true" && n.Value != "" {
+			return []*diagnostics.Diagnostic{ctx.MakeTemplateDiagnostic(n.SourceSpan(),
+				`ngSkipHydration only accepts "true" or "" as value or no value at all. For example 'ngSkipHydration="true"' or 'ngSkipHydration'`)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package extended
+
+import (
+	"fmt"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+// TextAttributeNotBindingCheck reports static attributes which look like attribute, class or
+// style bindings, e.g. `attr.id="value"` instead of `[attr.id]="value"`.
+type TextAttributeNotBindingCheck struct{}
+
+func (TextAttributeNotBindingCheck) Code() diagnostics.ErrorCode {
+	return diagnostics.TextAttributeNotBinding
+}
+func (TextAttributeNotBindingCheck) Name() string { return "textAttributeNotBinding" }
+
+func (c TextAttributeNotBindingCheck) Run(ctx *TemplateContext) []*diagnostics.Diagnostic {
+	return VisitTemplate(ctx, c)
+}
+
+func (TextAttributeNotBindingCheck) VisitNode(ctx *TemplateContext, node interface{}) []*diagnostics.Diagnostic {
+	attr, ok := node.(*render3.TextAttribute)
+	if !ok {
+		return nil
+	}
+	name := attr.Name
+	if !strings.HasPrefix(name, "attr.") && !strings.HasPrefix(name, "style.") && !strings.HasPrefix(name, "class.") {
+		return nil
+	}
+
+	var message string
+	if strings.HasPrefix(name, "attr.") {
+		message = "Static attributes should be written without the 'attr.' prefix."
+		if attr.Value != "" {
+			message += fmt.Sprintf(" For example, %s=\"%s\".", strings.TrimPrefix(name, "attr."), attr.Value)
+		}
+	} else {
+		// true and false keep their logical value when bound, anything else becomes a string.
+		value := attr.Value
+		if value != "true" && value != "false" {
+			value = "'" + value + "'"
+		}
+		message = "Attribute, style, and class bindings should be enclosed with square braces."
+		if attr.Value != "" {
+			message += fmt.Sprintf(" For example, '[%s]=\"%s\"'.", name, value)
+		}
+	}
+	return []*diagnostics.Diagnostic{ctx.MakeTemplateDiagnostic(attr.SourceSpan(), message)}
+}
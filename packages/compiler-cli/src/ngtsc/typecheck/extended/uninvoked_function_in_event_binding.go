@@ -0,0 +1,58 @@
+package extended
+
+import (
+	"fmt"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+// UninvokedFunctionInEventBindingCheck reports event handlers which only read a method of the
+// component instead of calling it, e.g. `(click)="save"`. It needs TypeInfo.
+type UninvokedFunctionInEventBindingCheck struct{}
+
+func (UninvokedFunctionInEventBindingCheck) Code() diagnostics.ErrorCode {
+	return diagnostics.UninvokedFunctionInEventBinding
+}
+func (UninvokedFunctionInEventBindingCheck) Name() string { return "uninvokedFunctionInEventBinding" }
+
+func (c UninvokedFunctionInEventBindingCheck) Run(ctx *TemplateContext) []*diagnostics.Diagnostic {
+	if ctx.Types == nil {
+		return nil
+	}
+	return VisitTemplate(ctx, c)
+}
+
+func (UninvokedFunctionInEventBindingCheck) VisitNode(ctx *TemplateContext, node interface{}) []*diagnostics.Diagnostic {
+	event, ok := node.(*render3.BoundEvent)
+	if !ok {
+		return nil
+	}
+	if event.Type != expression_parser.ParsedEventTypeRegular && event.Type != expression_parser.ParsedEventTypeAnimation {
+		return nil
+	}
+	handler := event.Handler
+	if withSource, ok := handler.(*expression_parser.ASTWithSource); ok {
+		handler = withSource.AST
+	}
+	statements := []expression_parser.AST{handler}
+	if chain, ok := handler.(*expression_parser.Chain); ok {
+		statements = chain.Expressions
+	}
+
+	var diags []*diagnostics.Diagnostic
+	for _, statement := range statements {
+		switch statement.(type) {
+		case *expression_parser.PropertyRead, *expression_parser.SafePropertyRead:
+		default:
+			continue
+		}
+		if ctx.MemberKindOf(statement) != MemberMethod {
+			continue
+		}
+		diags = append(diags, ctx.MakeTemplateDiagnostic(event.SourceSpan(), fmt.Sprintf(
+			"Function in event binding should be invoked: %s()", ctx.Text(statement))))
+	}
+	return diags
+}
@@ -0,0 +1,49 @@
+package extended
+
+import (
+	"fmt"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+// UnusedLetDeclarationCheck reports `@let` declarations which are never read.
+type UnusedLetDeclarationCheck struct{}
+
+func (UnusedLetDeclarationCheck) Code() diagnostics.ErrorCode {
+	return diagnostics.UnusedLetDeclaration
+}
+func (UnusedLetDeclarationCheck) Name() string { return "unusedLetDeclaration" }
+
+func (UnusedLetDeclarationCheck) Run(ctx *TemplateContext) []*diagnostics.Diagnostic {
+	var declarations []*render3.LetDeclaration
+	used := make(map[*render3.LetDeclaration]bool)
+	VisitTemplate(ctx, nodeCheckFunc(func(ctx *TemplateContext, node interface{}) []*diagnostics.Diagnostic {
+		switch n := node.(type) {
+		case *render3.LetDeclaration:
+			declarations = append(declarations, n)
+		case *expression_parser.PropertyRead, *expression_parser.SafePropertyRead:
+			if decl, ok := ctx.Bound.GetExpressionTarget(n.(expression_parser.AST)).(*render3.LetDeclaration); ok {
+				used[decl] = true
+			}
+		}
+		return nil
+	}))
+
+	var diags []*diagnostics.Diagnostic
+	for _, decl := range declarations {
+		if !used[decl] {
+			diags = append(diags, ctx.MakeTemplateDiagnostic(decl.SourceSpan(), fmt.Sprintf(
+				"@let %s is declared but its value is never read.", decl.Name)))
+		}
+	}
+	return diags
+}
+
+// nodeCheckFunc adapts a function to the NodeCheck interface.
+type nodeCheckFunc func(ctx *TemplateContext, node interface{}) []*diagnostics.Diagnostic
+
+func (f nodeCheckFunc) VisitNode(ctx *TemplateContext, node interface{}) []*diagnostics.Diagnostic {
+	return f(ctx, node)
+}
@@ -0,0 +1,517 @@
+package typecheck
+
+import (
+	"fmt"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/view"
+)
+
+// Nullability describes whether an expression can evaluate to `null` or `undefined`.
+type Nullability int
+
+const (
+	// NullabilityUnknown is used when nothing is known about the value. No diagnostics are
+	// reported for values of unknown nullability.
+	NullabilityUnknown Nullability = iota
+	// NonNullable values are never `null` or `undefined`.
+	NonNullable
+	// Nullable values may be `null` or `undefined`.
+	Nullable
+)
+
+// NullabilityResolver provides the nullability of the members of a component, which is only
+// known to the TypeScript program declaring it.
+type NullabilityResolver interface {
+	// Nullability returns the nullability of the member at the given path of the component,
+	// e.g. `["user", "name"]` for `user.name`, as declared by its type.
+	Nullability(path []string) Nullability
+}
+
+// MemberNullability is a `NullabilityResolver` backed by a map from dotted member paths to
+// their nullability, e.g. `{"user": Nullable, "user.name": NonNullable}`.
+type MemberNullability map[string]Nullability
+
+// Nullability implements NullabilityResolver.
+func (m MemberNullability) Nullability(path []string) Nullability {
+	return m[strings.Join(path, ".")]
+}
+
+// CheckNullSafety implements the null-safety checks of `strictTemplates`. It reports safe
+// navigation (`?.`) on receivers which are never null and non-null assertions (`!`) on values
+// which are never null.
+//
+// Values are narrowed the way TypeScript narrows the type-check block: in the branches of `@if`
+// and `@switch` blocks, and in all the views nested in them, the values tested by the conditions
+// are known not to be null. `@if (expr; as alias)` aliases and the implicit variables of `@for`
+// are never null, everything else is resolved through the given resolver.
+func CheckNullSafety(bound view.BoundTarget, resolver NullabilityResolver) []*diagnostics.Diagnostic {
+	return runNullChecker(bound, resolver).diagnostics
+}
+
+// NullabilityAnalysis holds the nullability of the expressions of a template, taking the
+// narrowing of control flow blocks into account.
+type NullabilityAnalysis struct {
+	results map[expression_parser.AST]Nullability
+}
+
+// Of returns the nullability of an expression of the analyzed template.
+func (a *NullabilityAnalysis) Of(ast expression_parser.AST) Nullability {
+	return a.results[ast]
+}
+
+// AnalyzeNullability computes the nullability of every expression of a template the way
+// `CheckNullSafety` does.
+func AnalyzeNullability(bound view.BoundTarget, resolver NullabilityResolver) *NullabilityAnalysis {
+	return &NullabilityAnalysis{results: runNullChecker(bound, resolver).results}
+}
+
+func runNullChecker(bound view.BoundTarget, resolver NullabilityResolver) *nullChecker {
+	checker := &nullChecker{
+		bound:    bound,
+		resolver: resolver,
+		entities: make(map[interface{}]Nullability),
+		narrowed: &narrowing{},
+		results:  make(map[expression_parser.AST]Nullability),
+	}
+	var nodes []render3.Node
+	if target := bound.Target(); target != nil {
+		nodes = target.Template
+	}
+	file := findTemplateFile(nodes)
+	if file == nil {
+		return checker
+	}
+	checker.locator = newSourceLocator(file)
+	checker.visitAll(nodes)
+	return checker
+}
+
+// narrowing is the set of values known not to be null in a view and the views nested in it.
+type narrowing struct {
+	parent *narrowing
+	paths  map[string]bool
+}
+
+func (n *narrowing) has(key string) bool {
+	for scope := n; scope != nil; scope = scope.parent {
+		if scope.paths[key] {
+			return true
+		}
+	}
+	return false
+}
+
+type nullChecker struct {
+	bound    view.BoundTarget
+	resolver NullabilityResolver
+	locator  *sourceLocator
+	// entities holds the nullability of template variables and `@let` declarations.
+	entities    map[interface{}]Nullability
+	narrowed    *narrowing
+	results     map[expression_parser.AST]Nullability
+	diagnostics []*diagnostics.Diagnostic
+}
+
+func (c *nullChecker) visitAll(nodes []render3.Node) {
+	for _, node := range nodes {
+		c.visitNode(node)
+	}
+}
+
+// visitNarrowed visits nodes with the values tested by the conditions narrowed.
+func (c *nullChecker) visitNarrowed(nodes []render3.Node, conditions []expression_parser.AST, truthy []bool) {
+	previous := c.narrowed
+	c.narrowed = &narrowing{parent: previous, paths: make(map[string]bool)}
+	for i, condition := range conditions {
+		c.narrow(condition, truthy[i])
+	}
+	c.visitAll(nodes)
+	c.narrowed = previous
+}
+
+func (c *nullChecker) visitNode(node render3.Node) {
+	switch n := node.(type) {
+	case *render3.Element:
+		c.visitBindings(n.Inputs, n.Outputs)
+		c.visitAll(n.Children)
+	case *render3.Template:
+		c.visitBindings(n.Inputs, n.Outputs)
+		for _, attr := range n.TemplateAttrs {
+			if input, ok := attr.(*render3.BoundAttribute); ok {
+				c.checkExpression(input.Value)
+			}
+		}
+		c.visitAll(n.Children)
+	case *render3.Content:
+		c.visitAll(n.Children)
+	case *render3.Component:
+		c.visitAll(n.Children)
+	case *render3.BoundText:
+		c.checkExpression(n.Value)
+	case *render3.Icu:
+		for _, boundText := range n.Vars {
+			c.checkExpression(boundText.Value)
+		}
+		for _, placeholder := range n.Placeholders {
+			if boundText, ok := placeholder.(*render3.BoundText); ok {
+				c.checkExpression(boundText.Value)
+			}
+		}
+	case *render3.LetDeclaration:
+		c.checkExpression(n.Value)
+		c.entities[n] = c.nullabilityOf(n.Value)
+	case *render3.IfBlock:
+		var conditions []expression_parser.AST
+		var truthy []bool
+		for _, branch := range n.Branches {
+			if branch.Expression == nil {
+				c.visitNarrowed(branch.Children, conditions, truthy)
+				break
+			}
+			c.checkExpression(branch.Expression)
+			if branch.ExpressionAlias != nil {
+				c.entities[branch.ExpressionAlias] = NonNullable
+			}
+			c.visitNarrowed(branch.Children, append(conditions, branch.Expression), append(truthy, true))
+			// Later branches are only rendered when the condition is falsy.
+			conditions = append(conditions, branch.Expression)
+			truthy = append(truthy, false)
+		}
+	case *render3.SwitchBlock:
+		c.checkExpression(n.Expression)
+		for _, switchCase := range n.Cases {
+			if switchCase.Expression == nil {
+				c.visitAll(switchCase.Children)
+				continue
+			}
+			c.checkExpression(switchCase.Expression)
+			var conditions []expression_parser.AST
+			// Matching a value which is never null narrows the switch expression.
+			if c.nullabilityOf(switchCase.Expression) == NonNullable {
+				conditions = append(conditions, n.Expression)
+			}
+			c.visitNarrowed(switchCase.Children, conditions, []bool{true})
+		}
+	case *render3.ForLoopBlock:
+		if n.Expression != nil {
+			c.checkExpression(n.Expression)
+		}
+		for _, variable := range n.ContextVariables {
+			c.entities[variable] = NonNullable
+		}
+		if n.TrackBy != nil {
+			c.checkExpression(n.TrackBy)
+		}
+		c.visitAll(n.Children)
+		if n.Empty != nil {
+			c.visitAll(n.Empty.Children)
+		}
+	case *render3.DeferredBlock:
+		for _, triggers := range []*render3.DeferredBlockTriggers{n.Triggers, n.PrefetchTriggers, n.HydrateTriggers} {
+			if triggers != nil && triggers.When != nil {
+				c.checkExpression(triggers.When.Value)
+			}
+		}
+		c.visitAll(n.Children)
+		if n.Placeholder != nil {
+			c.visitAll(n.Placeholder.Children)
+		}
+		if n.Loading != nil {
+			c.visitAll(n.Loading.Children)
+		}
+		if n.Error != nil {
+			c.visitAll(n.Error.Children)
+		}
+	}
+}
+
+func (c *nullChecker) visitBindings(inputs []*render3.BoundAttribute, outputs []*render3.BoundEvent) {
+	for _, input := range inputs {
+		c.checkExpression(input.Value)
+	}
+	for _, output := range outputs {
+		c.checkExpression(output.Handler)
+	}
+}
+
+// checkExpression reports the unnecessary safe navigations and non-null assertions of an
+// expression and its sub-expressions.
+func (c *nullChecker) checkExpression(ast expression_parser.AST) {
+	WalkExpression(ast, func(node expression_parser.AST) {
+		c.results[node] = c.nullabilityOf(node)
+		switch e := node.(type) {
+		case *expression_parser.SafePropertyRead:
+			c.checkSafeNavigation(e, e.Receiver)
+		case *expression_parser.SafeKeyedRead:
+			c.checkSafeNavigation(e, e.Receiver)
+		case *expression_parser.SafeCall:
+			c.checkSafeNavigation(e, e.Receiver)
+		case *expression_parser.NonNullAssert:
+			if c.nullabilityOf(e.Expression) != NonNullable {
+				return
+			}
+			c.report(diagnostics.UnnecessaryNonNullAssertion, e, e.Expression,
+				"the operand of this non-null assertion does not include 'null' or 'undefined' in its type, therefore the '!' operator can be removed.")
+		}
+	})
+}
+
+func (c *nullChecker) checkSafeNavigation(node expression_parser.AST, receiver expression_parser.AST) {
+	if c.nullabilityOf(receiver) != NonNullable {
+		return
+	}
+	c.report(diagnostics.OptionalChainNotNullable, node, receiver,
+		"the left side of this optional chain operation does not include 'null' or 'undefined' in its type, therefore the '?.' operator can be replaced with the '.' operator.")
+}
+
+// report reports a diagnostic for the operator of node, i.e. the part of node following its
+// operand.
+func (c *nullChecker) report(code diagnostics.ErrorCode, node expression_parser.AST, operand expression_parser.AST, message string) {
+	span, operandSpan := node.SourceSpan(), operand.SourceSpan()
+	if span == nil {
+		return
+	}
+	start := span.Start
+	if operandSpan != nil && operandSpan.End > start && operandSpan.End < span.End {
+		start = operandSpan.End
+	}
+	c.diagnostics = append(c.diagnostics, diagnostics.MakeDiagnostic(
+		code, diagnostics.CategoryWarning, c.locator.span(start, span.End), message))
+}
+
+// nullabilityOf returns the nullability of the value of an expression.
+func (c *nullChecker) nullabilityOf(ast expression_parser.AST) Nullability {
+	switch e := ast.(type) {
+	case *expression_parser.ASTWithSource:
+		return c.nullabilityOf(e.AST)
+	case *expression_parser.ParenthesizedExpression:
+		return c.nullabilityOf(e.Expression)
+	case *expression_parser.LiteralPrimitive:
+		switch e.Value.(type) {
+		case nil, expression_parser.UndefinedValue:
+			return Nullable
+		}
+		return NonNullable
+	case *expression_parser.LiteralArray, *expression_parser.LiteralMap, *expression_parser.Interpolation,
+		*expression_parser.TemplateLiteral, *expression_parser.PrefixNot, *expression_parser.TypeofExpression,
+		*expression_parser.Unary, *expression_parser.NonNullAssert:
+		return NonNullable
+	case *expression_parser.Binary:
+		switch e.Operation {
+		case "??", "||":
+			if c.nullabilityOf(e.Right) == NonNullable {
+				return NonNullable
+			}
+			return NullabilityUnknown
+		case "&&", "=", "??=", "||=", "&&=":
+			return NullabilityUnknown
+		}
+		// Arithmetic and comparisons always produce a value.
+		return NonNullable
+	case *expression_parser.Conditional:
+		trueExp, falseExp := c.nullabilityOf(e.TrueExp), c.nullabilityOf(e.FalseExp)
+		if trueExp == falseExp {
+			return trueExp
+		}
+		if trueExp == Nullable || falseExp == Nullable {
+			return Nullable
+		}
+		return NullabilityUnknown
+	case *expression_parser.PropertyRead, *expression_parser.SafePropertyRead:
+		return c.nullabilityOfRead(e)
+	}
+	return NullabilityUnknown
+}
+
+// nullabilityOfRead returns the nullability of a property read, taking narrowing into account.
+func (c *nullChecker) nullabilityOfRead(ast expression_parser.AST) Nullability {
+	root, path, ok := c.pathOf(ast)
+	if !ok {
+		return NullabilityUnknown
+	}
+	if c.narrowed.has(pathKey(root, path)) {
+		return NonNullable
+	}
+	if safe, isSafe := ast.(*expression_parser.SafePropertyRead); isSafe {
+		// `a?.b` is undefined when `a` is null.
+		if receiver := c.nullabilityOf(safe.Receiver); receiver != NonNullable {
+			if receiver == Nullable {
+				return Nullable
+			}
+			return NullabilityUnknown
+		}
+	}
+	if root != nil {
+		if len(path) == 0 {
+			return c.entities[root]
+		}
+		// Nothing is known about the members of template entities.
+		return NullabilityUnknown
+	}
+	if c.resolver == nil {
+		return NullabilityUnknown
+	}
+	return c.resolver.Nullability(path)
+}
+
+// pathOf returns the root and the property path of a chain of property reads. The root is the
+// template entity the chain starts from, or nil for members of the component.
+func (c *nullChecker) pathOf(ast expression_parser.AST) (root interface{}, path []string, ok bool) {
+	var receiver expression_parser.AST
+	var name string
+	switch e := ast.(type) {
+	case *expression_parser.ASTWithSource:
+		return c.pathOf(e.AST)
+	case *expression_parser.ParenthesizedExpression:
+		return c.pathOf(e.Expression)
+	case *expression_parser.NonNullAssert:
+		return c.pathOf(e.Expression)
+	case *expression_parser.PropertyRead:
+		receiver, name = e.Receiver, e.Name
+	case *expression_parser.SafePropertyRead:
+		receiver, name = e.Receiver, e.Name
+	default:
+		return nil, nil, false
+	}
+
+	switch receiver.(type) {
+	case *expression_parser.ThisReceiver:
+		return nil, []string{name}, true
+	case *expression_parser.ImplicitReceiver:
+		if target := c.bound.GetExpressionTarget(ast); target != nil {
+			return target, nil, true
+		}
+		return nil, []string{name}, true
+	}
+	root, path, ok = c.pathOf(receiver)
+	if !ok {
+		return nil, nil, false
+	}
+	return root, append(append([]string{}, path...), name), true
+}
+
+func pathKey(root interface{}, path []string) string {
+	if root == nil {
+		return "this." + strings.Join(path, ".")
+	}
+	return fmt.Sprintf("%p.%s", root, strings.Join(path, "."))
+}
+
+// narrow records the values which are known not to be null when the condition evaluates to the
+// given truthiness.
+func (c *nullChecker) narrow(condition expression_parser.AST, truthy bool) {
+	switch e := condition.(type) {
+	case *expression_parser.ASTWithSource:
+		c.narrow(e.AST, truthy)
+	case *expression_parser.ParenthesizedExpression:
+		c.narrow(e.Expression, truthy)
+	case *expression_parser.PrefixNot:
+		c.narrow(e.Expression, !truthy)
+	case *expression_parser.Binary:
+		switch {
+		case e.Operation == "&&" && truthy, e.Operation == "||" && !truthy:
+			c.narrow(e.Left, truthy)
+			c.narrow(e.Right, truthy)
+		case e.Operation == "!=" && truthy, e.Operation == "==" && !truthy:
+			// `x != null` and `x != undefined` exclude both null and undefined.
+			if isNullLiteral(e.Right) {
+				c.narrowPath(e.Left)
+			} else if isNullLiteral(e.Left) {
+				c.narrowPath(e.Right)
+			}
+		}
+	default:
+		if truthy {
+			c.narrowPath(condition)
+		}
+	}
+}
+
+// narrowPath marks a property chain and all of its receivers as not null: when `a.b.c` is
+// truthy, neither `a` nor `a.b` can be null.
+func (c *nullChecker) narrowPath(ast expression_parser.AST) {
+	root, path, ok := c.pathOf(ast)
+	if !ok {
+		return
+	}
+	if root != nil {
+		c.narrowed.paths[pathKey(root, nil)] = true
+	}
+	for i := 1; i <= len(path); i++ {
+		c.narrowed.paths[pathKey(root, path[:i])] = true
+	}
+}
+
+func isNullLiteral(ast expression_parser.AST) bool {
+	literal, ok := ast.(*expression_parser.LiteralPrimitive)
+	if !ok {
+		return false
+	}
+	switch literal.Value.(type) {
+	case nil, expression_parser.UndefinedValue:
+		return true
+	}
+	return false
+}
+
+// WalkExpression calls fn for an expression and all of its sub-expressions, parents first.
+func WalkExpression(ast expression_parser.AST, fn func(ast expression_parser.AST)) {
+	if ast == nil {
+		return
+	}
+	fn(ast)
+	var children []expression_parser.AST
+	switch e := ast.(type) {
+	case *expression_parser.ASTWithSource:
+		children = []expression_parser.AST{e.AST}
+	case *expression_parser.PropertyRead:
+		children = []expression_parser.AST{e.Receiver}
+	case *expression_parser.SafePropertyRead:
+		children = []expression_parser.AST{e.Receiver}
+	case *expression_parser.KeyedRead:
+		children = []expression_parser.AST{e.Receiver, e.Key}
+	case *expression_parser.SafeKeyedRead:
+		children = []expression_parser.AST{e.Receiver, e.Key}
+	case *expression_parser.Call:
+		children = append([]expression_parser.AST{e.Receiver}, e.Args...)
+	case *expression_parser.SafeCall:
+		children = append([]expression_parser.AST{e.Receiver}, e.Args...)
+	case *expression_parser.BindingPipe:
+		children = append([]expression_parser.AST{e.Exp}, e.Args...)
+	case *expression_parser.Unary:
+		children = []expression_parser.AST{e.Expr}
+	case *expression_parser.Binary:
+		children = []expression_parser.AST{e.Left, e.Right}
+	case *expression_parser.Conditional:
+		children = []expression_parser.AST{e.Condition, e.TrueExp, e.FalseExp}
+	case *expression_parser.PrefixNot:
+		children = []expression_parser.AST{e.Expression}
+	case *expression_parser.TypeofExpression:
+		children = []expression_parser.AST{e.Expression}
+	case *expression_parser.VoidExpression:
+		children = []expression_parser.AST{e.Expression}
+	case *expression_parser.NonNullAssert:
+		children = []expression_parser.AST{e.Expression}
+	case *expression_parser.ParenthesizedExpression:
+		children = []expression_parser.AST{e.Expression}
+	case *expression_parser.LiteralArray:
+		children = e.Expressions
+	case *expression_parser.LiteralMap:
+		children = e.Values
+	case *expression_parser.Interpolation:
+		children = e.Expressions
+	case *expression_parser.TemplateLiteral:
+		children = e.Expressions
+	case *expression_parser.TaggedTemplateLiteral:
+		children = append([]expression_parser.AST{e.Tag}, e.Template.Expressions...)
+	case *expression_parser.Chain:
+		children = e.Expressions
+	}
+	for _, child := range children {
+		WalkExpression(child, fn)
+	}
+}
@@ -0,0 +1,177 @@
+package typecheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// SourceMapping maps a range of generated type-check code back to the template expression it
+// was generated from. Generated offsets are relative to the code of the type-check block, source
+// offsets are absolute offsets into the template file.
+type SourceMapping struct {
+	GeneratedStart int
+	GeneratedEnd   int
+	SourceStart    int
+	SourceEnd      int
+}
+
+// tcbWriter accumulates generated code and the mappings of the expressions written to it.
+type tcbWriter struct {
+	sb       strings.Builder
+	indent   int
+	mappings []*SourceMapping
+	// ignoreSpans disables mappings and span comments, for code that is repeated from
+	// elsewhere in the block.
+	ignoreSpans bool
+}
+
+func (w *tcbWriter) len() int {
+	return w.sb.Len()
+}
+
+func (w *tcbWriter) write(parts ...string) {
+	for _, part := range parts {
+		w.sb.WriteString(part)
+	}
+}
+
+// newline starts a new line at the current indentation.
+func (w *tcbWriter) newline() {
+	w.sb.WriteString("\n")
+	w.sb.WriteString(strings.Repeat("  ", w.indent))
+}
+
+// openBlock writes `{` after the given prefix and indents the following lines.
+func (w *tcbWriter) openBlock(prefix string) {
+	w.newline()
+	w.write(prefix, "{")
+	w.indent++
+}
+
+// closeBlock closes a block opened with openBlock.
+func (w *tcbWriter) closeBlock() {
+	w.indent--
+	w.newline()
+	w.write("}")
+}
+
+// addSpanInfo records that the code written since generatedStart was generated from the given
+// template span, and writes the span as a trailing `/*start,end*/` comment the way Angular's
+// type-check blocks do.
+func (w *tcbWriter) addSpanInfo(generatedStart int, span *expression_parser.AbsoluteSourceSpan) {
+	if span == nil || w.ignoreSpans {
+		return
+	}
+	w.mappings = append(w.mappings, &SourceMapping{
+		GeneratedStart: generatedStart,
+		GeneratedEnd:   w.len(),
+		SourceStart:    span.Start,
+		SourceEnd:      span.End,
+	})
+	w.write(fmt.Sprintf(" /*%d,%d*/", span.Start, span.End))
+}
+
+// addParseSpanInfo is addSpanInfo for spans of template nodes.
+func (w *tcbWriter) addParseSpanInfo(generatedStart int, span *util.ParseSourceSpan) {
+	if span == nil || span.Start == nil || span.End == nil {
+		return
+	}
+	w.addSpanInfo(generatedStart, expression_parser.NewAbsoluteSourceSpan(span.Start.Offset, span.End.Offset))
+}
+
+// findMapping returns the narrowest mapping containing the generated offset.
+func findMapping(mappings []*SourceMapping, offset int) *SourceMapping {
+	var best *SourceMapping
+	for _, mapping := range mappings {
+		if offset < mapping.GeneratedStart || offset >= mapping.GeneratedEnd {
+			continue
+		}
+		if best == nil || mapping.GeneratedEnd-mapping.GeneratedStart < best.GeneratedEnd-best.GeneratedStart {
+			best = mapping
+		}
+	}
+	return best
+}
+
+// sourceLocator converts absolute offsets of a template file into `ParseSourceSpan`s.
+type sourceLocator struct {
+	file       *util.ParseSourceFile
+	lineStarts []int
+}
+
+func newSourceLocator(file *util.ParseSourceFile) *sourceLocator {
+	lineStarts := []int{0}
+	for i := 0; i < len(file.Content); i++ {
+		if file.Content[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	return &sourceLocator{file: file, lineStarts: lineStarts}
+}
+
+func (l *sourceLocator) location(offset int) *util.ParseLocation {
+	if offset > len(l.file.Content) {
+		offset = len(l.file.Content)
+	}
+	line := sort.Search(len(l.lineStarts), func(i int) bool { return l.lineStarts[i] > offset }) - 1
+	return util.NewParseLocation(l.file, offset, line, offset-l.lineStarts[line])
+}
+
+func (l *sourceLocator) span(start int, end int) *util.ParseSourceSpan {
+	return util.NewParseSourceSpan(l.location(start), l.location(end), nil, nil)
+}
+
+// quoteString returns a TypeScript string literal for the value.
+func quoteString(value string) string {
+	var sb strings.Builder
+	encoder := json.NewEncoder(&sb)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// isIdentifier reports whether the name can be used as a property name after a dot.
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, ch := range name {
+		if ch == '_' || ch == '$' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') {
+			continue
+		}
+		if i > 0 && ch >= '0' && ch <= '9' {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// propertyAccess returns `.name`, or `["name"]` when the name isn't a valid identifier.
+func propertyAccess(name string) string {
+	if isIdentifier(name) {
+		return "." + name
+	}
+	return "[" + quoteString(name) + "]"
+}
+
+// formatNumber formats a numeric literal of the expression AST.
+func formatNumber(value interface{}) string {
+	switch v := value.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	}
+	return fmt.Sprint(value)
+}
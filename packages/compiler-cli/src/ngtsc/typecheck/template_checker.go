@@ -0,0 +1,314 @@
+package typecheck
+
+import (
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/core"
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/view"
+	"ngc-go/packages/compiler/src/schema"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// TemplateCheckOptions configures `CheckTemplate`.
+type TemplateCheckOptions struct {
+	// Schemas of the component (or the NgModule declaring it), e.g. `CUSTOM_ELEMENTS_SCHEMA`.
+	Schemas []*core.SchemaMetadata
+
+	// HostIsStandalone is whether the component owning the template is standalone. Only affects
+	// the wording of the diagnostics.
+	HostIsStandalone bool
+
+	// Registry is the DOM schema to check against. A fresh `DomElementSchemaRegistry` is used
+	// when nil.
+	Registry *schema.DomElementSchemaRegistry
+	// StrictNullChecks enables the null-safety checks of `strictTemplates`, see
+	// `CheckNullSafety`.
+	StrictNullChecks bool
+
+	// Nullability resolves the nullability of component members for StrictNullChecks.
+	Nullability NullabilityResolver
+}
+
+// CheckTemplate validates the elements, property bindings and event bindings of a bound template
+// against the directives matched by the binder and the DOM schema. It reports elements that are
+// neither known DOM elements nor components, property bindings that are neither directive inputs
+// nor DOM properties, and event bindings that are neither directive outputs nor DOM events.
+// With StrictNullChecks it also runs `CheckNullSafety`.
+func CheckTemplate(bound view.BoundTarget, options *TemplateCheckOptions) []*diagnostics.Diagnostic {
+	if options == nil {
+		options = &TemplateCheckOptions{}
+	}
+	checker := NewRegistryDomSchemaChecker(options.Registry)
+	visitor := &templateCheckVisitor{
+		bound:   bound,
+		checker: checker,
+		options: options,
+	}
+	target := bound.Target()
+	if target != nil && target.Template != nil {
+		visitor.visitAll(target.Template)
+	}
+	diags := checker.Diagnostics()
+	if options.StrictNullChecks {
+		diags = append(diags, CheckNullSafety(bound, options.Nullability)...)
+	}
+	return diags
+}
+
+// templateCheckVisitor walks the R3 AST of a template and hands every element and its bindings
+// over to a `DomSchemaChecker`.
+type templateCheckVisitor struct {
+	bound   view.BoundTarget
+	checker DomSchemaChecker
+	options *TemplateCheckOptions
+}
+
+func (v *templateCheckVisitor) visitAll(nodes []render3.Node) {
+	for _, node := range nodes {
+		if node != nil {
+			node.Visit(v)
+		}
+	}
+}
+
+// Visit visits a node
+func (v *templateCheckVisitor) Visit(node render3.Node) interface{} {
+	return node.Visit(v)
+}
+
+// VisitElement checks the element itself, its unclaimed property bindings and its unclaimed
+// event bindings.
+func (v *templateCheckVisitor) VisitElement(element *render3.Element) interface{} {
+	directives := v.bound.GetDirectivesOfNode(element)
+	if !hasComponent(directives) {
+		v.checker.CheckElement(element, v.options.Schemas, v.options.HostIsStandalone)
+	}
+
+	v.checkBindings(element, element.Name, element.Inputs, element.Outputs)
+	v.visitAll(element.Children)
+	return nil
+}
+
+// checkBindings checks the property and event bindings of an element or template which none of
+// its directives consume.
+func (v *templateCheckVisitor) checkBindings(node render3.Node, tagName string, inputs []*render3.BoundAttribute, outputs []*render3.BoundEvent) {
+	for _, input := range inputs {
+		if input.Type != expression_parser.BindingTypeProperty && input.Type != expression_parser.BindingTypeTwoWay {
+			continue
+		}
+		if v.isClaimed(node, input) {
+			continue
+		}
+		// `[style]` and `[class]` bindings are handled by the styling instructions.
+		if input.Name == "style" || input.Name == "class" {
+			continue
+		}
+		// A two-way binding can only ever target a directive input.
+		propertyName := input.Name
+		if input.Type == expression_parser.BindingTypeProperty {
+			propertyName = mappedPropName(v.options.Registry, input.Name)
+		}
+		v.checker.CheckTemplateElementProperty(
+			tagName,
+			propertyName,
+			spanOf(input.KeySpan, input.SourceSpan()),
+			v.options.Schemas,
+			v.options.HostIsStandalone,
+		)
+	}
+
+	for _, output := range outputs {
+		// Two-way binding events are reported through their input half, animation events are
+		// validated at runtime and events with a global target (`window:resize`) are not bound to
+		// the element.
+		if output.Type != expression_parser.ParsedEventTypeRegular || output.Target != nil {
+			continue
+		}
+		// A backwards banana-in-box, `([x])="v"`, is reported by the invalidBananaInBox check.
+		if strings.HasPrefix(output.Name, "[") && strings.HasSuffix(output.Name, "]") {
+			continue
+		}
+		if v.isClaimed(node, output) {
+			continue
+		}
+		v.checker.CheckTemplateElementEvent(
+			tagName,
+			output.Name,
+			spanOf(output.KeySpan, output.SourceSpan()),
+			v.options.Schemas,
+			v.options.HostIsStandalone,
+		)
+	}
+}
+
+// isClaimed reports whether a binding on the given element or template is consumed by one of
+// its directives.
+func (v *templateCheckVisitor) isClaimed(node render3.Node, binding interface{}) bool {
+	consumer := v.bound.GetConsumerOfBinding(binding)
+	if consumer == nil {
+		return false
+	}
+	if consumerNode, ok := consumer.(render3.Node); ok && consumerNode == node {
+		return false
+	}
+	return true
+}
+
+// VisitTemplate checks the bindings of a template which none of its directives consume, then
+// visits its children. An `<ng-template>` has no DOM properties or events, so all of its
+// unclaimed bindings are reported. The bindings of the structural directive of an inline
+// template (`*ngIf`) are reported against the element it is written on.
+func (v *templateCheckVisitor) VisitTemplate(template *render3.Template) interface{} {
+	tagName := "ng-template"
+	if template.TagName != nil {
+		tagName = *template.TagName
+	}
+	// The inputs of a template include the bindings of its template attributes.
+	v.checkBindings(template, tagName, template.Inputs, template.Outputs)
+	v.visitAll(template.Children)
+	return nil
+}
+
+// VisitContent visits the fallback content of an `<ng-content>`
+func (v *templateCheckVisitor) VisitContent(content *render3.Content) interface{} {
+	v.visitAll(content.Children)
+	return nil
+}
+
+// VisitDeferredBlock visits a deferred block and its connected blocks
+func (v *templateCheckVisitor) VisitDeferredBlock(deferred *render3.DeferredBlock) interface{} {
+	v.visitAll(deferred.Children)
+	if deferred.Placeholder != nil {
+		deferred.Placeholder.Visit(v)
+	}
+	if deferred.Loading != nil {
+		deferred.Loading.Visit(v)
+	}
+	if deferred.Error != nil {
+		deferred.Error.Visit(v)
+	}
+	return nil
+}
+
+// VisitDeferredBlockPlaceholder visits a deferred block placeholder
+func (v *templateCheckVisitor) VisitDeferredBlockPlaceholder(block *render3.DeferredBlockPlaceholder) interface{} {
+	v.visitAll(block.Children)
+	return nil
+}
+
+// VisitDeferredBlockError visits a deferred block error
+func (v *templateCheckVisitor) VisitDeferredBlockError(block *render3.DeferredBlockError) interface{} {
+	v.visitAll(block.Children)
+	return nil
+}
+
+// VisitDeferredBlockLoading visits a deferred block loading
+func (v *templateCheckVisitor) VisitDeferredBlockLoading(block *render3.DeferredBlockLoading) interface{} {
+	v.visitAll(block.Children)
+	return nil
+}
+
+// VisitSwitchBlock visits a switch block
+func (v *templateCheckVisitor) VisitSwitchBlock(block *render3.SwitchBlock) interface{} {
+	for _, c := range block.Cases {
+		c.Visit(v)
+	}
+	return nil
+}
+
+// VisitSwitchBlockCase visits a switch block case
+func (v *templateCheckVisitor) VisitSwitchBlockCase(block *render3.SwitchBlockCase) interface{} {
+	v.visitAll(block.Children)
+	return nil
+}
+
+// VisitForLoopBlock visits a for loop block
+func (v *templateCheckVisitor) VisitForLoopBlock(block *render3.ForLoopBlock) interface{} {
+	v.visitAll(block.Children)
+	if block.Empty != nil {
+		block.Empty.Visit(v)
+	}
+	return nil
+}
+
+// VisitForLoopBlockEmpty visits a for loop block empty
+func (v *templateCheckVisitor) VisitForLoopBlockEmpty(block *render3.ForLoopBlockEmpty) interface{} {
+	v.visitAll(block.Children)
+	return nil
+}
+
+// VisitIfBlock visits an if block
+func (v *templateCheckVisitor) VisitIfBlock(block *render3.IfBlock) interface{} {
+	for _, branch := range block.Branches {
+		branch.Visit(v)
+	}
+	return nil
+}
+
+// VisitIfBlockBranch visits an if block branch
+func (v *templateCheckVisitor) VisitIfBlockBranch(block *render3.IfBlockBranch) interface{} {
+	v.visitAll(block.Children)
+	return nil
+}
+
+// VisitComponent visits the children of a selectorless component
+func (v *templateCheckVisitor) VisitComponent(component *render3.Component) interface{} {
+	v.visitAll(component.Children)
+	return nil
+}
+
+func (v *templateCheckVisitor) VisitDirective(directive *render3.Directive) interface{} { return nil }
+func (v *templateCheckVisitor) VisitVariable(variable *render3.Variable) interface{}    { return nil }
+func (v *templateCheckVisitor) VisitReference(reference *render3.Reference) interface{} { return nil }
+func (v *templateCheckVisitor) VisitTextAttribute(attr *render3.TextAttribute) interface{} {
+	return nil
+}
+func (v *templateCheckVisitor) VisitBoundAttribute(attr *render3.BoundAttribute) interface{} {
+	return nil
+}
+func (v *templateCheckVisitor) VisitBoundEvent(event *render3.BoundEvent) interface{} { return nil }
+func (v *templateCheckVisitor) VisitText(text *render3.Text) interface{}              { return nil }
+func (v *templateCheckVisitor) VisitBoundText(text *render3.BoundText) interface{}    { return nil }
+func (v *templateCheckVisitor) VisitIcu(icu *render3.Icu) interface{}                 { return nil }
+func (v *templateCheckVisitor) VisitDeferredTrigger(trigger *render3.DeferredTrigger) interface{} {
+	return nil
+}
+func (v *templateCheckVisitor) VisitUnknownBlock(block *render3.UnknownBlock) interface{} { return nil }
+func (v *templateCheckVisitor) VisitLetDeclaration(decl *render3.LetDeclaration) interface{} {
+	return nil
+}
+
+// hasComponent reports whether any of the matched directives is a component.
+func hasComponent(directives []interface{}) bool {
+	for _, dir := range directives {
+		if meta, ok := dir.(view.DirectiveMeta); ok && meta.IsComponent() {
+			return true
+		}
+	}
+	return false
+}
+
+// mappedPropName maps attribute names which differ from their DOM property (e.g. `for` and
+// `htmlFor`) before the property is looked up in the schema.
+func mappedPropName(registry *schema.DomElementSchemaRegistry, name string) string {
+	if registry == nil {
+		if mapped, ok := schema.AttrToProp[name]; ok {
+			return mapped
+		}
+		return name
+	}
+	return registry.GetMappedPropName(name)
+}
+
+// spanOf returns the most precise of the given spans that is available.
+func spanOf(spans ...*util.ParseSourceSpan) *util.ParseSourceSpan {
+	for _, span := range spans {
+		if span != nil {
+			return span
+		}
+	}
+	return nil
+}
@@ -0,0 +1,928 @@
+package typecheck
+
+import (
+	"fmt"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/view"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// TypeCheckBlockMetadata describes the component a type-check block is generated for.
+type TypeCheckBlockMetadata struct {
+	// ID is the name of the generated function. A name is allocated by the `TypeCheckFile` when
+	// empty.
+	ID string
+
+	// ComponentName is the class name of the component, used as the type of `this`.
+	ComponentName string
+
+	// Pipes maps the name of every pipe available to the template to its class name.
+	Pipes map[string]string
+
+	// TemplateFile is the file the template was parsed from. It is taken from the template
+	// nodes when nil.
+	TemplateFile *util.ParseSourceFile
+}
+
+// TemplateGuardType is the kind of a `ngTemplateGuard_` declared by a structural directive.
+type TemplateGuardType string
+
+const (
+	// TemplateGuardInvocation narrows through a call to the static guard function,
+	// e.g. `static ngTemplateGuard_ngIf<T>(dir: NgIf<T>, expr: T): expr is NonNullable<T>`.
+	TemplateGuardInvocation TemplateGuardType = "invocation"
+	// TemplateGuardBinding narrows using the input expression itself,
+	// e.g. `static ngTemplateGuard_ngIf: 'binding'`.
+	TemplateGuardBinding TemplateGuardType = "binding"
+)
+
+// TemplateGuardMeta describes a `ngTemplateGuard_<input>` of a structural directive.
+type TemplateGuardMeta struct {
+	InputName string
+	Type      TemplateGuardType
+}
+
+// DirectiveFieldMapping can be implemented by a `view.DirectiveMeta` whose inputs or outputs are
+// declared on class properties with a different name than the binding, e.g. `@Input('alias')`.
+// Binding names are used as property names otherwise.
+type DirectiveFieldMapping interface {
+	InputFieldName(bindingName string) string
+	OutputFieldName(bindingName string) string
+}
+
+// DirectiveTemplateGuards can be implemented by the `view.DirectiveMeta` of structural
+// directives which declare template guards, so that the type-check block narrows the types
+// of expressions in their templates the way Angular does.
+type DirectiveTemplateGuards interface {
+	// HasNgTemplateContextGuard returns whether the directive declares a static
+	// `ngTemplateContextGuard`, which types the context of its template.
+	HasNgTemplateContextGuard() bool
+	// NgTemplateGuards returns the `ngTemplateGuard_` members of the directive.
+	NgTemplateGuards() []TemplateGuardMeta
+}
+
+// TypeCheckBlock is the generated type-check code of a single component template.
+type TypeCheckBlock struct {
+	// Name is the name of the generated function.
+	Name string
+	// Code is the source of the generated function.
+	Code string
+	// Mappings map ranges of Code back to the template.
+	Mappings []*SourceMapping
+	// Diagnostics are the problems found while generating the block, which TypeScript could not
+	// report itself, e.g. pipes that are not available to the template.
+	Diagnostics []*diagnostics.Diagnostic
+
+	locator *sourceLocator
+	offset  int
+}
+
+// SourceSpanOf maps an offset of Code back to the span of the template expression it was
+// generated from. It returns nil for code which doesn't correspond to a template expression.
+func (b *TypeCheckBlock) SourceSpanOf(offset int) *util.ParseSourceSpan {
+	mapping := findMapping(b.Mappings, offset)
+	if mapping == nil || b.locator == nil {
+		return nil
+	}
+	return b.locator.span(mapping.SourceStart, mapping.SourceEnd)
+}
+
+// GenerateTypeCheckBlock generates the type-check block of a single template. Classes are
+// referenced by name and `@angular/core` through the `i0` namespace, i.e. the block is meant to
+// be placed in a file where those are in scope. Use a `TypeCheckFile` to generate a standalone
+// file.
+func GenerateTypeCheckBlock(bound view.BoundTarget, meta *TypeCheckBlockMetadata) *TypeCheckBlock {
+	return generateTypeCheckBlock(bound, meta, inlineReferences{})
+}
+
+// referenceEmitter resolves the expressions used to refer to classes in a type-check block.
+type referenceEmitter interface {
+	// reference returns the expression of a user class.
+	reference(className string) string
+	// coreReference returns the expression of a symbol of `@angular/core`.
+	coreReference(name string) string
+}
+
+type inlineReferences struct{}
+
+func (inlineReferences) reference(className string) string { return className }
+func (inlineReferences) coreReference(name string) string  { return "i0." + name }
+
+// tcbGenerator generates the code of a type-check block.
+//
+// The structure of the block follows the template: every element with bindings that need it is
+// created with `document.createElement`, every matched directive is declared as a variable of
+// its class, embedded views become nested blocks guarded by the conditions which narrow them
+// (`@if`, `@switch`, template guards) and `@for` becomes a `for...of` loop.
+type tcbGenerator struct {
+	bound   view.BoundTarget
+	meta    *TypeCheckBlockMetadata
+	refs    referenceEmitter
+	w       *tcbWriter
+	locator *sourceLocator
+
+	nextID int
+	// elements holds the identifiers of the elements that are created in the block.
+	elements map[render3.Node]string
+	// referencedElements are the elements that are the target of a template reference and
+	// need to be created even when they have no listeners.
+	referencedElements map[render3.Node]bool
+	directives         map[directiveKey]string
+	templateContexts   map[*render3.Template]string
+	variables          map[*render3.Variable]string
+	lets               map[*render3.LetDeclaration]string
+	pipes              map[string]string
+	pipeOrder          []string
+	inListener         bool
+	// guards are the conditions of the `@if` and `@switch` branches enclosing the current node.
+	// TypeScript doesn't narrow properties inside of closures, so listeners repeat them.
+	guards      []string
+	diagnostics []*diagnostics.Diagnostic
+}
+
+// directiveKey identifies the instance of a directive on a node.
+type directiveKey struct {
+	node      render3.Node
+	directive view.DirectiveMeta
+}
+
+func generateTypeCheckBlock(bound view.BoundTarget, meta *TypeCheckBlockMetadata, refs referenceEmitter) *TypeCheckBlock {
+	g := &tcbGenerator{
+		bound:              bound,
+		meta:               meta,
+		refs:               refs,
+		w:                  &tcbWriter{indent: 1},
+		elements:           make(map[render3.Node]string),
+		referencedElements: make(map[render3.Node]bool),
+		directives:         make(map[directiveKey]string),
+		templateContexts:   make(map[*render3.Template]string),
+		variables:          make(map[*render3.Variable]string),
+		lets:               make(map[*render3.LetDeclaration]string),
+		pipes:              make(map[string]string),
+	}
+
+	var nodes []render3.Node
+	if target := bound.Target(); target != nil {
+		nodes = target.Template
+	}
+	templateFile := meta.TemplateFile
+	if templateFile == nil {
+		templateFile = findTemplateFile(nodes)
+	}
+	if templateFile != nil {
+		g.locator = newSourceLocator(templateFile)
+	}
+
+	g.collectReferencedElements(nodes)
+	g.visitAll(nodes)
+
+	name := meta.ID
+	if name == "" {
+		name = "_tcb"
+	}
+	header := fmt.Sprintf("function %s(this: %s) {", name, g.refs.reference(meta.ComponentName))
+	var declarations strings.Builder
+	for _, pipeName := range g.pipeOrder {
+		declarations.WriteString(fmt.Sprintf("\n  var %s: %s = null!;", g.pipes[pipeName], g.refs.reference(g.meta.Pipes[pipeName])))
+	}
+	prefix := header + declarations.String()
+	code := prefix + g.w.sb.String() + "\n}\n"
+
+	// The mappings were recorded relative to the body.
+	for _, mapping := range g.w.mappings {
+		mapping.GeneratedStart += len(prefix)
+		mapping.GeneratedEnd += len(prefix)
+	}
+
+	return &TypeCheckBlock{
+		Name:        name,
+		Code:        code,
+		Mappings:    g.w.mappings,
+		Diagnostics: g.diagnostics,
+		locator:     g.locator,
+	}
+}
+
+// findTemplateFile returns the source file of the first node which has one.
+func findTemplateFile(nodes []render3.Node) *util.ParseSourceFile {
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		if span := node.SourceSpan(); span != nil && span.Start != nil && span.Start.File != nil {
+			return span.Start.File
+		}
+	}
+	return nil
+}
+
+// allocateID returns a new identifier for a declaration in the block.
+func (g *tcbGenerator) allocateID() string {
+	g.nextID++
+	return fmt.Sprintf("_t%d", g.nextID)
+}
+
+func (g *tcbGenerator) locateAbsolute(span *expression_parser.AbsoluteSourceSpan) *util.ParseSourceSpan {
+	if span == nil || g.locator == nil {
+		return nil
+	}
+	return g.locator.span(span.Start, span.End)
+}
+
+func (g *tcbGenerator) addDiagnostic(code diagnostics.ErrorCode, span *util.ParseSourceSpan, message string) {
+	g.diagnostics = append(g.diagnostics, diagnostics.MakeDiagnostic(code, diagnostics.CategoryError, span, message))
+}
+
+// collectReferencedElements finds the elements targeted by template references, which need a
+// variable even when nothing else requires one.
+func (g *tcbGenerator) collectReferencedElements(nodes []render3.Node) {
+	walkNodes(nodes, func(node render3.Node) {
+		var references []*render3.Reference
+		switch n := node.(type) {
+		case *render3.Element:
+			references = n.References
+		case *render3.Template:
+			references = n.References
+		}
+		for _, ref := range references {
+			if target, ok := g.bound.GetReferenceTarget(ref).(*view.ReferenceTargetElement); ok {
+				g.referencedElements[target.Element] = true
+			}
+		}
+	})
+}
+
+// resolveReference returns the expression a template reference resolves to.
+func (g *tcbGenerator) resolveReference(ref *render3.Reference) string {
+	switch target := g.bound.GetReferenceTarget(ref).(type) {
+	case *view.ReferenceTargetElement:
+		return g.elementID(target.Element)
+	case *view.ReferenceTargetTemplate:
+		return fmt.Sprintf("(null! as %s<any>)", g.refs.coreReference("TemplateRef"))
+	case *view.ReferenceTargetWithDirective:
+		if dir, ok := target.Directive.(view.DirectiveMeta); ok {
+			return g.directiveID(target.Node, dir)
+		}
+	}
+	// The reference target could not be resolved. This is reported by the binder, so the
+	// reference is typed as `any` to avoid further errors.
+	return "(null as any)"
+}
+
+func (g *tcbGenerator) elementID(node render3.Node) string {
+	if id, ok := g.elements[node]; ok {
+		return id
+	}
+	id := g.allocateID()
+	g.elements[node] = id
+	return id
+}
+
+func (g *tcbGenerator) directiveID(node render3.Node, dir view.DirectiveMeta) string {
+	key := directiveKey{node: node, directive: dir}
+	if id, ok := g.directives[key]; ok {
+		return id
+	}
+	id := g.allocateID()
+	g.directives[key] = id
+	return id
+}
+
+// pipeInstance returns the identifier of the instance of a pipe. Instances are declared at the
+// start of the block. Pipes which are not available to the template are reported and typed as
+// `any`.
+func (g *tcbGenerator) pipeInstance(pipe *expression_parser.BindingPipe) string {
+	if _, ok := g.meta.Pipes[pipe.Name]; !ok {
+		g.addDiagnostic(diagnostics.MissingPipe, g.locateAbsolute(pipe.NameSpan()),
+			fmt.Sprintf("No pipe found with name '%s'.", pipe.Name))
+		return "(null as any)"
+	}
+	if id, ok := g.pipes[pipe.Name]; ok {
+		return id
+	}
+	id := fmt.Sprintf("_pipe%d", len(g.pipeOrder)+1)
+	g.pipes[pipe.Name] = id
+	g.pipeOrder = append(g.pipeOrder, pipe.Name)
+	return id
+}
+
+// directivesOf returns the directives matched on a node.
+func (g *tcbGenerator) directivesOf(node view.DirectiveOwner) []view.DirectiveMeta {
+	var result []view.DirectiveMeta
+	for _, dir := range g.bound.GetDirectivesOfNode(node) {
+		if meta, ok := dir.(view.DirectiveMeta); ok {
+			result = append(result, meta)
+		}
+	}
+	return result
+}
+
+func (g *tcbGenerator) visitAll(nodes []render3.Node) {
+	for _, node := range nodes {
+		g.visitNode(node)
+	}
+}
+
+func (g *tcbGenerator) visitNode(node render3.Node) {
+	switch n := node.(type) {
+	case *render3.Element:
+		g.visitElement(n)
+	case *render3.Template:
+		g.visitTemplate(n)
+	case *render3.BoundText:
+		g.expressionStatement(n.Value)
+	case *render3.Icu:
+		for _, boundText := range n.Vars {
+			g.expressionStatement(boundText.Value)
+		}
+		for _, placeholder := range n.Placeholders {
+			if boundText, ok := placeholder.(*render3.BoundText); ok {
+				g.expressionStatement(boundText.Value)
+			}
+		}
+	case *render3.Content:
+		g.visitAll(n.Children)
+	case *render3.Component:
+		g.visitAll(n.Children)
+	case *render3.LetDeclaration:
+		id := g.allocateID()
+		g.w.newline()
+		g.w.write("const ", id, " = ")
+		g.translateExpression(n.Value)
+		g.w.write(";")
+		g.lets[n] = id
+	case *render3.IfBlock:
+		g.visitIfBranches(n.Branches)
+	case *render3.SwitchBlock:
+		g.visitSwitchBlock(n)
+	case *render3.ForLoopBlock:
+		g.visitForLoopBlock(n)
+	case *render3.DeferredBlock:
+		g.visitDeferredBlock(n)
+	}
+}
+
+// expressionStatement writes an expression as a statement, e.g. for text interpolations and
+// bindings that are only checked for errors in the expression itself.
+func (g *tcbGenerator) expressionStatement(ast expression_parser.AST) {
+	g.w.newline()
+	g.translateExpression(ast)
+	g.w.write(";")
+}
+
+func (g *tcbGenerator) visitElement(element *render3.Element) {
+	directives := g.directivesOf(element)
+
+	hasDomListeners := false
+	for _, output := range element.Outputs {
+		if output.Target == nil && !g.isClaimedOutput(output, directives) {
+			hasDomListeners = true
+		}
+	}
+	if hasDomListeners || g.referencedElements[element] {
+		id := g.elementID(element)
+		g.w.newline()
+		start := g.w.len()
+		g.w.write("var ", id, " = document.createElement(", quoteString(element.Name), ")")
+		g.w.addParseSpanInfo(start, element.StartSourceSpan)
+		g.w.write(";")
+	}
+
+	g.declareDirectives(element, directives)
+	g.writeInputs(element, element.Inputs, element.Attributes, directives)
+	g.writeOutputs(element, element.Outputs, directives)
+	g.visitAll(element.Children)
+}
+
+func (g *tcbGenerator) visitTemplate(template *render3.Template) {
+	directives := g.directivesOf(template)
+	g.declareDirectives(template, directives)
+
+	// Structural directives receive their inputs through the template attributes.
+	inputs := append([]*render3.BoundAttribute{}, template.Inputs...)
+	attributes := append([]*render3.TextAttribute{}, template.Attributes...)
+	for _, attr := range template.TemplateAttrs {
+		switch a := attr.(type) {
+		case *render3.BoundAttribute:
+			inputs = append(inputs, a)
+		case *render3.TextAttribute:
+			attributes = append(attributes, a)
+		}
+	}
+	g.writeInputs(template, inputs, attributes, directives)
+	g.writeOutputs(template, template.Outputs, directives)
+
+	// The context of the template is typed by the context guard of its directive, if any.
+	contextID := g.allocateID()
+	g.templateContexts[template] = contextID
+	g.w.newline()
+	g.w.write("var ", contextID, ": any = null!;")
+
+	guards := []func(){}
+	for _, dir := range directives {
+		templateGuards, ok := dir.(DirectiveTemplateGuards)
+		if !ok {
+			continue
+		}
+		dirID := g.directiveID(template, dir)
+		dirRef := g.refs.reference(dir.Name())
+		for _, guard := range templateGuards.NgTemplateGuards() {
+			input := findInput(inputs, guard.InputName)
+			if input == nil {
+				continue
+			}
+			guard, value := guard, input.Value
+			guards = append(guards, func() {
+				if guard.Type == TemplateGuardBinding {
+					g.translateExpression(value)
+					return
+				}
+				g.w.write(dirRef, ".ngTemplateGuard_", guard.InputName, "(", dirID, ", ")
+				g.translateExpression(value)
+				g.w.write(")")
+			})
+		}
+		if templateGuards.HasNgTemplateContextGuard() {
+			guards = append(guards, func() {
+				g.w.write(dirRef, ".ngTemplateContextGuard(", dirID, ", ", contextID, ")")
+			})
+		}
+	}
+
+	if len(guards) == 0 {
+		g.w.openBlock("")
+	} else {
+		g.w.newline()
+		g.w.write("if (")
+		for i, guard := range guards {
+			if i > 0 {
+				g.w.write(" && ")
+			}
+			guard()
+		}
+		g.w.write(") {")
+		g.w.indent++
+	}
+	for _, variable := range template.Variables {
+		id := g.allocateID()
+		g.variables[variable] = id
+		value := variable.Value
+		if value == "" {
+			value = "$implicit"
+		}
+		g.w.newline()
+		start := g.w.len()
+		g.w.write("var ", id, " = ", contextID, propertyAccess(value))
+		g.w.addParseSpanInfo(start, variable.SourceSpan())
+		g.w.write(";")
+	}
+	g.visitAll(template.Children)
+	g.w.closeBlock()
+}
+
+func findInput(inputs []*render3.BoundAttribute, name string) *render3.BoundAttribute {
+	for _, input := range inputs {
+		if input.Name == name {
+			return input
+		}
+	}
+	return nil
+}
+
+// declareDirectives declares a variable for every directive matched on a node.
+func (g *tcbGenerator) declareDirectives(node render3.Node, directives []view.DirectiveMeta) {
+	for _, dir := range directives {
+		id := g.directiveID(node, dir)
+		g.w.newline()
+		g.w.write("var ", id, ": ", g.refs.reference(dir.Name()), " = null!;")
+	}
+}
+
+// writeInputs assigns bound values to the inputs of directives. Bindings which no directive
+// claims target the DOM and only their expressions are checked.
+func (g *tcbGenerator) writeInputs(
+	node render3.Node,
+	inputs []*render3.BoundAttribute,
+	attributes []*render3.TextAttribute,
+	directives []view.DirectiveMeta,
+) {
+	for _, input := range inputs {
+		claimed := false
+		if input.Type == expression_parser.BindingTypeProperty || input.Type == expression_parser.BindingTypeTwoWay {
+			for _, dir := range directives {
+				if dir.Inputs() == nil || !dir.Inputs().HasBindingPropertyName(input.Name) {
+					continue
+				}
+				claimed = true
+				g.w.newline()
+				start := g.w.len()
+				g.w.write(g.directiveID(node, dir), propertyAccess(inputFieldName(dir, input.Name)))
+				g.w.addParseSpanInfo(start, spanOf(input.KeySpan, input.SourceSpan()))
+				g.w.write(" = ")
+				g.translateExpression(input.Value)
+				g.w.write(";")
+			}
+		}
+		if !claimed {
+			g.expressionStatement(input.Value)
+		}
+	}
+
+	// Plain attributes initialize inputs with their string value.
+	for _, attr := range attributes {
+		for _, dir := range directives {
+			if dir.Inputs() == nil || !dir.Inputs().HasBindingPropertyName(attr.Name) {
+				continue
+			}
+			g.w.newline()
+			start := g.w.len()
+			g.w.write(g.directiveID(node, dir), propertyAccess(inputFieldName(dir, attr.Name)))
+			g.w.addParseSpanInfo(start, spanOf(attr.KeySpan, attr.SourceSpan()))
+			g.w.write(" = ", quoteString(attr.Value), ";")
+		}
+	}
+}
+
+func (g *tcbGenerator) isClaimedOutput(output *render3.BoundEvent, directives []view.DirectiveMeta) bool {
+	for _, dir := range directives {
+		if dir.Outputs() != nil && dir.Outputs().HasBindingPropertyName(output.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeOutputs subscribes to the outputs of directives and adds DOM event listeners for the
+// remaining events, checking their handlers with a typed `$event`.
+func (g *tcbGenerator) writeOutputs(node render3.Node, outputs []*render3.BoundEvent, directives []view.DirectiveMeta) {
+	for _, output := range outputs {
+		claimed := false
+		for _, dir := range directives {
+			if dir.Outputs() == nil || !dir.Outputs().HasBindingPropertyName(output.Name) {
+				continue
+			}
+			claimed = true
+			g.w.newline()
+			start := g.w.len()
+			g.w.write(g.directiveID(node, dir), "[", quoteString(outputFieldName(dir, output.Name)), "]")
+			g.w.addParseSpanInfo(start, spanOf(output.KeySpan, output.SourceSpan()))
+			g.w.write(".subscribe(")
+			g.writeListener(output, "$event")
+			g.w.write(");")
+		}
+		if claimed {
+			continue
+		}
+
+		switch {
+		case output.Type == expression_parser.ParsedEventTypeTwoWay:
+			// The input half of the two-way binding is reported as an unknown property.
+			continue
+		case output.Type == expression_parser.ParsedEventTypeLegacyAnimation ||
+			output.Type == expression_parser.ParsedEventTypeAnimation:
+			g.w.newline()
+			g.writeListener(output, "$event: any")
+			g.w.write(";")
+		case output.Target != nil:
+			target := *output.Target
+			if target == "body" {
+				target = "document.body"
+			}
+			g.w.newline()
+			g.w.write(target, ".addEventListener(", quoteString(output.Name), ", ")
+			g.writeListener(output, "$event")
+			g.w.write(");")
+		default:
+			element, ok := g.elements[node]
+			if !ok {
+				// Only elements can have DOM listeners, events on templates are typed as `any`.
+				g.w.newline()
+				g.writeListener(output, "$event: any")
+				g.w.write(";")
+				continue
+			}
+			g.w.newline()
+			g.w.write(element, ".addEventListener(", quoteString(output.Name), ", ")
+			g.writeListener(output, "$event")
+			g.w.write(");")
+		}
+	}
+}
+
+// writeListener writes the arrow function of an event handler.
+func (g *tcbGenerator) writeListener(output *render3.BoundEvent, param string) {
+	g.w.write("(", param, "): any => {")
+	g.w.indent++
+	previous := g.inListener
+	g.inListener = true
+
+	handler := output.Handler
+	if withSource, ok := handler.(*expression_parser.ASTWithSource); ok {
+		handler = withSource.AST
+	}
+	if len(g.guards) > 0 {
+		g.w.newline()
+		g.w.write("if (", strings.Join(g.guards, " && "), ") {")
+		g.w.indent++
+	}
+	if output.Type == expression_parser.ParsedEventTypeTwoWay {
+		// Two-way bindings write the event back to their target.
+		g.checkWrite(handler)
+		g.w.newline()
+		g.translateExpression(handler)
+		g.w.write(" = $event;")
+	} else if chain, ok := handler.(*expression_parser.Chain); ok {
+		for _, expr := range chain.Expressions {
+			g.expressionStatement(expr)
+		}
+	} else {
+		g.expressionStatement(handler)
+	}
+
+	if len(g.guards) > 0 {
+		g.w.closeBlock()
+	}
+	g.inListener = previous
+	g.w.closeBlock()
+}
+
+func (g *tcbGenerator) pushGuard(guard string) {
+	g.guards = append(g.guards, guard)
+}
+
+func (g *tcbGenerator) popGuard() {
+	g.guards = g.guards[:len(g.guards)-1]
+}
+
+// guardExpression returns the code of a condition which has already been written to the block,
+// for use as a guard. Mappings and diagnostics are only recorded for the original.
+func (g *tcbGenerator) guardExpression(ast expression_parser.AST) string {
+	w, reported := g.w, len(g.diagnostics)
+	g.w = &tcbWriter{ignoreSpans: true}
+	g.translateExpression(ast)
+	code := g.w.sb.String()
+	g.w, g.diagnostics = w, g.diagnostics[:reported]
+	return "(" + code + ")"
+}
+
+func inputFieldName(dir view.DirectiveMeta, bindingName string) string {
+	if mapping, ok := dir.(DirectiveFieldMapping); ok {
+		return mapping.InputFieldName(bindingName)
+	}
+	return bindingName
+}
+
+func outputFieldName(dir view.DirectiveMeta, bindingName string) string {
+	if mapping, ok := dir.(DirectiveFieldMapping); ok {
+		return mapping.OutputFieldName(bindingName)
+	}
+	return bindingName
+}
+
+// visitIfBranches writes an `@if` block as an if/else chain. Branches with an alias
+// (`@if (expr; as alias)`) declare the alias before testing it, so that the alias is narrowed
+// in the branch.
+func (g *tcbGenerator) visitIfBranches(branches []*render3.IfBlockBranch) {
+	if len(branches) == 0 {
+		return
+	}
+	branch := branches[0]
+	if branch.Expression == nil {
+		g.w.openBlock("")
+		g.visitAll(branch.Children)
+		g.w.closeBlock()
+		return
+	}
+
+	var guard string
+	if branch.ExpressionAlias != nil {
+		g.w.openBlock("")
+		id := g.allocateID()
+		g.variables[branch.ExpressionAlias] = id
+		g.w.newline()
+		g.w.write("var ", id, " = ")
+		g.translateExpression(branch.Expression)
+		g.w.write(";")
+		g.w.newline()
+		g.w.write("if (", id, ") {")
+		guard = id
+	} else {
+		g.w.newline()
+		g.w.write("if (")
+		g.translateExpression(branch.Expression)
+		g.w.write(") {")
+		guard = g.guardExpression(branch.Expression)
+	}
+
+	g.w.indent++
+	g.pushGuard(guard)
+	g.visitAll(branch.Children)
+	g.popGuard()
+	g.w.indent--
+	if len(branches) > 1 {
+		g.w.newline()
+		g.w.write("} else {")
+		g.w.indent++
+		g.pushGuard("!" + guard)
+		if next := branches[1]; next.Expression == nil {
+			g.visitAll(next.Children)
+		} else {
+			g.visitIfBranches(branches[1:])
+		}
+		g.popGuard()
+		g.w.closeBlock()
+	} else {
+		g.w.newline()
+		g.w.write("}")
+	}
+
+	if branch.ExpressionAlias != nil {
+		g.w.closeBlock()
+	}
+}
+
+// visitSwitchBlock writes a `@switch` block as an if/else chain comparing the switch expression
+// with each case, which narrows the expression in the cases.
+func (g *tcbGenerator) visitSwitchBlock(block *render3.SwitchBlock) {
+	var defaultCase *render3.SwitchBlockCase
+	var previous []string
+	first := true
+	for _, c := range block.Cases {
+		if c.Expression == nil {
+			defaultCase = c
+			continue
+		}
+		g.w.newline()
+		if first {
+			g.w.write("if (")
+		} else {
+			g.w.write("} else if (")
+		}
+		g.translateExpression(block.Expression)
+		g.w.write(" === ")
+		g.translateExpression(c.Expression)
+		g.w.write(") {")
+		guard := "(" + g.guardExpression(block.Expression) + " === " + g.guardExpression(c.Expression) + ")"
+		g.w.indent++
+		g.pushGuard(guard)
+		g.visitAll(c.Children)
+		g.popGuard()
+		g.w.indent--
+		previous = append(previous, "!"+guard)
+		first = false
+	}
+	if defaultCase != nil {
+		if first {
+			g.w.openBlock("")
+		} else {
+			g.w.newline()
+			g.w.write("} else {")
+			g.w.indent++
+		}
+		g.guards = append(g.guards, previous...)
+		g.visitAll(defaultCase.Children)
+		g.guards = g.guards[:len(g.guards)-len(previous)]
+		g.w.indent--
+		first = false
+	}
+	if !first {
+		g.w.newline()
+		g.w.write("}")
+	} else {
+		// A switch without cases still needs its expression checked.
+		g.expressionStatement(block.Expression)
+	}
+}
+
+// forLoopContextTypes are the types of the implicit variables of `@for`.
+var forLoopContextTypes = map[string]string{
+	"$index": "number",
+	"$count": "number",
+	"$first": "boolean",
+	"$last":  "boolean",
+	"$even":  "boolean",
+	"$odd":   "boolean",
+}
+
+// visitForLoopBlock writes a `@for` block as a `for...of` loop over the iterable.
+func (g *tcbGenerator) visitForLoopBlock(block *render3.ForLoopBlock) {
+	itemID := g.allocateID()
+	if block.Item != nil {
+		g.variables[block.Item] = itemID
+	}
+	g.w.newline()
+	g.w.write("for (const ", itemID, " of (")
+	if block.Expression != nil {
+		g.translateExpression(block.Expression)
+	} else {
+		g.w.write("[]")
+	}
+	g.w.write(")!) {")
+	g.w.indent++
+	for _, variable := range block.ContextVariables {
+		id := g.allocateID()
+		g.variables[variable] = id
+		varType, ok := forLoopContextTypes[variable.Value]
+		if !ok {
+			varType = "any"
+		}
+		g.w.newline()
+		start := g.w.len()
+		g.w.write("var ", id, ": ", varType, " = null!")
+		g.w.addParseSpanInfo(start, variable.SourceSpan())
+		g.w.write(";")
+	}
+	if block.TrackBy != nil {
+		g.expressionStatement(block.TrackBy)
+	}
+	g.visitAll(block.Children)
+	g.w.closeBlock()
+
+	if block.Empty != nil {
+		g.w.openBlock("")
+		g.visitAll(block.Empty.Children)
+		g.w.closeBlock()
+	}
+}
+
+// visitDeferredBlock writes the main content and the connected blocks of `@defer` as plain
+// blocks, and checks the expression of its `when` triggers.
+func (g *tcbGenerator) visitDeferredBlock(block *render3.DeferredBlock) {
+	for _, triggers := range []*render3.DeferredBlockTriggers{block.Triggers, block.PrefetchTriggers, block.HydrateTriggers} {
+		if triggers != nil && triggers.When != nil {
+			g.expressionStatement(triggers.When.Value)
+		}
+	}
+	g.w.openBlock("")
+	g.visitAll(block.Children)
+	g.w.closeBlock()
+	if block.Placeholder != nil {
+		g.w.openBlock("")
+		g.visitAll(block.Placeholder.Children)
+		g.w.closeBlock()
+	}
+	if block.Loading != nil {
+		g.w.openBlock("")
+		g.visitAll(block.Loading.Children)
+		g.w.closeBlock()
+	}
+	if block.Error != nil {
+		g.w.openBlock("")
+		g.visitAll(block.Error.Children)
+		g.w.closeBlock()
+	}
+}
+
+// walkNodes calls fn for every node of the template, including nodes in embedded views.
+func walkNodes(nodes []render3.Node, fn func(node render3.Node)) {
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		fn(node)
+		switch n := node.(type) {
+		case *render3.Element:
+			walkNodes(n.Children, fn)
+		case *render3.Template:
+			walkNodes(n.Children, fn)
+		case *render3.Content:
+			walkNodes(n.Children, fn)
+		case *render3.Component:
+			walkNodes(n.Children, fn)
+		case *render3.IfBlock:
+			for _, branch := range n.Branches {
+				fn(branch)
+				walkNodes(branch.Children, fn)
+			}
+		case *render3.SwitchBlock:
+			for _, c := range n.Cases {
+				fn(c)
+				walkNodes(c.Children, fn)
+			}
+		case *render3.ForLoopBlock:
+			walkNodes(n.Children, fn)
+			if n.Empty != nil {
+				fn(n.Empty)
+				walkNodes(n.Empty.Children, fn)
+			}
+		case *render3.DeferredBlock:
+			walkNodes(n.Children, fn)
+			if n.Placeholder != nil {
+				fn(n.Placeholder)
+				walkNodes(n.Placeholder.Children, fn)
+			}
+			if n.Loading != nil {
+				fn(n.Loading)
+				walkNodes(n.Loading.Children, fn)
+			}
+			if n.Error != nil {
+				fn(n.Error)
+				walkNodes(n.Error.Children, fn)
+			}
+		}
+	}
+}
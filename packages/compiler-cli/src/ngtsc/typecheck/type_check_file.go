@@ -0,0 +1,147 @@
+package typecheck
+
+import (
+	"fmt"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/render3/view"
+)
+
+// TypeCheckFile collects the type-check blocks of several components into a single TypeScript
+// file, together with the imports they need. TypeScript diagnostics reported for the rendered
+// file are mapped back to the templates with `TranslateDiagnostic`.
+type TypeCheckFile struct {
+	FileName string
+
+	// Imports maps every class referenced by the blocks to the module it is imported from.
+	// Classes without an entry are assumed to be in scope.
+	Imports map[string]string
+
+	blocks     []*TypeCheckBlock
+	namespaces map[string]string
+	moduleList []string
+	code       string
+	lineStarts []int
+}
+
+// NewTypeCheckFile creates an empty type-check file.
+func NewTypeCheckFile(fileName string) *TypeCheckFile {
+	return &TypeCheckFile{
+		FileName:   fileName,
+		Imports:    make(map[string]string),
+		namespaces: make(map[string]string),
+	}
+}
+
+// AddTypeCheckBlock generates the type-check block of a template and adds it to the file.
+func (f *TypeCheckFile) AddTypeCheckBlock(bound view.BoundTarget, meta *TypeCheckBlockMetadata) *TypeCheckBlock {
+	if meta.ID == "" {
+		copied := *meta
+		copied.ID = fmt.Sprintf("_tcb%d", len(f.blocks)+1)
+		meta = &copied
+	}
+	block := generateTypeCheckBlock(bound, meta, f)
+	f.blocks = append(f.blocks, block)
+	f.code = ""
+	return block
+}
+
+// Blocks returns the blocks added to the file.
+func (f *TypeCheckFile) Blocks() []*TypeCheckBlock {
+	return f.blocks
+}
+
+// namespace returns the import namespace of a module. `@angular/core` is always `i0`.
+func (f *TypeCheckFile) namespace(module string) string {
+	if ns, ok := f.namespaces[module]; ok {
+		return ns
+	}
+	if module != "@angular/core" {
+		f.namespace("@angular/core")
+	}
+	ns := fmt.Sprintf("i%d", len(f.moduleList))
+	f.namespaces[module] = ns
+	f.moduleList = append(f.moduleList, module)
+	return ns
+}
+
+func (f *TypeCheckFile) reference(className string) string {
+	if module, ok := f.Imports[className]; ok {
+		return f.namespace(module) + "." + className
+	}
+	return className
+}
+
+func (f *TypeCheckFile) coreReference(name string) string {
+	return f.namespace("@angular/core") + "." + name
+}
+
+// Render returns the source of the file.
+func (f *TypeCheckFile) Render() string {
+	if f.code != "" {
+		return f.code
+	}
+	var sb strings.Builder
+	for _, module := range f.moduleList {
+		sb.WriteString(fmt.Sprintf("import * as %s from %s;\n", f.namespaces[module], quoteString(module)))
+	}
+	if len(f.moduleList) > 0 {
+		sb.WriteString("\n")
+	}
+	for i, block := range f.blocks {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		block.offset = sb.Len()
+		sb.WriteString(block.Code)
+	}
+	sb.WriteString("\nexport const IS_A_MODULE = true;\n")
+
+	f.code = sb.String()
+	f.lineStarts = []int{0}
+	for i := 0; i < len(f.code); i++ {
+		if f.code[i] == '\n' {
+			f.lineStarts = append(f.lineStarts, i+1)
+		}
+	}
+	return f.code
+}
+
+// OffsetAt converts a 0-based line and character of the rendered file, as reported by
+// TypeScript, into an offset.
+func (f *TypeCheckFile) OffsetAt(line int, character int) int {
+	f.Render()
+	if line < 0 {
+		return 0
+	}
+	if line >= len(f.lineStarts) {
+		return len(f.code)
+	}
+	return f.lineStarts[line] + character
+}
+
+// TranslateDiagnostic maps a TypeScript diagnostic reported at an offset of the rendered file
+// back to the template expression it was generated from. It returns nil when the offset is not
+// within a template expression, in which case the diagnostic is not a template error.
+func (f *TypeCheckFile) TranslateDiagnostic(
+	offset int,
+	code int,
+	message string,
+	category diagnostics.Category,
+) *diagnostics.Diagnostic {
+	f.Render()
+	for _, block := range f.blocks {
+		if offset < block.offset || offset >= block.offset+len(block.Code) {
+			continue
+		}
+		span := block.SourceSpanOf(offset - block.offset)
+		if span == nil {
+			return nil
+		}
+		diag := diagnostics.MakeDiagnostic(diagnostics.ErrorCode(code), category, span, message)
+		diag.FromTypeScript = true
+		return diag
+	}
+	return nil
+}
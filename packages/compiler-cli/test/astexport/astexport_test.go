@@ -0,0 +1,214 @@
+package astexport_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/astexport"
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+const appSource = `import { Component, Directive, Input } from '@angular/core';
+
+@Directive({ selector: '[appBold]', standalone: true, exportAs: 'bold' })
+export class BoldDirective {
+  @Input() appBold = '';
+}
+
+@Component({
+  selector: 'app-root',
+  standalone: true,
+  imports: [BoldDirective],
+  templateUrl: './app.component.html',
+})
+export class AppComponent {}
+
+@Component({ selector: 'app-inline', template: '<b title="t">{{ name }}</b>' })
+export class InlineComponent {}
+`
+
+const appTemplate = `<p [appBold]="user.name" #bold="bold">{{ bold }}</p>
+<input #field (keyup)="save(field.value)">
+`
+
+// exportJSON exports the templates of a file and decodes their JSON document.
+func exportJSON(t *testing.T, fileName string, content string, level astexport.Level) []map[string]interface{} {
+	t.Helper()
+	sf := reflection.ReflectSourceFile("/app/app.component.ts", appSource)
+	compiler := annotations.NewCompiler([]*reflection.SourceFile{sf}, annotations.Options{
+		RootDir: "/app",
+		ResourceLoader: func(path string) (string, error) {
+			return appTemplate, nil
+		},
+	})
+	if diags := compiler.Analyze(); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	templates, err := astexport.ExportFile(fileName, content, level, compiler)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := json.Marshal(templates)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return decoded
+}
+
+// get follows a path of keys and indexes in a decoded document.
+func get(t *testing.T, value interface{}, path ...interface{}) interface{} {
+	t.Helper()
+	for _, key := range path {
+		switch key := key.(type) {
+		case string:
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				t.Fatalf("expected an object with %q, got %v", key, value)
+			}
+			value = object[key]
+		case int:
+			array, ok := value.([]interface{})
+			if !ok || key >= len(array) {
+				t.Fatalf("expected an array with %d elements, got %v", key+1, value)
+			}
+			value = array[key]
+		}
+	}
+	return value
+}
+
+func expectValue(t *testing.T, value interface{}, want interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(value, want) {
+		t.Errorf("expected %v, got %v", want, value)
+	}
+}
+
+func span(startOffset, startLine, startCol, endOffset, endLine, endCol float64) map[string]interface{} {
+	return map[string]interface{}{
+		"start": map[string]interface{}{"offset": startOffset, "line": startLine, "col": startCol},
+		"end":   map[string]interface{}{"offset": endOffset, "line": endLine, "col": endCol},
+	}
+}
+
+func TestExportFile(t *testing.T) {
+	t.Run("should export the HTML AST of a template file", func(t *testing.T) {
+		templates := exportJSON(t, "/app/app.component.html", appTemplate, astexport.LevelHTML)
+		if len(templates) != 1 {
+			t.Fatalf("expected 1 template, got %d", len(templates))
+		}
+		template := templates[0]
+		expectValue(t, template["file"], "/app/app.component.html")
+		expectValue(t, template["component"], nil)
+		p := get(t, template, "nodes", 0)
+		expectValue(t, get(t, p, "kind"), "Element")
+		expectValue(t, get(t, p, "name"), "p")
+		expectValue(t, get(t, p, "attrs", 0, "name"), "[appBold]")
+		expectValue(t, get(t, p, "attrs", 0, "value"), "user.name")
+		expectValue(t, get(t, p, "attrs", 0, "valueSpan"), span(14, 0, 14, 23, 0, 23))
+		expectValue(t, get(t, p, "children", 0, "kind"), "Text")
+		expectValue(t, get(t, p, "children", 0, "value"), "{{ bold }}")
+		expectValue(t, get(t, template, "nodes", 2, "name"), "input")
+		expectValue(t, get(t, template, "nodes", 2, "sourceSpan"), span(53, 1, 0, 95, 1, 42))
+	})
+
+	t.Run("should export the inline templates of a component file with spans in the file", func(t *testing.T) {
+		templates := exportJSON(t, "/app/app.component.ts", appSource, astexport.LevelHTML)
+		if len(templates) != 1 {
+			t.Fatalf("expected 1 template, got %d", len(templates))
+		}
+		template := templates[0]
+		start := float64(strings.Index(appSource, "<b title"))
+		expectValue(t, template["component"], "InlineComponent")
+		expectValue(t, template["inline"], true)
+		expectValue(t, template["start"], start)
+		expectValue(t, get(t, template, "nodes", 0, "name"), "b")
+		expectValue(t, get(t, template, "nodes", 0, "attrs", 0, "keySpan"), span(start+3, 15, 51, start+8, 15, 56))
+	})
+
+	t.Run("should export the bindings and their expression trees", func(t *testing.T) {
+		templates := exportJSON(t, "/app/app.component.html", appTemplate, astexport.LevelR3)
+		if len(templates) != 1 {
+			t.Fatalf("expected 1 template, got %d", len(templates))
+		}
+		expectValue(t, templates[0]["component"], "AppComponent")
+		input := get(t, templates[0], "nodes", 0, "inputs", 0)
+		expectValue(t, get(t, input, "kind"), "BoundAttribute")
+		expectValue(t, get(t, input, "name"), "appBold")
+		expectValue(t, get(t, input, "type"), "property")
+		expectValue(t, get(t, input, "value", "source"), "user.name")
+		read := get(t, input, "value", "ast")
+		expectValue(t, get(t, read, "kind"), "PropertyRead")
+		expectValue(t, get(t, read, "name"), "name")
+		expectValue(t, get(t, read, "receiver", "name"), "user")
+		expectValue(t, get(t, read, "sourceSpan"), span(14, 0, 14, 23, 0, 23))
+		expectValue(t, get(t, input, "directives"), nil)
+
+		output := get(t, templates[0], "nodes", 1, "outputs", 0)
+		expectValue(t, get(t, output, "kind"), "BoundEvent")
+		expectValue(t, get(t, output, "type"), "regular")
+		expectValue(t, get(t, output, "handler", "ast", "kind"), "Call")
+		expectValue(t, get(t, output, "handler", "ast", "args", 0, "name"), "value")
+	})
+
+	t.Run("should export the directive matches and reference targets of the binder", func(t *testing.T) {
+		templates := exportJSON(t, "/app/app.component.html", appTemplate, astexport.LevelBound)
+		p := get(t, templates[0], "nodes", 0)
+		expectValue(t, get(t, p, "directives"), []interface{}{"BoldDirective"})
+		expectValue(t, get(t, p, "inputs", 0, "consumer"), map[string]interface{}{"directive": "BoldDirective"})
+		expectValue(t, get(t, p, "references", 0, "target", "directive"), "BoldDirective")
+		expectValue(t, get(t, p, "references", 0, "target", "node", "name"), "p")
+		expectValue(t, get(t, p, "children", 0, "value", "ast", "expressions", 0, "target", "kind"), "Reference")
+		expectValue(t, get(t, p, "children", 0, "value", "ast", "expressions", 0, "target", "name"), "bold")
+
+		input := get(t, templates[0], "nodes", 1)
+		expectValue(t, get(t, input, "directives"), []interface{}{})
+		expectValue(t, get(t, input, "references", 0, "target"), map[string]interface{}{
+			"node": map[string]interface{}{"kind": "Element", "name": "input", "sourceSpan": span(53, 1, 0, 95, 1, 42)},
+		})
+		expectValue(t, get(t, input, "outputs", 0, "handler", "ast", "args", 0, "receiver", "target", "name"), "field")
+	})
+
+	t.Run("should export the templates no component uses against an empty scope", func(t *testing.T) {
+		templates := exportJSON(t, "/app/other.html", appTemplate, astexport.LevelR3)
+		if len(templates) != 1 {
+			t.Fatalf("expected 1 template, got %d", len(templates))
+		}
+		expectValue(t, templates[0]["component"], nil)
+		expectValue(t, templates[0]["emptyScope"], nil)
+		expectValue(t, get(t, templates[0], "nodes", 0, "inputs", 0, "name"), "appBold")
+
+		templates = exportJSON(t, "/app/other.html", appTemplate, astexport.LevelBound)
+		expectValue(t, templates[0]["emptyScope"], true)
+		p := get(t, templates[0], "nodes", 0)
+		expectValue(t, get(t, p, "directives"), []interface{}{})
+		// No directive exports bold, but the reference to the input element is bound.
+		expectValue(t, get(t, p, "references", 0, "target"), nil)
+		expectValue(t, get(t, templates[0], "nodes", 1, "references", 0, "target", "node", "name"), "input")
+
+		// Without a compiler, as for a template read from stdin.
+		exported, err := astexport.ExportFile("-", "<p>{{ a }}</p>", astexport.LevelBound, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(exported) != 1 || !exported[0].EmptyScope || len(exported[0].Nodes) != 1 {
+			t.Errorf("expected a template bound against an empty scope, got %v", exported)
+		}
+	})
+
+	t.Run("should report the invalid exports", func(t *testing.T) {
+		if _, err := astexport.ExportFile("/app/app.component.ts", appSource, astexport.LevelR3, nil); err == nil {
+			t.Errorf("expected an error")
+		}
+		if _, err := astexport.ExportFile("/app/other.html", "<p></p>", "ivy", nil); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package format_test
+
+import (
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/format"
+)
+
+// expectFormatted formats an external template, and checks that formatting it again keeps it.
+func expectFormatted(t *testing.T, template string, width int, want string) {
+	t.Helper()
+	options := format.Options{Width: width}
+	got, err := format.FormatTemplate("/app/app.component.html", template, options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+	if again, _ := format.FormatTemplate("/app/app.component.html", got, options); again != got {
+		t.Errorf("expected the formatted template to be kept, got\n%s", again)
+	}
+}
+
+func TestFormatTemplate(t *testing.T) {
+	t.Run("should indent the elements and respace the expressions", func(t *testing.T) {
+		expectFormatted(t,
+			"<div class=\"a\"   id='b'><h1>{{title|uppercase}}</h1>\n   <ul><li *ngFor=\"let x of xs\">{{x}}</li><li>b</li></ul><br/><input [value]=\"a+b\"></div>",
+			80, `<div class="a" id="b">
+  <h1>{{ title | uppercase }}</h1>
+  <ul>
+    <li *ngFor="let x of xs">{{ x }}</li>
+    <li>b</li>
+  </ul>
+  <br />
+  <input [value]="a + b">
+</div>
+`)
+	})
+
+	t.Run("should wrap the attributes past the width", func(t *testing.T) {
+		expectFormatted(t,
+			`<input type="text" [value]="user.name" (input)="update($event.target.value)">`,
+			40, `<input
+  type="text"
+  [value]="user.name"
+  (input)="update($event.target.value)"
+>
+`)
+	})
+
+	t.Run("should only break text at its whitespace", func(t *testing.T) {
+		expectFormatted(t,
+			"<p>Lorem ipsum dolor sit amet,<b>consectetur</b> adipiscing elit, sed do eiusmod tempor.</p><p> a <i>b</i> </p>",
+			40, `<p>Lorem ipsum dolor sit amet,<b>consectetur</b>
+  adipiscing elit, sed do eiusmod
+  tempor.</p>
+<p> a <i>b</i></p>
+`)
+	})
+
+	t.Run("should format the blocks", func(t *testing.T) {
+		expectFormatted(t,
+			"@if (user.loggedIn&&!loading ;as u) {<span>in</span>} @else if (x) { <i>x</i> } @else {out}\n"+
+				"@for (item of items|async; track item.id; let i = $index) {<li>{{i}}</li>}\n@empty { none }\n"+
+				"@switch (mode) { @case ('a') {<a></a>} @default { <b></b> } }\n@let total = price*qty ;",
+			80, `@if (user.loggedIn && !loading; as u) {
+  <span>in</span>
+} @else if (x) {
+  <i>x</i>
+} @else {out}
+@for (item of items | async; track item.id; let i = $index) {
+  <li>{{ i }}</li>
+} @empty {
+  none
+}
+@switch (mode) {
+  @case ('a') {
+    <a></a>
+  }
+  @default {
+    <b></b>
+  }
+}
+@let total = price * qty;
+`)
+	})
+
+	t.Run("should keep the content whose whitespace is significant", func(t *testing.T) {
+		expectFormatted(t,
+			"<div><pre>\n  a   b\n</pre><p i18n>Keep   this</p><div ngNonBindable>{{x}}   y</div><span ngPreserveWhitespaces>  a  </span>"+
+				"<span>{count, plural, =0 {none} other {{{count}}   items}}</span></div>",
+			80, `<div>
+  <pre>
+  a   b
+</pre>
+  <p i18n>Keep   this</p>
+  <div ngNonBindable>{{x}}   y</div>
+  <span ngPreserveWhitespaces>  a  </span>
+  <span>{count, plural, =0 {none} other {{{count}}   items}}</span>
+</div>
+`)
+	})
+
+	t.Run("should keep the expressions changing more than their whitespace", func(t *testing.T) {
+		expectFormatted(t,
+			`<p>{{ value|date:'short' }} {{a}}</p><b (click)="save() ;" [title]="'say &quot;hi&quot;'">x</b>`,
+			80, `<p>{{ value|date:'short' }} {{ a }}</p>
+<b (click)="save() ;" [title]="'say &quot;hi&quot;'">x</b>
+`)
+	})
+
+	t.Run("should report parse errors", func(t *testing.T) {
+		_, err := format.FormatTemplate("/app/app.component.html", "<p>x</p><p", format.Options{})
+		if err == nil || !strings.HasPrefix(err.Error(), "/app/app.component.html:1:9: ") {
+			t.Errorf("expected a parse error, got %v", err)
+		}
+	})
+}
+
+func TestFormatFile(t *testing.T) {
+	source := "import { Component } from '@angular/core';\n\n" +
+		"@Component({\n  selector: 'app-a',\n  template: '<p *ngIf=\"show\">{{ \\'hi\\'|uppercase }}</p>',\n})\nexport class A {}\n\n" +
+		"@Component({\n  selector: 'app-b',\n  template: `<ul><li>{{a}}</li><li>b</li></ul>`,\n})\nexport class B {}\n\n" +
+		"@Component({\n  selector: 'app-c',\n  template: `<div>   {{a}}   </div>`,\n  preserveWhitespaces: true,\n})\nexport class C {}\n"
+	got, err := format.FormatFile("/app/app.component.ts", source, format.Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := strings.NewReplacer(
+		`{{ \'hi\'|uppercase }}`, `{{ \'hi\' | uppercase }}`,
+		"`<ul><li>{{a}}</li><li>b</li></ul>`", "`\n    <ul>\n      <li>{{ a }}</li>\n      <li>b</li>\n    </ul>\n  `",
+	).Replace(source)
+	if got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+	if again, _ := format.FormatFile("/app/app.component.ts", got, format.Options{}); again != got {
+		t.Errorf("expected the formatted file to be kept, got\n%s", again)
+	}
+}
@@ -0,0 +1,188 @@
+package linker_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/linker"
+)
+
+// link links the source and fails the test on errors.
+func link(t *testing.T, source string) string {
+	t.Helper()
+	linked, err := linker.LinkFile("/lib/test.mjs", source, linker.Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return linked
+}
+
+// expectContains checks the output ignoring the line breaks the emitter adds to long lines.
+func expectContains(t *testing.T, source string, parts ...string) {
+	t.Helper()
+	unwrapped := lineBreak.ReplaceAllString(source, "")
+	for _, part := range parts {
+		if !strings.Contains(unwrapped, part) {
+			t.Errorf("expected output to contain %q, got:\n%s", part, source)
+		}
+	}
+}
+
+var lineBreak = regexp.MustCompile(`\n +`)
+
+func TestFileLinker(t *testing.T) {
+	t.Run("should leave sources without declarations unchanged", func(t *testing.T) {
+		source := "import * as i0 from '@angular/core';\nexport const a = i0.ɵɵdefineInjectable;\n"
+		if linked := link(t, source); linked != source {
+			t.Errorf("expected the source to be unchanged, got:\n%s", linked)
+		}
+	})
+
+	t.Run("should link a component and its dependencies", func(t *testing.T) {
+		linked := link(t, `import * as i0 from '@angular/core';
+import { ShoutPipe } from './shout.pipe.js';
+import { HighlightDirective } from './highlight.directive.js';
+export class GreetComponent {}
+GreetComponent.ɵcmp = i0.ɵɵngDeclareComponent({minVersion:'14.0.0',version:'0.0.0-PLACEHOLDER',
+    type:GreetComponent,isStandalone:true,selector:'acme-greet',inputs:{name:'name'},ngImport:i0,
+    template:'<p acmeHighlight>Hello {{ name | shout }}</p>',isInline:true,
+    dependencies:[{kind:'directive',type:HighlightDirective,selector:'[acmeHighlight]'},
+      {kind:'pipe',type:ShoutPipe,name:'shout'}],
+    changeDetection:i0.ChangeDetectionStrategy.OnPush});
+`)
+		expectContains(t, linked,
+			"GreetComponent.ɵcmp = i0.ɵɵdefineComponent({type:GreetComponent,",
+			"i0.ɵɵelementStart(0,'p',0);",
+			"i0.ɵɵpipe(2,'shout');",
+			"dependencies:[HighlightDirective,ShoutPipe]",
+			"changeDetection:0",
+		)
+		if strings.Contains(linked, "ɵɵngDeclare") {
+			t.Errorf("expected the declaration to be replaced, got:\n%s", linked)
+		}
+	})
+
+	t.Run("should share the dependency functions of defer blocks", func(t *testing.T) {
+		linked := link(t, `import * as i0 from '@angular/core';
+export class AppComponent {}
+AppComponent.ɵcmp = i0.ɵɵngDeclareComponent({minVersion:'14.0.0',version:'0.0.0-PLACEHOLDER',
+    type:AppComponent,isStandalone:true,selector:'app-root',ngImport:i0,
+    template:'@defer { <cal /> } @defer (on idle) { <cal /> } @defer { <other /> }',isInline:true,
+    deferBlockDependencies:[() => [import('./cal.js').then(m => m.CalComponent)],
+      () => [import('./cal.js').then(m => m.CalComponent)],
+      () => [import('./other.js').then(m => m.OtherComponent)]]});
+`)
+		expectContains(t, linked,
+			"var AppComponent_Defer_1_DepsFn = () => [import('./cal.js').then(m => m.CalComponent)];",
+			"var AppComponent_Defer_7_DepsFn = () => [import('./other.js').then(m => m.OtherComponent)];",
+			"i0.ɵɵdefer(1,0,AppComponent_Defer_1_DepsFn,",
+			"i0.ɵɵdefer(4,3,AppComponent_Defer_1_DepsFn,",
+			"i0.ɵɵdefer(7,6,AppComponent_Defer_7_DepsFn,",
+		)
+		if strings.Contains(linked, "AppComponent_Defer_4_DepsFn") {
+			t.Errorf("expected the dependency function to be shared, got:\n%s", linked)
+		}
+	})
+
+	t.Run("should link a directive and its factory", func(t *testing.T) {
+		linked := link(t, `import * as i0 from '@angular/core';
+import { ElementRef } from '@angular/core';
+import { COLOR } from './tokens.js';
+export class HighlightDirective {}
+HighlightDirective.ɵfac = i0.ɵɵngDeclareFactory({minVersion:'12.0.0',version:'0.0.0-PLACEHOLDER',
+    ngImport:i0,type:HighlightDirective,deps:[{token:ElementRef},{token:COLOR,optional:true}],
+    target:i0.ɵɵFactoryTarget.Directive});
+HighlightDirective.ɵdir = i0.ɵɵngDeclareDirective({minVersion:'14.0.0',version:'0.0.0-PLACEHOLDER',
+    type:HighlightDirective,isStandalone:true,selector:'[acmeHighlight]',
+    host:{properties:{'style.color':'color'},classAttribute:'highlight'},ngImport:i0});
+`)
+		expectContains(t, linked,
+			"return new (__ngFactoryType__ || HighlightDirective)(i0.ɵɵdirectiveInject(ElementRef),i0.ɵɵdirectiveInject(COLOR,8));",
+			"HighlightDirective.ɵdir = i0.ɵɵdefineDirective({type:HighlightDirective,selectors:[['','acmeHighlight','']],hostAttrs:[1,'highlight'],hostVars:2,",
+			"i0.ɵɵstyleProp('color',ctx.color);",
+		)
+	})
+
+	t.Run("should link a pipe", func(t *testing.T) {
+		linked := link(t, `import * as i0 from '@angular/core';
+ShoutPipe.ɵpipe = i0.ɵɵngDeclarePipe({minVersion:'14.0.0',version:'0.0.0-PLACEHOLDER',ngImport:i0,
+    type:ShoutPipe,isStandalone:true,name:'shout',pure:false});
+`)
+		expectContains(t, linked, "ShoutPipe.ɵpipe = i0.ɵɵdefinePipe({name:'shout',type:ShoutPipe,pure:false});")
+	})
+
+	t.Run("should link an injectable", func(t *testing.T) {
+		linked := link(t, `import * as i0 from '@angular/core';
+GreetService.ɵprov = i0.ɵɵngDeclareInjectable({minVersion:'12.0.0',version:'0.0.0-PLACEHOLDER',
+    ngImport:i0,type:GreetService,providedIn:'root'});
+Logger.ɵprov = i0.ɵɵngDeclareInjectable({minVersion:'12.0.0',version:'0.0.0-PLACEHOLDER',
+    ngImport:i0,type:Logger,providedIn:'root',useExisting:ConsoleLogger});
+`)
+		expectContains(t, linked,
+			"GreetService.ɵprov = i0.ɵɵdefineInjectable({token:GreetService,factory:GreetService.ɵfac,providedIn:'root'});",
+			"i0.ɵɵinject(ConsoleLogger)",
+		)
+	})
+
+	t.Run("should link an NgModule and its injector", func(t *testing.T) {
+		source := `import * as i0 from '@angular/core';
+AcmeModule.ɵmod = i0.ɵɵngDeclareNgModule({minVersion:'14.0.0',version:'0.0.0-PLACEHOLDER',ngImport:i0,
+    type:AcmeModule,imports:[CommonModule],exports:[GreetComponent]});
+AcmeModule.ɵinj = i0.ɵɵngDeclareInjector({minVersion:'12.0.0',version:'0.0.0-PLACEHOLDER',ngImport:i0,
+    type:AcmeModule,providers:[GreetService],imports:[CommonModule]});
+`
+		expectContains(t, link(t, source),
+			"AcmeModule.ɵmod = i0.ɵɵdefineNgModule({type:AcmeModule});",
+			"AcmeModule.ɵinj = i0.ɵɵdefineInjector({providers:[GreetService],imports:[CommonModule]});",
+		)
+
+		jit, err := linker.LinkFile("/lib/test.mjs", source, linker.Options{JitMode: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expectContains(t, jit, "i0.ɵɵdefineNgModule({type:AcmeModule,imports:[CommonModule],exports:[GreetComponent]});")
+	})
+
+	t.Run("should link class metadata", func(t *testing.T) {
+		linked := link(t, `import * as i0 from '@angular/core';
+i0.ɵɵngDeclareClassMetadata({minVersion:'12.0.0',version:'0.0.0-PLACEHOLDER',ngImport:i0,
+    type:ShoutPipe,decorators:[{type:Pipe,args:[{name:'shout'}]}]});
+`)
+		expectContains(t, linked,
+			"i0.ɵsetClassMetadata(ShoutPipe,[{type:Pipe,args:[{name:'shout'}]}],null,null)",
+			"(typeof ngDevMode === 'undefined') || ngDevMode",
+		)
+	})
+
+	t.Run("should report a declaration without an object literal argument", func(t *testing.T) {
+		source := "import * as i0 from '@angular/core';\nA.ɵpipe = i0.ɵɵngDeclarePipe(meta);\n"
+		_, err := linker.LinkFile("/lib/test.mjs", source, linker.Options{})
+		if !linker.IsFatalLinkerError(err) {
+			t.Fatalf("expected a fatal linker error, got %v", err)
+		}
+		fatal := err.(*linker.FatalLinkerError)
+		if got := source[fatal.Start:fatal.End]; got != "meta" {
+			t.Errorf("expected the error to point at the argument, got %q", got)
+		}
+	})
+
+	t.Run("should report template errors with their location", func(t *testing.T) {
+		source := `import * as i0 from '@angular/core';
+A.ɵcmp = i0.ɵɵngDeclareComponent({minVersion:'14.0.0',version:'0.0.0-PLACEHOLDER',ngImport:i0,
+    type:A,selector:'a',template:'<div></span>',isInline:true});
+`
+		l := linker.NewFileLinker("/lib/test.mjs", source, linker.Options{})
+		_, err := l.Link()
+		if !linker.IsFatalLinkerError(err) {
+			t.Fatalf("expected a fatal linker error, got %v", err)
+		}
+		fatal := err.(*linker.FatalLinkerError)
+		if !strings.HasPrefix(fatal.Message, "Errors found in the template:") {
+			t.Errorf("unexpected message %q", fatal.Message)
+		}
+		if line, _ := l.Position(fatal.Start); line != 2 {
+			t.Errorf("expected the error on line 2, got %d", line)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package migrations_test
+
+import (
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/migrations"
+)
+
+// migrate migrates an external template and checks that no problem is reported.
+func migrate(t *testing.T, template string) string {
+	t.Helper()
+	m := migrations.MigrateControlFlow("/app/app.component.html", template)
+	for _, problem := range m.Problems {
+		t.Errorf("unexpected problem: %s", problem)
+	}
+	return m.Content
+}
+
+func expectMigrated(t *testing.T, template string, want string) {
+	t.Helper()
+	if got := migrate(t, template); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestMigrateControlFlow(t *testing.T) {
+	t.Run("should migrate *ngIf", func(t *testing.T) {
+		expectMigrated(t,
+			`<div class="a" *ngIf="user.loggedIn && !loading" id="b">Hi</div>`,
+			`@if (user.loggedIn && !loading) {<div class="a" id="b">Hi</div>}`)
+		expectMigrated(t,
+			"<ul>\n  <li\n    *ngIf=\"show\"\n    class=\"item\">x</li>\n</ul>",
+			"<ul>\n  @if (show) {<li\n    class=\"item\">x</li>}\n</ul>")
+		expectMigrated(t,
+			`<span *ngIf="user$ | async as user">{{ user.name }}</span><b *ngIf="item; let it">{{ it }}</b>`,
+			`@if (user$ | async; as user) {<span>{{ user.name }}</span>}@if (item; as it) {<b>{{ it }}</b>}`)
+	})
+
+	t.Run("should replace bare ng-containers by the block", func(t *testing.T) {
+		expectMigrated(t,
+			`<ng-container *ngIf="show"><b>a</b></ng-container><ng-container *ngIf="show" i18n>b</ng-container>`,
+			`@if (show) {<b>a</b>}@if (show) {<ng-container i18n>b</ng-container>}`)
+	})
+
+	t.Run("should move else and then templates into the blocks", func(t *testing.T) {
+		expectMigrated(t, `<div>
+  <span *ngIf="loaded; else loading">{{ data }}</span>
+  <ng-template #loading><i *ngIf="slow">Loading...</i></ng-template>
+</div>`, `<div>
+  @if (loaded) {<span>{{ data }}</span>} @else {@if (slow) {<i>Loading...</i>}}
+</div>`)
+		expectMigrated(t,
+			`<ng-container *ngIf="admin; then adminView; else userView">ignored</ng-container>
+<ng-template #adminView>Admin</ng-template>
+<ng-template #userView>User</ng-template>
+`,
+			"@if (admin) {Admin} @else {User}\n")
+	})
+
+	t.Run("should keep the templates used elsewhere", func(t *testing.T) {
+		expectMigrated(t,
+			`<a *ngIf="a; else spinner">a</a><b *ngIf="b; else spinner">b</b><ng-template #spinner>...</ng-template>`,
+			`@if (a) {<a>a</a>} @else {<ng-container *ngTemplateOutlet="spinner"></ng-container>}`+
+				`@if (b) {<b>b</b>} @else {<ng-container *ngTemplateOutlet="spinner"></ng-container>}`+
+				`<ng-template #spinner>...</ng-template>`)
+	})
+
+	t.Run("should migrate *ngFor", func(t *testing.T) {
+		expectMigrated(t,
+			`<li *ngFor="let item of items">{{ item }}</li>`,
+			`@for (item of items; track item) {<li>{{ item }}</li>}`)
+		expectMigrated(t,
+			`<li *ngFor="let item of items | async; trackBy: trackById; index as i; let isFirst = first; let l = last">{{ i }}</li>`,
+			`@for (item of items | async; track trackById($index, item); let i = $index, isFirst = $first, l = $last) {<li>{{ i }}</li>}`)
+	})
+
+	t.Run("should migrate [ngSwitch]", func(t *testing.T) {
+		expectMigrated(t, `<div [ngSwitch]="mode" class="m">
+  <!-- modes -->
+  <span *ngSwitchCase="'edit'">Edit</span>
+  <ng-container *ngSwitchCase="modes.view"><b *ngIf="x">View</b></ng-container>
+  <span *ngSwitchDefault>None</span>
+</div>`, `<div class="m">@switch (mode) {
+  <!-- modes -->
+  @case ('edit') {<span>Edit</span>}
+  @case (modes.view) {@if (x) {<b>View</b>}}
+  @default {<span>None</span>}
+}</div>`)
+		expectMigrated(t,
+			`<ng-container [ngSwitch]="n"><p *ngSwitchCase="1">one</p></ng-container>`,
+			`@switch (n) {@case (1) {<p>one</p>}}`)
+	})
+
+	t.Run("should migrate nested directives and existing blocks", func(t *testing.T) {
+		expectMigrated(t,
+			`@if (a) {<ul *ngIf="list"><li *ngFor="let x of list">{{ x }}</li></ul>}`,
+			`@if (a) {@if (list) {<ul>@for (x of list; track x) {<li>{{ x }}</li>}</ul>}}`)
+	})
+
+	t.Run("should migrate the inline templates of components", func(t *testing.T) {
+		source := "import { Component } from '@angular/core';\n\n" +
+			"@Component({\n  selector: 'app-root',\n  template: '<p *ngIf=\"show\">{{ \\'hi\\' }}</p>',\n})\nexport class AppComponent {}\n\n" +
+			"@Component({\n  selector: 'app-list',\n  template: `\n    <li *ngFor=\"let x of xs\">{{ x }}</li>\n  `,\n})\nexport class ListComponent {}\n"
+		m := migrations.MigrateControlFlow("/app/app.component.ts", source)
+		for _, problem := range m.Problems {
+			t.Errorf("unexpected problem: %s", problem)
+		}
+		want := strings.NewReplacer(
+			`'<p *ngIf="show">{{ \'hi\' }}</p>'`, `'@if (show) {<p>{{ \'hi\' }}</p>}'`,
+			`<li *ngFor="let x of xs">{{ x }}</li>`, `@for (x of xs; track x) {<li>{{ x }}</li>}`,
+		).Replace(source)
+		if m.Content != want {
+			t.Errorf("expected\n%s\ngot\n%s", want, m.Content)
+		}
+		if m.Migrated != 2 {
+			t.Errorf("expected 2 migrated directives, got %d", m.Migrated)
+		}
+	})
+
+	t.Run("should report the directives which cannot be migrated", func(t *testing.T) {
+		template := `<div *ngIf="a; else tpl">a</div>
+<div [ngSwitch]="x"><b>always</b><i *ngSwitchCase="1">1</i></div>
+<p *ngSwitchDefault>orphan</p>
+<ng-template [ngIf]="b">b</ng-template>
+<li *ngFor="let x of xs; template: t">{{ x }}</li>`
+		m := migrations.MigrateControlFlow("/app/app.component.html", template)
+		if m.Content != template {
+			t.Errorf("expected the template to be unchanged, got:\n%s", m.Content)
+		}
+		var got []string
+		for _, problem := range m.Problems {
+			got = append(got, problem.String())
+		}
+		want := []string{
+			"/app/app.component.html:1:6: <ng-template #tpl> is not in the template",
+			"/app/app.component.html:2:21: the children of [ngSwitch] must all be *ngSwitchCase or *ngSwitchDefault elements",
+			"/app/app.component.html:3:4: *ngSwitchDefault is not a direct child of an element with [ngSwitch]",
+			"/app/app.component.html:4:14: [ngIf] on <ng-template> is not migrated, use *ngIf on an element instead",
+			"/app/app.component.html:5:5: unknown *ngFor binding ngForTemplate",
+		}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("expected problems\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package annotations_test
+
+import (
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck/extended"
+)
+
+const checkTemplatesSource = `import { Component, Directive, Input, NgModule, CUSTOM_ELEMENTS_SCHEMA } from '@angular/core';
+import { CommonModule } from '@angular/common';
+
+@Directive({ selector: '[appTip]', standalone: true })
+export class TipDirective {
+  @Input() appTip = '';
+}
+
+@Component({
+  selector: 'app-card',
+  standalone: true,
+  imports: [TipDirective],
+  template: '<div [appTip]="tip"></div><app-unknown></app-unknown><span [nope]="x"></span>',
+})
+export class CardComponent {}
+
+@Component({ selector: 'app-custom', template: '<x-chart [data]="d"></x-chart>', standalone: false })
+export class CustomComponent {}
+
+@NgModule({ declarations: [CustomComponent], schemas: [CUSTOM_ELEMENTS_SCHEMA] })
+export class CustomModule {}
+
+@Component({ selector: 'app-list', template: '<li *ngFor="let i of items"></li>', standalone: false })
+export class ListComponent {}
+
+@NgModule({ declarations: [ListComponent], imports: [CommonModule] })
+export class ListModule {}
+`
+
+// analyzeCheckTemplates analyzes checkTemplatesSource and returns the diagnostics.
+func analyzeCheckTemplates(checkTemplates bool) []*diagnostics.Diagnostic {
+	sf := reflection.ReflectSourceFile("/app/card.ts", checkTemplatesSource)
+	compiler := annotations.NewCompiler([]*reflection.SourceFile{sf}, annotations.Options{RootDir: "/app", CheckTemplates: checkTemplates})
+	return compiler.Analyze()
+}
+
+func TestCheckTemplates(t *testing.T) {
+	t.Run("should not check templates by default", func(t *testing.T) {
+		if diags := analyzeCheckTemplates(false); len(diags) != 0 {
+			t.Errorf("unexpected diagnostics: %v", diags)
+		}
+	})
+
+	t.Run("should report the unknown elements and properties of templates", func(t *testing.T) {
+		diags := analyzeCheckTemplates(true)
+		if len(diags) != 2 {
+			t.Fatalf("expected 2 diagnostics, got %d: %v", len(diags), diags)
+		}
+		if diags[0].Code != diagnostics.SchemaInvalidElement || !strings.HasPrefix(diags[0].Message, "'app-unknown' is not a known element") {
+			t.Errorf("unexpected diagnostic: %v", diags[0])
+		}
+		if diags[1].Code != diagnostics.SchemaInvalidAttribute || diags[1].Message != "Can't bind to 'nope' since it isn't a known property of 'span'." {
+			t.Errorf("unexpected diagnostic: %v", diags[1])
+		}
+		// The spans of inline templates are in their source file.
+		for _, diag := range diags {
+			if diag.File != "/app/card.ts" {
+				t.Errorf("expected the diagnostic in /app/card.ts, got %s", diag.File)
+			}
+		}
+	})
+}
+
+func TestStrictNullChecks(t *testing.T) {
+	sf := reflection.ReflectSourceFile("/app/card.ts", `import { Component, signal } from '@angular/core';
+
+@Component({
+  selector: 'app-card',
+  standalone: true,
+  template: '{{ title?.length }} {{ user?.name }} {{ user!.name }} {{ count!.toFixed() }} {{ loose?.a }} {{ label?.length }} {{ total()?.x }}',
+})
+export class CardComponent {
+  title = '';
+  user: User | null = null;
+  count: number = 0;
+  loose;
+  label?: string;
+  total = signal(1);
+}
+`)
+	analyze := func(strict bool) []*diagnostics.Diagnostic {
+		compiler := annotations.NewCompiler([]*reflection.SourceFile{sf}, annotations.Options{RootDir: "/app", CheckTemplates: true, StrictNullChecks: strict})
+		return compiler.Analyze()
+	}
+
+	t.Run("should not check null safety by default", func(t *testing.T) {
+		if diags := analyze(false); len(diags) != 0 {
+			t.Errorf("unexpected diagnostics: %v", diags)
+		}
+	})
+
+	t.Run("should report null-safe operators on members which are never null", func(t *testing.T) {
+		diags := analyze(true)
+		if len(diags) != 2 {
+			t.Fatalf("expected 2 diagnostics, got %d: %v", len(diags), diags)
+		}
+		if diags[0].Code != diagnostics.OptionalChainNotNullable || diags[0].Span.String() != "?.length" {
+			t.Errorf("unexpected diagnostic: %v", diags[0])
+		}
+		if diags[1].Code != diagnostics.UnnecessaryNonNullAssertion || diags[1].Category != diagnostics.CategoryWarning {
+			t.Errorf("unexpected diagnostic: %v", diags[1])
+		}
+	})
+}
+
+func TestExtendedDiagnostics(t *testing.T) {
+	sf := reflection.ReflectSourceFile("/app/card.ts", `import { Component, signal } from '@angular/core';
+
+@Component({
+  selector: 'app-card',
+  standalone: true,
+  template: '<div ([title])="name"></div>{{ count }}<button (click)="save"></button>',
+})
+export class CardComponent {
+  name = '';
+  count = signal(0);
+  save() {}
+}
+`)
+	analyze := func(options *extended.Options) []*diagnostics.Diagnostic {
+		compiler := annotations.NewCompiler([]*reflection.SourceFile{sf}, annotations.Options{RootDir: "/app", ExtendedDiagnostics: options})
+		return compiler.Analyze()
+	}
+
+	t.Run("should not run the extended diagnostics by default", func(t *testing.T) {
+		if diags := analyze(nil); len(diags) != 0 {
+			t.Errorf("unexpected diagnostics: %v", diags)
+		}
+	})
+
+	t.Run("should run the extended diagnostics with their configured category", func(t *testing.T) {
+		diags := analyze(&extended.Options{Checks: map[string]extended.DiagnosticCategoryLabel{
+			"invalidBananaInBox":              extended.CategoryLabelError,
+			"uninvokedFunctionInEventBinding": extended.CategoryLabelSuppress,
+		}})
+		codes := map[diagnostics.ErrorCode]diagnostics.Category{}
+		for _, diag := range diags {
+			codes[diag.Code] = diag.Category
+		}
+		if len(diags) != 2 || codes[diagnostics.InvalidBananaInBox] != diagnostics.CategoryError || codes[diagnostics.InterpolatedSignalNotInvoked] != diagnostics.CategoryWarning {
+			t.Errorf("expected an invalidBananaInBox error and an interpolatedSignalNotInvoked warning, got %v", diags)
+		}
+	})
+
+	t.Run("should report invalid options", func(t *testing.T) {
+		diags := analyze(&extended.Options{DefaultCategory: "suppress", Checks: map[string]extended.DiagnosticCategoryLabel{"nope": "error"}})
+		if len(diags) != 1 || diags[0].Code != diagnostics.ConfigExtendedDiagnosticsUnknownCheck {
+			t.Errorf("expected an unknown check, got %v", diags)
+		}
+	})
+}
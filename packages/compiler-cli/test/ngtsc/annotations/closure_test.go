@@ -0,0 +1,63 @@
+package annotations_test
+
+import (
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+const closureSource = `import { Component } from '@angular/core';
+
+@Component({
+  selector: 'app-root',
+  standalone: true,
+  template: '<p i18n="greeting|Says hello">Hello {{ name }}!</p><ng-content select="header"></ng-content><ng-content></ng-content>',
+})
+export class App { name = 'x'; }
+`
+
+// compileClosure compiles closureSource in full, for JavaScript or TypeScript output.
+func compileClosure(t *testing.T, closure bool, typescript bool) string {
+	t.Helper()
+	sf := reflection.ReflectSourceFile("/app/app.ts", closureSource)
+	compiler := annotations.NewCompiler([]*reflection.SourceFile{sf}, annotations.Options{RootDir: "/app", ClosureCompiler: closure})
+	if diags := compiler.Analyze(); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if typescript {
+		return compiler.EmitTypeScript(sf)
+	}
+	return compiler.EmitFullModule(sf)
+}
+
+func TestClosureCompiler(t *testing.T) {
+	t.Run("should annotate the definitions and constants for Closure Compiler", func(t *testing.T) {
+		source := compileClosure(t, true, false)
+		expectContains(t, source,
+			"/** @nocollapse */ App.ɵfac = function App_Factory(",
+			"/** @nocollapse */ App.ɵcmp = i0.ɵɵdefineComponent(",
+			"/** @const */ var _c0 = ",
+			// The JSDoc of the message, whose lines expectContains joins.
+			"/*** @desc Says hello* @meaning greeting* @const*/var MSG_APP_TS_0 = goog.getMsg(",
+		)
+	})
+
+	t.Run("should annotate the static fields of TypeScript output", func(t *testing.T) {
+		expectContains(t, compileClosure(t, true, true),
+			"/** @nocollapse */ static ɵfac: i0.ɵɵFactoryDeclaration<App, never> = ",
+			"/** @nocollapse */ static ɵcmp: ",
+		)
+	})
+
+	t.Run("should only print the description of messages by default", func(t *testing.T) {
+		source := compileClosure(t, false, false)
+		for _, annotation := range []string{"@nocollapse", "@const"} {
+			if strings.Contains(source, annotation) {
+				t.Errorf("expected no %s annotation, got:\n%s", annotation, source)
+			}
+		}
+		expectContains(t, source, "/*** @desc Says hello* @meaning greeting*/var MSG_APP_TS_0 = goog.getMsg(")
+	})
+}
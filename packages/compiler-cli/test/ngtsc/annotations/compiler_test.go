@@ -0,0 +1,341 @@
+package annotations_test
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+// compile analyzes the given files and returns the partial module of each of them.
+func compile(t *testing.T, files map[string]string, resources map[string]string) (map[string]string, []*diagnostics.Diagnostic) {
+	t.Helper()
+	var sources []*reflection.SourceFile
+	for name, text := range files {
+		sources = append(sources, reflection.ReflectSourceFile(name, text))
+	}
+	compiler := annotations.NewCompiler(sources, annotations.Options{
+		RootDir: "/lib",
+		ResourceLoader: func(path string) (string, error) {
+			if content, ok := resources[path]; ok {
+				return content, nil
+			}
+			return "", fmt.Errorf("%s not found", path)
+		},
+	})
+	diags := compiler.Analyze()
+	modules := make(map[string]string)
+	for _, sf := range sources {
+		modules[sf.FileName] = compiler.EmitPartialModule(sf)
+	}
+	return modules, diags
+}
+
+// expectContains checks the output ignoring the line breaks the emitter adds to long lines.
+func expectContains(t *testing.T, source string, parts ...string) {
+	t.Helper()
+	unwrapped := lineBreak.ReplaceAllString(source, "")
+	for _, part := range parts {
+		if !strings.Contains(unwrapped, part) {
+			t.Errorf("expected output to contain %q, got:\n%s", part, source)
+		}
+	}
+}
+
+var lineBreak = regexp.MustCompile(`\n +`)
+
+func errorCodes(diags []*diagnostics.Diagnostic) []diagnostics.ErrorCode {
+	var codes []diagnostics.ErrorCode
+	for _, diag := range diags {
+		codes = append(codes, diag.Code)
+	}
+	return codes
+}
+
+func TestPartialCompilation(t *testing.T) {
+	t.Run("should declare a standalone component and its dependencies", func(t *testing.T) {
+		modules, diags := compile(t, map[string]string{
+			"/lib/greet.component.ts": `import { Component, Input } from '@angular/core';
+import { NgIf } from '@angular/common';
+import { ShoutPipe } from './shout.pipe';
+
+@Component({
+  selector: 'lib-greet',
+  imports: [NgIf, ShoutPipe],
+  template: '<p *ngIf="name">{{ name | shout }}</p>',
+})
+export class GreetComponent {
+  @Input() name = '';
+}
+`,
+			"/lib/shout.pipe.ts": `import { Pipe } from '@angular/core';
+
+@Pipe({ name: 'shout' })
+export class ShoutPipe {
+  transform(value: string) { return value.toUpperCase(); }
+}
+`,
+		}, nil)
+		if len(diags) != 0 {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+		expectContains(t, modules["/lib/greet.component.ts"],
+			"import { GreetComponent } from './greet.component.js';",
+			"import { NgIf } from '@angular/common';",
+			"import { ShoutPipe } from './shout.pipe.js';",
+			"import * as i0 from '@angular/core';",
+			"GreetComponent.ɵfac = i0.ɵɵngDeclareFactory({minVersion:'12.0.0',version:'0.0.0-PLACEHOLDER'",
+			"GreetComponent.ɵcmp = i0.ɵɵngDeclareComponent({minVersion:'14.0.0',version:'0.0.0-PLACEHOLDER'",
+			"selector:'lib-greet'",
+			"inputs:{name:'name'}",
+			"{kind:'pipe',type:ShoutPipe,",
+			"i0.ɵɵngDeclareClassMetadata(",
+			"propDecorators:{name:[{type:Input}]}",
+			"export * from './greet.component.js';",
+		)
+		expectContains(t, modules["/lib/shout.pipe.ts"],
+			"ShoutPipe.ɵpipe = i0.ɵɵngDeclarePipe(",
+			"isStandalone:true,name:'shout'",
+		)
+	})
+
+	t.Run("should use the declarations of the NgModule as the scope of a component", func(t *testing.T) {
+		modules, diags := compile(t, map[string]string{
+			"/lib/module.ts": `import { Component, Directive, NgModule } from '@angular/core';
+
+@Directive({ selector: '[libBold]', standalone: false })
+export class BoldDirective {}
+
+@Directive({ selector: '[libItalic]', standalone: false })
+export class ItalicDirective {}
+
+@Component({ selector: 'lib-text', templateUrl: './text.html', standalone: false })
+export class TextComponent {}
+
+@NgModule({ declarations: [BoldDirective, ItalicDirective, TextComponent], exports: [TextComponent] })
+export class TextModule {}
+`,
+		}, map[string]string{"/lib/text.html": "<b libBold>text</b>"})
+		if len(diags) != 0 {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+		source := modules["/lib/module.ts"]
+		expectContains(t, source,
+			"dependencies:[{kind:'directive',type:BoldDirective,selector:'[libBold]'}]",
+			"TextModule.ɵmod = i0.ɵɵngDeclareNgModule(",
+			"declarations:[BoldDirective,ItalicDirective,TextComponent]",
+			"exports:[TextComponent]",
+			"TextModule.ɵinj = i0.ɵɵngDeclareInjector(",
+		)
+		if strings.Contains(source, "kind:'directive',type:ItalicDirective") {
+			t.Errorf("expected unused directives to be left out of the dependencies, got:\n%s", source)
+		}
+	})
+
+	t.Run("should keep the imports of an NgModule which are not classes as dependencies", func(t *testing.T) {
+		modules, diags := compile(t, map[string]string{
+			"/lib/shell.module.ts": `import { Component, NgModule } from '@angular/core';
+import { RouterModule } from '@angular/router';
+import { ShellComponent } from './shell.component';
+import { DEV, DevModule, SHARED } from './shared';
+
+@Component({ selector: 'lib-page', template: '<lib-card></lib-card>', standalone: false })
+export class PageComponent {}
+
+@NgModule({ declarations: [ShellComponent, PageComponent], imports: [RouterModule.forChild([]), ...SHARED, DEV ? [DevModule] : []] })
+export class ShellModule {}
+`,
+			"/lib/shell.component.ts": `import { Component } from '@angular/core';
+
+@Component({ selector: 'lib-shell', template: '<router-outlet></router-outlet>', standalone: false })
+export class ShellComponent {}
+`,
+			"/lib/shared.ts": "export const SHARED = [];\nexport const DEV = false;\nexport class DevModule {}\n",
+		}, nil)
+		expected := []diagnostics.ErrorCode{diagnostics.NgmoduleInvalidImport}
+		if got := errorCodes(diags); fmt.Sprint(got) != fmt.Sprint(expected) {
+			t.Fatalf("expected error codes %v, got %v", expected, got)
+		}
+		expectContains(t, modules["/lib/shell.module.ts"],
+			"dependencies:[{kind:'ngmodule',type:RouterModule},{kind:'ngmodule',type:SHARED},{kind:'ngmodule',type:DEV ? [DevModule] : []}]",
+		)
+		// The conditional cannot be referenced from the other file, which is reported instead.
+		expectContains(t, modules["/lib/shell.component.ts"],
+			"import * as i2 from './shared.js';",
+			"dependencies:[{kind:'ngmodule',type:i0.forwardRef(() =>i1.RouterModule)},{kind:'ngmodule',type:i0.forwardRef(() =>i2.SHARED)}]",
+		)
+	})
+
+	t.Run("should read the kind of the classes of packages from their declaration files", func(t *testing.T) {
+		modules, diags := compile(t, map[string]string{
+			"/lib/card.component.ts": `import { Component, NgModule } from '@angular/core';
+import { CommonModule, DatePipe, NgIf } from '@angular/common';
+
+@Component({
+  selector: 'lib-card',
+  imports: [NgIf, DatePipe],
+  template: '<p *ngIf="day">{{ day | date }}</p>',
+})
+export class CardComponent {}
+
+@Component({ selector: 'lib-badge', template: '<b>badge</b>', standalone: false })
+export class BadgeComponent {}
+
+@NgModule({ declarations: [BadgeComponent], imports: [CommonModule] })
+export class BadgeModule {}
+`,
+		}, map[string]string{
+			"/lib/node_modules/@angular/common/package.json": `{"name": "@angular/common", "typings": "./index.d.ts"}`,
+			"/lib/node_modules/@angular/common/index.d.ts":   "export { CommonModule, DatePipe, NgIf } from './common_module.d-Bi9Uoq5n';\n",
+			"/lib/node_modules/@angular/common/common_module.d-Bi9Uoq5n.d.ts": `import * as i0 from '@angular/core';
+import { TemplateRef } from '@angular/core';
+declare class NgIf<T = unknown> {
+    constructor(templateRef: TemplateRef<unknown>);
+    static ɵdir: i0.ɵɵDirectiveDeclaration<NgIf<any>, "[ngIf]", never, { "ngIf": { "alias": "ngIf"; "required": false; }; "ngIfElse": { "alias": "ngIfElse"; "required": false; }; }, {}, never, never, true, never>;
+}
+declare class DatePipe {
+    static ɵpipe: i0.ɵɵPipeDeclaration<DatePipe, "date", true>;
+}
+declare class CommonModule {
+    static ɵmod: i0.ɵɵNgModuleDeclaration<CommonModule, never, [typeof NgIf, typeof DatePipe], [typeof NgIf, typeof DatePipe]>;
+}
+export { CommonModule, DatePipe, NgIf };
+`,
+		})
+		if len(diags) != 0 {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+		expectContains(t, modules["/lib/card.component.ts"],
+			"dependencies:[{kind:'directive',type:NgIf,selector:'[ngIf]',inputs:['ngIf','ngIfElse']},{kind:'pipe',type:DatePipe,name:'date'}]",
+			"dependencies:[{kind:'ngmodule',type:CommonModule}]",
+		)
+	})
+
+	t.Run("should declare injectables with their dependencies", func(t *testing.T) {
+		modules, diags := compile(t, map[string]string{
+			"/lib/store.ts": `import { Injectable, Inject, Optional } from '@angular/core';
+import { CONFIG } from './config';
+
+@Injectable({ providedIn: 'root' })
+export class Store {
+  constructor(@Optional() @Inject(CONFIG) config: unknown) {}
+}
+`,
+		}, nil)
+		if len(diags) != 0 {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+		expectContains(t, modules["/lib/store.ts"],
+			"import { CONFIG } from './config.js';",
+			"deps:[{token:CONFIG,optional:true}]",
+			"Store.ɵprov = i0.ɵɵngDeclareInjectable(",
+			"providedIn:'root'",
+		)
+	})
+
+	t.Run("should report invalid decorators", func(t *testing.T) {
+		modules, diags := compile(t, map[string]string{
+			"/lib/invalid.ts": `import { Component, Directive, Injectable, Pipe } from '@angular/core';
+
+@Pipe({ pure: false })
+export class NamelessPipe {}
+
+@Injectable()
+export class NeedsToken {
+  constructor(value: string) {}
+}
+
+@Component({ selector: 'lib-both', template: '' })
+@Directive({ selector: '[libBoth]' })
+export class Both {}
+`,
+		}, nil)
+		expected := []diagnostics.ErrorCode{diagnostics.DecoratorCollision, diagnostics.PipeMissingName, diagnostics.ParamMissingToken}
+		if got := errorCodes(diags); fmt.Sprint(got) != fmt.Sprint(expected) {
+			t.Errorf("expected error codes %v, got %v", expected, got)
+		}
+		source := modules["/lib/invalid.ts"]
+		if strings.Contains(source, "NamelessPipe.ɵ") || strings.Contains(source, "NeedsToken.ɵ") {
+			t.Errorf("expected invalid classes not to be compiled, got:\n%s", source)
+		}
+	})
+
+	t.Run("should report standalone declarations of NgModules", func(t *testing.T) {
+		_, diags := compile(t, map[string]string{
+			"/lib/module.ts": `import { Component, NgModule, Pipe } from '@angular/core';
+
+@Component({ selector: 'lib-card', template: '' })
+export class CardComponent {}
+
+@Pipe({ name: 'upper', standalone: true })
+export class UpperPipe {}
+
+@Component({ selector: 'lib-list', template: '', standalone: false })
+export class ListComponent {}
+
+@NgModule({ declarations: [CardComponent, UpperPipe, ListComponent] })
+export class ListModule {}
+`,
+		}, nil)
+		expected := []diagnostics.ErrorCode{diagnostics.NgmoduleDeclarationIsStandalone, diagnostics.NgmoduleDeclarationIsStandalone}
+		if got := errorCodes(diags); fmt.Sprint(got) != fmt.Sprint(expected) {
+			t.Fatalf("expected error codes %v, got %v", expected, got)
+		}
+		if msg := diags[0].Message; !strings.Contains(msg, "Component CardComponent is standalone") {
+			t.Errorf("unexpected message %q", msg)
+		}
+	})
+
+	t.Run("should print the sources without the decorators of the compiled classes", func(t *testing.T) {
+		sf := reflection.ReflectSourceFile("/lib/card.ts", `import { Component, Input, Pipe } from '@angular/core';
+import { Tracked } from './tracking';
+
+@Tracked()
+@Component({ selector: 'lib-card', template: '{{ title }}' })
+export class CardComponent {
+  @Input() title = '';
+}
+
+@Pipe({ pure: false })
+export class NamelessPipe {}
+`)
+		compiler := annotations.NewCompiler([]*reflection.SourceFile{sf}, annotations.Options{RootDir: "/lib"})
+		compiler.Analyze()
+		expected := `import { Component, Input, Pipe } from '@angular/core';
+import { Tracked } from './tracking';
+
+@Tracked()
+export class CardComponent {
+  title = '';
+}
+
+@Pipe({ pure: false })
+export class NamelessPipe {}
+`
+		if got := compiler.EmitUndecorated(sf); got != expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+
+	t.Run("should skip files without decorated classes", func(t *testing.T) {
+		modules, _ := compile(t, map[string]string{"/lib/util.ts": "export const answer = 42;\n"}, nil)
+		if modules["/lib/util.ts"] != "" {
+			t.Errorf("expected no output, got:\n%s", modules["/lib/util.ts"])
+		}
+	})
+}
+
+func TestParseCompilationMode(t *testing.T) {
+	for _, value := range []string{"full", "partial"} {
+		if mode, err := annotations.ParseCompilationMode(value); err != nil || string(mode) != value {
+			t.Errorf("ParseCompilationMode(%q) = %q, %v", value, mode, err)
+		}
+	}
+	if _, err := annotations.ParseCompilationMode("local"); err == nil {
+		t.Errorf("expected an error for an unknown compilation mode")
+	}
+}
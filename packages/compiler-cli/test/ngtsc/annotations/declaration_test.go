@@ -0,0 +1,205 @@
+package annotations_test
+
+import (
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+const counterComponent = `import { Component, EventEmitter, Input, Output, inject, input, model, output, viewChild, ElementRef } from '@angular/core';
+import { Logger } from './logger';
+import './polyfills';
+
+export interface Step {
+  size: number;
+}
+
+export type Mode = 'up' | 'down'
+
+export const DEFAULT_STEP = 1;
+
+@Component({ selector: 'lib-counter', template: '<button #button (click)="increment()">{{ count() }}</button>' })
+export class CounterComponent<T = number> {
+  @Input() label = 'Count';
+  @Input({ required: true }) step!: Step;
+  @Output() reset = new EventEmitter<void>();
+  count = model(0);
+  max = input.required<number>();
+  min = input<number>();
+  changed = output<number>();
+  button = viewChild<ElementRef>('button');
+  private logger = inject(Logger);
+  #ticks = 0;
+  static instances = 0;
+
+  constructor(protected readonly host: ElementRef) {}
+
+  increment(by = 1) {
+    this.count.update((value) => value + by);
+  }
+
+  get doubled(): number { return this.count() * 2; }
+
+  format(value: number): string;
+  format(value: string): string;
+  format(value: any) {
+    return String(value);
+  }
+}
+
+function helper() {}
+
+export function createStep({ size }: Step, ...rest: number[]): Step {
+  return { size };
+}
+`
+
+func emitDeclarations(t *testing.T, name string, files map[string]string) string {
+	t.Helper()
+	source, diags := emitDeclarationsWithDiagnostics(t, name, files)
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	return source
+}
+
+func emitDeclarationsWithDiagnostics(t *testing.T, name string, files map[string]string) (string, []*diagnostics.Diagnostic) {
+	t.Helper()
+	var sources []*reflection.SourceFile
+	for fileName, text := range files {
+		sources = append(sources, reflection.ReflectSourceFile(fileName, text))
+	}
+	compiler := annotations.NewCompiler(sources, annotations.Options{RootDir: "/lib"})
+	if diags := compiler.Analyze(); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	for _, sf := range sources {
+		if sf.FileName == name {
+			return compiler.EmitDeclarations(sf)
+		}
+	}
+	t.Fatalf("no file %s", name)
+	return "", nil
+}
+
+func TestEmitDeclarations(t *testing.T) {
+	t.Run("should declare the definitions of components as typed static fields", func(t *testing.T) {
+		source := emitDeclarations(t, "/lib/counter.component.ts", map[string]string{
+			"/lib/counter.component.ts": counterComponent,
+		})
+		expectContains(t, source,
+			"import { Logger } from './logger';\nimport * as i0 from '@angular/core';\n",
+			"export declare class CounterComponent<T = number> {",
+			"static ɵfac: i0.ɵɵFactoryDeclaration<CounterComponent<any>, never>;",
+			"static ɵcmp: i0.ɵɵComponentDeclaration<CounterComponent<any>, 'lib-counter', never, {'count':{'alias':'count','required':false,'isSignal':true},'label':{'alias':'label','required':false},",
+			"{'changed':'changed','countChange':'countChange','reset':'reset'}, never, never, true, never>;",
+		)
+		if strings.Contains(source, "polyfills") || strings.Contains(source, "@Component") {
+			t.Errorf("expected side-effect imports and decorators to be dropped, got:\n%s", source)
+		}
+	})
+
+	t.Run("should type members from their annotations and initializers", func(t *testing.T) {
+		source := emitDeclarations(t, "/lib/counter.component.ts", map[string]string{
+			"/lib/counter.component.ts": counterComponent,
+		})
+		members := strings.Join([]string{
+			"  #private;",
+			"  protected readonly host: ElementRef;",
+			"  label: string;",
+			"  step: Step;",
+			"  reset: EventEmitter<void>;",
+			"  count: i0.ModelSignal<number>;",
+			"  max: i0.InputSignal<number>;",
+			"  min: i0.InputSignal<number | undefined>;",
+			"  changed: i0.OutputEmitterRef<number>;",
+			"  button: i0.Signal<ElementRef | undefined>;",
+			"  private logger;",
+			"  static instances: number;",
+			"  constructor(host: ElementRef);",
+			"  increment(by?: number): void;",
+			"  get doubled(): number;",
+			"  format(value: number): string;",
+			"  format(value: string): string;",
+		}, "\n")
+		if !strings.Contains(source, members) {
+			t.Errorf("expected members\n%s\ngot:\n%s", members, source)
+		}
+	})
+
+	t.Run("should declare top-level statements", func(t *testing.T) {
+		source := emitDeclarations(t, "/lib/counter.component.ts", map[string]string{
+			"/lib/counter.component.ts": counterComponent,
+		})
+		if interfaceDecl := "export interface Step {\n  size: number;\n}\n"; !strings.Contains(source, interfaceDecl) {
+			t.Errorf("expected %q, got:\n%s", interfaceDecl, source)
+		}
+		expectContains(t, source,
+			"export type Mode = 'up' | 'down';\n",
+			"export declare const DEFAULT_STEP = 1;\n",
+			"export declare function createStep(__0: Step, ...rest: number[]): Step;\n",
+		)
+		if strings.Contains(source, "helper") {
+			t.Errorf("expected local functions to be dropped, got:\n%s", source)
+		}
+	})
+
+	t.Run("should keep the declarations exported by export lists", func(t *testing.T) {
+		source := emitDeclarations(t, "/lib/token.ts", map[string]string{
+			"/lib/token.ts": "const TOKEN = new InjectionToken<string>('token');\nexport { TOKEN };\n",
+		})
+		expected := "declare const TOKEN: InjectionToken<string>;\nexport { TOKEN };\n"
+		if source != expected {
+			t.Errorf("expected %q, got %q", expected, source)
+		}
+	})
+
+	t.Run("should keep the implements clauses of classes", func(t *testing.T) {
+		source := emitDeclarations(t, "/lib/tabs.ts", map[string]string{
+			"/lib/tabs.ts": "export class Tabs extends Base<{ id: string }> implements OnInit, Store<{ tab: number }> {\n  ngOnInit(): void {}\n}\n",
+		})
+		expectContains(t, source, "export declare class Tabs extends Base<{ id: string }> implements OnInit, Store<{ tab: number }> {")
+	})
+
+	t.Run("should report the types which can only be inferred from an expression", func(t *testing.T) {
+		source, diags := emitDeclarationsWithDiagnostics(t, "/lib/util.ts", map[string]string{
+			"/lib/util.ts": `export class Util {
+  size = compute();
+  get half() { return this.size / 2; }
+  total(): number { return this.size; }
+  double() {
+    return this.size * 2;
+  }
+}
+
+export const limit = compute();
+export function scale(by = factor) {
+  return by;
+}
+`,
+		})
+		var messages []string
+		for _, diag := range diags {
+			if diag.Code != diagnostics.DeclarationTypeNotInferred || diag.Category != diagnostics.CategoryError {
+				t.Errorf("expected a DeclarationTypeNotInferred error, got %v", diag)
+			}
+			messages = append(messages, diag.Message)
+		}
+		expected := []string{
+			"The type of 'size' cannot be inferred from its initializer for the declaration file, add a type annotation.",
+			"The type of the accessor 'half' cannot be inferred for the declaration file, add a return type annotation.",
+			"The return type of 'double' cannot be inferred for the declaration file, add a return type annotation.",
+			"The type of 'limit' cannot be inferred from its initializer for the declaration file, add a type annotation.",
+			"The type of 'by' cannot be inferred from its initializer for the declaration file, add a type annotation.",
+			"The return type of 'scale' cannot be inferred for the declaration file, add a return type annotation.",
+		}
+		if got := strings.Join(messages, "\n"); got != strings.Join(expected, "\n") {
+			t.Errorf("expected the diagnostics\n%s\ngot:\n%s", strings.Join(expected, "\n"), got)
+		}
+		// The annotated types are kept.
+		expectContains(t, source, "total(): number;")
+	})
+}
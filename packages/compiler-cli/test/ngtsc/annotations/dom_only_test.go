@@ -0,0 +1,102 @@
+package annotations_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+const domOnlySource = `import { Component, Directive, NgModule } from '@angular/core';
+import { CommonModule } from '@angular/common';
+
+@Directive({ selector: '[libBold]', standalone: false })
+export class BoldDirective {}
+
+@Component({ selector: 'lib-label', template: '<span [title]="title">{{ title }}</span>', standalone: false })
+export class LabelComponent {}
+
+@Component({ selector: 'lib-text', template: '<b libBold>text</b>', standalone: false })
+export class TextComponent {}
+
+@NgModule({ declarations: [BoldDirective, LabelComponent, TextComponent] })
+export class TextModule {}
+
+@Component({ selector: 'lib-list', template: '<ul></ul>', standalone: false })
+export class ListComponent {}
+
+@NgModule({ declarations: [ListComponent], imports: [CommonModule] })
+export class ListModule {}
+
+@Component({ selector: 'lib-icon', template: '<i></i>' })
+export class IconComponent {}
+`
+
+// compileDomOnly compiles domOnlySource in full and returns its module and DOM-only components.
+func compileDomOnly(t *testing.T, domOnly bool) (string, []string) {
+	t.Helper()
+	sf := reflection.ReflectSourceFile("/lib/text.ts", domOnlySource)
+	compiler := annotations.NewCompiler([]*reflection.SourceFile{sf}, annotations.Options{RootDir: "/lib", DomOnly: domOnly})
+	if diags := compiler.Analyze(); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	return compiler.EmitFullModule(sf), compiler.DomOnlyComponents()
+}
+
+func TestDomOnlyMode(t *testing.T) {
+	t.Run("should only compile standalone components in DOM-only mode by default", func(t *testing.T) {
+		source, components := compileDomOnly(t, false)
+		expectContains(t, source,
+			"i0.ɵɵelementStart(0,'span',0);",
+			"i0.ɵɵdomElement(0,'i');",
+		)
+		if want := []string{"IconComponent"}; !reflect.DeepEqual(components, want) {
+			t.Errorf("expected DOM-only components %v, got %v", want, components)
+		}
+	})
+
+	t.Run("should compile the components of NgModules matching no directives in DOM-only mode", func(t *testing.T) {
+		source, components := compileDomOnly(t, true)
+		expectContains(t, source,
+			"i0.ɵɵdomElementStart(0,'span',0);",
+			"i0.ɵɵdomProperty('title',ctx.title);",
+			"i0.ɵɵelementStart(0,'b',0);",
+			"i0.ɵɵelement(0,'ul');",
+			"i0.ɵɵdomElement(0,'i');",
+		)
+		if strings.Contains(source, "i0.ɵɵelementStart(0,'span'") {
+			t.Errorf("expected LabelComponent to use DOM-only instructions, got:\n%s", source)
+		}
+		if want := []string{"LabelComponent", "IconComponent"}; !reflect.DeepEqual(components, want) {
+			t.Errorf("expected DOM-only components %v, got %v", want, components)
+		}
+	})
+	t.Run("should not compile the components of NgModules with unresolved imports or exports in DOM-only mode", func(t *testing.T) {
+		sf := reflection.ReflectSourceFile("/lib/routed.ts", `import { Component, NgModule } from '@angular/core';
+import { RouterModule } from '@angular/router';
+import { SHARED } from './shared';
+
+@Component({ selector: 'lib-routed', template: '<router-outlet></router-outlet>', standalone: false })
+export class RoutedComponent {}
+
+@NgModule({ declarations: [RoutedComponent], imports: [RouterModule.forChild([])] })
+export class RoutedModule {}
+
+@Component({ selector: 'lib-shared', template: '<lib-card></lib-card>', standalone: false })
+export class SharedComponent {}
+
+@NgModule({ declarations: [SharedComponent], exports: [...SHARED] })
+export class SharedModule {}
+`)
+		compiler := annotations.NewCompiler([]*reflection.SourceFile{sf}, annotations.Options{RootDir: "/lib", DomOnly: true})
+		if diags := compiler.Analyze(); len(diags) != 0 {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+		if components := compiler.DomOnlyComponents(); len(components) != 0 {
+			t.Errorf("expected no DOM-only components, got %v", components)
+		}
+		expectContains(t, compiler.EmitFullModule(sf), "i0.ɵɵelement(0,'router-outlet');", "i0.ɵɵelement(0,'lib-card');")
+	})
+}
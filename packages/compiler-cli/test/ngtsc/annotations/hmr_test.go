@@ -0,0 +1,107 @@
+package annotations_test
+
+import (
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+const greetComponent = `import { Component } from '@angular/core';
+import { ShoutPipe } from './shout.pipe';
+
+@Component({
+  selector: 'lib-greet',
+  imports: [ShoutPipe],
+  template: '<p>{{ name | shout }}</p>',
+})
+export class GreetComponent {
+  name = '';
+}
+`
+
+const shoutPipe = `import { Pipe } from '@angular/core';
+
+@Pipe({ name: 'shout' })
+export class ShoutPipe {
+  transform(value: string) { return value.toUpperCase(); }
+}
+`
+
+// compileHmr analyzes the given files with HMR enabled and returns the compiler and the first
+// file.
+func compileHmr(t *testing.T, files map[string]string) (*annotations.Compiler, *reflection.SourceFile) {
+	t.Helper()
+	var sources []*reflection.SourceFile
+	for _, name := range []string{"/lib/greet.component.ts", "/lib/shout.pipe.ts"} {
+		sources = append(sources, reflection.ReflectSourceFile(name, files[name]))
+	}
+	compiler := annotations.NewCompiler(sources, annotations.Options{RootDir: "/lib", Hmr: true})
+	if diags := compiler.Analyze(); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	return compiler, sources[0]
+}
+
+func TestHmr(t *testing.T) {
+	t.Run("should emit full definitions with an HMR initializer", func(t *testing.T) {
+		compiler, sf := compileHmr(t, map[string]string{"/lib/greet.component.ts": greetComponent, "/lib/shout.pipe.ts": shoutPipe})
+		expectContains(t, compiler.EmitFullModule(sf),
+			"import { GreetComponent } from './greet.component.js';",
+			"GreetComponent.ɵfac = function GreetComponent_Factory(__ngFactoryType__) {",
+			"GreetComponent.ɵcmp = i0.ɵɵdefineComponent({type:GreetComponent,",
+			"dependencies:[ShoutPipe]",
+			"i0.ɵsetClassMetadata(GreetComponent,",
+			"var id = 'greet.component.ts%40GreetComponent';",
+			"i0.ɵɵreplaceMetadata(GreetComponent,m.default,[i0],[ShoutPipe,Component],import.meta,id)",
+			"import.meta.hot.on('angular:component-update',",
+		)
+	})
+
+	t.Run("should not emit HMR initializers for other classes", func(t *testing.T) {
+		compiler, _ := compileHmr(t, map[string]string{"/lib/greet.component.ts": greetComponent, "/lib/shout.pipe.ts": shoutPipe})
+		pipe := reflection.ReflectSourceFile("/lib/shout.pipe.ts", shoutPipe)
+		if updates := compiler.HmrUpdates(pipe); len(updates) != 0 {
+			t.Errorf("expected no updates, got %d", len(updates))
+		}
+	})
+
+	t.Run("should compile the update module of a component", func(t *testing.T) {
+		compiler, sf := compileHmr(t, map[string]string{"/lib/greet.component.ts": greetComponent, "/lib/shout.pipe.ts": shoutPipe})
+		updates := compiler.HmrUpdates(sf)
+		if len(updates) != 1 {
+			t.Fatalf("expected one update, got %d", len(updates))
+		}
+		update := updates[0]
+		if update.ID != "greet.component.ts%40GreetComponent" || update.ClassName != "GreetComponent" {
+			t.Errorf("unexpected update %s of %s", update.ID, update.ClassName)
+		}
+		if got := strings.Join(update.Dependencies, ","); got != "@angular/core,ShoutPipe,Component" {
+			t.Errorf("unexpected dependencies %s", got)
+		}
+		expectContains(t, update.Module,
+			"export default function GreetComponent_UpdateMetadata(GreetComponent,ɵɵnamespaces,ShoutPipe,Component) {",
+			"var i0 = ɵɵnamespaces[0];",
+			"GreetComponent.ɵcmp = i0.ɵɵdefineComponent({type:GreetComponent,",
+		)
+		if strings.Contains(update.Module, "import ") {
+			t.Errorf("expected the update module not to import anything, got:\n%s", update.Module)
+		}
+	})
+
+	t.Run("should keep the dependencies when only the template changes", func(t *testing.T) {
+		compiler, sf := compileHmr(t, map[string]string{"/lib/greet.component.ts": greetComponent, "/lib/shout.pipe.ts": shoutPipe})
+		changed := strings.Replace(greetComponent, "<p>", "<p>Hi ", 1)
+		changedCompiler, changedSf := compileHmr(t, map[string]string{"/lib/greet.component.ts": changed, "/lib/shout.pipe.ts": shoutPipe})
+
+		before, after := compiler.HmrUpdates(sf)[0], changedCompiler.HmrUpdates(changedSf)[0]
+		if strings.Join(before.Dependencies, ",") != strings.Join(after.Dependencies, ",") {
+			t.Errorf("expected the dependencies to be unchanged, got %v and %v", before.Dependencies, after.Dependencies)
+		}
+		if before.Module == after.Module {
+			t.Errorf("expected the update module to change")
+		}
+		expectContains(t, after.Module, "'Hi '")
+	})
+}
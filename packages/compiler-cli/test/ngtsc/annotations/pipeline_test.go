@@ -0,0 +1,272 @@
+package annotations_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler/src/template/pipeline"
+	"ngc-go/packages/compiler/src/template/pipeline/ir"
+	ops_create "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/create"
+	"ngc-go/packages/compiler/src/template/pipeline/src/compilation"
+)
+
+// emitFull compiles a standalone component with a template and returns its full module.
+func emitFull(t *testing.T, template string) string {
+	t.Helper()
+	sf := reflection.ReflectSourceFile("/lib/app.component.ts", `import {Component} from '@angular/core';
+
+@Component({
+  selector: 'app-root',
+  standalone: true,
+  template: `+"`"+template+"`"+`,
+})
+export class AppComponent {}
+`)
+	compiler := annotations.NewCompiler([]*reflection.SourceFile{sf}, annotations.Options{RootDir: "/lib"})
+	if diags := compiler.Analyze(); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	return compiler.EmitFullModule(sf)
+}
+
+func TestOptimizeVariables(t *testing.T) {
+	t.Run("should remove unused variables", func(t *testing.T) {
+		source := emitFull(t, `<input #box>@for (item of items; track item.id; let i = $index) { <p>{{ item.name }}</p> }`)
+		expectContains(t, source, "i0.ɵɵtextInterpolate(item_r1.name);")
+		for _, unused := range []string{"ctx.$index", "ctx.$count", "i0.ɵɵnextContext()", "i0.ɵɵreference(", "i0.ɵɵgetCurrentView()"} {
+			if strings.Contains(source, unused) {
+				t.Errorf("expected %q to be removed, got:\n%s", unused, source)
+			}
+		}
+	})
+
+	t.Run("should keep the side effects of unused variables", func(t *testing.T) {
+		source := emitFull(t, `@if (show) { <span (click)="go(box.value)">{{ name }}</span> }<input #box>`)
+		expectContains(t, source,
+			"i0.ɵɵrestoreView(_r1);",
+			"var ctx_r1 = i0.ɵɵnextContext();",
+			"return i0.ɵɵresetView(ctx_r1.go(box_r3.value));",
+		)
+	})
+
+	t.Run("should inline variables read once", func(t *testing.T) {
+		source := emitFull(t, `@if (user; as u) {<span>{{ u.name }}</span>}`)
+		expectContains(t, source, "i0.ɵɵtextInterpolate(ctx.name);")
+		if strings.Contains(source, "var u_") {
+			t.Errorf("expected the alias to be inlined, got:\n%s", source)
+		}
+	})
+}
+
+func TestGenerateAdvance(t *testing.T) {
+	t.Run("should advance to the element of every binding", func(t *testing.T) {
+		source := emitFull(t, `<span>{{x}}</span><p [title]="a" [attr.aria-label]="b"><i [style]="s" [class]="k"></i></p><b [class.on]="c"></b>`)
+		expectContains(t, source, strings.Join([]string{
+			"if (rf & 2) {",
+			"i0.ɵɵadvance();",
+			"i0.ɵɵtextInterpolate(ctx.x);",
+			"i0.ɵɵadvance();",
+			"i0.ɵɵdomProperty('title',ctx.a);",
+			"i0.ɵɵattribute('aria-label',ctx.b);",
+			"i0.ɵɵadvance();",
+			"i0.ɵɵstyleMap(ctx.s);",
+			"i0.ɵɵclassMap(ctx.k);",
+			"i0.ɵɵadvance();",
+			"i0.ɵɵclassProp('on',ctx.c);",
+			"}",
+		}, ""))
+	})
+
+	t.Run("should order the bindings of an element", func(t *testing.T) {
+		source := emitFull(t, `<p [attr.x]="x" [id]="i" [class.on]="c" [style.width.px]="w" [class]="k"></p>`)
+		expectContains(t, source, strings.Join([]string{
+			"i0.ɵɵclassMap(ctx.k);",
+			"i0.ɵɵstyleProp('width',ctx.w,'px');",
+			"i0.ɵɵclassProp('on',ctx.c);",
+			"i0.ɵɵdomProperty('id',ctx.i);",
+			"i0.ɵɵattribute('x',ctx.x);",
+		}, ""))
+	})
+}
+
+func TestCollectI18nConsts(t *testing.T) {
+	t.Run("should declare messages in the consts", func(t *testing.T) {
+		source := emitFull(t, `<div i18n>Hi <b>there</b> {{ name }}</div>`)
+		expectContains(t, source,
+			"consts:() =>{",
+			"var i18n_0;",
+			"var MSG_APP_COMPONENT_TS_0 = goog.getMsg('Hi {$startBoldText}there{$closeBoldText} {$interpolation}',{'closeBoldText':'\uFFFD/#2\uFFFD','interpolation':'\uFFFD0\uFFFD','startBoldText':'\uFFFD#2\uFFFD'},",
+			"{original_code:{'closeBoldText':'</b>','interpolation':'{{ name }}','startBoldText':'<b>'}});",
+			":START_BOLD_TEXT:there",
+			"return [i18n_0];",
+			"i0.ɵɵi18nStart(1,0);",
+		)
+	})
+
+	t.Run("should postprocess ICU sub-messages", func(t *testing.T) {
+		source := emitFull(t, `<div i18n>Items: {count, plural, =1 {one} other {{{ count }} many}}</div>`)
+		expectContains(t, source,
+			"goog.getMsg('{VAR_PLURAL, plural, =1 {one} other {{INTERPOLATION} many}}');",
+			"i18n_0 = i0.ɵɵi18nPostprocess(i18n_0,{'INTERPOLATION':'\uFFFD1\uFFFD','VAR_PLURAL':'\uFFFD0\uFFFD'});",
+			"goog.getMsg('Items: {$icu}',{'icu':i18n_0}",
+			"return [i18n_1];",
+		)
+	})
+
+	t.Run("should declare the messages of static i18n attributes", func(t *testing.T) {
+		source := emitFull(t, `<span i18n-title title="Tip">x</span>`)
+		expectContains(t, source,
+			"var MSG_APP_COMPONENT_TS_0 = goog.getMsg('Tip');",
+			":Tip'",
+			"return [['title',i18n_0]];",
+			"i0.ɵɵdomElementStart(0,'span',0);",
+		)
+		if strings.Contains(source, "ɵɵi18nAttributes") {
+			t.Errorf("expected a static i18n attribute to be extracted, got:\n%s", source)
+		}
+	})
+
+	t.Run("should declare the messages of interpolated i18n attributes", func(t *testing.T) {
+		source := emitFull(t, `<b i18n-title title="Hi {{ name }}">y</b>`)
+		expectContains(t, source,
+			"var MSG_APP_COMPONENT_TS_0 = goog.getMsg('Hi {$interpolation}',{'interpolation':'\uFFFD0\uFFFD'}",
+			"return [['title',i18n_0],[6,'title']];",
+			"i0.ɵɵdomElementStart(0,'b',1);",
+			"i0.ɵɵi18nAttributes(1,0);",
+			"i0.ɵɵi18nExp(ctx.name);",
+			"i0.ɵɵi18nApply(1);",
+		)
+	})
+}
+
+func TestResolveDeferDepsFns(t *testing.T) {
+	sf := reflection.ReflectSourceFile("/lib/app.component.ts", `import {Component} from '@angular/core';
+
+@Component({selector: 'cal', standalone: true, template: 'cal'})
+export class CalComponent {}
+
+@Component({selector: 'other', standalone: true, template: 'other'})
+export class OtherComponent {}
+
+@Component({
+  selector: 'app-root',
+  standalone: true,
+  imports: [CalComponent, OtherComponent],
+  template: `+"`"+`<other /> @defer { <cal /> } @defer (on idle) { <cal /> } @defer { <span></span> }`+"`"+`,
+})
+export class AppComponent {}
+`)
+	compiler := annotations.NewCompiler([]*reflection.SourceFile{sf}, annotations.Options{RootDir: "/lib"})
+	if diags := compiler.Analyze(); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	source := compiler.EmitFullModule(sf)
+	expectContains(t, source,
+		"var AppComponent_Defer_2_DepsFn = () =>[CalComponent];",
+		"i0.ɵɵdefer(2,1,AppComponent_Defer_2_DepsFn,",
+		"i0.ɵɵdefer(5,4,AppComponent_Defer_2_DepsFn,",
+		"i0.ɵɵdefer(8,7,null,",
+		"dependencies:[OtherComponent]",
+	)
+	if strings.Count(source, "_DepsFn = ") != 1 {
+		t.Errorf("expected a single shared resolver function, got:\n%s", source)
+	}
+}
+
+func TestTracePhases(t *testing.T) {
+	template := `@if (show) { <span (click)="go()">{{ name }}</span> }`
+	untraced := emitFull(t, template)
+
+	var out bytes.Buffer
+	pipeline.SetTraceOptions(&pipeline.TraceOptions{DumpIR: []string{"orderops", "Reify"}, Component: "AppComponent", Out: &out})
+	defer pipeline.SetTraceOptions(nil)
+	traced := emitFull(t, template)
+
+	if traced != untraced {
+		t.Errorf("expected tracing not to change the output, got:\n%s\nwant:\n%s", traced, untraced)
+	}
+	expectContains(t, out.String(),
+		"== AppComponent (template) ==",
+		"== AppComponent (host bindings) ==",
+		"-- IR after OrderOps --",
+		"-- IR after Reify --",
+		"ConditionalCreate Xref=",
+		"AppComponent_Template (view 0):",
+		"s  OptimizeVariables",
+		"s  total",
+	)
+	if strings.Contains(out.String(), "-- IR after OptimizeVariables --") {
+		t.Errorf("expected only the selected phases to be dumped, got:\n%s", out.String())
+	}
+}
+
+func TestCustomPhases(t *testing.T) {
+	t.Run("should run custom phases on the IR", func(t *testing.T) {
+		defer pipeline.ClearCustomPhases()
+		stripTestIds := pipeline.NewCustomPhase("StripTestIds", compilation.CompilationJobKindTmpl, func(job *compilation.CompilationJob) {
+			for _, unit := range job.GetUnits() {
+				for _, op := range unit.GetCreate().Ops() {
+					if attr, ok := op.(*ops_create.ExtractedAttributeOp); ok && attr.BindingKind == ir.BindingKindAttribute && attr.Name == "test-id" {
+						unit.GetCreate().Remove(op)
+					}
+				}
+			}
+		})
+		if err := pipeline.RegisterPhaseAfter("ExtractAttributes", stripTestIds); err != nil {
+			t.Fatal(err)
+		}
+
+		source := emitFull(t, `<button test-id="save" type="submit">Save</button>`)
+		expectContains(t, source, "consts:[['type','submit']]")
+		if strings.Contains(source, "'test-id'") {
+			t.Errorf("expected the test id to be stripped, got:\n%s", source)
+		}
+	})
+
+	t.Run("should run custom phases around their built-in phase in registration order", func(t *testing.T) {
+		defer pipeline.ClearCustomPhases()
+		var ran []string
+		record := func(name string, kind compilation.CompilationJobKind) pipeline.CustomPhase {
+			return pipeline.NewCustomPhase(name, kind, func(job *compilation.CompilationJob) {
+				ran = append(ran, name)
+			})
+		}
+		for _, err := range []error{
+			pipeline.RegisterPhaseAfter("Reify", record("AfterReify", compilation.CompilationJobKindBoth)),
+			pipeline.RegisterPhaseBefore("Reify", record("BeforeReify", compilation.CompilationJobKindTmpl)),
+			pipeline.RegisterPhaseBefore("Reify", record("BeforeReify2", compilation.CompilationJobKindTmpl)),
+		} {
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		names := strings.Join(pipeline.PhaseNames(), ",")
+		expectContains(t, names, "BeforeReify,BeforeReify2,Reify,AfterReify,Chain")
+		emitFull(t, `<span></span>`)
+		// The host bindings job only runs the phases of both kinds.
+		if got, want := strings.Join(ran, ","), "AfterReify,BeforeReify,BeforeReify2,AfterReify"; got != want {
+			t.Errorf("expected the phases to run as %s, got %s", want, got)
+		}
+	})
+
+	t.Run("should reject invalid registrations", func(t *testing.T) {
+		defer pipeline.ClearCustomPhases()
+		noop := func(*compilation.CompilationJob) {}
+		if err := pipeline.RegisterPhaseAfter("Missing", pipeline.NewCustomPhase("A", compilation.CompilationJobKindBoth, noop)); err == nil {
+			t.Error("expected an unknown built-in phase to be rejected")
+		}
+		if err := pipeline.RegisterPhaseAfter("Reify", pipeline.NewCustomPhase("Chain", compilation.CompilationJobKindBoth, noop)); err == nil {
+			t.Error("expected the name of a built-in phase to be rejected")
+		}
+		if err := pipeline.RegisterPhaseAfter("Reify", pipeline.NewCustomPhase("A", compilation.CompilationJobKindBoth, noop)); err != nil {
+			t.Fatal(err)
+		}
+		if err := pipeline.RegisterPhaseBefore("Chain", pipeline.NewCustomPhase("A", compilation.CompilationJobKindBoth, noop)); err == nil {
+			t.Error("expected a duplicate phase to be rejected")
+		}
+	})
+}
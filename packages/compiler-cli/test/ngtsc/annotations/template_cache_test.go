@@ -0,0 +1,45 @@
+package annotations_test
+
+import (
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+func TestTemplateCache(t *testing.T) {
+	cache := annotations.NewTemplateCache()
+	compile := func(component string) string {
+		t.Helper()
+		greet := reflection.ReflectSourceFile("/lib/greet.component.ts", component)
+		compiler := annotations.NewCompiler([]*reflection.SourceFile{
+			greet,
+			reflection.ReflectSourceFile("/lib/shout.pipe.ts", shoutPipe),
+		}, annotations.Options{RootDir: "/lib", TemplateCache: cache})
+		if diags := compiler.Analyze(); len(diags) != 0 {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+		return compiler.EmitFullModule(greet)
+	}
+
+	t.Run("should compile cached templates like parsed ones", func(t *testing.T) {
+		first := compile(greetComponent)
+		if second := compile(greetComponent); second != first {
+			t.Errorf("expected the same output, got:\n%s\nand:\n%s", first, second)
+		}
+	})
+
+	t.Run("should parse changed templates again", func(t *testing.T) {
+		compile(greetComponent)
+		source := compile(strings.Replace(greetComponent, "<p>", "<p><b>hi</b>", 1))
+		expectContains(t, source, "i0.ɵɵtext(2,'hi');")
+	})
+
+	t.Run("should compile templates which moved", func(t *testing.T) {
+		first := compile(greetComponent)
+		if moved := compile("// greet\n" + greetComponent); moved != first {
+			t.Errorf("expected the same output, got:\n%s\nand:\n%s", first, moved)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package annotations_test
+
+import (
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+const shoutPipeJS = `import { Pipe } from '@angular/core';
+
+@Pipe({ name: 'shout' })
+export class ShoutPipe {
+  transform(value) { return value.toUpperCase(); }
+}
+`
+
+func transformSource(t *testing.T, name string, files map[string]string) *annotations.TransformResult {
+	t.Helper()
+	var sources []*reflection.SourceFile
+	for fileName, text := range files {
+		sources = append(sources, reflection.ReflectSourceFile(fileName, text))
+	}
+	compiler := annotations.NewCompiler(sources, annotations.Options{RootDir: "/lib"})
+	if diags := compiler.Analyze(); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	for _, sf := range sources {
+		if sf.FileName == name {
+			res, err := compiler.TransformSource(sf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return res
+		}
+	}
+	t.Fatalf("no file %s", name)
+	return nil
+}
+
+func TestTransformSource(t *testing.T) {
+	t.Run("should assign the definitions after the classes of JavaScript files", func(t *testing.T) {
+		res := transformSource(t, "/lib/shout.pipe.js", map[string]string{"/lib/shout.pipe.js": shoutPipeJS})
+		expected := "import { Pipe } from '@angular/core';\nimport * as i0 from '@angular/core';\n\n" +
+			"export class ShoutPipe {\n  transform(value) { return value.toUpperCase(); }\n}\n" +
+			"ShoutPipe.ɵfac = function ShoutPipe_Factory(__ngFactoryType__) {"
+		if !strings.HasPrefix(res.Code, expected) {
+			t.Errorf("expected the output to start with %q, got:\n%s", expected, res.Code)
+		}
+		expectContains(t, res.Code,
+			"ShoutPipe.ɵpipe = i0.ɵɵdefinePipe({name:'shout',type:ShoutPipe,pure:true});",
+			"(((typeof ngDevMode === 'undefined') || ngDevMode) && i0.ɵsetClassMetadata(ShoutPipe,",
+		)
+		if strings.Contains(res.Code, "@Pipe") {
+			t.Errorf("expected the decorator to be removed, got:\n%s", res.Code)
+		}
+	})
+
+	t.Run("should map the preserved lines to the source file", func(t *testing.T) {
+		res := transformSource(t, "/lib/shout.pipe.js", map[string]string{"/lib/shout.pipe.js": shoutPipeJS})
+		sourceMap := res.SourceMap
+		if sourceMap == nil {
+			t.Fatal("expected a source map")
+		}
+		if len(sourceMap.Sources) != 1 || sourceMap.Sources[0] != "shout.pipe.js" || *sourceMap.SourcesContent[0] != shoutPipeJS {
+			t.Errorf("expected the source map to embed shout.pipe.js, got %v", sourceMap.Sources)
+		}
+		// The import maps to line 1, the generated import is unmapped and the class, following
+		// the removed decorator, maps to lines 4 to 6.
+		if expected := "AAAA;A;;AAGA;AACA;AACA;"; !strings.HasPrefix(sourceMap.Mappings, expected) {
+			t.Errorf("expected the mappings to start with %q, got %q", expected, sourceMap.Mappings)
+		}
+	})
+
+	t.Run("should keep the types of TypeScript files", func(t *testing.T) {
+		res := transformSource(t, "/lib/shout.pipe.ts", map[string]string{"/lib/shout.pipe.ts": shoutPipe})
+		if class := "export class ShoutPipe {\n  transform(value: string) { return value.toUpperCase(); }\n}\n"; !strings.Contains(res.Code, class) {
+			t.Errorf("expected the class to be preserved, got:\n%s", res.Code)
+		}
+		expectContains(t, res.Code, "(ShoutPipe.ɵfac = function ShoutPipe_Factory(__ngFactoryType__: any) {")
+	})
+
+	t.Run("should leave files without decorated classes unchanged", func(t *testing.T) {
+		token := "export const TOKEN = 'token';\n"
+		res := transformSource(t, "/lib/token.js", map[string]string{
+			"/lib/shout.pipe.js": shoutPipeJS,
+			"/lib/token.js":      token,
+		})
+		if res.Code != token || res.SourceMap != nil {
+			t.Errorf("expected %q without a source map, got %q", token, res.Code)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package annotations_test
+
+import (
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+func TestTypeCheckBlocks(t *testing.T) {
+	tip := reflection.ReflectSourceFile("/app/tip.ts", `import { Directive, Input, Pipe } from '@angular/core';
+
+@Directive({ selector: '[appTip]', standalone: true })
+export class TipDirective {
+  @Input('appTip') text = '';
+}
+
+@Pipe({ name: 'shout', standalone: true })
+export class ShoutPipe {
+  transform(value: string): string { return value; }
+}
+`)
+	card := reflection.ReflectSourceFile("/app/card.ts", `import { Component } from '@angular/core';
+import { TipDirective, ShoutPipe } from './tip';
+
+@Component({
+  selector: 'app-card',
+  standalone: true,
+  imports: [TipDirective, ShoutPipe],
+  template: '<div [appTip]="title | shout"></div>@let next = count + 1;{{ next }}{{ title | missing }}',
+})
+export class CardComponent {
+  title = '';
+  count = 0;
+}
+`)
+	analyze := func(typeCheckBlocks bool) (*annotations.Compiler, []*diagnostics.Diagnostic) {
+		compiler := annotations.NewCompiler([]*reflection.SourceFile{tip, card}, annotations.Options{RootDir: "/app", TypeCheckBlocks: typeCheckBlocks})
+		return compiler, compiler.Analyze()
+	}
+
+	t.Run("should not generate type-check blocks by default", func(t *testing.T) {
+		compiler, diags := analyze(false)
+		if len(diags) != 0 {
+			t.Errorf("unexpected diagnostics: %v", diags)
+		}
+		if compiler.TypeCheckFile(card) != nil {
+			t.Error("expected no type-check file")
+		}
+	})
+
+	t.Run("should generate the type-check blocks of the components of a file", func(t *testing.T) {
+		compiler, _ := analyze(true)
+		if compiler.TypeCheckFile(tip) != nil {
+			t.Error("expected no type-check file for a file without components")
+		}
+		file := compiler.TypeCheckFile(card)
+		if file == nil {
+			t.Fatal("expected a type-check file")
+		}
+		if file.FileName != "/app/card.ngtypecheck.ts" {
+			t.Errorf("unexpected file name %s", file.FileName)
+		}
+		expectContains(t, file.Render(),
+			`import * as i1 from "./tip.js";`,
+			`import * as i2 from "./card.js";`,
+			"function _tcb1(this: i2.CardComponent) {",
+			"var _pipe1: i1.ShoutPipe = null!;",
+			"var _t1: i1.TipDirective = null!;",
+			// Inputs are assigned to the class property they are declared on.
+			"_t1.text /*",
+			"= _pipe1.transform(this.title /*",
+			"const _t2 = (this.count /*",
+		)
+	})
+
+	t.Run("should report the problems found while generating the blocks", func(t *testing.T) {
+		_, diags := analyze(true)
+		if len(diags) != 1 {
+			t.Fatalf("expected 1 diagnostic, got %d: %v", len(diags), diags)
+		}
+		if diags[0].Code != diagnostics.MissingPipe || diags[0].File != "/app/card.ts" {
+			t.Errorf("unexpected diagnostic: %v", diags[0])
+		}
+	})
+}
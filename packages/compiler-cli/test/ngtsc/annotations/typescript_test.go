@@ -0,0 +1,85 @@
+package annotations_test
+
+import (
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+const greetDirective = `import { Directive, Input, Inject } from '@angular/core';
+import { TOKEN } from './token';
+
+@Directive({ selector: '[greet]' })
+export class GreetDirective {
+  @Input() name = '';
+
+  constructor(@Inject(TOKEN) private token: string) {}
+}
+`
+
+func emitTypeScript(t *testing.T, name string, files map[string]string) string {
+	t.Helper()
+	var sources []*reflection.SourceFile
+	for fileName, text := range files {
+		sources = append(sources, reflection.ReflectSourceFile(fileName, text))
+	}
+	compiler := annotations.NewCompiler(sources, annotations.Options{RootDir: "/lib"})
+	if diags := compiler.Analyze(); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	for _, sf := range sources {
+		if sf.FileName == name {
+			return compiler.EmitTypeScript(sf)
+		}
+	}
+	t.Fatalf("no file %s", name)
+	return ""
+}
+
+func TestEmitTypeScript(t *testing.T) {
+	t.Run("should add typed static fields to components", func(t *testing.T) {
+		source := emitTypeScript(t, "/lib/greet.component.ts", map[string]string{
+			"/lib/greet.component.ts": greetComponent,
+			"/lib/shout.pipe.ts":      shoutPipe,
+		})
+		expectContains(t, source,
+			"import { ShoutPipe } from './shout.pipe';\nimport * as i0 from '@angular/core';\n",
+			"static ɵfac: i0.ɵɵFactoryDeclaration<GreetComponent, never> = function GreetComponent_Factory(__ngFactoryType__: any) {",
+			"static ɵcmp: i0.ɵɵComponentDeclaration<GreetComponent, 'lib-greet', never, {}, {}, never, never, true, never> = i0.ɵɵdefineComponent({",
+			"template:function GreetComponent_Template(rf: any,ctx: any) {",
+			"i0.ɵsetClassMetadata(GreetComponent,",
+		)
+		if strings.Contains(source, "@Component") {
+			t.Errorf("expected the decorator to be removed, got:\n%s", source)
+		}
+	})
+
+	t.Run("should remove Angular member and parameter decorators", func(t *testing.T) {
+		source := emitTypeScript(t, "/lib/greet.directive.ts", map[string]string{
+			"/lib/greet.directive.ts": greetDirective,
+			"/lib/token.ts":           "export const TOKEN = 'token';\n",
+		})
+		if class := "export class GreetDirective {\n  name = '';\n\n  constructor(private token: string) {}\n"; !strings.Contains(source, class) {
+			t.Errorf("expected the decorators to be removed, got:\n%s", source)
+		}
+		expectContains(t, source,
+			"static ɵdir: i0.ɵɵDirectiveDeclaration<GreetDirective, '[greet]', never, {'name':{'alias':'name','required':false}}, {}, never, never, true, never> = i0.ɵɵdefineDirective({",
+			"i0.ɵɵdirectiveInject(TOKEN)",
+			"decorators:[{type:Inject,args:[TOKEN]}]",
+			"{name:[{type:Input}]}",
+		)
+	})
+
+	t.Run("should leave files without decorated classes unchanged", func(t *testing.T) {
+		token := "export const TOKEN = 'token';\n"
+		source := emitTypeScript(t, "/lib/token.ts", map[string]string{
+			"/lib/greet.directive.ts": greetDirective,
+			"/lib/token.ts":           token,
+		})
+		if source != token {
+			t.Errorf("expected %q, got %q", token, source)
+		}
+	})
+}
@@ -0,0 +1,160 @@
+package diagnostics_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// makeSpan creates a span covering the first occurrence of text in content.
+func makeSpan(t *testing.T, file *util.ParseSourceFile, text string) *util.ParseSourceSpan {
+	t.Helper()
+	offset := strings.Index(file.Content, text)
+	if offset < 0 {
+		t.Fatalf("%q not found in source", text)
+	}
+	start := util.NewParseLocation(file, 0, 0, 0).MoveBy(offset)
+	end := start.MoveBy(len(text))
+	return util.NewParseSourceSpan(start, end, nil, nil)
+}
+
+func makeDiagnostics(t *testing.T) []*diagnostics.Diagnostic {
+	file := util.NewParseSourceFile("<div>\n  <foo-bar></foo-bar>\n</div>\n", "app.component.html")
+	component := util.NewParseSourceFile("@Component({templateUrl: './app.component.html'})\nclass AppComponent {}\n", "app.component.ts")
+
+	elementError := diagnostics.MakeDiagnostic(
+		diagnostics.SchemaInvalidElement,
+		diagnostics.CategoryError,
+		makeSpan(t, file, "<foo-bar>"),
+		"'foo-bar' is not a known element",
+		diagnostics.MakeRelatedInformation(makeSpan(t, component, "AppComponent"), "Error occurs in the template of component AppComponent."),
+	)
+	parseWarning := diagnostics.FromParseError(
+		util.NewParseWarning(makeSpan(t, file, "</div>"), "Something looks off"),
+		diagnostics.TemplateParseError,
+	)
+	return []*diagnostics.Diagnostic{elementError, parseWarning}
+}
+
+func TestFormatDiagnosticsWithContext(t *testing.T) {
+	text := diagnostics.FormatDiagnosticsWithContext(makeDiagnostics(t), "")
+
+	expected := []string{
+		"app.component.html:2:3 - error NG8001: 'foo-bar' is not a known element\n",
+		"1 <div>\n2   <foo-bar></foo-bar>\n    ~~~~~~~~~\n",
+		"  app.component.ts:2:7\n",
+		"    2 class AppComponent {}\n            ~~~~~~~~~~~~\n    Error occurs in the template of component AppComponent.\n",
+		"app.component.html:3:1 - warning NG5002: Something looks off\n",
+		"Found 1 error and 1 warning.\n",
+	}
+	for _, part := range expected {
+		if !strings.Contains(text, part) {
+			t.Errorf("expected output to contain:\n%s\ngot:\n%s", part, text)
+		}
+	}
+}
+
+func TestWriteDiagnosticsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := diagnostics.WriteDiagnostics(&buf, makeDiagnostics(t), diagnostics.FormatOptions{Format: diagnostics.FormatJSON})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var report struct {
+		Diagnostics []struct {
+			Code     string
+			Name     string
+			Category string
+			File     string
+			Start    struct{ Line, Column, Offset int }
+			End      struct{ Line, Column, Offset int }
+		}
+		ErrorCount   int
+		WarningCount int
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if report.ErrorCount != 1 || report.WarningCount != 1 {
+		t.Errorf("unexpected counts: %d errors, %d warnings", report.ErrorCount, report.WarningCount)
+	}
+	first := report.Diagnostics[0]
+	if first.Code != "NG8001" || first.Name != "SCHEMA_INVALID_ELEMENT" || first.Category != "error" {
+		t.Errorf("unexpected diagnostic: %+v", first)
+	}
+	if first.Start.Line != 2 || first.Start.Column != 3 || first.Start.Offset != 8 || first.End.Column != 12 {
+		t.Errorf("unexpected position: %+v - %+v", first.Start, first.End)
+	}
+}
+
+func TestWriteDiagnosticsSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	err := diagnostics.WriteDiagnostics(&buf, makeDiagnostics(t), diagnostics.FormatOptions{Format: diagnostics.FormatSARIF})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct{ ID string }
+				}
+			}
+			Results []struct {
+				RuleID    string
+				RuleIndex int
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine, StartColumn int }
+					}
+				}
+				RelatedLocations []struct{ Message struct{ Text string } }
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF log: %s", buf.String())
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ID != "NG5002" || run.Tool.Driver.Rules[1].ID != "NG8001" {
+		t.Errorf("unexpected rules: %+v", run.Tool.Driver.Rules)
+	}
+	result := run.Results[0]
+	if result.RuleID != "NG8001" || result.RuleIndex != 1 || result.Level != "error" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	location := result.Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != "app.component.html" || location.Region.StartLine != 2 || location.Region.StartColumn != 3 {
+		t.Errorf("unexpected location: %+v", location)
+	}
+	if len(result.RelatedLocations) != 1 {
+		t.Errorf("expected a related location, got %+v", result.RelatedLocations)
+	}
+	if run.Results[1].Level != "warning" {
+		t.Errorf("expected a warning, got %s", run.Results[1].Level)
+	}
+}
+
+func TestParseErrorCode(t *testing.T) {
+	for _, value := range []string{"8001", "NG8001", "ng8001", "SCHEMA_INVALID_ELEMENT"} {
+		code, err := diagnostics.ParseErrorCode(value)
+		if err != nil || code != diagnostics.SchemaInvalidElement {
+			t.Errorf("ParseErrorCode(%q) = %v, %v", value, code, err)
+		}
+	}
+	if _, err := diagnostics.ParseErrorCode("NOPE"); err == nil {
+		t.Error("expected an error for an unknown code")
+	}
+}
@@ -0,0 +1,71 @@
+package incremental_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/incremental"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/util"
+)
+
+func TestCache(t *testing.T) {
+	t.Run("should miss unknown keys", func(t *testing.T) {
+		cache := incremental.NewCache(filepath.Join(t.TempDir(), "cache"))
+		if _, ok := cache.Get("missing"); ok {
+			t.Errorf("expected a miss")
+		}
+	})
+
+	t.Run("should restore entries with their diagnostics", func(t *testing.T) {
+		dir := t.TempDir()
+		template := filepath.Join(dir, "a.html")
+		if err := os.WriteFile(template, []byte("<p>\n<div"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		file := util.NewParseSourceFile("<p>\n<div", template)
+		span := util.NewParseSourceSpan(util.NewParseLocation(file, 4, 1, 0), util.NewParseLocation(file, 8, 1, 4), nil, nil)
+		cache := incremental.NewCache(filepath.Join(dir, "cache"))
+		err := cache.Put("key", &incremental.Entry{
+			Code: "export {};\n",
+			Map:  &output.SourceMap{Version: 3, Mappings: "AAAA"},
+			Diagnostics: []*diagnostics.Diagnostic{
+				diagnostics.MakeDiagnostic(diagnostics.TemplateParseError, diagnostics.CategoryError, span, "Opening tag \"div\" not terminated."),
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entry, ok := cache.Get("key")
+		if !ok {
+			t.Fatal("expected a hit")
+		}
+		if entry.Code != "export {};\n" || entry.Map == nil || entry.Map.Mappings != "AAAA" {
+			t.Errorf("unexpected entry %+v", entry)
+		}
+		if len(entry.Diagnostics) != 1 {
+			t.Fatalf("expected a diagnostic, got %v", entry.Diagnostics)
+		}
+		diag := entry.Diagnostics[0]
+		if diag.Code != diagnostics.TemplateParseError || diag.File != template || diag.Span.Start.Line != 1 ||
+			diag.Span.End.Offset != 8 || diag.Span.Start.File.Content != "<p>\n<div" {
+			t.Errorf("unexpected diagnostic %v", diag)
+		}
+	})
+
+	t.Run("should remove entries when cleared", func(t *testing.T) {
+		cache := incremental.NewCache(filepath.Join(t.TempDir(), "cache"))
+		if err := cache.Put("key", &incremental.Entry{Code: "x"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := cache.Clear(); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := cache.Get("key"); ok {
+			t.Errorf("expected a miss")
+		}
+	})
+}
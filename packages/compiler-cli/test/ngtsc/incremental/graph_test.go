@@ -0,0 +1,87 @@
+package incremental_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/incremental"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+func newGraph(files map[string]string) *incremental.Graph {
+	var sources []*reflection.SourceFile
+	for fileName, text := range files {
+		sources = append(sources, reflection.ReflectSourceFile(fileName, text))
+	}
+	return incremental.NewGraph(sources)
+}
+
+var project = map[string]string{
+	"/app/main.ts":              "import { AppModule } from './app.module';\n",
+	"/app/app.module.ts":        "import { NgModule } from '@angular/core';\nimport { AppComponent } from './app.component';\nimport { SharedModule } from './shared/index';\n",
+	"/app/app.component.ts":     "import { Component } from '@angular/core';\n",
+	"/app/shared/index.ts":      "import { Shout } from './shout.pipe.js';\n",
+	"/app/shared/shout.pipe.ts": "export class Shout {}\n",
+	"/app/other.ts":             "export const other = 1;\n",
+}
+
+func TestGraph(t *testing.T) {
+	t.Run("should depend on imports and on the imports of importers", func(t *testing.T) {
+		graph := newGraph(project)
+		expected := []string{
+			"/app/app.component.ts",
+			"/app/app.module.ts",
+			"/app/shared/index.ts",
+			"/app/shared/shout.pipe.ts",
+		}
+		if got := graph.Dependencies("/app/app.component.ts"); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("should change keys with the files they depend on", func(t *testing.T) {
+		noResources := func(path string) (string, error) { return "", errors.New("not found") }
+		key := newGraph(project).Key("/app/app.component.ts", "salt", noResources)
+
+		changed := make(map[string]string)
+		for name, text := range project {
+			changed[name] = text
+		}
+		changed["/app/other.ts"] = "export const other = 2;\n"
+		if got := newGraph(changed).Key("/app/app.component.ts", "salt", noResources); got != key {
+			t.Errorf("expected unrelated changes to keep the key")
+		}
+		if got := newGraph(project).Key("/app/app.component.ts", "other salt", noResources); got == key {
+			t.Errorf("expected the salt to change the key")
+		}
+		changed["/app/shared/shout.pipe.ts"] = "export class Shout { x = 1; }\n"
+		if got := newGraph(changed).Key("/app/app.component.ts", "salt", noResources); got == key {
+			t.Errorf("expected changes to dependencies to change the key")
+		}
+	})
+
+	t.Run("should change keys with external resources", func(t *testing.T) {
+		files := map[string]string{
+			"/app/a.component.ts": "import { Component } from '@angular/core';\n" +
+				"@Component({ selector: 'a', templateUrl: './a.html', styleUrls: ['./a.css'] })\nexport class A {}\n",
+		}
+		resources := map[string]string{"/app/a.html": "<p></p>", "/app/a.css": "p {}"}
+		read := func(path string) (string, error) {
+			if content, ok := resources[path]; ok {
+				return content, nil
+			}
+			return "", errors.New("not found")
+		}
+		graph := newGraph(files)
+		key := graph.Key("/app/a.component.ts", "", read)
+		resources["/app/a.css"] = "p { color: red }"
+		if graph.Key("/app/a.component.ts", "", read) == key {
+			t.Errorf("expected changes to stylesheets to change the key")
+		}
+		delete(resources, "/app/a.html")
+		if graph.Key("/app/a.component.ts", "", read) == key {
+			t.Errorf("expected missing templates to change the key")
+		}
+	})
+}
@@ -0,0 +1,285 @@
+package reflection_test
+
+import (
+	"reflect"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+)
+
+const testSource = `import { Component, Input, Optional as Opt } from '@angular/core';
+import * as rx from 'rxjs';
+import type { Foo } from './foo';
+import Default, { type Bar } from "./bar";
+
+/** The greeting component. */
+@Component({
+  selector: 'app-greet',
+  template: ` + "`<p>{{ greeting }}, \\`{{ name }}\\`</p>`" + `,
+  standalone: false,
+  host: {'[class.active]': 'active', '(click)': 'onClick($event)'},
+  providers: [Default, {provide: rx.Token, useValue: 1 / 2}],
+})
+export class GreetComponent<T = string> extends Base implements OnInit {
+  @Input() name = 'world'
+  @Input({required: true}) greeting!: string;
+  count = input<number>(0);
+  private readonly pattern = /[}{]/g
+  static defaults: Map<string, number> = new Map();
+  #secret = 1;
+
+  constructor(private http: HttpClient | null, @Opt() @Inject(TOKEN) public value?: Foo<string>) {
+    super();
+  }
+
+  get label(): string { return this.name; }
+  set label(value: string) {}
+
+  onClick(event: MouseEvent): void {
+    const nested = class Inner {};
+  }
+}
+
+function helper() { class NotTopLevel {} }
+export const TOKEN = new InjectionToken('token');
+export async function load() {}
+
+export default class {}
+`
+
+func TestReflectSourceFile(t *testing.T) {
+	sf := reflection.ReflectSourceFile("greet.component.ts", testSource)
+
+	t.Run("should read imports", func(t *testing.T) {
+		if len(sf.Imports) != 4 {
+			t.Fatalf("expected 4 imports, got %d", len(sf.Imports))
+		}
+		imp, name, ok := sf.ImportOf("Opt")
+		if !ok || imp.Module != "@angular/core" || name != "Optional" {
+			t.Errorf("unexpected import of Opt: %+v %q", imp, name)
+		}
+		if imp, name, _ := sf.ImportOf("rx"); imp.Module != "rxjs" || name != "*" {
+			t.Errorf("unexpected import of rx: %+v %q", imp, name)
+		}
+		if !sf.Imports[2].TypeOnly || sf.Imports[3].TypeOnly || !sf.Imports[3].Specifiers[0].TypeOnly {
+			t.Errorf("unexpected type-only flags")
+		}
+		if imp, name, _ := sf.ImportOf("Default"); imp.Module != "./bar" || name != "default" {
+			t.Errorf("unexpected import of Default: %+v %q", imp, name)
+		}
+		if got := testSource[sf.Imports[0].Start:sf.Imports[0].End]; got != "import { Component, Input, Optional as Opt } from '@angular/core';" {
+			t.Errorf("unexpected import span %q", got)
+		}
+	})
+
+	t.Run("should read top-level classes", func(t *testing.T) {
+		if len(sf.Classes) != 2 {
+			t.Fatalf("expected 2 classes, got %d", len(sf.Classes))
+		}
+		class := sf.Classes[0]
+		if class.Name != "GreetComponent" || !class.Exported || class.Extends != "Base" || class.TypeParameters != "T = string" {
+			t.Errorf("unexpected class: %+v", class)
+		}
+		if testSource[class.Start:class.Start+11] != "@Component(" || testSource[class.End-1] != '}' {
+			t.Errorf("unexpected class span %d-%d", class.Start, class.End)
+		}
+		if !sf.Classes[1].Default || sf.Classes[1].Name != "" {
+			t.Errorf("expected an anonymous default export, got %+v", sf.Classes[1])
+		}
+	})
+
+	t.Run("should read the heritage clauses of classes", func(t *testing.T) {
+		sf := reflection.ReflectSourceFile("/app/tabs.ts", "export class Tabs extends Base<{ id: string }> implements OnInit, Store<Map<string, { tab: number }>> {\n  ngOnInit(): void {}\n}\n")
+		class := sf.Classes[0]
+		if class.Extends != "Base<{ id: string }>" || class.Implements != "OnInit, Store<Map<string, { tab: number }>>" {
+			t.Errorf("unexpected heritage clauses %q %q", class.Extends, class.Implements)
+		}
+		if len(class.Members) != 1 || class.Members[0].Name != "ngOnInit" {
+			t.Errorf("unexpected members %+v", class.Members)
+		}
+	})
+
+	t.Run("should read exported declarations", func(t *testing.T) {
+		if !reflect.DeepEqual(sf.Exports, []string{"TOKEN", "load"}) {
+			t.Errorf("unexpected exports %v", sf.Exports)
+		}
+		if !sf.IsExported("GreetComponent") || sf.IsExported("helper") {
+			t.Errorf("unexpected IsExported results")
+		}
+	})
+
+	t.Run("should read decorator arguments", func(t *testing.T) {
+		dec := sf.Classes[0].Decorator("Component")
+		if dec == nil || len(dec.Args) != 1 {
+			t.Fatalf("expected a @Component decorator with one argument")
+		}
+		meta := dec.Args[0]
+		if selector, _ := meta.Property("selector").StringValue(); selector != "app-greet" {
+			t.Errorf("unexpected selector %q", selector)
+		}
+		if template, _ := meta.Property("template").StringValue(); template != "<p>{{ greeting }}, `{{ name }}`</p>" {
+			t.Errorf("unexpected template %q", template)
+		}
+		if standalone, ok := meta.Property("standalone").BoolValue(); !ok || standalone {
+			t.Errorf("unexpected standalone flag")
+		}
+		host := meta.Property("host")
+		if host.Kind != reflection.ExpressionObject || len(host.Properties) != 2 || host.Properties[1].Name != "(click)" {
+			t.Errorf("unexpected host: %+v", host)
+		}
+		providers := meta.Property("providers")
+		if providers.Kind != reflection.ExpressionArray || len(providers.Elements) != 2 {
+			t.Fatalf("unexpected providers: %+v", providers)
+		}
+		if providers.Elements[1].Property("provide").Value != "rx.Token" || providers.Elements[1].Property("useValue").Kind != reflection.ExpressionOther {
+			t.Errorf("unexpected provider: %s", providers.Elements[1].Text)
+		}
+		if text := testSource[dec.Start:dec.End]; text[:11] != "@Component(" || text[len(text)-2:] != "})" {
+			t.Errorf("unexpected decorator span")
+		}
+	})
+
+	t.Run("should read members", func(t *testing.T) {
+		var names []string
+		for _, member := range sf.Classes[0].Members {
+			names = append(names, member.Name)
+		}
+		expected := []string{"name", "greeting", "count", "pattern", "defaults", "#secret", "label", "label", "onClick"}
+		if !reflect.DeepEqual(names, expected) {
+			t.Fatalf("expected members %v, got %v", expected, names)
+		}
+		members := sf.Classes[0].Members
+		if members[0].Decorators[0].Name != "Input" || members[0].Initializer.Value != "world" {
+			t.Errorf("unexpected member: %+v", members[0])
+		}
+		if members[1].Type != "string" || members[1].Decorators[0].Args[0].Property("required").Value != "true" {
+			t.Errorf("unexpected member: %+v", members[1])
+		}
+		count := members[2].Initializer
+		if count.Kind != reflection.ExpressionCall || count.Callee.Value != "input" || count.TypeArguments != "number" || count.Elements[0].Value != "0" {
+			t.Errorf("unexpected initializer: %+v", count)
+		}
+		if !members[4].IsStatic || members[4].Type != "Map<string, number>" {
+			t.Errorf("unexpected member: %+v", members[4])
+		}
+		if members[6].Kind != reflection.MemberKindGetter || members[7].Kind != reflection.MemberKindSetter || members[7].Type != "string" {
+			t.Errorf("unexpected accessors: %+v %+v", members[6], members[7])
+		}
+		if members[8].Kind != reflection.MemberKindMethod {
+			t.Errorf("unexpected method: %+v", members[8])
+		}
+	})
+
+	t.Run("should read member signatures", func(t *testing.T) {
+		members := sf.Classes[0].Members
+		if !reflect.DeepEqual(members[3].Modifiers, []string{"private", "readonly"}) {
+			t.Errorf("unexpected modifiers %v", members[3].Modifiers)
+		}
+		if members[6].ReturnType != "string" || !members[6].HasBody {
+			t.Errorf("unexpected getter: %+v", members[6])
+		}
+		onClick := members[8]
+		if len(onClick.Parameters) != 1 || onClick.Parameters[0].Name != "event" || onClick.Parameters[0].Type != "MouseEvent" || onClick.ReturnType != "void" {
+			t.Errorf("unexpected method: %+v", onClick)
+		}
+	})
+
+	t.Run("should read constructor parameters", func(t *testing.T) {
+		ctor := sf.Classes[0].Constructor
+		if ctor == nil || len(ctor.Parameters) != 2 {
+			t.Fatalf("expected a constructor with 2 parameters, got %+v", ctor)
+		}
+		http, value := ctor.Parameters[0], ctor.Parameters[1]
+		if http.Name != "http" || http.TypeName() != "HttpClient" || !reflect.DeepEqual(http.Modifiers, []string{"private"}) {
+			t.Errorf("unexpected parameter: %+v", http)
+		}
+		if value.Name != "value" || !value.Optional || value.TypeName() != "Foo" || len(value.Decorators) != 2 ||
+			value.Decorators[1].Args[0].Value != "TOKEN" {
+			t.Errorf("unexpected parameter: %+v", value)
+		}
+	})
+}
+
+func TestReflectStatements(t *testing.T) {
+	source := `import { InputSignal } from '@angular/core';
+
+export interface Options<T> {
+  value: T;
+}
+type Mode = 'a' | 'b'
+export const enum Color { Red, Green }
+declare const ngDevMode: boolean;
+export const VERSION: string = '1.0';
+export let count = 0, other = 1
+export function format(value: string): string;
+export function format(value: any, ...rest: unknown[]) {
+  return String(value);
+}
+export async function load<T>(url = '/'): Promise<T> {}
+export * from './foo';
+export type { Foo } from './foo';
+export default function () {}
+`
+	sf := reflection.ReflectSourceFile("statements.ts", source)
+
+	type summary struct {
+		Kind     reflection.StatementKind
+		Name     string
+		Exported bool
+		Text     string
+	}
+	var got []summary
+	for _, stmt := range sf.Statements {
+		got = append(got, summary{stmt.Kind, stmt.Name, stmt.Exported, source[stmt.Start:stmt.End]})
+	}
+	expected := []summary{
+		{reflection.StatementInterface, "Options", true, "export interface Options<T> {\n  value: T;\n}"},
+		{reflection.StatementTypeAlias, "Mode", false, "type Mode = 'a' | 'b'"},
+		{reflection.StatementEnum, "Color", true, "export const enum Color { Red, Green }"},
+		{reflection.StatementVariable, "ngDevMode", false, "declare const ngDevMode: boolean;"},
+		{reflection.StatementVariable, "VERSION", true, "export const VERSION: string = '1.0';"},
+		{reflection.StatementVariable, "count", true, "export let count = 0, other = 1"},
+		{reflection.StatementFunction, "format", true, "export function format(value: string): string;"},
+		{reflection.StatementFunction, "format", true, "export function format(value: any, ...rest: unknown[]) {\n  return String(value);\n}"},
+		{reflection.StatementFunction, "load", true, "export async function load<T>(url = '/'): Promise<T> {}"},
+		{reflection.StatementExport, "", true, "export * from './foo';"},
+		{reflection.StatementExport, "", true, "export type { Foo } from './foo';"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected statements\n%+v\ngot\n%+v", expected, got)
+	}
+
+	if stmt := sf.Statements[2]; stmt.Keyword != "const enum" {
+		t.Errorf("unexpected enum keyword %q", stmt.Keyword)
+	}
+	if stmt := sf.Statements[3]; !stmt.Declare || stmt.Type != "boolean" {
+		t.Errorf("unexpected ambient declaration: %+v", stmt)
+	}
+	if stmt := sf.Statements[4]; stmt.Type != "string" || stmt.Initializer.Value != "1.0" {
+		t.Errorf("unexpected variable: %+v", stmt)
+	}
+	if overload, impl := sf.Statements[6], sf.Statements[7]; overload.HasBody || !impl.HasBody || overload.ReturnType != "string" ||
+		len(impl.Parameters) != 2 || !impl.Parameters[1].Rest || impl.Parameters[1].Type != "unknown[]" {
+		t.Errorf("unexpected functions: %+v %+v", overload, impl)
+	}
+	load := sf.Statements[8]
+	if !load.Async || load.TypeParameters != "T" || load.ReturnType != "Promise<T>" || !load.Parameters[0].Optional || load.Parameters[0].Initializer.Value != "/" {
+		t.Errorf("unexpected function: %+v", load)
+	}
+}
+
+func TestCookString(t *testing.T) {
+	cases := map[string]string{
+		`'it\'s'`:       "it's",
+		`"a\nb"`:        "a\nb",
+		"`a\\`b`":       "a`b",
+		`'A\x42'`:       "AB",
+		`'\u{1F600}'`:   "😀",
+		"'line\\\nend'": "lineend",
+	}
+	for literal, expected := range cases {
+		if got := reflection.CookString(literal); got != expected {
+			t.Errorf("CookString(%s): expected %q, got %q", literal, expected, got)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package extended_test
+
+import (
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck/extended"
+	"ngc-go/packages/compiler/src/css"
+	"ngc-go/packages/compiler/src/render3/view"
+)
+
+type inputMapping map[string]bool
+
+func (m inputMapping) HasBindingPropertyName(propertyName string) bool {
+	return m[propertyName]
+}
+
+type testDirectiveMeta struct {
+	name     string
+	selector string
+	inputs   inputMapping
+}
+
+func (t *testDirectiveMeta) Name() string                                             { return t.name }
+func (t *testDirectiveMeta) Selector() *string                                        { return &t.selector }
+func (t *testDirectiveMeta) IsComponent() bool                                        { return false }
+func (t *testDirectiveMeta) Inputs() view.InputOutputPropertySet                      { return t.inputs }
+func (t *testDirectiveMeta) Outputs() view.InputOutputPropertySet                     { return inputMapping{} }
+func (t *testDirectiveMeta) ExportAs() []string                                       { return nil }
+func (t *testDirectiveMeta) IsStructural() bool                                       { return true }
+func (t *testDirectiveMeta) NgContentSelectors() []string                             { return nil }
+func (t *testDirectiveMeta) PreserveWhitespaces() bool                                { return false }
+func (t *testDirectiveMeta) AnimationTriggerNames() *view.LegacyAnimationTriggerNames { return nil }
+
+var testMembers = extended.Members{
+	"title":   {Kind: extended.MemberProperty, Nullability: typecheck.NonNullable},
+	"user":    {Kind: extended.MemberProperty, Nullability: typecheck.Nullable},
+	"save":    {Kind: extended.MemberMethod},
+	"count":   {Kind: extended.MemberSignal, Nullability: typecheck.NonNullable},
+	"user.id": {Kind: extended.MemberSignal},
+}
+
+type templateOptions struct {
+	directives []*testDirectiveMeta
+	standalone bool
+	options    *extended.Options
+}
+
+func bindTemplate(t *testing.T, template string, directives []*testDirectiveMeta) view.BoundTarget {
+	t.Helper()
+	parsed := view.ParseTemplate(template, "test.html", nil)
+	if len(parsed.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", parsed.Errors)
+	}
+	matcher := css.NewSelectorMatcher[view.DirectiveMeta]()
+	for _, dir := range directives {
+		selectors, err := css.ParseCssSelector(dir.selector)
+		if err != nil {
+			t.Fatalf("invalid selector %q: %v", dir.selector, err)
+		}
+		var meta view.DirectiveMeta = dir
+		matcher.AddSelectables(selectors, &meta)
+	}
+	return view.NewR3TargetBinder(matcher).Bind(&view.Target{Template: parsed.Nodes})
+}
+
+// runChecks runs the given checks over a template and returns the diagnostics along with the
+// text of the template reported by each of them.
+func runChecks(t *testing.T, checks []extended.TemplateCheck, template string, opts templateOptions) ([]*diagnostics.Diagnostic, []string) {
+	t.Helper()
+	checker, configDiags := extended.NewExtendedTemplateChecker(checks, opts.options)
+	if len(configDiags) > 0 {
+		t.Fatalf("unexpected config diagnostics: %v", configDiags)
+	}
+	diags := checker.GetDiagnosticsForTemplate(extended.TemplateInfo{
+		Bound:            bindTemplate(t, template, opts.directives),
+		HostIsStandalone: opts.standalone,
+		Types:            testMembers,
+	})
+	var reported []string
+	for _, diag := range diags {
+		reported = append(reported, template[diag.Span.Start.Offset:diag.Span.End.Offset])
+	}
+	return diags, reported
+}
+
+func expectReported(t *testing.T, check extended.TemplateCheck, template string, opts templateOptions, expected ...string) []*diagnostics.Diagnostic {
+	t.Helper()
+	diags, reported := runChecks(t, []extended.TemplateCheck{check}, template, opts)
+	if len(reported) != len(expected) {
+		t.Fatalf("expected %q to be reported, got %q", expected, reported)
+	}
+	for i := range expected {
+		if reported[i] != expected[i] {
+			t.Errorf("expected %q to be reported, got %q", expected[i], reported[i])
+		}
+		if diags[i].Code != check.Code() {
+			t.Errorf("expected code %d, got %d", check.Code(), diags[i].Code)
+		}
+	}
+	return diags
+}
+
+func TestExtendedChecks(t *testing.T) {
+	t.Run("invalidBananaInBox", func(t *testing.T) {
+		diags := expectReported(t, extended.InvalidBananaInBoxCheck{},
+			`<div ([foo])="bar"></div><div [(baz)]="qux"></div>`, templateOptions{}, `([foo])="bar"`)
+		if !strings.Contains(diags[0].Message, `'[(foo)]="bar"'`) {
+			t.Errorf("unexpected message: %s", diags[0].Message)
+		}
+		if diags[0].Category != diagnostics.CategoryWarning {
+			t.Errorf("expected a warning, got %v", diags[0].Category)
+		}
+	})
+
+	t.Run("nullishCoalescingNotNullable", func(t *testing.T) {
+		expectReported(t, extended.NullishCoalescingNotNullableCheck{},
+			`{{ title ?? 'none' }} {{ user ?? 'none' }} {{ other ?? 'none' }}`, templateOptions{}, `title ?? 'none'`)
+	})
+
+	t.Run("missingControlFlowDirective", func(t *testing.T) {
+		template := `<div *ngIf="user"></div><div *ngFor="let item of items"></div>`
+		diags := expectReported(t, extended.MissingControlFlowDirectiveCheck{}, template,
+			templateOptions{standalone: true}, "ngIf", "ngFor")
+		if !strings.Contains(diags[0].Message, "`NgIf` directive") || !strings.Contains(diags[1].Message, "@for") {
+			t.Errorf("unexpected messages: %s / %s", diags[0].Message, diags[1].Message)
+		}
+		expectReported(t, extended.MissingControlFlowDirectiveCheck{}, template, templateOptions{})
+		expectReported(t, extended.MissingControlFlowDirectiveCheck{}, `<div *ngIf="user"></div>`, templateOptions{
+			standalone: true,
+			directives: []*testDirectiveMeta{{name: "NgIf", selector: "[ngIf]", inputs: inputMapping{"ngIf": true}}},
+		})
+		// The bindings of an explicit <ng-template> are not structural directives.
+		expectReported(t, extended.MissingControlFlowDirectiveCheck{}, `<ng-template [ngIf]="user"></ng-template>`,
+			templateOptions{standalone: true})
+	})
+
+	t.Run("missingStructuralDirective", func(t *testing.T) {
+		template := `<div *foo="bar"></div><div *ngIf="user"></div>`
+		diags := expectReported(t, extended.MissingStructuralDirectiveCheck{}, template,
+			templateOptions{standalone: true}, "foo")
+		if !strings.Contains(diags[0].Message, "`foo`") {
+			t.Errorf("unexpected message: %s", diags[0].Message)
+		}
+		expectReported(t, extended.MissingStructuralDirectiveCheck{}, template, templateOptions{
+			standalone: true,
+			directives: []*testDirectiveMeta{{name: "Foo", selector: "[foo]", inputs: inputMapping{"foo": true}}},
+		})
+		expectReported(t, extended.MissingStructuralDirectiveCheck{}, `<ng-template [foo]="1" bar></ng-template>`,
+			templateOptions{standalone: true})
+	})
+
+	t.Run("textAttributeNotBinding", func(t *testing.T) {
+		diags := expectReported(t, extended.TextAttributeNotBindingCheck{},
+			`<div attr.id="main" class.active="true" style.color="red" title="x"></div>`, templateOptions{},
+			`attr.id="main"`, `class.active="true"`, `style.color="red"`)
+		expected := []string{
+			`Static attributes should be written without the 'attr.' prefix. For example, id="main".`,
+			`Attribute, style, and class bindings should be enclosed with square braces. For example, '[class.active]="true"'.`,
+			`Attribute, style, and class bindings should be enclosed with square braces. For example, '[style.color]="'red'"'.`,
+		}
+		for i, message := range expected {
+			if diags[i].Message != message {
+				t.Errorf("expected message %q, got %q", message, diags[i].Message)
+			}
+		}
+	})
+
+	t.Run("skipHydrationNotStatic", func(t *testing.T) {
+		expectReported(t, extended.SkipHydrationNotStaticCheck{},
+			`<a ngSkipHydration></a><b ngSkipHydration="true"></b><c ngSkipHydration="no"></c><d [ngSkipHydration]="x"></d>`,
+			templateOptions{}, `ngSkipHydration="no"`, `[ngSkipHydration]="x"`)
+	})
+
+	t.Run("interpolatedSignalNotInvoked", func(t *testing.T) {
+		diags := expectReported(t, extended.InterpolatedSignalNotInvokedCheck{},
+			`{{ count }} {{ count() }} {{ title }} {{ user.id }} <div [attr.data-count]="count" [title]="count"></div>`,
+			templateOptions{}, "count", "user.id", "count")
+		if diags[0].Message != "count is a function and should be invoked: count()" {
+			t.Errorf("unexpected message: %s", diags[0].Message)
+		}
+	})
+
+	t.Run("uninvokedFunctionInEventBinding", func(t *testing.T) {
+		diags := expectReported(t, extended.UninvokedFunctionInEventBindingCheck{},
+			`<button (click)="save"></button><button (click)="save()"></button><button (click)="title; save"></button>`,
+			templateOptions{}, `(click)="save"`, `(click)="title; save"`)
+		if diags[0].Message != "Function in event binding should be invoked: save()" {
+			t.Errorf("unexpected message: %s", diags[0].Message)
+		}
+	})
+
+	t.Run("unusedLetDeclaration", func(t *testing.T) {
+		diags := expectReported(t, extended.UnusedLetDeclarationCheck{},
+			`@let used = title; @let unused = title; @let nested = used; <div (click)="save(nested)"></div>`,
+			templateOptions{}, "@let unused = title")
+		if diags[0].Message != "@let unused is declared but its value is never read." {
+			t.Errorf("unexpected message: %s", diags[0].Message)
+		}
+	})
+}
+
+func TestExtendedTemplateChecker(t *testing.T) {
+	template := `<div ([foo])="bar" attr.id="main"></div>`
+
+	t.Run("should run all checks by default", func(t *testing.T) {
+		diags, _ := runChecks(t, extended.AllChecks(), template, templateOptions{})
+		if len(diags) != 2 {
+			t.Fatalf("expected 2 diagnostics, got %v", diags)
+		}
+	})
+
+	t.Run("should configure the category of checks", func(t *testing.T) {
+		diags, _ := runChecks(t, extended.AllChecks(), template, templateOptions{options: &extended.Options{
+			DefaultCategory: extended.CategoryLabelError,
+			Checks:          map[string]extended.DiagnosticCategoryLabel{"textAttributeNotBinding": extended.CategoryLabelSuppress},
+		}})
+		if len(diags) != 1 || diags[0].Code != diagnostics.InvalidBananaInBox || diags[0].Category != diagnostics.CategoryError {
+			t.Fatalf("expected a single banana-in-box error, got %v", diags)
+		}
+	})
+
+	t.Run("should report invalid options", func(t *testing.T) {
+		_, diags := extended.NewExtendedTemplateChecker(extended.AllChecks(), &extended.Options{
+			DefaultCategory: "loud",
+			Checks: map[string]extended.DiagnosticCategoryLabel{
+				"unknownCheck":       extended.CategoryLabelError,
+				"invalidBananaInBox": "fatal",
+			},
+		})
+		if len(diags) != 3 {
+			t.Fatalf("expected 3 diagnostics, got %v", diags)
+		}
+		if diags[0].Code != diagnostics.ConfigExtendedDiagnosticsUnknownCategoryLabel ||
+			!strings.Contains(diags[2].Message, `unknown check: "unknownCheck"`) {
+			t.Errorf("unexpected diagnostics: %v", diags)
+		}
+	})
+}
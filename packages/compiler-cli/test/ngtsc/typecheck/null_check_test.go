@@ -0,0 +1,100 @@
+package typecheck_test
+
+import (
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck"
+)
+
+var testMembers = typecheck.MemberNullability{
+	"title":     typecheck.NonNullable,
+	"user":      typecheck.Nullable,
+	"user.name": typecheck.NonNullable,
+	"mode":      typecheck.Nullable,
+	"count":     typecheck.Nullable,
+}
+
+// checkNulls returns the text of the template reported by each diagnostic.
+func checkNulls(t *testing.T, template string) ([]string, []*diagnostics.Diagnostic) {
+	t.Helper()
+	diags := typecheck.CheckNullSafety(bindTemplate(t, template), testMembers)
+	var reported []string
+	for _, diag := range diags {
+		reported = append(reported, template[diag.Span.Start.Offset:diag.Span.End.Offset])
+	}
+	return reported, diags
+}
+
+func expectReported(t *testing.T, template string, expected ...string) []*diagnostics.Diagnostic {
+	t.Helper()
+	reported, diags := checkNulls(t, template)
+	if len(reported) != len(expected) {
+		t.Fatalf("expected %v to be reported, got %v", expected, reported)
+	}
+	for i := range expected {
+		if reported[i] != expected[i] {
+			t.Errorf("expected %q to be reported, got %q", expected[i], reported[i])
+		}
+	}
+	return diags
+}
+
+func TestCheckNullSafety(t *testing.T) {
+	t.Run("should report safe navigation on non-nullable values", func(t *testing.T) {
+		diags := expectReported(t, `{{ title?.length }} {{ user?.name }} {{ other?.value }}`, "?.length")
+		if diags[0].Code != diagnostics.OptionalChainNotNullable || diags[0].Category != diagnostics.CategoryWarning {
+			t.Errorf("unexpected diagnostic: %v", diags[0])
+		}
+	})
+
+	t.Run("should report safe keyed reads and method calls", func(t *testing.T) {
+		expectReported(t, `{{ title?.[0] }} {{ 'abc'?.toString() }}`, "?.[0]", "?.toString")
+	})
+
+	t.Run("should report non-null assertions on non-nullable values", func(t *testing.T) {
+		diags := expectReported(t, `{{ title! }} {{ count! }}`, "!")
+		if diags[0].Code != diagnostics.UnnecessaryNonNullAssertion {
+			t.Errorf("unexpected diagnostic: %v", diags[0])
+		}
+	})
+
+	t.Run("should narrow @if conditions in nested views", func(t *testing.T) {
+		expectReported(t,
+			`@if (user) { <div><ng-template>{{ user?.name }}</ng-template></div> } {{ user?.name }}`,
+			"?.name")
+	})
+
+	t.Run("should narrow receivers of tested properties", func(t *testing.T) {
+		expectReported(t, `@if (user?.name && count != null) { {{ user?.name }} {{ count! }} }`, "?.name", "!")
+	})
+
+	t.Run("should narrow @else branches", func(t *testing.T) {
+		expectReported(t, `@if (!user) { {{ user?.name }} } @else { {{ user?.name }} }`, "?.name")
+	})
+
+	t.Run("should treat @if aliases as non-nullable", func(t *testing.T) {
+		expectReported(t, `@if (user; as u) { {{ u?.name }} }`, "?.name")
+	})
+
+	t.Run("should narrow @switch cases", func(t *testing.T) {
+		expectReported(t,
+			`@switch (mode) { @case ('a') { {{ mode?.length }} } @default { {{ mode?.length }} } }`,
+			"?.length")
+	})
+
+	t.Run("should derive the nullability of @let declarations", func(t *testing.T) {
+		expectReported(t, `@let label = count ?? 'none'; @let raw = count; {{ label?.length }} {{ raw?.length }}`, "?.length")
+	})
+
+	t.Run("should run as part of CheckTemplate in strict mode", func(t *testing.T) {
+		template := `<div>{{ title?.length }}</div>`
+		if diags := checkTemplate(t, template, nil); len(diags) != 0 {
+			t.Errorf("expected no diagnostics without strict null checks, got %v", diags)
+		}
+		diags := checkTemplate(t, template, &typecheck.TemplateCheckOptions{StrictNullChecks: true, Nullability: testMembers})
+		if len(diags) != 1 || diags[0].Code != diagnostics.OptionalChainNotNullable {
+			t.Errorf("expected an optional chain diagnostic, got %v", diags)
+		}
+	})
+}
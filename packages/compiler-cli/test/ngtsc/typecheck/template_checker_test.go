@@ -0,0 +1,199 @@
+package typecheck_test
+
+import (
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck"
+	"ngc-go/packages/compiler/src/core"
+	"ngc-go/packages/compiler/src/css"
+	"ngc-go/packages/compiler/src/render3/view"
+)
+
+// inputMapping is an InputOutputPropertySet which only uses an identity mapping
+type inputMapping map[string]bool
+
+func (m inputMapping) HasBindingPropertyName(propertyName string) bool {
+	return m[propertyName]
+}
+
+// testDirectiveMeta is a test implementation of DirectiveMeta
+type testDirectiveMeta struct {
+	name        string
+	selector    string
+	isComponent bool
+	inputs      inputMapping
+	outputs     inputMapping
+}
+
+func (t *testDirectiveMeta) Name() string                                             { return t.name }
+func (t *testDirectiveMeta) Selector() *string                                        { return &t.selector }
+func (t *testDirectiveMeta) IsComponent() bool                                        { return t.isComponent }
+func (t *testDirectiveMeta) Inputs() view.InputOutputPropertySet                      { return t.inputs }
+func (t *testDirectiveMeta) Outputs() view.InputOutputPropertySet                     { return t.outputs }
+func (t *testDirectiveMeta) ExportAs() []string                                       { return nil }
+func (t *testDirectiveMeta) IsStructural() bool                                       { return false }
+func (t *testDirectiveMeta) NgContentSelectors() []string                             { return nil }
+func (t *testDirectiveMeta) PreserveWhitespaces() bool                                { return false }
+func (t *testDirectiveMeta) AnimationTriggerNames() *view.LegacyAnimationTriggerNames { return nil }
+
+func checkTemplate(t *testing.T, template string, options *typecheck.TemplateCheckOptions) []*diagnostics.Diagnostic {
+	t.Helper()
+	parsed := view.ParseTemplate(template, "test.html", nil)
+	if len(parsed.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", parsed.Errors)
+	}
+
+	matcher := css.NewSelectorMatcher[view.DirectiveMeta]()
+	directives := []*testDirectiveMeta{
+		{name: "Comp", selector: "my-comp", isComponent: true, inputs: inputMapping{"value": true}},
+		{name: "Dir", selector: "[dir]", inputs: inputMapping{"dirInput": true}, outputs: inputMapping{"dirChange": true}},
+	}
+	for _, dir := range directives {
+		selectors, err := css.ParseCssSelector(dir.selector)
+		if err != nil {
+			t.Fatalf("invalid selector %q: %v", dir.selector, err)
+		}
+		var meta view.DirectiveMeta = dir
+		matcher.AddSelectables(selectors, &meta)
+	}
+
+	bound := view.NewR3TargetBinder(matcher).Bind(&view.Target{Template: parsed.Nodes})
+	return typecheck.CheckTemplate(bound, options)
+}
+
+func TestCheckTemplate(t *testing.T) {
+	t.Run("should report unknown elements", func(t *testing.T) {
+		errors := checkTemplate(t, `<div><foo-bar></foo-bar></div>`, nil)
+		if len(errors) != 1 {
+			t.Fatalf("expected 1 diagnostic, got %d: %v", len(errors), errors)
+		}
+		if !strings.HasPrefix(errors[0].Message, "'foo-bar' is not a known element") {
+			t.Errorf("unexpected message: %s", errors[0].Message)
+		}
+		if !strings.Contains(errors[0].Message, "CUSTOM_ELEMENTS_SCHEMA") {
+			t.Errorf("expected a CUSTOM_ELEMENTS_SCHEMA hint, got: %s", errors[0].Message)
+		}
+		if errors[0].Category != diagnostics.CategoryError {
+			t.Errorf("expected an error, got %s", errors[0].Category)
+		}
+		if start := errors[0].Span.Start; start.Line != 0 || start.Col != 5 {
+			t.Errorf("expected span at 0:5, got %d:%d", start.Line, start.Col)
+		}
+	})
+
+	t.Run("should not report elements matched by a component", func(t *testing.T) {
+		errors := checkTemplate(t, `<my-comp [value]="1"></my-comp>`, nil)
+		if len(errors) != 0 {
+			t.Errorf("expected no diagnostics, got %v", errors)
+		}
+	})
+
+	t.Run("should report unknown properties with the span of the key", func(t *testing.T) {
+		errors := checkTemplate(t, `<div [fooBar]="1"></div>`, nil)
+		if len(errors) != 1 {
+			t.Fatalf("expected 1 diagnostic, got %d: %v", len(errors), errors)
+		}
+		if errors[0].Message != "Can't bind to 'fooBar' since it isn't a known property of 'div'." {
+			t.Errorf("unexpected message: %s", errors[0].Message)
+		}
+		if got := errors[0].Span.String(); got != "fooBar" {
+			t.Errorf("expected span to cover the key, got %q", got)
+		}
+	})
+
+	t.Run("should accept DOM properties, mapped attributes and directive inputs", func(t *testing.T) {
+		template := `<div [title]="a" [class]="b" [style]="c" [attr.foo]="d" dir [dirInput]="e"></div><label [for]="f"></label>`
+		errors := checkTemplate(t, template, nil)
+		if len(errors) != 0 {
+			t.Errorf("expected no diagnostics, got %v", errors)
+		}
+	})
+
+	t.Run("should report unknown events as warnings", func(t *testing.T) {
+		errors := checkTemplate(t, `<button (click)="a()" (keydown.enter)="b()" (window:resize)="c()" (fooChange)="d()"></button>`, nil)
+		if len(errors) != 1 {
+			t.Fatalf("expected 1 diagnostic, got %d: %v", len(errors), errors)
+		}
+		if !strings.HasPrefix(errors[0].Message, "'fooChange' is not a known event of 'button'") {
+			t.Errorf("unexpected message: %s", errors[0].Message)
+		}
+		if errors[0].Category != diagnostics.CategoryWarning {
+			t.Errorf("expected a warning, got %s", errors[0].Category)
+		}
+	})
+
+	t.Run("should not report the outputs of a backwards banana-in-box", func(t *testing.T) {
+		errors := checkTemplate(t, `<div ([title])="name"></div>`, nil)
+		if len(errors) != 0 {
+			t.Errorf("expected no diagnostics, got %v", errors)
+		}
+	})
+
+	t.Run("should not report directive outputs", func(t *testing.T) {
+		errors := checkTemplate(t, `<div dir (dirChange)="a()"></div>`, nil)
+		if len(errors) != 0 {
+			t.Errorf("expected no diagnostics, got %v", errors)
+		}
+	})
+
+	t.Run("should check nodes inside control flow blocks", func(t *testing.T) {
+		errors := checkTemplate(t, `@if (a) { <foo-bar></foo-bar> } @for (x of xs; track x) { <div [nope]="x"></div> }`, nil)
+		if len(errors) != 2 {
+			t.Fatalf("expected 2 diagnostics, got %d: %v", len(errors), errors)
+		}
+	})
+
+	t.Run("should report the unclaimed bindings of ng-template", func(t *testing.T) {
+		errors := checkTemplate(t, `<ng-template [ngIf]="a" (fooChange)="b()"><span></span></ng-template>`, nil)
+		if len(errors) != 2 {
+			t.Fatalf("expected 2 diagnostics, got %d: %v", len(errors), errors)
+		}
+		if !strings.HasPrefix(errors[0].Message, "Can't bind to 'ngIf' since it isn't a known property of 'ng-template'.\n1. If 'ngIf' is an Angular directive") {
+			t.Errorf("unexpected message: %s", errors[0].Message)
+		}
+		if got := errors[0].Span.String(); got != "ngIf" {
+			t.Errorf("expected span to cover the key, got %q", got)
+		}
+		if !strings.HasPrefix(errors[1].Message, "'fooChange' is not a known event of 'ng-template'") {
+			t.Errorf("unexpected message: %s", errors[1].Message)
+		}
+	})
+
+	t.Run("should not report the bindings of ng-template claimed by a directive", func(t *testing.T) {
+		errors := checkTemplate(t, `<ng-template dir [dirInput]="a" (dirChange)="b()"></ng-template>`, nil)
+		if len(errors) != 0 {
+			t.Errorf("expected no diagnostics, got %v", errors)
+		}
+	})
+
+	t.Run("should report the unclaimed bindings of inline templates against their element", func(t *testing.T) {
+		errors := checkTemplate(t, `<div *ngIf="a"></div><div *dir="b"></div>`, nil)
+		if len(errors) != 1 {
+			t.Fatalf("expected 1 diagnostic, got %d: %v", len(errors), errors)
+		}
+		if errors[0].Message != "Can't bind to 'ngIf' since it isn't a known property of 'div'." {
+			t.Errorf("unexpected message: %s", errors[0].Message)
+		}
+	})
+
+	t.Run("should respect CUSTOM_ELEMENTS_SCHEMA", func(t *testing.T) {
+		options := &typecheck.TemplateCheckOptions{Schemas: []*core.SchemaMetadata{&core.CUSTOM_ELEMENTS_SCHEMA}}
+		errors := checkTemplate(t, `<foo-bar [baz]="1" (qux)="a()"></foo-bar>`, options)
+		if len(errors) != 0 {
+			t.Errorf("expected no diagnostics, got %v", errors)
+		}
+	})
+
+	t.Run("should mention the component decorator for standalone hosts", func(t *testing.T) {
+		options := &typecheck.TemplateCheckOptions{HostIsStandalone: true}
+		errors := checkTemplate(t, `<foo></foo>`, options)
+		if len(errors) != 1 {
+			t.Fatalf("expected 1 diagnostic, got %d: %v", len(errors), errors)
+		}
+		if !strings.Contains(errors[0].Message, "'@Component.schemas'") {
+			t.Errorf("unexpected message: %s", errors[0].Message)
+		}
+	})
+}
@@ -0,0 +1,286 @@
+package typecheck_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck"
+	"ngc-go/packages/compiler/src/css"
+	"ngc-go/packages/compiler/src/render3/view"
+)
+
+func bindTemplate(t *testing.T, template string) view.BoundTarget {
+	t.Helper()
+	parsed := view.ParseTemplate(template, "test.html", nil)
+	if len(parsed.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", parsed.Errors)
+	}
+
+	matcher := css.NewSelectorMatcher[view.DirectiveMeta]()
+	directives := []*testDirectiveMeta{
+		{name: "Dir", selector: "[dir]", inputs: inputMapping{"dirInput": true}, outputs: inputMapping{"dirChange": true}},
+	}
+	for _, dir := range directives {
+		selectors, err := css.ParseCssSelector(dir.selector)
+		if err != nil {
+			t.Fatalf("invalid selector %q: %v", dir.selector, err)
+		}
+		var meta view.DirectiveMeta = dir
+		matcher.AddSelectables(selectors, &meta)
+	}
+	return view.NewR3TargetBinder(matcher).Bind(&view.Target{Template: parsed.Nodes})
+}
+
+func generateTcb(t *testing.T, template string) *typecheck.TypeCheckBlock {
+	t.Helper()
+	return typecheck.GenerateTypeCheckBlock(bindTemplate(t, template), &typecheck.TypeCheckBlockMetadata{
+		ComponentName: "TestCmp",
+		Pipes:         map[string]string{"uppercase": "UpperCasePipe"},
+	})
+}
+
+// stripSpans removes the `/*start,end*/` span comments from generated code.
+func stripSpans(code string) string {
+	return regexp.MustCompile(` /\*\d+,\d+\*/`).ReplaceAllString(code, "")
+}
+
+func expectCode(t *testing.T, code string, parts ...string) {
+	t.Helper()
+	stripped := stripSpans(code)
+	for _, part := range parts {
+		if !strings.Contains(stripped, part) {
+			t.Errorf("expected generated code to contain:\n%s\ngot:\n%s", part, stripped)
+		}
+	}
+}
+
+func TestTypeCheckBlock(t *testing.T) {
+	t.Run("should read component members from this", func(t *testing.T) {
+		block := generateTcb(t, `{{ user.name }} <span [title]="title"></span>`)
+		expectCode(t, block.Code,
+			"function _tcb(this: TestCmp) {",
+			`("" + this.user.name);`,
+			"this.title;",
+		)
+	})
+
+	t.Run("should declare directives and assign their inputs", func(t *testing.T) {
+		block := generateTcb(t, `<div dir [dirInput]="value" (dirChange)="onChange($event)"></div>`)
+		expectCode(t, block.Code,
+			"var _t1: Dir = null!;",
+			"_t1.dirInput = this.value;",
+			`_t1["dirChange"].subscribe(($event): any => {`,
+			"this.onChange($event);",
+		)
+	})
+
+	t.Run("should add listeners for DOM events", func(t *testing.T) {
+		block := generateTcb(t, `<button (click)="save($event)"></button>`)
+		expectCode(t, block.Code,
+			`var _t1 = document.createElement("button");`,
+			`_t1.addEventListener("click", ($event): any => {`,
+		)
+	})
+
+	t.Run("should resolve references and template variables", func(t *testing.T) {
+		block := generateTcb(t, `<input #name>{{ name.value }}<ng-template let-row><span>{{ row }}</span></ng-template>`)
+		expectCode(t, block.Code,
+			`var _t1 = document.createElement("input");`,
+			`("" + _t1.value);`,
+			"var _t2: any = null!;",
+			"var _t3 = _t2.$implicit;",
+			`("" + _t3);`,
+		)
+	})
+
+	t.Run("should narrow @if aliases", func(t *testing.T) {
+		block := generateTcb(t, `@if (user; as u) { {{ u.name }} } @else { none }`)
+		expectCode(t, block.Code,
+			"var _t1 = this.user;",
+			"if (_t1) {",
+			`("" + _t1.name);`,
+			"} else {",
+		)
+	})
+
+	t.Run("should repeat @if conditions in listeners", func(t *testing.T) {
+		block := generateTcb(t, `@if (user) { <button (click)="save(user.name)"></button> } @else { <button (click)="login()"></button> }`)
+		expectCode(t, block.Code,
+			"if (this.user) {",
+			"    if ((this.user)) {\n        this.save(this.user.name);",
+			"    if (!(this.user)) {\n        this.login();",
+		)
+	})
+
+	t.Run("should compare @switch cases", func(t *testing.T) {
+		block := generateTcb(t, `@switch (mode) { @case ('a') { a } @default { other } }`)
+		expectCode(t, block.Code, `if (this.mode === "a") {`, "} else {")
+	})
+
+	t.Run("should loop over @for blocks", func(t *testing.T) {
+		block := generateTcb(t, `@for (item of items; track item.id; let i = $index) { {{ i }} {{ item.name }} }`)
+		expectCode(t, block.Code,
+			"for (const _t1 of (this.items)!) {",
+			"_t1.id;",
+			`("" + _t8 + _t1.name);`,
+		)
+		if !regexp.MustCompile(`var _t8: number = null!`).MatchString(stripSpans(block.Code)) {
+			t.Errorf("expected $index to be typed as a number:\n%s", block.Code)
+		}
+	})
+
+	t.Run("should declare @let as constants", func(t *testing.T) {
+		block := generateTcb(t, `@let total = price * count; {{ total }}`)
+		expectCode(t, block.Code,
+			"const _t1 = (this.price * this.count);",
+			`("" + _t1);`,
+		)
+	})
+
+	t.Run("should declare pipe instances", func(t *testing.T) {
+		block := generateTcb(t, `{{ name | uppercase }} {{ other | uppercase }}`)
+		expectCode(t, block.Code,
+			"var _pipe1: UpperCasePipe = null!;",
+			"_pipe1.transform(this.name)",
+			"_pipe1.transform(this.other)",
+		)
+		if strings.Count(block.Code, "var _pipe1") != 1 {
+			t.Errorf("expected a single pipe instance:\n%s", block.Code)
+		}
+	})
+
+	t.Run("should report missing pipes", func(t *testing.T) {
+		block := generateTcb(t, `{{ name | nope }}`)
+		if len(block.Diagnostics) != 1 {
+			t.Fatalf("expected 1 diagnostic, got %v", block.Diagnostics)
+		}
+		diag := block.Diagnostics[0]
+		if diag.Code != diagnostics.MissingPipe || diag.Message != "No pipe found with name 'nope'." {
+			t.Errorf("unexpected diagnostic: %v", diag)
+		}
+		if diag.Span.Start.Offset != 10 || diag.Span.End.Offset != 14 {
+			t.Errorf("expected the pipe name to be reported, got %d-%d", diag.Span.Start.Offset, diag.Span.End.Offset)
+		}
+	})
+
+	t.Run("should report writes to template entities", func(t *testing.T) {
+		block := generateTcb(t, `<input #ref (input)="ref = $event"> @let x = 1; <button (click)="x = 2"></button>`)
+		if len(block.Diagnostics) != 2 {
+			t.Fatalf("expected 2 diagnostics, got %v", block.Diagnostics)
+		}
+		if block.Diagnostics[0].Code != diagnostics.WriteToReadOnlyVariable {
+			t.Errorf("unexpected diagnostic: %v", block.Diagnostics[0])
+		}
+		if block.Diagnostics[1].Code != diagnostics.IllegalLetWrite {
+			t.Errorf("unexpected diagnostic: %v", block.Diagnostics[1])
+		}
+	})
+
+	t.Run("should map generated code back to the template", func(t *testing.T) {
+		template := `<span>{{ user.name }}</span>`
+		block := generateTcb(t, template)
+		offset := strings.Index(block.Code, ".name")
+		span := block.SourceSpanOf(offset)
+		if span == nil {
+			t.Fatalf("expected a span for offset %d in:\n%s", offset, block.Code)
+		}
+		if got := template[span.Start.Offset:span.End.Offset]; got != "user.name" {
+			t.Errorf("expected the span of user.name, got %q", got)
+		}
+		if block.SourceSpanOf(0) != nil {
+			t.Error("expected the function header not to map to the template")
+		}
+	})
+}
+
+func TestTypeCheckFile(t *testing.T) {
+	file := typecheck.NewTypeCheckFile("app.ngtypecheck.ts")
+	file.Imports["AppComponent"] = "./app.component"
+	file.Imports["UpperCasePipe"] = "@angular/common"
+	file.AddTypeCheckBlock(bindTemplate(t, `{{ title | uppercase }}`), &typecheck.TypeCheckBlockMetadata{
+		ComponentName: "AppComponent",
+		Pipes:         map[string]string{"uppercase": "UpperCasePipe"},
+	})
+	file.AddTypeCheckBlock(bindTemplate(t, `{{ missing.value }}`), &typecheck.TypeCheckBlockMetadata{
+		ComponentName: "AppComponent",
+	})
+
+	code := file.Render()
+	expectCode(t, code,
+		"import * as i0 from \"@angular/core\";\nimport * as i1 from \"./app.component\";\nimport * as i2 from \"@angular/common\";\n",
+		"function _tcb1(this: i1.AppComponent) {",
+		"var _pipe1: i2.UpperCasePipe = null!;",
+		"function _tcb2(this: i1.AppComponent) {",
+	)
+
+	offset := strings.Index(code, "this.missing") + len("this.")
+	line := strings.Count(code[:offset], "\n")
+	character := offset - strings.LastIndex(code[:offset], "\n") - 1
+	if got := file.OffsetAt(line, character); got != offset {
+		t.Fatalf("OffsetAt(%d, %d) = %d, expected %d", line, character, got, offset)
+	}
+	diag := file.TranslateDiagnostic(offset, 2339, "Property 'missing' does not exist on type 'AppComponent'.", diagnostics.CategoryError)
+	if diag == nil {
+		t.Fatal("expected the diagnostic to be mapped to the template")
+	}
+	if diag.DisplayCode() != "TS2339" || diag.Span.Start.Offset != 3 || diag.Span.End.Offset != 10 {
+		t.Errorf("unexpected diagnostic: %v (%d-%d)", diag, diag.Span.Start.Offset, diag.Span.End.Offset)
+	}
+	if file.TranslateDiagnostic(0, 2307, "Cannot find module.", diagnostics.CategoryError) != nil {
+		t.Error("expected diagnostics outside of templates not to be mapped")
+	}
+}
+
+// TestTypeCheckBlockCompiles checks that the generated code is accepted by the TypeScript
+// compiler, and that its errors map back to the template.
+func TestTypeCheckBlockCompiles(t *testing.T) {
+	tsc, err := exec.LookPath("tsc")
+	if err != nil {
+		t.Skip("tsc is not available")
+	}
+
+	template := `<input #name (input)="onInput(name.value)">
+@for (item of items; track item.id; let i = $index) { {{ i + 1 }}: {{ item.label }} }
+{{ count.toFixed(2) }}`
+	file := typecheck.NewTypeCheckFile("app.ngtypecheck.ts")
+	file.AddTypeCheckBlock(bindTemplate(t, template), &typecheck.TypeCheckBlockMetadata{ComponentName: "AppComponent"})
+
+	source := `class AppComponent {
+  items: {id: number; label: string}[] = [];
+  count = 'not a number';
+  onInput(value: string) {}
+}
+` + file.Render()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.ngtypecheck.ts")
+	if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output, _ := exec.Command(tsc, "--noEmit", "--strict", "--target", "es2020", "--lib", "es2020,dom", "--pretty", "false", path).CombinedOutput()
+
+	// e.g. app.ngtypecheck.ts(12,24): error TS2551: Property 'toFixed' does not exist on type 'string'.
+	matches := regexp.MustCompile(`\((\d+),(\d+)\): error TS(\d+): (.*)`).FindAllStringSubmatch(string(output), -1)
+	if len(matches) != 1 {
+		t.Fatalf("expected a single error, got:\n%s\nfor:\n%s", output, source)
+	}
+	line, _ := strconv.Atoi(matches[0][1])
+	character, _ := strconv.Atoi(matches[0][2])
+	code, _ := strconv.Atoi(matches[0][3])
+
+	// The component class is prepended to the rendered file.
+	prefix := strings.Count(source[:strings.Index(source, file.Render())], "\n")
+	diag := file.TranslateDiagnostic(file.OffsetAt(line-1-prefix, character-1), code, matches[0][4], diagnostics.CategoryError)
+	if diag == nil {
+		t.Fatalf("expected the error to map to the template: %s", matches[0][0])
+	}
+	if got := template[diag.Span.Start.Offset:diag.Span.End.Offset]; got != "count.toFixed" {
+		t.Errorf("expected the error at count.toFixed, got %q", got)
+	}
+}
@@ -288,12 +288,12 @@ func (cp *ConstantPool) getLiteralFactory(
 
 // GetSharedFunctionReference returns a shared function reference
 func (cp *ConstantPool) GetSharedFunctionReference(fn output.OutputExpression, prefix string, useUniqueName bool) output.OutputExpression {
-	isArrow := isArrowFunction(fn)
+	_, isFunction := fn.(*output.FunctionExpr)
 
 	for _, current := range cp.statements {
-		// Arrow functions are saved as variables so we check if the
-		// value of the variable is the same as the arrow function.
-		if isArrow {
+		// Arrow functions, like any expression other than a function, are saved as
+		// variables so we check if the value of the variable is the same as the function.
+		if !isFunction {
 			if declareVar, ok := current.(*output.DeclareVarStmt); ok && declareVar.Value != nil && declareVar.Value.IsEquivalent(fn) {
 				return output.NewReadVarExpr(declareVar.Name, nil, nil)
 			}
@@ -301,9 +301,9 @@ func (cp *ConstantPool) GetSharedFunctionReference(fn output.OutputExpression, p
 
 		// Function declarations are saved as function statements
 		// so we compare them directly to the passed-in function.
-		if !isArrow {
+		if isFunction {
 			if declareFn, ok := current.(*output.DeclareFunctionStmt); ok {
-				if fnExpr, ok := fn.(*output.FunctionExpr); ok && fnExpr.IsEquivalentToStmt(declareFn) {
+				if fn.(*output.FunctionExpr).IsEquivalentToStmt(declareFn) {
 					return output.NewReadVarExpr(declareFn.Name, nil, nil)
 				}
 			}
@@ -449,11 +449,6 @@ func isFixupExpression(expr output.OutputExpression) bool {
 	return ok
 }
 
-func isArrowFunction(expr output.OutputExpression) bool {
-	_, ok := expr.(*output.ArrowFunctionExpr)
-	return ok
-}
-
 func joinStrings(strs []string, sep string) string {
 	if len(strs) == 0 {
 		return ""
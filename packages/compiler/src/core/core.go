@@ -79,12 +79,12 @@ const (
 type InjectFlags int
 
 const (
-	InjectFlagsDefault InjectFlags = iota
-	InjectFlagsHost                = 1 << iota
-	InjectFlagsSelf
-	InjectFlagsSkipSelf
-	InjectFlagsOptional
-	InjectFlagsForPipe
+	InjectFlagsDefault  InjectFlags = 0
+	InjectFlagsHost     InjectFlags = 1 << 0
+	InjectFlagsSelf     InjectFlags = 1 << 1
+	InjectFlagsSkipSelf InjectFlags = 1 << 2
+	InjectFlagsOptional InjectFlags = 1 << 3
+	InjectFlagsForPipe  InjectFlags = 1 << 4
 )
 
 // MissingTranslationStrategy represents the strategy for handling missing translations
@@ -136,13 +136,3 @@ const (
 // EmitDistinctChangesOnlyDefaultValue stores the default value of emitDistinctChangesOnly
 const EmitDistinctChangesOnlyDefaultValue = true
 
-// ParseSelectorToR3Selector parses a selector string to R3 selector format
-func ParseSelectorToR3Selector(selector *string) R3CssSelectorList {
-	if selector == nil || *selector == "" {
-		return R3CssSelectorList{}
-	}
-	// This will be implemented when we integrate with directivematching package
-	// For now, return empty list
-	return R3CssSelectorList{}
-}
-
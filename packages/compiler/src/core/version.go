@@ -29,6 +29,13 @@ func NewVersion(full string) *Version {
 	return v
 }
 
+// versionFull is the version of the compiler. Release builds stamp it with
+// `-ldflags "-X ngc-go/packages/compiler/src/core.versionFull=<version>"`.
+var versionFull = "0.0.0-PLACEHOLDER"
+
+// VERSION is the version of the compiler, recorded in partial declarations.
+var VERSION = NewVersion(versionFull)
+
 var v1To18Regexp = regexp.MustCompile(`^([1-9]|1[0-8])\.`)
 
 // GetJitStandaloneDefaultForVersion returns the default JIT standalone setting for a version
@@ -2,8 +2,11 @@ package css
 
 import (
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
+
+	"ngc-go/packages/compiler/src/core"
 )
 
 // SelectorRegexp represents the regex group indices for selector parsing
@@ -70,14 +73,14 @@ func ParseCssSelector(selector string) ([]*CssSelector, error) {
 	inNot := false
 
 	matches := selectorRegexp.FindAllStringSubmatch(selector, -1)
-	fmt.Printf("[DEBUG ParseCssSelector] Parsing selector=%q, found %d matches\n", selector, len(matches))
+	fmt.Fprintf(os.Stderr, "[DEBUG ParseCssSelector] Parsing selector=%q, found %d matches\n", selector, len(matches))
 	for i, match := range matches {
-		fmt.Printf("[DEBUG ParseCssSelector] match[%d]: inNot=%v, match=%q\n", i, inNot, match[0])
+		fmt.Fprintf(os.Stderr, "[DEBUG ParseCssSelector] match[%d]: inNot=%v, match=%q\n", i, inNot, match[0])
 		if len(match) > int(SelectorRegexpNot) && match[SelectorRegexpNot] != "" {
 			if inNot {
 				return nil, fmt.Errorf("nesting :not in a selector is not allowed")
 			}
-			fmt.Printf("[DEBUG ParseCssSelector] Setting inNot=true\n")
+			fmt.Fprintf(os.Stderr, "[DEBUG ParseCssSelector] Setting inNot=true\n")
 			inNot = true
 			current = NewCssSelector()
 			cssSelector.NotSelectors = append(cssSelector.NotSelectors, current)
@@ -132,7 +135,7 @@ func ParseCssSelector(selector string) ([]*CssSelector, error) {
 		}
 
 		if len(match) > int(SelectorRegexpNotEnd) && match[SelectorRegexpNotEnd] != "" {
-			fmt.Printf("[DEBUG ParseCssSelector] Found ), setting inNot=false\n")
+			fmt.Fprintf(os.Stderr, "[DEBUG ParseCssSelector] Found ), setting inNot=false\n")
 			inNot = false
 			current = cssSelector
 		}
@@ -141,7 +144,7 @@ func ParseCssSelector(selector string) ([]*CssSelector, error) {
 			if inNot {
 				return nil, fmt.Errorf("multiple selectors in :not are not supported")
 			}
-			fmt.Printf("[DEBUG ParseCssSelector] Found separator, adding result and resetting\n")
+			fmt.Fprintf(os.Stderr, "[DEBUG ParseCssSelector] Found separator, adding result and resetting\n")
 			results = addResult(results, cssSelector)
 			cssSelector = NewCssSelector()
 			current = cssSelector
@@ -267,6 +270,78 @@ func (cs *CssSelector) String() string {
 	return res
 }
 
+// ParseSelectorToR3Selector parses a selector string to the R3 selector format of the runtime, e.g.
+// `[["", "someDir", ""]]` for `[someDir]`. Selectors which cannot be parsed give an empty list.
+func ParseSelectorToR3Selector(selector *string) core.R3CssSelectorList {
+	if selector == nil || *selector == "" {
+		return core.R3CssSelectorList{}
+	}
+	selectors, err := ParseCssSelector(*selector)
+	if err != nil {
+		return core.R3CssSelectorList{}
+	}
+	list := make(core.R3CssSelectorList, len(selectors))
+	for i, selector := range selectors {
+		list[i] = parserSelectorToR3Selector(selector)
+	}
+	return list
+}
+
+func parserSelectorToSimpleSelector(selector *CssSelector) core.R3CssSelector {
+	element := ""
+	if selector.Element != nil && *selector.Element != "*" {
+		element = *selector.Element
+	}
+	r3Selector := core.R3CssSelector{element}
+	for _, attr := range selector.Attrs {
+		r3Selector = append(r3Selector, attr)
+	}
+	return append(r3Selector, classesToR3Selector(selector.ClassNames)...)
+}
+
+func parserSelectorToNegativeSelector(selector *CssSelector) core.R3CssSelector {
+	var r3Selector core.R3CssSelector
+	switch {
+	case selector.Element != nil:
+		r3Selector = core.R3CssSelector{core.SelectorFlagsNOT | core.SelectorFlagsELEMENT, *selector.Element}
+	case len(selector.Attrs) > 0:
+		r3Selector = core.R3CssSelector{core.SelectorFlagsNOT | core.SelectorFlagsATTRIBUTE}
+	case len(selector.ClassNames) > 0:
+		r3Selector = core.R3CssSelector{core.SelectorFlagsNOT | core.SelectorFlagsCLASS}
+		for _, className := range selector.ClassNames {
+			r3Selector = append(r3Selector, className)
+		}
+		return r3Selector
+	default:
+		return core.R3CssSelector{}
+	}
+	for _, attr := range selector.Attrs {
+		r3Selector = append(r3Selector, attr)
+	}
+	return append(r3Selector, classesToR3Selector(selector.ClassNames)...)
+}
+
+// classesToR3Selector returns the class names of a selector after their flag, nothing when there
+// are none.
+func classesToR3Selector(classNames []string) core.R3CssSelector {
+	if len(classNames) == 0 {
+		return nil
+	}
+	classes := core.R3CssSelector{core.SelectorFlagsCLASS}
+	for _, className := range classNames {
+		classes = append(classes, className)
+	}
+	return classes
+}
+
+func parserSelectorToR3Selector(selector *CssSelector) core.R3CssSelector {
+	r3Selector := parserSelectorToSimpleSelector(selector)
+	for _, notSelector := range selector.NotSelectors {
+		r3Selector = append(r3Selector, parserSelectorToNegativeSelector(notSelector)...)
+	}
+	return r3Selector
+}
+
 // SelectorMatcher matches CSS selectors
 type SelectorMatcher[T any] struct {
 	elementMap          map[string][]*SelectorContext[T]
@@ -300,7 +375,7 @@ func CreateNotMatcher(notSelectors []*CssSelector) *SelectorMatcher[interface{}]
 
 // AddSelectables adds selectables to the matcher
 func (sm *SelectorMatcher[T]) AddSelectables(cssSelectors []*CssSelector, callbackCtxt *T) {
-	fmt.Printf("[DEBUG CSS AddSelectables] Adding %d selectors\n", len(cssSelectors))
+	fmt.Fprintf(os.Stderr, "[DEBUG CSS AddSelectables] Adding %d selectors\n", len(cssSelectors))
 	var listContext *SelectorListContext
 	if len(cssSelectors) > 1 {
 		listContext = NewSelectorListContext(cssSelectors)
@@ -312,10 +387,10 @@ func (sm *SelectorMatcher[T]) AddSelectables(cssSelectors []*CssSelector, callba
 		if cssSelector.Element != nil {
 			elem = *cssSelector.Element
 		}
-		fmt.Printf("[DEBUG CSS AddSelectables] selector[%d]: element=%q, attrs=%v, attrs_len=%d\n", i, elem, cssSelector.Attrs, len(cssSelector.Attrs))
+		fmt.Fprintf(os.Stderr, "[DEBUG CSS AddSelectables] selector[%d]: element=%q, attrs=%v, attrs_len=%d\n", i, elem, cssSelector.Attrs, len(cssSelector.Attrs))
 		sm.addSelectable(cssSelector, callbackCtxt, listContext)
 	}
-	fmt.Printf("[DEBUG CSS AddSelectables] Done adding selectors\n")
+	fmt.Fprintf(os.Stderr, "[DEBUG CSS AddSelectables] Done adding selectors\n")
 }
 
 func (sm *SelectorMatcher[T]) addSelectable(cssSelector *CssSelector, callbackCtxt *T, listContext *SelectorListContext) {
@@ -382,11 +457,11 @@ func (sm *SelectorMatcher[T]) addTerminal(map_ map[string][]*SelectorContext[T],
 func (sm *SelectorMatcher[T]) addPartial(map_ map[string]*SelectorMatcher[T], name string) *SelectorMatcher[T] {
 	matcher, ok := map_[name]
 	if !ok {
-		fmt.Printf("[DEBUG CSS addPartial] Creating new nested matcher for key=%q\n", name)
+		fmt.Fprintf(os.Stderr, "[DEBUG CSS addPartial] Creating new nested matcher for key=%q\n", name)
 		matcher = NewSelectorMatcher[T]()
 		map_[name] = matcher
 	} else {
-		fmt.Printf("[DEBUG CSS addPartial] Reusing existing nested matcher for key=%q\n", name)
+		fmt.Fprintf(os.Stderr, "[DEBUG CSS addPartial] Reusing existing nested matcher for key=%q\n", name)
 	}
 	return matcher
 }
@@ -403,7 +478,7 @@ func (sm *SelectorMatcher[T]) Match(cssSelector *CssSelector, matchedCallback Ma
 	}
 	classNames := cssSelector.ClassNames
 	attrs := cssSelector.Attrs
-	fmt.Printf("[DEBUG CSS Match] element=%q, classNames=%v, attrs=%v, attrs_len=%d\n", element, classNames, attrs, len(attrs))
+	fmt.Fprintf(os.Stderr, "[DEBUG CSS Match] element=%q, classNames=%v, attrs=%v, attrs_len=%d\n", element, classNames, attrs, len(attrs))
 
 	for _, listContext := range sm.listContexts {
 		listContext.AlreadyMatched = false
@@ -423,10 +498,10 @@ func (sm *SelectorMatcher[T]) Match(cssSelector *CssSelector, matchedCallback Ma
 		if i+1 < len(attrs) {
 			value = attrs[i+1]
 		}
-		fmt.Printf("[DEBUG CSS Match] Processing attr i=%d, name=%q, value=%q\n", i, name, value)
+		fmt.Fprintf(os.Stderr, "[DEBUG CSS Match] Processing attr i=%d, name=%q, value=%q\n", i, name, value)
 
 		terminalValuesMap, ok := sm.attrValueMap[name]
-		fmt.Printf("[DEBUG CSS Match] attrValueMap[%q] exists=%v\n", name, ok)
+		fmt.Fprintf(os.Stderr, "[DEBUG CSS Match] attrValueMap[%q] exists=%v\n", name, ok)
 		if ok {
 			if value != "" {
 				result = sm.matchTerminal(terminalValuesMap, "", cssSelector, matchedCallback) || result
@@ -435,9 +510,9 @@ func (sm *SelectorMatcher[T]) Match(cssSelector *CssSelector, matchedCallback Ma
 		}
 
 		partialValuesMap, ok := sm.attrValuePartialMap[name]
-		fmt.Printf("[DEBUG CSS Match] attrValuePartialMap[%q] exists=%v\n", name, ok)
+		fmt.Fprintf(os.Stderr, "[DEBUG CSS Match] attrValuePartialMap[%q] exists=%v\n", name, ok)
 		if ok {
-			fmt.Printf("[DEBUG CSS Match] Calling matchPartial for name=%q, value=%q\n", name, value)
+			fmt.Fprintf(os.Stderr, "[DEBUG CSS Match] Calling matchPartial for name=%q, value=%q\n", name, value)
 			if value != "" {
 				result = sm.matchPartial(partialValuesMap, "", cssSelector, matchedCallback) || result
 			}
@@ -481,13 +556,13 @@ func (sm *SelectorMatcher[T]) matchPartial(map_ map[string]*SelectorMatcher[T],
 
 	nestedSelector, ok := map_[name]
 	if !ok {
-		fmt.Printf("[DEBUG CSS matchPartial] map_[%q] not found\n", name)
+		fmt.Fprintf(os.Stderr, "[DEBUG CSS matchPartial] map_[%q] not found\n", name)
 		return false
 	}
 
-	fmt.Printf("[DEBUG CSS matchPartial] Found nested matcher for %q, calling nested.Match...\n", name)
+	fmt.Fprintf(os.Stderr, "[DEBUG CSS matchPartial] Found nested matcher for %q, calling nested.Match...\n", name)
 	result := nestedSelector.Match(cssSelector, matchedCallback)
-	fmt.Printf("[DEBUG CSS matchPartial] nested.Match returned %v\n", result)
+	fmt.Fprintf(os.Stderr, "[DEBUG CSS matchPartial] nested.Match returned %v\n", result)
 	return result
 }
 
@@ -3,6 +3,7 @@ package css
 import (
 	"fmt"
 	"ngc-go/packages/compiler/src/core"
+	"os"
 	"regexp"
 	"strings"
 )
@@ -610,7 +611,7 @@ func (sc *ShadowCss) scopeSelectors(cssText string, scopeSelector string, hostSe
 
 			// Debug log for test case
 			if strings.Contains(rule.Selector, ".foo:not") && strings.Contains(rule.Selector, ".bar") {
-				fmt.Printf("  Scoped selector: %q\n", selector)
+				fmt.Fprintf(os.Stderr, "  Scoped selector: %q\n", selector)
 			}
 		} else {
 			// Check if it's a scoped at-rule
@@ -70,7 +70,8 @@ func (v *SerializerVisitor) VisitContainer(container *Container, context interfa
 // VisitIcu serializes an Icu node
 func (v *SerializerVisitor) VisitIcu(icu *Icu, context interface{}) interface{} {
 	strCases := make([]string, 0, len(icu.Cases))
-	for k, node := range icu.Cases {
+	for _, k := range icu.OrderedCaseKeys() {
+		node := icu.Cases[k]
 		result := node.Visit(v, nil)
 		if str, ok := result.(string); ok {
 			strCases = append(strCases, k+" {"+str+"}")
@@ -151,7 +152,8 @@ type SerializerIgnoreIcuExpVisitor struct {
 // VisitIcu serializes an Icu node without the expression
 func (v *SerializerIgnoreIcuExpVisitor) VisitIcu(icu *Icu, context interface{}) interface{} {
 	strCases := make([]string, 0, len(icu.Cases))
-	for k, node := range icu.Cases {
+	for _, k := range icu.OrderedCaseKeys() {
+		node := icu.Cases[k]
 		result := node.Visit(v, nil)
 		if str, ok := result.(string); ok {
 			strCases = append(strCases, k+" {"+str+"}")
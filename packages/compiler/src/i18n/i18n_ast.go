@@ -1,6 +1,8 @@
 package i18n
 
 import (
+	"sort"
+
 	"ngc-go/packages/compiler/src/util"
 )
 
@@ -139,6 +141,11 @@ type Icu struct {
 	Cases                 map[string]Node
 	sourceSpan            *util.ParseSourceSpan
 	ExpressionPlaceholder string
+	// CaseKeys holds the keys of Cases in source order.
+	CaseKeys []string
+	// Name is the placeholder name of the ICU when it is a sub-message of an
+	// enclosing i18n block.
+	Name string
 }
 
 // NewIcu creates a new Icu node
@@ -152,6 +159,20 @@ func NewIcu(expression string, icuType string, cases map[string]Node, sourceSpan
 	}
 }
 
+// OrderedCaseKeys returns the keys of Cases in source order. Nodes built without
+// an order fall back to the sorted keys so that serialization is stable.
+func (i *Icu) OrderedCaseKeys() []string {
+	if len(i.CaseKeys) == len(i.Cases) {
+		return i.CaseKeys
+	}
+	keys := make([]string, 0, len(i.Cases))
+	for k := range i.Cases {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // SourceSpan returns the source span
 func (i *Icu) SourceSpan() *util.ParseSourceSpan {
 	return i.sourceSpan
@@ -353,7 +374,10 @@ func (v *CloneVisitor) VisitIcu(icu *Icu, context interface{}) interface{} {
 	for key, node := range icu.Cases {
 		cases[key] = node.Visit(v, context).(Node)
 	}
-	return NewIcu(icu.Expression, icu.Type, cases, icu.sourceSpan, icu.ExpressionPlaceholder)
+	clone := NewIcu(icu.Expression, icu.Type, cases, icu.sourceSpan, icu.ExpressionPlaceholder)
+	clone.CaseKeys = append([]string(nil), icu.CaseKeys...)
+	clone.Name = icu.Name
+	return clone
 }
 
 // VisitTagPlaceholder clones a TagPlaceholder node
@@ -426,7 +450,8 @@ func (v *RecurseVisitor) VisitContainer(container *Container, context interface{
 
 // VisitIcu visits an Icu node
 func (v *RecurseVisitor) VisitIcu(icu *Icu, context interface{}) interface{} {
-	for _, node := range icu.Cases {
+	for _, k := range icu.OrderedCaseKeys() {
+		node := icu.Cases[k]
 		node.Visit(v, nil)
 	}
 	return nil
@@ -509,7 +534,8 @@ func (v *LocalizeMessageStringVisitor) VisitContainer(container *Container, cont
 // VisitIcu serializes an Icu node
 func (v *LocalizeMessageStringVisitor) VisitIcu(icu *Icu, context interface{}) interface{} {
 	strCases := make([]string, 0, len(icu.Cases))
-	for k, node := range icu.Cases {
+	for _, k := range icu.OrderedCaseKeys() {
+		node := icu.Cases[k]
 		result := node.Visit(v, nil)
 		if str, ok := result.(string); ok {
 			strCases = append(strCases, k+" {"+str+"}")
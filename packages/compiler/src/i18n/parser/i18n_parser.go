@@ -228,6 +228,7 @@ func (v *I18nVisitor) VisitExpansion(icu *ml_parser.Expansion, context interface
 			}
 		}
 		i18nIcuCases[caze.Value] = i18n.NewContainer(caseNodes, caze.ExpSourceSpan)
+		i18nIcu.CaseKeys = append(i18nIcu.CaseKeys, caze.Value)
 	}
 	ctx.IcuDepth--
 
@@ -117,7 +117,8 @@ func (v *PlaceholderNameVisitor) VisitContainer(container *i18n.Container, conte
 
 // VisitIcu visits an Icu node
 func (v *PlaceholderNameVisitor) VisitIcu(icu *i18n.Icu, context interface{}) interface{} {
-	for _, node := range icu.Cases {
+	for _, k := range icu.OrderedCaseKeys() {
+		node := icu.Cases[k]
 		node.Visit(v, context)
 	}
 	return nil
@@ -194,7 +194,8 @@ func (v *I18nToHtmlVisitor) VisitContainer(container *i18n.Container, context in
 // VisitIcu visits an Icu node
 func (v *I18nToHtmlVisitor) VisitIcu(icu *i18n.Icu, context interface{}) interface{} {
 	cases := make([]string, 0, len(icu.Cases))
-	for k, node := range icu.Cases {
+	for _, k := range icu.OrderedCaseKeys() {
+		node := icu.Cases[k]
 		result := node.Visit(v, nil)
 		if str, ok := result.(string); ok {
 			cases = append(cases, k+" {"+str+"}")
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"ngc-go/packages/compiler/src/core"
 	"ngc-go/packages/compiler/src/util"
+	"os"
 	"strconv"
 	"strings"
 	"unicode/utf8"
@@ -44,10 +45,10 @@ func (t *Tokenizer) Tokenize() {
 		if iterationCount > 1000 {
 			peekChar := t.cursor.Peek()
 			if peekChar >= 32 && peekChar < 127 {
-				fmt.Printf("[DEBUG] Tokenize: INFINITE LOOP DETECTED! iterationCount=%d, peek='%c' (%d), CharEOF=%d\n",
+				fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: INFINITE LOOP DETECTED! iterationCount=%d, peek='%c' (%d), CharEOF=%d\n",
 					iterationCount, peekChar, peekChar, core.CharEOF)
 			} else {
-				fmt.Printf("[DEBUG] Tokenize: INFINITE LOOP DETECTED! iterationCount=%d, peek=%d, CharEOF=%d\n",
+				fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: INFINITE LOOP DETECTED! iterationCount=%d, peek=%d, CharEOF=%d\n",
 					iterationCount, peekChar, core.CharEOF)
 			}
 			break
@@ -55,23 +56,23 @@ func (t *Tokenizer) Tokenize() {
 		if iterationCount <= 20 || iterationCount%100 == 0 {
 			peekChar := t.cursor.Peek()
 			if peekChar >= 32 && peekChar < 127 {
-				fmt.Printf("[DEBUG] Tokenize: iteration=%d, peek='%c' (%d)\n", iterationCount, peekChar, peekChar)
+				fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: iteration=%d, peek='%c' (%d)\n", iterationCount, peekChar, peekChar)
 			} else {
-				fmt.Printf("[DEBUG] Tokenize: iteration=%d, peek=%d\n", iterationCount, peekChar)
+				fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: iteration=%d, peek=%d\n", iterationCount, peekChar)
 			}
 		}
 		start := t.cursor.Clone()
 		if t._attemptCharCode(core.CharLT) {
 			if iterationCount <= 20 {
-				fmt.Printf("[DEBUG] Tokenize: found '<', checking next char\n")
+				fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: found '<', checking next char\n")
 			}
 			if t._attemptCharCode(core.CharBANG) {
 				if iterationCount <= 20 {
-					fmt.Printf("[DEBUG] Tokenize: found '<!'\n")
+					fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: found '<!'\n")
 				}
 				if t._attemptCharCode(core.CharLBRACKET) {
 					if iterationCount <= 20 {
-						fmt.Printf("[DEBUG] Tokenize: found '<![', consuming CDATA\n")
+						fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: found '<![', consuming CDATA\n")
 					}
 					func() {
 						defer func() {
@@ -83,7 +84,7 @@ func (t *Tokenizer) Tokenize() {
 					}()
 				} else if t._attemptCharCode(core.CharMINUS) {
 					if iterationCount <= 20 {
-						fmt.Printf("[DEBUG] Tokenize: found '<!-', consuming comment\n")
+						fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: found '<!-', consuming comment\n")
 					}
 					func() {
 						defer func() {
@@ -106,7 +107,7 @@ func (t *Tokenizer) Tokenize() {
 					}()
 				} else {
 					if iterationCount <= 20 {
-						fmt.Printf("[DEBUG] Tokenize: found '<!', consuming doctype\n")
+						fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: found '<!', consuming doctype\n")
 					}
 					func() {
 						defer func() {
@@ -119,23 +120,23 @@ func (t *Tokenizer) Tokenize() {
 				}
 			} else if t._attemptCharCode(core.CharSLASH) {
 				if iterationCount <= 20 {
-					fmt.Printf("[DEBUG] Tokenize: found '</', consuming tag close\n")
+					fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: found '</', consuming tag close\n")
 				}
 				t._consumeTagClose(start)
 			} else {
 				if iterationCount <= 20 {
-					fmt.Printf("[DEBUG] Tokenize: found '<', consuming tag open\n")
+					fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: found '<', consuming tag open\n")
 				}
 				tagInfo := t._consumeTagOpen(start)
 				if iterationCount <= 20 {
-					fmt.Printf("[DEBUG] Tokenize: _consumeTagOpen done, peek=%d\n", t.cursor.Peek())
+					fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: _consumeTagOpen done, peek=%d\n", t.cursor.Peek())
 				}
 				// Check if we need to consume raw text (for script, style, title, textarea, etc.)
 				if tagInfo != nil && !tagInfo.isSelfClosing {
 					prefix := tagInfo.prefix
 					tagName := tagInfo.name
 					if iterationCount <= 20 {
-						fmt.Printf("[DEBUG] Tokenize: checking content type for tag=%s, prefix=%s, isSelfClosing=%v\n", tagName, prefix, tagInfo.isSelfClosing)
+						fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: checking content type for tag=%s, prefix=%s, isSelfClosing=%v\n", tagName, prefix, tagInfo.isSelfClosing)
 					}
 					// Use getTagDefinition if provided, otherwise use default HTML tag definitions
 					getTagDef := t.getTagDefinition
@@ -145,7 +146,7 @@ func (t *Tokenizer) Tokenize() {
 					if getTagDef != nil {
 						tagDef := getTagDef(tagName)
 						if iterationCount <= 20 {
-							fmt.Printf("[DEBUG] Tokenize: tagDef=%v\n", tagDef != nil)
+							fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: tagDef=%v\n", tagDef != nil)
 						}
 						if tagDef != nil {
 							var prefixPtr *string
@@ -154,7 +155,7 @@ func (t *Tokenizer) Tokenize() {
 							}
 							contentType := tagDef.GetContentType(prefixPtr)
 							if iterationCount <= 20 {
-								fmt.Printf("[DEBUG] Tokenize: contentType=%v (RAW_TEXT=%v, ESCAPABLE_RAW_TEXT=%v)\n", contentType, TagContentTypeRAW_TEXT, TagContentTypeESCAPABLE_RAW_TEXT)
+								fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: contentType=%v (RAW_TEXT=%v, ESCAPABLE_RAW_TEXT=%v)\n", contentType, TagContentTypeRAW_TEXT, TagContentTypeESCAPABLE_RAW_TEXT)
 							}
 							// Find the open token (TAG_OPEN_START or COMPONENT_OPEN_START) to pass to _consumeRawTextWithTagClose
 							var openToken Token
@@ -170,12 +171,12 @@ func (t *Tokenizer) Tokenize() {
 							}
 							if contentType == TagContentTypeRAW_TEXT {
 								if iterationCount <= 20 {
-									fmt.Printf("[DEBUG] Tokenize: consuming RAW_TEXT for tag=%s\n", tagName)
+									fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: consuming RAW_TEXT for tag=%s\n", tagName)
 								}
 								t._consumeRawTextWithTagClose(openToken, tagInfo.closingTagName, false)
 							} else if contentType == TagContentTypeESCAPABLE_RAW_TEXT {
 								if iterationCount <= 20 {
-									fmt.Printf("[DEBUG] Tokenize: consuming ESCAPABLE_RAW_TEXT for tag=%s\n", tagName)
+									fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: consuming ESCAPABLE_RAW_TEXT for tag=%s\n", tagName)
 								}
 								t._consumeRawTextWithTagClose(openToken, tagInfo.closingTagName, true)
 							}
@@ -196,17 +197,17 @@ func (t *Tokenizer) Tokenize() {
 			peekChar := t.cursor.Peek()
 			shouldTokenizeExpansion := t.tokenizeIcu && !t.inInterpolation
 			if peekChar == core.CharLBRACE || peekChar == core.CharRBRACE {
-				fmt.Printf("[DEBUG] Tokenize: found '%c', checking expansion form, tokenizeIcu=%v, inInterpolation=%v, peek=%d, iteration=%d\n",
+				fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: found '%c', checking expansion form, tokenizeIcu=%v, inInterpolation=%v, peek=%d, iteration=%d\n",
 					peekChar, t.tokenizeIcu, t.inInterpolation, peekChar, iterationCount)
 			}
 			expansionFormTokenized := false
 			if shouldTokenizeExpansion {
 				expansionFormTokenized = t._tokenizeExpansionForm()
 				if peekChar == core.CharLBRACE || peekChar == core.CharRBRACE {
-					fmt.Printf("[DEBUG] Tokenize: _tokenizeExpansionForm returned %v, peek after=%d\n", expansionFormTokenized, t.cursor.Peek())
+					fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: _tokenizeExpansionForm returned %v, peek after=%d\n", expansionFormTokenized, t.cursor.Peek())
 				}
 			} else if peekChar == core.CharLBRACE || peekChar == core.CharRBRACE {
-				fmt.Printf("[DEBUG] Tokenize: NOT checking expansion form (shouldTokenizeExpansion=false), tokenizeIcu=%v, inInterpolation=%v\n",
+				fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: NOT checking expansion form (shouldTokenizeExpansion=false), tokenizeIcu=%v, inInterpolation=%v\n",
 					t.tokenizeIcu, t.inInterpolation)
 			}
 
@@ -230,7 +231,7 @@ func (t *Tokenizer) Tokenize() {
 				// In (possibly interpolated) text the end of the text is given by `isTextEnd()`, while
 				// the premature end of an interpolation is given by the start of a new HTML element.
 				if peekChar == core.CharLBRACE {
-					fmt.Printf("[DEBUG] Tokenize: NOT tokenizing expansion form, calling _consumeWithInterpolation, tokenizeIcu=%v, inInterpolation=%v, peek=%d\n",
+					fmt.Fprintf(os.Stderr, "[DEBUG] Tokenize: NOT tokenizing expansion form, calling _consumeWithInterpolation, tokenizeIcu=%v, inInterpolation=%v, peek=%d\n",
 						t.tokenizeIcu, t.inInterpolation, peekChar)
 				}
 				t._consumeWithInterpolation(
@@ -840,28 +841,28 @@ func (t *Tokenizer) _consumeTagClose(start CharacterCursor) {
 }
 
 func (t *Tokenizer) _consumeAttributesAndDirectives() {
-	fmt.Printf("[DEBUG] lexer._consumeAttributesAndDirectives: START, peek=%d\n", t.cursor.Peek())
+	fmt.Fprintf(os.Stderr, "[DEBUG] lexer._consumeAttributesAndDirectives: START, peek=%d\n", t.cursor.Peek())
 	attrIterationCount := 0
 	for !isAttributeTerminator(t.cursor.Peek()) {
 		attrIterationCount++
 		if attrIterationCount > 1000 {
-			fmt.Printf("[DEBUG] lexer._consumeAttributesAndDirectives: INFINITE LOOP DETECTED! iterationCount=%d, peek=%d\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] lexer._consumeAttributesAndDirectives: INFINITE LOOP DETECTED! iterationCount=%d, peek=%d\n",
 				attrIterationCount, t.cursor.Peek())
 			break
 		}
 		if attrIterationCount <= 20 || attrIterationCount%100 == 0 {
-			fmt.Printf("[DEBUG] lexer._consumeAttributesAndDirectives: iteration=%d, peek=%d\n", attrIterationCount, t.cursor.Peek())
+			fmt.Fprintf(os.Stderr, "[DEBUG] lexer._consumeAttributesAndDirectives: iteration=%d, peek=%d\n", attrIterationCount, t.cursor.Peek())
 		}
 		t._attemptCharCodeUntilFn(isNotWhitespace)
 		if isAttributeTerminator(t.cursor.Peek()) {
-			fmt.Printf("[DEBUG] lexer._consumeAttributesAndDirectives: found end char, breaking\n")
+			fmt.Fprintf(os.Stderr, "[DEBUG] lexer._consumeAttributesAndDirectives: found end char, breaking\n")
 			break
 		}
 		// If we encounter a quote that's not part of an attribute value (no '=' before it),
 		// stop consuming attributes and let the tag close consume it as text
 		// (e.g., `<t a='b' '>` - the second quote should be treated as text)
 		if t.cursor.Peek() == core.CharSQ || t.cursor.Peek() == core.CharDQ {
-			fmt.Printf("[DEBUG] lexer._consumeAttributesAndDirectives: found quote, stopping attribute consumption\n")
+			fmt.Fprintf(os.Stderr, "[DEBUG] lexer._consumeAttributesAndDirectives: found quote, stopping attribute consumption\n")
 			break
 		}
 		// Check if next char is '=' - if so, this means we're in the middle of an attribute value
@@ -879,18 +880,11 @@ func (t *Tokenizer) _consumeAttributesAndDirectives() {
 		} else {
 			// Save peek before consuming attr to check if cursor advanced
 			peekBefore := t.cursor.Peek()
-			offsetBefore := 0
-			if plainCursor, ok := t.cursor.(*PlainCharacterCursor); ok {
-				offsetBefore = plainCursor.state.Offset
-			}
+			cursorBefore := t.cursor.Clone()
 			t._consumeAttr()
 			// If cursor didn't advance (e.g., quote without =), advance it to avoid infinite loop
-			offsetAfter := 0
-			if plainCursor, ok := t.cursor.(*PlainCharacterCursor); ok {
-				offsetAfter = plainCursor.state.Offset
-			}
-			if offsetAfter == offsetBefore && peekBefore != core.CharGT && peekBefore != core.CharSLASH && peekBefore != core.CharEOF {
-				fmt.Printf("[DEBUG] lexer._consumeAttributesAndDirectives: cursor didn't advance after _consumeAttr, peek=%d, forcing advance\n", peekBefore)
+			if t.cursor.Diff(cursorBefore) == 0 && peekBefore != core.CharGT && peekBefore != core.CharSLASH && peekBefore != core.CharEOF {
+				fmt.Fprintf(os.Stderr, "[DEBUG] lexer._consumeAttributesAndDirectives: cursor didn't advance after _consumeAttr, peek=%d, forcing advance\n", peekBefore)
 				t.cursor.Advance()
 			}
 		}
@@ -937,23 +931,23 @@ func (t *Tokenizer) _consumeAttr() {
 			}
 			// Debug: log bracket updates and newline checks
 			if code == core.CharLBRACKET || code == core.CharRBRACKET {
-				fmt.Printf("[DEBUG] _consumeAttr: nameEndPredicate bracket update, code=%d, openBrackets: %d -> %d\n", code, oldOpenBrackets, openBrackets)
+				fmt.Fprintf(os.Stderr, "[DEBUG] _consumeAttr: nameEndPredicate bracket update, code=%d, openBrackets: %d -> %d\n", code, oldOpenBrackets, openBrackets)
 			}
 			// Check for newline when openBrackets > 0 (matches TypeScript: chars.isNewLine(code) when openBrackets > 0)
 			// This should stop parsing at newline, so the name doesn't include it
 			isNewline := code == core.CharLF || code == core.CharCR
 			if isNewline {
-				fmt.Printf("[DEBUG] _consumeAttr: nameEndPredicate checking newline, openBrackets=%d, code=%d\n", openBrackets, code)
+				fmt.Fprintf(os.Stderr, "[DEBUG] _consumeAttr: nameEndPredicate checking newline, openBrackets=%d, code=%d\n", openBrackets, code)
 			}
 			if openBrackets > 0 && isNewline {
-				fmt.Printf("[DEBUG] _consumeAttr: nameEndPredicate returning true for newline, openBrackets=%d, code=%d ('%c')\n", openBrackets, code, rune(code))
+				fmt.Fprintf(os.Stderr, "[DEBUG] _consumeAttr: nameEndPredicate returning true for newline, openBrackets=%d, code=%d ('%c')\n", openBrackets, code, rune(code))
 				return true
 			}
 			// Only check for name-ending characters if the brackets are balanced or mismatched
 			if openBrackets <= 0 {
 				result := isNameEnd(code) || code == core.CharEQ
 				if result {
-					fmt.Printf("[DEBUG] _consumeAttr: nameEndPredicate returning true for nameEnd/EQ, openBrackets=%d, code=%d\n", openBrackets, code)
+					fmt.Fprintf(os.Stderr, "[DEBUG] _consumeAttr: nameEndPredicate returning true for nameEnd/EQ, openBrackets=%d, code=%d\n", openBrackets, code)
 				}
 				return result
 			}
@@ -1111,13 +1105,13 @@ func (t *Tokenizer) _consumeAttributeValue() {
 }
 
 func (t *Tokenizer) _consumeQuote(quoteChar int) {
-	fmt.Printf("[DEBUG] _consumeQuote: START, quoteChar=%d ('%c'), peek=%d\n", quoteChar, rune(quoteChar), t.cursor.Peek())
+	fmt.Fprintf(os.Stderr, "[DEBUG] _consumeQuote: START, quoteChar=%d ('%c'), peek=%d\n", quoteChar, rune(quoteChar), t.cursor.Peek())
 	t._beginToken(TokenTypeATTR_QUOTE, nil)
 	t._requireCharCode(quoteChar)
 	quoteStr := string(rune(quoteChar))
-	fmt.Printf("[DEBUG] _consumeQuote: ending token with quote=%q\n", quoteStr)
+	fmt.Fprintf(os.Stderr, "[DEBUG] _consumeQuote: ending token with quote=%q\n", quoteStr)
 	t._endToken([]string{quoteStr}, nil)
-	fmt.Printf("[DEBUG] _consumeQuote: END, totalTokens=%d\n", len(t.tokens))
+	fmt.Fprintf(os.Stderr, "[DEBUG] _consumeQuote: END, totalTokens=%d\n", len(t.tokens))
 }
 
 func (t *Tokenizer) _isLetStart() bool {
@@ -1213,10 +1207,10 @@ func (t *Tokenizer) _tokenizeExpansionForm() bool {
 	if peekChar == core.CharRBRACE {
 		isInCase := t._isInExpansionCase()
 		isInForm := t._isInExpansionForm()
-		fmt.Printf("[DEBUG] _tokenizeExpansionForm: found '}', checking expansion end, isInExpansionCase=%v, isInExpansionForm=%v, stackLen=%d\n",
+		fmt.Fprintf(os.Stderr, "[DEBUG] _tokenizeExpansionForm: found '}', checking expansion end, isInExpansionCase=%v, isInExpansionForm=%v, stackLen=%d\n",
 			isInCase, isInForm, len(t.expansionCaseStack))
 		if len(t.expansionCaseStack) > 0 {
-			fmt.Printf("[DEBUG] _tokenizeExpansionForm: stack contents: %v\n", t.expansionCaseStack)
+			fmt.Fprintf(os.Stderr, "[DEBUG] _tokenizeExpansionForm: stack contents: %v\n", t.expansionCaseStack)
 		}
 	}
 	if t._isExpansionFormStart() {
@@ -1228,18 +1222,18 @@ func (t *Tokenizer) _tokenizeExpansionForm() bool {
 		return true
 	}
 	if t._isExpansionCaseEnd() {
-		fmt.Printf("[DEBUG] _tokenizeExpansionForm: found expansion case end\n")
+		fmt.Fprintf(os.Stderr, "[DEBUG] _tokenizeExpansionForm: found expansion case end\n")
 		t._consumeExpansionCaseEnd()
-		fmt.Printf("[DEBUG] _tokenizeExpansionForm: after consume case end, stackLen=%d\n", len(t.expansionCaseStack))
+		fmt.Fprintf(os.Stderr, "[DEBUG] _tokenizeExpansionForm: after consume case end, stackLen=%d\n", len(t.expansionCaseStack))
 		return true
 	}
 	if t._isExpansionFormEnd() {
-		fmt.Printf("[DEBUG] _tokenizeExpansionForm: found expansion form end\n")
+		fmt.Fprintf(os.Stderr, "[DEBUG] _tokenizeExpansionForm: found expansion form end\n")
 		t._consumeExpansionFormEnd()
 		return true
 	}
 	if peekChar == core.CharRBRACE {
-		fmt.Printf("[DEBUG] _tokenizeExpansionForm: '}' not recognized as expansion end, returning false\n")
+		fmt.Fprintf(os.Stderr, "[DEBUG] _tokenizeExpansionForm: '}' not recognized as expansion end, returning false\n")
 	}
 	return false
 }
@@ -1254,7 +1248,7 @@ func (t *Tokenizer) _isExpansionFormStart() bool {
 	temp.Advance()
 	nextChar := temp.Peek()
 	result := nextChar != core.CharLBRACE
-	fmt.Printf("[DEBUG] _isExpansionFormStart: peek=%d, nextChar=%d, result=%v\n", t.cursor.Peek(), nextChar, result)
+	fmt.Fprintf(os.Stderr, "[DEBUG] _isExpansionFormStart: peek=%d, nextChar=%d, result=%v\n", t.cursor.Peek(), nextChar, result)
 	return result
 }
 
@@ -1300,10 +1294,10 @@ func (t *Tokenizer) _isExpansionCaseStart() bool {
 	isValidStart := peekChar == core.CharEQ || isAsciiLetter(peekChar) || isDigit(peekChar)
 	result := isInForm && isValidStart
 	if peekChar == core.CharEQ {
-		fmt.Printf("[DEBUG] _isExpansionCaseStart: peek='=', isInForm=%v, isValidStart=%v, result=%v, stackLen=%d\n",
+		fmt.Fprintf(os.Stderr, "[DEBUG] _isExpansionCaseStart: peek='=', isInForm=%v, isValidStart=%v, result=%v, stackLen=%d\n",
 			isInForm, isValidStart, result, len(t.expansionCaseStack))
 		if len(t.expansionCaseStack) > 0 {
-			fmt.Printf("[DEBUG] _isExpansionCaseStart: top of stack=%d\n", t.expansionCaseStack[len(t.expansionCaseStack)-1])
+			fmt.Fprintf(os.Stderr, "[DEBUG] _isExpansionCaseStart: top of stack=%d\n", t.expansionCaseStack[len(t.expansionCaseStack)-1])
 		}
 	}
 	return result
@@ -1344,10 +1338,10 @@ func (t *Tokenizer) _isExpansionFormEnd() bool {
 	isInForm := t._isInExpansionForm()
 	result := peekChar == core.CharRBRACE && isInForm
 	if peekChar == core.CharRBRACE {
-		fmt.Printf("[DEBUG] _isExpansionFormEnd: peek='}', isInForm=%v, result=%v, stackLen=%d\n",
+		fmt.Fprintf(os.Stderr, "[DEBUG] _isExpansionFormEnd: peek='}', isInForm=%v, result=%v, stackLen=%d\n",
 			isInForm, result, len(t.expansionCaseStack))
 		if len(t.expansionCaseStack) > 0 {
-			fmt.Printf("[DEBUG] _isExpansionFormEnd: top of stack=%d\n", t.expansionCaseStack[len(t.expansionCaseStack)-1])
+			fmt.Fprintf(os.Stderr, "[DEBUG] _isExpansionFormEnd: top of stack=%d\n", t.expansionCaseStack[len(t.expansionCaseStack)-1])
 		}
 	}
 	return result
@@ -1391,12 +1385,12 @@ type TagInfo struct {
 }
 
 func (t *Tokenizer) _consumeTagOpen(start CharacterCursor) *TagInfo {
-	fmt.Printf("[DEBUG] _consumeTagOpen: START, peek=%d\n", t.cursor.Peek())
+	fmt.Fprintf(os.Stderr, "[DEBUG] _consumeTagOpen: START, peek=%d\n", t.cursor.Peek())
 	var openTokenStarted bool
 
 	// Use defer/recover to handle incomplete tags (terminated by EOF)
 	defer func() {
-		fmt.Printf("[DEBUG] _consumeTagOpen: END (defer), peek=%d\n", t.cursor.Peek())
+		fmt.Fprintf(os.Stderr, "[DEBUG] _consumeTagOpen: END (defer), peek=%d\n", t.cursor.Peek())
 		if r := recover(); r != nil {
 			// Check if it's a ParseError (from _createError) or CursorError
 			var isParseError bool
@@ -1416,7 +1410,7 @@ func (t *Tokenizer) _consumeTagOpen(start CharacterCursor) *TagInfo {
 						if token != nil && token.Type() == TokenTypeTAG_OPEN_START {
 							if tagToken, ok := token.(*TagOpenStartToken); ok {
 								tagToken.TokenBase.tokenType = TokenTypeINCOMPLETE_TAG_OPEN
-								fmt.Printf("[DEBUG] _consumeTagOpen: changed token[%d] to INCOMPLETE_TAG_OPEN\n", i)
+								fmt.Fprintf(os.Stderr, "[DEBUG] _consumeTagOpen: changed token[%d] to INCOMPLETE_TAG_OPEN\n", i)
 								break
 							}
 						}
@@ -1479,7 +1473,7 @@ func (t *Tokenizer) _consumeTagOpen(start CharacterCursor) *TagInfo {
 						t.cursor.Advance()
 					}
 					textValue := t.cursor.GetChars(textStart)
-					fmt.Printf("[DEBUG] _consumeTagOpen: creating TEXT token with value=%q, textStart offset=%d, cursor offset=%d\n",
+					fmt.Fprintf(os.Stderr, "[DEBUG] _consumeTagOpen: creating TEXT token with value=%q, textStart offset=%d, cursor offset=%d\n",
 						textValue, textStart.Diff(t.cursor), t.cursor.Diff(textStart))
 					t._beginToken(TokenTypeTEXT, textStart)
 					t._endToken([]string{textValue}, nil)
@@ -1498,7 +1492,7 @@ func (t *Tokenizer) _consumeTagOpen(start CharacterCursor) *TagInfo {
 
 	// Check if this is a component tag (selectorless enabled and starts with uppercase or underscore)
 	if t.selectorlessEnabled && isSelectorlessNameStart(t.cursor.Peek()) {
-		fmt.Printf("[DEBUG] _consumeTagOpen: detected component tag, peek=%d\n", t.cursor.Peek())
+		fmt.Fprintf(os.Stderr, "[DEBUG] _consumeTagOpen: detected component tag, peek=%d\n", t.cursor.Peek())
 		openToken = t._consumeComponentOpenStart(start)
 		parts := openToken.Parts()
 		closingTagName = parts[0]
@@ -1524,11 +1518,11 @@ func (t *Tokenizer) _consumeTagOpen(start CharacterCursor) *TagInfo {
 				t.cursor.GetSpan(start, nil),
 			))
 		}
-		fmt.Printf("[DEBUG] _consumeTagOpen: calling _consumePrefixAndName, peek=%d\n", t.cursor.Peek())
+		fmt.Fprintf(os.Stderr, "[DEBUG] _consumeTagOpen: calling _consumePrefixAndName, peek=%d\n", t.cursor.Peek())
 		prefixAndName := t._consumePrefixAndName(func(code int) bool {
 			return isNameEnd(code) || code == core.CharSLASH
 		})
-		fmt.Printf("[DEBUG] _consumeTagOpen: got prefixAndName=%v, peek=%d\n", prefixAndName, t.cursor.Peek())
+		fmt.Fprintf(os.Stderr, "[DEBUG] _consumeTagOpen: got prefixAndName=%v, peek=%d\n", prefixAndName, t.cursor.Peek())
 		prefix = prefixAndName[0]
 		name = prefixAndName[1]
 		if len(prefixAndName) > 2 {
@@ -1545,7 +1539,7 @@ func (t *Tokenizer) _consumeTagOpen(start CharacterCursor) *TagInfo {
 
 	// Consume attributes and directives
 	t._consumeAttributesAndDirectives()
-	fmt.Printf("[DEBUG] _consumeTagOpen: after _consumeAttributesAndDirectives, peek=%d\n", t.cursor.Peek())
+	fmt.Fprintf(os.Stderr, "[DEBUG] _consumeTagOpen: after _consumeAttributesAndDirectives, peek=%d\n", t.cursor.Peek())
 
 	// Check if we have an incomplete tag due to newline in attribute name
 	hasIncompleteAttr := false
@@ -1994,7 +1988,7 @@ func (t *Tokenizer) _endToken(parts []string, end CharacterCursor) Token {
 
 	t.tokens = append(t.tokens, token)
 	if token.Type() == TokenTypeTAG_OPEN_START {
-		fmt.Printf("[DEBUG] _endToken: added TAG_OPEN_START to tokens array, totalTokens=%d, parts=%v\n",
+		fmt.Fprintf(os.Stderr, "[DEBUG] _endToken: added TAG_OPEN_START to tokens array, totalTokens=%d, parts=%v\n",
 			len(t.tokens), token.Parts())
 	}
 
@@ -2005,7 +1999,7 @@ func (t *Tokenizer) _endToken(parts []string, end CharacterCursor) Token {
 }
 
 func (t *Tokenizer) _consumePrefixAndName(endPredicate func(code int) bool) []string {
-	fmt.Printf("[DEBUG] _consumePrefixAndName: START, peek=%d, CharsLeft=%d\n", t.cursor.Peek(), t.cursor.CharsLeft())
+	fmt.Fprintf(os.Stderr, "[DEBUG] _consumePrefixAndName: START, peek=%d, CharsLeft=%d\n", t.cursor.Peek(), t.cursor.CharsLeft())
 	nameOrPrefixStart := t.cursor.Clone()
 	prefix := ""
 	prefixLoopCount := 0
@@ -2014,23 +2008,23 @@ func (t *Tokenizer) _consumePrefixAndName(endPredicate func(code int) bool) []st
 		if prefixLoopCount > 1000 {
 			peekBefore := t.cursor.Peek()
 			charsLeft := t.cursor.CharsLeft()
-			fmt.Printf("[DEBUG] _consumePrefixAndName: INFINITE LOOP in prefix loop! iterationCount=%d, peek=%d, isPrefixEnd=%v, CharsLeft=%d\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumePrefixAndName: INFINITE LOOP in prefix loop! iterationCount=%d, peek=%d, isPrefixEnd=%v, CharsLeft=%d\n",
 				prefixLoopCount, peekBefore, isPrefixEnd(peekBefore), charsLeft)
 			// Check if cursor can advance
 			t.cursor.Advance()
 			peekAfter := t.cursor.Peek()
-			fmt.Printf("[DEBUG] _consumePrefixAndName: after advance, peek=%d (changed: %v), CharsLeft=%d\n", peekAfter, peekBefore != peekAfter, t.cursor.CharsLeft())
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumePrefixAndName: after advance, peek=%d (changed: %v), CharsLeft=%d\n", peekAfter, peekBefore != peekAfter, t.cursor.CharsLeft())
 			break
 		}
 		peekBeforeAdvance := t.cursor.Peek()
 		charsLeftBefore := t.cursor.CharsLeft()
 		if peekBeforeAdvance == core.CharEOF || charsLeftBefore <= 0 {
-			fmt.Printf("[DEBUG] _consumePrefixAndName: reached EOF, breaking (peek=%d, CharsLeft=%d)\n", peekBeforeAdvance, charsLeftBefore)
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumePrefixAndName: reached EOF, breaking (peek=%d, CharsLeft=%d)\n", peekBeforeAdvance, charsLeftBefore)
 			break
 		}
 		// Check if we should break based on the condition
 		if t.cursor.Peek() == core.CharCOLON || isPrefixEnd(t.cursor.Peek()) {
-			fmt.Printf("[DEBUG] _consumePrefixAndName: condition met, breaking (peek=%d, isColon=%v, isPrefixEnd=%v)\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumePrefixAndName: condition met, breaking (peek=%d, isColon=%v, isPrefixEnd=%v)\n",
 				t.cursor.Peek(), t.cursor.Peek() == core.CharCOLON, isPrefixEnd(t.cursor.Peek()))
 			break
 		}
@@ -2038,36 +2032,36 @@ func (t *Tokenizer) _consumePrefixAndName(endPredicate func(code int) bool) []st
 		peekAfterAdvance := t.cursor.Peek()
 		charsLeftAfter := t.cursor.CharsLeft()
 		if peekBeforeAdvance == peekAfterAdvance && prefixLoopCount > 10 {
-			fmt.Printf("[DEBUG] _consumePrefixAndName: cursor not advancing! peek=%d (EOF=%d), CharsLeft before=%d, after=%d, iteration=%d\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumePrefixAndName: cursor not advancing! peek=%d (EOF=%d), CharsLeft before=%d, after=%d, iteration=%d\n",
 				peekBeforeAdvance, core.CharEOF, charsLeftBefore, charsLeftAfter, prefixLoopCount)
 			// Force break to avoid infinite loop
 			break
 		}
 	}
-	fmt.Printf("[DEBUG] _consumePrefixAndName: after prefix loop, peek=%d, prefixLoopCount=%d\n", t.cursor.Peek(), prefixLoopCount)
+	fmt.Fprintf(os.Stderr, "[DEBUG] _consumePrefixAndName: after prefix loop, peek=%d, prefixLoopCount=%d\n", t.cursor.Peek(), prefixLoopCount)
 	var nameStart CharacterCursor
 	if t.cursor.Peek() == core.CharCOLON {
 		prefix = t.cursor.GetChars(nameOrPrefixStart)
 		t.cursor.Advance()
 		nameStart = t.cursor.Clone()
-		fmt.Printf("[DEBUG] _consumePrefixAndName: found colon, prefix=%s, peek=%d\n", prefix, t.cursor.Peek())
+		fmt.Fprintf(os.Stderr, "[DEBUG] _consumePrefixAndName: found colon, prefix=%s, peek=%d\n", prefix, t.cursor.Peek())
 	} else {
 		// No prefix - nameStart is from the beginning, but cursor stays at current position
 		// This handles cases like "ref-a" where the loop stopped at "-"
 		// The cursor will continue reading from "-" until endPredicate returns true
 		nameStart = nameOrPrefixStart
-		fmt.Printf("[DEBUG] _consumePrefixAndName: no prefix, peek=%d\n", t.cursor.Peek())
+		fmt.Fprintf(os.Stderr, "[DEBUG] _consumePrefixAndName: no prefix, peek=%d\n", t.cursor.Peek())
 	}
 	// Matches TypeScript: _requireCharCodeUntilFn(endPredicate, prefix === '' ? 0 : 1)
 	minLength := 0
 	if prefix != "" {
 		minLength = 1
 	}
-	fmt.Printf("[DEBUG] _consumePrefixAndName: calling _requireCharCodeUntilFn, minLength=%d, peek=%d\n", minLength, t.cursor.Peek())
+	fmt.Fprintf(os.Stderr, "[DEBUG] _consumePrefixAndName: calling _requireCharCodeUntilFn, minLength=%d, peek=%d\n", minLength, t.cursor.Peek())
 	t._requireCharCodeUntilFn(endPredicate, minLength)
-	fmt.Printf("[DEBUG] _consumePrefixAndName: after _requireCharCodeUntilFn, peek=%d\n", t.cursor.Peek())
+	fmt.Fprintf(os.Stderr, "[DEBUG] _consumePrefixAndName: after _requireCharCodeUntilFn, peek=%d\n", t.cursor.Peek())
 	name := t.cursor.GetChars(nameStart)
-	fmt.Printf("[DEBUG] _consumePrefixAndName: END, prefix=%q, name=%q, nameStart chars=%q\n", prefix, name, func() string {
+	fmt.Fprintf(os.Stderr, "[DEBUG] _consumePrefixAndName: END, prefix=%q, name=%q, nameStart chars=%q\n", prefix, name, func() string {
 		if nameStart != nil {
 			return t.cursor.GetChars(nameStart)
 		}
@@ -2083,7 +2077,7 @@ func (t *Tokenizer) _consumeBlockEnd(start CharacterCursor) {
 
 func (t *Tokenizer) _consumeWithInterpolation(textTokenType TokenType, interpolationTokenType TokenType, isTextEnd func() bool, isTagStart func() bool) {
 	peekChar := t.cursor.Peek()
-	fmt.Printf("[DEBUG] _consumeWithInterpolation: START, peek=%d ('%c'), isTextEnd()=%v\n", peekChar, peekChar, isTextEnd())
+	fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: START, peek=%d ('%c'), isTextEnd()=%v\n", peekChar, peekChar, isTextEnd())
 	t._beginToken(textTokenType, nil)
 	parts := []string{}
 	interpIterationCount := 0
@@ -2091,21 +2085,21 @@ func (t *Tokenizer) _consumeWithInterpolation(textTokenType TokenType, interpola
 	for !isTextEnd() && t.cursor.Peek() != core.CharEOF && t.cursor.Peek() != core.CharGT {
 		interpIterationCount++
 		if interpIterationCount > 1000 {
-			fmt.Printf("[DEBUG] _consumeWithInterpolation: INFINITE LOOP DETECTED! iterationCount=%d, peek=%d, isTextEnd()=%v\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: INFINITE LOOP DETECTED! iterationCount=%d, peek=%d, isTextEnd()=%v\n",
 				interpIterationCount, t.cursor.Peek(), isTextEnd())
 			break
 		}
 		currentPeek := t.cursor.Peek()
 		if currentPeek == core.CharRBRACE {
-			fmt.Printf("[DEBUG] _consumeWithInterpolation: found '}', iteration=%d, isTextEnd()=%v, isInExpansionCase=%v, isInExpansionForm=%v\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: found '}', iteration=%d, isTextEnd()=%v, isInExpansionCase=%v, isInExpansionForm=%v\n",
 				interpIterationCount, isTextEnd(), t._isInExpansionCase(), t._isInExpansionForm())
 		}
 		if interpIterationCount <= 20 || interpIterationCount%100 == 0 {
-			fmt.Printf("[DEBUG] _consumeWithInterpolation: iteration=%d, peek=%d, isTextEnd()=%v\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: iteration=%d, peek=%d, isTextEnd()=%v\n",
 				interpIterationCount, currentPeek, isTextEnd())
 		}
 		if interpIterationCount <= 20 {
-			fmt.Printf("[DEBUG] _consumeWithInterpolation: before _attemptStr(INTERPOLATION.start), peek=%d ('%c'), CharsLeft=%d\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: before _attemptStr(INTERPOLATION.start), peek=%d ('%c'), CharsLeft=%d\n",
 				t.cursor.Peek(), t.cursor.Peek(), t.cursor.CharsLeft())
 		}
 		// Clone cursor before attempting to match INTERPOLATION.start
@@ -2115,7 +2109,7 @@ func (t *Tokenizer) _consumeWithInterpolation(textTokenType TokenType, interpola
 		beforeInterpolationCursor := t.cursor.Clone()
 		if t._attemptStr(INTERPOLATION.start) {
 			if interpIterationCount <= 20 {
-				fmt.Printf("[DEBUG] _consumeWithInterpolation: _attemptStr(INTERPOLATION.start) matched, starting interpolation\n")
+				fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: _attemptStr(INTERPOLATION.start) matched, starting interpolation\n")
 			}
 			// End the current text token before starting interpolation
 			// Use beforeInterpolationCursor as end cursor to exclude {{ from TEXT token
@@ -2162,20 +2156,20 @@ func (t *Tokenizer) _consumeWithInterpolation(textTokenType TokenType, interpola
 						// Prematurely terminated interpolation (no }} found)
 						// Don't set foundEnd=true - this is a premature termination, not a proper end
 						if interpLoopCount <= 20 {
-							fmt.Printf("[DEBUG] _consumeWithInterpolation: isTextEnd()=true and peek is quote (inQuote=%v, peek=%d), breaking (prematurely terminated)\n", inQuote != nil, peekChar)
+							fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: isTextEnd()=true and peek is quote (inQuote=%v, peek=%d), breaking (prematurely terminated)\n", inQuote != nil, peekChar)
 						}
 						break
 					} else {
 						// isTextEnd() is true but peek is not a quote (e.g., '}' in block context)
 						// Don't break - interpolation should continue until }}
 						if interpLoopCount <= 20 {
-							fmt.Printf("[DEBUG] _consumeWithInterpolation: isTextEnd()=true but peek is not quote (peek=%d), continuing\n", peekChar)
+							fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: isTextEnd()=true but peek is not quote (peek=%d), continuing\n", peekChar)
 						}
 					}
 				}
 				interpLoopCount++
 				if interpLoopCount > 1000 {
-					fmt.Printf("[DEBUG] _consumeWithInterpolation: INFINITE LOOP in interpolation! iterationCount=%d, peek=%d, inQuote=%v, inComment=%v\n",
+					fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: INFINITE LOOP in interpolation! iterationCount=%d, peek=%d, inQuote=%v, inComment=%v\n",
 						interpLoopCount, t.cursor.Peek(), inQuote != nil, inComment)
 					break
 				}
@@ -2195,7 +2189,7 @@ func (t *Tokenizer) _consumeWithInterpolation(textTokenType TokenType, interpola
 					expressionChars = t._processCarriageReturns(expressionChars)
 					interpolationParts = append(interpolationParts, expressionChars)
 					if interpLoopCount <= 20 {
-						fmt.Printf("[DEBUG] _consumeWithInterpolation: isTagStart()=true, pushing expression chars, breaking\n")
+						fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: isTagStart()=true, pushing expression chars, breaking\n")
 					}
 					foundEnd = false // This is a premature termination, not a proper end
 					break
@@ -2205,7 +2199,7 @@ func (t *Tokenizer) _consumeWithInterpolation(textTokenType TokenType, interpola
 				if inQuote == nil {
 					if t._attemptStr(INTERPOLATION.end) {
 						if interpLoopCount <= 20 {
-							fmt.Printf("[DEBUG] _consumeWithInterpolation: found INTERPOLATION.end, setting foundEnd=true, inQuote=%v\n", inQuote)
+							fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: found INTERPOLATION.end, setting foundEnd=true, inQuote=%v\n", inQuote)
 						}
 						foundEnd = true
 						break
@@ -2221,7 +2215,7 @@ func (t *Tokenizer) _consumeWithInterpolation(textTokenType TokenType, interpola
 					// This handles the case where we encounter a tag start (like <! comment) in the interpolation
 					if isTagStart != nil && isTagStart() {
 						if interpLoopCount <= 20 {
-							fmt.Printf("[DEBUG] _consumeWithInterpolation: isTagStart()=true before reading char (peek=%d), pushing expression chars, breaking\n", t.cursor.Peek())
+							fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: isTagStart()=true before reading char (peek=%d), pushing expression chars, breaking\n", t.cursor.Peek())
 						}
 						// We are starting what looks like an HTML element in the middle of this interpolation.
 						// Reset the cursor to before the `<` character and end the interpolation token.
@@ -2234,7 +2228,7 @@ func (t *Tokenizer) _consumeWithInterpolation(textTokenType TokenType, interpola
 						foundEnd = false // This is a premature termination, not a proper end
 						break
 					} else if isTagStart != nil && interpLoopCount <= 20 {
-						fmt.Printf("[DEBUG] _consumeWithInterpolation: isTagStart()=false before reading char (peek=%d), isTextEnd()=%v\n", t.cursor.Peek(), isTextEnd != nil && isTextEnd())
+						fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: isTagStart()=false before reading char (peek=%d), isTextEnd()=%v\n", t.cursor.Peek(), isTextEnd != nil && isTextEnd())
 					}
 					// Check isTextEnd() before reading char when not in quote
 					// This allows interpolation to end when matching quote is encountered in attribute value
@@ -2253,12 +2247,12 @@ func (t *Tokenizer) _consumeWithInterpolation(textTokenType TokenType, interpola
 								char := t._readChar()
 								interpolationParts = append(interpolationParts, char)
 								if interpLoopCount <= 20 {
-									fmt.Printf("[DEBUG] _consumeWithInterpolation: isTextEnd()=true, consumed char='%s' (code=%d) before breaking (peek next is '<')\n", char, int(char[0]))
+									fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: isTextEnd()=true, consumed char='%s' (code=%d) before breaking (peek next is '<')\n", char, int(char[0]))
 								}
 							}
 						}
 						if interpLoopCount <= 20 {
-							fmt.Printf("[DEBUG] _consumeWithInterpolation: isTextEnd()=true before reading char (peek=%d), breaking (prematurely terminated)\n", t.cursor.Peek())
+							fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: isTextEnd()=true before reading char (peek=%d), breaking (prematurely terminated)\n", t.cursor.Peek())
 						}
 						break
 					}
@@ -2268,7 +2262,7 @@ func (t *Tokenizer) _consumeWithInterpolation(textTokenType TokenType, interpola
 				interpolationParts = append(interpolationParts, char)
 				charCode := int(char[0])
 				if interpLoopCount <= 20 {
-					fmt.Printf("[DEBUG] _consumeWithInterpolation: read char='%s' (code=%d), inQuote=%v, isTextEnd()=%v\n", char, charCode, inQuote != nil, isTextEnd != nil && isTextEnd())
+					fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: read char='%s' (code=%d), inQuote=%v, isTextEnd()=%v\n", char, charCode, inQuote != nil, isTextEnd != nil && isTextEnd())
 				}
 				if charCode == core.CharBACKSLASH {
 					// Skip the next character because it was escaped.
@@ -2279,7 +2273,7 @@ func (t *Tokenizer) _consumeWithInterpolation(textTokenType TokenType, interpola
 				} else if inQuote != nil && charCode == *inQuote {
 					// Exiting the current quoted string
 					if interpLoopCount <= 20 {
-						fmt.Printf("[DEBUG] _consumeWithInterpolation: exiting quote, char='%s', inQuote=%d, peek after=%d, isTextEnd()=%v\n", char, *inQuote, t.cursor.Peek(), isTextEnd())
+						fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: exiting quote, char='%s', inQuote=%d, peek after=%d, isTextEnd()=%v\n", char, *inQuote, t.cursor.Peek(), isTextEnd())
 					}
 					inQuote = nil
 					// After exiting quote, continue the loop to check for }} before breaking
@@ -2288,7 +2282,7 @@ func (t *Tokenizer) _consumeWithInterpolation(textTokenType TokenType, interpola
 				} else if !inComment && inQuote == nil && (charCode == core.CharSQ || charCode == core.CharDQ) {
 					// Entering a new quoted string
 					if interpLoopCount <= 20 {
-						fmt.Printf("[DEBUG] _consumeWithInterpolation: entering quote, char='%s', charCode=%d\n", char, charCode)
+						fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: entering quote, char='%s', charCode=%d\n", char, charCode)
 					}
 					inQuote = &charCode
 				}
@@ -2298,7 +2292,7 @@ func (t *Tokenizer) _consumeWithInterpolation(textTokenType TokenType, interpola
 			expression := t._processCarriageReturns(strings.Join(interpolationParts, ""))
 			// When we hit EOF without finding a closing interpolation marker,
 			// we don't include the end marker (matches TypeScript behavior)
-			fmt.Printf("[DEBUG] _consumeWithInterpolation: ending interpolation, foundEnd=%v, expression=%q, startMarker=%q, INTERPOLATION.end=%q\n", foundEnd, expression, startMarker, INTERPOLATION.end)
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: ending interpolation, foundEnd=%v, expression=%q, startMarker=%q, INTERPOLATION.end=%q\n", foundEnd, expression, startMarker, INTERPOLATION.end)
 			if foundEnd {
 				t._endToken([]string{startMarker, expression, INTERPOLATION.end}, nil)
 			} else {
@@ -2318,7 +2312,7 @@ func (t *Tokenizer) _consumeWithInterpolation(textTokenType TokenType, interpola
 			t._beginToken(textTokenType, nil)
 		} else {
 			if interpIterationCount <= 20 {
-				fmt.Printf("[DEBUG] _consumeWithInterpolation: _attemptStr(INTERPOLATION.start) did NOT match, peek=%d ('%c'), consuming as text\n",
+				fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: _attemptStr(INTERPOLATION.start) did NOT match, peek=%d ('%c'), consuming as text\n",
 					t.cursor.Peek(), t.cursor.Peek())
 			}
 			if t.cursor.Peek() == core.CharAMPERSAND {
@@ -2367,7 +2361,7 @@ func (t *Tokenizer) _consumeWithInterpolation(textTokenType TokenType, interpola
 				char := t._readChar()
 				parts = append(parts, char)
 				if interpIterationCount <= 20 {
-					fmt.Printf("[DEBUG] _consumeWithInterpolation: consumed char='%s' as text, parts=%v\n", char, parts)
+					fmt.Fprintf(os.Stderr, "[DEBUG] _consumeWithInterpolation: consumed char='%s' as text, parts=%v\n", char, parts)
 				}
 			}
 		}
@@ -2387,32 +2381,32 @@ func (t *Tokenizer) _isTextEnd() bool {
 		// checking _isInExpansionCase()
 		if t._isExpansionFormStart() {
 			// start of an expansion form (including nested expansion forms)
-			fmt.Printf("[DEBUG] _isTextEnd: found expansion form start, returning true\n")
+			fmt.Fprintf(os.Stderr, "[DEBUG] _isTextEnd: found expansion form start, returning true\n")
 			return true
 		}
 
 		if t._isExpansionCaseStart() {
 			// start of an expansion case
-			fmt.Printf("[DEBUG] _isTextEnd: found expansion case start, returning true\n")
+			fmt.Fprintf(os.Stderr, "[DEBUG] _isTextEnd: found expansion case start, returning true\n")
 			return true
 		}
 
 		if t.cursor.Peek() == core.CharRBRACE {
 			isInCase := t._isInExpansionCase()
 			isInForm := t._isInExpansionForm()
-			fmt.Printf("[DEBUG] _isTextEnd: found '}', isInExpansionCase=%v, isInExpansionForm=%v, stackLen=%d\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] _isTextEnd: found '}', isInExpansionCase=%v, isInExpansionForm=%v, stackLen=%d\n",
 				isInCase, isInForm, len(t.expansionCaseStack))
 			if len(t.expansionCaseStack) > 0 {
-				fmt.Printf("[DEBUG] _isTextEnd: stack contents: %v\n", t.expansionCaseStack)
+				fmt.Fprintf(os.Stderr, "[DEBUG] _isTextEnd: stack contents: %v\n", t.expansionCaseStack)
 			}
 			if isInCase {
 				// end of an expansion case
-				fmt.Printf("[DEBUG] _isTextEnd: found expansion case end, returning true\n")
+				fmt.Fprintf(os.Stderr, "[DEBUG] _isTextEnd: found expansion case end, returning true\n")
 				return true
 			}
 			if isInForm {
 				// end of an expansion form
-				fmt.Printf("[DEBUG] _isTextEnd: found expansion form end, returning true\n")
+				fmt.Fprintf(os.Stderr, "[DEBUG] _isTextEnd: found expansion form end, returning true\n")
 				return true
 			}
 		}
@@ -2501,7 +2495,7 @@ func (t *Tokenizer) _consumeLetDeclarationValue() {
 		}
 		char := t.cursor.Peek()
 		if iteration <= 20 {
-			fmt.Printf("[DEBUG] _consumeLetDeclarationValue: iteration=%d, char=%d ('%c')\n", iteration, char, func() rune {
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumeLetDeclarationValue: iteration=%d, char=%d ('%c')\n", iteration, char, func() rune {
 				if char >= 32 && char < 127 {
 					return rune(char)
 				}
@@ -2511,14 +2505,14 @@ func (t *Tokenizer) _consumeLetDeclarationValue() {
 
 		// `@let` declarations terminate with a semicolon.
 		if char == core.CharSEMICOLON {
-			fmt.Printf("[DEBUG] _consumeLetDeclarationValue: found semicolon, breaking\n")
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumeLetDeclarationValue: found semicolon, breaking\n")
 			break
 		}
 
 		// If we hit a quote, skip over its content since we don't care what's inside.
 		if core.IsQuote(char) {
 			if iteration <= 20 {
-				fmt.Printf("[DEBUG] _consumeLetDeclarationValue: found quote %d ('%c'), skipping content\n", char, rune(char))
+				fmt.Fprintf(os.Stderr, "[DEBUG] _consumeLetDeclarationValue: found quote %d ('%c'), skipping content\n", char, rune(char))
 			}
 			t.cursor.Advance() // Skip opening quote
 			t._attemptCharCodeUntilFn(func(inner int) bool {
@@ -2528,7 +2522,7 @@ func (t *Tokenizer) _consumeLetDeclarationValue() {
 				}
 				if inner == char {
 					if iteration <= 20 {
-						fmt.Printf("[DEBUG] _consumeLetDeclarationValue: found closing quote %d ('%c'), stopping\n", inner, rune(inner))
+						fmt.Fprintf(os.Stderr, "[DEBUG] _consumeLetDeclarationValue: found closing quote %d ('%c'), stopping\n", inner, rune(inner))
 					}
 				}
 				return inner == char // Found closing quote
@@ -2536,7 +2530,7 @@ func (t *Tokenizer) _consumeLetDeclarationValue() {
 			// Advance past the closing quote (matches TypeScript: this._cursor.advance() at line 465)
 			peekAfterAttempt := t.cursor.Peek()
 			if iteration <= 20 {
-				fmt.Printf("[DEBUG] _consumeLetDeclarationValue: after _attemptCharCodeUntilFn, peek=%d ('%c')\n", peekAfterAttempt, func() rune {
+				fmt.Fprintf(os.Stderr, "[DEBUG] _consumeLetDeclarationValue: after _attemptCharCodeUntilFn, peek=%d ('%c')\n", peekAfterAttempt, func() rune {
 					if peekAfterAttempt >= 32 && peekAfterAttempt < 127 {
 						return rune(peekAfterAttempt)
 					}
@@ -2545,7 +2539,7 @@ func (t *Tokenizer) _consumeLetDeclarationValue() {
 			}
 			t.cursor.Advance()
 			if iteration <= 20 {
-				fmt.Printf("[DEBUG] _consumeLetDeclarationValue: after advancing past closing quote, peek=%d ('%c')\n", t.cursor.Peek(), func() rune {
+				fmt.Fprintf(os.Stderr, "[DEBUG] _consumeLetDeclarationValue: after advancing past closing quote, peek=%d ('%c')\n", t.cursor.Peek(), func() rune {
 					peek := t.cursor.Peek()
 					if peek >= 32 && peek < 127 {
 						return rune(peek)
@@ -2560,15 +2554,15 @@ func (t *Tokenizer) _consumeLetDeclarationValue() {
 	}
 
 	valueContent := t.cursor.GetChars(start)
-	fmt.Printf("[DEBUG] _consumeLetDeclarationValue: END, valueContent=%q, length=%d\n", valueContent, len(valueContent))
+	fmt.Fprintf(os.Stderr, "[DEBUG] _consumeLetDeclarationValue: END, valueContent=%q, length=%d\n", valueContent, len(valueContent))
 	// Debug: print each character
-	fmt.Printf("[DEBUG] _consumeLetDeclarationValue: valueContent bytes: ")
+	fmt.Fprintf(os.Stderr, "[DEBUG] _consumeLetDeclarationValue: valueContent bytes: ")
 	for i, b := range []byte(valueContent) {
 		if i < 50 {
-			fmt.Printf("%d ", b)
+			fmt.Fprintf(os.Stderr, "%d ", b)
 		}
 	}
-	fmt.Printf("\n")
+	fmt.Fprintf(os.Stderr, "\n")
 	t._endToken([]string{valueContent}, nil)
 }
 
@@ -2747,11 +2741,11 @@ func (t *Tokenizer) _attemptCharCodeUntilFn(predicate func(code int) bool) {
 	for !predicate(t.cursor.Peek()) {
 		peek := t.cursor.Peek()
 		if iteration < 50 && (peek == core.CharLF || peek == core.CharCR) {
-			fmt.Printf("[DEBUG] _attemptCharCodeUntilFn: iteration=%d, peek=%d (newline), predicate returned false, advancing\n", iteration, peek)
+			fmt.Fprintf(os.Stderr, "[DEBUG] _attemptCharCodeUntilFn: iteration=%d, peek=%d (newline), predicate returned false, advancing\n", iteration, peek)
 		}
 		// Debug: log every char when iteration < 50
 		if iteration < 50 {
-			fmt.Printf("[DEBUG] _attemptCharCodeUntilFn: iteration=%d, peek=%d ('%c'), predicate returned false, advancing\n", iteration, peek, func() rune {
+			fmt.Fprintf(os.Stderr, "[DEBUG] _attemptCharCodeUntilFn: iteration=%d, peek=%d ('%c'), predicate returned false, advancing\n", iteration, peek, func() rune {
 				if peek >= 32 && peek < 127 {
 					return rune(peek)
 				}
@@ -2766,7 +2760,7 @@ func (t *Tokenizer) _attemptCharCodeUntilFn(predicate func(code int) bool) {
 	}
 	peek := t.cursor.Peek()
 	if peek == core.CharLF || peek == core.CharCR {
-		fmt.Printf("[DEBUG] _attemptCharCodeUntilFn: stopped at newline, peek=%d, predicate returned true\n", peek)
+		fmt.Fprintf(os.Stderr, "[DEBUG] _attemptCharCodeUntilFn: stopped at newline, peek=%d, predicate returned true\n", peek)
 	}
 }
 
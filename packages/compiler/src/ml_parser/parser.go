@@ -3,6 +3,7 @@ package ml_parser
 import (
 	"fmt"
 	"ngc-go/packages/compiler/src/util"
+	"os"
 	"strings"
 )
 
@@ -85,13 +86,13 @@ func NewParser(getTagDefinition func(tagName string) TagDefinition) *Parser {
 
 // Parse parses source code into a ParseTreeResult
 func (p *Parser) Parse(source, url string, options *TokenizeOptions) *ParseTreeResult {
-	fmt.Printf("[DEBUG] Parse: START, source length=%d\n", len(source))
+	fmt.Fprintf(os.Stderr, "[DEBUG] Parse: START, source length=%d\n", len(source))
 	tokenizeResult := Tokenize(source, url, p.GetTagDefinition, options)
-	fmt.Printf("[DEBUG] Parse: Tokenize done, tokens count=%d, errors count=%d\n", len(tokenizeResult.Tokens), len(tokenizeResult.Errors))
+	fmt.Fprintf(os.Stderr, "[DEBUG] Parse: Tokenize done, tokens count=%d, errors count=%d\n", len(tokenizeResult.Tokens), len(tokenizeResult.Errors))
 	treeBuilder := NewTreeBuilder(tokenizeResult.Tokens, p.GetTagDefinition)
-	fmt.Printf("[DEBUG] Parse: TreeBuilder created, calling Build()\n")
+	fmt.Fprintf(os.Stderr, "[DEBUG] Parse: TreeBuilder created, calling Build()\n")
 	treeBuilder.Build()
-	fmt.Printf("[DEBUG] Parse: Build() done\n")
+	fmt.Fprintf(os.Stderr, "[DEBUG] Parse: Build() done\n")
 
 	// Combine errors from tokenization and tree building
 	allErrors := tokenizeResult.Errors
@@ -148,29 +149,29 @@ func NewTreeBuilder(tokens []Token, tagDefinitionResolver func(tagName string) T
 
 // Build builds the tree from tokens
 func (tb *TreeBuilder) Build() {
-	fmt.Printf("[DEBUG] Build: START, totalTokens=%d, index=%d\n", len(tb.tokens), tb.index)
+	fmt.Fprintf(os.Stderr, "[DEBUG] Build: START, totalTokens=%d, index=%d\n", len(tb.tokens), tb.index)
 	// Debug: print all tokens
 	if len(tb.tokens) <= 20 {
-		fmt.Printf("[DEBUG] Build: all tokens:\n")
+		fmt.Fprintf(os.Stderr, "[DEBUG] Build: all tokens:\n")
 		for i, token := range tb.tokens {
 			tokenType := token.Type()
 			parts := token.Parts()
 			if len(parts) > 0 && len(parts[0]) > 50 {
 				parts = []string{parts[0][:50] + "..."}
 			}
-			fmt.Printf("[DEBUG] Build: token[%d]: type=%d, parts=%v\n", i, tokenType, parts)
+			fmt.Fprintf(os.Stderr, "[DEBUG] Build: token[%d]: type=%d, parts=%v\n", i, tokenType, parts)
 		}
 	}
 	if tb.peek != nil {
-		fmt.Printf("[DEBUG] Build: initial peek.Type()=%d\n", tb.peek.Type())
+		fmt.Fprintf(os.Stderr, "[DEBUG] Build: initial peek.Type()=%d\n", tb.peek.Type())
 	} else {
-		fmt.Printf("[DEBUG] Build: initial peek is nil\n")
+		fmt.Fprintf(os.Stderr, "[DEBUG] Build: initial peek is nil\n")
 	}
 	buildIterationCount := 0
 	for tb.peek != nil && tb.peek.Type() != TokenTypeEOF {
 		buildIterationCount++
 		if buildIterationCount > 1000 {
-			fmt.Printf("[DEBUG] Build: INFINITE LOOP DETECTED! iterationCount=%d, peek.Type()=%d, index=%d, totalTokens=%d\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] Build: INFINITE LOOP DETECTED! iterationCount=%d, peek.Type()=%d, index=%d, totalTokens=%d\n",
 				buildIterationCount, func() int {
 					if tb.peek == nil {
 						return -1
@@ -180,7 +181,7 @@ func (tb *TreeBuilder) Build() {
 			break
 		}
 		if buildIterationCount <= 20 || buildIterationCount%100 == 0 {
-			fmt.Printf("[DEBUG] Build: iteration=%d, peek.Type()=%d, index=%d, totalTokens=%d\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] Build: iteration=%d, peek.Type()=%d, index=%d, totalTokens=%d\n",
 				buildIterationCount, func() int {
 					if tb.peek == nil {
 						return -1
@@ -200,7 +201,7 @@ func (tb *TreeBuilder) Build() {
 			if len(parts) > 1 {
 				name = strings.TrimSpace(parts[1])
 			}
-			fmt.Printf("[DEBUG] Build: found TAG_OPEN_START, index=%d, totalTokens=%d, parts=%v, prefix=%q, name=%q\n", tb.index, len(tb.tokens), parts, prefix, name)
+			fmt.Fprintf(os.Stderr, "[DEBUG] Build: found TAG_OPEN_START, index=%d, totalTokens=%d, parts=%v, prefix=%q, name=%q\n", tb.index, len(tb.tokens), parts, prefix, name)
 			// Try to get the underlying TagOpenStartToken
 			// If token is TokenBase, we need to reconstruct it
 			var startTag *TagOpenStartToken
@@ -211,12 +212,12 @@ func (tb *TreeBuilder) Build() {
 				startTag = NewTagOpenStartToken(prefix, name, baseToken.SourceSpan())
 			}
 			if startTag != nil {
-				fmt.Printf("[DEBUG] Build: calling _consumeStartTag, prefix=%q, name=%q\n", prefix, name)
+				fmt.Fprintf(os.Stderr, "[DEBUG] Build: calling _consumeStartTag, prefix=%q, name=%q\n", prefix, name)
 				tb._consumeStartTag(startTag)
 			} else {
 				// This case should ideally not happen if tokenization is correct
 				// but keeping the error for robustness.
-				fmt.Printf("[DEBUG] Build: ERROR - cannot create TagOpenStartToken from token type %T\n", token)
+				fmt.Fprintf(os.Stderr, "[DEBUG] Build: ERROR - cannot create TagOpenStartToken from token type %T\n", token)
 				tb.errors = append(tb.errors, NewTreeError(
 					nil,
 					token.SourceSpan(),
@@ -254,7 +255,7 @@ func (tb *TreeBuilder) Build() {
 			parts := token.Parts()
 			if len(parts) > 0 {
 				content := strings.Join(parts, "")
-				fmt.Printf("[DEBUG] Build: INTERPOLATION token (standalone), parts=%v, content=%q\n", parts, content)
+				fmt.Fprintf(os.Stderr, "[DEBUG] Build: INTERPOLATION token (standalone), parts=%v, content=%q\n", parts, content)
 				var tokens []InterpolatedTextToken
 				if interpToken, ok := token.(InterpolatedTextToken); ok {
 					tokens = append(tokens, interpToken)
@@ -266,27 +267,27 @@ func (tb *TreeBuilder) Build() {
 			tb._consumeDocType(tb.advance())
 		case TokenTypeEXPANSION_FORM_START:
 			tb._closeVoidElement()
-			fmt.Printf("[DEBUG] Build: found EXPANSION_FORM_START, index=%d, totalTokens=%d\n", tb.index, len(tb.tokens))
+			fmt.Fprintf(os.Stderr, "[DEBUG] Build: found EXPANSION_FORM_START, index=%d, totalTokens=%d\n", tb.index, len(tb.tokens))
 			tb._consumeExpansion(tb.advance())
 		case TokenTypeBLOCK_OPEN_START:
 			tb._closeVoidElement()
-			fmt.Printf("[DEBUG] Build: found BLOCK_OPEN_START, index=%d, totalTokens=%d\n", tb.index, len(tb.tokens))
+			fmt.Fprintf(os.Stderr, "[DEBUG] Build: found BLOCK_OPEN_START, index=%d, totalTokens=%d\n", tb.index, len(tb.tokens))
 			tb._consumeBlockOpen(tb.advance())
 		case TokenTypeBLOCK_CLOSE:
 			tb._closeVoidElement()
-			fmt.Printf("[DEBUG] Build: found BLOCK_CLOSE, index=%d, totalTokens=%d, inExpansionContext=%v\n", tb.index, len(tb.tokens), tb.inExpansionContext)
+			fmt.Fprintf(os.Stderr, "[DEBUG] Build: found BLOCK_CLOSE, index=%d, totalTokens=%d, inExpansionContext=%v\n", tb.index, len(tb.tokens), tb.inExpansionContext)
 			// In expansion context, BLOCK_CLOSE tokens should be ignored
 			// They were likely meant to be EXPANSION_FORM_END or EXPANSION_CASE_EXP_END
 			// but were tokenized as BLOCK_CLOSE due to lexer ordering
 			if tb.inExpansionContext {
-				fmt.Printf("[DEBUG] Build: skipping BLOCK_CLOSE in expansion context\n")
+				fmt.Fprintf(os.Stderr, "[DEBUG] Build: skipping BLOCK_CLOSE in expansion context\n")
 				tb.advance()
 			} else {
 				tb._consumeBlockClose(tb.advance())
 			}
 		case TokenTypeINCOMPLETE_BLOCK_OPEN:
 			tb._closeVoidElement()
-			fmt.Printf("[DEBUG] Build: found INCOMPLETE_BLOCK_OPEN, index=%d, totalTokens=%d\n", tb.index, len(tb.tokens))
+			fmt.Fprintf(os.Stderr, "[DEBUG] Build: found INCOMPLETE_BLOCK_OPEN, index=%d, totalTokens=%d\n", tb.index, len(tb.tokens))
 			tb._consumeIncompleteBlock(tb.advance())
 		case TokenTypeLET_START:
 			tb._closeVoidElement()
@@ -303,13 +304,13 @@ func (tb *TreeBuilder) Build() {
 		case TokenTypeATTR_VALUE_TEXT, TokenTypeATTR_VALUE_INTERPOLATION, TokenTypeATTR_QUOTE:
 			// These tokens should only appear within attribute context, but if they appear
 			// at top level (e.g., due to premature tag start in attribute value), skip them
-			fmt.Printf("[DEBUG] Build: found attribute token at top level, skipping: type=%d, index=%d\n", tb.peek.Type(), tb.index)
+			fmt.Fprintf(os.Stderr, "[DEBUG] Build: found attribute token at top level, skipping: type=%d, index=%d\n", tb.peek.Type(), tb.index)
 			tb.advance()
 		case TokenTypeEXPANSION_CASE_EXP_END, TokenTypeEXPANSION_FORM_END:
 			// These tokens are delimiters for nested expansion forms within expansion case expressions
 			// Skip them (matches TypeScript behavior where unmatched tokens are skipped)
 			if buildIterationCount <= 10 {
-				fmt.Printf("[DEBUG] Build: skipping expansion delimiter token, type=%d, index=%d\n", tb.peek.Type(), tb.index)
+				fmt.Fprintf(os.Stderr, "[DEBUG] Build: skipping expansion delimiter token, type=%d, index=%d\n", tb.peek.Type(), tb.index)
 			}
 			tb.advance()
 		default:
@@ -341,7 +342,7 @@ func (tb *TreeBuilder) _consumeStartTag(startTag *TagOpenStartToken) {
 	tagDef := tb._getTagDefinition(fullName)
 	isSelfClosing := false
 
-	fmt.Printf("[DEBUG] _consumeStartTag: START, fullName=%q, peek.Type()=%d (TAG_OPEN_END_VOID=%d), index=%d, totalTokens=%d\n",
+	fmt.Fprintf(os.Stderr, "[DEBUG] _consumeStartTag: START, fullName=%q, peek.Type()=%d (TAG_OPEN_END_VOID=%d), index=%d, totalTokens=%d\n",
 		fullName, func() int {
 			if tb.peek == nil {
 				return -1
@@ -363,7 +364,7 @@ func (tb *TreeBuilder) _consumeStartTag(startTag *TagOpenStartToken) {
 	if tb.peek != nil && tb.peek.Type() == TokenTypeTAG_OPEN_END_VOID {
 		tb.advance()
 		isSelfClosing = true
-		fmt.Printf("[DEBUG] _consumeStartTag: found TAG_OPEN_END_VOID, fullName=%q, tagDef=%v, CanSelfClose=%v, IsVoid=%v, GetNsPrefix=%v, isComponent=%v\n",
+		fmt.Fprintf(os.Stderr, "[DEBUG] _consumeStartTag: found TAG_OPEN_END_VOID, fullName=%q, tagDef=%v, CanSelfClose=%v, IsVoid=%v, GetNsPrefix=%v, isComponent=%v\n",
 			fullName, tagDef != nil, func() bool {
 				if tagDef != nil {
 					return tagDef.CanSelfClose()
@@ -387,13 +388,13 @@ func (tb *TreeBuilder) _consumeStartTag(startTag *TagOpenStartToken) {
 		// Match TypeScript logic exactly
 		if !(canSelfClose || hasNamespacePrefix || isVoid) {
 			errMsg := fmt.Sprintf("Only void, custom and foreign elements can be self closed \"%s\"", startTag.Parts()[1])
-			fmt.Printf("[DEBUG] _consumeStartTag: adding error: %q\n", errMsg)
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumeStartTag: adding error: %q\n", errMsg)
 			tb.errors = append(tb.errors, NewTreeError(
 				&fullName,
 				startTag.SourceSpan(),
 				errMsg,
 			))
-			fmt.Printf("[DEBUG] _consumeStartTag: after adding error, len(errors)=%d\n", len(tb.errors))
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumeStartTag: after adding error, len(errors)=%d\n", len(tb.errors))
 		}
 	} else if tb.peek != nil && tb.peek.Type() == TokenTypeTAG_OPEN_END {
 		tb.advance()
@@ -520,7 +521,7 @@ func (tb *TreeBuilder) _consumeAttributesAndDirectives(attributesResult *[]*Attr
 	iterationCount := 0
 	for tb.peek != nil && (tb.peek.Type() == TokenTypeATTR_NAME || tb.peek.Type() == TokenTypeDIRECTIVE_NAME) {
 		iterationCount++
-		fmt.Printf("[DEBUG] _consumeAttributesAndDirectives: iteration=%d, peek.Type()=%d, index=%d\n", iterationCount, func() int {
+		fmt.Fprintf(os.Stderr, "[DEBUG] _consumeAttributesAndDirectives: iteration=%d, peek.Type()=%d, index=%d\n", iterationCount, func() int {
 			if tb.peek == nil {
 				return -1
 			}
@@ -531,10 +532,10 @@ func (tb *TreeBuilder) _consumeAttributesAndDirectives(attributesResult *[]*Attr
 			break
 		}
 		if tb.peek.Type() == TokenTypeDIRECTIVE_NAME {
-			fmt.Printf("[DEBUG] _consumeAttributesAndDirectives: consuming directive, peek.Type()=%d, index=%d\n", tb.peek.Type(), tb.index)
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumeAttributesAndDirectives: consuming directive, peek.Type()=%d, index=%d\n", tb.peek.Type(), tb.index)
 			directive := tb._consumeDirective(tb.peek)
 			*directivesResult = append(*directivesResult, directive)
-			fmt.Printf("[DEBUG] _consumeAttributesAndDirectives: after consuming directive, peek.Type()=%d, index=%d\n", func() int {
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumeAttributesAndDirectives: after consuming directive, peek.Type()=%d, index=%d\n", func() int {
 				if tb.peek == nil {
 					return -1
 				}
@@ -545,18 +546,18 @@ func (tb *TreeBuilder) _consumeAttributesAndDirectives(attributesResult *[]*Attr
 		} else {
 			attrNameToken := tb.advance().(*AttributeNameToken)
 			attrParts := attrNameToken.Parts()
-			fmt.Printf("[DEBUG] _consumeAttributesAndDirectives: consuming attr, name parts=%v\n", attrParts)
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumeAttributesAndDirectives: consuming attr, name parts=%v\n", attrParts)
 			peekBeforeConsume := tb.peek
-			fmt.Printf("[DEBUG] _consumeAttributesAndDirectives: before consuming attr, peek.Type()=%d, index=%d\n", func() int {
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumeAttributesAndDirectives: before consuming attr, peek.Type()=%d, index=%d\n", func() int {
 				if peekBeforeConsume == nil {
 					return -1
 				}
 				return int(peekBeforeConsume.Type())
 			}(), tb.index)
 			attr := tb._consumeAttr(attrNameToken)
-			fmt.Printf("[DEBUG] _consumeAttributesAndDirectives: adding attr.Name=%q, attr.Value=%q\n", attr.Name, attr.Value)
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumeAttributesAndDirectives: adding attr.Name=%q, attr.Value=%q\n", attr.Name, attr.Value)
 			*attributesResult = append(*attributesResult, attr)
-			fmt.Printf("[DEBUG] _consumeAttributesAndDirectives: after consuming attr, peek.Type()=%d, index=%d, peek==peekBeforeConsume=%v\n", func() int {
+			fmt.Fprintf(os.Stderr, "[DEBUG] _consumeAttributesAndDirectives: after consuming attr, peek.Type()=%d, index=%d, peek==peekBeforeConsume=%v\n", func() int {
 				if tb.peek == nil {
 					return -1
 				}
@@ -567,7 +568,7 @@ func (tb *TreeBuilder) _consumeAttributesAndDirectives(attributesResult *[]*Attr
 			// The loop will naturally continue to process the next ATTR_NAME or DIRECTIVE_NAME token
 			// No action needed - just let the loop continue
 			if tb.peek != nil && tb.peek == peekBeforeConsume {
-				fmt.Printf("[DEBUG] _consumeAttributesAndDirectives: peek unchanged after consuming attr (attr without value), continuing loop\n")
+				fmt.Fprintf(os.Stderr, "[DEBUG] _consumeAttributesAndDirectives: peek unchanged after consuming attr (attr without value), continuing loop\n")
 				// Loop will continue and process the next token if it's ATTR_NAME or DIRECTIVE_NAME
 			}
 		}
@@ -751,11 +752,16 @@ func (tb *TreeBuilder) _consumeIncompleteStartTag(startTag *TagOpenStartToken) {
 		if attrPrefix != "" {
 			fullName = attrPrefix + ":" + attrName
 		}
+		// Attributes without value end with their name.
+		attrEnd := attrNameToken.SourceSpan().End
+		if valueSpan != nil {
+			attrEnd = valueSpan.End
+		}
 
 		attrs = append(attrs, NewAttribute(
 			fullName,
 			attrValue,
-			util.NewParseSourceSpan(attrNameToken.SourceSpan().Start, valueSpan.End, attrNameToken.SourceSpan().Start, nil),
+			util.NewParseSourceSpan(attrNameToken.SourceSpan().Start, attrEnd, attrNameToken.SourceSpan().Start, nil),
 			attrNameToken.SourceSpan(),
 			valueSpan,
 			valueTokens,
@@ -934,7 +940,7 @@ func (tb *TreeBuilder) _consumeText(token *TextToken) {
 			parts := token.Parts()
 			if len(parts) > 0 {
 				joined := strings.Join(parts, "")
-				fmt.Printf("[DEBUG] _consumeText: INTERPOLATION token, parts=%v, joined=%q\n", parts, joined)
+				fmt.Fprintf(os.Stderr, "[DEBUG] _consumeText: INTERPOLATION token, parts=%v, joined=%q\n", parts, joined)
 				text += joined
 			}
 		} else {
@@ -1360,7 +1366,7 @@ func (tb *TreeBuilder) _consumeExpansion(token Token) {
 }
 
 func (tb *TreeBuilder) _parseExpansionCase() *ExpansionCase {
-	fmt.Printf("[DEBUG] _parseExpansionCase: START, index=%d, peek.Type()=%d\n", tb.index, func() int {
+	fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: START, index=%d, peek.Type()=%d\n", tb.index, func() int {
 		if tb.peek == nil {
 			return -1
 		}
@@ -1371,32 +1377,32 @@ func (tb *TreeBuilder) _parseExpansionCase() *ExpansionCase {
 	if parts := valueToken.Parts(); len(parts) > 0 {
 		value = parts[0]
 	}
-	fmt.Printf("[DEBUG] _parseExpansionCase: value=%s, index=%d\n", value, tb.index)
+	fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: value=%s, index=%d\n", value, tb.index)
 
 	// Read {
 	if tb.peek == nil || tb.peek.Type() != TokenTypeEXPANSION_CASE_EXP_START {
 		if tb.peek != nil {
-			fmt.Printf("[DEBUG] _parseExpansionCase: ERROR - expected EXPANSION_CASE_EXP_START but got type=%d\n", tb.peek.Type())
+			fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: ERROR - expected EXPANSION_CASE_EXP_START but got type=%d\n", tb.peek.Type())
 			tb.errors = append(tb.errors, NewTreeError(
 				nil,
 				tb.peek.SourceSpan(),
 				"Invalid ICU message. Missing '{'.",
 			))
 		} else {
-			fmt.Printf("[DEBUG] _parseExpansionCase: ERROR - peek is nil\n")
+			fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: ERROR - peek is nil\n")
 		}
 		return nil
 	}
 
 	startToken := tb.advance()
-	fmt.Printf("[DEBUG] _parseExpansionCase: got EXPANSION_CASE_EXP_START, calling _collectExpansionExpTokens, index=%d\n", tb.index)
+	fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: got EXPANSION_CASE_EXP_START, calling _collectExpansionExpTokens, index=%d\n", tb.index)
 
 	exp := tb._collectExpansionExpTokens(startToken)
 	if exp == nil {
-		fmt.Printf("[DEBUG] _parseExpansionCase: _collectExpansionExpTokens returned nil\n")
+		fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: _collectExpansionExpTokens returned nil\n")
 		return nil
 	}
-	fmt.Printf("[DEBUG] _parseExpansionCase: _collectExpansionExpTokens returned %d tokens, index=%d\n", len(exp), tb.index)
+	fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: _collectExpansionExpTokens returned %d tokens, index=%d\n", len(exp), tb.index)
 
 	// Get the end token - _collectExpansionExpTokens has already advanced past the closing token
 	// Check what token we're at now
@@ -1404,7 +1410,7 @@ func (tb *TreeBuilder) _parseExpansionCase() *ExpansionCase {
 	if tb.peek != nil {
 		// If the next token is BLOCK_CLOSE, it was the closing token that _collectExpansionExpTokens advanced past
 		if tb.peek.Type() == TokenTypeBLOCK_CLOSE {
-			fmt.Printf("[DEBUG] _parseExpansionCase: next token is BLOCK_CLOSE (was advanced past by _collectExpansionExpTokens), treating as EXPANSION_CASE_EXP_END\n")
+			fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: next token is BLOCK_CLOSE (was advanced past by _collectExpansionExpTokens), treating as EXPANSION_CASE_EXP_END\n")
 			endToken = tb.advance()
 			// Create a synthetic EXPANSION_CASE_EXP_END token
 			endToken = NewTokenBase(TokenTypeEXPANSION_CASE_EXP_END, []string{}, endToken.SourceSpan())
@@ -1413,14 +1419,14 @@ func (tb *TreeBuilder) _parseExpansionCase() *ExpansionCase {
 		} else {
 			// Unexpected token - might be TEXT or something else
 			// This shouldn't happen, but handle it gracefully
-			fmt.Printf("[DEBUG] _parseExpansionCase: unexpected token after _collectExpansionExpTokens, type=%d, treating as end\n", tb.peek.Type())
+			fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: unexpected token after _collectExpansionExpTokens, type=%d, treating as end\n", tb.peek.Type())
 			endToken = tb.advance()
 			// Create a synthetic EXPANSION_CASE_EXP_END token
 			endToken = NewTokenBase(TokenTypeEXPANSION_CASE_EXP_END, []string{}, endToken.SourceSpan())
 		}
 	} else {
 		// No more tokens - create a synthetic end token
-		fmt.Printf("[DEBUG] _parseExpansionCase: no more tokens, creating synthetic end token\n")
+		fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: no more tokens, creating synthetic end token\n")
 		if len(exp) > 0 {
 			lastToken := exp[len(exp)-1]
 			endToken = NewTokenBase(TokenTypeEXPANSION_CASE_EXP_END, []string{}, lastToken.SourceSpan())
@@ -1428,7 +1434,7 @@ func (tb *TreeBuilder) _parseExpansionCase() *ExpansionCase {
 			endToken = NewTokenBase(TokenTypeEXPANSION_CASE_EXP_END, []string{}, startToken.SourceSpan())
 		}
 	}
-	fmt.Printf("[DEBUG] _parseExpansionCase: got endToken, type=%d, index=%d\n", endToken.Type(), tb.index)
+	fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: got endToken, type=%d, index=%d\n", endToken.Type(), tb.index)
 	// Add EOF token to the end
 	eofToken := NewTokenBase(TokenTypeEOF, []string{}, endToken.SourceSpan())
 	exp = append(exp, eofToken)
@@ -1440,24 +1446,24 @@ func (tb *TreeBuilder) _parseExpansionCase() *ExpansionCase {
 		if token.Type() != TokenTypeBLOCK_CLOSE {
 			filteredExp = append(filteredExp, token)
 		} else {
-			fmt.Printf("[DEBUG] _parseExpansionCase: filtering out BLOCK_CLOSE token from exp\n")
+			fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: filtering out BLOCK_CLOSE token from exp\n")
 		}
 	}
 
 	// Parse everything in between { and }
-	fmt.Printf("[DEBUG] _parseExpansionCase: creating expansionCaseParser with %d tokens\n", len(filteredExp))
+	fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: creating expansionCaseParser with %d tokens\n", len(filteredExp))
 	for i, token := range filteredExp {
 		if i < 5 {
-			fmt.Printf("[DEBUG] _parseExpansionCase: filteredExp[%d]: type=%d, parts=%v\n", i, token.Type(), token.Parts())
+			fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: filteredExp[%d]: type=%d, parts=%v\n", i, token.Type(), token.Parts())
 		}
 	}
 	expansionCaseParser := NewTreeBuilder(filteredExp, tb.tagDefinitionResolver)
 	expansionCaseParser.inExpansionContext = true // Mark that we're parsing expansion case expression
 	expansionCaseParser.Build()
 	if len(expansionCaseParser.errors) > 0 {
-		fmt.Printf("[DEBUG] _parseExpansionCase: expansionCaseParser has %d errors\n", len(expansionCaseParser.errors))
+		fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: expansionCaseParser has %d errors\n", len(expansionCaseParser.errors))
 		for i, err := range expansionCaseParser.errors {
-			fmt.Printf("[DEBUG] _parseExpansionCase: error[%d]=%q, span=%v\n", i, err.String(), err.ParseError.Span)
+			fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: error[%d]=%q, span=%v\n", i, err.String(), err.ParseError.Span)
 		}
 		// In TypeScript, errors from expansionCaseParser are directly appended
 		// But we need to check for duplicates to avoid reporting the same error twice
@@ -1472,7 +1478,7 @@ func (tb *TreeBuilder) _parseExpansionCase() *ExpansionCase {
 					existingErr.ParseError.Span.Start.Line == expErr.ParseError.Span.Start.Line &&
 					existingErr.ParseError.Span.Start.Col == expErr.ParseError.Span.Start.Col {
 					isDuplicate = true
-					fmt.Printf("[DEBUG] _parseExpansionCase: skipping duplicate error at offset %d\n", expErr.ParseError.Span.Start.Offset)
+					fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: skipping duplicate error at offset %d\n", expErr.ParseError.Span.Start.Offset)
 					break
 				}
 			}
@@ -1482,7 +1488,7 @@ func (tb *TreeBuilder) _parseExpansionCase() *ExpansionCase {
 		}
 		return nil
 	}
-	fmt.Printf("[DEBUG] _parseExpansionCase: expansionCaseParser built successfully, rootNodes=%d\n", len(expansionCaseParser.rootNodes))
+	fmt.Fprintf(os.Stderr, "[DEBUG] _parseExpansionCase: expansionCaseParser built successfully, rootNodes=%d\n", len(expansionCaseParser.rootNodes))
 
 	sourceSpan := util.NewParseSourceSpan(
 		valueToken.SourceSpan().Start,
@@ -1515,7 +1521,7 @@ func (tb *TreeBuilder) _parseExpansionCase() *ExpansionCase {
 }
 
 func (tb *TreeBuilder) _collectExpansionExpTokens(start Token) []Token {
-	fmt.Printf("[DEBUG] _collectExpansionExpTokens: START, index=%d, totalTokens=%d\n", tb.index, len(tb.tokens))
+	fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: START, index=%d, totalTokens=%d\n", tb.index, len(tb.tokens))
 	exp := []Token{}
 	// Stack starts with EXPANSION_CASE_EXP_START for the outer case we're collecting
 	// We need to track both expansion forms and expansion cases to know when we're done
@@ -1526,7 +1532,7 @@ func (tb *TreeBuilder) _collectExpansionExpTokens(start Token) []Token {
 	for {
 		iterationCount++
 		if iterationCount > 1000 {
-			fmt.Printf("[DEBUG] _collectExpansionExpTokens: INFINITE LOOP DETECTED! iterationCount=%d, stackSize=%d, peek.Type()=%d, index=%d\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: INFINITE LOOP DETECTED! iterationCount=%d, stackSize=%d, peek.Type()=%d, index=%d\n",
 				iterationCount, len(expansionFormStack), func() int {
 					if tb.peek == nil {
 						return -1
@@ -1544,35 +1550,35 @@ func (tb *TreeBuilder) _collectExpansionExpTokens(start Token) []Token {
 		// Check if we've reached the end of tokens or EOF
 		if tb.peek == nil || tb.index >= len(tb.tokens) {
 			if tb.peek == nil {
-				fmt.Printf("[DEBUG] _collectExpansionExpTokens: peek is nil, returning error at start.SourceSpan()=%v\n", start.SourceSpan())
+				fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: peek is nil, returning error at start.SourceSpan()=%v\n", start.SourceSpan())
 			} else {
-				fmt.Printf("[DEBUG] _collectExpansionExpTokens: reached end of tokens, index=%d, totalTokens=%d, returning error at start.SourceSpan()=%v\n", tb.index, len(tb.tokens), start.SourceSpan())
+				fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: reached end of tokens, index=%d, totalTokens=%d, returning error at start.SourceSpan()=%v\n", tb.index, len(tb.tokens), start.SourceSpan())
 			}
 			err := NewTreeError(
 				nil,
 				start.SourceSpan(),
 				"Invalid ICU message. Missing '}'.",
 			)
-			fmt.Printf("[DEBUG] _collectExpansionExpTokens: creating error with span=%v\n", err.ParseError.Span)
+			fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: creating error with span=%v\n", err.ParseError.Span)
 			tb.errors = append(tb.errors, err)
 			return nil
 		}
 
 		peekType := tb.peek.Type()
 		if iterationCount <= 10 || iterationCount%50 == 0 {
-			fmt.Printf("[DEBUG] _collectExpansionExpTokens: iteration=%d, peek.Type()=%d, stackSize=%d, index=%d\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: iteration=%d, peek.Type()=%d, stackSize=%d, index=%d\n",
 				iterationCount, peekType, len(expansionFormStack), tb.index)
 		}
 
 		// Check for EOF early to avoid duplicate error (after checking peek != nil)
 		if peekType == TokenTypeEOF {
-			fmt.Printf("[DEBUG] _collectExpansionExpTokens: ERROR - reached EOF at start.SourceSpan()=%v\n", start.SourceSpan())
+			fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: ERROR - reached EOF at start.SourceSpan()=%v\n", start.SourceSpan())
 			err := NewTreeError(
 				nil,
 				start.SourceSpan(),
 				"Invalid ICU message. Missing '}'.",
 			)
-			fmt.Printf("[DEBUG] _collectExpansionExpTokens: creating EOF error with span=%v\n", err.ParseError.Span)
+			fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: creating EOF error with span=%v\n", err.ParseError.Span)
 			tb.errors = append(tb.errors, err)
 			return nil
 		}
@@ -1583,7 +1589,7 @@ func (tb *TreeBuilder) _collectExpansionExpTokens(start Token) []Token {
 		if peekType == TokenTypeBLOCK_CLOSE {
 			if lastOnStack(expansionFormStack, TokenTypeEXPANSION_FORM_START) {
 				// This BLOCK_CLOSE is actually closing a nested expansion form
-				fmt.Printf("[DEBUG] _collectExpansionExpTokens: treating BLOCK_CLOSE as EXPANSION_FORM_END (nested), index=%d\n", tb.index)
+				fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: treating BLOCK_CLOSE as EXPANSION_FORM_END (nested), index=%d\n", tb.index)
 				expansionFormStack = expansionFormStack[:len(expansionFormStack)-1]
 				// Don't return - this was a nested expansion form, continue collecting
 				// But don't collect the BLOCK_CLOSE token itself
@@ -1592,10 +1598,10 @@ func (tb *TreeBuilder) _collectExpansionExpTokens(start Token) []Token {
 			} else if lastOnStack(expansionFormStack, TokenTypeEXPANSION_CASE_EXP_START) {
 				// This BLOCK_CLOSE is actually closing the outer expansion case
 				// This is the end of the expression we're collecting
-				fmt.Printf("[DEBUG] _collectExpansionExpTokens: treating BLOCK_CLOSE as outer case end, index=%d\n", tb.index)
+				fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: treating BLOCK_CLOSE as outer case end, index=%d\n", tb.index)
 				expansionFormStack = expansionFormStack[:len(expansionFormStack)-1]
 				if len(expansionFormStack) == 0 {
-					fmt.Printf("[DEBUG] _collectExpansionExpTokens: stack empty after BLOCK_CLOSE, returning %d tokens\n", len(exp))
+					fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: stack empty after BLOCK_CLOSE, returning %d tokens\n", len(exp))
 					// Advance past the BLOCK_CLOSE token before returning
 					// The caller will expect the next token to be the closing token
 					tb.advance()
@@ -1607,14 +1613,14 @@ func (tb *TreeBuilder) _collectExpansionExpTokens(start Token) []Token {
 			} else if len(expansionFormStack) == 0 {
 				// Stack is empty and we encounter BLOCK_CLOSE - this is the closing } of the outer expansion case
 				// This is the end of the expression we're collecting
-				fmt.Printf("[DEBUG] _collectExpansionExpTokens: stack empty, BLOCK_CLOSE is outer case end, returning %d tokens\n", len(exp))
+				fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: stack empty, BLOCK_CLOSE is outer case end, returning %d tokens\n", len(exp))
 				// Don't advance - let _parseExpansionCase handle the BLOCK_CLOSE token
 				// This way _parseExpansionCase can see it and treat it as EXPANSION_CASE_EXP_END
 				return exp
 			} else {
 				// Unexpected BLOCK_CLOSE - this might be the closing } of the expansion case
 				// Don't collect it, just skip and let the caller handle it
-				fmt.Printf("[DEBUG] _collectExpansionExpTokens: unexpected BLOCK_CLOSE, might be case end, skipping, index=%d\n", tb.index)
+				fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: unexpected BLOCK_CLOSE, might be case end, skipping, index=%d\n", tb.index)
 				tb.advance()
 				continue
 			}
@@ -1625,12 +1631,12 @@ func (tb *TreeBuilder) _collectExpansionExpTokens(start Token) []Token {
 		if isExpansionStart {
 			expansionFormStack = append(expansionFormStack, peekType)
 			if iterationCount <= 10 {
-				fmt.Printf("[DEBUG] _collectExpansionExpTokens: pushed to stack, peekType=%d (EXPANSION_FORM_START=%d, EXPANSION_CASE_EXP_START=%d), new stackSize=%d\n",
+				fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: pushed to stack, peekType=%d (EXPANSION_FORM_START=%d, EXPANSION_CASE_EXP_START=%d), new stackSize=%d\n",
 					peekType, TokenTypeEXPANSION_FORM_START, TokenTypeEXPANSION_CASE_EXP_START, len(expansionFormStack))
 			}
 		} else if iterationCount <= 10 && (peekType == TokenTypeCOMMENT_END || peekType == TokenTypeCDATA_END) {
 			// Debug: show when we encounter COMMENT_END or CDATA_END (which should NOT be pushed)
-			fmt.Printf("[DEBUG] _collectExpansionExpTokens: NOT pushing to stack, peekType=%d (COMMENT_END=%d, CDATA_END=%d, EXPANSION_FORM_START=%d, EXPANSION_CASE_EXP_START=%d)\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: NOT pushing to stack, peekType=%d (COMMENT_END=%d, CDATA_END=%d, EXPANSION_FORM_START=%d, EXPANSION_CASE_EXP_START=%d)\n",
 				peekType, TokenTypeCOMMENT_END, TokenTypeCDATA_END, TokenTypeEXPANSION_FORM_START, TokenTypeEXPANSION_CASE_EXP_START)
 		}
 
@@ -1638,19 +1644,19 @@ func (tb *TreeBuilder) _collectExpansionExpTokens(start Token) []Token {
 			if lastOnStack(expansionFormStack, TokenTypeEXPANSION_CASE_EXP_START) {
 				expansionFormStack = expansionFormStack[:len(expansionFormStack)-1]
 				if iterationCount <= 10 {
-					fmt.Printf("[DEBUG] _collectExpansionExpTokens: popped EXPANSION_CASE_EXP_START from stack, new stackSize=%d\n", len(expansionFormStack))
+					fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: popped EXPANSION_CASE_EXP_START from stack, new stackSize=%d\n", len(expansionFormStack))
 				}
 				// If stack is empty, this is the outer case end - return without collecting
 				if len(expansionFormStack) == 0 {
-					fmt.Printf("[DEBUG] _collectExpansionExpTokens: stack empty (outer case closed), returning %d tokens\n", len(exp))
+					fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: stack empty (outer case closed), returning %d tokens\n", len(exp))
 					// Don't advance - return immediately without collecting the outer EXPANSION_CASE_EXP_END
 					return exp
 				}
 				// Nested expansion case - collect the token and continue
-				fmt.Printf("[DEBUG] _collectExpansionExpTokens: nested expansion case closed, collecting token, stackSize=%d\n", len(expansionFormStack))
+				fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: nested expansion case closed, collecting token, stackSize=%d\n", len(expansionFormStack))
 				// Fall through to collect the token
 			} else {
-				fmt.Printf("[DEBUG] _collectExpansionExpTokens: ERROR - stack mismatch for EXPANSION_CASE_EXP_END, stack=%v\n", expansionFormStack)
+				fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: ERROR - stack mismatch for EXPANSION_CASE_EXP_END, stack=%v\n", expansionFormStack)
 				tb.errors = append(tb.errors, NewTreeError(
 					nil,
 					start.SourceSpan(),
@@ -1664,12 +1670,12 @@ func (tb *TreeBuilder) _collectExpansionExpTokens(start Token) []Token {
 			if lastOnStack(expansionFormStack, TokenTypeEXPANSION_FORM_START) {
 				expansionFormStack = expansionFormStack[:len(expansionFormStack)-1]
 				if iterationCount <= 10 {
-					fmt.Printf("[DEBUG] _collectExpansionExpTokens: popped EXPANSION_FORM_START from stack, new stackSize=%d\n", len(expansionFormStack))
+					fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: popped EXPANSION_FORM_START from stack, new stackSize=%d\n", len(expansionFormStack))
 				}
 				// Collect the token and continue (matches TypeScript behavior)
 				// Fall through to collect the token
 			} else {
-				fmt.Printf("[DEBUG] _collectExpansionExpTokens: ERROR - stack mismatch for EXPANSION_FORM_END\n")
+				fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: ERROR - stack mismatch for EXPANSION_FORM_END\n")
 				tb.errors = append(tb.errors, NewTreeError(
 					nil,
 					start.SourceSpan(),
@@ -1684,13 +1690,13 @@ func (tb *TreeBuilder) _collectExpansionExpTokens(start Token) []Token {
 		currentToken := tb.advance()
 		exp = append(exp, currentToken)
 		if iterationCount <= 10 {
-			fmt.Printf("[DEBUG] _collectExpansionExpTokens: collected token, type=%d, parts=%v, expLen=%d\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: collected token, type=%d, parts=%v, expLen=%d\n",
 				currentToken.Type(), currentToken.Parts(), len(exp))
 		}
 
 		// Check if we actually advanced (prevent infinite loop)
 		if iterationCount <= 10 {
-			fmt.Printf("[DEBUG] _collectExpansionExpTokens: after advance, oldIndex=%d, newIndex=%d, totalTokens=%d, peek.Type()=%d\n",
+			fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: after advance, oldIndex=%d, newIndex=%d, totalTokens=%d, peek.Type()=%d\n",
 				oldIndex, tb.index, len(tb.tokens), func() int {
 					if tb.peek == nil {
 						return -1
@@ -1701,11 +1707,11 @@ func (tb *TreeBuilder) _collectExpansionExpTokens(start Token) []Token {
 
 		// If we didn't advance (stuck at the same index), we've reached the end
 		if tb.index == oldIndex && tb.index >= len(tb.tokens)-1 {
-			fmt.Printf("[DEBUG] _collectExpansionExpTokens: cannot advance further, index=%d, totalTokens=%d, returning error at start.SourceSpan()=%v\n", tb.index, len(tb.tokens), start.SourceSpan())
+			fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: cannot advance further, index=%d, totalTokens=%d, returning error at start.SourceSpan()=%v\n", tb.index, len(tb.tokens), start.SourceSpan())
 			// Check if peek is EOF - if so, we already handled it above
 			if tb.peek != nil && tb.peek.Type() == TokenTypeEOF {
 				// Already handled EOF case above, just return
-				fmt.Printf("[DEBUG] _collectExpansionExpTokens: cannot advance further, EOF already handled, returning\n")
+				fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: cannot advance further, EOF already handled, returning\n")
 				return nil
 			}
 			err := NewTreeError(
@@ -1713,7 +1719,7 @@ func (tb *TreeBuilder) _collectExpansionExpTokens(start Token) []Token {
 				start.SourceSpan(),
 				"Invalid ICU message. Missing '}'.",
 			)
-			fmt.Printf("[DEBUG] _collectExpansionExpTokens: creating stuck error with span=%v\n", err.ParseError.Span)
+			fmt.Fprintf(os.Stderr, "[DEBUG] _collectExpansionExpTokens: creating stuck error with span=%v\n", err.ParseError.Span)
 			tb.errors = append(tb.errors, err)
 			return nil
 		}
@@ -266,10 +266,21 @@ func (ctx *EmitterVisitorContext) sourceLines() []*EmittedLine {
 	return ctx.lines
 }
 
+// EmitterVisitor is implemented by concrete emitters, which print both expressions and
+// statements.
+type EmitterVisitor interface {
+	ExpressionVisitor
+	StatementVisitor
+}
+
 // AbstractEmitterVisitor is the base class for emitters
 type AbstractEmitterVisitor struct {
 	lastIfCondition       OutputExpression
 	escapeDollarInStrings bool
+
+	// visitor is the concrete emitter embedding this one. Nested expressions and statements are
+	// dispatched to it so that its overrides apply to the whole tree, not just the root.
+	visitor EmitterVisitor
 }
 
 // NewAbstractEmitterVisitor creates a new AbstractEmitterVisitor
@@ -280,6 +291,19 @@ func NewAbstractEmitterVisitor(escapeDollarInStrings bool) *AbstractEmitterVisit
 	}
 }
 
+// SetVisitor sets the concrete emitter to which nested expressions and statements are
+// dispatched. Emitters embedding AbstractEmitterVisitor call it with themselves.
+func (v *AbstractEmitterVisitor) SetVisitor(visitor EmitterVisitor) {
+	v.visitor = visitor
+}
+
+func (v *AbstractEmitterVisitor) self() EmitterVisitor {
+	if v.visitor != nil {
+		return v.visitor
+	}
+	return v
+}
+
 // getContext converts interface{} to *EmitterVisitorContext
 func (v *AbstractEmitterVisitor) getContext(context interface{}) *EmitterVisitorContext {
 	if ctx, ok := context.(*EmitterVisitorContext); ok {
@@ -288,16 +312,46 @@ func (v *AbstractEmitterVisitor) getContext(context interface{}) *EmitterVisitor
 	panic("context must be *EmitterVisitorContext")
 }
 
-// PrintLeadingComments prints leading comments
+// PrintLeadingComments prints the comments preceding a statement.
 func (v *AbstractEmitterVisitor) PrintLeadingComments(stmt OutputStatement, ctx *EmitterVisitorContext) {
-	// TODO: Implement when LeadingComments are available
+	if withComments, ok := stmt.(interface{ GetLeadingComments() []*LeadingComment }); ok {
+		printComments(stmt, withComments.GetLeadingComments(), ctx)
+	}
+}
+
+func printComments(stmt OutputStatement, comments []*LeadingComment, ctx *EmitterVisitorContext) {
+	for _, comment := range comments {
+		var text string
+		switch {
+		case comment.JSDoc != nil && comment.TrailingNewline:
+			text = comment.JSDoc.String()
+		case comment.JSDoc != nil:
+			text = comment.JSDoc.InlineString()
+		case comment.Multiline:
+			text = "/* " + comment.Text + " */"
+		default:
+			for _, line := range strings.Split(comment.Text, "\n") {
+				ctx.Println(stmt, "// "+line)
+			}
+			continue
+		}
+		lines := strings.Split(text, "\n")
+		for _, line := range lines[:len(lines)-1] {
+			ctx.Println(stmt, line)
+		}
+		if comment.TrailingNewline {
+			ctx.Println(stmt, lines[len(lines)-1])
+		} else {
+			ctx.Print(stmt, lines[len(lines)-1]+" ", false)
+		}
+	}
 }
 
 // VisitExpressionStmt visits an expression statement
 func (v *AbstractEmitterVisitor) VisitExpressionStmt(stmt *ExpressionStatement, context interface{}) interface{} {
 	ctx := v.getContext(context)
 	v.PrintLeadingComments(stmt, ctx)
-	stmt.Expr.VisitExpression(v, ctx)
+	stmt.Expr.VisitExpression(v.self(), ctx)
 	ctx.Println(stmt, ";")
 	return nil
 }
@@ -307,7 +361,7 @@ func (v *AbstractEmitterVisitor) VisitReturnStmt(stmt *ReturnStatement, context
 	ctx := v.getContext(context)
 	v.PrintLeadingComments(stmt, ctx)
 	ctx.Print(stmt, "return ", false)
-	stmt.Value.VisitExpression(v, ctx)
+	stmt.Value.VisitExpression(v.self(), ctx)
 	ctx.Println(stmt, ";")
 	return nil
 }
@@ -318,7 +372,7 @@ func (v *AbstractEmitterVisitor) VisitIfStmt(stmt *IfStmt, context interface{})
 	v.PrintLeadingComments(stmt, ctx)
 	ctx.Print(stmt, "if (", false)
 	v.lastIfCondition = stmt.Condition
-	stmt.Condition.VisitExpression(v, ctx)
+	stmt.Condition.VisitExpression(v.self(), ctx)
 	v.lastIfCondition = nil
 	ctx.Print(stmt, ") {", false)
 
@@ -355,7 +409,7 @@ func (v *AbstractEmitterVisitor) VisitInvokeFunctionExpr(expr *InvokeFunctionExp
 	if shouldParenthesize {
 		ctx.Print(expr.Fn, "(", false)
 	}
-	expr.Fn.VisitExpression(v, ctx)
+	expr.Fn.VisitExpression(v.self(), ctx)
 	if shouldParenthesize {
 		ctx.Print(expr.Fn, ")", false)
 	}
@@ -368,8 +422,8 @@ func (v *AbstractEmitterVisitor) VisitInvokeFunctionExpr(expr *InvokeFunctionExp
 // VisitTaggedTemplateLiteralExpr visits a tagged template literal expression
 func (v *AbstractEmitterVisitor) VisitTaggedTemplateLiteralExpr(expr *TaggedTemplateLiteralExpr, context interface{}) interface{} {
 	ctx := v.getContext(context)
-	expr.Tag.VisitExpression(v, ctx)
-	expr.Template.VisitExpression(v, ctx)
+	expr.Tag.VisitExpression(v.self(), ctx)
+	expr.Template.VisitExpression(v.self(), ctx)
 	return nil
 }
 
@@ -378,11 +432,11 @@ func (v *AbstractEmitterVisitor) VisitTemplateLiteralExpr(expr *TemplateLiteralE
 	ctx := v.getContext(context)
 	ctx.Print(expr, "`", false)
 	for i := 0; i < len(expr.Elements); i++ {
-		expr.Elements[i].VisitExpression(v, ctx)
+		expr.Elements[i].VisitExpression(v.self(), ctx)
 		if i < len(expr.Expressions) {
 			expression := expr.Expressions[i]
 			ctx.Print(expression, "${", false)
-			expression.VisitExpression(v, ctx)
+			expression.VisitExpression(v.self(), ctx)
 			ctx.Print(expression, "}", false)
 		}
 	}
@@ -407,7 +461,7 @@ func (v *AbstractEmitterVisitor) VisitWrappedNodeExpr(ast *WrappedNodeExpr, cont
 func (v *AbstractEmitterVisitor) VisitTypeofExpr(expr *TypeofExpr, context interface{}) interface{} {
 	ctx := v.getContext(context)
 	ctx.Print(expr, "typeof ", false)
-	expr.Expr.VisitExpression(v, ctx)
+	expr.Expr.VisitExpression(v.self(), ctx)
 	return nil
 }
 
@@ -415,7 +469,7 @@ func (v *AbstractEmitterVisitor) VisitTypeofExpr(expr *TypeofExpr, context inter
 func (v *AbstractEmitterVisitor) VisitVoidExpr(expr *VoidExpr, context interface{}) interface{} {
 	ctx := v.getContext(context)
 	ctx.Print(expr, "void ", false)
-	expr.Expr.VisitExpression(v, ctx)
+	expr.Expr.VisitExpression(v.self(), ctx)
 	return nil
 }
 
@@ -430,7 +484,7 @@ func (v *AbstractEmitterVisitor) VisitReadVarExpr(ast *ReadVarExpr, context inte
 func (v *AbstractEmitterVisitor) VisitInstantiateExpr(ast *InstantiateExpr, context interface{}) interface{} {
 	ctx := v.getContext(context)
 	ctx.Print(ast, "new ", false)
-	ast.ClassExpr.VisitExpression(v, ctx)
+	ast.ClassExpr.VisitExpression(v.self(), ctx)
 	ctx.Print(ast, "(", false)
 	v.VisitAllExpressions(ast.Args, ctx, ",")
 	ctx.Print(ast, ")", false)
@@ -443,6 +497,8 @@ func (v *AbstractEmitterVisitor) VisitLiteralExpr(ast *LiteralExpr, context inte
 	switch val := ast.Value.(type) {
 	case string:
 		ctx.Print(ast, EscapeIdentifier(val, v.escapeDollarInStrings, true), false)
+	case nil:
+		ctx.Print(ast, "null", false)
 	default:
 		ctx.Print(ast, fmt.Sprintf("%v", val), false)
 	}
@@ -463,8 +519,13 @@ func (v *AbstractEmitterVisitor) VisitRegularExpressionLiteral(ast *RegularExpre
 // VisitLocalizedString visits a localized string
 func (v *AbstractEmitterVisitor) VisitLocalizedString(ast *LocalizedString, context interface{}) interface{} {
 	ctx := v.getContext(context)
-	// TODO: Implement when LocalizedString is fully implemented
-	ctx.Print(ast, "$localize `", false)
+	head := ast.SerializeI18nHead()
+	ctx.Print(ast, "$localize `"+head.Raw, false)
+	for i := 1; i < len(ast.MessageParts); i++ {
+		ctx.Print(ast, "${", false)
+		ast.Expressions[i-1].VisitExpression(v.self(), ctx)
+		ctx.Print(ast, "}"+ast.SerializeI18nTemplatePart(i).Raw, false)
+	}
 	ctx.Print(ast, "`", false)
 	return nil
 }
@@ -473,12 +534,12 @@ func (v *AbstractEmitterVisitor) VisitLocalizedString(ast *LocalizedString, cont
 func (v *AbstractEmitterVisitor) VisitConditionalExpr(ast *ConditionalExpr, context interface{}) interface{} {
 	ctx := v.getContext(context)
 	ctx.Print(ast, "(", false)
-	ast.Condition.VisitExpression(v, ctx)
+	ast.Condition.VisitExpression(v.self(), ctx)
 	ctx.Print(ast, "? ", false)
-	ast.TrueCase.VisitExpression(v, ctx)
+	ast.TrueCase.VisitExpression(v.self(), ctx)
 	ctx.Print(ast, ": ", false)
 	if ast.FalseCase != nil {
-		ast.FalseCase.VisitExpression(v, ctx)
+		ast.FalseCase.VisitExpression(v.self(), ctx)
 	}
 	ctx.Print(ast, ")", false)
 	return nil
@@ -491,7 +552,7 @@ func (v *AbstractEmitterVisitor) VisitDynamicImportExpr(ast *DynamicImportExpr,
 		ctx.Print(ast, fmt.Sprintf("import(%s)", url), false)
 	} else if expr, ok := ast.URL.(OutputExpression); ok {
 		ctx.Print(ast, "import(", false)
-		expr.VisitExpression(v, ctx)
+		expr.VisitExpression(v.self(), ctx)
 		ctx.Print(ast, ")", false)
 	}
 	return nil
@@ -501,7 +562,7 @@ func (v *AbstractEmitterVisitor) VisitDynamicImportExpr(ast *DynamicImportExpr,
 func (v *AbstractEmitterVisitor) VisitNotExpr(ast *NotExpr, context interface{}) interface{} {
 	ctx := v.getContext(context)
 	ctx.Print(ast, "!", false)
-	ast.Condition.VisitExpression(v, ctx)
+	ast.Condition.VisitExpression(v.self(), ctx)
 	return nil
 }
 
@@ -523,7 +584,7 @@ func (v *AbstractEmitterVisitor) VisitUnaryOperatorExpr(ast *UnaryOperatorExpr,
 		ctx.Print(ast, "(", false)
 	}
 	ctx.Print(ast, opStr, false)
-	ast.Expr.VisitExpression(v, ctx)
+	ast.Expr.VisitExpression(v.self(), ctx)
 	if parens {
 		ctx.Print(ast, ")", false)
 	}
@@ -542,9 +603,9 @@ func (v *AbstractEmitterVisitor) VisitBinaryOperatorExpr(ast *BinaryOperatorExpr
 	if parens {
 		ctx.Print(ast, "(", false)
 	}
-	ast.Lhs.VisitExpression(v, ctx)
+	ast.Lhs.VisitExpression(v.self(), ctx)
 	ctx.Print(ast, " "+operator+" ", false)
-	ast.Rhs.VisitExpression(v, ctx)
+	ast.Rhs.VisitExpression(v.self(), ctx)
 	if parens {
 		ctx.Print(ast, ")", false)
 	}
@@ -554,7 +615,7 @@ func (v *AbstractEmitterVisitor) VisitBinaryOperatorExpr(ast *BinaryOperatorExpr
 // VisitReadPropExpr visits a read property expression
 func (v *AbstractEmitterVisitor) VisitReadPropExpr(ast *ReadPropExpr, context interface{}) interface{} {
 	ctx := v.getContext(context)
-	ast.Receiver.VisitExpression(v, ctx)
+	ast.Receiver.VisitExpression(v.self(), ctx)
 	ctx.Print(ast, ".", false)
 	ctx.Print(ast, ast.Name, false)
 	return nil
@@ -563,9 +624,9 @@ func (v *AbstractEmitterVisitor) VisitReadPropExpr(ast *ReadPropExpr, context in
 // VisitReadKeyExpr visits a read key expression
 func (v *AbstractEmitterVisitor) VisitReadKeyExpr(ast *ReadKeyExpr, context interface{}) interface{} {
 	ctx := v.getContext(context)
-	ast.Receiver.VisitExpression(v, ctx)
+	ast.Receiver.VisitExpression(v.self(), ctx)
 	ctx.Print(ast, "[", false)
-	ast.Index.VisitExpression(v, ctx)
+	ast.Index.VisitExpression(v.self(), ctx)
 	ctx.Print(ast, "]", false)
 	return nil
 }
@@ -585,7 +646,7 @@ func (v *AbstractEmitterVisitor) VisitLiteralMapExpr(ast *LiteralMapExpr, contex
 	ctx.Print(ast, "{", false)
 	handler := func(entry *LiteralMapEntry) {
 		ctx.Print(ast, EscapeIdentifier(entry.Key, v.escapeDollarInStrings, entry.Quoted)+":", false)
-		entry.Value.VisitExpression(v, ctx)
+		entry.Value.VisitExpression(v.self(), ctx)
 	}
 	v.VisitAllObjects(handler, ast.Entries, ctx, ",")
 	ctx.Print(ast, "}", false)
@@ -606,14 +667,14 @@ func (v *AbstractEmitterVisitor) VisitParenthesizedExpr(ast *ParenthesizedExpr,
 	ctx := v.getContext(context)
 	// We parenthesize everything regardless of an explicit ParenthesizedExpr, so we can just visit
 	// the inner expression.
-	ast.Expr.VisitExpression(v, ctx)
+	ast.Expr.VisitExpression(v.self(), ctx)
 	return nil
 }
 
 // VisitAllExpressions visits all expressions
 func (v *AbstractEmitterVisitor) VisitAllExpressions(expressions []OutputExpression, ctx *EmitterVisitorContext, separator string) {
 	v.VisitAllObjects(func(expr OutputExpression) {
-		expr.VisitExpression(v, ctx)
+		expr.VisitExpression(v.self(), ctx)
 	}, expressions, ctx, separator)
 }
 
@@ -625,46 +686,44 @@ func (v *AbstractEmitterVisitor) VisitAllObjects(
 	ctx *EmitterVisitorContext,
 	separator string,
 ) {
-	incrementedIndent := false
-
 	// Type assertion based on the type of expressions
+	count := 0
+	var visit func(i int)
 	switch exprs := expressions.(type) {
 	case []OutputExpression:
-		for i := 0; i < len(exprs); i++ {
-			if i > 0 {
-				if ctx.LineLength() > 80 {
-					ctx.Print(nil, separator, true)
-					if !incrementedIndent {
-						ctx.IncIndent()
-						ctx.IncIndent()
-						incrementedIndent = true
-					}
-				} else {
-					ctx.Print(nil, separator, false)
-				}
-			}
-			if h, ok := handler.(func(OutputExpression)); ok {
-				h(exprs[i])
-			}
+		count = len(exprs)
+		if h, ok := handler.(func(OutputExpression)); ok {
+			visit = func(i int) { h(exprs[i]) }
 		}
 	case []*LiteralMapEntry:
-		for i := 0; i < len(exprs); i++ {
-			if i > 0 {
-				if ctx.LineLength() > 80 {
-					ctx.Print(nil, separator, true)
-					if !incrementedIndent {
-						ctx.IncIndent()
-						ctx.IncIndent()
-						incrementedIndent = true
-					}
-				} else {
-					ctx.Print(nil, separator, false)
+		count = len(exprs)
+		if h, ok := handler.(func(*LiteralMapEntry)); ok {
+			visit = func(i int) { h(exprs[i]) }
+		}
+	case []*FnParam:
+		count = len(exprs)
+		if h, ok := handler.(func(*FnParam)); ok {
+			visit = func(i int) { h(exprs[i]) }
+		}
+	}
+
+	incrementedIndent := false
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			if ctx.LineLength() > 80 {
+				ctx.Print(nil, separator, true)
+				if !incrementedIndent {
+					ctx.IncIndent()
+					ctx.IncIndent()
+					incrementedIndent = true
 				}
-			}
-			if h, ok := handler.(func(*LiteralMapEntry)); ok {
-				h(exprs[i])
+			} else {
+				ctx.Print(nil, separator, false)
 			}
 		}
+		if visit != nil {
+			visit(i)
+		}
 	}
 
 	if incrementedIndent {
@@ -676,7 +735,7 @@ func (v *AbstractEmitterVisitor) VisitAllObjects(
 // VisitAllStatements visits all statements
 func (v *AbstractEmitterVisitor) VisitAllStatements(statements []OutputStatement, ctx *EmitterVisitorContext) {
 	for _, stmt := range statements {
-		stmt.VisitStatement(v, ctx)
+		stmt.VisitStatement(v.self(), ctx)
 	}
 }
 
@@ -709,10 +768,6 @@ func (v *AbstractEmitterVisitor) VisitDeclareFunctionStmt(stmt *DeclareFunctionS
 
 // EscapeIdentifier escapes an identifier
 func EscapeIdentifier(input string, escapeDollar bool, alwaysQuote bool) string {
-	if input == "" {
-		return ""
-	}
-
 	body := singleQuoteEscapeStringRe.ReplaceAllStringFunc(input, func(match string) string {
 		if match == "$" {
 			if escapeDollar {
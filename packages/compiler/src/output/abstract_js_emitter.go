@@ -14,9 +14,11 @@ type AbstractJsEmitterVisitor struct {
 
 // NewAbstractJsEmitterVisitor creates a new AbstractJsEmitterVisitor
 func NewAbstractJsEmitterVisitor() *AbstractJsEmitterVisitor {
-	return &AbstractJsEmitterVisitor{
+	v := &AbstractJsEmitterVisitor{
 		AbstractEmitterVisitor: NewAbstractEmitterVisitor(false),
 	}
+	v.SetVisitor(v)
+	return v
 }
 
 // VisitWrappedNodeExpr visits a wrapped node expression
@@ -30,7 +32,7 @@ func (v *AbstractJsEmitterVisitor) VisitDeclareVarStmt(stmt *DeclareVarStmt, con
 	ctx.Print(stmt, fmt.Sprintf("var %s", stmt.Name), false)
 	if stmt.Value != nil {
 		ctx.Print(stmt, " = ", false)
-		stmt.Value.VisitExpression(v, ctx)
+		stmt.Value.VisitExpression(v.self(), ctx)
 	}
 	ctx.Println(stmt, ";")
 	return nil
@@ -48,7 +50,7 @@ func (v *AbstractJsEmitterVisitor) VisitTaggedTemplateLiteralExpr(expr *TaggedTe
 	// tag(__makeTemplateObject(cooked, raw), expression1, expression2, ...);
 	// ```
 	elements := expr.Template.Elements
-	expr.Tag.VisitExpression(v, ctx)
+	expr.Tag.VisitExpression(v.self(), ctx)
 	ctx.Print(expr, fmt.Sprintf("(%s(", makeTemplateObjectPolyfill), false)
 
 	cookedParts := []string{}
@@ -65,7 +67,7 @@ func (v *AbstractJsEmitterVisitor) VisitTaggedTemplateLiteralExpr(expr *TaggedTe
 
 	for _, expression := range expr.Template.Expressions {
 		ctx.Print(expr, ", ", false)
-		expression.VisitExpression(v, ctx)
+		expression.VisitExpression(v.self(), ctx)
 	}
 	ctx.Print(expr, ")", false)
 	return nil
@@ -76,11 +78,11 @@ func (v *AbstractJsEmitterVisitor) VisitTemplateLiteralExpr(expr *TemplateLitera
 	ctx := v.getContext(context)
 	ctx.Print(expr, "`", false)
 	for i := 0; i < len(expr.Elements); i++ {
-		expr.Elements[i].VisitExpression(v, ctx)
+		expr.Elements[i].VisitExpression(v.self(), ctx)
 		if i < len(expr.Expressions) {
 			expression := expr.Expressions[i]
 			ctx.Print(expression, "${", false)
-			expression.VisitExpression(v, ctx)
+			expression.VisitExpression(v.self(), ctx)
 			ctx.Print(expression, "}", false)
 		}
 	}
@@ -135,7 +137,7 @@ func (v *AbstractJsEmitterVisitor) VisitArrowFunctionExpr(ast *ArrowFunctionExpr
 			ctx.Print(ast, "(", false)
 		}
 
-		expr.VisitExpression(v, ctx)
+		expr.VisitExpression(v.self(), ctx)
 
 		if isObjectLiteral {
 			ctx.Print(ast, ")", false)
@@ -170,32 +172,22 @@ func (v *AbstractJsEmitterVisitor) VisitLocalizedString(ast *LocalizedString, co
 	// $localize(__makeTemplateObject(cooked, raw), expression1, expression2, ...);
 	// ```
 	ctx.Print(ast, fmt.Sprintf("$localize(%s(", makeTemplateObjectPolyfill), false)
-
-	// TODO: Implement when LocalizedString is fully implemented
-	// parts := []CookedRawString{ast.SerializeI18nHead()}
-	// for i := 1; i < len(ast.MessageParts); i++ {
-	// 	parts = append(parts, ast.SerializeI18nTemplatePart(i))
-	// }
-	// cookedParts := []string{}
-	// for _, part := range parts {
-	// 	cookedParts = append(cookedParts, EscapeIdentifier(part.Cooked, false, true))
-	// }
-	// ctx.Print(ast, fmt.Sprintf("[%s], ", strings.Join(cookedParts, ", ")), false)
-	// rawParts := []string{}
-	// for _, part := range parts {
-	// 	rawParts = append(rawParts, EscapeIdentifier(part.Raw, false, true))
-	// }
-	// ctx.Print(ast, fmt.Sprintf("[%s])", strings.Join(rawParts, ", ")), false)
-
-	// Placeholder implementation
-	ctx.Print(ast, "[], []", false)
-
-	// TODO: Add expressions when LocalizedString is fully implemented
-	// for _, expression := range ast.Expressions {
-	// 	ctx.Print(ast, ", ", false)
-	// 	expression.VisitExpression(v, ctx)
-	// }
-
+	parts := []CookedRawString{ast.SerializeI18nHead()}
+	for i := 1; i < len(ast.MessageParts); i++ {
+		parts = append(parts, ast.SerializeI18nTemplatePart(i))
+	}
+	cookedParts := make([]string, len(parts))
+	rawParts := make([]string, len(parts))
+	for i, part := range parts {
+		cookedParts[i] = EscapeIdentifier(part.Cooked, false, true)
+		rawParts[i] = EscapeIdentifier(part.Raw, false, true)
+	}
+	ctx.Print(ast, fmt.Sprintf("[%s], ", strings.Join(cookedParts, ", ")), false)
+	ctx.Print(ast, fmt.Sprintf("[%s])", strings.Join(rawParts, ", ")), false)
+	for _, expression := range ast.Expressions {
+		ctx.Print(ast, ", ", false)
+		expression.VisitExpression(v.self(), ctx)
+	}
 	ctx.Print(ast, ")", false)
 	return nil
 }
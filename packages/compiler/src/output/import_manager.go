@@ -0,0 +1,74 @@
+package output
+
+import (
+	"fmt"
+)
+
+// ImportWithPrefix is a module imported by emitted code under a namespace prefix.
+type ImportWithPrefix struct {
+	ModuleName string
+	Prefix     string
+}
+
+// ImportManager manages the imports of a generated module. Every module referenced by an
+// ExternalExpr is imported once as a namespace, with prefixes assigned in order of first use,
+// e.g. `import * as i0 from '@angular/core';`. References to the modules of compiled sources,
+// e.g. of the directives and pipes used by a template, carry module names relative to the
+// referencing file and are imported the same way.
+//
+// The emitters of the parts of a module share a manager, so that they agree on the prefixes.
+type ImportManager struct {
+	imports  []ImportWithPrefix
+	prefixes map[string]string
+}
+
+// NewImportManager creates an ImportManager without imports.
+func NewImportManager() *ImportManager {
+	return &ImportManager{prefixes: make(map[string]string)}
+}
+
+// Prefix returns the namespace prefix of a module, assigning the next one on first use.
+func (m *ImportManager) Prefix(moduleName string) string {
+	prefix, ok := m.prefixes[moduleName]
+	if !ok {
+		prefix = fmt.Sprintf("i%d", len(m.imports))
+		m.prefixes[moduleName] = prefix
+		m.imports = append(m.imports, ImportWithPrefix{ModuleName: moduleName, Prefix: prefix})
+	}
+	return prefix
+}
+
+// ImportsWithPrefixes returns the modules referenced so far, in order of first use.
+func (m *ImportManager) ImportsWithPrefixes() []ImportWithPrefix {
+	return m.imports
+}
+
+// Declarations returns the import declarations of the modules referenced so far.
+func (m *ImportManager) Declarations() []string {
+	return ImportDeclarations(m.imports)
+}
+
+// CollectStatements assigns prefixes to the modules referenced by statements, in the order
+// emitting them would, without printing them.
+func (m *ImportManager) CollectStatements(stmts []OutputStatement) {
+	v := NewJsEmitterVisitor()
+	v.ImportManager = m
+	v.VisitAllStatements(stmts, CreateRootEmitterVisitorContext())
+}
+
+// printReference prints an external reference through the prefix of its module.
+func (m *ImportManager) printReference(ast *ExternalExpr, ctx *EmitterVisitorContext) {
+	if ast.Value.ModuleName == nil && ast.Value.Name == nil {
+		panic("Cannot emit an external reference without a module or a name.")
+	}
+	if ast.Value.ModuleName != nil {
+		prefix := m.Prefix(*ast.Value.ModuleName)
+		// A reference without a name is the module namespace itself, e.g. `ngImport: i0`.
+		if ast.Value.Name == nil {
+			ctx.Print(ast, prefix, false)
+			return
+		}
+		ctx.Print(ast, prefix+".", false)
+	}
+	ctx.Print(ast, *ast.Value.Name, false)
+}
@@ -0,0 +1,137 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JavaScriptEmitter prints output statements as an ES module.
+type JavaScriptEmitter struct {
+	// AnnotateForClosureCompiler is as for JsEmitterVisitor.
+	AnnotateForClosureCompiler bool
+}
+
+// EmitStatements prints the statements of a generated file. External references are imported
+// through namespace imports (`import * as i0 from '@angular/core';`) which are emitted after the
+// preamble.
+func (e JavaScriptEmitter) EmitStatements(genFilePath string, stmts []OutputStatement, preamble string) string {
+	converter := NewJsEmitterVisitor()
+	converter.AnnotateForClosureCompiler = e.AnnotateForClosureCompiler
+	ctx := CreateRootEmitterVisitorContext()
+	converter.VisitAllStatements(stmts, ctx)
+	return moduleSource(preamble, converter.ImportsWithPrefixes(), ctx.ToSource())
+}
+
+// moduleSource prints the preamble, the namespace imports and the code of a module.
+func moduleSource(preamble string, imports []ImportWithPrefix, code string) string {
+	var lines []string
+	if preamble != "" {
+		lines = strings.Split(strings.TrimSuffix(preamble, "\n"), "\n")
+	}
+	lines = append(lines, ImportDeclarations(imports)...)
+	lines = append(lines, code)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// ImportDeclarations returns the namespace import declarations of the given modules, e.g.
+// `import * as i0 from '@angular/core';`.
+func ImportDeclarations(imports []ImportWithPrefix) []string {
+	lines := make([]string, len(imports))
+	for i, imp := range imports {
+		lines[i] = fmt.Sprintf("import * as %s from '%s';", imp.Prefix, imp.ModuleName)
+	}
+	return lines
+}
+
+// JsEmitterVisitor emits ES module code. References to other modules are printed through the
+// namespace prefixes of its ImportManager.
+type JsEmitterVisitor struct {
+	*AbstractJsEmitterVisitor
+	*ImportManager
+	// AnnotateForClosureCompiler marks the constants, which are declared with `var`, as `@const`
+	// for the advanced optimizations of Closure Compiler.
+	AnnotateForClosureCompiler bool
+}
+
+// NewJsEmitterVisitor creates a new JsEmitterVisitor with its own ImportManager.
+func NewJsEmitterVisitor() *JsEmitterVisitor {
+	v := &JsEmitterVisitor{
+		AbstractJsEmitterVisitor: NewAbstractJsEmitterVisitor(),
+		ImportManager:            NewImportManager(),
+	}
+	v.SetVisitor(v)
+	return v
+}
+
+// VisitExternalExpr visits an external expression
+func (v *JsEmitterVisitor) VisitExternalExpr(ast *ExternalExpr, context interface{}) interface{} {
+	v.printReference(ast, v.getContext(context))
+	return nil
+}
+
+// VisitWrappedNodeExpr visits a wrapped node expression. Strings are source code which is
+// copied verbatim, e.g. the expressions of decorator arguments.
+func (v *JsEmitterVisitor) VisitWrappedNodeExpr(ast *WrappedNodeExpr, context interface{}) interface{} {
+	ctx := v.getContext(context)
+	code, ok := ast.Node.(string)
+	if !ok {
+		panic("Cannot emit a WrappedNodeExpr in Javascript.")
+	}
+	ctx.Print(ast, code, false)
+	return nil
+}
+
+// VisitDeclareVarStmt visits a declare variable statement
+func (v *JsEmitterVisitor) VisitDeclareVarStmt(stmt *DeclareVarStmt, context interface{}) interface{} {
+	ctx := v.getContext(context)
+	comments := stmt.LeadingComments
+	if v.AnnotateForClosureCompiler && stmt.GetModifiers()&StmtModifierFinal != 0 {
+		comments = withConstTag(comments)
+	}
+	printComments(stmt, comments, ctx)
+	if stmt.GetModifiers()&StmtModifierExported != 0 {
+		ctx.Print(stmt, "export ", false)
+	}
+	return v.AbstractJsEmitterVisitor.VisitDeclareVarStmt(stmt, context)
+}
+
+// withConstTag adds `@const` to the JSDoc comment of a declaration, or a `/** @const */` comment
+// when it has none, as Closure Compiler only reads the last JSDoc comment.
+func withConstTag(comments []*LeadingComment) []*LeadingComment {
+	constTag := "const"
+	tag := JSDocTag{TagName: &constTag}
+	annotated := make([]*LeadingComment, len(comments))
+	copy(annotated, comments)
+	for i := len(annotated) - 1; i >= 0; i-- {
+		if comment := annotated[i]; comment.JSDoc != nil {
+			tags := append(append([]JSDocTag{}, comment.JSDoc.Tags...), tag)
+			annotated[i] = &LeadingComment{JSDoc: NewJSDocComment(tags), TrailingNewline: comment.TrailingNewline}
+			return annotated
+		}
+	}
+	return append(annotated, &LeadingComment{JSDoc: NewJSDocComment([]JSDocTag{tag})})
+}
+
+// VisitDeclareFunctionStmt visits a declare function statement
+func (v *JsEmitterVisitor) VisitDeclareFunctionStmt(stmt *DeclareFunctionStmt, context interface{}) interface{} {
+	ctx := v.getContext(context)
+	if stmt.GetModifiers()&StmtModifierExported != 0 {
+		ctx.Print(stmt, "export ", false)
+	}
+	return v.AbstractJsEmitterVisitor.VisitDeclareFunctionStmt(stmt, context)
+}
+
+// VisitExpressionStmt visits an expression statement. Top-level assignments are printed
+// without parentheses.
+func (v *JsEmitterVisitor) VisitExpressionStmt(stmt *ExpressionStatement, context interface{}) interface{} {
+	ctx := v.getContext(context)
+	if binary, ok := stmt.Expr.(*BinaryOperatorExpr); ok && binary.Operator == BinaryOperatorAssign {
+		v.PrintLeadingComments(stmt, ctx)
+		binary.Lhs.VisitExpression(v, ctx)
+		ctx.Print(binary, " = ", false)
+		binary.Rhs.VisitExpression(v, ctx)
+		ctx.Println(stmt, ";")
+		return nil
+	}
+	return v.AbstractJsEmitterVisitor.VisitExpressionStmt(stmt, context)
+}
@@ -1,8 +1,10 @@
 package output
 
 import (
+	"regexp"
 	"strings"
 
+	"ngc-go/packages/compiler/src/i18n"
 	"ngc-go/packages/compiler/src/util"
 )
 
@@ -522,6 +524,18 @@ func areAllEquivalentExprs(base, other []OutputExpression) bool {
 	return true
 }
 
+func areAllEquivalentStmts(base, other []OutputStatement) bool {
+	if len(base) != len(other) {
+		return false
+	}
+	for i := 0; i < len(base); i++ {
+		if !base[i].IsEquivalent(other[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 type TaggedTemplateLiteralExpr struct {
 	ExpressionBase
 	Tag      OutputExpression
@@ -724,6 +738,110 @@ func (l *LocalizedString) Clone() OutputExpression {
 	)
 }
 
+// Separators of the metadata block of `$localize` tagged strings.
+const (
+	MEANING_SEPARATOR   = "|"
+	ID_SEPARATOR        = "@@"
+	LEGACY_ID_INDICATOR = "\u241F"
+)
+
+// CookedRawString is a part of a `$localize` tagged string: cooked is the text, raw is the text
+// escaped for a template literal.
+type CookedRawString struct {
+	Cooked string
+	Raw    string
+	Range  *util.ParseSourceSpan
+}
+
+// SerializeI18nHead serializes the meta block and the first message part into "cooked" and "raw"
+// strings that can be used in a `$localize` tagged string. The format of the metadata is the same
+// as that parsed by `parseI18nMeta()`.
+func (l *LocalizedString) SerializeI18nHead() CookedRawString {
+	metaBlock := ""
+	if l.MetaBlock != nil {
+		if l.MetaBlock.Description != nil {
+			metaBlock = *l.MetaBlock.Description
+		}
+		if l.MetaBlock.Meaning != nil && *l.MetaBlock.Meaning != "" {
+			metaBlock = *l.MetaBlock.Meaning + MEANING_SEPARATOR + metaBlock
+		}
+		if l.MetaBlock.CustomID != nil && *l.MetaBlock.CustomID != "" {
+			metaBlock = metaBlock + ID_SEPARATOR + *l.MetaBlock.CustomID
+		}
+		for _, legacyID := range l.MetaBlock.LegacyIDs {
+			metaBlock = metaBlock + LEGACY_ID_INDICATOR + legacyID
+		}
+	}
+	return createCookedRawString(metaBlock, l.MessageParts[0].Text, l.getMessagePartSourceSpan(0))
+}
+
+// SerializeI18nTemplatePart serializes the placeholder before a message part and the message
+// part into "cooked" and "raw" strings that can be used in a `$localize` tagged string.
+//
+// The format is `:<placeholder-name>[@@<associated-id>]:`, where the associated id is the id of
+// the message the placeholder refers to, such as an ICU of the main message.
+func (l *LocalizedString) SerializeI18nTemplatePart(partIndex int) CookedRawString {
+	placeholder := l.PlaceholderNames[partIndex-1]
+	messagePart := l.MessageParts[partIndex]
+	metaBlock := placeholder.Text
+	if associated, ok := placeholder.AssociatedMessage.(*i18n.Message); ok && associated != nil && len(associated.LegacyIDs) == 0 {
+		metaBlock += ID_SEPARATOR + i18n.ComputeMsgID(associated.MessageString, associated.Meaning)
+	}
+	return createCookedRawString(metaBlock, messagePart.Text, l.getMessagePartSourceSpan(partIndex))
+}
+
+func (l *LocalizedString) getMessagePartSourceSpan(i int) *util.ParseSourceSpan {
+	if i < len(l.MessageParts) && l.MessageParts[i].SourceSpan != nil {
+		return l.MessageParts[i].SourceSpan
+	}
+	return l.SourceSpan
+}
+
+var (
+	startingColonRe         = regexp.MustCompile(`^:`)
+	templateLiteralReplacer = strings.NewReplacer("`", "\\`", "${", "$\\{")
+)
+
+func escapeSlashes(str string) string {
+	return strings.ReplaceAll(str, "\\", "\\\\")
+}
+
+func escapeStartingColon(str string) string {
+	return startingColonRe.ReplaceAllString(str, "\\:")
+}
+
+func escapeColons(str string) string {
+	return strings.ReplaceAll(str, ":", "\\:")
+}
+
+func escapeForTemplateLiteral(str string) string {
+	return templateLiteralReplacer.Replace(str)
+}
+
+// createCookedRawString creates a CookedRawString from a meta block and a message part.
+//
+// The raw text must have various character sequences escaped:
+//   - "\" would otherwise indicate that the next character is a control character.
+//   - "`" and "${" are template string control sequences that would otherwise prematurely
+//     indicate the end of a message part.
+//   - ":" inside a metablock would prematurely indicate the end of the metablock.
+//   - ":" at the start of a messagePart with no metablock would erroneously indicate the start of
+//     a metablock.
+func createCookedRawString(metaBlock string, messagePart string, r *util.ParseSourceSpan) CookedRawString {
+	if metaBlock == "" {
+		return CookedRawString{
+			Cooked: messagePart,
+			Raw:    escapeForTemplateLiteral(escapeStartingColon(escapeSlashes(messagePart))),
+			Range:  r,
+		}
+	}
+	return CookedRawString{
+		Cooked: ":" + metaBlock + ":" + messagePart,
+		Raw:    escapeForTemplateLiteral(":" + escapeColons(escapeSlashes(metaBlock)) + ":" + escapeSlashes(messagePart)),
+		Range:  r,
+	}
+}
+
 type ExternalExpr struct {
 	ExpressionBase
 	Value      *ExternalReference
@@ -917,8 +1035,7 @@ func (f *FunctionExpr) IsEquivalent(e OutputExpression) bool {
 				return false
 			}
 		}
-		// TODO: Compare statements properly
-		return true
+		return areAllEquivalentStmts(f.Statements, fn.Statements)
 	}
 	return false
 }
@@ -933,8 +1050,7 @@ func (f *FunctionExpr) IsEquivalentToStmt(stmt *DeclareFunctionStmt) bool {
 			return false
 		}
 	}
-	// TODO: Compare statements properly
-	return true
+	return areAllEquivalentStmts(f.Statements, stmt.Statements)
 }
 
 func (f *FunctionExpr) IsConstant() bool {
@@ -1353,8 +1469,15 @@ func (a *ArrowFunctionExpr) IsEquivalent(e OutputExpression) bool {
 				return false
 			}
 		}
-		// TODO: Compare body
-		return true
+		switch body := a.Body.(type) {
+		case OutputExpression:
+			otherBody, ok := other.Body.(OutputExpression)
+			return ok && body.IsEquivalent(otherBody)
+		case []OutputStatement:
+			otherBody, ok := other.Body.([]OutputStatement)
+			return ok && areAllEquivalentStmts(body, otherBody)
+		}
+		return false
 	}
 	return false
 }
@@ -1486,6 +1609,11 @@ func (s *StatementBase) GetSourceSpan() *util.ParseSourceSpan {
 	return s.SourceSpan
 }
 
+// GetLeadingComments returns the comments printed before the statement
+func (s *StatementBase) GetLeadingComments() []*LeadingComment {
+	return s.LeadingComments
+}
+
 // I18nMeta represents i18n metadata
 type I18nMeta struct {
 	ID          *string
@@ -1587,26 +1715,46 @@ func (j *JSDocComment) String() string {
 
 	result := "/**\n"
 	for _, tag := range j.Tags {
-		if tag.TagName != nil {
-			result += " * @" + *tag.TagName
-		}
-		if tag.Text != nil {
-			text := *tag.Text
-			// Escape @ in text
-			text = strings.ReplaceAll(text, "@", "\\@")
-			result += " " + text
-		}
-		result += "\n"
+		result += " * " + tag.String() + "\n"
 	}
 	result += " */"
 	return result
 }
 
+// InlineString returns the JSDoc comment on a single line, e.g. `/** @nocollapse */`.
+func (j *JSDocComment) InlineString() string {
+	tags := make([]string, len(j.Tags))
+	for i, tag := range j.Tags {
+		tags[i] = tag.String()
+	}
+	return "/** " + strings.Join(tags, " ") + " */"
+}
+
+// String returns the tag as it is written in a JSDoc comment, e.g. `@desc Greeting`.
+func (t JSDocTag) String() string {
+	result := ""
+	if t.TagName != nil {
+		result = "@" + *t.TagName
+	}
+	if t.Text != nil {
+		// Escape @ in text
+		text := strings.ReplaceAll(*t.Text, "@", "\\@")
+		if result != "" {
+			result += " "
+		}
+		result += text
+	}
+	return result
+}
+
 // LeadingComment represents a leading comment
 type LeadingComment struct {
 	Text            string
 	Multiline       bool
 	TrailingNewline bool
+	// JSDoc is printed instead of Text for JSDoc comments: over several lines when followed by a
+	// newline, and on the line of the statement otherwise.
+	JSDoc *JSDocComment
 }
 
 // DeclareVarStmt represents a variable declaration statement
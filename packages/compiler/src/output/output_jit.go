@@ -128,13 +128,15 @@ type JitEmitterVisitor struct {
 
 // NewJitEmitterVisitor creates a new JitEmitterVisitor
 func NewJitEmitterVisitor(refResolver ExternalReferenceResolver) *JitEmitterVisitor {
-	return &JitEmitterVisitor{
+	jev := &JitEmitterVisitor{
 		AbstractJsEmitterVisitor: NewAbstractJsEmitterVisitor(),
 		refResolver:              refResolver,
 		evalArgNames:             []string{},
 		evalArgValues:            []interface{}{},
 		evalExportedVars:         []string{},
 	}
+	jev.SetVisitor(jev)
+	return jev
 }
 
 // CreateReturnStmt creates a return statement
@@ -24,12 +24,12 @@ type Segment struct {
 
 // SourceMap represents a source map
 type SourceMap struct {
-	Version        int
-	File           string
-	SourceRoot     string
-	Sources        []string
-	SourcesContent []*string // null is represented as nil
-	Mappings       string
+	Version        int       `json:"version"`
+	File           string    `json:"file"`
+	SourceRoot     string    `json:"sourceRoot"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent"` // null is represented as nil
+	Mappings       string    `json:"mappings"`
 }
 
 // SourceMapGenerator generates source maps
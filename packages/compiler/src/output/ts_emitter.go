@@ -0,0 +1,264 @@
+package output
+
+import (
+	"fmt"
+)
+
+// TypeScriptEmitter prints output statements as a TypeScript module, with the types of the output
+// AST as annotations.
+type TypeScriptEmitter struct{}
+
+// EmitStatements prints the statements of a generated file. Like JavaScriptEmitter, external
+// references are imported through namespace imports which are emitted after the preamble.
+func (TypeScriptEmitter) EmitStatements(genFilePath string, stmts []OutputStatement, preamble string) string {
+	converter := NewTsEmitterVisitor()
+	ctx := CreateRootEmitterVisitorContext()
+	converter.VisitAllStatements(stmts, ctx)
+	return moduleSource(preamble, converter.ImportsWithPrefixes(), ctx.ToSource())
+}
+
+// TsEmitterVisitor emits TypeScript code. Declarations, parameters and static fields are
+// annotated with their types; references to other modules are printed through namespace
+// prefixes, in types as well as in values.
+type TsEmitterVisitor struct {
+	*AbstractEmitterVisitor
+	*ImportManager
+}
+
+// NewTsEmitterVisitor creates a new TsEmitterVisitor with its own ImportManager.
+func NewTsEmitterVisitor() *TsEmitterVisitor {
+	v := &TsEmitterVisitor{
+		AbstractEmitterVisitor: NewAbstractEmitterVisitor(false),
+		ImportManager:          NewImportManager(),
+	}
+	v.SetVisitor(v)
+	return v
+}
+
+// EmitStaticField prints a static property of a class, e.g.
+// `static ɵcmp: i0.ɵɵComponentDeclaration<...> = i0.ɵɵdefineComponent({...});`.
+func (v *TsEmitterVisitor) EmitStaticField(name string, typ Type, initializer OutputExpression, ctx *EmitterVisitorContext) {
+	ctx.Print(nil, "static "+name, false)
+	v.printColonType(typ, ctx, "any")
+	if initializer != nil {
+		ctx.Print(nil, " = ", false)
+		initializer.VisitExpression(v, ctx)
+	}
+	ctx.Println(nil, ";")
+}
+
+// VisitType prints a type, or defaultType when it is nil or inferred.
+func (v *TsEmitterVisitor) VisitType(typ Type, ctx *EmitterVisitorContext, defaultType string) {
+	if typ == nil || typ == InferredType {
+		ctx.Print(nil, defaultType, false)
+		return
+	}
+	typ.VisitType(v, ctx)
+}
+
+// printColonType prints the annotation of a declaration. Inferred types have none.
+func (v *TsEmitterVisitor) printColonType(typ Type, ctx *EmitterVisitorContext, defaultType string) {
+	if typ == InferredType || (typ == nil && defaultType == "") {
+		return
+	}
+	ctx.Print(nil, ": ", false)
+	v.VisitType(typ, ctx, defaultType)
+}
+
+// VisitBuiltinType visits a builtin type
+func (v *TsEmitterVisitor) VisitBuiltinType(typ *BuiltinType, context interface{}) interface{} {
+	ctx := v.getContext(context)
+	var name string
+	switch typ.Name {
+	case BuiltinTypeNameBool:
+		name = "boolean"
+	case BuiltinTypeNameDynamic:
+		name = "any"
+	case BuiltinTypeNameFunction:
+		name = "Function"
+	case BuiltinTypeNameInt, BuiltinTypeNameNumber:
+		name = "number"
+	case BuiltinTypeNameString:
+		name = "string"
+	case BuiltinTypeNameNone:
+		name = "never"
+	default:
+		panic(fmt.Sprintf("Unsupported builtin type %d", typ.Name))
+	}
+	ctx.Print(nil, name, false)
+	return nil
+}
+
+// VisitExpressionType visits an expression type, e.g. `i0.ɵɵFactoryDeclaration<Foo, never>`.
+// Expressions print as the types they denote: literals as literal types, arrays as tuples and
+// maps as object types.
+func (v *TsEmitterVisitor) VisitExpressionType(typ *ExpressionType, context interface{}) interface{} {
+	ctx := v.getContext(context)
+	typ.Value.VisitExpression(v, ctx)
+	v.printTypeParams(typ.TypeParams, ctx)
+	return nil
+}
+
+// VisitArrayType visits an array type
+func (v *TsEmitterVisitor) VisitArrayType(typ *ArrayType, context interface{}) interface{} {
+	ctx := v.getContext(context)
+	v.VisitType(typ.Of, ctx, "any")
+	ctx.Print(nil, "[]", false)
+	return nil
+}
+
+// VisitMapType visits a map type
+func (v *TsEmitterVisitor) VisitMapType(typ *MapType, context interface{}) interface{} {
+	ctx := v.getContext(context)
+	ctx.Print(nil, "{[key: string]:", false)
+	var valueType Type
+	if typ.ValueType != nil {
+		valueType = *typ.ValueType
+	}
+	v.VisitType(valueType, ctx, "any")
+	ctx.Print(nil, "}", false)
+	return nil
+}
+
+// VisitTransplantedType visits a transplanted type. Only types given as source text can be
+// printed.
+func (v *TsEmitterVisitor) VisitTransplantedType(typ *TransplantedType, context interface{}) interface{} {
+	ctx := v.getContext(context)
+	code, ok := typ.Type.(string)
+	if !ok {
+		panic("Cannot emit a TransplantedType which is not source text.")
+	}
+	ctx.Print(nil, code, false)
+	return nil
+}
+
+// printTypeParams prints type arguments in angle brackets, if any.
+func (v *TsEmitterVisitor) printTypeParams(params []Type, ctx *EmitterVisitorContext) {
+	if len(params) == 0 {
+		return
+	}
+	ctx.Print(nil, "<", false)
+	for i, param := range params {
+		if i > 0 {
+			ctx.Print(nil, ", ", false)
+		}
+		v.VisitType(param, ctx, "any")
+	}
+	ctx.Print(nil, ">", false)
+}
+
+// VisitExternalExpr visits an external expression
+func (v *TsEmitterVisitor) VisitExternalExpr(ast *ExternalExpr, context interface{}) interface{} {
+	ctx := v.getContext(context)
+	v.printReference(ast, ctx)
+	v.printTypeParams(ast.TypeParams, ctx)
+	return nil
+}
+
+// VisitWrappedNodeExpr visits a wrapped node expression. Strings are source code which is
+// copied verbatim.
+func (v *TsEmitterVisitor) VisitWrappedNodeExpr(ast *WrappedNodeExpr, context interface{}) interface{} {
+	ctx := v.getContext(context)
+	code, ok := ast.Node.(string)
+	if !ok {
+		panic("Cannot emit a WrappedNodeExpr in TypeScript.")
+	}
+	ctx.Print(ast, code, false)
+	return nil
+}
+
+// VisitDeclareVarStmt visits a declare variable statement
+func (v *TsEmitterVisitor) VisitDeclareVarStmt(stmt *DeclareVarStmt, context interface{}) interface{} {
+	ctx := v.getContext(context)
+	v.PrintLeadingComments(stmt, ctx)
+	if stmt.GetModifiers()&StmtModifierExported != 0 {
+		ctx.Print(stmt, "export ", false)
+	}
+	if stmt.GetModifiers()&StmtModifierFinal != 0 {
+		ctx.Print(stmt, "const ", false)
+	} else {
+		ctx.Print(stmt, "let ", false)
+	}
+	ctx.Print(stmt, stmt.Name, false)
+	v.printColonType(stmt.Type, ctx, "")
+	if stmt.Value != nil {
+		ctx.Print(stmt, " = ", false)
+		stmt.Value.VisitExpression(v, ctx)
+	}
+	ctx.Println(stmt, ";")
+	return nil
+}
+
+// VisitDeclareFunctionStmt visits a declare function statement. The return type is only
+// printed when known.
+func (v *TsEmitterVisitor) VisitDeclareFunctionStmt(stmt *DeclareFunctionStmt, context interface{}) interface{} {
+	ctx := v.getContext(context)
+	if stmt.GetModifiers()&StmtModifierExported != 0 {
+		ctx.Print(stmt, "export ", false)
+	}
+	ctx.Print(stmt, fmt.Sprintf("function %s(", stmt.Name), false)
+	v.visitParams(stmt.Params, ctx)
+	ctx.Print(stmt, ")", false)
+	v.printColonType(stmt.Type, ctx, "")
+	ctx.Println(stmt, " {")
+	ctx.IncIndent()
+	v.VisitAllStatements(stmt.Statements, ctx)
+	ctx.DecIndent()
+	ctx.Println(stmt, "}")
+	return nil
+}
+
+// VisitFunctionExpr visits a function expression
+func (v *TsEmitterVisitor) VisitFunctionExpr(ast *FunctionExpr, context interface{}) interface{} {
+	ctx := v.getContext(context)
+	namePart := ""
+	if ast.Name != nil {
+		namePart = " " + *ast.Name
+	}
+	ctx.Print(ast, fmt.Sprintf("function%s(", namePart), false)
+	v.visitParams(ast.Params, ctx)
+	ctx.Print(ast, ")", false)
+	v.printColonType(ast.GetType(), ctx, "")
+	ctx.Println(ast, " {")
+	ctx.IncIndent()
+	v.VisitAllStatements(ast.Statements, ctx)
+	ctx.DecIndent()
+	ctx.Print(ast, "}", false)
+	return nil
+}
+
+// VisitArrowFunctionExpr visits an arrow function expression
+func (v *TsEmitterVisitor) VisitArrowFunctionExpr(ast *ArrowFunctionExpr, context interface{}) interface{} {
+	ctx := v.getContext(context)
+	ctx.Print(ast, "(", false)
+	v.visitParams(ast.Params, ctx)
+	ctx.Print(ast, ") =>", false)
+
+	switch body := ast.Body.(type) {
+	case []OutputStatement:
+		ctx.Println(ast, "{")
+		ctx.IncIndent()
+		v.VisitAllStatements(body, ctx)
+		ctx.DecIndent()
+		ctx.Print(ast, "}", false)
+	case OutputExpression:
+		_, isObjectLiteral := body.(*LiteralMapExpr)
+		if isObjectLiteral {
+			ctx.Print(ast, "(", false)
+		}
+		body.VisitExpression(v, ctx)
+		if isObjectLiteral {
+			ctx.Print(ast, ")", false)
+		}
+	}
+	return nil
+}
+
+// visitParams prints function parameters with their types. Parameters without a type are
+// `any`, so that the output compiles with `noImplicitAny`.
+func (v *TsEmitterVisitor) visitParams(params []*FnParam, ctx *EmitterVisitorContext) {
+	v.VisitAllObjects(func(param *FnParam) {
+		ctx.Print(nil, param.Name, false)
+		v.printColonType(param.Type, ctx, "any")
+	}, params, ctx, ",")
+}
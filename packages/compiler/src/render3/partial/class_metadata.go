@@ -1,6 +1,7 @@
 package partial
 
 import (
+	"ngc-go/packages/compiler/src/core"
 	"ngc-go/packages/compiler/src/output"
 	"ngc-go/packages/compiler/src/render3/r3_identifiers"
 	"ngc-go/packages/compiler/src/render3/view"
@@ -88,7 +89,7 @@ const MINIMUM_PARTIAL_LINKER_DEFER_SUPPORT_VERSION = "18.0.0"
 func CompileDeclareClassMetadata(metadata R3ClassMetadata) output.OutputExpression {
 	definitionMap := view.NewDefinitionMap()
 	definitionMap.Set("minVersion", output.NewLiteralExpr(MINIMUM_PARTIAL_LINKER_VERSION, output.InferredType, nil))
-	definitionMap.Set("version", output.NewLiteralExpr("0.0.0-PLACEHOLDER", output.InferredType, nil))
+	definitionMap.Set("version", output.NewLiteralExpr(core.VERSION.Full, output.InferredType, nil))
 	definitionMap.Set("ngImport", output.NewExternalExpr(r3_identifiers.Core, nil, nil, nil))
 	definitionMap.Set("type", metadata.Type)
 	definitionMap.Set("decorators", metadata.Decorators)
@@ -136,7 +137,7 @@ func CompileComponentDeclareClassMetadata(
 	}
 
 	definitionMap.Set("minVersion", output.NewLiteralExpr(MINIMUM_PARTIAL_LINKER_DEFER_SUPPORT_VERSION, output.InferredType, nil))
-	definitionMap.Set("version", output.NewLiteralExpr("0.0.0-PLACEHOLDER", output.InferredType, nil))
+	definitionMap.Set("version", output.NewLiteralExpr(core.VERSION.Full, output.InferredType, nil))
 	definitionMap.Set("ngImport", output.NewExternalExpr(r3_identifiers.Core, nil, nil, nil))
 	definitionMap.Set("type", metadata.Type)
 	definitionMap.Set("resolveDeferredDeps", CompileComponentMetadataAsyncResolver(dependencies))
@@ -128,7 +128,10 @@ func CreateComponentDefinitionMap(
 		resolvers := []output.OutputExpression{}
 		hasResolvers := false
 
-		for _, deps := range meta.Defer.Blocks {
+		// Blocks is a map, so take the order of the blocks from the template.
+		blocks := view.NewR3TargetBinder(nil).Bind(&view.Target{Template: meta.Template.Nodes}).GetDeferBlocks()
+		for _, block := range blocks {
+			deps := meta.Defer.Blocks[block]
 			// Note: we need to push a `null` even if there are no dependencies, because matching of
 			// defer resolver functions to defer blocks happens by index and not adding an array
 			// entry for a block can throw off the blocks coming after it.
@@ -218,12 +221,7 @@ func compileUsedDependenciesMetadata(
 		panic(errors.New("Unsupported emit mode"))
 	}
 
-	// Convert []R3TemplateDependency to []interface{} for ToOptionalLiteralArray
-	decls := make([]interface{}, len(meta.Declarations))
-	for i, decl := range meta.Declarations {
-		decls[i] = decl
-	}
-	return ToOptionalLiteralArray(decls, func(decl interface{}) output.OutputExpression {
+	return ToOptionalLiteralArray(meta.Declarations, func(decl view.R3TemplateDependencyMetadata) output.OutputExpression {
 		switch d := decl.(type) {
 		case view.R3DirectiveDependencyMetadata:
 			dirDecl := d
@@ -1,6 +1,8 @@
 package partial
 
 import (
+	"ngc-go/packages/compiler/src/core"
+	"sort"
 	"strings"
 
 	"ngc-go/packages/compiler/src/output"
@@ -41,7 +43,7 @@ func CreateDirectiveDefinitionMap(
 	minVersion := getMinimumVersionForPartialOutput(meta)
 
 	definitionMap.Set("minVersion", output.NewLiteralExpr(minVersion, output.InferredType, nil))
-	definitionMap.Set("version", output.NewLiteralExpr("0.0.0-PLACEHOLDER", output.InferredType, nil))
+	definitionMap.Set("version", output.NewLiteralExpr(core.VERSION.Full, output.InferredType, nil))
 
 	// e.g. `type: MyDirective`
 	definitionMap.Set("type", meta.Type.Value)
@@ -307,7 +309,8 @@ func createInputsPartialMetadata(inputs map[string]view.R3InputMetadata) output.
 	}
 
 	keys := make([]*output.LiteralMapEntry, 0, len(inputs))
-	for declaredName, value := range inputs {
+	for _, declaredName := range sortedKeys(inputs) {
+		value := inputs[declaredName]
 		valueMap := []*output.LiteralMapEntry{
 			output.NewLiteralMapEntry("classPropertyName", view.AsLiteral(value.ClassPropertyName), false),
 			output.NewLiteralMapEntry("publicName", view.AsLiteral(value.BindingPropertyName), false),
@@ -354,7 +357,8 @@ func legacyInputsPartialMetadata(inputs map[string]view.R3InputMetadata) output.
 	}
 
 	keys := make([]*output.LiteralMapEntry, 0, len(inputs))
-	for declaredName, value := range inputs {
+	for _, declaredName := range sortedKeys(inputs) {
+		value := inputs[declaredName]
 		publicName := value.BindingPropertyName
 		differentDeclaringName := publicName != declaredName
 		var result output.OutputExpression
@@ -477,8 +481,16 @@ func stringArrayAsType(arr []string) output.Type {
 
 // getInputsTypeExpression creates a type expression for inputs
 func getInputsTypeExpression(meta *view.R3DirectiveMetadata) output.OutputExpression {
+	// Sort the keys so that the output is deterministic.
+	keys := make([]string, 0, len(meta.Inputs))
+	for key := range meta.Inputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
 	entries := []*output.LiteralMapEntry{}
-	for key, value := range meta.Inputs {
+	for _, key := range keys {
+		value := meta.Inputs[key]
 		values := []*output.LiteralMapEntry{
 			output.NewLiteralMapEntry("alias", output.NewLiteralExpr(value.BindingPropertyName, output.InferredType, nil), true),
 			output.NewLiteralMapEntry("required", output.NewLiteralExpr(value.Required, output.InferredType, nil), true),
@@ -508,8 +520,15 @@ func convertStringMap(m map[string]string) map[string]interface{} {
 
 // stringMapAsLiteralExpression creates a literal expression from a string map
 func stringMapAsLiteralExpression(m map[string]interface{}) output.OutputExpression {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
 	mapValues := []*output.LiteralMapEntry{}
-	for key, value := range m {
+	for _, key := range keys {
+		value := m[key]
 		var literalValue output.OutputExpression
 		if str, ok := value.(string); ok {
 			literalValue = output.NewLiteralExpr(str, output.InferredType, nil)
@@ -1,6 +1,7 @@
 package partial
 
 import (
+	"ngc-go/packages/compiler/src/core"
 	"ngc-go/packages/compiler/src/facade"
 	"ngc-go/packages/compiler/src/output"
 	"ngc-go/packages/compiler/src/render3"
@@ -20,7 +21,7 @@ const MINIMUM_PARTIAL_LINKER_VERSION_FACTORY = "12.0.0"
 func CompileDeclareFactoryFunction(meta render3.R3ConstructorFactoryMetadata) render3.R3CompiledExpression {
 	definitionMap := view.NewDefinitionMap()
 	definitionMap.Set("minVersion", output.NewLiteralExpr(MINIMUM_PARTIAL_LINKER_VERSION_FACTORY, output.InferredType, nil))
-	definitionMap.Set("version", output.NewLiteralExpr("0.0.0-PLACEHOLDER", output.InferredType, nil))
+	definitionMap.Set("version", output.NewLiteralExpr(core.VERSION.Full, output.InferredType, nil))
 	definitionMap.Set("ngImport", output.NewExternalExpr(r3_identifiers.Core, nil, nil, nil))
 	definitionMap.Set("type", meta.Type.Value)
 	definitionMap.Set("deps", CompileDependencies(meta.Deps))
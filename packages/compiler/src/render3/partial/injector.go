@@ -1,6 +1,7 @@
 package partial
 
 import (
+	"ngc-go/packages/compiler/src/core"
 	"ngc-go/packages/compiler/src/output"
 	"ngc-go/packages/compiler/src/render3"
 	r3_identifiers "ngc-go/packages/compiler/src/render3/r3_identifiers"
@@ -43,7 +44,7 @@ func createInjectorDefinitionMap(
 	definitionMap := view.NewDefinitionMap()
 
 	definitionMap.Set("minVersion", output.NewLiteralExpr(MINIMUM_PARTIAL_LINKER_VERSION_INJECTOR, output.InferredType, nil))
-	definitionMap.Set("version", output.NewLiteralExpr("0.0.0-PLACEHOLDER", output.InferredType, nil))
+	definitionMap.Set("version", output.NewLiteralExpr(core.VERSION.Full, output.InferredType, nil))
 	definitionMap.Set("ngImport", output.NewExternalExpr(r3_identifiers.Core, nil, nil, nil))
 
 	definitionMap.Set("type", meta.Type.Value)
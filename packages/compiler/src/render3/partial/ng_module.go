@@ -2,6 +2,7 @@ package partial
 
 import (
 	"errors"
+	"ngc-go/packages/compiler/src/core"
 
 	"ngc-go/packages/compiler/src/output"
 	"ngc-go/packages/compiler/src/render3"
@@ -52,7 +53,7 @@ func createNgModuleDefinitionMap(
 	}
 
 	definitionMap.Set("minVersion", output.NewLiteralExpr(MINIMUM_PARTIAL_LINKER_VERSION_NGMODULE, output.InferredType, nil))
-	definitionMap.Set("version", output.NewLiteralExpr("0.0.0-PLACEHOLDER", output.InferredType, nil))
+	definitionMap.Set("version", output.NewLiteralExpr(core.VERSION.Full, output.InferredType, nil))
 	definitionMap.Set("ngImport", output.NewExternalExpr(r3_identifiers.Core, nil, nil, nil))
 	definitionMap.Set("type", common.Type.Value)
 
@@ -1,6 +1,8 @@
 package partial
 
 import (
+	"sort"
+
 	"ngc-go/packages/compiler/src/output"
 	"ngc-go/packages/compiler/src/render3"
 	"ngc-go/packages/compiler/src/render3/view"
@@ -16,7 +18,7 @@ import (
 func ToOptionalLiteralArray[T any](
 	values []T,
 	mapper func(value T) output.OutputExpression,
-) *output.LiteralArrayExpr {
+) output.OutputExpression {
 	if values == nil || len(values) == 0 {
 		return nil
 	}
@@ -33,17 +35,17 @@ func ToOptionalLiteralArray[T any](
 // @param object The object to transfer into an object literal expression.
 // @param mapper The logic to use for creating an expression for the object's values.
 // @returns An object literal expression representing `object`, or null if `object` does not have
-// any keys.
+// any keys. Keys are emitted in sorted order so that the output is deterministic.
 func ToOptionalLiteralMap[T any](
 	object map[string]T,
 	mapper func(value T) output.OutputExpression,
-) *output.LiteralMapExpr {
+) output.OutputExpression {
 	if object == nil || len(object) == 0 {
 		return nil
 	}
 	entries := make([]*output.LiteralMapEntry, 0, len(object))
-	for key, value := range object {
-		entries = append(entries, output.NewLiteralMapEntry(key, mapper(value), true))
+	for _, key := range sortedKeys(object) {
+		entries = append(entries, output.NewLiteralMapEntry(key, mapper(object[key]), true))
 	}
 	if len(entries) > 0 {
 		return output.NewLiteralMapExpr(entries, nil, nil)
@@ -92,3 +94,13 @@ func CompileDependency(dep render3.R3DependencyMetadata) *output.LiteralMapExpr
 	}
 	return depMeta.ToLiteralMap()
 }
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys[T any](object map[string]T) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1288,6 +1288,11 @@ func (t *Template) GetInputs() []*BoundAttribute {
 	return t.Inputs
 }
 
+// GetOutputs returns the bound events (outputs)
+func (t *Template) GetOutputs() []*BoundEvent {
+	return t.Outputs
+}
+
 // GetTemplateAttrs returns the template attributes (for backward compatibility)
 func (t *Template) GetTemplateAttrs() []interface{} {
 	return t.TemplateAttrs
@@ -0,0 +1,96 @@
+package render3_class_metadata_compiler
+
+import (
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/partial"
+	"ngc-go/packages/compiler/src/render3/r3_identifiers"
+)
+
+// CompileClassMetadata compiles the dev-mode only call which attaches the original Angular
+// decorators of a class to it:
+//
+//	(() => { (typeof ngDevMode === "undefined" || ngDevMode) && i0.ɵsetClassMetadata(...); })()
+func CompileClassMetadata(metadata partial.R3ClassMetadata) output.OutputExpression {
+	fnCall := internalCompileClassMetadata(metadata)
+	return output.NewInvokeFunctionExpr(
+		output.NewArrowFunctionExpr(
+			[]*output.FnParam{},
+			[]output.OutputStatement{devOnlyStatement(fnCall)},
+			nil,
+			nil,
+		),
+		[]output.OutputExpression{},
+		nil,
+		nil,
+		false,
+	)
+}
+
+// CompileSetClassMetadataAsync compiles the variant of CompileClassMetadata for components with
+// deferred dependencies. The metadata is only attached once dependencyResolverFn has loaded the
+// dependencies, which are passed to a wrapper function under the given parameter names.
+func CompileSetClassMetadataAsync(
+	metadata partial.R3ClassMetadata,
+	dependencyResolverFn output.OutputExpression,
+	wrapperParams []string,
+) output.OutputExpression {
+	params := make([]*output.FnParam, len(wrapperParams))
+	for i, name := range wrapperParams {
+		params[i] = output.NewFnParam(name, output.DynamicType)
+	}
+
+	setClassMetaWrapper := output.NewArrowFunctionExpr(
+		params,
+		[]output.OutputStatement{output.NewExpressionStatement(internalCompileClassMetadata(metadata), nil, nil)},
+		nil,
+		nil,
+	)
+
+	setClassMetaAsync := output.NewInvokeFunctionExpr(
+		output.NewExternalExpr(r3_identifiers.SetClassMetadataAsync, nil, nil, nil),
+		[]output.OutputExpression{metadata.Type, dependencyResolverFn, setClassMetaWrapper},
+		nil,
+		nil,
+		false,
+	)
+
+	return output.NewInvokeFunctionExpr(
+		output.NewArrowFunctionExpr(
+			[]*output.FnParam{},
+			[]output.OutputStatement{devOnlyStatement(setClassMetaAsync)},
+			nil,
+			nil,
+		),
+		[]output.OutputExpression{},
+		nil,
+		nil,
+		false,
+	)
+}
+
+// internalCompileClassMetadata compiles the `ɵsetClassMetadata` call itself.
+func internalCompileClassMetadata(metadata partial.R3ClassMetadata) output.OutputExpression {
+	orNull := func(expr *output.OutputExpression) output.OutputExpression {
+		if expr == nil {
+			return output.NewLiteralExpr(nil, output.InferredType, nil)
+		}
+		return *expr
+	}
+	return output.NewInvokeFunctionExpr(
+		output.NewExternalExpr(r3_identifiers.SetClassMetadata, nil, nil, nil),
+		[]output.OutputExpression{
+			metadata.Type,
+			metadata.Decorators,
+			orNull(metadata.CtorParameters),
+			orNull(metadata.PropDecorators),
+		},
+		nil,
+		nil,
+		false,
+	)
+}
+
+func devOnlyStatement(expr output.OutputExpression) output.OutputStatement {
+	return output.NewExpressionStatement(render3.DevOnlyGuardedExpression(expr), nil, nil)
+}
@@ -82,7 +82,7 @@ func CreateIfBlock(
 			ast.StartSourceSpan,
 			ast.EndSourceSpan,
 			ast.NameSpan,
-			ast.I18n,
+			ast.I18n(),
 		))
 	}
 
@@ -99,7 +99,7 @@ func CreateIfBlock(
 					block.StartSourceSpan,
 					block.EndSourceSpan,
 					block.NameSpan,
-					block.I18n,
+					block.I18n(),
 				))
 			}
 		} else if block.Name == "else" {
@@ -112,7 +112,7 @@ func CreateIfBlock(
 				block.StartSourceSpan,
 				block.EndSourceSpan,
 				block.NameSpan,
-				block.I18n,
+				block.I18n(),
 			))
 		}
 	}
@@ -172,7 +172,7 @@ func CreateForLoop(
 					block.StartSourceSpan,
 					block.EndSourceSpan,
 					block.NameSpan,
-					block.I18n,
+					block.I18n(),
 				)
 			}
 		} else {
@@ -207,7 +207,7 @@ func CreateForLoop(
 				ast.StartSourceSpan,
 				endSpan,
 				ast.NameSpan,
-				ast.I18n,
+				ast.I18n(),
 			)
 		}
 	}
@@ -264,7 +264,7 @@ func CreateSwitchBlock(
 			block.StartSourceSpan,
 			block.EndSourceSpan,
 			block.NameSpan,
-			block.I18n,
+			block.I18n(),
 		)
 
 		if expr == nil {
@@ -91,7 +91,7 @@ func CreateDeferredBlock(
 		ast.SourceSpan(),
 		ast.StartSourceSpan,
 		lastEndSourceSpan,
-		ast.I18n,
+		ast.I18n(),
 	)
 
 	return CreateDeferredBlockResult{Node: node, Errors: errors}
@@ -194,7 +194,7 @@ func parsePlaceholderBlock(ast *ml_parser.Block, visitor ml_parser.Visitor) *Def
 		ast.SourceSpan(),
 		ast.StartSourceSpan,
 		ast.EndSourceSpan,
-		ast.I18n,
+		ast.I18n(),
 	)
 }
 
@@ -254,7 +254,7 @@ func parseLoadingBlock(ast *ml_parser.Block, visitor ml_parser.Visitor) *Deferre
 		ast.SourceSpan(),
 		ast.StartSourceSpan,
 		ast.EndSourceSpan,
-		ast.I18n,
+		ast.I18n(),
 	)
 }
 
@@ -271,7 +271,7 @@ func parseErrorBlock(ast *ml_parser.Block, visitor ml_parser.Visitor) *DeferredB
 		ast.SourceSpan(),
 		ast.StartSourceSpan,
 		ast.EndSourceSpan,
-		ast.I18n,
+		ast.I18n(),
 	)
 }
 
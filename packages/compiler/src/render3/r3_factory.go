@@ -516,14 +516,8 @@ func IsExpressionFactoryMetadata(meta interface{}) bool {
 // getInjectFn gets the inject function for the given target
 func getInjectFn(target facade.FactoryTarget) output.ExternalReference {
 	switch target {
-	case facade.FactoryTargetComponent:
-	case facade.FactoryTargetDirective:
-	case facade.FactoryTargetPipe:
+	case facade.FactoryTargetComponent, facade.FactoryTargetDirective, facade.FactoryTargetPipe:
 		return *r3_identifiers.DirectiveInject
-	case facade.FactoryTargetNgModule:
-	case facade.FactoryTargetInjectable:
-	default:
-		return *r3_identifiers.Inject
 	}
 	return *r3_identifiers.Inject
 }
@@ -247,8 +247,7 @@ func CompileHmrInitializer(meta R3HmrMetadata) output.OutputExpression {
 		false,
 	)
 
-	// Encode the ID
-	idValue := url.QueryEscape(meta.FilePath + "@" + meta.ClassName)
+	idValue := HmrComponentID(meta)
 
 	return output.NewInvokeFunctionExpr(
 		output.NewArrowFunctionExpr(
@@ -296,6 +295,12 @@ func CompileHmrInitializer(meta R3HmrMetadata) output.OutputExpression {
 	)
 }
 
+// HmrComponentID returns the ID identifying a component in HMR update notifications and in the
+// URL of its update module.
+func HmrComponentID(meta R3HmrMetadata) string {
+	return url.QueryEscape(meta.FilePath + "@" + meta.ClassName)
+}
+
 // R3HmrDefinition represents a compiled definition for a class
 type R3HmrDefinition struct {
 	Name        string
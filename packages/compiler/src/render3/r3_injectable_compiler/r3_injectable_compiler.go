@@ -0,0 +1,174 @@
+package render3_injectable_compiler
+
+import (
+	"ngc-go/packages/compiler/src/facade"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/partial"
+	"ngc-go/packages/compiler/src/render3/r3_identifiers"
+	"ngc-go/packages/compiler/src/render3/view"
+)
+
+// CompileInjectable compiles an injectable definition (`ɵɵdefineInjectable()`) from its metadata.
+// When resolveForwardRefs is set, a `useClass` which is not the injectable itself is unwrapped
+// with `resolveForwardRef()` before its factory is used.
+func CompileInjectable(meta partial.R3InjectableMetadata, resolveForwardRefs bool) render3.R3CompiledExpression {
+	var result render3.R3CompiledExpression
+
+	factoryMeta := render3.R3ConstructorFactoryMetadata{
+		Name:              meta.Name,
+		Type:              meta.Type,
+		TypeArgumentCount: meta.TypeArgumentCount,
+		Deps:              []render3.R3DependencyMetadata{},
+		Target:            facade.FactoryTargetInjectable,
+	}
+
+	switch {
+	case meta.UseClass != nil:
+		// meta.UseClass has two modes of operation. Either deps are specified, in which case `new` is
+		// used to instantiate the class with dependencies injected, or deps are not specified and
+		// the factory of the class is used to instantiate it.
+		//
+		// A special case exists for useClass: Type where Type is the injectable type itself and no
+		// deps are specified, in which case 'useClass' is effectively ignored.
+		useClassOnSelf := meta.UseClass.Expression.IsEquivalent(meta.Type.Value)
+		if meta.Deps != nil {
+			result = render3.CompileFactoryFunction(&render3.R3DelegatedFnOrClassMetadata{
+				R3ConstructorFactoryMetadata: factoryMeta,
+				Delegate:                     meta.UseClass.Expression,
+				DelegateDeps:                 *meta.Deps,
+				DelegateType:                 render3.R3FactoryDelegateTypeClass,
+			})
+		} else if useClassOnSelf {
+			result = render3.CompileFactoryFunction(&factoryMeta)
+		} else {
+			result = render3.R3CompiledExpression{
+				Expression: delegateToFactory(meta.Type.Value, meta.UseClass.Expression, resolveForwardRefs),
+			}
+		}
+	case meta.UseFactory != nil:
+		if meta.Deps != nil {
+			result = render3.CompileFactoryFunction(&render3.R3DelegatedFnOrClassMetadata{
+				R3ConstructorFactoryMetadata: factoryMeta,
+				Delegate:                     *meta.UseFactory,
+				DelegateDeps:                 *meta.Deps,
+				DelegateType:                 render3.R3FactoryDelegateTypeFunction,
+			})
+		} else {
+			result = render3.R3CompiledExpression{
+				Expression: output.NewArrowFunctionExpr(
+					[]*output.FnParam{},
+					output.NewInvokeFunctionExpr(*meta.UseFactory, []output.OutputExpression{}, nil, nil, false),
+					nil,
+					nil,
+				),
+			}
+		}
+	case meta.UseValue != nil:
+		// Note: it's safe to use `meta.UseValue` instead of the `UseValue` of the provider because
+		// the value is wrapped in a factory function and so won't be evaluated eagerly.
+		result = render3.CompileFactoryFunction(&render3.R3ExpressionFactoryMetadata{
+			R3ConstructorFactoryMetadata: factoryMeta,
+			Expression:                   meta.UseValue.Expression,
+		})
+	case meta.UseExisting != nil:
+		// useExisting is an `inject` call on the existing token.
+		result = render3.CompileFactoryFunction(&render3.R3ExpressionFactoryMetadata{
+			R3ConstructorFactoryMetadata: factoryMeta,
+			Expression: output.NewInvokeFunctionExpr(
+				output.NewExternalExpr(r3_identifiers.Inject, nil, nil, nil),
+				[]output.OutputExpression{meta.UseExisting.Expression},
+				nil,
+				nil,
+				false,
+			),
+		})
+	default:
+		result = render3.R3CompiledExpression{
+			Expression: delegateToFactory(meta.Type.Value, meta.Type.Value, resolveForwardRefs),
+		}
+	}
+
+	token := meta.Type.Value
+
+	injectableProps := view.NewDefinitionMap()
+	injectableProps.Set("token", token)
+	injectableProps.Set("factory", result.Expression)
+
+	// Only generate providedIn property if it has a non-null value
+	if meta.ProvidedIn.Expression != nil {
+		if literal, ok := meta.ProvidedIn.Expression.(*output.LiteralExpr); !ok || literal.Value != nil {
+			injectableProps.Set("providedIn", render3.ConvertFromMaybeForwardRefExpression(meta.ProvidedIn))
+		}
+	}
+
+	expression := output.NewInvokeFunctionExpr(
+		output.NewExternalExpr(r3_identifiers.DefineInjectable, nil, nil, nil),
+		[]output.OutputExpression{injectableProps.ToLiteralMap()},
+		nil,  // typ
+		nil,  // sourceSpan
+		true, // pure
+	)
+
+	statements := result.Statements
+	if statements == nil {
+		statements = []output.OutputStatement{}
+	}
+	return render3.R3CompiledExpression{
+		Expression: expression,
+		Type:       CreateInjectableType(meta),
+		Statements: statements,
+	}
+}
+
+// CreateInjectableType creates the type of an injectable definition.
+func CreateInjectableType(meta partial.R3InjectableMetadata) output.Type {
+	return output.NewExpressionType(
+		output.NewExternalExpr(
+			r3_identifiers.InjectableDeclaration,
+			nil,
+			[]output.Type{render3.TypeWithParameters(meta.Type.Type, meta.TypeArgumentCount)},
+			nil,
+		),
+		output.TypeModifierNone,
+		nil,
+	)
+}
+
+// delegateToFactory returns the factory of useType, called with the type being instantiated.
+func delegateToFactory(typ, useType output.OutputExpression, unwrapForwardRefs bool) output.OutputExpression {
+	if typ.IsEquivalent(useType) {
+		// useClass: Type, so skip the extra `(t) => ...`
+		return output.NewReadPropExpr(useType, "ɵfac", nil, nil)
+	}
+
+	if !unwrapForwardRefs {
+		return createFactoryFunction(useType)
+	}
+
+	unwrappedType := output.NewInvokeFunctionExpr(
+		output.NewExternalExpr(r3_identifiers.ResolveForwardRef, nil, nil, nil),
+		[]output.OutputExpression{useType},
+		nil,
+		nil,
+		false,
+	)
+	return createFactoryFunction(unwrappedType)
+}
+
+// createFactoryFunction creates `(__ngFactoryType__) => typ.ɵfac(__ngFactoryType__)`.
+func createFactoryFunction(typ output.OutputExpression) output.OutputExpression {
+	t := output.NewFnParam("__ngFactoryType__", output.DynamicType)
+	return output.NewArrowFunctionExpr(
+		[]*output.FnParam{t},
+		output.NewInvokeFunctionExpr(
+			output.NewReadPropExpr(typ, "ɵfac", nil, nil),
+			[]output.OutputExpression{output.NewReadVarExpr(t.Name, nil, nil)},
+			nil,
+			nil,
+			false,
+		),
+		nil,
+		nil,
+	)
+}
@@ -139,7 +139,7 @@ type R3ComponentMetadata struct {
 	// Information about the component's template.
 	Template R3ComponentTemplateMetadata
 
-	Declarations []R3TemplateDependency
+	Declarations []R3TemplateDependencyMetadata
 
 	// Metadata related to the deferred blocks in the component's template.
 	Defer R3ComponentDeferMetadata
@@ -190,6 +190,11 @@ type R3ComponentMetadata struct {
 	// Whether any of the component's dependencies are directives.
 	HasDirectiveDependencies bool
 
+	// Whether the template is compiled with the DOM-only instruction set although the component is
+	// not standalone. Only valid when all the directives its template could match are known, and it
+	// has no directive dependencies.
+	DomOnly bool
+
 	// The imports expression as appears on the component decorate for standalone component. This
 	// field is currently needed only for local compilation, and so in other compilation modes it may
 	// not be set. If component has empty array imports then this field is not set.
@@ -243,6 +248,17 @@ type R3TemplateDependency struct {
 	Type output.OutputExpression
 }
 
+// GetTemplateDependency returns the dependency itself.
+func (d R3TemplateDependency) GetTemplateDependency() R3TemplateDependency {
+	return d
+}
+
+// R3TemplateDependencyMetadata is a dependency of a component template: an
+// R3DirectiveDependencyMetadata, R3PipeDependencyMetadata or R3NgModuleDependencyMetadata.
+type R3TemplateDependencyMetadata interface {
+	GetTemplateDependency() R3TemplateDependency
+}
+
 // R3DirectiveDependencyMetadata contains information about a directive that is used in a component template.
 // Only the stable, public facing information of the directive is stored here.
 type R3DirectiveDependencyMetadata struct {
@@ -2,6 +2,7 @@ package compiler
 
 import (
 	"regexp"
+	"sort"
 	"strings"
 
 	"ngc-go/packages/compiler/src/constant"
@@ -51,19 +52,14 @@ func baseDirectiveFields(
 	bindingParser template_parser.BindingParser,
 ) *view.DefinitionMap {
 	definitionMap := view.NewDefinitionMap()
-	selectors := core.ParseSelectorToR3Selector(meta.Selector)
+	selectors := css.ParseSelectorToR3Selector(meta.Selector)
 
 	// e.g. `type: MyDirective`
 	definitionMap.Set("type", meta.Type.Value)
 
 	// e.g. `selectors: [['', 'someDir', '']]`
 	if len(selectors) > 0 {
-		// Convert R3CssSelectorList to []interface{} for AsLiteral
-		selectorsInterface := make([]interface{}, len(selectors))
-		for i, sel := range selectors {
-			selectorsInterface[i] = sel
-		}
-		definitionMap.Set("selectors", view.AsLiteral(selectorsInterface))
+		definitionMap.Set("selectors", view.AsLiteral(selectors))
 	}
 
 	if len(meta.Queries) > 0 {
@@ -580,6 +576,12 @@ func CompileDirectiveFromMetadata(
 	}
 }
 
+// UsesDomOnlyMode reports whether the template of a component is compiled with the DOM-only
+// instruction set (ɵɵdomElementStart, ɵɵdomProperty...), which doesn't support directives.
+func UsesDomOnlyMode(meta *view.R3ComponentMetadata) bool {
+	return (meta.IsStandalone || meta.DomOnly) && !meta.HasDirectiveDependencies
+}
+
 // CompileComponentFromMetadata compiles a component for the render3 runtime as defined by the `R3ComponentMetadata`.
 func CompileComponentFromMetadata(
 	meta *view.R3ComponentMetadata,
@@ -642,7 +644,7 @@ func CompileComponentFromMetadata(
 	}
 
 	compilationMode := compilation.TemplateCompilationModeFull
-	if meta.IsStandalone && !meta.HasDirectiveDependencies {
+	if UsesDomOnlyMode(meta) {
 		compilationMode = compilation.TemplateCompilationModeDomOnly
 	}
 
@@ -670,8 +672,11 @@ func CompileComponentFromMetadata(
 		definitionMap.Set("ngContentSelectors", tpl.ContentSelectors)
 	}
 
-	definitionMap.Set("decls", output.NewLiteralExpr(tpl.Root.Decls, output.InferredType, nil))
-	definitionMap.Set("vars", output.NewLiteralExpr(tpl.Root.Vars, output.InferredType, nil))
+	if tpl.Root.Decls == nil || tpl.Root.Vars == nil {
+		panic("AssertionError: expected the root view to have its decls and vars counted")
+	}
+	definitionMap.Set("decls", output.NewLiteralExpr(*tpl.Root.Decls, output.InferredType, nil))
+	definitionMap.Set("vars", output.NewLiteralExpr(*tpl.Root.Vars, output.InferredType, nil))
 	if len(tpl.Consts) > 0 {
 		if len(tpl.ConstsInitializers) > 0 {
 			statements := append([]output.OutputStatement{}, tpl.ConstsInitializers...)
@@ -693,7 +698,7 @@ func CompileComponentFromMetadata(
 	if meta.DeclarationListEmitMode != view.DeclarationListEmitModeRuntimeResolved && len(meta.Declarations) > 0 {
 		declTypes := make([]output.OutputExpression, len(meta.Declarations))
 		for i, decl := range meta.Declarations {
-			declTypes[i] = decl.Type
+			declTypes[i] = decl.GetTemplateDependency().Type
 		}
 		definitionMap.Set(
 			"dependencies",
@@ -841,8 +846,15 @@ func stringAsType(str string) output.Type {
 
 // stringMapAsLiteralExpression creates a literal map expression from a string map
 func stringMapAsLiteralExpression(m map[string]interface{}) *output.LiteralMapExpr {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
 	mapValues := []*output.LiteralMapEntry{}
-	for key, value := range m {
+	for _, key := range keys {
+		value := m[key]
 		var literalValue output.OutputExpression
 		if arr, ok := value.([]string); ok && len(arr) > 0 {
 			literalValue = output.NewLiteralExpr(arr[0], output.InferredType, nil)
@@ -927,8 +939,16 @@ func convertStringMap(m map[string]string) map[string]interface{} {
 
 // getInputsTypeExpression creates a type expression for inputs
 func getInputsTypeExpression(meta *view.R3DirectiveMetadata) output.OutputExpression {
+	// Sort the keys so that the output is deterministic.
+	keys := make([]string, 0, len(meta.Inputs))
+	for key := range meta.Inputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
 	entries := []*output.LiteralMapEntry{}
-	for key, value := range meta.Inputs {
+	for _, key := range keys {
+		value := meta.Inputs[key]
 		values := []*output.LiteralMapEntry{
 			output.NewLiteralMapEntry("alias", output.NewLiteralExpr(value.BindingPropertyName, output.InferredType, nil), true),
 			output.NewLiteralMapEntry("required", output.NewLiteralExpr(value.Required, output.InferredType, nil), true),
@@ -1015,6 +1035,9 @@ func createHostBindingsFunction(
 		typeSourceSpan,
 	)
 
+	if hostBindingsMetadata.Attributes == nil {
+		hostBindingsMetadata.Attributes = make(map[string]output.OutputExpression)
+	}
 	if hostBindingsMetadata.SpecialAttributes.StyleAttr != nil {
 		hostBindingsMetadata.Attributes["style"] = output.NewLiteralExpr(
 			*hostBindingsMetadata.SpecialAttributes.StyleAttr,
@@ -1038,7 +1061,7 @@ func createHostBindingsFunction(
 			Events:            eventBindings,
 			Attributes:        hostBindingsMetadata.Attributes,
 		},
-		bindingParser,
+		&bindingParser,
 		constantPool,
 	)
 	pipeline.Transform(hostJob, compilation.CompilationJobKindHost)
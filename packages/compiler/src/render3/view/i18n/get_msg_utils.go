@@ -1,6 +1,7 @@
 package viewi18n
 
 import (
+	"sort"
 	"strings"
 
 	i18n "ngc-go/packages/compiler/src/i18n"
@@ -24,10 +25,15 @@ func CreateGoogleGetMsgStatements(
 	if len(placeholderValues) > 0 {
 		// Message template parameters containing the magic strings replaced by the Angular runtime with
 		// real data, e.g. `{'interpolation': '\uFFFD0\uFFFD'}`.
-		formattedParams := FormatI18nPlaceholderNamesInMap(placeholderValues, true /* useCamelCase */)
-		entries := make([]*output.LiteralMapEntry, 0, len(formattedParams))
-		for key, value := range formattedParams {
-			entries = append(entries, output.NewLiteralMapEntry(key, value, true /* quoted */))
+		// Params are listed in the order of their names, as maps have no order of their own.
+		params := make([]string, 0, len(placeholderValues))
+		for param := range placeholderValues {
+			params = append(params, param)
+		}
+		sort.Strings(params)
+		entries := make([]*output.LiteralMapEntry, 0, len(params))
+		for _, param := range params {
+			entries = append(entries, output.NewLiteralMapEntry(FormatI18nPlaceholderName(param, true /* useCamelCase */), placeholderValues[param], true /* quoted */))
 		}
 		args = append(args, output.NewLiteralMapExpr(entries, nil, nil))
 
@@ -35,7 +41,7 @@ func CreateGoogleGetMsgStatements(
 		// present in a template, e.g.
 		// `{original_code: {'interpolation': '{{ name }}', 'startTagSpan': '<span>'}}`.
 		originalCodeEntries := make([]*output.LiteralMapEntry, 0, len(placeholderValues))
-		for param := range placeholderValues {
+		for _, param := range params {
 			var value output.OutputExpression
 			if placeholder, ok := message.Placeholders[param]; ok {
 				// Get source span for typical placeholder if it exists.
@@ -52,7 +58,7 @@ func CreateGoogleGetMsgStatements(
 					value = output.NewLiteralExpr("", nil, nil)
 				}
 			}
-			formattedName := FormatI18nPlaceholderName(param, false)
+			formattedName := FormatI18nPlaceholderName(param, true /* useCamelCase */)
 			originalCodeEntries = append(originalCodeEntries, output.NewLiteralMapEntry(formattedName, value, true /* quoted */))
 		}
 		originalCodeMap := output.NewLiteralMapExpr(originalCodeEntries, nil, nil)
@@ -91,7 +97,7 @@ func CreateGoogleGetMsgStatements(
 		nil, /* sourceSpan */
 		[]*output.LeadingComment{
 			{
-				Text:            I18nMetaToJSDoc(meta).String(),
+				JSDoc:           I18nMetaToJSDoc(meta),
 				Multiline:       true,
 				TrailingNewline: true,
 			},
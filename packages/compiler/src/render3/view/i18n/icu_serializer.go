@@ -35,7 +35,8 @@ func (v *IcuSerializerVisitor) VisitContainer(container *i18n.Container, context
 // VisitIcu visits an Icu node
 func (v *IcuSerializerVisitor) VisitIcu(icu *i18n.Icu, context interface{}) interface{} {
 	strCases := make([]string, 0, len(icu.Cases))
-	for k, caseNode := range icu.Cases {
+	for _, k := range icu.OrderedCaseKeys() {
+		caseNode := icu.Cases[k]
 		result := caseNode.Visit(v, context)
 		var caseStr string
 		if str, ok := result.(string); ok {
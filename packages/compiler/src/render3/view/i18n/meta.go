@@ -520,7 +520,7 @@ func ParseI18nMeta(meta string) I18nMeta {
 // to a JsDoc statement formatted as expected by the Closure compiler.
 func I18nMetaToJSDoc(meta I18nMeta) *output.JSDocComment {
 	tags := make([]output.JSDocTag, 0)
-	if meta.Description != nil {
+	if meta.Description != nil && *meta.Description != "" {
 		descTag := "desc"
 		descText := *meta.Description
 		tags = append(tags, output.JSDocTag{
@@ -536,7 +536,7 @@ func I18nMetaToJSDoc(meta I18nMeta) *output.JSDocComment {
 			Text:    &suppressText,
 		})
 	}
-	if meta.Meaning != nil {
+	if meta.Meaning != nil && *meta.Meaning != "" {
 		meaningTag := "meaning"
 		meaningText := *meta.Meaning
 		tags = append(tags, output.JSDocTag{
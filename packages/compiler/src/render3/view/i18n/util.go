@@ -41,12 +41,12 @@ func HasI18nAttrs(node ml_parser.Node) bool {
 	return false
 }
 
-// IcuFromI18nMessage extracts the ICU placeholder from an i18n message
-func IcuFromI18nMessage(message *i18n.Message) *i18n.IcuPlaceholder {
+// IcuFromI18nMessage extracts the root ICU node from an ICU message
+func IcuFromI18nMessage(message *i18n.Message) *i18n.Icu {
 	if len(message.Nodes) == 0 {
 		return nil
 	}
-	if icu, ok := message.Nodes[0].(*i18n.IcuPlaceholder); ok {
+	if icu, ok := message.Nodes[0].(*i18n.Icu); ok {
 		return icu
 	}
 	return nil
@@ -8,6 +8,7 @@ import (
 	"ngc-go/packages/compiler/src/render3"
 	"ngc-go/packages/compiler/src/template_parser"
 	"ngc-go/packages/compiler/src/util"
+	"os"
 	"strings"
 )
 
@@ -33,22 +34,22 @@ func HtmlAstToRender3Ast(
 		htmlNodesSlice[i] = node
 	}
 
-	fmt.Printf("[DEBUG] HtmlAstToRender3Ast: visiting %d html nodes\n", len(htmlNodes))
+	fmt.Fprintf(os.Stderr, "[DEBUG] HtmlAstToRender3Ast: visiting %d html nodes\n", len(htmlNodes))
 	for i, node := range htmlNodes {
-		fmt.Printf("[DEBUG] HtmlAstToRender3Ast: visiting node[%d], type=%T\n", i, node)
+		fmt.Fprintf(os.Stderr, "[DEBUG] HtmlAstToRender3Ast: visiting node[%d], type=%T\n", i, node)
 		// Pass siblings array as context for blocks
 		result := node.Visit(transformer, htmlNodesSlice)
-		fmt.Printf("[DEBUG] HtmlAstToRender3Ast: node[%d] Visit returned: %v (type=%T)\n", i, result != nil, result)
+		fmt.Fprintf(os.Stderr, "[DEBUG] HtmlAstToRender3Ast: node[%d] Visit returned: %v (type=%T)\n", i, result != nil, result)
 		if result != nil {
 			if r3Node, ok := result.(render3.Node); ok {
-				fmt.Printf("[DEBUG] HtmlAstToRender3Ast: node[%d] is render3.Node, appending\n", i)
+				fmt.Fprintf(os.Stderr, "[DEBUG] HtmlAstToRender3Ast: node[%d] is render3.Node, appending\n", i)
 				ivyNodes = append(ivyNodes, r3Node)
 			} else {
-				fmt.Printf("[DEBUG] HtmlAstToRender3Ast: node[%d] is NOT render3.Node\n", i)
+				fmt.Fprintf(os.Stderr, "[DEBUG] HtmlAstToRender3Ast: node[%d] is NOT render3.Node\n", i)
 			}
 		}
 	}
-	fmt.Printf("[DEBUG] HtmlAstToRender3Ast: total ivyNodes=%d\n", len(ivyNodes))
+	fmt.Fprintf(os.Stderr, "[DEBUG] HtmlAstToRender3Ast: total ivyNodes=%d\n", len(ivyNodes))
 
 	allErrors := append(bindingParser.Errors, transformer.Errors...)
 
@@ -132,7 +133,7 @@ func (t *HtmlAstToIvyAst) convertParsedEventToBoundEvent(pe *expression_parser.P
 
 // VisitElement visits an element node
 func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context interface{}) interface{} {
-	fmt.Printf("[DEBUG] VisitElement: START, element.Name=%q\n", element.Name)
+	fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: START, element.Name=%q\n", element.Name)
 	// Preparse element to check if it's ng-content, script, style, etc.
 	preparsedElement := template_parser.PreparseElement(element)
 
@@ -254,10 +255,19 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 	inlineTemplateVariables := []*expression_parser.ParsedVariable{}
 	hasTemplateAttrs := false
 
+	// The i18n messages of the attributes, keyed by name, attached to the attributes created from
+	// their parsed properties.
+	i18nAttrsMeta := map[string]interface{}{}
+	for _, attr := range element.Attrs {
+		if attr.I18n() != nil {
+			i18nAttrsMeta[attr.Name] = attr.I18n()
+		}
+	}
+
 	for _, attr := range element.Attrs {
 		name := strings.TrimSpace(attr.Name)
 		value := attr.Value
-		fmt.Printf("[DEBUG] VisitElement: processing attr, name=%q (original=%q), value=%q\n", name, attr.Name, value)
+		fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: processing attr, name=%q (original=%q), value=%q\n", name, attr.Name, value)
 
 		// Skip let-* attributes (they are handled separately for ng-template)
 		if strings.HasPrefix(name, "let-") {
@@ -378,7 +388,7 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 					keySpanStart,
 					&detailsStr,
 				)
-				fmt.Printf("[DEBUG] VisitElement: banana box binding - attr.KeySpan=%q, adjustedKeySpan=%q (propName=%q)\n",
+				fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: banana box binding - attr.KeySpan=%q, adjustedKeySpan=%q (propName=%q)\n",
 					attr.KeySpan.String(), adjustedKeySpan.String(), propName)
 			} else {
 				adjustedKeySpan = attr.KeySpan
@@ -411,12 +421,12 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 				&parsedEvents,
 				adjustedKeySpan, // KeySpan is still for propName, not eventName
 			)
-			fmt.Printf("[DEBUG] VisitElement: banana box binding - parsedEvents count=%d, eventName=%q\n", len(parsedEvents), eventName)
+			fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: banana box binding - parsedEvents count=%d, eventName=%q\n", len(parsedEvents), eventName)
 			// Convert ParsedEvent to BoundEvent
 			for _, pe := range parsedEvents {
 				outputs = append(outputs, t.convertParsedEventToBoundEvent(pe))
 			}
-			fmt.Printf("[DEBUG] VisitElement: banana box binding - outputs count=%d after adding events\n", len(outputs))
+			fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: banana box binding - outputs count=%d after adding events\n", len(outputs))
 			continue
 		}
 
@@ -455,7 +465,7 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 				adjustedKeySpan,
 			)
 			afterCount := len(parsedProperties)
-			fmt.Printf("[DEBUG] VisitElement: ParsePropertyBinding for [%s]=\"%s\", beforeCount=%d, afterCount=%d\n", propName, value, beforeCount, afterCount)
+			fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: ParsePropertyBinding for [%s]=\"%s\", beforeCount=%d, afterCount=%d\n", propName, value, beforeCount, afterCount)
 			continue
 		}
 
@@ -470,7 +480,7 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 				propName = name[10:] // Remove "data-bind-" prefix
 				prefixLen = 10
 			}
-			fmt.Printf("[DEBUG] VisitElement: found bind-* attribute, name=%q, propName=%q, value=%q\n", name, propName, value)
+			fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: found bind-* attribute, name=%q, propName=%q, value=%q\n", name, propName, value)
 			absoluteOffset := attr.SourceSpan().Start.Offset
 			if attr.ValueSpan != nil {
 				absoluteOffset = attr.ValueSpan.FullStart.Offset
@@ -697,13 +707,13 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 	}
 
 	// Convert ParsedProperty to BoundAttribute or TextAttribute
-	fmt.Printf("[DEBUG] VisitElement: converting parsedProperties (element attrs), count=%d\n", len(parsedProperties))
-	fmt.Printf("[DEBUG] VisitElement: converting inlineTemplateProperties (template attrs), count=%d\n", len(inlineTemplateProperties))
+	fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: converting parsedProperties (element attrs), count=%d\n", len(parsedProperties))
+	fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: converting inlineTemplateProperties (template attrs), count=%d\n", len(inlineTemplateProperties))
 	templateAttrs := []interface{}{} // BoundAttribute | TextAttribute
 
 	// First, convert element properties (parsedProperties) to attrs/inputs
 	for i, prop := range parsedProperties {
-		fmt.Printf("[DEBUG] VisitElement: parsedProperties[%d]: name=%q, IsLiteral=%v\n", i, prop.Name, prop.IsLiteral)
+		fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: parsedProperties[%d]: name=%q, IsLiteral=%v\n", i, prop.Name, prop.IsLiteral)
 		if prop.IsLiteral {
 			// This is a text attribute, not a binding (element attribute)
 			valueStr := ""
@@ -716,19 +726,19 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 				prop.SourceSpan,
 				prop.KeySpan,
 				prop.ValueSpan,
-				nil, // i18n
+				i18nAttrsMeta[prop.Name],
 			)
 			attrs = append(attrs, textAttr)
 		} else {
 			// This is a binding
-			fmt.Printf("[DEBUG] VisitElement: creating BoundAttribute for prop=%q, Type=%d\n", prop.Name, prop.Type)
+			fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: creating BoundAttribute for prop=%q, Type=%d\n", prop.Name, prop.Type)
 			boundProp := t.bindingParser.CreateBoundElementProperty(
 				&element.Name,
 				prop,
 				false, // skipValidation
-				true,  // mapPropertyName
+				false, // mapPropertyName
 			)
-			fmt.Printf("[DEBUG] VisitElement: BoundAttribute created: name=%q, Type=%d, Value=%v\n", boundProp.Name, boundProp.Type, boundProp.Value)
+			fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: BoundAttribute created: name=%q, Type=%d, Value=%v\n", boundProp.Name, boundProp.Type, boundProp.Value)
 			// For two-way binding [(prop)], adjust KeySpan to exclude '(' and ')' if needed
 			keySpan := boundProp.KeySpan
 			// Check if prop.Name has the form "(prop)" - this indicates a banana box binding
@@ -746,7 +756,7 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 						keySpan.Start.MoveBy(1),
 						&detailsStr,
 					)
-					fmt.Printf("[DEBUG] VisitElement: adjusted KeySpan for banana box binding - prop.Name=%q, propName=%q, keySpan=%q\n", prop.Name, propName, keySpan.String())
+					fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: adjusted KeySpan for banana box binding - prop.Name=%q, propName=%q, keySpan=%q\n", prop.Name, propName, keySpan.String())
 				}
 			}
 			boundAttr := render3.NewBoundAttribute(
@@ -758,7 +768,7 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 				boundProp.SourceSpan,
 				keySpan,
 				boundProp.ValueSpan,
-				nil, // i18n
+				i18nAttrsMeta[prop.Name],
 			)
 			inputs = append(inputs, boundAttr)
 		}
@@ -766,7 +776,7 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 
 	// Then, convert inline template properties to templateAttrs
 	for i, prop := range inlineTemplateProperties {
-		fmt.Printf("[DEBUG] VisitElement: inlineTemplateProperties[%d]: name=%q, IsLiteral=%v\n", i, prop.Name, prop.IsLiteral)
+		fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: inlineTemplateProperties[%d]: name=%q, IsLiteral=%v\n", i, prop.Name, prop.IsLiteral)
 		if prop.IsLiteral {
 			valueStr := ""
 			if prop.Expression != nil && prop.Expression.Source != nil {
@@ -778,7 +788,7 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 				prop.SourceSpan,
 				prop.KeySpan,
 				prop.ValueSpan,
-				nil, // i18n
+				i18nAttrsMeta[prop.Name],
 			)
 			templateAttrs = append(templateAttrs, textAttr)
 		} else {
@@ -786,7 +796,7 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 				&element.Name,
 				prop,
 				false, // skipValidation
-				true,  // mapPropertyName
+				false, // mapPropertyName
 			)
 			boundAttr := render3.NewBoundAttribute(
 				boundProp.Name,
@@ -797,7 +807,7 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 				boundProp.SourceSpan,
 				boundProp.KeySpan,
 				boundProp.ValueSpan,
-				nil, // i18n
+				i18nAttrsMeta[prop.Name],
 			)
 			templateAttrs = append(templateAttrs, boundAttr)
 		}
@@ -849,7 +859,7 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 						&element.Name,
 						prop,
 						false, // skipValidation
-						true,  // mapPropertyName
+						false, // mapPropertyName
 					)
 					if boundProp != nil {
 						boundAttr := render3.NewBoundAttribute(
@@ -875,7 +885,7 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 			}
 		}
 
-		fmt.Printf("[DEBUG] VisitElement: processing directive, Name=%q, StartSourceSpan=%v, SourceSpan=%v\n",
+		fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: processing directive, Name=%q, StartSourceSpan=%v, SourceSpan=%v\n",
 			directive.Name, func() string {
 				if directive.StartSourceSpan == nil {
 					return "<nil>"
@@ -1049,7 +1059,7 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 					&element.Name,
 					prop,
 					false, // skipValidation
-					true,  // mapPropertyName
+					false, // mapPropertyName
 				)
 				if boundProp != nil {
 					boundAttr := render3.NewBoundAttribute(
@@ -1111,10 +1121,10 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 		)
 
 		// Convert parsed variables to Variable nodes
-		fmt.Printf("[DEBUG] VisitElement: converting inlineTemplateVariables, count=%d\n", len(inlineTemplateVariables))
+		fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: converting inlineTemplateVariables, count=%d\n", len(inlineTemplateVariables))
 		templateVariables := []*render3.Variable{}
 		for i, parsedVar := range inlineTemplateVariables {
-			fmt.Printf("[DEBUG] VisitElement: inlineTemplateVariables[%d]: name=%q, value=%q\n", i, parsedVar.Name, parsedVar.Value)
+			fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: inlineTemplateVariables[%d]: name=%q, value=%q\n", i, parsedVar.Name, parsedVar.Value)
 			variable := render3.NewVariable(
 				parsedVar.Name,
 				parsedVar.Value,
@@ -1237,7 +1247,7 @@ func (t *HtmlAstToIvyAst) VisitElement(element *ml_parser.Element, context inter
 		element.IsVoid,
 		element.I18n(),
 	)
-	fmt.Printf("[DEBUG] VisitElement: END, element.Name=%q, returning Element with %d inputs, %d outputs, %d directives\n", element.Name, len(inputs), len(outputs), len(directives))
+	fmt.Fprintf(os.Stderr, "[DEBUG] VisitElement: END, element.Name=%q, returning Element with %d inputs, %d outputs, %d directives\n", element.Name, len(inputs), len(outputs), len(directives))
 	return result
 }
 
@@ -1269,7 +1279,7 @@ func (t *HtmlAstToIvyAst) VisitText(text *ml_parser.Text, context interface{}) i
 
 	if hasInterpolation {
 		// Parse interpolation and create BoundText
-		fmt.Printf("[DEBUG] VisitText: parsing interpolation, Value=%q, SourceSpan=%v, FullStart.Offset=%d\n",
+		fmt.Fprintf(os.Stderr, "[DEBUG] VisitText: parsing interpolation, Value=%q, SourceSpan=%v, FullStart.Offset=%d\n",
 			text.Value, text.SourceSpan(), text.SourceSpan().FullStart.Offset)
 
 		// Find the first INTERPOLATION token to get its SourceSpan for accurate offset calculation
@@ -1280,7 +1290,7 @@ func (t *HtmlAstToIvyAst) VisitText(text *ml_parser.Text, context interface{}) i
 			for _, token := range text.Tokens {
 				if token.Type() == ml_parser.TokenTypeINTERPOLATION {
 					interpolationSourceSpan = token.SourceSpan()
-					fmt.Printf("[DEBUG] VisitText: found INTERPOLATION token, SourceSpan=%v, Start.Offset=%d\n",
+					fmt.Fprintf(os.Stderr, "[DEBUG] VisitText: found INTERPOLATION token, SourceSpan=%v, Start.Offset=%d\n",
 						interpolationSourceSpan, interpolationSourceSpan.Start.Offset)
 					break
 				}
@@ -1632,7 +1642,7 @@ func (t *HtmlAstToIvyAst) VisitLetDeclaration(decl *ml_parser.LetDeclaration, co
 
 // VisitComponent visits a component node
 func (t *HtmlAstToIvyAst) VisitComponent(component *ml_parser.Component, context interface{}) interface{} {
-	fmt.Printf("[DEBUG] VisitComponent: START, component.ComponentName=%q\n", component.ComponentName)
+	fmt.Fprintf(os.Stderr, "[DEBUG] VisitComponent: START, component.ComponentName=%q\n", component.ComponentName)
 
 	// Visit children
 	children := make([]render3.Node, 0)
@@ -1663,6 +1673,13 @@ func (t *HtmlAstToIvyAst) VisitComponent(component *ml_parser.Component, context
 		elementSelector = *component.TagName
 	}
 
+	i18nAttrsMeta := map[string]interface{}{}
+	for _, attr := range component.Attrs {
+		if attr.I18n() != nil {
+			i18nAttrsMeta[attr.Name] = attr.I18n()
+		}
+	}
+
 	for _, attr := range component.Attrs {
 		name := strings.TrimSpace(attr.Name)
 		value := attr.Value
@@ -2034,7 +2051,7 @@ func (t *HtmlAstToIvyAst) VisitComponent(component *ml_parser.Component, context
 				prop.SourceSpan,
 				prop.KeySpan,
 				prop.ValueSpan,
-				nil, // i18n
+				i18nAttrsMeta[prop.Name],
 			)
 			attrs = append(attrs, textAttr)
 		} else {
@@ -2042,7 +2059,7 @@ func (t *HtmlAstToIvyAst) VisitComponent(component *ml_parser.Component, context
 				&elementSelector,
 				prop,
 				false, // skipValidation
-				true,  // mapPropertyName
+				false, // mapPropertyName
 			)
 			if boundProp != nil {
 				boundAttr := render3.NewBoundAttribute(
@@ -2054,7 +2071,7 @@ func (t *HtmlAstToIvyAst) VisitComponent(component *ml_parser.Component, context
 					boundProp.SourceSpan,
 					boundProp.KeySpan,
 					boundProp.ValueSpan,
-					nil, // i18n
+					i18nAttrsMeta[prop.Name],
 				)
 				inputs = append(inputs, boundAttr)
 			}
@@ -2115,7 +2132,7 @@ func (t *HtmlAstToIvyAst) VisitComponent(component *ml_parser.Component, context
 						&elementSelector,
 						prop,
 						false, // skipValidation
-						true,  // mapPropertyName
+						false, // mapPropertyName
 					)
 					if boundProp != nil {
 						boundAttr := render3.NewBoundAttribute(
@@ -2297,7 +2314,7 @@ func (t *HtmlAstToIvyAst) VisitComponent(component *ml_parser.Component, context
 					&elementSelector,
 					prop,
 					false, // skipValidation
-					true,  // mapPropertyName
+					false, // mapPropertyName
 				)
 				if boundProp != nil {
 					boundAttr := render3.NewBoundAttribute(
@@ -2353,7 +2370,7 @@ func (t *HtmlAstToIvyAst) VisitComponent(component *ml_parser.Component, context
 		component.EndSourceSpan,
 		component.I18n(),
 	)
-	fmt.Printf("[DEBUG] VisitComponent: END, component.ComponentName=%q, returning Component with %d inputs, %d outputs, %d directives\n", component.ComponentName, len(inputs), len(outputs), len(directives))
+	fmt.Fprintf(os.Stderr, "[DEBUG] VisitComponent: END, component.ComponentName=%q, returning Component with %d inputs, %d outputs, %d directives\n", component.ComponentName, len(inputs), len(outputs), len(directives))
 	return result
 }
 
@@ -2,6 +2,7 @@ package view
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"ngc-go/packages/compiler/src/css"
@@ -207,7 +208,7 @@ func (b *R3TargetBinder) Bind(target *Target) BoundTarget {
 			target.Template,
 			b.directiveMatcher,
 			directives,
-			eagerDirectives,
+			&eagerDirectives,
 			missingDirectives,
 			bindings,
 			references,
@@ -223,7 +224,7 @@ func (b *R3TargetBinder) Bind(target *Target) BoundTarget {
 			nestingLevel,
 			usedPipes,
 			eagerPipes,
-			deferBlocks,
+			&deferBlocks,
 		)
 	}
 
@@ -239,7 +240,7 @@ func (b *R3TargetBinder) Bind(target *Target) BoundTarget {
 			nestingLevel,
 			usedPipes,
 			eagerPipes,
-			deferBlocks,
+			&deferBlocks,
 		)
 	}
 
@@ -630,7 +631,7 @@ func (s *Scope) ingestScopedNode(node ScopedNode) {
 type DirectiveBinder struct {
 	directiveMatcher  DirectiveMatcher
 	directives        MatchedDirectives
-	eagerDirectives   []interface{}
+	eagerDirectives   *[]interface{}
 	missingDirectives map[string]bool
 	bindings          BindingsMap
 	references        ReferenceMap
@@ -650,7 +651,7 @@ func DirectiveBinderApply(
 	template []render3.Node,
 	directiveMatcher DirectiveMatcher,
 	directives MatchedDirectives,
-	eagerDirectives []interface{},
+	eagerDirectives *[]interface{},
 	missingDirectives map[string]bool,
 	bindings BindingsMap,
 	references ReferenceMap,
@@ -847,16 +848,16 @@ func (db *DirectiveBinder) visitElementOrTemplate(node interface{}) {
 	if selectorMatcher, ok := db.directiveMatcher.(*css.SelectorMatcher[DirectiveMeta]); ok {
 		directives := []DirectiveMeta{}
 		cssSelector := CreateCssSelectorFromNode(node.(render3.Node))
-		fmt.Printf("[DEBUG] DirectiveBinder: cssSelector=%v\n", cssSelector)
-		fmt.Printf("[DEBUG] DirectiveBinder: calling Match...\n")
+		fmt.Fprintf(os.Stderr, "[DEBUG] DirectiveBinder: cssSelector=%v\n", cssSelector)
+		fmt.Fprintf(os.Stderr, "[DEBUG] DirectiveBinder: calling Match...\n")
 		matchCount := 0
 		selectorMatcher.Match(cssSelector, func(c *css.CssSelector, a *DirectiveMeta) {
 			matchCount++
-			fmt.Printf("[DEBUG] DirectiveBinder: Match callback called! matchCount=%d, directive=%q, selector=%v\n", matchCount, (*a).Name(), c)
+			fmt.Fprintf(os.Stderr, "[DEBUG] DirectiveBinder: Match callback called! matchCount=%d, directive=%q, selector=%v\n", matchCount, (*a).Name(), c)
 			directives = append(directives, *a)
 		})
-		fmt.Printf("[DEBUG] DirectiveBinder: Match returned, callback was called %d times\n", matchCount)
-		fmt.Printf("[DEBUG] DirectiveBinder: total matched directives=%d\n", len(directives))
+		fmt.Fprintf(os.Stderr, "[DEBUG] DirectiveBinder: Match returned, callback was called %d times\n", matchCount)
+		fmt.Fprintf(os.Stderr, "[DEBUG] DirectiveBinder: total matched directives=%d\n", len(directives))
 		db.trackSelectorBasedBindingsAndDirectives(node, directives)
 	} else {
 		// Handle references for non-selector matcher
@@ -903,7 +904,7 @@ func (db *DirectiveBinder) trackMatchedDirectives(node DirectiveOwner, directive
 		}
 		db.directives[node] = dirs
 		if !db.isInDeferBlock {
-			db.eagerDirectives = append(db.eagerDirectives, dirs...)
+			*db.eagerDirectives = append(*db.eagerDirectives, dirs...)
 		}
 	}
 }
@@ -1193,7 +1194,7 @@ func TemplateBinderApplyWithScope(
 	nestingLevel map[ScopedNode]int,
 	usedPipes map[string]bool,
 	eagerPipes map[string]bool,
-	deferBlocks []DeferBlockScope,
+	deferBlocks *[]DeferBlockScope,
 ) {
 	var template *render3.Template
 	if t, ok := nodeOrNodes.(*render3.Template); ok {
@@ -1205,7 +1206,7 @@ func TemplateBinderApplyWithScope(
 		symbols:       symbols,
 		usedPipes:     usedPipes,
 		eagerPipes:    eagerPipes,
-		deferBlocks:   &deferBlocks,
+		deferBlocks:   deferBlocks,
 		nestingLevel:  nestingLevel,
 		scope:         scope,
 		rootNode:      template,
@@ -1245,10 +1246,10 @@ func (tb *TemplateBinder) ingest(nodeOrNodes interface{}) {
 			tb.visitNodeFunc(v)
 		}
 		if n.TrackBy != nil && n.TrackBy.AST != nil {
-			n.TrackBy.AST.Visit(&tb.RecursiveAstVisitor, nil)
+			tb.visitAST(n.TrackBy.AST)
 		}
 		if n.Expression != nil && n.Expression.AST != nil {
-			n.Expression.AST.Visit(&tb.RecursiveAstVisitor, nil)
+			tb.visitAST(n.Expression.AST)
 		}
 		for _, node := range n.Children {
 			tb.visitNodeFunc(node)
@@ -1306,7 +1307,7 @@ func (tb *TemplateBinder) ingest(nodeOrNodes interface{}) {
 
 // VisitAST wraps RecursiveAstVisitor.Visit for AST expressions
 func (tb *TemplateBinder) VisitAST(ast expression_parser.AST, context interface{}) interface{} {
-	return ast.Visit(&tb.RecursiveAstVisitor, context)
+	return ast.Visit(&templateBinderAstVisitorAdapter{binder: tb}, context)
 }
 
 // Visit implements render3.Visitor interface for template nodes
@@ -1366,14 +1367,14 @@ func (tb *TemplateBinder) VisitReference(reference *render3.Reference) interface
 func (tb *TemplateBinder) VisitDeferredBlock(deferred *render3.DeferredBlock) interface{} {
 	tb.ingestScopedNode(deferred)
 	if deferred.Triggers != nil && deferred.Triggers.When != nil && deferred.Triggers.When.Value != nil {
-		deferred.Triggers.When.Value.Visit(&tb.RecursiveAstVisitor, nil)
+		tb.visitAST(deferred.Triggers.When.Value)
 	}
 	if deferred.PrefetchTriggers != nil && deferred.PrefetchTriggers.When != nil && deferred.PrefetchTriggers.When.Value != nil {
-		deferred.PrefetchTriggers.When.Value.Visit(&tb.RecursiveAstVisitor, nil)
+		tb.visitAST(deferred.PrefetchTriggers.When.Value)
 	}
 	if deferred.HydrateTriggers != nil {
 		if deferred.HydrateTriggers.When != nil && deferred.HydrateTriggers.When.Value != nil {
-			deferred.HydrateTriggers.When.Value.Visit(&tb.RecursiveAstVisitor, nil)
+			tb.visitAST(deferred.HydrateTriggers.When.Value)
 		}
 		if deferred.HydrateTriggers.Never != nil {
 			deferred.HydrateTriggers.Never.Visit(tb)
@@ -1412,7 +1413,7 @@ func (tb *TemplateBinder) VisitDeferredBlockLoading(block *render3.DeferredBlock
 // VisitSwitchBlockCase visits a SwitchBlockCase node
 func (tb *TemplateBinder) VisitSwitchBlockCase(block *render3.SwitchBlockCase) interface{} {
 	if block.Expression != nil {
-		block.Expression.Visit(&tb.RecursiveAstVisitor, nil)
+		tb.visitAST(block.Expression)
 	}
 	tb.ingestScopedNode(block)
 	return nil
@@ -1421,7 +1422,7 @@ func (tb *TemplateBinder) VisitSwitchBlockCase(block *render3.SwitchBlockCase) i
 // VisitForLoopBlock visits a ForLoopBlock node
 func (tb *TemplateBinder) VisitForLoopBlock(block *render3.ForLoopBlock) interface{} {
 	if block.Expression != nil && block.Expression.AST != nil {
-		block.Expression.AST.Visit(&tb.RecursiveAstVisitor, nil)
+		tb.visitAST(block.Expression.AST)
 	}
 	tb.ingestScopedNode(block)
 	if block.Empty != nil {
@@ -1439,7 +1440,7 @@ func (tb *TemplateBinder) VisitForLoopBlockEmpty(block *render3.ForLoopBlockEmpt
 // VisitIfBlockBranch visits an IfBlockBranch node
 func (tb *TemplateBinder) VisitIfBlockBranch(block *render3.IfBlockBranch) interface{} {
 	if block.Expression != nil {
-		block.Expression.Visit(&tb.RecursiveAstVisitor, nil)
+		tb.visitAST(block.Expression)
 	}
 	tb.ingestScopedNode(block)
 	return nil
@@ -1453,7 +1454,7 @@ func (tb *TemplateBinder) VisitContent(content *render3.Content) interface{} {
 
 // VisitLetDeclaration visits a LetDeclaration node
 func (tb *TemplateBinder) VisitLetDeclaration(decl *render3.LetDeclaration) interface{} {
-	tb.RecursiveAstVisitor.Visit(decl.Value, nil)
+	tb.visitAST(decl.Value)
 
 	if tb.rootNode != nil {
 		tb.symbols[decl] = tb.rootNode
@@ -1467,7 +1468,10 @@ func (tb *TemplateBinder) VisitPipe(ast *expression_parser.BindingPipe, context
 	if !tb.scope.IsDeferred {
 		tb.eagerPipes[ast.Name] = true
 	}
-	return tb.RecursiveAstVisitor.VisitPipe(ast, context)
+	adapter := &templateBinderAstVisitorAdapter{binder: tb}
+	adapter.Visit(ast.Exp, context)
+	adapter.visitAll(ast.Args, context)
+	return nil
 }
 
 // VisitPropertyRead visits a PropertyRead node
@@ -1571,11 +1575,13 @@ func (a *templateBinderAstVisitorAdapter) VisitSafeKeyedRead(ast *expression_par
 }
 
 func (a *templateBinderAstVisitorAdapter) VisitLiteralArray(ast *expression_parser.LiteralArray, ctx interface{}) interface{} {
-	return a.binder.RecursiveAstVisitor.VisitLiteralArray(ast, ctx)
+	a.visitAll(ast.Expressions, ctx)
+	return nil
 }
 
 func (a *templateBinderAstVisitorAdapter) VisitLiteralMap(ast *expression_parser.LiteralMap, ctx interface{}) interface{} {
-	return a.binder.RecursiveAstVisitor.VisitLiteralMap(ast, ctx)
+	a.visitAll(ast.Values, ctx)
+	return nil
 }
 
 func (a *templateBinderAstVisitorAdapter) VisitLiteralPrimitive(ast *expression_parser.LiteralPrimitive, ctx interface{}) interface{} {
@@ -1583,15 +1589,18 @@ func (a *templateBinderAstVisitorAdapter) VisitLiteralPrimitive(ast *expression_
 }
 
 func (a *templateBinderAstVisitorAdapter) VisitPrefixNot(ast *expression_parser.PrefixNot, ctx interface{}) interface{} {
-	return a.binder.RecursiveAstVisitor.VisitPrefixNot(ast, ctx)
+	a.Visit(ast.Expression, ctx)
+	return nil
 }
 
 func (a *templateBinderAstVisitorAdapter) VisitTypeofExpression(ast *expression_parser.TypeofExpression, ctx interface{}) interface{} {
-	return a.binder.RecursiveAstVisitor.VisitTypeofExpression(ast, ctx)
+	a.Visit(ast.Expression, ctx)
+	return nil
 }
 
 func (a *templateBinderAstVisitorAdapter) VisitVoidExpression(ast *expression_parser.VoidExpression, ctx interface{}) interface{} {
-	return a.binder.RecursiveAstVisitor.VisitVoidExpression(ast, ctx)
+	a.Visit(ast.Expression, ctx)
+	return nil
 }
 
 func (a *templateBinderAstVisitorAdapter) VisitNonNullAssert(ast *expression_parser.NonNullAssert, ctx interface{}) interface{} {
@@ -1618,7 +1627,8 @@ func (a *templateBinderAstVisitorAdapter) VisitASTWithSource(ast *expression_par
 }
 
 func (a *templateBinderAstVisitorAdapter) VisitTemplateLiteral(ast *expression_parser.TemplateLiteral, ctx interface{}) interface{} {
-	return a.binder.RecursiveAstVisitor.VisitTemplateLiteral(ast, ctx)
+	a.visitAll(ast.Expressions, ctx)
+	return nil
 }
 
 func (a *templateBinderAstVisitorAdapter) VisitTemplateLiteralElement(ast *expression_parser.TemplateLiteralElement, ctx interface{}) interface{} {
@@ -1626,11 +1636,14 @@ func (a *templateBinderAstVisitorAdapter) VisitTemplateLiteralElement(ast *expre
 }
 
 func (a *templateBinderAstVisitorAdapter) VisitTaggedTemplateLiteral(ast *expression_parser.TaggedTemplateLiteral, ctx interface{}) interface{} {
-	return a.binder.RecursiveAstVisitor.VisitTaggedTemplateLiteral(ast, ctx)
+	a.Visit(ast.Tag, ctx)
+	a.visitAll(ast.Template.Expressions, ctx)
+	return nil
 }
 
 func (a *templateBinderAstVisitorAdapter) VisitParenthesizedExpression(ast *expression_parser.ParenthesizedExpression, ctx interface{}) interface{} {
-	return a.binder.RecursiveAstVisitor.VisitParenthesizedExpression(ast, ctx)
+	a.Visit(ast.Expression, ctx)
+	return nil
 }
 
 func (a *templateBinderAstVisitorAdapter) VisitRegularExpressionLiteral(ast *expression_parser.RegularExpressionLiteral, ctx interface{}) interface{} {
@@ -1725,7 +1738,7 @@ func (tb *TemplateBinder) VisitElement(element *render3.Element) interface{} {
 
 func (tb *TemplateBinder) VisitBoundAttribute(attr *render3.BoundAttribute) interface{} {
 	if attr.Value != nil {
-		attr.Value.Visit(&tb.RecursiveAstVisitor, nil)
+		tb.visitAST(attr.Value)
 	}
 	return nil
 }
@@ -1748,7 +1761,7 @@ func (tb *TemplateBinder) VisitBoundText(text *render3.BoundText) interface{} {
 
 func (tb *TemplateBinder) VisitIcu(icu *render3.Icu) interface{} {
 	for _, boundText := range icu.Vars {
-		boundText.Value.Visit(&tb.RecursiveAstVisitor, nil)
+		tb.visitAST(boundText.Value)
 	}
 	for _, placeholder := range icu.Placeholders {
 		if node, ok := placeholder.(render3.Node); ok {
@@ -1767,7 +1780,7 @@ func (tb *TemplateBinder) VisitDeferredTrigger(trigger *render3.DeferredTrigger)
 
 func (tb *TemplateBinder) VisitSwitchBlock(block *render3.SwitchBlock) interface{} {
 	if block.Expression != nil {
-		block.Expression.Visit(&tb.RecursiveAstVisitor, nil)
+		tb.visitAST(block.Expression)
 	}
 	for _, caseNode := range block.Cases {
 		tb.visitNodeFunc(caseNode)
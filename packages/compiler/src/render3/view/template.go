@@ -9,6 +9,7 @@ import (
 	"ngc-go/packages/compiler/src/schema"
 	"ngc-go/packages/compiler/src/template_parser"
 	"ngc-go/packages/compiler/src/util"
+	"os"
 )
 
 // LEADING_TRIVIA_CHARS are characters that should be considered as leading trivia
@@ -186,7 +187,7 @@ func ParseTemplate(
 	}
 
 	rootNodes := parseResult.RootNodes
-	fmt.Printf("ParseTemplate: htmlParser returned %d nodes\n", len(rootNodes))
+	fmt.Fprintf(os.Stderr, "ParseTemplate: htmlParser returned %d nodes\n", len(rootNodes))
 
 	// We need to use the same `retainEmptyTokens` value for both parses to avoid
 	// causing a mismatch when reusing source spans, even if the
@@ -239,7 +240,7 @@ func ParseTemplate(
 	}
 
 	rootNodes = i18nMetaResult.RootNodes
-	fmt.Printf("ParseTemplate: after i18nMetaVisitor, nodes count: %d\n", len(rootNodes))
+	fmt.Fprintf(os.Stderr, "ParseTemplate: after i18nMetaVisitor, nodes count: %d\n", len(rootNodes))
 
 	if preserveWhitespaces == nil || !*preserveWhitespaces {
 		// Always preserve significant whitespace here because this is used to generate the `goog.getMsg`
@@ -260,7 +261,7 @@ func ParseTemplate(
 		)
 		visitedNodes := ml_parser.VisitAll(whitespaceVisitor, rootNodes, nil)
 		rootNodes = convertToMlNodes(visitedNodes)
-		fmt.Printf("ParseTemplate: after whitespaceVisitor, nodes count: %d\n", len(rootNodes))
+		fmt.Fprintf(os.Stderr, "ParseTemplate: after whitespaceVisitor, nodes count: %d\n", len(rootNodes))
 
 		// run i18n meta visitor again in case whitespaces are removed (because that might affect
 		// generated i18n message content) and first pass indicated that i18n content is present in a
@@ -278,7 +279,7 @@ func ParseTemplate(
 			)
 			visitedNodes2 := ml_parser.VisitAll(i18nMetaVisitor2, rootNodes, nil)
 			rootNodes = convertToMlNodes(visitedNodes2)
-			fmt.Printf("ParseTemplate: after i18nMetaVisitor2, nodes count: %d\n", len(rootNodes))
+			fmt.Fprintf(os.Stderr, "ParseTemplate: after i18nMetaVisitor2, nodes count: %d\n", len(rootNodes))
 		}
 	}
 
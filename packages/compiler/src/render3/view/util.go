@@ -2,7 +2,10 @@ package view
 
 import (
 	"fmt"
+	"os"
+	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 
 	"ngc-go/packages/compiler/src/core"
@@ -75,6 +78,18 @@ func Invalid(visitor interface{}, arg interface{}) {
 
 // AsLiteral converts a value to a literal expression
 func AsLiteral(value interface{}) output.OutputExpression {
+	switch v := value.(type) {
+	case core.R3CssSelectorList:
+		literals := make([]output.OutputExpression, len(v))
+		for i, selector := range v {
+			literals[i] = AsLiteral(selector)
+		}
+		return output.NewLiteralArrayExpr(literals, nil, nil)
+	case core.R3CssSelector:
+		return AsLiteral([]interface{}(v))
+	case core.SelectorFlags:
+		return output.NewLiteralExpr(int(v), output.InferredType, nil)
+	}
 	if arr, ok := value.([]interface{}); ok {
 		literals := make([]output.OutputExpression, len(arr))
 		for i, v := range arr {
@@ -103,8 +118,16 @@ func ConditionallyCreateDirectiveBindingLiteral(
 		return nil
 	}
 
+	// Sort the keys so that the output is deterministic.
+	keys := make([]string, 0, len(bindingMap))
+	for key := range bindingMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
 	entries := []*output.LiteralMapEntry{}
-	for key, value := range bindingMap {
+	for _, key := range keys {
+		value := bindingMap[key]
 		var declaredName, publicName, minifiedName string
 		var expressionValue output.OutputExpression
 
@@ -189,11 +212,14 @@ func NewDefinitionMap() *DefinitionMap {
 }
 
 // Set sets a key-value pair in the map. If the key already exists, it updates the value.
-// If value is nil, the key is not added.
+// If value is nil, including a nil pointer to an expression, the key is not added.
 func (dm *DefinitionMap) Set(key string, value output.OutputExpression) {
 	if value == nil {
 		return
 	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr && v.IsNil() {
+		return
+	}
 	// Find existing entry
 	for i := range dm.Values {
 		if dm.Values[i].Key == key {
@@ -262,7 +288,7 @@ func CreateCssSelectorFromNode(node Node) *css.CssSelector {
 
 	cssSelector.SetElement(elementNameNoNs)
 
-	fmt.Printf("[DEBUG] CreateCssSelectorFromNode: elementNameNoNs=%q, attributes=%v\n", elementNameNoNs, attributes)
+	fmt.Fprintf(os.Stderr, "[DEBUG] CreateCssSelectorFromNode: elementNameNoNs=%q, attributes=%v\n", elementNameNoNs, attributes)
 
 	// Sort attribute names for consistent order (Go map iteration is random)
 	attrNames := make([]string, 0, len(attributes))
@@ -281,7 +307,7 @@ func CreateCssSelectorFromNode(node Node) *css.CssSelector {
 	for _, name := range attrNames {
 		value := attributes[name]
 		_, nameNoNs := ml_parser.SplitNsName(name, false)
-		fmt.Printf("[DEBUG] CreateCssSelectorFromNode: adding attribute name=%q, value=%q\n", nameNoNs, value)
+		fmt.Fprintf(os.Stderr, "[DEBUG] CreateCssSelectorFromNode: adding attribute name=%q, value=%q\n", nameNoNs, value)
 		cssSelector.AddAttribute(nameNoNs, value)
 		if strings.ToLower(name) == "class" {
 			classes := strings.Fields(value)
@@ -291,7 +317,7 @@ func CreateCssSelectorFromNode(node Node) *css.CssSelector {
 		}
 	}
 
-	fmt.Printf("[DEBUG] CreateCssSelectorFromNode: final cssSelector=%v\n", cssSelector)
+	fmt.Fprintf(os.Stderr, "[DEBUG] CreateCssSelectorFromNode: final cssSelector=%v\n", cssSelector)
 	return cssSelector
 }
 
@@ -308,46 +334,45 @@ func GetAttrsForDirectiveMatching(elOrTpl Node) map[string]string {
 	}); ok {
 		if tagName := template.GetTagName(); tagName != nil && *tagName != "ng-template" {
 			// For inline templates (*ngFor, *ngIf, etc.), use the typed fields
-			fmt.Printf("[DEBUG] GetAttrsForDirectiveMatching: found inline template with tagName=%q\n", *tagName)
+			fmt.Fprintf(os.Stderr, "[DEBUG] GetAttrsForDirectiveMatching: found inline template with tagName=%q\n", *tagName)
 			// Get text attributes
 			attrs := template.GetAttributes()
-			fmt.Printf("[DEBUG] GetAttrsForDirectiveMatching: template.GetAttributes() count=%d\n", len(attrs))
+			fmt.Fprintf(os.Stderr, "[DEBUG] GetAttrsForDirectiveMatching: template.GetAttributes() count=%d\n", len(attrs))
 			for i, attr := range attrs {
 				name := attr.Name
-				fmt.Printf("[DEBUG] GetAttrsForDirectiveMatching: attr[%d].Name=%q, Value=%q\n", i, name, attr.Value)
+				fmt.Fprintf(os.Stderr, "[DEBUG] GetAttrsForDirectiveMatching: attr[%d].Name=%q, Value=%q\n", i, name, attr.Value)
 				if !IsI18nAttribute(name) {
 					attributesMap[name] = attr.Value
 				}
 			}
 			// Get inputs (bound attributes)
 			inputs := template.GetInputs()
-			fmt.Printf("[DEBUG] GetAttrsForDirectiveMatching: template.GetInputs() count=%d\n", len(inputs))
+			fmt.Fprintf(os.Stderr, "[DEBUG] GetAttrsForDirectiveMatching: template.GetInputs() count=%d\n", len(inputs))
 			for i, input := range inputs {
-				fmt.Printf("[DEBUG] GetAttrsForDirectiveMatching: input[%d].Name=%q, Type=%d\n", i, input.Name, input.Type)
+				fmt.Fprintf(os.Stderr, "[DEBUG] GetAttrsForDirectiveMatching: input[%d].Name=%q, Type=%d\n", i, input.Name, input.Type)
 				if input.Type == expression_parser.BindingTypeProperty || input.Type == expression_parser.BindingTypeTwoWay {
 					attributesMap[input.Name] = ""
 				}
 			}
-			fmt.Printf("[DEBUG] GetAttrsForDirectiveMatching: final attributesMap=%v\n", attributesMap)
+			fmt.Fprintf(os.Stderr, "[DEBUG] GetAttrsForDirectiveMatching: final attributesMap=%v\n", attributesMap)
 			return attributesMap
 		}
 	}
 
-	// Handle Element nodes
+	// Handle Element nodes and explicit <ng-template> elements
 	if element, ok := elOrTpl.(interface {
-		GetName() string
 		GetAttributes() []*render3.TextAttribute
 		GetInputs() []*render3.BoundAttribute
 		GetOutputs() []*render3.BoundEvent
 	}); ok {
-		fmt.Printf("[DEBUG] GetAttrsForDirectiveMatching: found Element with name=%q\n", element.GetName())
+		fmt.Fprintf(os.Stderr, "[DEBUG] GetAttrsForDirectiveMatching: found %T\n", elOrTpl)
 
 		// Get text attributes
 		attrs := element.GetAttributes()
-		fmt.Printf("[DEBUG] GetAttrsForDirectiveMatching: element.GetAttributes() count=%d\n", len(attrs))
+		fmt.Fprintf(os.Stderr, "[DEBUG] GetAttrsForDirectiveMatching: element.GetAttributes() count=%d\n", len(attrs))
 		for i, attr := range attrs {
 			name := attr.Name
-			fmt.Printf("[DEBUG] GetAttrsForDirectiveMatching: attr[%d].Name=%q, Value=%q\n", i, name, attr.Value)
+			fmt.Fprintf(os.Stderr, "[DEBUG] GetAttrsForDirectiveMatching: attr[%d].Name=%q, Value=%q\n", i, name, attr.Value)
 			if !IsI18nAttribute(name) {
 				attributesMap[name] = attr.Value
 			}
@@ -355,9 +380,9 @@ func GetAttrsForDirectiveMatching(elOrTpl Node) map[string]string {
 
 		// Get inputs (bound attributes)
 		inputs := element.GetInputs()
-		fmt.Printf("[DEBUG] GetAttrsForDirectiveMatching: element.GetInputs() count=%d\n", len(inputs))
+		fmt.Fprintf(os.Stderr, "[DEBUG] GetAttrsForDirectiveMatching: element.GetInputs() count=%d\n", len(inputs))
 		for i, input := range inputs {
-			fmt.Printf("[DEBUG] GetAttrsForDirectiveMatching: input[%d].Name=%q, Type=%d\n", i, input.Name, input.Type)
+			fmt.Fprintf(os.Stderr, "[DEBUG] GetAttrsForDirectiveMatching: input[%d].Name=%q, Type=%d\n", i, input.Name, input.Type)
 			if input.Type == expression_parser.BindingTypeProperty || input.Type == expression_parser.BindingTypeTwoWay {
 				attributesMap[input.Name] = ""
 			}
@@ -365,13 +390,13 @@ func GetAttrsForDirectiveMatching(elOrTpl Node) map[string]string {
 
 		// Get outputs (bound events)
 		outputs := element.GetOutputs()
-		fmt.Printf("[DEBUG] GetAttrsForDirectiveMatching: element.GetOutputs() count=%d\n", len(outputs))
+		fmt.Fprintf(os.Stderr, "[DEBUG] GetAttrsForDirectiveMatching: element.GetOutputs() count=%d\n", len(outputs))
 		for i, output := range outputs {
-			fmt.Printf("[DEBUG] GetAttrsForDirectiveMatching: output[%d].Name=%q\n", i, output.Name)
+			fmt.Fprintf(os.Stderr, "[DEBUG] GetAttrsForDirectiveMatching: output[%d].Name=%q\n", i, output.Name)
 			attributesMap[output.Name] = ""
 		}
 
-		fmt.Printf("[DEBUG] GetAttrsForDirectiveMatching: final attributesMap=%v\n", attributesMap)
+		fmt.Fprintf(os.Stderr, "[DEBUG] GetAttrsForDirectiveMatching: final attributesMap=%v\n", attributesMap)
 		return attributesMap
 	}
 
@@ -0,0 +1,105 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"ngc-go/packages/compiler/src/template/pipeline/src/compilation"
+)
+
+// CustomPhase is a project-specific transform that Transform runs next to the built-in phases, e.g.
+// to strip test attributes or rewrite deprecated element names.
+type CustomPhase interface {
+	// Name identifies the phase, e.g. in `--dump-ir`. It must not be the name of another phase.
+	Name() string
+	// Kind selects the jobs the phase runs on: templates, host bindings or both.
+	Kind() compilation.CompilationJobKind
+	// Run transforms the operations of the job.
+	Run(job *compilation.CompilationJob)
+}
+
+// NewCustomPhase returns a CustomPhase running fn.
+func NewCustomPhase(name string, kind compilation.CompilationJobKind, fn func(*compilation.CompilationJob)) CustomPhase {
+	return &funcPhase{name: name, kind: kind, fn: fn}
+}
+
+type funcPhase struct {
+	name string
+	kind compilation.CompilationJobKind
+	fn   func(*compilation.CompilationJob)
+}
+
+func (p *funcPhase) Name() string                         { return p.name }
+func (p *funcPhase) Kind() compilation.CompilationJobKind { return p.kind }
+func (p *funcPhase) Run(job *compilation.CompilationJob)  { p.fn(job) }
+
+// customPhase is a registered CustomPhase, anchored to a built-in phase.
+type customPhase struct {
+	phase  CustomPhase
+	anchor string
+	after  bool
+}
+
+var customPhases []customPhase
+
+// RegisterPhaseBefore registers a phase to run right before the built-in phase named before. Phases
+// registered against the same built-in phase run in registration order.
+func RegisterPhaseBefore(before string, phase CustomPhase) error {
+	return registerPhase(customPhase{phase: phase, anchor: before})
+}
+
+// RegisterPhaseAfter registers a phase to run right after the built-in phase named after. Phases
+// registered against the same built-in phase run in registration order.
+func RegisterPhaseAfter(after string, phase CustomPhase) error {
+	return registerPhase(customPhase{phase: phase, anchor: after, after: true})
+}
+
+// ClearCustomPhases unregisters every custom phase.
+func ClearCustomPhases() {
+	customPhases = nil
+}
+
+func registerPhase(custom customPhase) error {
+	name := custom.phase.Name()
+	anchored := false
+	for _, phase := range phasesList {
+		builtin := phaseName(phase)
+		if builtin == name {
+			return fmt.Errorf("phase %q is already a built-in phase", name)
+		}
+		anchored = anchored || builtin == custom.anchor
+	}
+	if !anchored {
+		return fmt.Errorf("unknown built-in phase %q", custom.anchor)
+	}
+	for _, registered := range customPhases {
+		if registered.phase.Name() == name {
+			return fmt.Errorf("phase %q is already registered", name)
+		}
+	}
+	customPhases = append(customPhases, custom)
+	return nil
+}
+
+// allPhases returns the built-in phases with the custom phases inserted around their anchors.
+func allPhases() []Phase {
+	if len(customPhases) == 0 {
+		return phasesList
+	}
+	all := make([]Phase, 0, len(phasesList)+len(customPhases))
+	for _, phase := range phasesList {
+		name := phaseName(phase)
+		all = appendCustomPhases(all, name, false)
+		all = append(all, phase)
+		all = appendCustomPhases(all, name, true)
+	}
+	return all
+}
+
+func appendCustomPhases(all []Phase, anchor string, after bool) []Phase {
+	for _, custom := range customPhases {
+		if custom.anchor == anchor && custom.after == after {
+			all = append(all, Phase{custom.phase.Kind(), custom.phase})
+		}
+	}
+	return all
+}
@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"fmt"
+	"time"
 
 	"ngc-go/packages/compiler/src/constant"
 	"ngc-go/packages/compiler/src/output"
@@ -15,7 +16,7 @@ import (
 // Phase represents a compilation phase
 type Phase struct {
 	Kind compilation.CompilationJobKind
-	Fn   interface{} // func(*compilation.CompilationJob) | func(*compilation.ComponentCompilationJob) | func(*compilation.HostBindingCompilationJob)
+	Fn   interface{} // func(*compilation.CompilationJob) | func(*compilation.ComponentCompilationJob) | func(*compilation.HostBindingCompilationJob) | CustomPhase
 }
 
 var phasesList = []Phase{
@@ -87,8 +88,9 @@ var phasesList = []Phase{
 	{compilation.CompilationJobKindBoth, phases.Chain},
 }
 
-// Transform runs all transformation phases in the correct order against a compilation job.
-// After this processing, the compilation should be in a state where it can be emitted.
+// Transform runs all transformation phases in the correct order against a compilation job, including
+// the registered custom phases. After this processing, the compilation should be in a state where it
+// can be emitted.
 func Transform(job interface{}, kind compilation.CompilationJobKind) {
 	// Get the base CompilationJob to check Kind
 	var baseJob *compilation.CompilationJob
@@ -103,8 +105,10 @@ func Transform(job interface{}, kind compilation.CompilationJobKind) {
 		panic(fmt.Sprintf("Transform: unexpected job type %T", job))
 	}
 
-	for _, phase := range phasesList {
+	tracer := newTracer(baseJob)
+	for _, phase := range allPhases() {
 		if phase.Kind == kind || phase.Kind == compilation.CompilationJobKindBoth {
+			start := time.Now()
 			// Type assertion to call the appropriate function
 			switch fn := phase.Fn.(type) {
 			case func(*compilation.CompilationJob):
@@ -117,9 +121,13 @@ func Transform(job interface{}, kind compilation.CompilationJobKind) {
 				if hostJob, ok := job.(*compilation.HostBindingCompilationJob); ok {
 					fn(hostJob)
 				}
+			case CustomPhase:
+				fn.Run(baseJob)
 			}
+			tracer.afterPhase(phase, time.Since(start))
 		}
 	}
+	tracer.finish()
 }
 
 // EmitTemplateFn compiles all views in the given ComponentCompilationJob into the final template function,
@@ -159,7 +167,7 @@ func emitView(view *compilation.ViewCompilationUnit) *output.FunctionExpr {
 	}
 
 	createStatements := []output.OutputStatement{}
-	for op := view.Create.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range view.Create.Ops() {
 		if op.GetKind() != ir.OpKindStatement {
 			panic(fmt.Sprintf(
 				"AssertionError: expected all create ops to have been compiled, but got %v",
@@ -174,7 +182,7 @@ func emitView(view *compilation.ViewCompilationUnit) *output.FunctionExpr {
 	}
 
 	updateStatements := []output.OutputStatement{}
-	for op := view.Update.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range view.Update.Ops() {
 		if op.GetKind() != ir.OpKindStatement {
 			panic(fmt.Sprintf(
 				"AssertionError: expected all update ops to have been compiled, but got %v",
@@ -232,7 +240,7 @@ func EmitHostBindingFunction(job *compilation.HostBindingCompilationJob) *output
 	}
 
 	createStatements := []output.OutputStatement{}
-	for op := job.Root.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range job.Root.GetCreate().Ops() {
 		if op.GetKind() != ir.OpKindStatement {
 			panic(fmt.Sprintf(
 				"AssertionError: expected all create ops to have been compiled, but got %v",
@@ -247,7 +255,7 @@ func EmitHostBindingFunction(job *compilation.HostBindingCompilationJob) *output
 	}
 
 	updateStatements := []output.OutputStatement{}
-	for op := job.Root.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range job.Root.GetUpdate().Ops() {
 		if op.GetKind() != ir.OpKindStatement {
 			panic(fmt.Sprintf(
 				"AssertionError: expected all update ops to have been compiled, but got %v",
@@ -2,9 +2,11 @@ package pipeline
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/core"
 	"ngc-go/packages/compiler/src/expression_parser"
 	"ngc-go/packages/compiler/src/i18n"
 	"ngc-go/packages/compiler/src/ml_parser"
@@ -96,10 +98,16 @@ type HostBindingInput struct {
 	Events            []*expression_parser.ParsedEvent
 }
 
+// securityContextCalculator computes the security contexts of a property or attribute of the
+// elements matched by a selector, as the binding parser does.
+type securityContextCalculator interface {
+	CalcPossibleSecurityContexts(selector string, propName string, isAttribute bool) []core.SecurityContext
+}
+
 // IngestHostBinding processes a host binding AST and converts it into a HostBindingCompilationJob in the intermediate representation
 func IngestHostBinding(
 	input *HostBindingInput,
-	bindingParser interface{}, // TODO: BindingParser type
+	bindingParser securityContextCalculator,
 	constantPool *constant.ConstantPool,
 ) *compilation.HostBindingCompilationJob {
 	job := compilation.NewHostBindingCompilationJob(
@@ -109,12 +117,135 @@ func IngestHostBinding(
 		compilation.TemplateCompilationModeDomOnly,
 	)
 
-	// TODO: Implement property, attribute, and event ingestion
-	// This requires the BindingParser interface to be defined
+	for _, property := range input.Properties {
+		bindingKind := ir.BindingKindProperty
+		// TODO: this should really be handled in the parser.
+		if strings.HasPrefix(property.Name, "attr.") {
+			property.Name = property.Name[len("attr."):]
+			bindingKind = ir.BindingKindAttribute
+		}
+		if property.IsLegacyAnimation {
+			bindingKind = ir.BindingKindLegacyAnimation
+		}
+		if property.IsAnimation {
+			bindingKind = ir.BindingKindAnimation
+		}
+		securityContexts := withoutNoneContext(bindingParser.CalcPossibleSecurityContexts(
+			input.ComponentSelector, property.Name, bindingKind == ir.BindingKindAttribute))
+		ingestDomProperty(job, property, bindingKind, securityContexts)
+	}
+
+	attributeNames := make([]string, 0, len(input.Attributes))
+	for name := range input.Attributes {
+		attributeNames = append(attributeNames, name)
+	}
+	sort.Strings(attributeNames)
+	for _, name := range attributeNames {
+		securityContexts := withoutNoneContext(bindingParser.CalcPossibleSecurityContexts(
+			input.ComponentSelector, name, true))
+		ingestHostAttribute(job, name, input.Attributes[name], securityContexts)
+	}
+
+	for _, event := range input.Events {
+		ingestHostEvent(job, event)
+	}
 
 	return job
 }
 
+// withoutNoneContext drops SecurityContextNONE from a list of security contexts.
+func withoutNoneContext(contexts []core.SecurityContext) []core.SecurityContext {
+	result := make([]core.SecurityContext, 0, len(contexts))
+	for _, context := range contexts {
+		if context != core.SecurityContextNONE {
+			result = append(result, context)
+		}
+	}
+	return result
+}
+
+// ingestDomProperty ingests a host property binding.
+func ingestDomProperty(
+	job *compilation.HostBindingCompilationJob,
+	property *expression_parser.ParsedProperty,
+	bindingKind ir.BindingKind,
+	securityContexts []core.SecurityContext,
+) {
+	var expr interface{}
+	ast := astOf(property.Expression)
+	if interpolation, ok := ast.(*expression_parser.Interpolation); ok {
+		interp, err := ops_update.NewInterpolation(
+			interpolation.Strings,
+			convertExpressions(interpolation.Expressions, job.CompilationJob, property.SourceSpan),
+			[]string{},
+		)
+		if err != nil {
+			panic(err)
+		}
+		expr = interp
+	} else {
+		expr = convertAst(ast, job.CompilationJob, property.SourceSpan)
+	}
+	job.Root.Update.Push(ops_update.NewBindingOp(
+		job.Root.Xref,
+		bindingKind,
+		property.Name,
+		expr,
+		nil,
+		securityContexts,
+		false,
+		false,
+		nil,
+		// TODO: How do Host bindings handle i18n attrs?
+		nil,
+		property.SourceSpan,
+	))
+}
+
+// ingestHostAttribute ingests a static host attribute. Host attributes are always extracted to the
+// `hostAttrs` const, even if they are not strictly text literals.
+func ingestHostAttribute(
+	job *compilation.HostBindingCompilationJob,
+	name string,
+	value output.OutputExpression,
+	securityContexts []core.SecurityContext,
+) {
+	job.Root.Update.Push(ops_update.NewBindingOp(
+		job.Root.Xref,
+		ir.BindingKindAttribute,
+		name,
+		value,
+		nil,
+		securityContexts,
+		true,
+		false,
+		nil,
+		nil,
+		value.GetSourceSpan(),
+	))
+}
+
+// ingestHostEvent ingests a host listener.
+func ingestHostEvent(job *compilation.HostBindingCompilationJob, event *expression_parser.ParsedEvent) {
+	var phase, target *string
+	if event.Type == expression_parser.ParsedEventTypeLegacyAnimation {
+		phase = event.TargetOrPhase
+	} else {
+		target = event.TargetOrPhase
+	}
+	job.Root.Create.Push(ops_create.NewListenerOp(
+		job.Root.Xref,
+		ir.NewSlotHandle(),
+		event.Name,
+		nil,
+		makeListenerHandlerOps(job.Root, event.Handler, event.HandlerSpan),
+		phase,
+		target,
+		true,
+		event.SourceSpan,
+	))
+}
+
 // ingestNodes ingests the nodes of a template AST into the given ViewCompilationUnit
 func ingestNodes(unit *compilation.ViewCompilationUnit, template []render3.Node) {
 	for _, node := range template {
@@ -276,18 +407,14 @@ func ingestTemplate(unit *compilation.ViewCompilationUnit, tmpl *render3.Templat
 	if templateKind == ir.TemplateKindNgTemplate {
 		if msg, ok := tmpl.I18n.(*i18n.Message); ok {
 			id := unit.Job.AllocateXrefId()
-			childView.Create.InsertAfter(
-				childView.Create.Head(),
+			childView.Create.Prepend([]ir_operations.Op{
 				ops_create.NewI18nStartOp(id, msg, 0, tmpl.StartSourceSpan),
-			)
+			})
 			endSpan := tmpl.EndSourceSpan
 			if endSpan == nil {
 				endSpan = tmpl.StartSourceSpan
 			}
-			childView.Create.InsertBefore(
-				childView.Create.Tail(),
-				ops_create.NewI18nEndOp(id, endSpan),
-			)
+			childView.Create.Push(ops_create.NewI18nEndOp(id, endSpan))
 		}
 	}
 }
@@ -1006,9 +1133,13 @@ func ingestDeferBlock(unit *compilation.ViewCompilationUnit, deferBlock *render3
 
 	// Check if we need a per-block resolver function
 	if unit.Job.DeferMeta.Mode == view.DeferBlockDepsEmitModePerBlock {
-		// TODO: Look up deferBlock in unit.Job.DeferMeta.Blocks map
-		// For now, we'll leave it as nil
-		ownResolverFn = nil
+		resolverFn, ok := unit.Job.DeferMeta.Blocks[deferBlock]
+		if !ok {
+			panic("AssertionError: unable to find a dependency function for this deferred block")
+		}
+		if resolverFn != nil {
+			ownResolverFn = *resolverFn
+		}
 	}
 
 	// Generate the defer main view and all secondary views.
@@ -1056,14 +1187,19 @@ func ingestDeferBlock(unit *compilation.ViewCompilationUnit, deferBlock *render3
 		)
 	}
 
-	// Create the main defer op, and ops for all secondary views.
+	// Create the main defer op, and ops for all secondary views. The shared resolver is only
+	// set in `PerComponent` mode; keep a nil pointer out of the interface.
+	var resolverFn output.OutputExpression
+	if unit.Job.AllDeferrableDepsFn != nil {
+		resolverFn = unit.Job.AllDeferrableDepsFn
+	}
 	deferXref := unit.Job.AllocateXrefId()
 	deferOp := ops_create.NewDeferOp(
 		deferXref,
 		main.Xref,
 		main.Handle,
 		ownResolverFn,
-		unit.Job.AllDeferrableDepsFn,
+		resolverFn,
 		deferBlock.SourceSpan(),
 	)
 
@@ -1175,17 +1311,27 @@ func ingestIcu(unit *compilation.ViewCompilationUnit, icu *render3.Icu) {
 			ops_create.NewIcuStartOp(xref, msg, icuPlaceholder.Name, icu.SourceSpan()),
 		)
 
-		// Combine vars and placeholders
+		// Combine vars and placeholders, vars first as in the source
 		allPlaceholders := make(map[string]render3.Node)
+		var placeholderNames []string
 		for k, v := range icu.Vars {
 			allPlaceholders[k] = v
+			placeholderNames = append(placeholderNames, k)
 		}
+		sort.Strings(placeholderNames)
+		varCount := len(placeholderNames)
 		for k, v := range icu.Placeholders {
+			if _, ok := allPlaceholders[k]; !ok {
+				placeholderNames = append(placeholderNames, k)
+			}
 			allPlaceholders[k] = v
 		}
+		sort.Strings(placeholderNames[varCount:])
 
 		// Process each placeholder
-		for placeholder, text := range allPlaceholders {
+		for _, placeholder := range placeholderNames {
+			text := allPlaceholders[placeholder]
+			placeholder := placeholder
 			placeholderPtr := &placeholder
 			if boundText, ok := text.(*render3.BoundText); ok {
 				ingestBoundText(unit, boundText, placeholderPtr)
@@ -1297,7 +1443,7 @@ func ingestForBlock(unit *compilation.ViewCompilationUnit, forBlock *render3.For
 
 	// Set all the context variables and aliases available in the repeater.
 	if forBlock.Item != nil {
-		repeaterView.ContextVariables[forBlock.Item.Name] = ir_variable.CTX_REF
+		repeaterView.ContextVariables[forBlock.Item.Name] = forBlock.Item.Value
 	}
 
 	for _, variable := range forBlock.ContextVariables {
@@ -1305,11 +1451,11 @@ func ingestForBlock(unit *compilation.ViewCompilationUnit, forBlock *render3.For
 			indexVarNames[variable.Name] = true
 		}
 		if variable.Name == "$index" {
-			repeaterView.ContextVariables["$index"] = ir_variable.CTX_REF
-			repeaterView.ContextVariables[indexName] = ir_variable.CTX_REF
+			repeaterView.ContextVariables["$index"] = variable.Value
+			repeaterView.ContextVariables[indexName] = variable.Value
 		} else if variable.Name == "$count" {
-			repeaterView.ContextVariables["$count"] = ir_variable.CTX_REF
-			repeaterView.ContextVariables[countName] = ir_variable.CTX_REF
+			repeaterView.ContextVariables["$count"] = variable.Value
+			repeaterView.ContextVariables[countName] = variable.Value
 		} else {
 			alias := ir_variable.NewAliasVariable(
 				variable.Name,
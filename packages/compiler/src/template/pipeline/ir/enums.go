@@ -1,5 +1,7 @@
 package ir
 
+import "fmt"
+
 // OpKind distinguishes different kinds of IR operations
 type OpKind int
 
@@ -124,6 +126,131 @@ const (
 	OpKindControlCreate
 )
 
+// String returns the name of the kind of operation, e.g. "ElementStart".
+func (k OpKind) String() string {
+	switch k {
+	case OpKindListEnd:
+		return "ListEnd"
+	case OpKindStatement:
+		return "Statement"
+	case OpKindVariable:
+		return "Variable"
+	case OpKindElementStart:
+		return "ElementStart"
+	case OpKindElement:
+		return "Element"
+	case OpKindTemplate:
+		return "Template"
+	case OpKindElementEnd:
+		return "ElementEnd"
+	case OpKindContainerStart:
+		return "ContainerStart"
+	case OpKindContainer:
+		return "Container"
+	case OpKindContainerEnd:
+		return "ContainerEnd"
+	case OpKindDisableBindings:
+		return "DisableBindings"
+	case OpKindConditionalCreate:
+		return "ConditionalCreate"
+	case OpKindConditionalBranchCreate:
+		return "ConditionalBranchCreate"
+	case OpKindConditional:
+		return "Conditional"
+	case OpKindEnableBindings:
+		return "EnableBindings"
+	case OpKindText:
+		return "Text"
+	case OpKindListener:
+		return "Listener"
+	case OpKindInterpolateText:
+		return "InterpolateText"
+	case OpKindBinding:
+		return "Binding"
+	case OpKindProperty:
+		return "Property"
+	case OpKindStyleProp:
+		return "StyleProp"
+	case OpKindClassProp:
+		return "ClassProp"
+	case OpKindStyleMap:
+		return "StyleMap"
+	case OpKindClassMap:
+		return "ClassMap"
+	case OpKindAdvance:
+		return "Advance"
+	case OpKindPipe:
+		return "Pipe"
+	case OpKindAttribute:
+		return "Attribute"
+	case OpKindExtractedAttribute:
+		return "ExtractedAttribute"
+	case OpKindDefer:
+		return "Defer"
+	case OpKindDeferOn:
+		return "DeferOn"
+	case OpKindDeferWhen:
+		return "DeferWhen"
+	case OpKindI18nMessage:
+		return "I18nMessage"
+	case OpKindDomProperty:
+		return "DomProperty"
+	case OpKindNamespace:
+		return "Namespace"
+	case OpKindProjectionDef:
+		return "ProjectionDef"
+	case OpKindProjection:
+		return "Projection"
+	case OpKindRepeaterCreate:
+		return "RepeaterCreate"
+	case OpKindRepeater:
+		return "Repeater"
+	case OpKindTwoWayProperty:
+		return "TwoWayProperty"
+	case OpKindTwoWayListener:
+		return "TwoWayListener"
+	case OpKindDeclareLet:
+		return "DeclareLet"
+	case OpKindStoreLet:
+		return "StoreLet"
+	case OpKindI18nStart:
+		return "I18nStart"
+	case OpKindI18n:
+		return "I18n"
+	case OpKindI18nEnd:
+		return "I18nEnd"
+	case OpKindI18nExpression:
+		return "I18nExpression"
+	case OpKindI18nApply:
+		return "I18nApply"
+	case OpKindIcuStart:
+		return "IcuStart"
+	case OpKindIcuEnd:
+		return "IcuEnd"
+	case OpKindIcuPlaceholder:
+		return "IcuPlaceholder"
+	case OpKindI18nContext:
+		return "I18nContext"
+	case OpKindI18nAttributes:
+		return "I18nAttributes"
+	case OpKindSourceLocation:
+		return "SourceLocation"
+	case OpKindAnimation:
+		return "Animation"
+	case OpKindAnimationString:
+		return "AnimationString"
+	case OpKindAnimationBinding:
+		return "AnimationBinding"
+	case OpKindAnimationListener:
+		return "AnimationListener"
+	case OpKindControl:
+		return "Control"
+	case OpKindControlCreate:
+		return "ControlCreate"
+	}
+	return fmt.Sprintf("OpKind(%d)", int(k))
+}
+
 // ExpressionKind distinguishes different kinds of IR expressions
 type ExpressionKind int
 
@@ -182,6 +309,65 @@ const (
 	ExpressionKindTwoWayBindingSet
 )
 
+// String returns the name of the kind of expression, e.g. "ReadVariable".
+func (k ExpressionKind) String() string {
+	switch k {
+	case ExpressionKindLexicalRead:
+		return "LexicalRead"
+	case ExpressionKindContext:
+		return "Context"
+	case ExpressionKindTrackContext:
+		return "TrackContext"
+	case ExpressionKindReadVariable:
+		return "ReadVariable"
+	case ExpressionKindNextContext:
+		return "NextContext"
+	case ExpressionKindReference:
+		return "Reference"
+	case ExpressionKindStoreLet:
+		return "StoreLet"
+	case ExpressionKindContextLetReference:
+		return "ContextLetReference"
+	case ExpressionKindGetCurrentView:
+		return "GetCurrentView"
+	case ExpressionKindRestoreView:
+		return "RestoreView"
+	case ExpressionKindResetView:
+		return "ResetView"
+	case ExpressionKindPureFunctionExpr:
+		return "PureFunctionExpr"
+	case ExpressionKindPureFunctionParameterExpr:
+		return "PureFunctionParameterExpr"
+	case ExpressionKindPipeBinding:
+		return "PipeBinding"
+	case ExpressionKindPipeBindingVariadic:
+		return "PipeBindingVariadic"
+	case ExpressionKindSafePropertyRead:
+		return "SafePropertyRead"
+	case ExpressionKindSafeKeyedRead:
+		return "SafeKeyedRead"
+	case ExpressionKindSafeInvokeFunction:
+		return "SafeInvokeFunction"
+	case ExpressionKindSafeTernaryExpr:
+		return "SafeTernaryExpr"
+	case ExpressionKindEmptyExpr:
+		return "EmptyExpr"
+	case ExpressionKindAssignTemporaryExpr:
+		return "AssignTemporaryExpr"
+	case ExpressionKindReadTemporaryExpr:
+		return "ReadTemporaryExpr"
+	case ExpressionKindSlotLiteralExpr:
+		return "SlotLiteralExpr"
+	case ExpressionKindConditionalCase:
+		return "ConditionalCase"
+	case ExpressionKindConstCollected:
+		return "ConstCollected"
+	case ExpressionKindTwoWayBindingSet:
+		return "TwoWayBindingSet"
+	}
+	return fmt.Sprintf("ExpressionKind(%d)", int(k))
+}
+
 // VariableFlags describes flags for variables
 type VariableFlags int
 
@@ -30,6 +30,7 @@ const (
 // IrExpression is an interface for IR expressions that can transform their internal expressions
 type IrExpression interface {
 	output.OutputExpression
+	GetKind() ir.ExpressionKind
 	TransformInternalExpressions(transform ExpressionTransform, flags VisitorContextFlag)
 }
 
@@ -49,6 +50,11 @@ func NewExpressionBase(kind ir.ExpressionKind, sourceSpan *util.ParseSourceSpan)
 	}
 }
 
+// GetKind returns the kind of the expression
+func (e *ExpressionBase) GetKind() ir.ExpressionKind {
+	return e.Kind
+}
+
 // GetType returns the type
 func (e *ExpressionBase) GetType() output.Type {
 	return e.Type
@@ -1480,6 +1486,24 @@ func transformExpressionsInInterpolation(
 	}
 }
 
+// transformExpressionsInBindingValue transforms a binding value, which is either an expression or an
+// interpolation.
+func transformExpressionsInBindingValue(
+	value interface{},
+	transform ExpressionTransform,
+	flags VisitorContextFlag,
+) interface{} {
+	switch v := value.(type) {
+	case *ops_update.Interpolation:
+		transformExpressionsInInterpolation(v, transform, flags)
+	case output.OutputExpression:
+		if v != nil {
+			return TransformExpressionsInExpression(v, transform, flags)
+		}
+	}
+	return value
+}
+
 // TransformExpressionsInOp transforms all expressions in an operations
 func TransformExpressionsInOp(
 	op ir_operations.Op,
@@ -1490,24 +1514,39 @@ func TransformExpressionsInOp(
 	case ir.OpKindStyleProp, ir.OpKindStyleMap, ir.OpKindClassProp, ir.OpKindClassMap,
 		ir.OpKindAnimationString, ir.OpKindAnimationBinding, ir.OpKindBinding:
 		// Handle operations with expression or interpolation
-		if bindingOp, ok := op.(*ops_update.BindingOp); ok {
-			if interpolation, ok := bindingOp.Expression.(*ops_update.Interpolation); ok {
-				transformExpressionsInInterpolation(interpolation, transform, flags)
-			} else if expr, ok := bindingOp.Expression.(output.OutputExpression); ok {
-				bindingOp.Expression = TransformExpressionsInExpression(expr, transform, flags)
-			}
+		switch o := op.(type) {
+		case *ops_update.BindingOp:
+			o.Expression = transformExpressionsInBindingValue(o.Expression, transform, flags)
+		case *ops_update.StylePropOp:
+			o.Expression = transformExpressionsInBindingValue(o.Expression, transform, flags)
+		case *ops_update.ClassPropOp:
+			o.Expression = transformExpressionsInBindingValue(o.Expression, transform, flags)
+		case *ops_update.StyleMapOp:
+			o.Expression = transformExpressionsInBindingValue(o.Expression, transform, flags)
+		case *ops_update.ClassMapOp:
+			o.Expression = transformExpressionsInBindingValue(o.Expression, transform, flags)
+		case *ops_update.AnimationBindingOp:
+			o.Expression = transformExpressionsInBindingValue(o.Expression, transform, flags)
 		}
 	case ir.OpKindProperty, ir.OpKindDomProperty, ir.OpKindAttribute, ir.OpKindControl:
 		// Handle property/attribute operations with expression and sanitizer
-		if propertyOp, ok := op.(*ops_update.PropertyOp); ok {
-			if interpolation, ok := propertyOp.Expression.(*ops_update.Interpolation); ok {
-				transformExpressionsInInterpolation(interpolation, transform, flags)
-			} else if expr, ok := propertyOp.Expression.(output.OutputExpression); ok {
-				propertyOp.Expression = TransformExpressionsInExpression(expr, transform, flags)
-			}
-			if propertyOp.Sanitizer != nil {
-				propertyOp.Sanitizer = TransformExpressionsInExpression(propertyOp.Sanitizer, transform, flags)
-			}
+		var sanitizer *output.OutputExpression
+		switch o := op.(type) {
+		case *ops_update.PropertyOp:
+			o.Expression = transformExpressionsInBindingValue(o.Expression, transform, flags)
+			sanitizer = &o.Sanitizer
+		case *ops_update.DomPropertyOp:
+			o.Expression = transformExpressionsInBindingValue(o.Expression, transform, flags)
+			sanitizer = &o.Sanitizer
+		case *ops_update.AttributeOp:
+			o.Expression = transformExpressionsInBindingValue(o.Expression, transform, flags)
+			sanitizer = &o.Sanitizer
+		case *ops_update.ControlOp:
+			o.Expression = transformExpressionsInBindingValue(o.Expression, transform, flags)
+			sanitizer = &o.Sanitizer
+		}
+		if sanitizer != nil && *sanitizer != nil {
+			*sanitizer = TransformExpressionsInExpression(*sanitizer, transform, flags)
 		}
 	case ir.OpKindTwoWayProperty:
 		if twoWayOp, ok := op.(*ops_update.TwoWayPropertyOp); ok {
@@ -1549,19 +1588,19 @@ func TransformExpressionsInOp(
 	case ir.OpKindAnimation, ir.OpKindAnimationListener, ir.OpKindListener, ir.OpKindTwoWayListener:
 		// Handle operations with handlerOps
 		if listenerOp, ok := op.(*ops_create.ListenerOp); ok && listenerOp.HandlerOps != nil {
-			for handlerOp := listenerOp.HandlerOps.Head(); handlerOp != nil && handlerOp.GetKind() != ir.OpKindListEnd; handlerOp = handlerOp.Next() {
+			for _, handlerOp := range listenerOp.HandlerOps.Ops() {
 				TransformExpressionsInOp(handlerOp, transform, flags|VisitorContextFlagInChildOperation)
 			}
 		} else if twoWayOp, ok := op.(*ops_create.TwoWayListenerOp); ok && twoWayOp.HandlerOps != nil {
-			for handlerOp := twoWayOp.HandlerOps.Head(); handlerOp != nil && handlerOp.GetKind() != ir.OpKindListEnd; handlerOp = handlerOp.Next() {
+			for _, handlerOp := range twoWayOp.HandlerOps.Ops() {
 				TransformExpressionsInOp(handlerOp, transform, flags|VisitorContextFlagInChildOperation)
 			}
 		} else if animOp, ok := op.(*ops_create.AnimationOp); ok && animOp.HandlerOps != nil {
-			for handlerOp := animOp.HandlerOps.Head(); handlerOp != nil && handlerOp.GetKind() != ir.OpKindListEnd; handlerOp = handlerOp.Next() {
+			for _, handlerOp := range animOp.HandlerOps.Ops() {
 				TransformExpressionsInOp(handlerOp, transform, flags|VisitorContextFlagInChildOperation)
 			}
 		} else if animListenerOp, ok := op.(*ops_create.AnimationListenerOp); ok && animListenerOp.HandlerOps != nil {
-			for handlerOp := animListenerOp.HandlerOps.Head(); handlerOp != nil && handlerOp.GetKind() != ir.OpKindListEnd; handlerOp = handlerOp.Next() {
+			for _, handlerOp := range animListenerOp.HandlerOps.Ops() {
 				TransformExpressionsInOp(handlerOp, transform, flags|VisitorContextFlagInChildOperation)
 			}
 		}
@@ -1579,7 +1618,7 @@ func TransformExpressionsInOp(
 			if repeaterOp.TrackByOps == nil {
 				repeaterOp.Track = TransformExpressionsInExpression(repeaterOp.Track, transform, flags)
 			} else {
-				for innerOp := repeaterOp.TrackByOps.Head(); innerOp != nil; innerOp = innerOp.Next() {
+				for _, innerOp := range repeaterOp.TrackByOps.Ops() {
 					TransformExpressionsInOp(innerOp, transform, flags|VisitorContextFlagInChildOperation)
 				}
 			}
@@ -170,14 +170,26 @@ func (o *OpBase) SetDebugListId(id *int) {
 	o.debugListId = id
 }
 
-// Head returns the head of the list
+// Head returns the first operation of the list. When the list is empty, it returns the end of
+// the list, whose kind is ir.OpKindListEnd.
 func (l *OpList) Head() Op {
-	return l.head
+	return l.head.GetNext()
 }
 
-// Tail returns the tail of the list
+// Tail returns the last operation of the list. When the list is empty, it returns the start of
+// the list, whose kind is ir.OpKindListEnd.
 func (l *OpList) Tail() Op {
-	return l.tail
+	return l.tail.GetPrev()
+}
+
+// Ops returns the operations of the list. The current operation can be removed or replaced while
+// ranging over the result.
+func (l *OpList) Ops() []Op {
+	var ops []Op
+	for op := l.head.GetNext(); op != l.tail; op = op.GetNext() {
+		ops = append(ops, op)
+	}
+	return ops
 }
 
 // Push adds an operations to the tail of the list
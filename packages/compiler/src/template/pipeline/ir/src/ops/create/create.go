@@ -25,7 +25,7 @@ type ElementOrContainerOpBase struct {
 	Xref            ir_operations.XrefId
 	Handle          *ir.SlotHandle
 	NumSlotsUsed    int
-	Attributes      ir_operations.ConstIndex
+	Attributes      *ir_operations.ConstIndex
 	LocalRefs       interface{} // []LocalRef | ir.ConstIndex | null
 	NonBindable     bool
 	StartSourceSpan *util.ParseSourceSpan
@@ -81,7 +81,7 @@ func NewElementStartOp(
 				Xref:            xref,
 				Handle:          ir.NewSlotHandle(),
 				NumSlotsUsed:    1,
-				Attributes:      0,
+				Attributes:      nil,
 				LocalRefs:       []LocalRef{},
 				NonBindable:     false,
 				StartSourceSpan: startSourceSpan,
@@ -122,7 +122,7 @@ func NewElementOp(
 				Xref:            xref,
 				Handle:          ir.NewSlotHandle(),
 				NumSlotsUsed:    1,
-				Attributes:      0,
+				Attributes:      nil,
 				LocalRefs:       []LocalRef{},
 				NonBindable:     false,
 				StartSourceSpan: startSourceSpan,
@@ -188,7 +188,7 @@ func NewContainerStartOp(
 			Xref:            xref,
 			Handle:          ir.NewSlotHandle(),
 			NumSlotsUsed:    1,
-			Attributes:      0,
+			Attributes:      nil,
 			LocalRefs:       []LocalRef{},
 			NonBindable:     false,
 			StartSourceSpan: startSourceSpan,
@@ -219,7 +219,7 @@ func NewContainerOp(
 			Xref:            xref,
 			Handle:          ir.NewSlotHandle(),
 			NumSlotsUsed:    1,
-			Attributes:      0,
+			Attributes:      nil,
 			LocalRefs:       []LocalRef{},
 			NonBindable:     false,
 			StartSourceSpan: startSourceSpan,
@@ -403,7 +403,7 @@ func NewTemplateOp(
 				Xref:            xref,
 				Handle:          ir.NewSlotHandle(),
 				NumSlotsUsed:    1,
-				Attributes:      0,
+				Attributes:      nil,
 				LocalRefs:       []LocalRef{},
 				NonBindable:     false,
 				StartSourceSpan: startSourceSpan,
@@ -453,7 +453,7 @@ func NewConditionalCreateOp(
 				Xref:            xref,
 				Handle:          ir.NewSlotHandle(),
 				NumSlotsUsed:    1,
-				Attributes:      0,
+				Attributes:      nil,
 				LocalRefs:       []LocalRef{},
 				NonBindable:     false,
 				StartSourceSpan: startSourceSpan,
@@ -503,7 +503,7 @@ func NewConditionalBranchCreateOp(
 				Xref:            xref,
 				Handle:          ir.NewSlotHandle(),
 				NumSlotsUsed:    1,
-				Attributes:      0,
+				Attributes:      nil,
 				LocalRefs:       []LocalRef{},
 				NonBindable:     false,
 				StartSourceSpan: startSourceSpan,
@@ -546,7 +546,7 @@ type RepeaterCreateOp struct {
 	UsesComponentInstance bool
 	FunctionNameSuffix    string
 	EmptyTag              *string
-	EmptyAttributes       ir_operations.ConstIndex
+	EmptyAttributes       *ir_operations.ConstIndex
 	I18nPlaceholder       interface{} // *i18n.BlockPlaceholder
 	EmptyI18nPlaceholder  interface{} // *i18n.BlockPlaceholder
 }
@@ -575,7 +575,7 @@ func NewRepeaterCreateOp(
 				Xref:            primaryView,
 				Handle:          ir.NewSlotHandle(),
 				NumSlotsUsed:    numSlotsUsed,
-				Attributes:      0,
+				Attributes:      nil,
 				LocalRefs:       []LocalRef{},
 				NonBindable:     false,
 				StartSourceSpan: startSourceSpan,
@@ -596,7 +596,7 @@ func NewRepeaterCreateOp(
 		UsesComponentInstance: false,
 		FunctionNameSuffix:    "For",
 		EmptyTag:              emptyTag,
-		EmptyAttributes:       0,
+		EmptyAttributes:       nil,
 		I18nPlaceholder:       i18nPlaceholder,
 		EmptyI18nPlaceholder:  emptyI18nPlaceholder,
 	}
@@ -664,8 +664,9 @@ func NewListenerOp(
 	sourceSpan *util.ParseSourceSpan,
 ) *ListenerOp {
 	handlerList := ir_operations.NewOpList()
-	// TODO: Add handlerOps to handlerList
-	_ = handlerList
+	for _, op := range handlerOps {
+		handlerList.Push(op)
+	}
 	return &ListenerOp{
 		OpBase:                    ir_operations.NewOpBase(),
 		Target:                    target,
@@ -720,8 +721,9 @@ func NewTwoWayListenerOp(
 	sourceSpan *util.ParseSourceSpan,
 ) *TwoWayListenerOp {
 	handlerList := ir_operations.NewOpList()
-	// TODO: Add handlerOps to handlerList
-	_ = handlerList
+	for _, op := range handlerOps {
+		handlerList.Push(op)
+	}
 	return &TwoWayListenerOp{
 		OpBase:        ir_operations.NewOpBase(),
 		Target:        target,
@@ -1295,7 +1297,7 @@ type I18nOpBase struct {
 	NumSlotsUsed     int
 	Root             ir_operations.XrefId
 	Message          *i18n.Message
-	MessageIndex     ir_operations.ConstIndex
+	MessageIndex     *ir_operations.ConstIndex
 	SubTemplateIndex *int
 	Context          ir_operations.XrefId
 	SourceSpan       *util.ParseSourceSpan
@@ -1343,7 +1345,7 @@ func NewI18nOp(
 			NumSlotsUsed:     1,
 			Root:             root,
 			Message:          message,
-			MessageIndex:     0,
+			MessageIndex:     nil,
 			SubTemplateIndex: nil,
 			Context:          0,
 			SourceSpan:       sourceSpan,
@@ -1379,7 +1381,7 @@ func NewI18nStartOp(
 			NumSlotsUsed:     1,
 			Root:             root,
 			Message:          message,
-			MessageIndex:     0,
+			MessageIndex:     nil,
 			SubTemplateIndex: nil,
 			Context:          0,
 			SourceSpan:       sourceSpan,
@@ -1590,7 +1592,7 @@ type I18nAttributesOp struct {
 	Handle               *ir.SlotHandle
 	NumSlotsUsed         int
 	Target               ir_operations.XrefId
-	I18nAttributesConfig ir_operations.ConstIndex
+	I18nAttributesConfig *ir_operations.ConstIndex
 }
 
 // NewI18nAttributesOp creates a new I18nAttributesOp
@@ -1605,7 +1607,7 @@ func NewI18nAttributesOp(
 		Handle:               handle,
 		NumSlotsUsed:         1,
 		Target:               target,
-		I18nAttributesConfig: 0,
+		I18nAttributesConfig: nil,
 	}
 }
 
@@ -1778,8 +1780,9 @@ func NewAnimationOp(
 	sourceSpan *util.ParseSourceSpan,
 ) *AnimationOp {
 	handlerOps := ir_operations.NewOpList()
-	// TODO: Add callbackOps to handlerOps
-	_ = handlerOps
+	for _, op := range callbackOps {
+		handlerOps.Push(op)
+	}
 	return &AnimationOp{
 		OpBase:          ir_operations.NewOpBase(),
 		Name:            name,
@@ -1838,8 +1841,9 @@ func NewAnimationListenerOp(
 	sourceSpan *util.ParseSourceSpan,
 ) *AnimationListenerOp {
 	handlerList := ir_operations.NewOpList()
-	// TODO: Add handlerOps to handlerList
-	_ = handlerList
+	for _, op := range handlerOps {
+		handlerList.Push(op)
+	}
 	return &AnimationListenerOp{
 		OpBase:              ir_operations.NewOpBase(),
 		Target:              target,
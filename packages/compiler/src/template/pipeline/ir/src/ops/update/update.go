@@ -205,6 +205,19 @@ func (p *PropertyOp) SetXref(xref ir_operations.XrefId) {
 	p.Target = xref
 }
 
+// HasConsumesVarsTrait implements ConsumesVarsTraitInterface
+func (p *PropertyOp) HasConsumesVarsTrait() bool {
+	return true
+}
+
+// GetDependsOnSlotContextTrait returns the DependsOnSlotContextOpTrait
+func (p *PropertyOp) GetDependsOnSlotContextTrait() *ir_traits.DependsOnSlotContextOpTrait {
+	return &ir_traits.DependsOnSlotContextOpTrait{
+		Target:     p.Target,
+		SourceSpan: p.SourceSpan,
+	}
+}
+
 // StylePropOp is an operations to bind an expression to a style property of an element
 type StylePropOp struct {
 	ir_operations.OpBase
@@ -245,6 +258,18 @@ func (s *StylePropOp) SetXref(xref ir_operations.XrefId) {
 	s.Target = xref
 }
 
+// HasConsumesVarsTrait implements ConsumesVarsTraitInterface
+func (s *StylePropOp) HasConsumesVarsTrait() bool {
+	return true
+}
+
+// GetDependsOnSlotContextTrait returns the DependsOnSlotContextOpTrait
+func (s *StylePropOp) GetDependsOnSlotContextTrait() *ir_traits.DependsOnSlotContextOpTrait {
+	return &ir_traits.DependsOnSlotContextOpTrait{
+		Target: s.Target,
+	}
+}
+
 // ClassPropOp is an operations to bind an expression to a class property of an element
 type ClassPropOp struct {
 	ir_operations.OpBase
@@ -282,6 +307,18 @@ func (c *ClassPropOp) SetXref(xref ir_operations.XrefId) {
 	c.Target = xref
 }
 
+// HasConsumesVarsTrait implements ConsumesVarsTraitInterface
+func (c *ClassPropOp) HasConsumesVarsTrait() bool {
+	return true
+}
+
+// GetDependsOnSlotContextTrait returns the DependsOnSlotContextOpTrait
+func (c *ClassPropOp) GetDependsOnSlotContextTrait() *ir_traits.DependsOnSlotContextOpTrait {
+	return &ir_traits.DependsOnSlotContextOpTrait{
+		Target: c.Target,
+	}
+}
+
 // StyleMapOp is an operations to bind an expression to the styles of an element
 type StyleMapOp struct {
 	ir_operations.OpBase
@@ -316,6 +353,18 @@ func (s *StyleMapOp) SetXref(xref ir_operations.XrefId) {
 	s.Target = xref
 }
 
+// HasConsumesVarsTrait implements ConsumesVarsTraitInterface
+func (s *StyleMapOp) HasConsumesVarsTrait() bool {
+	return true
+}
+
+// GetDependsOnSlotContextTrait returns the DependsOnSlotContextOpTrait
+func (s *StyleMapOp) GetDependsOnSlotContextTrait() *ir_traits.DependsOnSlotContextOpTrait {
+	return &ir_traits.DependsOnSlotContextOpTrait{
+		Target: s.Target,
+	}
+}
+
 // ClassMapOp is an operations to bind an expression to the classes of an element
 type ClassMapOp struct {
 	ir_operations.OpBase
@@ -350,6 +399,18 @@ func (c *ClassMapOp) SetXref(xref ir_operations.XrefId) {
 	c.Target = xref
 }
 
+// HasConsumesVarsTrait implements ConsumesVarsTraitInterface
+func (c *ClassMapOp) HasConsumesVarsTrait() bool {
+	return true
+}
+
+// GetDependsOnSlotContextTrait returns the DependsOnSlotContextOpTrait
+func (c *ClassMapOp) GetDependsOnSlotContextTrait() *ir_traits.DependsOnSlotContextOpTrait {
+	return &ir_traits.DependsOnSlotContextOpTrait{
+		Target: c.Target,
+	}
+}
+
 // AdvanceOp is an operations to advance the runtime's implicit slot context during the update phase of a view
 type AdvanceOp struct {
 	ir_operations.OpBase
@@ -438,6 +499,19 @@ func (a *AttributeOp) SetXref(xref ir_operations.XrefId) {
 	a.Target = xref
 }
 
+// HasConsumesVarsTrait implements ConsumesVarsTraitInterface
+func (a *AttributeOp) HasConsumesVarsTrait() bool {
+	return true
+}
+
+// GetDependsOnSlotContextTrait returns the DependsOnSlotContextOpTrait
+func (a *AttributeOp) GetDependsOnSlotContextTrait() *ir_traits.DependsOnSlotContextOpTrait {
+	return &ir_traits.DependsOnSlotContextOpTrait{
+		Target:     a.Target,
+		SourceSpan: a.SourceSpan,
+	}
+}
+
 // DomPropertyOp is a binding to a native DOM property
 type DomPropertyOp struct {
 	ir_operations.OpBase
@@ -482,6 +556,18 @@ func (d *DomPropertyOp) SetXref(xref ir_operations.XrefId) {
 	d.Target = xref
 }
 
+// HasConsumesVarsTrait implements ConsumesVarsTraitInterface
+func (d *DomPropertyOp) HasConsumesVarsTrait() bool {
+	return true
+}
+
+// GetDependsOnSlotContextTrait returns the DependsOnSlotContextOpTrait
+func (d *DomPropertyOp) GetDependsOnSlotContextTrait() *ir_traits.DependsOnSlotContextOpTrait {
+	return &ir_traits.DependsOnSlotContextOpTrait{
+		Target: d.Target,
+	}
+}
+
 // TwoWayPropertyOp is an operations to bind an expression to the property side of a two-way binding
 type TwoWayPropertyOp struct {
 	ir_operations.OpBase
@@ -522,6 +608,18 @@ func (t *TwoWayPropertyOp) SetXref(xref ir_operations.XrefId) {
 	t.Target = xref
 }
 
+// HasConsumesVarsTrait implements ConsumesVarsTraitInterface
+func (t *TwoWayPropertyOp) HasConsumesVarsTrait() bool {
+	return true
+}
+
+// GetDependsOnSlotContextTrait returns the DependsOnSlotContextOpTrait
+func (t *TwoWayPropertyOp) GetDependsOnSlotContextTrait() *ir_traits.DependsOnSlotContextOpTrait {
+	return &ir_traits.DependsOnSlotContextOpTrait{
+		Target: t.Target,
+	}
+}
+
 // ControlOp is an operations to bind an expression to a `field` property of an element
 type ControlOp struct {
 	ir_operations.OpBase
@@ -562,6 +660,18 @@ func (c *ControlOp) SetXref(xref ir_operations.XrefId) {
 	c.Target = xref
 }
 
+// HasConsumesVarsTrait implements ConsumesVarsTraitInterface
+func (c *ControlOp) HasConsumesVarsTrait() bool {
+	return true
+}
+
+// GetDependsOnSlotContextTrait returns the DependsOnSlotContextOpTrait
+func (c *ControlOp) GetDependsOnSlotContextTrait() *ir_traits.DependsOnSlotContextOpTrait {
+	return &ir_traits.DependsOnSlotContextOpTrait{
+		Target: c.Target,
+	}
+}
+
 // ConditionalOp is an op to conditionally render a template
 type ConditionalOp struct {
 	ir_operations.OpBase
@@ -754,11 +864,6 @@ func (r *RepeaterOp) SetXref(xref ir_operations.XrefId) {
 	r.Target = xref
 }
 
-// HasConsumesVarsTrait implements ConsumesVarsTraitInterface
-func (r *RepeaterOp) HasConsumesVarsTrait() bool {
-	return true
-}
-
 // GetDependsOnSlotContextTrait returns the DependsOnSlotContextOpTrait
 func (r *RepeaterOp) GetDependsOnSlotContextTrait() *ir_traits.DependsOnSlotContextOpTrait {
 	return &ir_traits.DependsOnSlotContextOpTrait{
@@ -1,11 +1,14 @@
 package compilation
 
 import (
+	"sort"
+
 	"ngc-go/packages/compiler/src/constant"
 	"ngc-go/packages/compiler/src/output"
 	"ngc-go/packages/compiler/src/render3/view"
 	"ngc-go/packages/compiler/src/template/pipeline/ir"
 	ir_operations "ngc-go/packages/compiler/src/template/pipeline/ir/src/operations"
+	ops_create "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/create"
 	ir_variables "ngc-go/packages/compiler/src/template/pipeline/ir/src/variable"
 )
 
@@ -40,6 +43,16 @@ type CompilationJob struct {
 	Mode          TemplateCompilationMode
 	Kind          CompilationJobKind
 	nextXrefId    ir_operations.XrefId
+	// concrete is the job embedding this one, which knows its compilation units. Phases which
+	// apply to both kinds of jobs receive the embedded job and reach the units through it.
+	concrete compilationJob
+}
+
+// compilationJob is implemented by the concrete kinds of compilation jobs.
+type compilationJob interface {
+	GetUnits() []CompilationUnit
+	GetRoot() CompilationUnit
+	GetFnSuffix() string
 }
 
 // NewCompilationJob creates a new CompilationJob
@@ -68,20 +81,32 @@ func (j *CompilationJob) AllocateXrefId() ir_operations.XrefId {
 
 // GetUnits returns all compilation units in this job
 func (j *CompilationJob) GetUnits() []CompilationUnit {
-	// This will be implemented by concrete types
-	return nil
+	if j.concrete == nil {
+		return nil
+	}
+	return j.concrete.GetUnits()
 }
 
 // GetRoot returns the root compilation unit
 func (j *CompilationJob) GetRoot() CompilationUnit {
-	// This will be implemented by concrete types
-	return nil
+	if j.concrete == nil {
+		return nil
+	}
+	return j.concrete.GetRoot()
 }
 
 // GetFnSuffix returns a unique string used to identify this kind of job
 func (j *CompilationJob) GetFnSuffix() string {
-	// This will be implemented by concrete types
-	return ""
+	if j.concrete == nil {
+		return ""
+	}
+	return j.concrete.GetFnSuffix()
+}
+
+// Concrete returns the job embedding this one: a *ComponentCompilationJob or a
+// *HostBindingCompilationJob.
+func (j *CompilationJob) Concrete() interface{} {
+	return j.concrete
 }
 
 // ComponentCompilationJob is compilation-in-progress of a whole component's template,
@@ -125,6 +150,7 @@ func NewComponentCompilationJob(
 		EnableDebugLocations:    enableDebugLocations,
 	}
 	job.CompilationJob.Kind = CompilationJobKindTmpl
+	job.CompilationJob.concrete = job
 	root := NewViewCompilationUnit(job, job.AllocateXrefId(), nil)
 	job.Root = root
 	job.Views[root.Xref] = root
@@ -138,12 +164,13 @@ func (j *ComponentCompilationJob) AllocateView(parent ir_operations.XrefId) *Vie
 	return view
 }
 
-// GetUnits returns all view compilation units
+// GetUnits returns all view compilation units, in the order they were allocated.
 func (j *ComponentCompilationJob) GetUnits() []CompilationUnit {
 	units := make([]CompilationUnit, 0, len(j.Views))
 	for _, view := range j.Views {
 		units = append(units, view)
 	}
+	sort.Slice(units, func(a, b int) bool { return units[a].GetXref() < units[b].GetXref() })
 	return units
 }
 
@@ -184,6 +211,32 @@ type CompilationUnit interface {
 	SetVars(vars int)
 }
 
+// UnitOps returns all the operations of a unit: its create operations, followed by the operations
+// of the listener handlers and track functions nested in them, and its update operations.
+func UnitOps(unit CompilationUnit) []ir_operations.Op {
+	var ops []ir_operations.Op
+	for _, op := range unit.GetCreate().Ops() {
+		ops = append(ops, op)
+		var nested *ir_operations.OpList
+		switch op := op.(type) {
+		case *ops_create.ListenerOp:
+			nested = op.HandlerOps
+		case *ops_create.TwoWayListenerOp:
+			nested = op.HandlerOps
+		case *ops_create.AnimationOp:
+			nested = op.HandlerOps
+		case *ops_create.AnimationListenerOp:
+			nested = op.HandlerOps
+		case *ops_create.RepeaterCreateOp:
+			nested = op.TrackByOps
+		}
+		if nested != nil {
+			ops = append(ops, nested.Ops()...)
+		}
+	}
+	return append(ops, unit.GetUpdate().Ops()...)
+}
+
 // ViewCompilationUnit is compilation-in-progress of an individual view within a template.
 type ViewCompilationUnit struct {
 	Job              *ComponentCompilationJob
@@ -273,6 +326,7 @@ func NewHostBindingCompilationJob(
 		CompilationJob: NewCompilationJob(componentName, pool, compatibility, mode),
 	}
 	job.CompilationJob.Kind = CompilationJobKindHost
+	job.CompilationJob.concrete = job
 	root := NewHostBindingCompilationUnit(job)
 	job.Root = root
 	return job
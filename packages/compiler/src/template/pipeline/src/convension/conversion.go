@@ -1,6 +1,7 @@
 package pipeline_convension
 
 import (
+	"ngc-go/packages/compiler/src/core"
 	"ngc-go/packages/compiler/src/output"
 	"ngc-go/packages/compiler/src/template/pipeline/ir"
 )
@@ -93,6 +94,16 @@ func LiteralOrArrayLiteral(value LiteralType) output.OutputExpression {
 			entries[i] = LiteralOrArrayLiteral(item)
 		}
 		return output.NewLiteralArrayExpr(entries, nil, nil)
+	case core.R3CssSelectorList:
+		entries := make([]output.OutputExpression, len(v))
+		for i, selector := range v {
+			entries[i] = LiteralOrArrayLiteral(selector)
+		}
+		return output.NewLiteralArrayExpr(entries, nil, nil)
+	case core.R3CssSelector:
+		return LiteralOrArrayLiteral([]interface{}(v))
+	case core.SelectorFlags:
+		return output.NewLiteralExpr(int(v), nil, nil)
 	case string:
 		return output.NewLiteralExpr(v, nil, nil)
 	case int:
@@ -12,10 +12,10 @@ import (
 func DeleteAnyCasts(job *compilation.CompilationJob) {
 	for _, unit := range job.GetUnits() {
 		// Iterate through all ops in create and update lists
-		for op := unit.GetCreate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			expression.TransformExpressionsInOp(op, removeAnys, expression.VisitorContextFlagNone)
 		}
-		for op := unit.GetUpdate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			expression.TransformExpressionsInOp(op, removeAnys, expression.VisitorContextFlagNone)
 		}
 	}
@@ -13,7 +13,7 @@ import (
 func ApplyI18nExpressions(job *compilation.CompilationJob) {
 	i18nContexts := make(map[ir_operations.XrefId]*ops_create.I18nContextOp)
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if i18nContext, ok := op.(*ops_create.I18nContextOp); ok {
 				i18nContexts[i18nContext.Xref] = i18nContext
 			}
@@ -21,7 +21,7 @@ func ApplyI18nExpressions(job *compilation.CompilationJob) {
 	}
 
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetUpdate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			// Only add apply after expressions that are not followed by more expressions.
 			if i18nExprOp, ok := op.(*ops_update.I18nExpressionOp); ok {
 				if needsApplication(i18nContexts, i18nExprOp, unit.GetUpdate()) {
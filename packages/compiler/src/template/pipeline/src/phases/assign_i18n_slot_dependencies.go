@@ -31,7 +31,7 @@ func AssignI18nSlotDependencies(job *pipeline.CompilationJob) {
 		// Non-null while we are iterating through an i18nStart/i18nEnd pair
 		var state *BlockState
 
-		for createOp := unit.GetCreate().Head(); createOp != nil; createOp = createOp.Next() {
+		for _, createOp := range unit.GetCreate().Ops() {
 			if i18nStart, ok := createOp.(*ops_create.I18nStartOp); ok {
 				state = &BlockState{
 					BlockXref:        i18nStart.Xref,
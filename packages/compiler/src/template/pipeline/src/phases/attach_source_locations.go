@@ -24,7 +24,7 @@ func AttachSourceLocations(job *pipeline.ComponentCompilationJob) {
 
 		var locations []ops_create.ElementSourceLocation
 
-		for op := viewUnit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range viewUnit.GetCreate().Ops() {
 			kind := op.GetKind()
 			if kind != ir.OpKindElementStart && kind != ir.OpKindElement {
 				continue
@@ -20,7 +20,7 @@ import (
 func ExtractAttributes(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
 		elements := pipeline_util.CreateOpXrefMap(unit)
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindAttribute:
 				extractAttributeOp(unit, op, elements, job)
@@ -137,9 +137,7 @@ func ExtractAttributes(job *pipeline.CompilationJob) {
 				if !ok {
 					continue
 				}
-				// Check if this is a legacy animation listener
-				// TODO: Check IsLegacyAnimationListener properly
-				if false { // !listenerOp.IsLegacyAnimationListener
+				if !listenerOp.IsLegacyAnimationListener {
 					extractedAttrOp := ops_create.NewExtractedAttributeOp(
 						listenerOp.Target,
 						ir.BindingKindProperty,
@@ -186,7 +184,7 @@ func ExtractAttributes(job *pipeline.CompilationJob) {
 				}
 			}
 		}
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindAttribute:
 				extractAttributeOp(unit, op, elements, job)
@@ -195,8 +193,12 @@ func ExtractAttributes(job *pipeline.CompilationJob) {
 				if !ok {
 					continue
 				}
-				var bindingKind ir.BindingKind
-				bindingKind = ir.BindingKindProperty
+				// A binding with an i18n message is an i18n attribute, and has that kind in the
+				// consts array.
+				bindingKind := ir.BindingKindProperty
+				if propertyOp.I18nMessage != nil {
+					bindingKind = ir.BindingKindI18n
+				}
 
 				extractedAttrOp := ops_create.NewExtractedAttributeOp(
 					propertyOp.Target,
@@ -390,8 +392,8 @@ func extractAttributeOp(
 			nil, // namespace - TODO: get from attributeOp
 			attributeOp.Name,
 			expr,
-			0,                        // i18nContext - TODO: get from attributeOp
-			nil,                      // i18nMessage - TODO: get from attributeOp
+			attributeOp.I18nContext,
+			attributeOp.I18nMessage,
 			core.SecurityContextNONE, // securityContext - TODO: get from attributeOp
 		)
 
@@ -19,7 +19,7 @@ import (
 func SpecializeBindings(job *pipeline.CompilationJob) {
 	elements := make(map[operations.XrefId]operations.CreateOp)
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			createOp, ok := op.(operations.CreateOp)
 			if !ok {
 				continue
@@ -32,13 +32,13 @@ func SpecializeBindings(job *pipeline.CompilationJob) {
 
 	for _, unit := range job.GetUnits() {
 		// Process create ops
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if op.GetKind() == ir.OpKindBinding {
 				specializeBindingOp(unit, op, elements, job)
 			}
 		}
 		// Process update ops
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			if op.GetKind() == ir.OpKindBinding {
 				specializeBindingOp(unit, op, elements, job)
 			}
@@ -96,6 +96,9 @@ func specializeBindingOp(
 				bindingOp.IsStructuralTemplateAttribute,
 				bindingOp.TemplateKind,
 			)
+			attrOp.I18nContext = bindingOp.I18nContext
+			attrOp.I18nMessage = bindingOp.I18nMessage
+			attrOp.SourceSpan = bindingOp.SourceSpan
 			unit.GetUpdate().Replace(op, attrOp)
 		}
 	case ir.BindingKindAnimation:
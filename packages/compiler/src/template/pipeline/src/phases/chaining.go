@@ -83,7 +83,7 @@ type chain struct {
 func chainOperationsInList(opList *operations.OpList) {
 	var currentChain *chain = nil
 
-	for op := opList.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range opList.Ops() {
 		if op.GetKind() != ir.OpKindStatement {
 			currentChain = nil
 			continue
@@ -130,7 +130,7 @@ func chainOperationsInList(opList *operations.OpList) {
 			// This instruction can be added onto the previous chain.
 			chainedExpr := callFn(currentChain.expression, invokeExpr.Args, invokeExpr.SourceSpan, invokeExpr.Pure)
 			currentChain.expression = chainedExpr
-			stmtOp.Statement = toStmt(chainedExpr, invokeExpr.SourceSpan)
+			currentChain.op.Statement = toStmt(chainedExpr, invokeExpr.SourceSpan)
 			currentChain.length++
 			opList.Remove(op)
 		} else {
@@ -16,7 +16,7 @@ import (
 // pipeline allows other phases to accurately know what instruction will be emitted.
 func CollapseSingletonInterpolations(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetUpdate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			kind := op.GetKind()
 			eligibleOpKind := kind == ir.OpKindAttribute ||
 				kind == ir.OpKindStyleProp ||
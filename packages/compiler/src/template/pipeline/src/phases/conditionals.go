@@ -13,7 +13,7 @@ import (
 // GenerateConditionalExpressions collapses the various conditions of conditional ops (if, switch) into a single test expression.
 func GenerateConditionalExpressions(job *pipeline.ComponentCompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if op.GetKind() != ir.OpKindConditional {
 				continue
 			}
@@ -112,7 +112,7 @@ func GenerateConditionalExpressions(job *pipeline.ComponentCompilationJob) {
 			conditionalOp.Conditions = []interface{}{}
 		}
 
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			if op.GetKind() != ir.OpKindConditional {
 				continue
 			}
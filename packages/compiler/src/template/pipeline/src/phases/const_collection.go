@@ -2,6 +2,7 @@ package phases
 
 import (
 	"ngc-go/packages/compiler/src/core"
+	"ngc-go/packages/compiler/src/css"
 	"ngc-go/packages/compiler/src/output"
 	"ngc-go/packages/compiler/src/template/pipeline/ir"
 	"ngc-go/packages/compiler/src/template/pipeline/ir/src/expression"
@@ -18,7 +19,7 @@ func CollectElementConsts(job *compilation.CompilationJob) {
 	// Collect all extracted attributes.
 	allElementAttributes := make(map[operations.XrefId]*ElementAttributes)
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if op.GetKind() == ir.OpKindExtractedAttribute {
 				extractedAttrOp, ok := op.(*ops_create.ExtractedAttributeOp)
 				if !ok {
@@ -60,7 +61,7 @@ func CollectElementConsts(job *compilation.CompilationJob) {
 			return
 		}
 		for _, unit := range componentJob.GetUnits() {
-			for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+			for _, op := range unit.GetCreate().Ops() {
 				// TODO: Simplify and combine these cases.
 				if op.GetKind() == ir.OpKindProjection {
 					projectionOp, ok := op.(*ops_create.ProjectionOp)
@@ -133,15 +134,16 @@ func getConstIndex(
 	job *compilation.ComponentCompilationJob,
 	allElementAttributes map[operations.XrefId]*ElementAttributes,
 	xref operations.XrefId,
-) operations.ConstIndex {
+) *operations.ConstIndex {
 	attributes, exists := allElementAttributes[xref]
 	if exists {
 		attrArray := serializeAttributes(attributes)
 		if len(attrArray.Entries) > 0 {
-			return job.AddConst(attrArray, nil)
+			constIndex := job.AddConst(attrArray, nil)
+			return &constIndex
 		}
 	}
-	return 0
+	return nil
 }
 
 // ElementAttributes is a container for all of the various kinds of attributes which are applied on an element.
@@ -321,7 +323,7 @@ func serializeAttributes(attrs *ElementAttributes) *output.LiteralArrayExpr {
 		// Parse the attribute value into a CssSelectorList. Note that we only take the
 		// first selector, because we don't support multiple selectors in ngProjectAs.
 		selectorStr := attrs.projectAs
-		parsedR3Selector := core.ParseSelectorToR3Selector(selectorStr)
+		parsedR3Selector := css.ParseSelectorToR3Selector(selectorStr)
 		if len(parsedR3Selector) > 0 {
 			attrArray = append(attrArray,
 				output.NewLiteralExpr(int(core.AttributeMarkerProjectAs), nil, nil),
@@ -15,7 +15,7 @@ import (
 func ConvertAnimations(job *pipeline.CompilationJob) {
 	elements := make(map[operations.XrefId]operations.CreateOp)
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			createOp, ok := op.(operations.CreateOp)
 			if !ok {
 				continue
@@ -29,7 +29,7 @@ func ConvertAnimations(job *pipeline.CompilationJob) {
 
 	for _, unit := range job.GetUnits() {
 		// Process both create and update operations
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if op.GetKind() == ir.OpKindAnimationBinding {
 				animBindingOp, ok := op.(*ops_update.AnimationBindingOp)
 				if !ok {
@@ -45,7 +45,7 @@ func ConvertAnimations(job *pipeline.CompilationJob) {
 				unit.GetCreate().Remove(op)
 			}
 		}
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			if op.GetKind() == ir.OpKindAnimationBinding {
 				animBindingOp, ok := op.(*ops_update.AnimationBindingOp)
 				if !ok {
@@ -17,7 +17,7 @@ import (
 func ConvertI18nBindings(job *pipeline.CompilationJob) {
 	i18nAttributesByElem := make(map[operations.XrefId]*ops_create.I18nAttributesOp)
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if op.GetKind() == ir.OpKindI18nAttributes {
 				i18nAttributesOp, ok := op.(*ops_create.I18nAttributesOp)
 				if ok {
@@ -26,7 +26,7 @@ func ConvertI18nBindings(job *pipeline.CompilationJob) {
 			}
 		}
 
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindProperty:
 				propertyOp, ok := op.(*ops_update.PropertyOp)
@@ -20,7 +20,7 @@ func CreateI18nContexts(job *pipeline.CompilationJob) {
 	// Create i18n context ops for i18n attrs.
 	attrContextByMessage := make(map[*i18n.Message]operations.XrefId)
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindBinding:
 				bindingOp, ok := op.(*ops_update.BindingOp)
@@ -78,7 +78,7 @@ func CreateI18nContexts(job *pipeline.CompilationJob) {
 				extractedAttrOp.I18nContext = attrContextByMessage[extractedAttrOp.I18nMessage]
 			}
 		}
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindBinding:
 				bindingOp, ok := op.(*ops_update.BindingOp)
@@ -104,11 +104,45 @@ func CreateI18nContexts(job *pipeline.CompilationJob) {
 				}
 				bindingOp.I18nContext = attrContextByMessage[bindingOp.I18nMessage]
 			case ir.OpKindProperty:
-				// PropertyOp doesn't have I18nMessage field directly, skip for now
-				continue
+				propertyOp, ok := op.(*ops_update.PropertyOp)
+				if !ok || propertyOp.I18nMessage == nil {
+					continue
+				}
+				if _, exists := attrContextByMessage[propertyOp.I18nMessage]; !exists {
+					i18nContext, err := ops_create.NewI18nContextOp(
+						ir.I18nContextKindAttr,
+						job.AllocateXrefId(),
+						0, // i18nBlock - not needed for attr context
+						propertyOp.I18nMessage,
+						nil, // sourceSpan
+					)
+					if err != nil {
+						panic(err)
+					}
+					unit.GetCreate().Push(i18nContext)
+					attrContextByMessage[propertyOp.I18nMessage] = i18nContext.Xref
+				}
+				propertyOp.I18nContext = attrContextByMessage[propertyOp.I18nMessage]
 			case ir.OpKindAttribute:
-				// AttributeOp doesn't have I18nMessage field directly, skip for now
-				continue
+				attributeOp, ok := op.(*ops_update.AttributeOp)
+				if !ok || attributeOp.I18nMessage == nil {
+					continue
+				}
+				if _, exists := attrContextByMessage[attributeOp.I18nMessage]; !exists {
+					i18nContext, err := ops_create.NewI18nContextOp(
+						ir.I18nContextKindAttr,
+						job.AllocateXrefId(),
+						0, // i18nBlock - not needed for attr context
+						attributeOp.I18nMessage,
+						nil, // sourceSpan
+					)
+					if err != nil {
+						panic(err)
+					}
+					unit.GetCreate().Push(i18nContext)
+					attrContextByMessage[attributeOp.I18nMessage] = i18nContext.Xref
+				}
+				attributeOp.I18nContext = attrContextByMessage[attributeOp.I18nMessage]
 			case ir.OpKindExtractedAttribute:
 				extractedAttrOp, ok := op.(*ops_create.ExtractedAttributeOp)
 				if !ok {
@@ -139,7 +173,7 @@ func CreateI18nContexts(job *pipeline.CompilationJob) {
 	// Create i18n context ops for root i18n blocks.
 	blockContextByI18nBlock := make(map[operations.XrefId]*ops_create.I18nContextOp)
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if op.GetKind() == ir.OpKindI18nStart {
 				i18nStartOp, ok := op.(*ops_create.I18nStartOp)
 				if !ok {
@@ -167,7 +201,7 @@ func CreateI18nContexts(job *pipeline.CompilationJob) {
 	// Assign i18n contexts for child i18n blocks. These don't need their own context, instead they
 	// should inherit from their root i18n block.
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if op.GetKind() == ir.OpKindI18nStart {
 				i18nStartOp, ok := op.(*ops_create.I18nStartOp)
 				if !ok {
@@ -188,7 +222,7 @@ func CreateI18nContexts(job *pipeline.CompilationJob) {
 	// Create or assign i18n contexts for ICUs.
 	var currentI18nOp *ops_create.I18nStartOp = nil
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindI18nStart:
 				if i18nStartOp, ok := op.(*ops_create.I18nStartOp); ok {
@@ -13,7 +13,9 @@ func DeduplicateTextBindings(job *pipeline.CompilationJob) {
 	seen := make(map[operations.XrefId]map[string]bool)
 	for _, unit := range job.GetUnits() {
 		// Iterate in reverse order
-		for op := unit.GetUpdate().Tail(); op != nil; op = op.GetPrev() {
+		ops := unit.GetUpdate().Ops()
+		for i := len(ops) - 1; i >= 0; i-- {
+			op := ops[i]
 			if bindingOp, ok := op.(*ops_update.BindingOp); ok && bindingOp.IsTextAttribute {
 				seenForElement, exists := seen[bindingOp.Target]
 				if !exists {
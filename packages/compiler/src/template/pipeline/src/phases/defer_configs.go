@@ -13,7 +13,7 @@ import (
 // Defer instructions take a configuration array, which should be collected into the component consts.
 func ConfigureDeferInstructions(job *compilation.ComponentCompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if op.GetKind() != ir.OpKindDefer {
 				continue
 			}
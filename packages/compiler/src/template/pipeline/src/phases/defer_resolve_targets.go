@@ -35,7 +35,7 @@ func ResolveDeferTargetNames(job *pipeline.ComponentCompilationJob) {
 		scope := &ScopeDefer{
 			Targets: make(map[string]TargetInfo),
 		}
-		for op := view.Create.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range view.Create.Ops() {
 
 			createOp, convertOk := op.(operations.CreateOp)
 			if !convertOk {
@@ -136,7 +136,7 @@ func ResolveDeferTargetNames(job *pipeline.ComponentCompilationJob) {
 				if !exists {
 					panic("AssertionError: could not find placeholder view for defer on trigger")
 				}
-				for op := placeholder.Create.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+				for _, op := range placeholder.Create.Ops() {
 					if ir_traits.HasConsumesSlotTrait(op) {
 						createOp, ok := op.(operations.CreateOp)
 						if !ok {
@@ -195,7 +195,7 @@ func ResolveDeferTargetNames(job *pipeline.ComponentCompilationJob) {
 			continue
 		}
 		defers := make(map[operations.XrefId]*ops_create.DeferOp)
-		for op := viewUnit.Create.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range viewUnit.Create.Ops() {
 			switch op.GetKind() {
 			case ir.OpKindDefer:
 				deferOp, ok := op.(*ops_create.DeferOp)
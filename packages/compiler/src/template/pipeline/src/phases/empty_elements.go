@@ -24,7 +24,7 @@ var ignoredOpKinds = map[ir.OpKind]bool{
 // with a consolidated instruction (e.g. `Element`).
 func CollapseEmptyInstructions(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			// Find end ops that may be able to be merged.
 			opReplacement, ok := replacements[op.GetKind()]
 			if !ok {
@@ -22,13 +22,13 @@ type SafeTransformContext struct {
 func ExpandSafeReads(job *pipeline.CompilationJob) {
 	ctx := &SafeTransformContext{Job: job}
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			expression.TransformExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
 				return safeTransform(expr, ctx)
 			}, expression.VisitorContextFlagNone)
 			expression.TransformExpressionsInOp(op, ternaryTransform, expression.VisitorContextFlagNone)
 		}
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			expression.TransformExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
 				return safeTransform(expr, ctx)
 			}, expression.VisitorContextFlagNone)
@@ -40,7 +40,7 @@ func ExtractI18nMessages(job *pipeline.CompilationJob) {
 	i18nBlocks := make(map[operations.XrefId]*ops_create.I18nStartOp)
 	i18nContexts := make(map[operations.XrefId]*ops_create.I18nContextOp)
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindI18nContext:
 				i18nContextOp, ok := op.(*ops_create.I18nContextOp)
@@ -65,7 +65,7 @@ func ExtractI18nMessages(job *pipeline.CompilationJob) {
 	// ICU start/end ops, as they are no longer needed.
 	var currentIcu *ops_create.IcuStartOp = nil
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindIcuStart:
 				icuStartOp, ok := op.(*ops_create.IcuStartOp)
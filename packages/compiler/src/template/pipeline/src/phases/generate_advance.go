@@ -18,7 +18,7 @@ func GenerateAdvance(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
 		// First build a map of all of the declarations in the view that have assigned slots.
 		slotMap := make(map[operations.XrefId]int)
-		for op := unit.GetCreate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if !ir_traits.HasConsumesSlotTrait(op) {
 				continue
 			}
@@ -39,7 +39,7 @@ func GenerateAdvance(job *pipeline.CompilationJob) {
 		//
 		// To do that, we track what the runtime's slot counter will be through the update operations.
 		slotContext := 0
-		for op := unit.GetUpdate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			var consumer *ir_traits.DependsOnSlotContextOpTrait
 
 			if ir_traits.HasDependsOnSlotContextTrait(op) {
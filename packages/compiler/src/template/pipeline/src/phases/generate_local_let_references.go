@@ -14,7 +14,7 @@ import (
 // used to reference the value within the same view.
 func GenerateLocalLetReferences(job *pipeline.ComponentCompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetUpdate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			if storeLetOp, ok := op.(*ops_update.StoreLetOp); ok {
 				varDecl := ir_variable.NewIdentifierVariable(storeLetOp.DeclaredName, true)
 
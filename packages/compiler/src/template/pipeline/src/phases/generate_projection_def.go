@@ -1,9 +1,10 @@
 package phases
 
 import (
-	"ngc-go/packages/compiler/src/core"
+	"ngc-go/packages/compiler/src/css"
 	"ngc-go/packages/compiler/src/output"
 	"ngc-go/packages/compiler/src/template/pipeline/ir"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/operations"
 	ops_create "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/create"
 
 	"ngc-go/packages/compiler/src/template/pipeline/src/compilation"
@@ -23,7 +24,7 @@ func GenerateProjectionDefs(job *compilation.ComponentCompilationJob) {
 	selectors := make([]string, 0)
 	projectionSlotIndex := 0
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if projectionOp, ok := op.(*ops_create.ProjectionOp); ok {
 				selectors = append(selectors, projectionOp.Selector)
 				projectionOp.ProjectionSlotIndex = projectionSlotIndex
@@ -43,7 +44,7 @@ func GenerateProjectionDefs(job *compilation.ComponentCompilationJob) {
 					def[i] = s
 				} else {
 					selectorPtr := &s
-					r3Selector := core.ParseSelectorToR3Selector(selectorPtr)
+					r3Selector := css.ParseSelectorToR3Selector(selectorPtr)
 					// Convert R3CssSelectorList to interface{} for LiteralOrArrayLiteral
 					def[i] = r3Selector
 				}
@@ -65,12 +66,7 @@ func GenerateProjectionDefs(job *compilation.ComponentCompilationJob) {
 		if defExpr != nil {
 			projectionDefOp := ops_create.NewProjectionDefOp(defExpr)
 			// Insert at the beginning of the create list
-			rootCreate := job.Root.GetCreate()
-			if rootCreate.Head() != nil {
-				rootCreate.InsertBefore(rootCreate.Head(), projectionDefOp)
-			} else {
-				rootCreate.Push(projectionDefOp)
-			}
+			job.Root.GetCreate().Prepend([]operations.Op{projectionDefOp})
 		}
 	}
 }
@@ -1,6 +1,8 @@
 package phases
 
 import (
+	"sort"
+
 	"ngc-go/packages/compiler/src/output"
 	"ngc-go/packages/compiler/src/template/pipeline/ir"
 	"ngc-go/packages/compiler/src/template/pipeline/ir/src/expression"
@@ -87,7 +89,7 @@ func recursivelyProcessView(view *pipeline.ViewCompilationUnit, parentScope *Sco
 	// Extract a `Scope` from this view.
 	scope := getScopeForView(view, parentScope)
 
-	for op := view.Create.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range view.Create.Ops() {
 		switch op.GetKind() {
 		case ir.OpKindConditionalCreate:
 			conditionalOp, ok := op.(*ops_create.ConditionalCreateOp)
@@ -183,7 +185,7 @@ func getScopeForView(view *pipeline.ViewCompilationUnit, parent *Scope) *Scope {
 		_ = value // value is stored but not used in scope
 	}
 
-	for op := view.Create.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range view.Create.Ops() {
 		switch op.GetKind() {
 		case ir.OpKindElementStart, ir.OpKindConditionalCreate, ir.OpKindConditionalBranchCreate, ir.OpKindTemplate:
 			var localRefs []ops_create.LocalRef
@@ -287,7 +289,15 @@ func generateVariablesInScopeForView(
 
 	// Add variables for all context variables available in this scope's view.
 	scopeView := view.Job.Views[scope.View]
-	for name, value := range scopeView.ContextVariables {
+	// Maps have no iteration order, so walk the variables in a stable order to keep the generated
+	// code deterministic.
+	contextVariableNames := make([]string, 0, len(scopeView.ContextVariables))
+	for name := range scopeView.ContextVariables {
+		contextVariableNames = append(contextVariableNames, name)
+	}
+	sort.Strings(contextVariableNames)
+	for _, name := range contextVariableNames {
+		value := scopeView.ContextVariables[name]
 		context := expression.NewContextExpr(scope.View)
 		// We either read the context, or, if the variable is CTX_REF, use the context directly.
 		var variable output.OutputExpression
@@ -305,7 +315,12 @@ func generateVariablesInScopeForView(
 		))
 	}
 
+	aliases := make([]ir_variable.AliasVariable, 0, len(scopeView.Aliases))
 	for alias := range scopeView.Aliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Identifier < aliases[j].Identifier })
+	for _, alias := range aliases {
 		// Create a copy of alias to avoid modifying the map key
 		aliasCopy := alias
 		newOps = append(newOps, shared.NewVariableOp(
@@ -2,7 +2,6 @@ package phases
 
 import (
 	"ngc-go/packages/compiler/src/output"
-	"ngc-go/packages/compiler/src/template/pipeline/ir"
 	"ngc-go/packages/compiler/src/template/pipeline/ir/src/expression"
 
 	pipeline "ngc-go/packages/compiler/src/template/pipeline/src/compilation"
@@ -14,7 +13,7 @@ import (
 // usage site. This phase walks the IR and performs this transformation.
 func CollectConstExpressions(job *pipeline.ComponentCompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			expression.TransformExpressionsInOp(
 				op,
 				func(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
@@ -28,7 +27,7 @@ func CollectConstExpressions(job *pipeline.ComponentCompilationJob) {
 				expression.VisitorContextFlagNone,
 			)
 		}
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			expression.TransformExpressionsInOp(
 				op,
 				func(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
@@ -36,7 +36,7 @@ func ParseHostStyleProperties(job *compilation.CompilationJob) {
 		return
 	}
 
-	for op := hostUnit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range hostUnit.GetUpdate().Ops() {
 		if op.GetKind() != ir.OpKindBinding {
 			continue
 		}
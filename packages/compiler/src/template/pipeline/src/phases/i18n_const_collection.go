@@ -1,16 +1,373 @@
 package phases
 
 import (
-	"ngc-go/packages/compiler/src/template/pipeline/src/compilation"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"ngc-go/packages/compiler/src/constant"
+	i18n "ngc-go/packages/compiler/src/i18n"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3/r3_identifiers"
+	viewi18n "ngc-go/packages/compiler/src/render3/view/i18n"
+	"ngc-go/packages/compiler/src/template/pipeline/ir"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/operations"
+	ops_create "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/create"
+	ops_update "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/update"
+	"ngc-go/packages/compiler/src/util"
+
+	pipeline "ngc-go/packages/compiler/src/template/pipeline/src/compilation"
 )
 
+const (
+	// NG_I18N_CLOSURE_MODE is the name of the global variable that is used to determine if we use
+	// Closure translations or not.
+	NG_I18N_CLOSURE_MODE = "ngI18nClosureMode"
+
+	// TRANSLATION_VAR_PREFIX is the prefix for non-`goog.getMsg` i18n-related vars.
+	// Note: the prefix uses lowercase characters intentionally due to a Closure behavior that
+	// considers variables like `I18N_0` as constants and throws an error when their value changes.
+	TRANSLATION_VAR_PREFIX = "i18n_"
+
+	// I18N_ICU_MAPPING_PREFIX is the prefix of ICU expressions for post processing.
+	I18N_ICU_MAPPING_PREFIX = "I18N_EXP_"
+
+	// CLOSURE_TRANSLATION_VAR_PREFIX is the prefix of the Closure variables holding messages,
+	// which must be named `MSG_[A-Z0-9]+`.
+	CLOSURE_TRANSLATION_VAR_PREFIX = "MSG_"
+)
+
+var nonIdentifierChars = regexp.MustCompile(`[^A-Za-z0-9]`)
+
+// GetTranslationConstPrefix generates a prefix for translation const name, injecting extra as a
+// local prefix.
+func GetTranslationConstPrefix(extra string) string {
+	return strings.ToUpper(CLOSURE_TRANSLATION_VAR_PREFIX + extra)
+}
+
+// DeclareI18nVariable generates AST to declare a variable. E.g. `var I18N_1;`.
+func DeclareI18nVariable(variable *output.ReadVarExpr) output.OutputStatement {
+	return output.NewDeclareVarStmt(variable.Name, nil, output.InferredType, output.StmtModifierNone, variable.GetSourceSpan(), nil)
+}
+
 // CollectI18nConsts lifts i18n properties into the consts array.
-// This is a placeholder implementation - full implementation should be added later.
-func CollectI18nConsts(job *compilation.ComponentCompilationJob) {
-	// TODO: Implement full i18n const collection logic
-	// This should:
-	// - Build lookup maps for i18n contexts, attributes, and expressions
-	// - Serialize extracted i18n messages for root i18n blocks and i18n attributes
-	// - Serialize I18nAttributes configurations into the const array
-	// - Propagate extracted const index into i18n ops
+// TODO: Can we use `ConstCollectedExpr`?
+// TODO: The way the various attributes are linked together is very complex. Perhaps we could
+// simplify the process, maybe by combining the context and message ops?
+func CollectI18nConsts(job *pipeline.ComponentCompilationJob) {
+	fileBasedI18nSuffix := strings.ToUpper(nonIdentifierChars.ReplaceAllString(job.RelativeContextFilePath, "_")) + "_"
+	// Step One: Build up various lookup maps we need to collect all the consts.
+
+	// Context Xref -> Extracted Attribute Ops
+	extractedAttributesByI18nContext := make(map[operations.XrefId][]*ops_create.ExtractedAttributeOp)
+	// Element/ElementStart Xref -> I18n Attributes config op
+	i18nAttributesByElement := make(map[operations.XrefId]*ops_create.I18nAttributesOp)
+	// Element/ElementStart Xref -> All I18n Expression ops for attrs on that target
+	i18nExpressionsByElement := make(map[operations.XrefId][]*ops_update.I18nExpressionOp)
+	// I18n Message Xref -> I18n Message Op (TODO: use a central op map)
+	messages := make(map[operations.XrefId]*ops_create.I18nMessageOp)
+
+	for _, unit := range job.GetUnits() {
+		for _, op := range pipeline.UnitOps(unit) {
+			switch op := op.(type) {
+			case *ops_create.ExtractedAttributeOp:
+				if op.I18nContext != 0 {
+					extractedAttributesByI18nContext[op.I18nContext] = append(extractedAttributesByI18nContext[op.I18nContext], op)
+				}
+			case *ops_create.I18nAttributesOp:
+				i18nAttributesByElement[op.Target] = op
+			case *ops_update.I18nExpressionOp:
+				if op.Usage == ir.I18nExpressionForI18nAttribute {
+					i18nExpressionsByElement[op.Target] = append(i18nExpressionsByElement[op.Target], op)
+				}
+			case *ops_create.I18nMessageOp:
+				messages[op.Xref] = op
+			}
+		}
+	}
+
+	// Step Two: Serialize the extracted i18n messages for root i18n blocks and i18n attributes into
+	// the const array.
+	//
+	// Also, each i18n message will have a variable expression that can refer to its
+	// value. Store these expressions in the appropriate place:
+	// 1. For normal i18n content, it also goes in the const array. We save the const index to use
+	// later.
+	// 2. For extracted attributes, it becomes the value of the extracted attribute instruction.
+	// 3. For i18n bindings, it will go in a separate const array instruction below; for now, we
+	// just save it.
+
+	i18nValuesByContext := make(map[operations.XrefId]output.OutputExpression)
+	messageConstIndices := make(map[operations.XrefId]operations.ConstIndex)
+
+	for _, unit := range job.GetUnits() {
+		for _, op := range unit.GetCreate().Ops() {
+			messageOp, ok := op.(*ops_create.I18nMessageOp)
+			if !ok {
+				continue
+			}
+			if messageOp.MessagePlaceholder == nil {
+				mainVar, statements := collectMessage(job, fileBasedI18nSuffix, messages, messageOp)
+				if messageOp.I18nBlock != 0 {
+					// This is a regular i18n message with a corresponding i18n block. Collect it
+					// into the const array.
+					messageConstIndices[messageOp.I18nBlock] = job.AddConst(mainVar, statements)
+				} else {
+					// This is an i18n attribute. Extract the initializers into the const pool.
+					job.ConstsInitializers = append(job.ConstsInitializers, statements...)
+
+					// Save the i18n variable value for later.
+					i18nValuesByContext[messageOp.I18nContext] = mainVar
+
+					// This i18n message may correspond to an individual extracted attribute. If
+					// so, the value of that attribute is updated to read the extracted i18n
+					// variable.
+					for _, attr := range extractedAttributesByI18nContext[messageOp.I18nContext] {
+						attr.Expression = mainVar.Clone()
+					}
+				}
+			}
+			unit.GetCreate().Remove(op)
+		}
+	}
+
+	// Step Three: Serialize I18nAttributes configurations into the const array. Each
+	// I18nAttributes instruction has a config array, which contains k-v pairs describing each
+	// binding name, and the i18n variable that provides the value.
+
+	for _, unit := range job.GetUnits() {
+		for _, elem := range unit.GetCreate().Ops() {
+			createOp, ok := elem.(operations.CreateOp)
+			if !ok || !ops_create.IsElementOrContainerOp(createOp) {
+				continue
+			}
+			i18nAttributes, ok := i18nAttributesByElement[createOp.GetXref()]
+			if !ok {
+				// This element is not associated with an i18n attributes configuration
+				// instruction.
+				continue
+			}
+
+			i18nExpressions, ok := i18nExpressionsByElement[createOp.GetXref()]
+			if !ok {
+				// Unused i18nAttributes should have already been removed.
+				// TODO: Should the removal of those dead instructions be merged with this phase?
+				panic("AssertionError: Could not find any i18n expressions associated with an I18nAttributes instruction")
+			}
+
+			// Find expressions for all the unique property names, removing duplicates.
+			seenPropertyNames := make(map[string]bool)
+			var i18nAttributeConfig []output.OutputExpression
+			for _, i18nExpr := range i18nExpressions {
+				if seenPropertyNames[i18nExpr.Name] {
+					continue
+				}
+				seenPropertyNames[i18nExpr.Name] = true
+
+				i18nExprValue, ok := i18nValuesByContext[i18nExpr.Context]
+				if !ok {
+					panic("AssertionError: Could not find i18n expression's value")
+				}
+				i18nAttributeConfig = append(i18nAttributeConfig, output.NewLiteralExpr(i18nExpr.Name, nil, nil), i18nExprValue)
+			}
+
+			configIndex := job.AddConst(output.NewLiteralArrayExpr(i18nAttributeConfig, nil, nil), nil)
+			i18nAttributes.I18nAttributesConfig = &configIndex
+		}
+	}
+
+	// Step Four: Propagate the extracted const index into i18n ops that messages were extracted
+	// from.
+
+	for _, unit := range job.GetUnits() {
+		for _, op := range unit.GetCreate().Ops() {
+			i18nStartOp, ok := op.(*ops_create.I18nStartOp)
+			if !ok {
+				continue
+			}
+			msgIndex, ok := messageConstIndices[i18nStartOp.Root]
+			if !ok {
+				panic("AssertionError: Could not find corresponding i18n block index for an i18n message op; was an i18n message incorrectly assumed to correspond to an attribute?")
+			}
+			i18nStartOp.MessageIndex = &msgIndex
+		}
+	}
+}
+
+// collectMessage collects the given message into a set of statements that can be added to the
+// const array. This will recursively collect any sub-messages referenced from the parent message
+// as well.
+func collectMessage(
+	job *pipeline.ComponentCompilationJob,
+	fileBasedI18nSuffix string,
+	messages map[operations.XrefId]*ops_create.I18nMessageOp,
+	messageOp *ops_create.I18nMessageOp,
+) (*output.ReadVarExpr, []output.OutputStatement) {
+	// Recursively collect any sub-messages, record each sub-message's main variable under its
+	// placeholder so that we can add them to the params for the parent message. It is possible
+	// that multiple sub-messages will share the same placeholder, so we need to track an array of
+	// variables for each placeholder.
+	var statements []output.OutputStatement
+	subMessagePlaceholders := make(map[string][]output.OutputExpression)
+	var placeholderOrder []string
+	for _, subMessageID := range messageOp.SubMessages {
+		subMessage := messages[subMessageID]
+		subMessageVar, subMessageStatements := collectMessage(job, fileBasedI18nSuffix, messages, subMessage)
+		statements = append(statements, subMessageStatements...)
+		placeholder := *subMessage.MessagePlaceholder
+		if _, ok := subMessagePlaceholders[placeholder]; !ok {
+			placeholderOrder = append(placeholderOrder, placeholder)
+		}
+		subMessagePlaceholders[placeholder] = append(subMessagePlaceholders[placeholder], subMessageVar)
+	}
+	addSubMessageParams(messageOp, placeholderOrder, subMessagePlaceholders)
+
+	mainVar := output.NewReadVarExpr(job.Pool.UniqueName(TRANSLATION_VAR_PREFIX, true), nil, nil)
+	// Closure Compiler requires const names to start with `MSG_` but disallows any other
+	// const to start with `MSG_`. We define a variable starting with `MSG_` just for the
+	// `goog.getMsg` call
+	closureVar := i18nGenerateClosureVar(job.Pool, messageOp.Message.ID, fileBasedI18nSuffix, job.I18nUseExternalIds)
+	var transformFn func(*output.ReadVarExpr) output.OutputExpression
+
+	// If necessary, add a post-processing step and resolve any placeholder params that are
+	// set in post-processing.
+	if messageOp.NeedsPostprocessing || len(messageOp.PostprocessingParams) > 0 {
+		var extraTransformFnParams []output.OutputExpression
+		if len(messageOp.PostprocessingParams) > 0 {
+			// Sort the post-processing params for consistency with TemplateDefinitionBuilder
+			// output.
+			formatted := viewi18n.FormatI18nPlaceholderNamesInMap(messageOp.PostprocessingParams, false /* useCamelCase */)
+			var entries []*output.LiteralMapEntry
+			for _, key := range sortedKeys(formatted) {
+				entries = append(entries, output.NewLiteralMapEntry(key, formatted[key], true /* quoted */))
+			}
+			extraTransformFnParams = append(extraTransformFnParams, output.NewLiteralMapExpr(entries, nil, nil))
+		}
+		transformFn = func(expr *output.ReadVarExpr) output.OutputExpression {
+			args := append([]output.OutputExpression{expr}, extraTransformFnParams...)
+			return output.NewInvokeFunctionExpr(output.NewExternalExpr(r3_identifiers.I18nPostprocess, nil, nil, nil), args, nil, nil, false)
+		}
+	}
+
+	// Add the message's statements
+	statements = append(statements, getTranslationDeclStmts(messageOp.Message, mainVar, closureVar, messageOp.Params, transformFn)...)
+	return mainVar, statements
+}
+
+// addSubMessageParams adds the given subMessage placeholders to the given message op.
+//
+// If a placeholder only corresponds to a single sub-message variable, we just set that variable
+// as the param value. However, if the placeholder corresponds to multiple sub-message
+// variables, we need to add a special placeholder value that is handled by the post-processing
+// step. We then add the array of variables as a post-processing param.
+func addSubMessageParams(
+	messageOp *ops_create.I18nMessageOp,
+	placeholders []string,
+	subMessagePlaceholders map[string][]output.OutputExpression,
+) {
+	for _, placeholder := range placeholders {
+		subMessages := subMessagePlaceholders[placeholder]
+		if len(subMessages) == 1 {
+			messageOp.Params[placeholder] = subMessages[0]
+		} else {
+			messageOp.Params[placeholder] = output.NewLiteralExpr(ESCAPE+I18N_ICU_MAPPING_PREFIX+placeholder+ESCAPE, nil, nil)
+			messageOp.PostprocessingParams[placeholder] = output.NewLiteralArrayExpr(subMessages, nil, nil)
+		}
+	}
+}
+
+// getTranslationDeclStmts generates statements that define a given translation message.
+//
+//	var I18N_1;
+//	if (typeof ngI18nClosureMode !== undefined && ngI18nClosureMode) {
+//	    var MSG_EXTERNAL_XXX = goog.getMsg(
+//	         "Some message with {$interpolation}!",
+//	         { "interpolation": "�0�" }
+//	    );
+//	    I18N_1 = MSG_EXTERNAL_XXX;
+//	}
+//	else {
+//	    I18N_1 = $localize`Some message with ${'�0�'}!`;
+//	}
+//
+// variable is assigned the translation, closureVar is the variable for Closure `goog.getMsg`
+// calls, params maps placeholder names to their values, and the optional transformFn is applied
+// to the translation (e.g. post-processing).
+func getTranslationDeclStmts(
+	message *i18n.Message,
+	variable *output.ReadVarExpr,
+	closureVar *output.ReadVarExpr,
+	params map[string]output.OutputExpression,
+	transformFn func(*output.ReadVarExpr) output.OutputExpression,
+) []output.OutputStatement {
+	statements := []output.OutputStatement{
+		DeclareI18nVariable(variable),
+		output.NewIfStmt(
+			createClosureModeGuard(),
+			viewi18n.CreateGoogleGetMsgStatements(variable, message, closureVar, params),
+			viewi18n.CreateLocalizeStatements(
+				variable,
+				message,
+				viewi18n.FormatI18nPlaceholderNamesInMap(params, false /* useCamelCase */),
+			),
+			nil,
+			nil,
+		),
+	}
+
+	if transformFn != nil {
+		statements = append(statements, output.NewExpressionStatement(variable.Set(transformFn(variable)), nil, nil))
+	}
+	return statements
+}
+
+// createClosureModeGuard creates the expression that will be used to guard the closure mode
+// block. It is equivalent to:
+//
+//	typeof ngI18nClosureMode !== undefined && ngI18nClosureMode
+func createClosureModeGuard() *output.BinaryOperatorExpr {
+	return output.NewBinaryOperatorExpr(
+		output.BinaryOperatorAnd,
+		output.NewBinaryOperatorExpr(
+			output.BinaryOperatorNotIdentical,
+			output.NewTypeofExpr(output.NewReadVarExpr(NG_I18N_CLOSURE_MODE, nil, nil), nil, nil),
+			output.NewLiteralExpr("undefined", output.StringType, nil),
+			nil,
+			nil,
+		),
+		output.NewReadVarExpr(NG_I18N_CLOSURE_MODE, nil, nil),
+		nil,
+		nil,
+	)
+}
+
+// i18nGenerateClosureVar generates vars with Closure-specific names for i18n blocks (i.e.
+// `MSG_XXX`).
+func i18nGenerateClosureVar(
+	pool *constant.ConstantPool,
+	messageID string,
+	fileBasedI18nSuffix string,
+	useExternalIds bool,
+) *output.ReadVarExpr {
+	var name string
+	if useExternalIds {
+		prefix := GetTranslationConstPrefix("EXTERNAL_")
+		uniqueSuffix := pool.UniqueName(fileBasedI18nSuffix, true)
+		name = fmt.Sprintf("%s%s$$%s", prefix, util.SanitizeIdentifier(messageID), uniqueSuffix)
+	} else {
+		prefix := GetTranslationConstPrefix(fileBasedI18nSuffix)
+		name = pool.UniqueName(prefix, true)
+	}
+	return output.NewReadVarExpr(name, nil, nil)
+}
+
+// sortedKeys returns the keys of a map of expressions in order.
+func sortedKeys(m map[string]output.OutputExpression) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
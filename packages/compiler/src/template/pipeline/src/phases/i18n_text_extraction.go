@@ -22,7 +22,7 @@ func ConvertI18nText(job *pipeline.CompilationJob) {
 		textNodeI18nBlocks := make(map[operations.XrefId]*ops_create.I18nStartOp)
 		textNodeIcus := make(map[operations.XrefId]*ops_create.IcuStartOp)
 		icuPlaceholderByText := make(map[operations.XrefId]*ops_create.IcuPlaceholderOp)
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindI18nStart:
 				i18nStartOp, ok := op.(*ops_create.I18nStartOp)
@@ -78,7 +78,7 @@ func ConvertI18nText(job *pipeline.CompilationJob) {
 
 		// Update any interpolations to the removed text, and instead represent them as a series of i18n
 		// expressions that we then apply.
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			if op.GetKind() == ir.OpKindInterpolateText {
 				interpolateTextOp, ok := op.(*ops_update.InterpolateTextOp)
 				if !ok {
@@ -12,7 +12,7 @@ import (
 // into an entry in the `consts` array for the whole component.
 func LiftLocalRefs(job *pipeline.ComponentCompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindElementStart:
 				if elementStart, ok := op.(*ops_create.ElementStartOp); ok {
@@ -12,7 +12,7 @@ func EmitNamespaceChanges(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
 		activeNamespace := ir.NamespaceHTML
 
-		for op := unit.GetCreate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if elementStart, ok := op.(*ops_create.ElementStartOp); ok {
 				if elementStart.Namespace != activeNamespace {
 					namespaceOp := ops_create.NewNamespaceOp(elementStart.Namespace)
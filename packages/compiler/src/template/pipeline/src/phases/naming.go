@@ -35,22 +35,9 @@ func sanitizeIdentifier(name string) string {
 // This includes propagating those names into any `ir.ReadVariableExpr`s of those variables, so that
 // the reads can be emitted correctly.
 func NameFunctionsAndVariables(job *compilation.CompilationJob) {
-	// Check if this is a template compilation job
-	if job.Kind != compilation.CompilationJobKindTmpl {
-		return
-	}
-
-	// Get ComponentCompilationJob by accessing through root unit
-	rootUnit := job.GetRoot()
-	viewUnit, ok := rootUnit.(*compilation.ViewCompilationUnit)
-	if !ok || viewUnit.Job == nil {
-		return
-	}
-	componentJob := viewUnit.Job
-
 	state := &namingState{index: 0}
 	compatibility := job.Compatibility == ir.CompatibilityModeTemplateDefinitionBuilder
-	addNamesToView(componentJob.Root, componentJob.ComponentName, state, compatibility)
+	addNamesToView(job.GetRoot(), job.ComponentName, state, compatibility)
 }
 
 type namingState struct {
@@ -78,33 +65,13 @@ func addNamesToView(
 	// into reads of those variables afterwards.
 	varNames := make(map[operations.XrefId]string)
 
-	// Process create ops
-	for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
-		processOpForNaming(op, unit, baseName, varNames, state, compatibility)
-	}
-
-	// Process update ops
-	for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range compilation.UnitOps(unit) {
 		processOpForNaming(op, unit, baseName, varNames, state, compatibility)
 	}
 
 	// Having named all variables declared in the view, now we can push those names into the
 	// `ir.ReadVariableExpr` expressions which represent reads of those variables.
-	for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
-		expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
-			if readVar, ok := expr.(*expression.ReadVariableExpr); ok {
-				if readVar.Name == nil {
-					if name, exists := varNames[readVar.Xref]; exists {
-						readVar.Name = &name
-					} else {
-						panic(fmt.Sprintf("Variable %d not yet named", readVar.Xref))
-					}
-				}
-			}
-		})
-	}
-
-	for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range compilation.UnitOps(unit) {
 		expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
 			if readVar, ok := expr.(*expression.ReadVariableExpr); ok {
 				if readVar.Name == nil {
@@ -185,7 +152,7 @@ func processOpForNaming(
 				}
 				animation := ""
 				if listenerOp.IsLegacyAnimationListener {
-					listenerOp.Name = fmt.Sprintf("@%s.%s", listenerOp.Name, listenerOp.LegacyAnimationPhase)
+					listenerOp.Name = fmt.Sprintf("@%s.%s", listenerOp.Name, *listenerOp.LegacyAnimationPhase)
 					animation = "animation"
 				}
 				var name string
@@ -343,11 +310,11 @@ func getVariableName(
 	}
 
 	if name == nil {
+		var varName string
 		switch kind {
 		case ir.SemanticVariableKindContext:
-			varName := fmt.Sprintf("ctx_r%d", state.index)
+			varName = fmt.Sprintf("ctx_r%d", state.index)
 			state.index++
-			return varName
 		case ir.SemanticVariableKindIdentifier:
 			identifierVar := variable.(*ir_variable.IdentifierVariable)
 			if compatibility {
@@ -359,17 +326,20 @@ func getVariableName(
 					compatPrefix = "i"
 				}
 				state.index++
-				return fmt.Sprintf("%s_%sr%d", identifierVar.Identifier, compatPrefix, state.index)
+				varName = fmt.Sprintf("%s_%sr%d", identifierVar.Identifier, compatPrefix, state.index)
 			} else {
-				varName := fmt.Sprintf("%s_i%d", identifierVar.Identifier, state.index)
+				varName = fmt.Sprintf("%s_i%d", identifierVar.Identifier, state.index)
 				state.index++
-				return varName
 			}
 		default:
 			// TODO: Prefix increment for compatibility only.
 			state.index++
-			return fmt.Sprintf("_r%d", state.index)
+			varName = fmt.Sprintf("_r%d", state.index)
+		}
+		if semanticVar, ok := variable.(ir_variable.SemanticVariable); ok {
+			semanticVar.SetName(varName)
 		}
+		return varName
 	}
 	return *name
 }
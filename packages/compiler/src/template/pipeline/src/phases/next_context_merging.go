@@ -22,7 +22,7 @@ import (
 //   - No operations in between them uses the implicit context.
 func MergeNextContextExpressions(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			kind := op.GetKind()
 			if kind == ir.OpKindListener || kind == ir.OpKindAnimation ||
 				kind == ir.OpKindAnimationListener || kind == ir.OpKindTwoWayListener {
@@ -42,7 +42,7 @@ func MergeNextContextExpressions(job *pipeline.CompilationJob) {
 }
 
 func mergeNextContextsInOps(opsList *operations.OpList) {
-	for op := opsList.Head(); op != nil; op = op.Next() {
+	for _, op := range opsList.Ops() {
 		// Look for a candidate operations to maybe merge.
 		if op.GetKind() != ir.OpKindStatement {
 			continue
@@ -14,7 +14,7 @@ func GenerateNgContainerOps(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
 		updatedElementXrefs := make(map[operations.XrefId]bool)
 
-		for op := unit.GetCreate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if elementStart, ok := op.(*ops_create.ElementStartOp); ok && elementStart.Tag != nil && *elementStart.Tag == containerTag {
 				// Replace the `ElementStart` instruction with `ContainerStart`.
 				containerStart := ops_create.NewContainerStartOp(
@@ -36,7 +36,7 @@ func isElementOrContainerOp(op operations.Op) bool {
 func DisableBindings(job *pipeline.CompilationJob) {
 	elements := make(map[operations.XrefId]operations.CreateOp)
 	for _, view := range job.GetUnits() {
-		for op := view.GetCreate().Head(); op != nil; op = op.Next() {
+		for _, op := range view.GetCreate().Ops() {
 			if !isElementOrContainerOp(op) {
 				continue
 			}
@@ -47,7 +47,7 @@ func DisableBindings(job *pipeline.CompilationJob) {
 	}
 
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			kind := op.GetKind()
 			if kind == ir.OpKindElementStart || kind == ir.OpKindContainerStart {
 				var nonBindable bool
@@ -165,7 +165,7 @@ func orderWithin(opList *operations.OpList, ordering []Rule) {
 	// Only reorder ops that target the same xref; do not mix ops that target different xrefs.
 	var firstTargetInGroup *operations.XrefId
 
-	for op := opList.Head(); op != nil; op = op.Next() {
+	for _, op := range opList.Ops() {
 		if op.GetKind() == ir.OpKindListEnd {
 			break
 		}
@@ -183,9 +183,8 @@ func orderWithin(opList *operations.OpList, ordering []Rule) {
 
 		if shouldBreak {
 			// Insert reordered ops before current op
-			reordered := reorder(opsToOrder, ordering)
-			for i := len(reordered) - 1; i >= 0; i-- {
-				opList.InsertBefore(op, reordered[i])
+			for _, reordered := range reorder(opsToOrder, ordering) {
+				opList.InsertBefore(op, reordered)
 			}
 			opsToOrder = nil
 			firstTargetInGroup = nil
@@ -125,7 +125,7 @@ func ParseExtractedStyles(job *pipeline.CompilationJob) {
 	elements := make(map[operations.XrefId]operations.CreateOp)
 
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			createOp, ok := op.(operations.CreateOp)
 			if !ok {
 				continue
@@ -137,7 +137,7 @@ func ParseExtractedStyles(job *pipeline.CompilationJob) {
 	}
 
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if op.GetKind() == ir.OpKindExtractedAttribute {
 				extractedAttrOp, ok := op.(*ops_create.ExtractedAttributeOp)
 				if !ok {
@@ -184,6 +184,7 @@ func ParseExtractedStyles(job *pipeline.CompilationJob) {
 						parsedStyles := Parse(strValue)
 						for i := 0; i < len(parsedStyles)-1; i += 2 {
 							unit.GetCreate().InsertBefore(
+								op,
 								ops_create.NewExtractedAttributeOp(
 									extractedAttrOp.Target,
 									ir.BindingKindStyleProperty,
@@ -194,7 +195,6 @@ func ParseExtractedStyles(job *pipeline.CompilationJob) {
 									nil, // i18nMessage
 									core.SecurityContextSTYLE,
 								),
-								op,
 							)
 						}
 						unit.GetCreate().Remove(op)
@@ -214,6 +214,7 @@ func ParseExtractedStyles(job *pipeline.CompilationJob) {
 								continue
 							}
 							unit.GetCreate().InsertBefore(
+								op,
 								ops_create.NewExtractedAttributeOp(
 									extractedAttrOp.Target,
 									ir.BindingKindClassName,
@@ -224,7 +225,6 @@ func ParseExtractedStyles(job *pipeline.CompilationJob) {
 									nil, // i18nMessage
 									core.SecurityContextNONE,
 								),
-								op,
 							)
 						}
 						unit.GetCreate().Remove(op)
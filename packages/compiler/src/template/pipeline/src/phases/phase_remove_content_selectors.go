@@ -16,7 +16,7 @@ import (
 func RemoveContentSelectors(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
 		elements := pipeline_util.CreateOpXrefMap(unit)
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if op.GetKind() == ir.OpKindBinding {
 				bindingOp, ok := op.(*ops_update.BindingOp)
 				if !ok {
@@ -28,7 +28,7 @@ func RemoveContentSelectors(job *pipeline.CompilationJob) {
 				}
 			}
 		}
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			if op.GetKind() == ir.OpKindBinding {
 				bindingOp, ok := op.(*ops_update.BindingOp)
 				if !ok {
@@ -22,7 +22,7 @@ func CreatePipes(job *pipeline.CompilationJob) {
 }
 
 func processPipeBindingsInView(unit pipeline.CompilationUnit) {
-	for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range unit.GetUpdate().Ops() {
 		expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
 			if !expression.IsIrExpression(expr) {
 				return
@@ -70,7 +70,7 @@ func addPipeToCreationBlock(
 	// Find the appropriate point to insert the Pipe creation operations.
 	// We're looking for `afterTargetXref` (and also want to insert after any other pipe operations
 	// which might be beyond it).
-	for op := unit.GetCreate().Head().Next(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range unit.GetCreate().Ops() {
 		if !ir_traits.HasConsumesSlotTrait(op) {
 			continue
 		}
@@ -92,7 +92,7 @@ func addPipeToCreationBlock(
 		}
 
 		pipe := ops_create.NewPipeOp(binding.Target, binding.TargetSlot, binding.Name)
-		unit.GetCreate().InsertBefore(pipe, op.Next())
+		unit.GetCreate().InsertAfter(op, pipe)
 
 		// This completes adding the pipe to the creation block.
 		return
@@ -2,7 +2,6 @@ package phases
 
 import (
 	"ngc-go/packages/compiler/src/output"
-	"ngc-go/packages/compiler/src/template/pipeline/ir"
 	"ngc-go/packages/compiler/src/template/pipeline/ir/src/expression"
 
 	pipeline "ngc-go/packages/compiler/src/template/pipeline/src/compilation"
@@ -13,7 +12,7 @@ import (
 // instruction.
 func CreateVariadicPipes(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			expression.TransformExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
 				pipeBinding, ok := expr.(*expression.PipeBindingExpr)
 				if !ok {
@@ -2,6 +2,7 @@ package phases
 
 import (
 	"ngc-go/packages/compiler/src/template/pipeline/ir"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/operations"
 	ops_create "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/create"
 
 	pipeline "ngc-go/packages/compiler/src/template/pipeline/src/compilation"
@@ -21,7 +22,7 @@ func propagateI18nBlocksToTemplates(
 	subTemplateIndex int,
 ) int {
 	var i18nBlock *ops_create.I18nStartOp = nil
-	for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range unit.GetCreate().Ops() {
 		switch op.GetKind() {
 		case ir.OpKindI18nStart:
 			i18nStartOp, ok := op.(*ops_create.I18nStartOp)
@@ -157,20 +158,10 @@ func propagateI18nBlocksForView(
 // wrapTemplateWithI18n wraps a template view with i18n start and end ops.
 func wrapTemplateWithI18n(unit *pipeline.ViewCompilationUnit, parentI18n *ops_create.I18nStartOp) {
 	// Only add i18n ops if they have not already been propagated to this template.
-	head := unit.GetCreate().Head()
-	if head == nil || head.GetKind() == ir.OpKindListEnd {
-		return
-	}
-	nextOp := head.Next()
-	if nextOp == nil || nextOp.GetKind() != ir.OpKindI18nStart {
+	if unit.GetCreate().Head().GetKind() != ir.OpKindI18nStart {
 		id := unit.Job.AllocateXrefId()
 		// Nested ng-template i18n start/end ops should not receive source spans.
-		i18nStartOp := ops_create.NewI18nStartOp(id, parentI18n.Message, parentI18n.Root, nil)
-		unit.GetCreate().InsertAfter(head, i18nStartOp)
-		tail := unit.GetCreate().Tail()
-		if tail != nil {
-			i18nEndOp := ops_create.NewI18nEndOp(id, nil)
-			unit.GetCreate().InsertBefore(tail, i18nEndOp)
-		}
+		unit.GetCreate().Prepend([]operations.Op{ops_create.NewI18nStartOp(id, parentI18n.Message, parentI18n.Root, nil)})
+		unit.GetCreate().Push(ops_create.NewI18nEndOp(id, nil))
 	}
 }
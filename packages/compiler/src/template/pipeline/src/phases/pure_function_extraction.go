@@ -5,7 +5,6 @@ import (
 
 	"ngc-go/packages/compiler/src/constant"
 	"ngc-go/packages/compiler/src/output"
-	"ngc-go/packages/compiler/src/template/pipeline/ir"
 	"ngc-go/packages/compiler/src/template/pipeline/ir/src/expression"
 
 	pipeline "ngc-go/packages/compiler/src/template/pipeline/src/compilation"
@@ -14,7 +13,7 @@ import (
 // ExtractPureFunctions extracts pure functions from expressions and moves them to the constant pool.
 func ExtractPureFunctions(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
 				pureFuncExpr, ok := expr.(*expression.PureFunctionExpr)
 				if !ok || pureFuncExpr.Body == nil {
@@ -26,7 +25,7 @@ func ExtractPureFunctions(job *pipeline.CompilationJob) {
 				pureFuncExpr.Body = nil
 			})
 		}
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
 				pureFuncExpr, ok := expr.(*expression.PureFunctionExpr)
 				if !ok || pureFuncExpr.Body == nil {
@@ -2,7 +2,6 @@ package phases
 
 import (
 	"ngc-go/packages/compiler/src/output"
-	"ngc-go/packages/compiler/src/template/pipeline/ir"
 	"ngc-go/packages/compiler/src/template/pipeline/ir/src/expression"
 
 	pipeline "ngc-go/packages/compiler/src/template/pipeline/src/compilation"
@@ -11,7 +10,7 @@ import (
 // GeneratePureLiteralStructures transforms literal arrays and maps into pure function expressions.
 func GeneratePureLiteralStructures(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			expression.TransformExpressionsInOp(
 				op,
 				func(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
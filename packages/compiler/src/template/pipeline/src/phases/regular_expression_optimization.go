@@ -5,7 +5,6 @@ import (
 
 	"ngc-go/packages/compiler/src/constant"
 	"ngc-go/packages/compiler/src/output"
-	"ngc-go/packages/compiler/src/template/pipeline/ir"
 	"ngc-go/packages/compiler/src/template/pipeline/ir/src/expression"
 
 	pipeline "ngc-go/packages/compiler/src/template/pipeline/src/compilation"
@@ -14,7 +13,7 @@ import (
 // OptimizeRegularExpressions optimizes regular expressions used in expressions.
 func OptimizeRegularExpressions(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			expression.TransformExpressionsInOp(
 				op,
 				func(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
@@ -31,7 +30,7 @@ func OptimizeRegularExpressions(job *pipeline.CompilationJob) {
 				expression.VisitorContextFlagNone,
 			)
 		}
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			expression.TransformExpressionsInOp(
 				op,
 				func(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
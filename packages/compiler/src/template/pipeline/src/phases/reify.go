@@ -49,17 +49,31 @@ func Reify(job *pipeline.CompilationJob) {
 	}
 }
 
-// This function can be used a sanity check -- it walks every expression in the const pool, and
-// every expression reachable from an op, and makes sure that there are no IR expressions
-// left. This is nice to use for debugging mysterious failures where an IR expression cannot be
-// output from the output AST code.
-func ensureNoIrForDebug(job *pipeline.CompilationJob) {
-	// TODO: Implement if needed for debugging
-	// This would require access to job.pool.statements which may not be available in the same way
+// EnsureNoIrForDebug can be used a sanity check -- it walks every expression in the const pool and
+// in the statements of the views, and makes sure that there are no IR expressions left. This is
+// nice to use for debugging mysterious failures where an IR expression cannot be output from the
+// output AST code.
+func EnsureNoIrForDebug(job *pipeline.CompilationJob) {
+	ensureNoIr := func(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
+		if irExpr, ok := expr.(expression.IrExpression); ok {
+			panic(fmt.Sprintf("AssertionError: IR expression found during reify: %s", irExpr.GetKind()))
+		}
+		return expr
+	}
+	for _, stmt := range job.Pool.GetStatements() {
+		expression.TransformExpressionsInStatement(stmt, ensureNoIr, expression.VisitorContextFlagNone)
+	}
+	for _, unit := range job.GetUnits() {
+		for _, op := range pipeline.UnitOps(unit) {
+			if stmtOp, ok := op.(*shared.StatementOp); ok {
+				expression.TransformExpressionsInStatement(stmtOp.Statement, ensureNoIr, expression.VisitorContextFlagNone)
+			}
+		}
+	}
 }
 
 func reifyCreateOperations(unit pipeline.CompilationUnit, ops *ir_operations.OpList) {
-	for op := ops.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range ops.Ops() {
 		expression.TransformExpressionsInOp(op, reifyIrExpression, expression.VisitorContextFlagNone)
 
 		switch op.GetKind() {
@@ -84,12 +98,12 @@ func reifyCreateOperations(unit pipeline.CompilationUnit, ops *ir_operations.OpL
 				panic("expected tag to be set")
 			}
 			var attributesPtr *int
-			if elementStartOp.Attributes != 0 {
-				attrIdx := int(elementStartOp.Attributes)
+			if elementStartOp.Attributes != nil {
+				attrIdx := int(*elementStartOp.Attributes)
 				attributesPtr = &attrIdx
 			}
 			var localRefsPtr *int
-			if localRefsIdx, ok := elementStartOp.LocalRefs.(ir_operations.ConstIndex); ok && localRefsIdx != 0 {
+			if localRefsIdx, ok := elementStartOp.LocalRefs.(ir_operations.ConstIndex); ok {
 				idx := int(localRefsIdx)
 				localRefsPtr = &idx
 			}
@@ -122,12 +136,12 @@ func reifyCreateOperations(unit pipeline.CompilationUnit, ops *ir_operations.OpL
 				panic("expected tag to be set")
 			}
 			var attributesPtr *int
-			if elementOp.Attributes != 0 {
-				attrIdx := int(elementOp.Attributes)
+			if elementOp.Attributes != nil {
+				attrIdx := int(*elementOp.Attributes)
 				attributesPtr = &attrIdx
 			}
 			var localRefsPtr *int
-			if localRefsIdx, ok := elementOp.LocalRefs.(ir_operations.ConstIndex); ok && localRefsIdx != 0 {
+			if localRefsIdx, ok := elementOp.LocalRefs.(ir_operations.ConstIndex); ok {
 				idx := int(localRefsIdx)
 				localRefsPtr = &idx
 			}
@@ -167,12 +181,12 @@ func reifyCreateOperations(unit pipeline.CompilationUnit, ops *ir_operations.OpL
 				panic("expected slot to be assigned")
 			}
 			var attributesPtr *int
-			if containerStartOp.Attributes != 0 {
-				attrIdx := int(containerStartOp.Attributes)
+			if containerStartOp.Attributes != nil {
+				attrIdx := int(*containerStartOp.Attributes)
 				attributesPtr = &attrIdx
 			}
 			var localRefsPtr *int
-			if localRefsIdx, ok := containerStartOp.LocalRefs.(ir_operations.ConstIndex); ok && localRefsIdx != 0 {
+			if localRefsIdx, ok := containerStartOp.LocalRefs.(ir_operations.ConstIndex); ok {
 				idx := int(localRefsIdx)
 				localRefsPtr = &idx
 			}
@@ -200,12 +214,12 @@ func reifyCreateOperations(unit pipeline.CompilationUnit, ops *ir_operations.OpL
 				panic("expected slot to be assigned")
 			}
 			var attributesPtr *int
-			if containerOp.Attributes != 0 {
-				attrIdx := int(containerOp.Attributes)
+			if containerOp.Attributes != nil {
+				attrIdx := int(*containerOp.Attributes)
 				attributesPtr = &attrIdx
 			}
 			var localRefsPtr *int
-			if localRefsIdx, ok := containerOp.LocalRefs.(ir_operations.ConstIndex); ok && localRefsIdx != 0 {
+			if localRefsIdx, ok := containerOp.LocalRefs.(ir_operations.ConstIndex); ok {
 				idx := int(localRefsIdx)
 				localRefsPtr = &idx
 			}
@@ -238,12 +252,12 @@ func reifyCreateOperations(unit pipeline.CompilationUnit, ops *ir_operations.OpL
 			if i18nStartOp.Handle == nil || i18nStartOp.Handle.Slot == nil {
 				panic("expected slot to be assigned")
 			}
-			if i18nStartOp.MessageIndex == 0 {
+			if i18nStartOp.MessageIndex == nil {
 				panic("expected messageIndex to be set")
 			}
 			ops.Replace(op, pipeline_instruction.I18nStart(
 				*i18nStartOp.Handle.Slot,
-				int(i18nStartOp.MessageIndex),
+				int(*i18nStartOp.MessageIndex),
 				i18nStartOp.SubTemplateIndex,
 				i18nStartOp.SourceSpan,
 			))
@@ -261,12 +275,12 @@ func reifyCreateOperations(unit pipeline.CompilationUnit, ops *ir_operations.OpL
 			if i18nOp.Handle == nil || i18nOp.Handle.Slot == nil {
 				panic("expected slot to be assigned")
 			}
-			if i18nOp.MessageIndex == 0 {
+			if i18nOp.MessageIndex == nil {
 				panic("expected messageIndex to be set")
 			}
 			ops.Replace(op, pipeline_instruction.I18n(
 				*i18nOp.Handle.Slot,
-				int(i18nOp.MessageIndex),
+				int(*i18nOp.MessageIndex),
 				i18nOp.SubTemplateIndex,
 				i18nOp.SourceSpan,
 			))
@@ -278,12 +292,12 @@ func reifyCreateOperations(unit pipeline.CompilationUnit, ops *ir_operations.OpL
 			if i18nAttrOp.Handle == nil || i18nAttrOp.Handle.Slot == nil {
 				panic("expected slot to be assigned")
 			}
-			if i18nAttrOp.I18nAttributesConfig == 0 {
+			if i18nAttrOp.I18nAttributesConfig == nil {
 				panic("AssertionError: i18nAttributesConfig was not set")
 			}
 			ops.Replace(op, pipeline_instruction.I18nAttributes(
 				*i18nAttrOp.Handle.Slot,
-				int(i18nAttrOp.I18nAttributesConfig),
+				int(*i18nAttrOp.I18nAttributesConfig),
 			))
 		case ir.OpKindTemplate:
 			templateOp, ok := op.(*ops_create.TemplateOp)
@@ -314,12 +328,12 @@ func reifyCreateOperations(unit pipeline.CompilationUnit, ops *ir_operations.OpL
 				panic("expected slot to be assigned")
 			}
 			var attributesPtr *int
-			if templateOp.Attributes != 0 {
-				attrIdx := int(templateOp.Attributes)
+			if templateOp.Attributes != nil {
+				attrIdx := int(*templateOp.Attributes)
 				attributesPtr = &attrIdx
 			}
 			var localRefsPtr *int
-			if localRefsIdx, ok := templateOp.LocalRefs.(ir_operations.ConstIndex); ok && localRefsIdx != 0 {
+			if localRefsIdx, ok := templateOp.LocalRefs.(ir_operations.ConstIndex); ok {
 				idx := int(localRefsIdx)
 				localRefsPtr = &idx
 			}
@@ -710,12 +724,12 @@ func reifyCreateOperations(unit pipeline.CompilationUnit, ops *ir_operations.OpL
 				panic("expected slot to be assigned")
 			}
 			var attributesPtr *int
-			if conditionalCreateOp.Attributes != 0 {
-				attrIdx := int(conditionalCreateOp.Attributes)
+			if conditionalCreateOp.Attributes != nil {
+				attrIdx := int(*conditionalCreateOp.Attributes)
 				attributesPtr = &attrIdx
 			}
 			var localRefsPtr *int
-			if localRefsIdx, ok := conditionalCreateOp.LocalRefs.(ir_operations.ConstIndex); ok && localRefsIdx != 0 {
+			if localRefsIdx, ok := conditionalCreateOp.LocalRefs.(ir_operations.ConstIndex); ok {
 				idx := int(localRefsIdx)
 				localRefsPtr = &idx
 			}
@@ -758,12 +772,12 @@ func reifyCreateOperations(unit pipeline.CompilationUnit, ops *ir_operations.OpL
 				panic("expected slot to be assigned")
 			}
 			var attributesPtr *int
-			if conditionalBranchCreateOp.Attributes != 0 {
-				attrIdx := int(conditionalBranchCreateOp.Attributes)
+			if conditionalBranchCreateOp.Attributes != nil {
+				attrIdx := int(*conditionalBranchCreateOp.Attributes)
 				attributesPtr = &attrIdx
 			}
 			var localRefsPtr *int
-			if localRefsIdx, ok := conditionalBranchCreateOp.LocalRefs.(ir_operations.ConstIndex); ok && localRefsIdx != 0 {
+			if localRefsIdx, ok := conditionalBranchCreateOp.LocalRefs.(ir_operations.ConstIndex); ok {
 				idx := int(localRefsIdx)
 				localRefsPtr = &idx
 			}
@@ -813,8 +827,8 @@ func reifyCreateOperations(unit pipeline.CompilationUnit, ops *ir_operations.OpL
 				emptyDecls = emptyView.Decls
 				emptyVars = emptyView.Vars
 				emptyTag = repeaterCreateOp.EmptyTag
-				if repeaterCreateOp.EmptyAttributes != 0 {
-					attrIdx := int(repeaterCreateOp.EmptyAttributes)
+				if repeaterCreateOp.EmptyAttributes != nil {
+					attrIdx := int(*repeaterCreateOp.EmptyAttributes)
 					emptyAttributesPtr = &attrIdx
 				}
 			}
@@ -825,8 +839,8 @@ func reifyCreateOperations(unit pipeline.CompilationUnit, ops *ir_operations.OpL
 				panic("expected repeater vars to be set")
 			}
 			var attributesPtr *int
-			if repeaterCreateOp.Attributes != 0 {
-				attrIdx := int(repeaterCreateOp.Attributes)
+			if repeaterCreateOp.Attributes != nil {
+				attrIdx := int(*repeaterCreateOp.Attributes)
 				attributesPtr = &attrIdx
 			}
 			ops.Replace(op, pipeline_instruction.RepeaterCreate(
@@ -884,7 +898,7 @@ func reifyCreateOperations(unit pipeline.CompilationUnit, ops *ir_operations.OpL
 
 // reifyUpdateOperations reifies update operations
 func reifyUpdateOperations(unit pipeline.CompilationUnit, ops *ir_operations.OpList) {
-	for op := ops.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range ops.Ops() {
 		expression.TransformExpressionsInOp(op, reifyIrExpression, expression.VisitorContextFlagNone)
 
 		switch op.GetKind() {
@@ -1276,7 +1290,7 @@ func reifyListenerHandler(
 	// Next, extract all the OutputStatement from the reified operations. We can expect that at this
 	// point, all operations have been converted to statements.
 	handlerStmts := []output.OutputStatement{}
-	for op := handlerOps.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range handlerOps.Ops() {
 		if op.GetKind() != ir.OpKindStatement {
 			panic(fmt.Sprintf("AssertionError: expected reified statements, but found op %v", op.GetKind()))
 		}
@@ -1331,7 +1345,7 @@ func reifyTrackBy(unit pipeline.CompilationUnit, op *ops_create.RepeaterCreateOp
 		reifyUpdateOperations(unit, op.TrackByOps)
 
 		statements := []output.OutputStatement{}
-		for trackOp := op.TrackByOps.Head(); trackOp != nil && trackOp.GetKind() != ir.OpKindListEnd; trackOp = trackOp.Next() {
+		for _, trackOp := range op.TrackByOps.Ops() {
 			if trackOp.GetKind() != ir.OpKindStatement {
 				panic(fmt.Sprintf("AssertionError: expected reified statements, but found op %v", trackOp.GetKind()))
 			}
@@ -11,7 +11,7 @@ import (
 // RemoveEmptyBindings removes bindings with no content, which can be safely deleted.
 func RemoveEmptyBindings(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetUpdate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindAttribute, ir.OpKindBinding, ir.OpKindClassProp, ir.OpKindClassMap,
 				ir.OpKindProperty, ir.OpKindStyleProp, ir.OpKindStyleMap:
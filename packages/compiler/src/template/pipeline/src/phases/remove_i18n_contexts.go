@@ -11,7 +11,7 @@ import (
 // be safe.
 func RemoveI18nContexts(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindI18nContext:
 				unit.GetCreate().Remove(op)
@@ -19,7 +19,7 @@ import (
 // Eventually users will see the proper error from the template type checker.
 func RemoveIllegalLetReferences(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			if op.GetKind() != ir.OpKindVariable {
 				continue
 			}
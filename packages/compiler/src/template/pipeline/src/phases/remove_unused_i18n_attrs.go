@@ -16,7 +16,7 @@ func RemoveUnusedI18nAttributesOps(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
 		ownersWithI18nExpressions := make(map[operations.XrefId]bool)
 
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			if op.GetKind() == ir.OpKindI18nExpression {
 				i18nExprOp, ok := op.(*ops_update.I18nExpressionOp)
 				if ok {
@@ -25,7 +25,7 @@ func RemoveUnusedI18nAttributesOps(job *pipeline.CompilationJob) {
 			}
 		}
 
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if op.GetKind() == ir.OpKindI18nAttributes {
 				i18nAttributesOp, ok := op.(*ops_create.I18nAttributesOp)
 				if ok {
@@ -35,7 +35,7 @@ func processLexicalScope(
 	// The current view's context is accessible via the `ctx` parameter.
 	scope[view.GetXref()] = output.NewReadVarExpr("ctx", nil, nil)
 
-	for op := opsList.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range opsList.Ops() {
 		switch op.GetKind() {
 		case ir.OpKindVariable:
 			if varOp, ok := op.(*shared.VariableOp); ok {
@@ -73,7 +73,7 @@ func processLexicalScope(
 		scope[view.GetXref()] = output.NewReadVarExpr("ctx", nil, nil)
 	}
 
-	for op := opsList.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range opsList.Ops() {
 		expression.TransformExpressionsInOp(
 			op,
 			func(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
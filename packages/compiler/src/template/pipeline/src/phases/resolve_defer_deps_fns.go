@@ -1,6 +1,9 @@
 package phases
 
 import (
+	"fmt"
+	"strings"
+
 	"ngc-go/packages/compiler/src/template/pipeline/ir"
 	ops_create "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/create"
 	pipeline "ngc-go/packages/compiler/src/template/pipeline/src/compilation"
@@ -13,7 +16,7 @@ func ResolveDeferDepsFns(job *pipeline.ComponentCompilationJob) {
 		if !ok {
 			continue
 		}
-		for op := viewUnit.Create.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range viewUnit.Create.Ops() {
 			if op.GetKind() != ir.OpKindDefer {
 				continue
 			}
@@ -33,21 +36,15 @@ func ResolveDeferDepsFns(job *pipeline.ComponentCompilationJob) {
 				}
 				fullPathName := ""
 				if viewUnit.FnName != nil {
-					fullPathName = *viewUnit.FnName
-					// Replace "_Template" suffix
-					if len(fullPathName) > 9 && fullPathName[len(fullPathName)-9:] == "_Template" {
-						fullPathName = fullPathName[:len(fullPathName)-9]
-					}
-				}
-				slotNum := 0
-				if deferOp.Handle.Slot != nil {
-					slotNum = *deferOp.Handle.Slot
+					fullPathName = strings.Replace(*viewUnit.FnName, "_Template", "", 1)
 				}
-				resolverFnName := fullPathName + "_Defer_" + string(rune(slotNum)) + "_DepsFn"
-				// TODO: Implement GetSharedFunctionReference using job.Pool.GetSharedFunctionReference
-				// For now, just use the ownResolverFn directly
-				deferOp.ResolverFn = deferOp.OwnResolverFn
-				_ = resolverFnName // TODO: Use this when GetSharedFunctionReference is implemented
+				resolverFnName := fmt.Sprintf("%s_Defer_%d_DepsFn", fullPathName, *deferOp.Handle.Slot)
+				deferOp.ResolverFn = job.Pool.GetSharedFunctionReference(
+					deferOp.OwnResolverFn,
+					resolverFnName,
+					// Don't use unique names for TDB compatibility.
+					false,
+				)
 			}
 		}
 	}
@@ -20,7 +20,7 @@ func ResolveDollarEvent(job *pipeline.CompilationJob) {
 }
 
 func transformDollarEvent(opsList *operations.OpList) {
-	for op := opsList.Head(); op != nil; op = op.Next() {
+	for _, op := range opsList.Ops() {
 		kind := op.GetKind()
 		if kind == ir.OpKindListener || kind == ir.OpKindTwoWayListener || kind == ir.OpKindAnimationListener {
 			expression.TransformExpressionsInOp(
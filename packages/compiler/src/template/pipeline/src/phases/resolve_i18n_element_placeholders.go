@@ -15,7 +15,7 @@ func ResolveI18nElementPlaceholders(job *pipeline.ComponentCompilationJob) {
 	i18nContexts := make(map[ir_operations.XrefId]*ops_create.I18nContextOp)
 	elements := make(map[ir_operations.XrefId]*ops_create.ElementStartOp)
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindI18nContext:
 				i18nContextOp, ok := op.(*ops_create.I18nContextOp)
@@ -52,7 +52,7 @@ func resolvePlaceholdersForView(
 	var currentOps *CurrentOps
 	pendingStructuralDirectiveCloses := make(map[ir_operations.XrefId]interface{}) // *ops.TemplateOp | *ops.ConditionalCreateOp | *ops.ConditionalBranchCreateOp
 
-	for op := unit.Create.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range unit.Create.Ops() {
 		switch op.GetKind() {
 		case ir.OpKindI18nStart:
 			i18nStartOp, ok := op.(*ops_create.I18nStartOp)
@@ -640,7 +640,7 @@ func getSubTemplateIndexForTemplateTag(
 	i18nOp *ops_create.I18nStartOp,
 	view *pipeline.ViewCompilationUnit,
 ) *int {
-	for op := view.Create.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range view.Create.Ops() {
 		if op.GetKind() == ir.OpKindI18nStart {
 			i18nStartOp, ok := op.(*ops_create.I18nStartOp)
 			if ok {
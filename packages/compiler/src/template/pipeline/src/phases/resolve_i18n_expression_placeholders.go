@@ -16,7 +16,7 @@ func ResolveI18nExpressionPlaceholders(job *pipeline.ComponentCompilationJob) {
 	i18nContexts := make(map[ir_operations.XrefId]*ops_create.I18nContextOp)
 	icuPlaceholders := make(map[ir_operations.XrefId]*ops_create.IcuPlaceholderOp)
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindI18nStart:
 				i18nStartOp, ok := op.(*ops_create.I18nStartOp)
@@ -52,7 +52,7 @@ func ResolveI18nExpressionPlaceholders(job *pipeline.ComponentCompilationJob) {
 	}
 
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			if op.GetKind() == ir.OpKindI18nExpression {
 				i18nExprOp, ok := op.(*ops_update.I18nExpressionOp)
 				if !ok {
@@ -50,7 +50,7 @@ func processLexicalScopeResolveName(
 	// First, step through the operations list and:
 	// 1) build up the `scope` mapping
 	// 2) recurse into any listener functions
-	for op := opsList.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range opsList.Ops() {
 		switch op.GetKind() {
 		case ir.OpKindVariable:
 			if varOp, ok := op.(*shared.VariableOp); ok {
@@ -84,7 +84,7 @@ func processLexicalScopeResolveName(
 	// Next, use the `scope` mapping to match `ir.LexicalReadExpr` with defined names in the lexical
 	// scope. Also, look for `ir.RestoreViewExpr`s and match them with the snapshotted view context
 	// variable.
-	for op := opsList.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range opsList.Ops() {
 		kind := op.GetKind()
 		if kind == ir.OpKindListener || kind == ir.OpKindTwoWayListener ||
 			kind == ir.OpKindAnimation || kind == ir.OpKindAnimationListener {
@@ -106,13 +106,7 @@ func processLexicalScopeResolveName(
 						return expression.NewReadVariableExpr(xref)
 					} else {
 						// Reading from the component context.
-						// Get ComponentCompilationJob by casting unit to ViewCompilationUnit
-						viewUnit, ok := unit.(*compilation.ViewCompilationUnit)
-						if !ok || viewUnit.Job == nil {
-							panic("Expected ViewCompilationUnit with ComponentCompilationJob")
-						}
-						componentJob := viewUnit.Job
-						rootXref := componentJob.Root.Xref
+						rootXref := unit.GetJob().GetRoot().GetXref()
 						return output.NewReadPropExpr(expression.NewContextExpr(rootXref), lexicalRead.Name, nil, nil)
 					}
 				} else if restoreView, ok := expr.(*expression.RestoreViewExpr); ok {
@@ -134,7 +128,7 @@ func processLexicalScopeResolveName(
 	}
 
 	// Verify no lexical reads remain
-	for op := opsList.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range opsList.Ops() {
 		expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
 			if lexicalRead, ok := expr.(*expression.LexicalReadExpr); ok {
 				panic(fmt.Sprintf("AssertionError: no lexical reads should remain, but found read of %s", lexicalRead.Name))
@@ -41,7 +41,7 @@ func ResolveSanitizers(job *compilation.CompilationJob) {
 		// TemplateDefinitionBuilder does).
 		// TODO: Is the TDB behavior correct here?
 		if job.Kind != compilation.CompilationJobKindHost {
-			for op := unit.GetCreate().Head(); op != nil; op = op.Next() {
+			for _, op := range unit.GetCreate().Ops() {
 				if extractedAttr, ok := op.(*ops_create.ExtractedAttributeOp); ok {
 					securityCtx := getOnlySecurityContext(extractedAttr.SecurityContext)
 					trustedValueFn := trustedValueFns[securityCtx]
@@ -54,7 +54,7 @@ func ResolveSanitizers(job *compilation.CompilationJob) {
 			}
 		}
 
-		for op := unit.GetUpdate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindProperty, ir.OpKindAttribute, ir.OpKindDomProperty:
 				var sanitizerFn *output.ExternalReference
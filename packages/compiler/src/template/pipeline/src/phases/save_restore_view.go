@@ -31,10 +31,10 @@ func SaveAndRestoreView(job *pipeline.ComponentCompilationJob) {
 			expression.NewGetCurrentViewExpr(),
 			ir.VariableFlagsNone,
 		)
-		viewUnit.Create.InsertBefore(savedViewVarOp, viewUnit.Create.Head())
+		viewUnit.Create.Prepend([]operations.Op{savedViewVarOp})
 
 		// Check each listener to see if it needs restore view
-		for op := viewUnit.Create.Head(); op != nil; op = op.Next() {
+		for _, op := range viewUnit.Create.Ops() {
 			kind := op.GetKind()
 			if kind != ir.OpKindListener && kind != ir.OpKindTwoWayListener &&
 				kind != ir.OpKindAnimation && kind != ir.OpKindAnimationListener {
@@ -105,12 +105,12 @@ func addSaveRestoreViewOperationToListener(
 		expression.NewRestoreViewExpr(unit.Xref),
 		ir.VariableFlagsNone,
 	)
-	handlerOps.InsertBefore(restoreViewVarOp, handlerOps.Head())
+	handlerOps.Prepend([]operations.Op{restoreViewVarOp})
 
 	// The "restore view" operations in listeners requires a call to `resetView` to reset the
 	// context prior to returning from the listener operations. Find any `return` statements in
 	// the listener body and wrap them in a call to reset the view.
-	for handlerOp := handlerOps.Head(); handlerOp != nil; handlerOp = handlerOp.Next() {
+	for _, handlerOp := range handlerOps.Ops() {
 		if handlerOp.GetKind() == ir.OpKindStatement {
 			if stmtOp, ok := handlerOp.(*shared.StatementOp); ok {
 				if returnStmt, ok := stmtOp.Statement.(*output.ReturnStatement); ok {
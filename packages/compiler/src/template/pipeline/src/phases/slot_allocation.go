@@ -27,7 +27,7 @@ func AllocateSlots(job *pipeline.ComponentCompilationJob) {
 		// Slot indices start at 0 for each view (and are not unique between views).
 		slotCount := 0
 
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			// Only consider declarations which consume data slots.
 			if !ir_traits.HasConsumesSlotTrait(op) {
 				continue
@@ -39,11 +39,12 @@ func AllocateSlots(job *pipeline.ComponentCompilationJob) {
 			}); ok {
 				trait := traitOp.GetConsumesSlotTrait()
 				if trait.Handle == nil {
-					trait.Handle = ir.NewSlotHandle()
+					panic("AssertionError: expected a slot handle on a declaration which consumes slots")
 				}
 
 				// Assign slots to this declaration starting at the current `slotCount`.
-				trait.Handle.Slot = &slotCount
+				slot := slotCount
+				trait.Handle.Slot = &slot
 
 				// And track its assigned slot in the `slotMap`.
 				slotMap[trait.Xref] = slotCount
@@ -57,7 +58,8 @@ func AllocateSlots(job *pipeline.ComponentCompilationJob) {
 		// Record the total number of slots used on the view itself. This will later be propagated into
 		// `ir.TemplateOp`s which declare those views (except for the root view).
 		if viewUnit, ok := unit.(*pipeline.ViewCompilationUnit); ok {
-			viewUnit.Decls = &slotCount
+			decls := slotCount
+			viewUnit.Decls = &decls
 		}
 	}
 
@@ -68,12 +70,12 @@ func AllocateSlots(job *pipeline.ComponentCompilationJob) {
 	// propagate the number of slots used for each view into the operations which declares it.
 	for _, unit := range job.GetUnits() {
 		// Process create ops
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			processOpForSlotPropagation(op, job)
 		}
 
 		// Process update ops
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			processOpForSlotPropagation(op, job)
 		}
 	}
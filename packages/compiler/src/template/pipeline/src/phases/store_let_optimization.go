@@ -19,7 +19,7 @@ func OptimizeStoreLet(job *pipeline.CompilationJob) {
 	// the creation block (via listeners) and in the update block, we have
 	// to look through all the ops to find the references.
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			// Take advantage that we're already looking through all the ops and track some more info.
 			if op.GetKind() == ir.OpKindDeclareLet {
 				if declareLetOp, ok := op.(*ops_create.DeclareLetOp); ok {
@@ -34,7 +34,7 @@ func OptimizeStoreLet(job *pipeline.CompilationJob) {
 			})
 		}
 
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
 				if contextLetRef, ok := expr.(*expression.ContextLetReferenceExpr); ok {
 					letUsedExternally[contextLetRef.Target] = true
@@ -44,7 +44,7 @@ func OptimizeStoreLet(job *pipeline.CompilationJob) {
 	}
 
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			expression.TransformExpressionsInOp(
 				op,
 				func(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
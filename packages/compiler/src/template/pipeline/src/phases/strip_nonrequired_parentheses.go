@@ -2,7 +2,6 @@ package phases
 
 import (
 	"ngc-go/packages/compiler/src/output"
-	"ngc-go/packages/compiler/src/template/pipeline/ir"
 	"ngc-go/packages/compiler/src/template/pipeline/ir/src/expression"
 
 	pipeline "ngc-go/packages/compiler/src/template/pipeline/src/compilation"
@@ -30,7 +29,7 @@ func StripNonrequiredParentheses(job *pipeline.CompilationJob) {
 	// Check which parentheses are required.
 	requiredParens := make(map[*output.ParenthesizedExpr]bool)
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
 				if binaryOp, ok := expr.(*output.BinaryOperatorExpr); ok {
 					switch binaryOp.Operator {
@@ -46,7 +45,7 @@ func StripNonrequiredParentheses(job *pipeline.CompilationJob) {
 				}
 			})
 		}
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
 				if binaryOp, ok := expr.(*output.BinaryOperatorExpr); ok {
 					switch binaryOp.Operator {
@@ -64,7 +63,7 @@ func StripNonrequiredParentheses(job *pipeline.CompilationJob) {
 
 	// Remove any non-required parentheses.
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			expression.TransformExpressionsInOp(
 				op,
 				func(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
@@ -79,7 +78,7 @@ func StripNonrequiredParentheses(job *pipeline.CompilationJob) {
 				expression.VisitorContextFlagNone,
 			)
 		}
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			expression.TransformExpressionsInOp(
 				op,
 				func(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
@@ -12,7 +12,7 @@ import (
 // Must run before the main binding specialization pass.
 func SpecializeStyleBindings(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			if op.GetKind() != ir.OpKindBinding {
 				continue
 			}
@@ -40,7 +40,7 @@ func generateTemporaries(opsList *operations.OpList) []*shared.StatementOp {
 
 	// For each op, search for any variables that are assigned or read. For each variable, generate a
 	// name and produce a `DeclareVarStmt` to the beginning of the block.
-	for op := opsList.Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+	for _, op := range opsList.Ops() {
 		// Identify the final time each temp var is read.
 		finalReads := make(map[operations.XrefId]*expression.ReadTemporaryExpr)
 		expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
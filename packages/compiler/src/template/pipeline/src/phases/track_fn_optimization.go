@@ -18,7 +18,7 @@ import (
 // track expressions for optimizable cases.
 func OptimizeTrackFns(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if op.GetKind() != ir.OpKindRepeaterCreate {
 				continue
 			}
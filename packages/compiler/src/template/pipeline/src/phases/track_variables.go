@@ -14,7 +14,7 @@ import (
 // appropriate output read.
 func GenerateTrackVariables(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if op.GetKind() != ir.OpKindRepeaterCreate {
 				continue
 			}
@@ -16,7 +16,7 @@ import (
 // `ng.twoWayBindingSet(target, value) || (target = value)`.
 func TransformTwoWayBindingSet(job *pipeline.CompilationJob) {
 	for _, unit := range job.GetUnits() {
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if op.GetKind() != ir.OpKindTwoWayListener {
 				continue
 			}
@@ -22,12 +22,12 @@ func CountVariables(job *compilation.CompilationJob) {
 		varCount := 0
 
 		// Count variables on top-level ops first. Don't explore nested expressions just yet.
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			if ir_traits.HasConsumesVarsTrait(op) {
 				varCount += varsUsedByOp(op)
 			}
 		}
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			if ir_traits.HasConsumesVarsTrait(op) {
 				varCount += varsUsedByOp(op)
 			}
@@ -36,7 +36,7 @@ func CountVariables(job *compilation.CompilationJob) {
 		// Count variables on expressions inside ops. We do this later because some of these expressions
 		// might be conditional (e.g. `pipeBinding` inside of a ternary), and we don't want to interfere
 		// with indices for top-level binding slots (e.g. `property`).
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
 				if !expression.IsIrExpression(expr) {
 					return
@@ -62,7 +62,7 @@ func CountVariables(job *compilation.CompilationJob) {
 				}
 			})
 		}
-		for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetUpdate().Ops() {
 			expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
 				if !expression.IsIrExpression(expr) {
 					return
@@ -91,7 +91,7 @@ func CountVariables(job *compilation.CompilationJob) {
 
 		// Compatibility mode pass for pure function offsets (as explained above).
 		if job.Compatibility == ir.CompatibilityModeTemplateDefinitionBuilder {
-			for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+			for _, op := range unit.GetCreate().Ops() {
 				expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
 					if !expression.IsIrExpression(expr) {
 						return
@@ -112,7 +112,7 @@ func CountVariables(job *compilation.CompilationJob) {
 					_ = pureFunc
 				})
 			}
-			for op := unit.GetUpdate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+			for _, op := range unit.GetUpdate().Ops() {
 				expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
 					if !expression.IsIrExpression(expr) {
 						return
@@ -152,7 +152,7 @@ func CountVariables(job *compilation.CompilationJob) {
 				// Add var counts for each view to the `ir.TemplateOp` which declares that view (if the view is
 				// an embedded view).
 				for _, unit := range componentJob.GetUnits() {
-					for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+					for _, op := range unit.GetCreate().Ops() {
 						kind := op.GetKind()
 						if kind != ir.OpKindTemplate && kind != ir.OpKindRepeaterCreate &&
 							kind != ir.OpKindConditionalCreate && kind != ir.OpKindConditionalBranchCreate {
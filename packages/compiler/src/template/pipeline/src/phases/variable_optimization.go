@@ -1,15 +1,455 @@
 package phases
 
 import (
-	"ngc-go/packages/compiler/src/template/pipeline/src/compilation"
+	"fmt"
+
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/template/pipeline/ir"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/expression"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/operations"
+	ops_create "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/create"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/shared"
+	ir_variable "ngc-go/packages/compiler/src/template/pipeline/ir/src/variable"
+
+	pipeline "ngc-go/packages/compiler/src/template/pipeline/src/compilation"
 )
 
 // OptimizeVariables optimizes variables declared and used in the IR.
-// This is a placeholder implementation - full implementation should be added later.
-func OptimizeVariables(job *compilation.CompilationJob) {
-	// TODO: Implement full variable optimization logic
-	// This should:
-	// - Transform variable declarations to side effectful expressions when variables are not used
-	// - Remove variable declarations if variables are not referenced
-	// - Inline variable declarations when variables are only used once
+//
+// Variables are eagerly generated by pipeline stages for all possible values that could be
+// referenced. This stage processes the list of declared variables and all variable usages,
+// and optimizes where possible. It performs 3 main optimizations:
+//
+//   - It transforms variable declarations to side effectful expressions when the
+//     variable is not used, but its initializer has global effects which other
+//     operations rely upon.
+//   - It removes variable declarations if those variables are not referenced and
+//     either they do not have global effects, or nothing relies on them.
+//   - It inlines variable declarations when those variables are only used once
+//     and the inlining is semantically safe.
+//
+// To guarantee correctness, analysis of "fences" in the instruction lists is used to determine
+// which optimizations are safe to perform.
+func OptimizeVariables(job *pipeline.CompilationJob) {
+	for _, unit := range job.GetUnits() {
+		inlineAlwaysInlineVariables(unit.GetCreate())
+		inlineAlwaysInlineVariables(unit.GetUpdate())
+		for _, op := range unit.GetCreate().Ops() {
+			if ops := childOpList(op); ops != nil {
+				inlineAlwaysInlineVariables(ops)
+			}
+		}
+
+		optimizeVariablesInOpList(unit.GetCreate(), job.Compatibility)
+		optimizeVariablesInOpList(unit.GetUpdate(), job.Compatibility)
+		for _, op := range unit.GetCreate().Ops() {
+			if ops := childOpList(op); ops != nil {
+				optimizeVariablesInOpList(ops, job.Compatibility)
+			}
+		}
+	}
+}
+
+// childOpList returns the operations nested in a create operation: the handler operations of
+// listeners and the track function operations of repeaters, or nil.
+func childOpList(op operations.Op) *operations.OpList {
+	switch o := op.(type) {
+	case *ops_create.ListenerOp:
+		return o.HandlerOps
+	case *ops_create.AnimationOp:
+		return o.HandlerOps
+	case *ops_create.AnimationListenerOp:
+		return o.HandlerOps
+	case *ops_create.TwoWayListenerOp:
+		return o.HandlerOps
+	case *ops_create.RepeaterCreateOp:
+		return o.TrackByOps
+	}
+	return nil
+}
+
+// fence is a bitmask of the global effects of an operation which order it relative to other
+// operations.
+type fence int
+
+const (
+	// fenceNone means the operation has no global effects.
+	fenceNone fence = 0b000
+
+	// fenceViewContextRead means the operation reads from the current view context, so it cannot
+	// be reordered across operations which write to it.
+	fenceViewContextRead fence = 0b001
+
+	// fenceViewContextWrite means the operation writes to the current view context, so it cannot
+	// be reordered across operations which read from it.
+	fenceViewContextWrite fence = 0b010
+
+	// fenceSideEffectful means the operation has effects which are observable beyond the view
+	// context, so it must be kept even when its result is unused.
+	fenceSideEffectful fence = 0b100
+)
+
+// opInfo summarizes the variables an operation reads and its fences.
+type opInfo struct {
+	variablesUsed map[operations.XrefId]bool
+	fences        fence
+}
+
+// inlineAlwaysInlineVariables replaces the reads of the variables flagged
+// `VariableFlagsAlwaysInline` with their initializers, and removes their declarations.
+func inlineAlwaysInlineVariables(ops *operations.OpList) {
+	vars := make(map[operations.XrefId]*shared.VariableOp)
+	var declarations []*shared.VariableOp
+	for _, op := range ops.Ops() {
+		if varOp, ok := op.(*shared.VariableOp); ok && varOp.Flags&ir.VariableFlagsAlwaysInline != 0 {
+			expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
+				if fencesForIrExpression(expr) != fenceNone {
+					panic("AssertionError: A context-sensitive variable was marked AlwaysInline")
+				}
+			})
+			vars[varOp.Xref] = varOp
+			declarations = append(declarations, varOp)
+		}
+
+		expression.TransformExpressionsInOp(
+			op,
+			func(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
+				if read, ok := expr.(*expression.ReadVariableExpr); ok {
+					if varOp, ok := vars[read.Xref]; ok {
+						// Inline by cloning, because the variable may be read in several places.
+						return varOp.Initializer.Clone()
+					}
+				}
+				return expr
+			},
+			expression.VisitorContextFlagNone,
+		)
+	}
+
+	for _, op := range declarations {
+		ops.Remove(op)
+	}
+}
+
+// optimizeVariablesInOpList removes the unused variables of a list of operations, keeping the
+// side effects of their initializers, and inlines the variables read exactly once.
+func optimizeVariablesInOpList(ops *operations.OpList, compatibility ir.CompatibilityMode) {
+	varDecls := make(map[operations.XrefId]*shared.VariableOp)
+	varUsages := make(map[operations.XrefId]int)
+	// varOrder is the order of declaration of the variables, to inline them deterministically.
+	var varOrder []operations.XrefId
+
+	// Track variables that are used outside of the immediate operation list. For example, within
+	// the handler operations of listeners in the current operation list.
+	varRemoteUsages := make(map[operations.XrefId]bool)
+	opMap := make(map[operations.Op]*opInfo)
+
+	// First, extract information about variables declared or used within the whole list.
+	for _, op := range ops.Ops() {
+		if varOp, ok := op.(*shared.VariableOp); ok {
+			if _, declared := varDecls[varOp.Xref]; declared {
+				panic(fmt.Sprintf("Should not see two declarations of the same variable: %d", varOp.Xref))
+			}
+			if _, used := varUsages[varOp.Xref]; used {
+				panic(fmt.Sprintf("Should not see two declarations of the same variable: %d", varOp.Xref))
+			}
+			varDecls[varOp.Xref] = varOp
+			varUsages[varOp.Xref] = 0
+			varOrder = append(varOrder, varOp.Xref)
+		}
+
+		opMap[op] = collectOpInfo(op)
+		countVariableUsages(op, varUsages, varRemoteUsages)
+	}
+
+	// The next step is to remove any variable declarations for variables that aren't used. The
+	// variable initializer expressions may be side-effectful, so they may need to be retained as
+	// expression statements.
+
+	// Track whether we've seen an operation which reads from the view context yet. This is used to
+	// determine whether a write to the view context in a variable initializer can be observed.
+	contextIsUsed := false
+
+	// Note that iteration through the list happens in reverse, which guarantees that we'll process
+	// all reads of a variable prior to processing its declaration.
+	all := ops.Ops()
+	for i := len(all) - 1; i >= 0; i-- {
+		op := all[i]
+		info := opMap[op]
+
+		if varOp, ok := op.(*shared.VariableOp); ok && varUsages[varOp.Xref] == 0 {
+			// This variable is unused and can be removed. We might need to keep the initializer
+			// around, though, if something depends on it running.
+			if (contextIsUsed && info.fences&fenceViewContextWrite != 0) || info.fences&fenceSideEffectful != 0 {
+				// This variable initializer has a side effect which must be retained. Either:
+				//  * it writes to the view context, and we know there is a future operation which
+				//    depends on that write, or
+				//  * it's an operation which is inherently side-effectful.
+				// We can't remove the initializer, but we can remove the variable declaration
+				// itself and replace it with a side-effectful statement.
+				stmtOp := shared.NewStatementOp(output.NewExpressionStatement(varOp.Initializer, nil, nil))
+				opMap[stmtOp] = info
+				ops.Replace(varOp, stmtOp)
+			} else {
+				// It's safe to delete this entire variable declaration as nothing depends on it,
+				// even side-effectfully. Note that doing this might make other variables unused.
+				// Since we're iterating in reverse order, we should always be processing usages
+				// before declarations and therefore by the time we get to a declaration, all
+				// removable usages will have been removed.
+				uncountVariableUsages(varOp, varUsages)
+				ops.Remove(varOp)
+			}
+
+			delete(opMap, varOp)
+			delete(varDecls, varOp.Xref)
+			delete(varUsages, varOp.Xref)
+			continue
+		}
+
+		// Does this operation depend on the view context?
+		if info.fences&fenceViewContextRead != 0 {
+			contextIsUsed = true
+		}
+	}
+
+	// Next, inline any remaining variables with exactly one usage.
+	var toInline []operations.XrefId
+	for _, id := range varOrder {
+		count, ok := varUsages[id]
+		if !ok {
+			continue
+		}
+		decl := varDecls[id]
+		// We can inline variables that:
+		//  - are used exactly once, and
+		//  - are not used remotely
+		// Variables marked for always inlining were inlined already.
+		if count != 1 || decl.Flags&ir.VariableFlagsAlwaysInline != 0 {
+			// We can't inline this variable as it's used more than once.
+			continue
+		}
+		if varRemoteUsages[id] {
+			// This variable is used once, but across an operation boundary, so it can't be
+			// inlined.
+			continue
+		}
+		toInline = append(toInline, id)
+	}
+
+	for len(toInline) > 0 {
+		candidate := toInline[len(toInline)-1]
+		toInline = toInline[:len(toInline)-1]
+
+		// We will attempt to inline this variable. If inlining fails (due to fences for example),
+		// no future operation will make inlining legal.
+		decl := varDecls[candidate]
+		varInfo := opMap[decl]
+		if decl.Flags&ir.VariableFlagsAlwaysInline != 0 {
+			panic("AssertionError: Found an 'AlwaysInline' variable after the always inlining pass.")
+		}
+
+		// Scan operations following the variable declaration and look for the point where that
+		// variable is used. There should only be one usage given the precondition above.
+		for targetOp := decl.Next(); targetOp != nil && targetOp.GetKind() != ir.OpKindListEnd; targetOp = targetOp.Next() {
+			info := opMap[targetOp]
+
+			// Is the variable used in this operation?
+			if info.variablesUsed[candidate] {
+				if compatibility == ir.CompatibilityModeTemplateDefinitionBuilder && !allowConservativeInlining(decl, targetOp) {
+					// We're in conservative mode, and this variable is not eligible for inlining
+					// into the target operation in this mode.
+					break
+				}
+
+				// Yes, try to inline it. Inlining may not be successful if fences in this
+				// operation before the variable's usage cannot be safely crossed.
+				if tryInlineVariableInitializer(candidate, decl.Initializer, targetOp, varInfo.fences) {
+					// Inlining was successful! Update the tracking structures to reflect the
+					// inlined variable.
+					delete(info.variablesUsed, candidate)
+
+					// Add all variables used in the variable's initializer to its new usage site.
+					for id := range varInfo.variablesUsed {
+						info.variablesUsed[id] = true
+					}
+
+					// Merge fences in the variable's initializer into its new usage site.
+					info.fences |= varInfo.fences
+
+					// Delete tracking info related to the declaration.
+					delete(varDecls, candidate)
+					delete(varUsages, candidate)
+					delete(opMap, decl)
+
+					// And finally, delete the original declaration from the operation list.
+					ops.Remove(decl)
+				}
+
+				// Whether inlining succeeded or failed, we're done processing this variable.
+				break
+			}
+
+			// If the variable is not used in this operation, then we'd need to inline across it.
+			// Check if that's safe to do.
+			if !safeToInlinePastFences(info.fences, varInfo.fences) {
+				// We can't safely inline this variable beyond this operation, so don't proceed
+				// with inlining this variable.
+				break
+			}
+		}
+	}
+}
+
+// fencesForIrExpression returns the fences of an IR expression, not counting the expressions it
+// contains.
+func fencesForIrExpression(expr output.OutputExpression) fence {
+	switch expr.(type) {
+	case *expression.NextContextExpr:
+		return fenceViewContextRead | fenceViewContextWrite
+	case *expression.RestoreViewExpr:
+		return fenceViewContextRead | fenceViewContextWrite | fenceSideEffectful
+	case *expression.StoreLetExpr:
+		return fenceSideEffectful
+	case *expression.ReferenceExpr, *expression.ContextLetReferenceExpr:
+		return fenceViewContextRead
+	}
+	return fenceNone
+}
+
+// collectOpInfo builds the opInfo of an operation.
+func collectOpInfo(op operations.Op) *opInfo {
+	info := &opInfo{variablesUsed: make(map[operations.XrefId]bool)}
+	expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
+		if read, ok := expr.(*expression.ReadVariableExpr); ok {
+			info.variablesUsed[read.Xref] = true
+			return
+		}
+		info.fences |= fencesForIrExpression(expr)
+	})
+	return info
+}
+
+// countVariableUsages counts the reads of the variables declared in the current list by an
+// operation, and records the reads from child operations as remote.
+func countVariableUsages(op operations.Op, varUsages map[operations.XrefId]int, varRemoteUsages map[operations.XrefId]bool) {
+	expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
+		read, ok := expr.(*expression.ReadVariableExpr)
+		if !ok {
+			return
+		}
+		count, ok := varUsages[read.Xref]
+		if !ok {
+			// This variable is declared outside the current scope of optimization.
+			return
+		}
+		varUsages[read.Xref] = count + 1
+
+		if flags&expression.VisitorContextFlagInChildOperation != 0 {
+			varRemoteUsages[read.Xref] = true
+		}
+	})
+}
+
+// uncountVariableUsages removes the reads of an operation from the counts, as the operation is
+// being removed.
+func uncountVariableUsages(op operations.Op, varUsages map[operations.XrefId]int) {
+	expression.VisitExpressionsInOp(op, func(expr output.OutputExpression, flags expression.VisitorContextFlag) {
+		read, ok := expr.(*expression.ReadVariableExpr)
+		if !ok {
+			return
+		}
+		count, ok := varUsages[read.Xref]
+		if !ok {
+			// This variable is declared outside the current scope of optimization.
+			return
+		}
+		if count == 0 {
+			panic(fmt.Sprintf("Inaccurate variable count: %d - found another read but count is already 0", read.Xref))
+		}
+		varUsages[read.Xref] = count - 1
+	})
+}
+
+// safeToInlinePastFences checks whether a variable with the fences declFences can be inlined
+// past an operation or expression with the fences fences.
+func safeToInlinePastFences(fences fence, declFences fence) bool {
+	if fences&fenceViewContextWrite != 0 {
+		// It's not safe to inline context reads across context writes.
+		if declFences&fenceViewContextRead != 0 {
+			return false
+		}
+	} else if fences&fenceViewContextRead != 0 {
+		// It's not safe to inline context writes across context reads.
+		if declFences&fenceViewContextWrite != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// tryInlineVariableInitializer attempts to replace the read of a variable in an operation with
+// its initializer, and reports whether it did. Inlining fails when the read comes after an
+// expression the initializer cannot be moved past.
+func tryInlineVariableInitializer(id operations.XrefId, initializer output.OutputExpression, target operations.Op, declFences fence) bool {
+	// Expressions are walked by a callback, which cannot stop the walk once inlining succeeds or
+	// fails, so keep track of whether inlining succeeded or is no longer allowed.
+	inlined := false
+	inliningAllowed := true
+
+	expression.TransformExpressionsInOp(
+		target,
+		func(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
+			if !expression.IsIrExpression(expr) {
+				return expr
+			}
+
+			if inlined || !inliningAllowed {
+				// Either the inlining has already succeeded, or we've passed a fence that
+				// disallows inlining at this point, so don't try.
+				return expr
+			} else if flags&expression.VisitorContextFlagInChildOperation != 0 && declFences&fenceViewContextRead != 0 {
+				// We cannot inline variables that are sensitive to the current context into
+				// operation boundaries.
+				return expr
+			}
+
+			if read, ok := expr.(*expression.ReadVariableExpr); ok {
+				if read.Xref == id {
+					// This is the usage site of the variable. Since nothing has disallowed
+					// inlining, it's safe to inline the initializer here.
+					inlined = true
+					return initializer
+				}
+				return expr
+			}
+			// For other IR expressions, whether inlining is allowed depends on their fences.
+			inliningAllowed = safeToInlinePastFences(fencesForIrExpression(expr), declFences)
+			return expr
+		},
+		expression.VisitorContextFlagNone,
+	)
+	return inlined
+}
+
+// allowConservativeInlining checks whether a variable may be inlined into an operation when
+// compiling in TemplateDefinitionBuilder compatibility mode, which inlines less.
+func allowConservativeInlining(decl *shared.VariableOp, target operations.Op) bool {
+	variable, ok := decl.Variable.(ir_variable.SemanticVariable)
+	if !ok {
+		return true
+	}
+	switch variable.GetKind() {
+	case ir.SemanticVariableKindIdentifier:
+		if read, ok := decl.Initializer.(*output.ReadVarExpr); ok && read.Name == "ctx" {
+			// Although TemplateDefinitionBuilder is cautious about inlining, we still want to do
+			// so when the variable is the context, to imitate its behavior with aliases in
+			// control flow blocks.
+			return true
+		}
+		return false
+	case ir.SemanticVariableKindContext:
+		// Context can only be inlined into other variables.
+		return target.GetKind() == ir.OpKindVariable
+	}
+	return true
 }
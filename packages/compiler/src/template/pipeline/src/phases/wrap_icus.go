@@ -15,7 +15,7 @@ func WrapI18nIcus(job *pipeline.CompilationJob) {
 		var addedI18nId operations.XrefId = 0
 		hasAddedI18nId := false
 
-		for op := unit.GetCreate().Head(); op != nil && op.GetKind() != ir.OpKindListEnd; op = op.Next() {
+		for _, op := range unit.GetCreate().Ops() {
 			switch op.GetKind() {
 			case ir.OpKindI18nStart:
 				if i18nStartOp, ok := op.(*ops_create.I18nStartOp); ok {
@@ -18,7 +18,7 @@ type OpXrefMapEntry interface {
 func CreateOpXrefMap(unit compilation.CompilationUnit) map[ir_operations.XrefId]OpXrefMapEntry {
 	result := make(map[ir_operations.XrefId]OpXrefMapEntry)
 
-	for op := unit.GetCreate().Head(); op != nil; op = op.Next() {
+	for _, op := range unit.GetCreate().Ops() {
 		if !ir_traits.HasConsumesSlotTrait(op) {
 			continue
 		}
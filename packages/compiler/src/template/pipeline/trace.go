@@ -0,0 +1,262 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/template/pipeline/ir"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/expression"
+	ir_operations "ngc-go/packages/compiler/src/template/pipeline/ir/src/operations"
+	"ngc-go/packages/compiler/src/template/pipeline/src/compilation"
+	"ngc-go/packages/compiler/src/template/pipeline/src/phases"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// DumpAllPhases selects every phase in TraceOptions.DumpIR.
+const DumpAllPhases = "all"
+
+// TraceOptions configures the tracing of the phases run by Transform, to debug the pipeline.
+type TraceOptions struct {
+	// DumpIR lists the phases after which the IR of the traced jobs is printed, or DumpAllPhases.
+	DumpIR []string
+	// Component restricts tracing to the jobs of the component with this class name. Every job is
+	// traced when it is empty.
+	Component string
+	// Out receives the timing of each phase and the IR dumps.
+	Out io.Writer
+}
+
+var traceOptions *TraceOptions
+
+// SetTraceOptions enables the tracing of Transform, or disables it when options is nil.
+func SetTraceOptions(options *TraceOptions) {
+	traceOptions = options
+}
+
+// PhaseNames returns the names of the phases in the order Transform runs them.
+func PhaseNames() []string {
+	phases := allPhases()
+	names := make([]string, len(phases))
+	for i, phase := range phases {
+		names[i] = phaseName(phase)
+	}
+	return names
+}
+
+// phaseName returns the name of a custom phase, or of the function of a built-in phase, e.g.
+// "OptimizeVariables".
+func phaseName(phase Phase) string {
+	if custom, ok := phase.Fn.(CustomPhase); ok {
+		return custom.Name()
+	}
+	name := runtime.FuncForPC(reflect.ValueOf(phase.Fn).Pointer()).Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// tracer traces the phases run on one compilation job. A nil tracer traces nothing.
+type tracer struct {
+	options *TraceOptions
+	job     *compilation.CompilationJob
+	total   time.Duration
+}
+
+// newTracer returns the tracer of a job, or nil when the job is not traced.
+func newTracer(job *compilation.CompilationJob) *tracer {
+	options := traceOptions
+	if options == nil || (options.Component != "" && options.Component != job.ComponentName) {
+		return nil
+	}
+	kind := "template"
+	if job.Kind == compilation.CompilationJobKindHost {
+		kind = "host bindings"
+	}
+	fmt.Fprintf(options.Out, "== %s (%s) ==\n", job.ComponentName, kind)
+	return &tracer{options: options, job: job}
+}
+
+// afterPhase records the time a phase took and dumps the IR if the phase is selected.
+func (t *tracer) afterPhase(phase Phase, elapsed time.Duration) {
+	if t == nil {
+		return
+	}
+	name := phaseName(phase)
+	t.total += elapsed
+	fmt.Fprintf(t.options.Out, "%12s  %s\n", elapsed, name)
+	for _, selected := range t.options.DumpIR {
+		if selected == DumpAllPhases || strings.EqualFold(selected, name) {
+			fmt.Fprintf(t.options.Out, "-- IR after %s --\n", name)
+			DumpIR(t.options.Out, t.job)
+			break
+		}
+	}
+	if name == "Reify" {
+		phases.EnsureNoIrForDebug(t.job)
+	}
+}
+
+// finish prints the total time of the phases.
+func (t *tracer) finish() {
+	if t == nil {
+		return
+	}
+	fmt.Fprintf(t.options.Out, "%12s  total\n", t.total)
+}
+
+// DumpIR prints the create and update operations of every unit of a job.
+func DumpIR(w io.Writer, job *compilation.CompilationJob) {
+	p := &irPrinter{w: w}
+	for _, unit := range job.GetUnits() {
+		name := fmt.Sprintf("view %d", unit.GetXref())
+		if fnName := unit.GetFnName(); fnName != nil {
+			name = fmt.Sprintf("%s (view %d)", *fnName, unit.GetXref())
+		}
+		fmt.Fprintf(w, "%s:\n", name)
+		p.opList("create", unit.GetCreate(), 1)
+		p.opList("update", unit.GetUpdate(), 1)
+	}
+}
+
+var (
+	opListType     = reflect.TypeOf(&ir_operations.OpList{})
+	sourceSpanType = reflect.TypeOf(&util.ParseSourceSpan{})
+	outputTypeType = reflect.TypeOf((*output.Type)(nil)).Elem()
+	newLines       = regexp.MustCompile(`\n\s*`)
+)
+
+// irPrinter prints operations with their exported fields, and expressions as the code they would
+// be emitted as, with the IR expressions they contain printed as `<Kind field=value>`.
+type irPrinter struct {
+	w io.Writer
+}
+
+// namedOpList is an operation list held by a field of an operation, e.g. the handler of a listener.
+type namedOpList struct {
+	name string
+	ops  *ir_operations.OpList
+}
+
+func (p *irPrinter) opList(name string, ops *ir_operations.OpList, depth int) {
+	indent := strings.Repeat("  ", depth)
+	fmt.Fprintf(p.w, "%s%s:\n", indent, name)
+	for _, op := range ops.Ops() {
+		var nested []namedOpList
+		parts := append([]string{op.GetKind().String()}, p.fields(reflect.ValueOf(op).Elem(), &nested, 0)...)
+		fmt.Fprintf(p.w, "%s  %s\n", indent, strings.Join(parts, " "))
+		for _, list := range nested {
+			p.opList(list.name, list.ops, depth+2)
+		}
+	}
+}
+
+// fields describes the exported fields of an operation or IR expression, flattening the embedded
+// structs. Operation lists are collected into nested rather than described.
+func (p *irPrinter) fields(v reflect.Value, nested *[]namedOpList, depth int) []string {
+	var parts []string
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous {
+			if value.Kind() == reflect.Ptr && !value.IsNil() {
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				parts = append(parts, p.fields(value, nested, depth)...)
+			}
+			continue
+		}
+		switch {
+		case field.Name == "Kind" || field.Type == sourceSpanType || field.Type == outputTypeType:
+			continue
+		case field.Type == opListType:
+			if !value.IsNil() && nested != nil {
+				*nested = append(*nested, namedOpList{field.Name, value.Interface().(*ir_operations.OpList)})
+			}
+			continue
+		}
+		if s, ok := p.value(value, depth); ok {
+			parts = append(parts, field.Name+"="+s)
+		}
+	}
+	return parts
+}
+
+// value describes a field, or returns false when it is unset or not worth printing.
+func (p *irPrinter) value(v reflect.Value, depth int) (string, bool) {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface || v.Kind() == reflect.Slice) && v.IsNil() {
+		return "", false
+	}
+	switch x := v.Interface().(type) {
+	case ir_operations.Op:
+		return fmt.Sprintf("<%s>", x.GetKind()), true
+	case output.OutputExpression:
+		return p.expr(x), true
+	case output.OutputStatement:
+		return p.stmt(x), true
+	case *ir.SlotHandle:
+		if x.Slot == nil {
+			return "", false
+		}
+		return strconv.Itoa(*x.Slot), true
+	case fmt.Stringer:
+		return x.String(), true
+	}
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.Elem().Kind() == reflect.Struct && (depth > 2 || !strings.Contains(v.Elem().Type().PkgPath(), "template/pipeline/ir")) {
+			return "", false
+		}
+		return p.value(v.Elem(), depth)
+	case reflect.Struct:
+		return "{" + strings.Join(p.fields(v, nil, depth+1), " ") + "}", true
+	case reflect.String:
+		return strconv.Quote(v.String()), v.Len() > 0
+	case reflect.Bool:
+		return "true", v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), v.Int() != 0
+	case reflect.Slice:
+		var elements []string
+		for i := 0; i < v.Len(); i++ {
+			if s, ok := p.value(v.Index(i), depth); ok {
+				elements = append(elements, s)
+			}
+		}
+		return "[" + strings.Join(elements, ", ") + "]", len(elements) > 0
+	}
+	return "", false
+}
+
+// expr prints an expression, with the IR expressions it contains described in its place.
+func (p *irPrinter) expr(expr output.OutputExpression) string {
+	described := expression.TransformExpressionsInExpression(expr.Clone(), p.describeIr, expression.VisitorContextFlagNone)
+	ctx := output.CreateRootEmitterVisitorContext()
+	described.VisitExpression(output.NewJsEmitterVisitor(), ctx)
+	return newLines.ReplaceAllString(strings.TrimSpace(ctx.ToSource()), " ")
+}
+
+func (p *irPrinter) stmt(stmt output.OutputStatement) string {
+	ctx := output.CreateRootEmitterVisitorContext()
+	output.NewJsEmitterVisitor().VisitAllStatements([]output.OutputStatement{stmt}, ctx)
+	return newLines.ReplaceAllString(strings.TrimSpace(ctx.ToSource()), " ")
+}
+
+// describeIr replaces an IR expression, whose nested expressions are already described, with a
+// variable read printing its kind and fields.
+func (p *irPrinter) describeIr(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
+	irExpr, ok := expr.(expression.IrExpression)
+	if !ok {
+		return expr
+	}
+	parts := append([]string{irExpr.GetKind().String()}, p.fields(reflect.ValueOf(irExpr).Elem(), nil, 0)...)
+	return output.NewReadVarExpr("<"+strings.Join(parts, " ")+">", nil, nil)
+}
@@ -2,6 +2,7 @@ package template_parser
 
 import (
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 
@@ -58,7 +59,14 @@ func (bp *BindingParser) CreateBoundHostProperties(
 	sourceSpan *util.ParseSourceSpan,
 ) []*expression_parser.ParsedProperty {
 	boundProps := []*expression_parser.ParsedProperty{}
-	for propName, expression := range properties {
+	// Iterate in a stable order so that the generated instructions are deterministic.
+	propNames := make([]string, 0, len(properties))
+	for propName := range properties {
+		propNames = append(propNames, propName)
+	}
+	sort.Strings(propNames)
+	for _, propName := range propNames {
+		expression := properties[propName]
 		bp.ParsePropertyBinding(
 			propName,
 			expression,
@@ -81,7 +89,13 @@ func (bp *BindingParser) CreateDirectiveHostEventAsts(
 	sourceSpan *util.ParseSourceSpan,
 ) []*expression_parser.ParsedEvent {
 	targetEvents := []*expression_parser.ParsedEvent{}
-	for propName, expression := range hostListeners {
+	propNames := make([]string, 0, len(hostListeners))
+	for propName := range hostListeners {
+		propNames = append(propNames, propName)
+	}
+	sort.Strings(propNames)
+	for _, propName := range propNames {
+		expression := hostListeners[propName]
 		// Use the `sourceSpan` for `keySpan` and `handlerSpan`
 		targetMatchableAttrs := []string{}
 		bp.parseEvent(
@@ -178,9 +192,9 @@ func (bp *BindingParser) ParseInlineTemplateBinding(
 		absoluteValueOffset,
 	)
 
-	fmt.Printf("[DEBUG] ParseInlineTemplateBinding: processing %d bindings\n", len(bindings))
+	fmt.Fprintf(os.Stderr, "[DEBUG] ParseInlineTemplateBinding: processing %d bindings\n", len(bindings))
 	for i, binding := range bindings {
-		fmt.Printf("[DEBUG] ParseInlineTemplateBinding: binding[%d] type=%T\n", i, binding)
+		fmt.Fprintf(os.Stderr, "[DEBUG] ParseInlineTemplateBinding: binding[%d] type=%T\n", i, binding)
 		// sourceSpan is for the entire HTML attribute. bindingSpan is for a particular
 		// binding within the microsyntax expression so it's more narrow than sourceSpan.
 		bindingSpan := moveParseSourceSpan(sourceSpan, binding.SourceSpan())
@@ -189,7 +203,7 @@ func (bp *BindingParser) ParseInlineTemplateBinding(
 
 		switch b := binding.(type) {
 		case *expression_parser.VariableBinding:
-			fmt.Printf("[DEBUG] ParseInlineTemplateBinding: found VariableBinding, key=%q\n", b.Key.Source)
+			fmt.Fprintf(os.Stderr, "[DEBUG] ParseInlineTemplateBinding: found VariableBinding, key=%q\n", b.Key.Source)
 			key = b.Key.Source
 			keySpan = moveParseSourceSpanFromAbsolute(sourceSpan, b.Key.Span)
 			value := "$implicit"
@@ -474,7 +488,7 @@ func (bp *BindingParser) parsePropertyAst(
 	propType := expression_parser.ParsedPropertyTypeDefault
 	if isPartOfAssignmentBinding {
 		propType = expression_parser.ParsedPropertyTypeTwoWay
-		fmt.Printf("[DEBUG] parsePropertyAst: setting propType to TwoWay for name=%q, isPartOfAssignmentBinding=%v\n", name, isPartOfAssignmentBinding)
+		fmt.Fprintf(os.Stderr, "[DEBUG] parsePropertyAst: setting propType to TwoWay for name=%q, isPartOfAssignmentBinding=%v\n", name, isPartOfAssignmentBinding)
 	}
 	*targetProps = append(*targetProps, expression_parser.NewParsedProperty(
 		name,
@@ -589,11 +603,11 @@ func (bp *BindingParser) parseBinding(
 	sourceSpan *util.ParseSourceSpan,
 	absoluteOffset int,
 ) *expression_parser.ASTWithSource {
-	fmt.Printf("[DEBUG] parseBinding: value=%q, isHostBinding=%v, absoluteOffset=%d\n", value, isHostBinding, absoluteOffset)
+	fmt.Fprintf(os.Stderr, "[DEBUG] parseBinding: value=%q, isHostBinding=%v, absoluteOffset=%d\n", value, isHostBinding, absoluteOffset)
 	result := bp.ParseBinding(value, isHostBinding, sourceSpan, absoluteOffset)
-	fmt.Printf("[DEBUG] parseBinding: result=%v (nil=%v)\n", result != nil, result == nil)
+	fmt.Fprintf(os.Stderr, "[DEBUG] parseBinding: result=%v (nil=%v)\n", result != nil, result == nil)
 	if result != nil && result.AST != nil {
-		fmt.Printf("[DEBUG] parseBinding: AST type=%T\n", result.AST)
+		fmt.Fprintf(os.Stderr, "[DEBUG] parseBinding: AST type=%T\n", result.AST)
 	}
 	return result
 }
@@ -1,9 +1,11 @@
 package css_test
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
+	"ngc-go/packages/compiler/src/core"
 	"ngc-go/packages/compiler/src/css"
 )
 
@@ -886,3 +888,27 @@ func TestCssSelectorParse(t *testing.T) {
 		}
 	})
 }
+
+func TestParseSelectorToR3Selector(t *testing.T) {
+	not := core.SelectorFlagsNOT
+	for _, tc := range []struct {
+		selector string
+		expected core.R3CssSelectorList
+	}{
+		{"", core.R3CssSelectorList{}},
+		{"div", core.R3CssSelectorList{{"div"}}},
+		{".foo", core.R3CssSelectorList{{"", core.SelectorFlagsCLASS, "foo"}}},
+		{"div.foo.bar", core.R3CssSelectorList{{"div", core.SelectorFlagsCLASS, "foo", "bar"}}},
+		{"[foo]", core.R3CssSelectorList{{"", "foo", ""}}},
+		{"[foo=bar].baz", core.R3CssSelectorList{{"", "foo", "bar", core.SelectorFlagsCLASS, "baz"}}},
+		{"div, [foo]", core.R3CssSelectorList{{"div"}, {"", "foo", ""}}},
+		{"div:not(.foo)", core.R3CssSelectorList{{"div", not | core.SelectorFlagsCLASS, "foo"}}},
+		{":not(span)", core.R3CssSelectorList{{"", not | core.SelectorFlagsELEMENT, "span"}}},
+		{"[foo]:not([bar=baz].x)", core.R3CssSelectorList{{"", "foo", "", not | core.SelectorFlagsATTRIBUTE, "bar", "baz", core.SelectorFlagsCLASS, "x"}}},
+	} {
+		selector := tc.selector
+		if got := css.ParseSelectorToR3Selector(&selector); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%q: expected %v, got %v", tc.selector, tc.expected, got)
+		}
+	}
+}
@@ -572,6 +572,20 @@ func TestHtmlParser_Parse(t *testing.T) {
 			}
 		})
 
+		t.Run("should parse attributes without values in incomplete start tags", func(t *testing.T) {
+			expected := []interface{}{
+				[]interface{}{"Element", "div", 0},
+				[]interface{}{"Attribute", "k", ""},
+			}
+			parsed := parser.Parse(`<div k`, "TestComp", nil)
+			if diff := cmp.Diff(expected, HumanizeNodes(parsed.RootNodes, false)); diff != "" {
+				t.Errorf("HumanizeNodes() mismatch (-want +got):\n%s", diff)
+			}
+			if len(parsed.Errors) != 2 {
+				t.Errorf("Expected 2 errors, got %d", len(parsed.Errors))
+			}
+		})
+
 		t.Run("should parse attributes on svg elements case sensitive", func(t *testing.T) {
 			expected := []interface{}{
 				[]interface{}{"Element", ":svg:svg", 0},
@@ -1344,6 +1344,24 @@ func TestHtmlLexer_EscapedStrings(t *testing.T) {
 			t.Errorf("tokenizeAndHumanizeParts() mismatch (-want +got):\n%s", diff)
 		}
 	})
+
+	t.Run("should tokenize attributes without values", func(t *testing.T) {
+		expected := []interface{}{
+			[]interface{}{ml_parser.TokenTypeTAG_OPEN_START, "", "p"},
+			[]interface{}{ml_parser.TokenTypeATTR_NAME, "", "a"},
+			[]interface{}{ml_parser.TokenTypeATTR_NAME, "", "b"},
+			[]interface{}{ml_parser.TokenTypeATTR_QUOTE, "\""},
+			[]interface{}{ml_parser.TokenTypeATTR_VALUE_TEXT, "c"},
+			[]interface{}{ml_parser.TokenTypeATTR_QUOTE, "\""},
+			[]interface{}{ml_parser.TokenTypeTAG_OPEN_END},
+			[]interface{}{ml_parser.TokenTypeTAG_CLOSE, "", "p"},
+			[]interface{}{ml_parser.TokenTypeEOF},
+		}
+		result := tokenizeAndHumanizeParts(`<p a b="c"></p>`, &ml_parser.TokenizeOptions{EscapedString: boolPtr(true)})
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Errorf("tokenizeAndHumanizeParts() mismatch (-want +got):\n%s", diff)
+		}
+	})
 }
 
 func TestHtmlLexer_LetDeclarations(t *testing.T) {
@@ -0,0 +1,56 @@
+package output_test
+
+import (
+	"reflect"
+	"testing"
+
+	"ngc-go/packages/compiler/src/output"
+)
+
+func TestImportManager(t *testing.T) {
+	reference := func(module, name string) output.OutputExpression {
+		return output.NewExternalExpr(&output.ExternalReference{ModuleName: &module, Name: &name}, nil, nil, nil)
+	}
+
+	t.Run("should collect the modules of statements in order of first use", func(t *testing.T) {
+		imports := output.NewImportManager()
+		imports.CollectStatements([]output.OutputStatement{
+			output.NewExpressionStatement(output.NewInvokeFunctionExpr(reference("@angular/core", "ɵɵdefineComponent"), []output.OutputExpression{
+				output.NewLiteralArrayExpr([]output.OutputExpression{
+					reference("./shout.pipe.js", "ShoutPipe"),
+					reference("@angular/core", "ɵɵdefineDirective"),
+				}, nil, nil),
+			}, nil, nil, false), nil, nil),
+		})
+
+		expected := []string{
+			"import * as i0 from '@angular/core';",
+			"import * as i1 from './shout.pipe.js';",
+		}
+		if got := imports.Declarations(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("should share prefixes between emitters", func(t *testing.T) {
+		imports := output.NewImportManager()
+		if prefix := imports.Prefix("./highlight.directive.js"); prefix != "i0" {
+			t.Errorf("Expected i0, got %s", prefix)
+		}
+		emitter := output.NewJsEmitterVisitor()
+		emitter.ImportManager = imports
+		ctx := output.CreateRootEmitterVisitorContext()
+		emitter.VisitAllStatements([]output.OutputStatement{
+			output.NewExpressionStatement(reference("@angular/core", "ɵɵtext"), nil, nil),
+			output.NewExpressionStatement(reference("./highlight.directive.js", "HighlightDirective"), nil, nil),
+		}, ctx)
+
+		expected := "i1.ɵɵtext;\ni0.HighlightDirective;"
+		if got := ctx.ToSource(); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+		if got := len(imports.ImportsWithPrefixes()); got != 2 {
+			t.Errorf("Expected 2 imports, got %d", got)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package output_test
+
+import (
+	"testing"
+
+	"ngc-go/packages/compiler/src/output"
+)
+
+func TestJavaScriptEmitter(t *testing.T) {
+	coreModule := "@angular/core"
+	external := func(name string) output.OutputExpression {
+		return output.NewExternalExpr(&output.ExternalReference{ModuleName: &coreModule, Name: &name}, nil, nil, nil)
+	}
+	emit := func(preamble string, stmts ...output.OutputStatement) string {
+		return output.JavaScriptEmitter{}.EmitStatements("someGenFile.js", stmts, preamble)
+	}
+
+	t.Run("should import external references through namespace prefixes", func(t *testing.T) {
+		otherModule := "./other.js"
+		otherName := "Other"
+		stmt := output.NewExpressionStatement(output.NewLiteralArrayExpr([]output.OutputExpression{
+			external("ɵɵdefineComponent"),
+			output.NewExternalExpr(&output.ExternalReference{ModuleName: &otherModule, Name: &otherName}, nil, nil, nil),
+			external("ɵɵdefineDirective"),
+		}, nil, nil), nil, nil)
+
+		expected := "import * as i0 from '@angular/core';\n" +
+			"import * as i1 from './other.js';\n" +
+			"[i0.ɵɵdefineComponent,i1.Other,i0.ɵɵdefineDirective];\n"
+		if got := emit("", stmt); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("should print the module namespace of references without a name", func(t *testing.T) {
+		stmt := output.NewExpressionStatement(
+			output.NewExternalExpr(&output.ExternalReference{ModuleName: &coreModule}, nil, nil, nil), nil, nil)
+
+		expected := "import * as i0 from '@angular/core';\ni0;\n"
+		if got := emit("", stmt); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("should emit the preamble before the imports", func(t *testing.T) {
+		stmt := output.NewExpressionStatement(external("ɵɵtext"), nil, nil)
+
+		expected := "import { Foo } from './foo.js';\nimport * as i0 from '@angular/core';\ni0.ɵɵtext;\n"
+		if got := emit("import { Foo } from './foo.js';\n", stmt); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("should print top-level assignments without parentheses", func(t *testing.T) {
+		field := output.NewReadPropExpr(output.NewReadVarExpr("Foo", nil, nil), "ɵfac", nil, nil)
+		stmt := output.NewExpressionStatement(field.Set(output.NewLiteralExpr(nil, nil, nil)), nil, nil)
+
+		expected := "Foo.ɵfac = null;\n"
+		if got := emit("", stmt); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("should copy wrapped source code verbatim", func(t *testing.T) {
+		stmt := output.NewExpressionStatement(output.NewWrappedNodeExpr("{ provide: TOKEN, useValue: 1 }", nil, nil), nil, nil)
+
+		expected := "{ provide: TOKEN, useValue: 1 };\n"
+		if got := emit("", stmt); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("should export exported declarations", func(t *testing.T) {
+		stmt := output.NewDeclareVarStmt("x", output.NewLiteralExpr(1, nil, nil), nil, output.StmtModifierExported, nil, nil)
+
+		expected := "export var x = 1;\n"
+		if got := emit("", stmt); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("should print the parameters of functions", func(t *testing.T) {
+		fn := output.NewFunctionExpr([]*output.FnParam{output.NewFnParam("rf", nil), output.NewFnParam("ctx", nil)},
+			[]output.OutputStatement{}, nil, nil, nil)
+		stmt := output.NewDeclareVarStmt("fn", fn, nil, output.StmtModifierNone, nil, nil)
+
+		expected := "var fn = function(rf,ctx) {\n};\n"
+		if got := emit("", stmt); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("should print the leading comments of statements", func(t *testing.T) {
+		desc, meaning := "desc", "meaning"
+		doc := output.NewJSDocComment([]output.JSDocTag{{TagName: &desc, Text: strPtr("Says hello")}, {TagName: &meaning, Text: strPtr("a@b")}})
+		field := output.NewReadPropExpr(output.NewReadVarExpr("Foo", nil, nil), "ɵfac", nil, nil)
+		stmts := []output.OutputStatement{
+			output.NewDeclareVarStmt("x", output.NewLiteralExpr(1, nil, nil), nil, output.StmtModifierNone, nil,
+				[]*output.LeadingComment{{JSDoc: doc, TrailingNewline: true}, {Text: "a\nb"}, {Text: "c", Multiline: true}}),
+			output.NewExpressionStatement(field.Set(output.NewLiteralExpr(nil, nil, nil)), nil,
+				[]*output.LeadingComment{{JSDoc: doc}}),
+		}
+
+		expected := "/**\n * @desc Says hello\n * @meaning a\\@b\n */\n// a\n// b\n/* c */ var x = 1;\n" +
+			"/** @desc Says hello @meaning a\\@b */ Foo.ɵfac = null;\n"
+		if got := emit("", stmts...); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("should annotate constants for Closure Compiler", func(t *testing.T) {
+		desc := "desc"
+		stmts := []output.OutputStatement{
+			output.NewDeclareVarStmt("_c0", output.NewLiteralExpr(1, nil, nil), nil, output.StmtModifierFinal, nil, nil),
+			output.NewDeclareVarStmt("MSG_0", output.NewLiteralExpr(2, nil, nil), nil, output.StmtModifierFinal, nil,
+				[]*output.LeadingComment{{JSDoc: output.NewJSDocComment([]output.JSDocTag{{TagName: &desc, Text: strPtr("d")}}), TrailingNewline: true}}),
+			output.NewDeclareVarStmt("x", output.NewLiteralExpr(3, nil, nil), nil, output.StmtModifierNone, nil, nil),
+		}
+
+		expected := "/** @const */ var _c0 = 1;\n/**\n * @desc d\n * @const\n */\nvar MSG_0 = 2;\nvar x = 3;\n"
+		emitter := output.JavaScriptEmitter{AnnotateForClosureCompiler: true}
+		if got := emitter.EmitStatements("someGenFile.js", stmts, ""); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+		if got := emit("", stmts...); got != "var _c0 = 1;\n/**\n * @desc d\n */\nvar MSG_0 = 2;\nvar x = 3;\n" {
+			t.Errorf("Expected no annotations, got %q", got)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package output_test
+
+import (
+	"testing"
+
+	"ngc-go/packages/compiler/src/output"
+)
+
+func TestTypeScriptEmitter(t *testing.T) {
+	coreModule := "@angular/core"
+	external := func(name string, typeParams ...output.Type) *output.ExternalExpr {
+		return output.NewExternalExpr(&output.ExternalReference{ModuleName: &coreModule, Name: &name}, nil, typeParams, nil)
+	}
+	emit := func(stmts ...output.OutputStatement) string {
+		return output.TypeScriptEmitter{}.EmitStatements("someGenFile.ts", stmts, "")
+	}
+
+	t.Run("should declare variables with their types", func(t *testing.T) {
+		stmts := []output.OutputStatement{
+			output.NewDeclareVarStmt("a", output.NewLiteralExpr(1, nil, nil), output.NumberType, output.StmtModifierFinal, nil, nil),
+			output.NewDeclareVarStmt("b", nil, output.NewArrayType(output.StringType, output.TypeModifierNone), output.StmtModifierNone, nil, nil),
+			output.NewDeclareVarStmt("c", output.NewLiteralExpr(true, nil, nil), output.InferredType, output.StmtModifierFinal|output.StmtModifierExported, nil, nil),
+		}
+
+		expected := "const a: number = 1;\nlet b: string[];\nexport const c = true;\n"
+		if got := emit(stmts...); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("should print typed function parameters", func(t *testing.T) {
+		stmt := output.NewDeclareFunctionStmt("fn", []*output.FnParam{
+			output.NewFnParam("rf", output.NumberType),
+			output.NewFnParam("ctx", nil),
+		}, []output.OutputStatement{
+			output.NewReturnStatement(output.NewArrowFunctionExpr(
+				[]*output.FnParam{output.NewFnParam("m", output.DynamicType)},
+				output.NewReadVarExpr("m", nil, nil), nil, nil), nil, nil),
+		}, output.FunctionType, output.StmtModifierNone, nil, nil)
+
+		expected := "function fn(rf: number,ctx: any): Function {\n  return (m: any) =>m;\n}\n"
+		if got := emit(stmt); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("should print external types with type arguments through namespace prefixes", func(t *testing.T) {
+		foo := output.NewReadVarExpr("Foo", nil, nil)
+		var boolType output.Type = output.BoolType
+		typ := output.NewExpressionType(external("ɵɵComponentDeclaration",
+			output.NewExpressionType(foo, output.TypeModifierNone, nil),
+			output.NewExpressionType(output.NewLiteralExpr("foo", nil, nil), output.TypeModifierNone, nil),
+			output.NoneType,
+			output.NewMapType(&boolType, output.TypeModifierNone),
+		), output.TypeModifierNone, nil)
+		stmt := output.NewDeclareVarStmt("cmp", nil, typ, output.StmtModifierNone, nil, nil)
+
+		expected := "import * as i0 from '@angular/core';\n" +
+			"let cmp: i0.ɵɵComponentDeclaration<Foo, 'foo', never, {[key: string]:boolean}>;\n"
+		if got := emit(stmt); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("should print typed static fields", func(t *testing.T) {
+		foo := output.NewReadVarExpr("Foo", nil, nil)
+		emitter := output.NewTsEmitterVisitor()
+		ctx := output.NewEmitterVisitorContext(1)
+		typ := output.NewExpressionType(external("ɵɵFactoryDeclaration"), output.TypeModifierNone,
+			[]output.Type{output.NewExpressionType(foo, output.TypeModifierNone, nil), output.NoneType})
+		initializer := output.NewInvokeFunctionExpr(external("ɵɵdefineFoo"), []output.OutputExpression{foo}, nil, nil, false)
+		emitter.EmitStaticField("ɵfac", typ, initializer, ctx)
+
+		expected := "  static ɵfac: i0.ɵɵFactoryDeclaration<Foo, never> = i0.ɵɵdefineFoo(Foo);"
+		if got := ctx.ToSource(); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+		if imports := emitter.ImportsWithPrefixes(); len(imports) != 1 || imports[0].ModuleName != coreModule {
+			t.Errorf("Expected a single import of %s, got %v", coreModule, imports)
+		}
+	})
+}
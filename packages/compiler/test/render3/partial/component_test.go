@@ -0,0 +1,96 @@
+package partial_test
+
+import (
+	"testing"
+
+	"ngc-go/packages/compiler/src/core"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/partial"
+	"ngc-go/packages/compiler/src/render3/view"
+)
+
+// literalMapValue returns the value of a key of a literal map, or nil if the map has no such key.
+func literalMapValue(literal *output.LiteralMapExpr, key string) output.OutputExpression {
+	for _, entry := range literal.Entries {
+		if entry.Key == key {
+			return entry.Value
+		}
+	}
+	return nil
+}
+
+func TestCreateComponentDefinitionMap(t *testing.T) {
+	t.Run("should declare the directives, pipes and NgModules used by the template", func(t *testing.T) {
+		selector := "app-root"
+		template := view.ParseTemplate(`<my-dir></my-dir>`, "app.component.html", nil)
+		meta := &view.R3ComponentMetadata{
+			R3DirectiveMetadata: view.R3DirectiveMetadata{
+				Name:     "AppComponent",
+				Type:     render3.R3Reference{Value: output.NewReadVarExpr("AppComponent", nil, nil)},
+				Selector: &selector,
+			},
+			Template:      view.R3ComponentTemplateMetadata{Nodes: template.Nodes},
+			Encapsulation: core.ViewEncapsulationEmulated,
+			Declarations: []view.R3TemplateDependencyMetadata{
+				view.R3DirectiveDependencyMetadata{
+					R3TemplateDependency: view.R3TemplateDependency{
+						Kind: view.R3TemplateDependencyKindDirective,
+						Type: output.NewReadVarExpr("MyDir", nil, nil),
+					},
+					Selector: "my-dir",
+				},
+				view.R3PipeDependencyMetadata{
+					R3TemplateDependency: view.R3TemplateDependency{
+						Kind: view.R3TemplateDependencyKindPipe,
+						Type: output.NewReadVarExpr("MyPipe", nil, nil),
+					},
+					Name: "myPipe",
+				},
+				view.R3NgModuleDependencyMetadata{
+					R3TemplateDependency: view.R3TemplateDependency{
+						Kind: view.R3TemplateDependencyKindNgModule,
+						Type: output.NewReadVarExpr("MyModule", nil, nil),
+					},
+				},
+			},
+			Defer:                   view.R3ComponentDeferMetadata{Mode: view.DeferBlockDepsEmitModePerBlock},
+			DeclarationListEmitMode: view.DeclarationListEmitModeDirect,
+		}
+
+		definitionMap := partial.CreateComponentDefinitionMap(meta, template, partial.DeclareComponentTemplateInfo{})
+
+		var dependencies *output.LiteralArrayExpr
+		for _, entry := range definitionMap.Values {
+			if entry.Key == "dependencies" {
+				dependencies, _ = entry.Value.(*output.LiteralArrayExpr)
+			}
+		}
+		if dependencies == nil || len(dependencies.Entries) != 3 {
+			t.Fatalf("expected a literal array of 3 dependencies, got %#v", dependencies)
+		}
+		expected := []struct{ kind, typ string }{
+			{"directive", "MyDir"},
+			{"pipe", "MyPipe"},
+			{"ngmodule", "MyModule"},
+		}
+		for i, want := range expected {
+			dependency, ok := dependencies.Entries[i].(*output.LiteralMapExpr)
+			if !ok {
+				t.Fatalf("expected dependency %d to be a literal map, got %T", i, dependencies.Entries[i])
+			}
+			kind, ok := literalMapValue(dependency, "kind").(*output.LiteralExpr)
+			if !ok || kind.Value != want.kind {
+				t.Errorf("expected dependency %d to be of kind %q, got %#v", i, want.kind, literalMapValue(dependency, "kind"))
+			}
+			typ, ok := literalMapValue(dependency, "type").(*output.ReadVarExpr)
+			if !ok || typ.Name != want.typ {
+				t.Errorf("expected dependency %d to have type %s, got %#v", i, want.typ, literalMapValue(dependency, "type"))
+			}
+		}
+		selectorValue, ok := literalMapValue(dependencies.Entries[0].(*output.LiteralMapExpr), "selector").(*output.LiteralExpr)
+		if !ok || selectorValue.Value != "my-dir" {
+			t.Errorf("expected the directive dependency to have selector my-dir, got %#v", selectorValue)
+		}
+	})
+}
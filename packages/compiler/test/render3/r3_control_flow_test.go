@@ -0,0 +1,63 @@
+package render3_test
+
+import (
+	"testing"
+
+	"ngc-go/packages/compiler/src/i18n"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/view"
+)
+
+// expectI18nMeta checks that the i18n metadata of a block is absent or an i18n node, and not the
+// accessor of the metadata of the HTML block.
+func expectI18nMeta(t *testing.T, block string, meta render3.I18nMeta) {
+	t.Helper()
+	switch meta.(type) {
+	case nil, i18n.Node, *i18n.Message:
+	default:
+		t.Errorf("expected the i18n metadata of %s to be an i18n node, got %T", block, meta)
+	}
+}
+
+func TestControlFlowBlocksI18n(t *testing.T) {
+	t.Run("should pass the i18n metadata of control flow blocks", func(t *testing.T) {
+		parsed := view.ParseTemplate(
+			`@if (a) {A} @else if (b) {B} @else {C}`+
+				`@for (item of items; track item) {D} @empty {E}`+
+				`@switch (a) { @case (1) {F} @default {G} }`,
+			"app.component.html", nil)
+		if len(parsed.Errors) > 0 {
+			t.Fatalf("unexpected template errors: %v", parsed.Errors)
+		}
+		for _, node := range parsed.Nodes {
+			switch block := node.(type) {
+			case *render3.IfBlock:
+				for _, branch := range block.Branches {
+					expectI18nMeta(t, "@if", branch.I18n)
+				}
+			case *render3.ForLoopBlock:
+				expectI18nMeta(t, "@for", block.I18n)
+				expectI18nMeta(t, "@empty", block.Empty.I18n)
+			case *render3.SwitchBlock:
+				for _, switchCase := range block.Cases {
+					expectI18nMeta(t, "@case", switchCase.I18n)
+				}
+			}
+		}
+	})
+
+	t.Run("should pass the i18n metadata of deferred blocks", func(t *testing.T) {
+		parsed := view.ParseTemplate(`@defer {A} @placeholder {B} @loading {C} @error {D}`, "app.component.html", nil)
+		if len(parsed.Errors) > 0 {
+			t.Fatalf("unexpected template errors: %v", parsed.Errors)
+		}
+		deferred, ok := parsed.Nodes[0].(*render3.DeferredBlock)
+		if !ok {
+			t.Fatalf("expected a deferred block, got %T", parsed.Nodes[0])
+		}
+		expectI18nMeta(t, "@defer", deferred.I18n)
+		expectI18nMeta(t, "@placeholder", deferred.Placeholder.I18n)
+		expectI18nMeta(t, "@loading", deferred.Loading.I18n)
+		expectI18nMeta(t, "@error", deferred.Error.I18n)
+	})
+}
@@ -0,0 +1,70 @@
+package render3_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler/src/facade"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+)
+
+func TestCompileFactoryFunction(t *testing.T) {
+	compile := func(target facade.FactoryTarget, deps ...render3.R3DependencyMetadata) string {
+		compiled := render3.CompileFactoryFunction(&render3.R3ConstructorFactoryMetadata{
+			Name:   "Target",
+			Type:   render3.R3Reference{Value: output.NewReadVarExpr("Target", nil, nil)},
+			Deps:   deps,
+			Target: target,
+		})
+		source := output.JavaScriptEmitter{}.EmitStatements("target.js", []output.OutputStatement{
+			output.NewExpressionStatement(compiled.Expression, nil, nil),
+		}, "")
+		// Ignore the line breaks the emitter adds to long lines.
+		return regexp.MustCompile(`\n +`).ReplaceAllString(source, "")
+	}
+	token := func(name string) output.OutputExpression {
+		return output.NewReadVarExpr(name, nil, nil)
+	}
+
+	t.Run("should inject the dependencies of directives with directiveInject", func(t *testing.T) {
+		for _, target := range []facade.FactoryTarget{facade.FactoryTargetComponent, facade.FactoryTargetDirective} {
+			source := compile(target, render3.R3DependencyMetadata{Token: token("Dep")})
+			if !strings.Contains(source, "i0.ɵɵdirectiveInject(Dep)") {
+				t.Errorf("expected the dependency to be injected with directiveInject, got:\n%s", source)
+			}
+		}
+	})
+
+	t.Run("should inject the dependencies of injectables with inject", func(t *testing.T) {
+		source := compile(facade.FactoryTargetInjectable, render3.R3DependencyMetadata{Token: token("Dep")})
+		if !strings.Contains(source, "i0.ɵɵinject(Dep)") {
+			t.Errorf("expected the dependency to be injected with inject, got:\n%s", source)
+		}
+	})
+
+	t.Run("should pass the flags of the runtime InjectFlags", func(t *testing.T) {
+		source := compile(facade.FactoryTargetDirective,
+			render3.R3DependencyMetadata{Token: token("HostDep"), Host: true},
+			render3.R3DependencyMetadata{Token: token("SelfDep"), Self: true},
+			render3.R3DependencyMetadata{Token: token("SkipSelfDep"), SkipSelf: true},
+			render3.R3DependencyMetadata{Token: token("OptionalDep"), Optional: true},
+		)
+		for _, expected := range []string{
+			"i0.ɵɵdirectiveInject(HostDep,1)",
+			"i0.ɵɵdirectiveInject(SelfDep,2)",
+			"i0.ɵɵdirectiveInject(SkipSelfDep,4)",
+			"i0.ɵɵdirectiveInject(OptionalDep,8)",
+		} {
+			if !strings.Contains(source, expected) {
+				t.Errorf("expected output to contain %q, got:\n%s", expected, source)
+			}
+		}
+
+		source = compile(facade.FactoryTargetPipe, render3.R3DependencyMetadata{Token: token("PipeDep"), Optional: true})
+		if !strings.Contains(source, "i0.ɵɵdirectiveInject(PipeDep,24)") {
+			t.Errorf("expected the flags of a pipe dependency to include ForPipe, got:\n%s", source)
+		}
+	})
+}
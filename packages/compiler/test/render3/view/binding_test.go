@@ -636,6 +636,23 @@ func TestT2Binding(t *testing.T) {
 		})
 	})
 
+	t.Run("should collect defer blocks and the directives used eagerly", func(t *testing.T) {
+		template := view.ParseTemplate(`<div dir></div> @defer { <div hasOutput></div> } @defer { <span></span> }`, "", nil)
+		binder := view.NewR3TargetBinder(makeSelectorMatcher())
+		res := binder.Bind(&view.Target{Template: template.Nodes})
+
+		if blocks := res.GetDeferBlocks(); len(blocks) != 2 {
+			t.Fatalf("Expected 2 defer blocks, got %d", len(blocks))
+		}
+		if used := res.GetUsedDirectives(); len(used) != 2 {
+			t.Errorf("Expected 2 used directives, got %d", len(used))
+		}
+		eager := res.GetEagerlyUsedDirectives()
+		if len(eager) != 1 || eager[0].(view.DirectiveMeta).Name() != "Dir" {
+			t.Errorf("Expected only Dir to be used eagerly, got %v", eager)
+		}
+	})
+
 	// Note: Additional test cases for defer blocks, switch blocks, for loop blocks, etc.
 	// can be added here following the same pattern
 }
@@ -0,0 +1,57 @@
+package view_test
+
+import (
+	"reflect"
+	"testing"
+
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3/view"
+)
+
+func TestConditionallyCreateDirectiveBindingLiteral(t *testing.T) {
+	t.Run("should emit the bindings in the order of their keys", func(t *testing.T) {
+		bindings := map[string]interface{}{
+			"zeta":  "zeta",
+			"alpha": "alphaAlias",
+			"mid": &view.DirectiveBindingValue{
+				ClassPropertyName:   "mid",
+				BindingPropertyName: "midAlias",
+			},
+			"beta": "beta",
+		}
+		expected := []string{"alpha", "beta", "mid", "zeta"}
+
+		// Maps have no iteration order, so a single compilation could pass by chance.
+		for i := 0; i < 20; i++ {
+			literal, ok := view.ConditionallyCreateDirectiveBindingLiteral(bindings, true).(*output.LiteralMapExpr)
+			if !ok {
+				t.Fatalf("expected a literal map")
+			}
+			keys := []string{}
+			for _, entry := range literal.Entries {
+				keys = append(keys, entry.Key)
+			}
+			if !reflect.DeepEqual(keys, expected) {
+				t.Fatalf("expected keys %v, got %v", expected, keys)
+			}
+		}
+	})
+}
+
+func TestDefinitionMap(t *testing.T) {
+	t.Run("should skip nil values, including nil pointers to expressions", func(t *testing.T) {
+		definitionMap := view.NewDefinitionMap()
+		var attributes *output.LiteralArrayExpr
+		definitionMap.Set("hostAttrs", attributes)
+		definitionMap.Set("decls", nil)
+		definitionMap.Set("vars", output.NewLiteralExpr(1, output.InferredType, nil))
+
+		keys := []string{}
+		for _, entry := range definitionMap.Values {
+			keys = append(keys, entry.Key)
+		}
+		if expected := []string{"vars"}; !reflect.DeepEqual(keys, expected) {
+			t.Errorf("expected keys %v, got %v", expected, keys)
+		}
+	})
+}
@@ -0,0 +1,185 @@
+package pipeline_test
+
+import (
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/view"
+	"ngc-go/packages/compiler/src/template/pipeline"
+	"ngc-go/packages/compiler/src/template/pipeline/ir"
+	ops_create "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/create"
+	ops_update "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/update"
+	"ngc-go/packages/compiler/src/template/pipeline/src/compilation"
+	"ngc-go/packages/compiler/src/template_parser"
+	"ngc-go/packages/compiler/src/util"
+)
+
+func hostSourceSpan() *util.ParseSourceSpan {
+	file := util.NewParseSourceFile("", "host.ts")
+	location := util.NewParseLocation(file, 0, 0, 0)
+	return util.NewParseSourceSpan(location, location, location, nil)
+}
+
+// ingestComponent ingests a template into a component compilation job.
+func ingestComponent(t *testing.T, template string, deferMeta view.R3ComponentDeferMetadata) *compilation.ComponentCompilationJob {
+	t.Helper()
+	parsed := view.ParseTemplate(template, "app.component.html", nil)
+	if len(parsed.Errors) > 0 {
+		t.Fatalf("unexpected template errors: %v", parsed.Errors)
+	}
+	return pipeline.IngestComponent("AppComponent", parsed.Nodes, constant.NewConstantPool(false),
+		compilation.TemplateCompilationModeFull, "app.component.ts", false, deferMeta, nil, nil, false)
+}
+
+func TestIngestForBlock(t *testing.T) {
+	t.Run("should read the item and the context variables of a repeater from their context properties", func(t *testing.T) {
+		job := ingestComponent(t, `@for (item of items; track item; let i = $index, c = $count) {{{item}}}`,
+			view.R3ComponentDeferMetadata{Mode: view.DeferBlockDepsEmitModePerBlock})
+		units := job.GetUnits()
+		if len(units) != 2 {
+			t.Fatalf("expected 2 views, got %d", len(units))
+		}
+		contextVariables := units[1].(*compilation.ViewCompilationUnit).ContextVariables
+		if value := contextVariables["item"]; value != "$implicit" {
+			t.Errorf("expected the item to read \"$implicit\", got %q", value)
+		}
+		// The index and count are also stored under names unique to the repeater.
+		for name, value := range contextVariables {
+			for _, property := range []string{"$index", "$count"} {
+				if strings.Contains(name, property) && value != property {
+					t.Errorf("expected the context variable %s to read %q, got %q", name, property, value)
+				}
+			}
+		}
+		if len(contextVariables) != 5 {
+			t.Errorf("expected 5 context variables, got %v", contextVariables)
+		}
+	})
+}
+
+func TestIngestDeferBlock(t *testing.T) {
+	ingestDefer := func(blocks func(deferred *render3.DeferredBlock) map[*render3.DeferredBlock]*output.OutputExpression) *compilation.ComponentCompilationJob {
+		parsed := view.ParseTemplate(`@defer {<span></span>}`, "app.component.html", nil)
+		deferred, ok := parsed.Nodes[0].(*render3.DeferredBlock)
+		if !ok {
+			t.Fatalf("expected a deferred block, got %T", parsed.Nodes[0])
+		}
+		return pipeline.IngestComponent("AppComponent", parsed.Nodes, constant.NewConstantPool(false),
+			compilation.TemplateCompilationModeFull, "app.component.ts", false,
+			view.R3ComponentDeferMetadata{Mode: view.DeferBlockDepsEmitModePerBlock, Blocks: blocks(deferred)},
+			nil, nil, false)
+	}
+
+	t.Run("should use the dependency function of each deferred block", func(t *testing.T) {
+		var resolverFn output.OutputExpression = output.NewReadVarExpr("AppComponent_Defer_1_DepsFn", nil, nil)
+		job := ingestDefer(func(deferred *render3.DeferredBlock) map[*render3.DeferredBlock]*output.OutputExpression {
+			return map[*render3.DeferredBlock]*output.OutputExpression{deferred: &resolverFn}
+		})
+		var deferOp *ops_create.DeferOp
+		for _, op := range job.Root.Create.Ops() {
+			if op, ok := op.(*ops_create.DeferOp); ok {
+				deferOp = op
+			}
+		}
+		if deferOp == nil {
+			t.Fatalf("expected a defer operation")
+		}
+		if deferOp.OwnResolverFn != resolverFn {
+			t.Errorf("expected the defer operation to use the dependency function of the block, got %#v", deferOp.OwnResolverFn)
+		}
+	})
+
+	t.Run("should fail when a deferred block has no dependency function", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected ingestion to fail")
+			}
+		}()
+		ingestDefer(func(*render3.DeferredBlock) map[*render3.DeferredBlock]*output.OutputExpression {
+			return map[*render3.DeferredBlock]*output.OutputExpression{}
+		})
+	})
+}
+
+func TestIngestHostBinding(t *testing.T) {
+	t.Run("should ingest host properties, attributes and listeners", func(t *testing.T) {
+		parser := view.MakeBindingParser(false)
+		job := pipeline.IngestHostBinding(
+			&pipeline.HostBindingInput{
+				ComponentName:     "AppComponent",
+				ComponentSelector: "app-root",
+				Properties: parser.CreateBoundHostProperties(template_parser.HostProperties{
+					"attr.role": "role",
+					"title":     "title",
+				}, hostSourceSpan()),
+				Attributes: map[string]output.OutputExpression{
+					"tabindex": output.NewLiteralExpr("0", output.InferredType, nil),
+				},
+				Events: parser.CreateDirectiveHostEventAsts(template_parser.HostListeners{
+					"click": "onClick()",
+				}, hostSourceSpan()),
+			},
+			parser,
+			constant.NewConstantPool(false),
+		)
+
+		expected := []struct {
+			kind ir.BindingKind
+			name string
+			text bool
+		}{
+			{ir.BindingKindAttribute, "role", false},
+			{ir.BindingKindProperty, "title", false},
+			{ir.BindingKindAttribute, "tabindex", true},
+		}
+		bindings := job.Root.Update.Ops()
+		if len(bindings) != len(expected) {
+			t.Fatalf("expected %d binding operations, got %d", len(expected), len(bindings))
+		}
+		for i, want := range expected {
+			binding, ok := bindings[i].(*ops_update.BindingOp)
+			if !ok {
+				t.Fatalf("expected operation %d to be a binding, got %T", i, bindings[i])
+			}
+			if binding.BindingKind != want.kind || binding.Name != want.name || binding.IsTextAttribute != want.text {
+				t.Errorf("expected binding %d to be %v %q (text attribute: %v), got %v %q (text attribute: %v)",
+					i, want.kind, want.name, want.text, binding.BindingKind, binding.Name, binding.IsTextAttribute)
+			}
+			if binding.Target != job.Root.Xref {
+				t.Errorf("expected binding %d to target the host, got %d", i, binding.Target)
+			}
+		}
+
+		listeners := job.Root.Create.Ops()
+		if len(listeners) != 1 {
+			t.Fatalf("expected 1 listener operation, got %d", len(listeners))
+		}
+		listener, ok := listeners[0].(*ops_create.ListenerOp)
+		if !ok || listener.Name != "click" || !listener.HostListener {
+			t.Fatalf("expected a host listener for click, got %#v", listeners[0])
+		}
+		if len(listener.HandlerOps.Ops()) == 0 {
+			t.Errorf("expected the listener to have handler operations")
+		}
+	})
+
+	t.Run("should reach the units of the job through the embedded compilation job", func(t *testing.T) {
+		job := pipeline.IngestHostBinding(
+			&pipeline.HostBindingInput{ComponentName: "AppComponent", ComponentSelector: "app-root"},
+			view.MakeBindingParser(false),
+			constant.NewConstantPool(false),
+		)
+		if job.CompilationJob.GetRoot() != job.Root {
+			t.Errorf("expected the root of the embedded job to be the host binding unit")
+		}
+		if units := job.CompilationJob.GetUnits(); len(units) != 1 || units[0] != job.Root {
+			t.Errorf("expected the embedded job to have the host binding unit as its only unit, got %v", units)
+		}
+		if job.CompilationJob.GetFnSuffix() != job.GetFnSuffix() {
+			t.Errorf("expected the embedded job to have the function suffix %q, got %q", job.GetFnSuffix(), job.CompilationJob.GetFnSuffix())
+		}
+	})
+}
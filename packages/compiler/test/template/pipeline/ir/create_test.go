@@ -0,0 +1,51 @@
+package ir_test
+
+import (
+	"testing"
+
+	"ngc-go/packages/compiler/src/template/pipeline/ir"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/operations"
+	ops_create "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/create"
+	ops_update "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/update"
+)
+
+// handlerOps returns update operations for a handler, each advancing by its position.
+func handlerOps() []operations.UpdateOp {
+	return []operations.UpdateOp{ops_update.NewAdvanceOp(1), ops_update.NewAdvanceOp(2)}
+}
+
+// expectHandlerOps checks that a handler list holds the operations created by handlerOps.
+func expectHandlerOps(t *testing.T, list *operations.OpList) {
+	t.Helper()
+	ops := list.Ops()
+	if len(ops) != 2 {
+		t.Fatalf("expected 2 handler operations, got %d", len(ops))
+	}
+	for i, op := range ops {
+		if advance, ok := op.(*ops_update.AdvanceOp); !ok || advance.AdvanceBy != i+1 {
+			t.Errorf("expected handler operation %d to advance by %d, got %#v", i, i+1, op)
+		}
+	}
+}
+
+func TestListenerOps(t *testing.T) {
+	t.Run("should keep the handler operations of a listener", func(t *testing.T) {
+		op := ops_create.NewListenerOp(0, ir.NewSlotHandle(), "click", nil, handlerOps(), nil, nil, false, nil)
+		expectHandlerOps(t, op.HandlerOps)
+	})
+
+	t.Run("should keep the handler operations of a two-way listener", func(t *testing.T) {
+		op := ops_create.NewTwoWayListenerOp(0, ir.NewSlotHandle(), "valueChange", nil, handlerOps(), nil)
+		expectHandlerOps(t, op.HandlerOps)
+	})
+
+	t.Run("should keep the callback operations of an animation", func(t *testing.T) {
+		op := ops_create.NewAnimationOp("fade", 0, ir.AnimationKindEnter, handlerOps(), nil, nil)
+		expectHandlerOps(t, op.HandlerOps)
+	})
+
+	t.Run("should keep the handler operations of an animation listener", func(t *testing.T) {
+		op := ops_create.NewAnimationListenerOp(0, ir.NewSlotHandle(), "animate.enter", nil, handlerOps(), ir.AnimationKindEnter, nil, false, nil)
+		expectHandlerOps(t, op.HandlerOps)
+	})
+}
@@ -0,0 +1,59 @@
+package ir_test
+
+import (
+	"testing"
+
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/template/pipeline/ir"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/expression"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/operations"
+	ops_update "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/update"
+)
+
+// renameReads renames the variables read by an expression to their name with a suffix.
+func renameReads(expr output.OutputExpression, flags expression.VisitorContextFlag) output.OutputExpression {
+	if read, ok := expr.(*output.ReadVarExpr); ok {
+		return output.NewReadVarExpr(read.Name+"_renamed", nil, nil)
+	}
+	return expr
+}
+
+func read(name string) output.OutputExpression {
+	return output.NewReadVarExpr(name, nil, nil)
+}
+
+func TestTransformExpressionsInOp(t *testing.T) {
+	t.Run("should transform the expressions and sanitizers of bindings", func(t *testing.T) {
+		styleProp := ops_update.NewStylePropOp(0, "width", read("width"), nil)
+		classProp := ops_update.NewClassPropOp(0, "active", read("active"))
+		styleMap := ops_update.NewStyleMapOp(0, read("styles"))
+		classMap := ops_update.NewClassMapOp(0, read("classes"))
+		domProperty := ops_update.NewDomPropertyOp(0, "title", read("title"), ir.BindingKindProperty, read("sanitizer"))
+		attribute := ops_update.NewAttributeOp(0, nil, "role", read("role"), read("sanitizer"), false, false, nil)
+
+		for _, op := range []operations.Op{styleProp, classProp, styleMap, classMap, domProperty, attribute} {
+			expression.TransformExpressionsInOp(op, renameReads, expression.VisitorContextFlagNone)
+		}
+
+		for name, value := range map[string]interface{}{
+			"width":   styleProp.Expression,
+			"active":  classProp.Expression,
+			"styles":  styleMap.Expression,
+			"classes": classMap.Expression,
+			"title":   domProperty.Expression,
+			"role":    attribute.Expression,
+		} {
+			if read, ok := value.(*output.ReadVarExpr); !ok || read.Name != name+"_renamed" {
+				t.Errorf("expected the read of %s to be transformed, got %#v", name, value)
+			}
+		}
+		for name, sanitizer := range map[string]output.OutputExpression{
+			"dom property": domProperty.Sanitizer,
+			"attribute":    attribute.Sanitizer,
+		} {
+			if read, ok := sanitizer.(*output.ReadVarExpr); !ok || read.Name != "sanitizer_renamed" {
+				t.Errorf("expected the sanitizer of the %s to be transformed, got %#v", name, sanitizer)
+			}
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package ir_test
+
+import (
+	"testing"
+
+	"ngc-go/packages/compiler/src/template/pipeline/ir"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/operations"
+	ops_update "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/update"
+)
+
+func TestOpList(t *testing.T) {
+	t.Run("should return the first and last operations from Head and Tail", func(t *testing.T) {
+		list := operations.NewOpList()
+		if list.Head().GetKind() != ir.OpKindListEnd || list.Tail().GetKind() != ir.OpKindListEnd {
+			t.Fatalf("expected Head and Tail of an empty list to be list ends")
+		}
+		first := ops_update.NewAdvanceOp(1)
+		last := ops_update.NewAdvanceOp(2)
+		list.Push(first)
+		list.Push(last)
+		if list.Head() != first {
+			t.Errorf("expected Head to return the first operation, got %#v", list.Head())
+		}
+		if list.Tail() != last {
+			t.Errorf("expected Tail to return the last operation, got %#v", list.Tail())
+		}
+	})
+
+	t.Run("should iterate the operations without the list ends", func(t *testing.T) {
+		list := operations.NewOpList()
+		if len(list.Ops()) != 0 {
+			t.Fatalf("expected an empty list to have no operations, got %d", len(list.Ops()))
+		}
+		for i := 1; i <= 3; i++ {
+			list.Push(ops_update.NewAdvanceOp(i))
+		}
+		ops := list.Ops()
+		if len(ops) != 3 {
+			t.Fatalf("expected 3 operations, got %d", len(ops))
+		}
+		for i, op := range ops {
+			if advance, ok := op.(*ops_update.AdvanceOp); !ok || advance.AdvanceBy != i+1 {
+				t.Errorf("expected operation %d to advance by %d, got %#v", i, i+1, op)
+			}
+		}
+	})
+
+	t.Run("should allow removing and replacing operations while iterating", func(t *testing.T) {
+		list := operations.NewOpList()
+		for i := 1; i <= 4; i++ {
+			list.Push(ops_update.NewAdvanceOp(i))
+		}
+		for _, op := range list.Ops() {
+			switch op.(*ops_update.AdvanceOp).AdvanceBy {
+			case 2:
+				list.Remove(op)
+			case 3:
+				list.Replace(op, ops_update.NewAdvanceOp(30))
+			}
+		}
+		deltas := []int{}
+		for _, op := range list.Ops() {
+			deltas = append(deltas, op.(*ops_update.AdvanceOp).AdvanceBy)
+		}
+		if len(deltas) != 3 || deltas[0] != 1 || deltas[1] != 30 || deltas[2] != 4 {
+			t.Errorf("expected deltas [1 30 4], got %v", deltas)
+		}
+	})
+}
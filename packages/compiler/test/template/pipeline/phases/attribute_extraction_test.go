@@ -0,0 +1,29 @@
+package phases_test
+
+import (
+	"testing"
+
+	"ngc-go/packages/compiler/src/template/pipeline/ir"
+	ops_create "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/create"
+	"ngc-go/packages/compiler/src/template/pipeline/src/phases"
+)
+
+func TestExtractAttributes(t *testing.T) {
+	t.Run("should extract the event names of listeners before their element", func(t *testing.T) {
+		job := ingestComponent(t, `<button (click)="onClick()"></button>`)
+
+		phases.ExtractAttributes(job.CompilationJob)
+
+		ops := job.Root.Create.Ops()
+		if len(ops) < 2 {
+			t.Fatalf("expected at least 2 create operations, got %d", len(ops))
+		}
+		extracted, ok := ops[0].(*ops_create.ExtractedAttributeOp)
+		if !ok || extracted.Name != "click" || extracted.BindingKind != ir.BindingKindProperty {
+			t.Fatalf("expected the click event to be extracted first, got %#v", ops[0])
+		}
+		if ops[1].GetKind() != ir.OpKindElementStart {
+			t.Errorf("expected the extracted attribute to precede the element, got %v", ops[1].GetKind())
+		}
+	})
+}
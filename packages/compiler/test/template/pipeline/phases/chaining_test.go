@@ -0,0 +1,41 @@
+package phases_test
+
+import (
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3/r3_identifiers"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/shared"
+	"ngc-go/packages/compiler/src/template/pipeline/src/phases"
+)
+
+func TestChain(t *testing.T) {
+	t.Run("should chain consecutive calls of the same instruction into the first one", func(t *testing.T) {
+		job := newHostJob()
+		for i, tag := range []string{"a", "b", "c"} {
+			job.Root.Create.Push(shared.NewStatementOp(output.NewExpressionStatement(
+				output.NewInvokeFunctionExpr(
+					output.NewExternalExpr(r3_identifiers.Element, nil, nil, nil),
+					[]output.OutputExpression{
+						output.NewLiteralExpr(i, output.InferredType, nil),
+						output.NewLiteralExpr(tag, output.InferredType, nil),
+					},
+					nil, nil, false,
+				),
+				nil, nil,
+			)))
+		}
+
+		phases.Chain(job.CompilationJob)
+
+		ops := job.Root.Create.Ops()
+		if len(ops) != 1 {
+			t.Fatalf("expected the calls to be chained into 1 operation, got %d", len(ops))
+		}
+		source := emitStatements([]output.OutputStatement{ops[0].(*shared.StatementOp).Statement})
+		if expected := `i0.ɵɵelement(0,'a')(1,'b')(2,'c');`; !strings.Contains(source, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, source)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package phases_test
+
+import (
+	"strings"
+	"testing"
+
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/shared"
+	"ngc-go/packages/compiler/src/template/pipeline/src/compilation"
+	"ngc-go/packages/compiler/src/template/pipeline/src/phases"
+)
+
+// emitCreateStatements emits the reified create operations of the root view of a component.
+func emitCreateStatements(job *compilation.ComponentCompilationJob) string {
+	statements := []output.OutputStatement{}
+	for _, op := range job.Root.Create.Ops() {
+		if statementOp, ok := op.(*shared.StatementOp); ok {
+			statements = append(statements, statementOp.Statement)
+		}
+	}
+	return emitStatements(statements)
+}
+
+func TestCollectElementConsts(t *testing.T) {
+	t.Run("should pass the attributes of an element at the first const index", func(t *testing.T) {
+		job := ingestComponent(t, `<div title="a"><span title="b"></span></div>`)
+		phases.SpecializeBindings(job.CompilationJob)
+		phases.ExtractAttributes(job.CompilationJob)
+		phases.CollectElementConsts(job.CompilationJob)
+		phases.AllocateSlots(job)
+		phases.Reify(job.CompilationJob)
+
+		source := emitCreateStatements(job)
+		for _, expected := range []string{`i0.ɵɵelementStart(0,'div',0);`, `i0.ɵɵelementStart(1,'span',1);`} {
+			if !strings.Contains(source, expected) {
+				t.Errorf("expected output to contain %q, got:\n%s", expected, source)
+			}
+		}
+	})
+	t.Run("should pass the local references of an element at the first const index", func(t *testing.T) {
+		job := ingestComponent(t, `<div #ref></div>`)
+		phases.LiftLocalRefs(job)
+		phases.AllocateSlots(job)
+		phases.Reify(job.CompilationJob)
+
+		source := emitCreateStatements(job)
+		if expected := `i0.ɵɵelementStart(0,'div',null,0);`; !strings.Contains(source, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, source)
+		}
+	})
+}
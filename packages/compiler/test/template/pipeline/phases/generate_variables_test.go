@@ -0,0 +1,54 @@
+package phases_test
+
+import (
+	"reflect"
+	"testing"
+
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/shared"
+	ir_variable "ngc-go/packages/compiler/src/template/pipeline/ir/src/variable"
+	"ngc-go/packages/compiler/src/template/pipeline/src/phases"
+)
+
+func TestGenerateVariables(t *testing.T) {
+	// Maps have no iteration order, so a single compilation could pass by chance.
+	const runs = 20
+
+	t.Run("should declare the context variables of a view in the order of their names", func(t *testing.T) {
+		expected := []string{"alpha", "mid", "zeta"}
+		for i := 0; i < runs; i++ {
+			job := ingestComponent(t, `<ng-template let-zeta let-alpha="a" let-mid="m">{{zeta}}{{alpha}}{{mid}}</ng-template>`)
+			phases.GenerateVariables(job)
+
+			units := job.GetUnits()
+			if len(units) != 2 {
+				t.Fatalf("expected 2 views, got %d", len(units))
+			}
+			names := []string{}
+			for _, op := range units[1].GetUpdate().Ops() {
+				if variableOp, ok := op.(*shared.VariableOp); ok {
+					if identifier, ok := variableOp.Variable.(*ir_variable.IdentifierVariable); ok {
+						names = append(names, identifier.Identifier)
+					}
+				}
+			}
+			if !reflect.DeepEqual(names, expected) {
+				t.Fatalf("expected variables %v, got %v", expected, names)
+			}
+		}
+	})
+
+	t.Run("should return the views of a component in the order they were allocated", func(t *testing.T) {
+		for i := 0; i < runs; i++ {
+			job := ingestComponent(t, `<ng-template></ng-template><ng-template></ng-template><ng-template></ng-template><ng-template></ng-template>`)
+			units := job.GetUnits()
+			if len(units) != 5 || units[0] != job.Root {
+				t.Fatalf("expected the root view followed by 4 views, got %v", units)
+			}
+			for j := 1; j < len(units); j++ {
+				if units[j-1].GetXref() >= units[j].GetXref() {
+					t.Fatalf("expected the views in the order of their xrefs, got %d before %d", units[j-1].GetXref(), units[j].GetXref())
+				}
+			}
+		}
+	})
+}
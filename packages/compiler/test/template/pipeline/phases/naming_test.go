@@ -0,0 +1,51 @@
+package phases_test
+
+import (
+	"testing"
+
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/template/pipeline/ir"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/expression"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/operations"
+	ops_create "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/create"
+	"ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/shared"
+	ir_variable "ngc-go/packages/compiler/src/template/pipeline/ir/src/variable"
+	"ngc-go/packages/compiler/src/template/pipeline/src/phases"
+)
+
+func TestNameFunctionsAndVariables(t *testing.T) {
+	t.Run("should name the host binding function and its listeners", func(t *testing.T) {
+		job := newHostJob()
+		listener := ops_create.NewListenerOp(job.Root.Xref, ir.NewSlotHandle(), "click", nil, nil, nil, nil, true, nil)
+		job.Root.Create.Push(listener)
+
+		phases.NameFunctionsAndVariables(job.CompilationJob)
+
+		if job.Root.FnName == nil || *job.Root.FnName != "AppComponent_HostBindings" {
+			t.Errorf("expected the host binding function to be named AppComponent_HostBindings, got %v", job.Root.FnName)
+		}
+		if listener.HandlerFnName == nil || *listener.HandlerFnName != "AppComponent_click_HostBindingHandler" {
+			t.Errorf("expected the listener to be named AppComponent_click_HostBindingHandler, got %v", listener.HandlerFnName)
+		}
+	})
+
+	t.Run("should name the variables declared in listener handlers", func(t *testing.T) {
+		job := newHostJob()
+		xref := job.AllocateXrefId()
+		variable := ir_variable.NewIdentifierVariable("value", false)
+		read := expression.NewReadVariableExpr(xref)
+		job.Root.Create.Push(ops_create.NewListenerOp(job.Root.Xref, ir.NewSlotHandle(), "click", nil, []operations.UpdateOp{
+			shared.NewVariableOp(xref, variable, output.NewLiteralExpr(1, output.InferredType, nil), ir.VariableFlagsNone),
+			shared.NewStatementOp(output.NewExpressionStatement(read, nil, nil)),
+		}, nil, nil, true, nil))
+
+		phases.NameFunctionsAndVariables(job.CompilationJob)
+
+		if variable.GetName() == nil {
+			t.Fatalf("expected the variable to be named")
+		}
+		if read.Name == nil || *read.Name != *variable.GetName() {
+			t.Errorf("expected the read of the variable to use the name %q, got %v", *variable.GetName(), read.Name)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package phases_test
+
+import (
+	"regexp"
+	"testing"
+
+	"ngc-go/packages/compiler/src/constant"
+	"ngc-go/packages/compiler/src/output"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/view"
+	"ngc-go/packages/compiler/src/template/pipeline"
+	"ngc-go/packages/compiler/src/template/pipeline/src/compilation"
+)
+
+// ingestComponent ingests a template into a component compilation job.
+func ingestComponent(t *testing.T, template string) *compilation.ComponentCompilationJob {
+	t.Helper()
+	parsed := view.ParseTemplate(template, "app.component.html", nil)
+	if len(parsed.Errors) > 0 {
+		t.Fatalf("unexpected template errors: %v", parsed.Errors)
+	}
+	return pipeline.IngestComponent("AppComponent", parsed.Nodes, constant.NewConstantPool(false),
+		compilation.TemplateCompilationModeFull, "app.component.ts", false,
+		view.R3ComponentDeferMetadata{Mode: view.DeferBlockDepsEmitModePerBlock, Blocks: map[*render3.DeferredBlock]*output.OutputExpression{}},
+		nil, nil, false)
+}
+
+// newHostJob returns a host binding compilation job without any binding.
+func newHostJob() *compilation.HostBindingCompilationJob {
+	return pipeline.IngestHostBinding(
+		&pipeline.HostBindingInput{ComponentName: "AppComponent", ComponentSelector: "app-root"},
+		view.MakeBindingParser(false),
+		constant.NewConstantPool(false),
+	)
+}
+
+// emitStatements emits statements as JavaScript, without the line breaks of long lines.
+func emitStatements(statements []output.OutputStatement) string {
+	source := output.JavaScriptEmitter{}.EmitStatements("app.component.js", statements, "")
+	return regexp.MustCompile(`\n +`).ReplaceAllString(source, "")
+}
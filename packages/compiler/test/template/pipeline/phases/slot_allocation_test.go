@@ -0,0 +1,32 @@
+package phases_test
+
+import (
+	"reflect"
+	"testing"
+
+	ops_create "ngc-go/packages/compiler/src/template/pipeline/ir/src/ops/create"
+	"ngc-go/packages/compiler/src/template/pipeline/src/phases"
+)
+
+func TestAllocateSlots(t *testing.T) {
+	t.Run("should give each declaration its own slot", func(t *testing.T) {
+		job := ingestComponent(t, `<div><span></span><p></p></div>`)
+		phases.AllocateSlots(job)
+
+		slots := []int{}
+		for _, op := range job.Root.Create.Ops() {
+			if elementStart, ok := op.(*ops_create.ElementStartOp); ok {
+				slots = append(slots, *elementStart.Handle.Slot)
+			}
+			if element, ok := op.(*ops_create.ElementOp); ok {
+				slots = append(slots, *element.Handle.Slot)
+			}
+		}
+		if expected := []int{0, 1, 2}; !reflect.DeepEqual(slots, expected) {
+			t.Errorf("expected slots %v, got %v", expected, slots)
+		}
+		if job.Root.Decls == nil || *job.Root.Decls != 3 {
+			t.Errorf("expected the root view to declare 3 slots, got %v", job.Root.Decls)
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package phases_test
+
+import (
+	"testing"
+
+	"ngc-go/packages/compiler/src/template/pipeline/src/phases"
+)
+
+func TestCountVariables(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		vars     int
+	}{
+		{"properties", `<div [title]="a" [id]="b"></div>`, 2},
+		{"attributes", `<div [attr.role]="a"></div>`, 1},
+		{"class and style bindings", `<div [class.active]="a" [style.width]="b"></div>`, 4},
+		{"two-way bindings", `<input [(ngModel)]="value">`, 1},
+		{"repeaters", `@for (item of items; track item) {}`, 0},
+	}
+	for _, c := range cases {
+		t.Run("should count the variables of "+c.name, func(t *testing.T) {
+			job := ingestComponent(t, c.template)
+			phases.SpecializeStyleBindings(job.CompilationJob)
+			phases.SpecializeBindings(job.CompilationJob)
+			phases.CountVariables(job.CompilationJob)
+			if job.Root.Vars == nil || *job.Root.Vars != c.vars {
+				t.Errorf("expected %d variables, got %d", c.vars, *job.Root.Vars)
+			}
+		})
+	}
+}
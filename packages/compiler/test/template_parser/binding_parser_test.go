@@ -0,0 +1,63 @@
+package template_parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"ngc-go/packages/compiler/src/render3/view"
+	"ngc-go/packages/compiler/src/template_parser"
+	"ngc-go/packages/compiler/src/util"
+)
+
+func hostSourceSpan() *util.ParseSourceSpan {
+	file := util.NewParseSourceFile("", "host.ts")
+	location := util.NewParseLocation(file, 0, 0, 0)
+	return util.NewParseSourceSpan(location, location, location, nil)
+}
+
+func TestBindingParser_HostBindings(t *testing.T) {
+	// Maps have no iteration order, so a single parse could pass by chance.
+	const runs = 20
+
+	t.Run("should parse host properties in the order of their names", func(t *testing.T) {
+		properties := template_parser.HostProperties{
+			"title":         "title",
+			"attr.role":     "role",
+			"class.active":  "active",
+			"style.width":   "width",
+			"id":            "id",
+			"attr.tabindex": "tabIndex",
+		}
+		expected := []string{"attr.role", "attr.tabindex", "class.active", "id", "style.width", "title"}
+		for i := 0; i < runs; i++ {
+			parser := view.MakeBindingParser(false)
+			names := []string{}
+			for _, property := range parser.CreateBoundHostProperties(properties, hostSourceSpan()) {
+				names = append(names, property.Name)
+			}
+			if !reflect.DeepEqual(names, expected) {
+				t.Fatalf("expected properties %v, got %v", expected, names)
+			}
+		}
+	})
+
+	t.Run("should parse host listeners in the order of their events", func(t *testing.T) {
+		listeners := template_parser.HostListeners{
+			"keydown": "onKeydown()",
+			"click":   "onClick()",
+			"focus":   "onFocus()",
+			"blur":    "onBlur()",
+		}
+		expected := []string{"blur", "click", "focus", "keydown"}
+		for i := 0; i < runs; i++ {
+			parser := view.MakeBindingParser(false)
+			names := []string{}
+			for _, event := range parser.CreateDirectiveHostEventAsts(listeners, hostSourceSpan()) {
+				names = append(names, event.Name)
+			}
+			if !reflect.DeepEqual(names, expected) {
+				t.Fatalf("expected listeners %v, got %v", expected, names)
+			}
+		}
+	})
+}
@@ -0,0 +1,262 @@
+package languageservice
+
+import (
+	"sort"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler/src/css"
+	"ngc-go/packages/compiler/src/schema"
+)
+
+// CompletionKind is the kind of what a Completion inserts.
+type CompletionKind int
+
+const (
+	CompletionElement CompletionKind = iota
+	CompletionComponent
+	CompletionAttribute
+	CompletionProperty
+	CompletionEvent
+	CompletionBlock
+)
+
+// Completion is what can be typed at an offset of a template.
+type Completion struct {
+	Label  string
+	Kind   CompletionKind
+	Detail string
+	// Replace is what was already typed of the completion, which it replaces.
+	Replace Span
+}
+
+// blockKeywords are the names of the blocks, completed after `@`.
+var blockKeywords = []string{"if", "else", "for", "empty", "switch", "case", "default", "defer", "placeholder", "loading", "error", "let"}
+
+// completionContext is where an offset is in the markup of a template.
+type completionContext int
+
+const (
+	contextText completionContext = iota
+	contextTagName
+	contextAttributes
+	contextOther
+)
+
+// Completions returns what can be typed at an offset of a template of a file: elements after
+// `<`, attributes, property bindings and event bindings in start tags, and blocks after `@`.
+// Elements and bindings include those of the directives in scope. Clients filter them with what
+// was typed already.
+func (s *LanguageService) Completions(fileName string, offset int) []*Completion {
+	t := s.templateAt(fileName, offset)
+	if t == nil {
+		return nil
+	}
+	text, _ := s.Text(fileName)
+	context, tagName, start := scanMarkup(text, t.template.Start, offset)
+	replace := Span{FileName: t.template.TemplateURL, Start: start, End: offset}
+
+	var completions []*Completion
+	add := func(label string, kind CompletionKind, detail string) {
+		completions = append(completions, &Completion{Label: label, Kind: kind, Detail: detail, Replace: replace})
+	}
+	switch context {
+	case contextText:
+		at := offset
+		for at > t.template.Start && isNameChar(text[at-1]) {
+			at--
+		}
+		if at == t.template.Start || text[at-1] != '@' {
+			return nil
+		}
+		replace.Start = at
+		for _, keyword := range blockKeywords {
+			add(keyword, CompletionBlock, "@"+keyword+" block")
+		}
+	case contextTagName:
+		seen := make(map[string]bool)
+		for _, dir := range t.template.Directives {
+			for _, element := range selectorElements(dir) {
+				if !seen[element] {
+					seen[element] = true
+					add(element, CompletionComponent, dir.Name)
+				}
+			}
+		}
+		for _, name := range domElements(s.registry) {
+			if !seen[name] {
+				add(name, CompletionElement, "DOM element")
+			}
+		}
+	case contextAttributes:
+		typed := text[start:offset]
+		if strings.Contains(typed, "=") {
+			return nil
+		}
+		dirs := applicableDirectives(t.template, tagName)
+		switch {
+		case strings.HasPrefix(typed, "("):
+			for _, dir := range dirs {
+				for _, output := range dir.Outputs {
+					add("("+output.Name+")", CompletionEvent, dir.Name+"."+output.Property)
+				}
+			}
+			for _, event := range sortedNames(s.registry.AllKnownEventsOfElement(tagName)) {
+				add("("+event+")", CompletionEvent, "DOM event")
+			}
+		case strings.HasPrefix(typed, "["):
+			for _, dir := range dirs {
+				for _, input := range dir.Inputs {
+					add("["+input.Name+"]", CompletionProperty, dir.Name+"."+input.Property)
+				}
+			}
+			for _, property := range domProperties(s.registry, tagName) {
+				add("["+property+"]", CompletionProperty, "DOM property")
+			}
+		default:
+			for _, dir := range dirs {
+				for _, attr := range selectorAttributes(dir) {
+					add(attr, CompletionAttribute, dir.Name)
+				}
+				for _, input := range dir.Inputs {
+					add(input.Name, CompletionAttribute, dir.Name+"."+input.Property)
+				}
+			}
+			for _, attr := range sortedNames(s.registry.AllKnownAttributesOfElement(tagName)) {
+				add(attr, CompletionAttribute, "DOM attribute")
+			}
+		}
+	}
+	return completions
+}
+
+// scanMarkup scans a template up to an offset and returns where the offset is: in text, in the
+// name of a start tag or among its attributes, with the tag name and the start of the name
+// being typed.
+func scanMarkup(text string, from int, offset int) (context completionContext, tagName string, start int) {
+	context = contextText
+	var quote byte
+	for i := from; i < offset; i++ {
+		c := text[i]
+		switch context {
+		case contextText:
+			switch {
+			case strings.HasPrefix(text[i:], "<!--"):
+				context = contextOther
+			case c == '<' && i+1 < len(text) && text[i+1] == '/':
+				context = contextOther
+			case c == '<':
+				context, start = contextTagName, i+1
+			}
+		case contextTagName:
+			switch {
+			case c == '>':
+				context = contextText
+			case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '/':
+				context, tagName = contextAttributes, text[start:i]
+			}
+		case contextAttributes:
+			switch {
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+			case c == '"' || c == '\'':
+				quote = c
+			case c == '>':
+				context = contextText
+			}
+		case contextOther:
+			if c == '>' {
+				context = contextText
+			}
+		}
+	}
+	if context == contextAttributes {
+		if quote != 0 {
+			return contextOther, tagName, offset
+		}
+		start = offset
+		for start > from && !strings.ContainsRune(" \t\r\n/\"'", rune(text[start-1])) {
+			start--
+		}
+	}
+	return context, tagName, start
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '$' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// applicableDirectives returns the directives whose selectors can match an element with a tag
+// name, given the right attributes.
+func applicableDirectives(t *annotations.ComponentTemplate, tagName string) []*annotations.ScopeDirective {
+	var dirs []*annotations.ScopeDirective
+	for _, dir := range t.Directives {
+		selectors, err := css.ParseCssSelector(dir.Selector)
+		if err != nil {
+			continue
+		}
+		for _, selector := range selectors {
+			if selector.Element == nil || *selector.Element == "" || strings.EqualFold(*selector.Element, tagName) {
+				dirs = append(dirs, dir)
+				break
+			}
+		}
+	}
+	return dirs
+}
+
+// selectorElements returns the element names of the selector of a directive.
+func selectorElements(dir *annotations.ScopeDirective) []string {
+	selectors, _ := css.ParseCssSelector(dir.Selector)
+	var names []string
+	for _, selector := range selectors {
+		if selector.Element != nil && *selector.Element != "" {
+			names = append(names, *selector.Element)
+		}
+	}
+	return names
+}
+
+// selectorAttributes returns the attribute names of the selector of a directive.
+func selectorAttributes(dir *annotations.ScopeDirective) []string {
+	selectors, _ := css.ParseCssSelector(dir.Selector)
+	var names []string
+	for _, selector := range selectors {
+		for i := 0; i < len(selector.Attrs); i += 2 {
+			names = append(names, selector.Attrs[i])
+		}
+	}
+	return names
+}
+
+// domElements returns the names of the elements of the DOM schema, without the namespaced ones.
+func domElements(registry *schema.DomElementSchemaRegistry) []string {
+	var names []string
+	for _, name := range registry.AllKnownElementNames() {
+		if !strings.HasPrefix(name, ":") && name != "unknown" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// domProperties returns the properties of an element of the DOM schema.
+func domProperties(registry *schema.DomElementSchemaRegistry, tagName string) []string {
+	var names []string
+	for _, attr := range registry.AllKnownAttributesOfElement(tagName) {
+		if property, ok := schema.AttrToProp[attr]; ok {
+			attr = property
+		}
+		names = append(names, attr)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedNames(names []string) []string {
+	sort.Strings(names)
+	return names
+}
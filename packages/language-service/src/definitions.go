@@ -0,0 +1,66 @@
+package languageservice
+
+import (
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// Definition returns where what is at an offset of a template of a file is declared: the
+// reference, template variable or `@let` declaration an expression reads, the component class
+// of an element, or the property of the directive input or output an attribute binds to. It
+// returns nil when there is no such declaration.
+func (s *LanguageService) Definition(fileName string, offset int) []Span {
+	t := s.templateAt(fileName, offset)
+	if t == nil {
+		return nil
+	}
+	target := findTarget(t.parsed.Nodes, offset)
+	templateSpan := func(span *util.ParseSourceSpan) []Span {
+		return []Span{{FileName: t.template.TemplateURL, Start: span.Start.Offset, End: span.End.Offset}}
+	}
+
+	switch key := target.key.(type) {
+	case *render3.TextAttribute, *render3.BoundAttribute:
+		if input, dir := findInput(t, key); input != nil {
+			return []Span{{FileName: dir.FileName, Start: input.Start, End: input.End}}
+		}
+		return nil
+	case *render3.BoundEvent:
+		if dir := t.template.Directive(t.bound.GetConsumerOfBinding(key)); dir != nil {
+			for _, output := range dir.Outputs {
+				if output.Name == key.Name {
+					return []Span{{FileName: dir.FileName, Start: output.Start, End: output.End}}
+				}
+			}
+		}
+		return nil
+	case *render3.Reference, *render3.Variable, *render3.LetDeclaration:
+		// Declarations are their own definition.
+		return nil
+	}
+
+	if read, ok := target.expr.(*expression_parser.PropertyRead); ok {
+		switch entity := t.bound.GetExpressionTarget(read).(type) {
+		case *render3.Reference:
+			return templateSpan(entity.KeySpan)
+		case *render3.Variable:
+			return templateSpan(entity.KeySpan)
+		case *render3.LetDeclaration:
+			return templateSpan(entity.NameSpan)
+		}
+		return nil
+	}
+
+	text, _ := s.Text(fileName)
+	if tagNameSpan(text, target.node, offset) != nil {
+		var spans []Span
+		for _, dir := range directivesOf(t, target.node) {
+			if dir.IsComponent {
+				spans = append(spans, Span{FileName: dir.FileName, Start: dir.Start, End: dir.End})
+			}
+		}
+		return spans
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// Package languageservice answers the questions editors ask about the templates of an Angular
+// project: their diagnostics, what is under the cursor, where it is defined and what can be
+// typed there. It works on external templates and on the inline templates of component files,
+// with the parser and binder of the compiler.
+package languageservice
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	"ngc-go/packages/compiler-cli/src/ngtsc/reflection"
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck"
+	"ngc-go/packages/compiler/src/render3/view"
+	"ngc-go/packages/compiler/src/schema"
+)
+
+// LanguageService serves the templates of the project in a directory. The documents open in
+// the editor override the files on disk. It is safe for concurrent use.
+type LanguageService struct {
+	rootPath string
+	registry *schema.DomElementSchemaRegistry
+	cache    *annotations.TemplateCache
+
+	mu        sync.Mutex
+	documents map[string]string
+	// version counts the changes of the documents.
+	version int
+	// program is the compilation of the project, nil once a document changed.
+	program *program
+}
+
+// program is an analyzed compilation of the project.
+type program struct {
+	compiler *annotations.Compiler
+	diags    []*diagnostics.Diagnostic
+}
+
+// New creates a LanguageService for the project in rootPath.
+func New(rootPath string) *LanguageService {
+	return &LanguageService{
+		rootPath:  rootPath,
+		registry:  schema.NewDomElementSchemaRegistry(),
+		cache:     annotations.NewTemplateCache(),
+		documents: make(map[string]string),
+	}
+}
+
+// Open sets the text of a document open in the editor, replacing the file on disk. It is also
+// called with the new text when the document changes.
+func (s *LanguageService) Open(fileName string, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.documents[filepath.Clean(fileName)] = text
+	s.version++
+	s.program = nil
+}
+
+// Close forgets a document, whose file on disk is used again.
+func (s *LanguageService) Close(fileName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.documents, filepath.Clean(fileName))
+	s.version++
+	s.program = nil
+}
+
+// Text returns the text of a file: the open document or the file on disk.
+func (s *LanguageService) Text(fileName string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return readFile(s.documents, filepath.Clean(fileName))
+}
+
+// readFile reads a file, or the open document replacing it.
+func readFile(documents map[string]string, fileName string) (string, bool) {
+	if text, ok := documents[fileName]; ok {
+		return text, true
+	}
+	data, err := os.ReadFile(fileName)
+	return string(data), err == nil
+}
+
+// compile returns the compilation of the project, analyzing it again after changes. The
+// analysis works on a copy of the documents, so that they can change in the meantime.
+func (s *LanguageService) compile() *program {
+	s.mu.Lock()
+	if p := s.program; p != nil {
+		s.mu.Unlock()
+		return p
+	}
+	version := s.version
+	documents := make(map[string]string, len(s.documents))
+	for name, text := range s.documents {
+		documents[name] = text
+	}
+	s.mu.Unlock()
+
+	var files []*reflection.SourceFile
+	seen := make(map[string]bool)
+	filepath.Walk(s.rootPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if info.Name() == "node_modules" || info.Name() == "dist" || (strings.HasPrefix(info.Name(), ".") && path != s.rootPath) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isSource(path) {
+			seen[path] = true
+			if text, ok := readFile(documents, path); ok {
+				files = append(files, reflection.ReflectSourceFile(path, text))
+			}
+		}
+		return nil
+	})
+	// Documents which are not saved yet are part of the project too.
+	var unsaved []string
+	for name := range documents {
+		if !seen[name] && isSource(name) && strings.HasPrefix(name, s.rootPath) {
+			unsaved = append(unsaved, name)
+		}
+	}
+	sort.Strings(unsaved)
+	for _, name := range unsaved {
+		files = append(files, reflection.ReflectSourceFile(name, documents[name]))
+	}
+
+	compiler := annotations.NewCompiler(files, annotations.Options{
+		RootDir: s.rootPath,
+		ResourceLoader: func(path string) (string, error) {
+			if text, ok := documents[filepath.Clean(path)]; ok {
+				return text, nil
+			}
+			data, err := os.ReadFile(path)
+			return string(data), err
+		},
+		TemplateCache: s.cache,
+	})
+	p := &program{compiler: compiler, diags: compiler.Analyze()}
+
+	s.mu.Lock()
+	if s.version == version {
+		s.program = p
+	}
+	s.mu.Unlock()
+	return p
+}
+
+// isSource reports whether a file is a source file of the project.
+func isSource(path string) bool {
+	return strings.HasSuffix(path, ".ts") && !strings.HasSuffix(path, ".d.ts") && !strings.HasSuffix(path, ".spec.ts")
+}
+
+// Diagnostics returns the problems of a file: those the compiler reports in it and, for the
+// templates it contains, the elements, properties and events the DOM schema and the directives
+// in scope do not know about.
+func (s *LanguageService) Diagnostics(fileName string) []*diagnostics.Diagnostic {
+	fileName = filepath.Clean(fileName)
+	p := s.compile()
+	var diags []*diagnostics.Diagnostic
+	for _, diag := range p.diags {
+		if filepath.Clean(diag.File) == fileName {
+			diags = append(diags, diag)
+		}
+	}
+	for _, t := range s.templates(p, fileName) {
+		if len(t.parsed.Errors) > 0 || !t.template.Complete {
+			// The compiler reports the parse errors, and the scopes importing NgModules outside
+			// of the program have unknown directives.
+			continue
+		}
+		diags = append(diags, typecheck.CheckTemplate(t.bound, &typecheck.TemplateCheckOptions{
+			Schemas:          t.template.Schemas,
+			HostIsStandalone: t.template.Standalone,
+			Registry:         s.registry,
+		})...)
+	}
+	diagnostics.Sort(diags)
+	return diags
+}
+
+// boundTemplate is a template of a file, parsed and bound.
+type boundTemplate struct {
+	template *annotations.ComponentTemplate
+	parsed   *view.ParsedTemplate
+	bound    view.BoundTarget
+}
+
+// templates returns the templates in a file.
+func (s *LanguageService) templates(p *program, fileName string) []*boundTemplate {
+	var templates []*boundTemplate
+	for _, t := range p.compiler.Templates(fileName) {
+		if filepath.Clean(t.TemplateURL) != fileName {
+			continue
+		}
+		parsed := t.Parse()
+		templates = append(templates, &boundTemplate{template: t, parsed: parsed, bound: t.Bind(parsed.Nodes)})
+	}
+	return templates
+}
+
+// templateAt returns the template of a file containing an offset.
+func (s *LanguageService) templateAt(fileName string, offset int) *boundTemplate {
+	for _, t := range s.templates(s.compile(), filepath.Clean(fileName)) {
+		if t.template.Start <= offset && offset <= t.template.End {
+			return t
+		}
+	}
+	return nil
+}
+
+// Span is a range of offsets in a file.
+type Span struct {
+	FileName   string
+	Start, End int
+}
@@ -0,0 +1,299 @@
+// Package lsp serves the language service over the Language Server Protocol: JSON-RPC messages
+// framed by Content-Length headers, as editors exchange them with language servers over stdio.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/diagnostics"
+	languageservice "ngc-go/packages/language-service/src"
+)
+
+// codeServerNotInitialized answers the requests received before `initialize`.
+const codeServerNotInitialized = -32002
+
+// errExit stops Serve on the `exit` notification.
+var errExit = errors.New("exit")
+
+// Server is a language server for the templates of a project. Documents are synchronized in
+// full: every change sends the whole text.
+type Server struct {
+	rootPath string
+	service  *languageservice.LanguageService
+	out      io.Writer
+	// open are the names of the open documents.
+	open map[string]bool
+}
+
+// NewServer creates a Server for the project in rootPath, or in the root the client sends
+// with `initialize` when rootPath is empty.
+func NewServer(rootPath string) *Server {
+	return &Server{rootPath: rootPath, open: make(map[string]bool)}
+}
+
+// Serve handles the messages of in and writes the responses and notifications to out, until
+// the `exit` notification or the end of in.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	r := bufio.NewReader(in)
+	for {
+		body, err := readMessage(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.respond(nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.handle(&req); err == errExit {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// handle handles a request or notification. Only the errors writing out are returned.
+func (s *Server) handle(req *request) error {
+	if req.Method == "exit" {
+		return errExit
+	}
+	if s.service == nil && req.Method != "initialize" {
+		if req.ID == nil {
+			return nil
+		}
+		return s.respond(req.ID, nil, &responseError{Code: codeServerNotInitialized, Message: "the server is not initialized"})
+	}
+
+	var result interface{}
+	var params interface{}
+	switch req.Method {
+	case "initialize":
+		params = &initializeParams{}
+	case "textDocument/didOpen":
+		params = &didOpenParams{}
+	case "textDocument/didChange":
+		params = &didChangeParams{}
+	case "textDocument/didClose":
+		params = &didCloseParams{}
+	case "textDocument/hover", "textDocument/definition", "textDocument/completion":
+		params = &textDocumentPositionParams{}
+	case "shutdown":
+	default:
+		if req.ID == nil {
+			// Notifications such as `initialized` and `$/cancelRequest` need no answer.
+			return nil
+		}
+		return s.respond(req.ID, nil, &responseError{Code: codeMethodNotFound, Message: "unsupported method " + req.Method})
+	}
+	if params != nil && len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, params); err != nil {
+			if req.ID == nil {
+				return nil
+			}
+			return s.respond(req.ID, nil, &responseError{Code: codeInvalidParams, Message: err.Error()})
+		}
+	}
+
+	switch p := params.(type) {
+	case *initializeParams:
+		result = s.initialize(p)
+	case *didOpenParams:
+		fileName := fileNameOf(p.TextDocument.URI)
+		s.service.Open(fileName, p.TextDocument.Text)
+		s.open[fileName] = true
+		return s.publishDiagnostics()
+	case *didChangeParams:
+		if len(p.ContentChanges) == 0 {
+			return nil
+		}
+		fileName := fileNameOf(p.TextDocument.URI)
+		s.service.Open(fileName, p.ContentChanges[len(p.ContentChanges)-1].Text)
+		s.open[fileName] = true
+		return s.publishDiagnostics()
+	case *didCloseParams:
+		fileName := fileNameOf(p.TextDocument.URI)
+		s.service.Close(fileName)
+		delete(s.open, fileName)
+		// The problems of closed documents are no longer shown.
+		if err := s.notify("textDocument/publishDiagnostics", &publishDiagnosticsParams{URI: p.TextDocument.URI, Diagnostics: []diagnostic{}}); err != nil {
+			return err
+		}
+		return s.publishDiagnostics()
+	case *textDocumentPositionParams:
+		fileName := fileNameOf(p.TextDocument.URI)
+		text, _ := s.service.Text(fileName)
+		offset := offsetAt(text, p.Position)
+		switch req.Method {
+		case "textDocument/hover":
+			result = s.hover(fileName, text, offset)
+		case "textDocument/definition":
+			result = s.definition(fileName, offset)
+		case "textDocument/completion":
+			result = s.completion(fileName, text, offset)
+		}
+	}
+	if req.ID == nil {
+		return nil
+	}
+	return s.respond(req.ID, result, nil)
+}
+
+// initialize creates the language service and returns the capabilities of the server.
+func (s *Server) initialize(p *initializeParams) interface{} {
+	if s.rootPath == "" {
+		s.rootPath = p.RootPath
+		if p.RootURI != "" {
+			s.rootPath = fileNameOf(p.RootURI)
+		}
+	}
+	if rootPath, err := filepath.Abs(s.rootPath); err == nil {
+		s.rootPath = rootPath
+	}
+	s.service = languageservice.New(s.rootPath)
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			// Documents are synchronized in full.
+			"textDocumentSync":   1,
+			"hoverProvider":      true,
+			"definitionProvider": true,
+			"completionProvider": map[string]interface{}{
+				"triggerCharacters": []string{"<", "@", "[", "(", " "},
+			},
+		},
+		"serverInfo": map[string]interface{}{"name": "ngc-go"},
+	}
+}
+
+// publishDiagnostics sends the diagnostics of all open documents, as a change in one of them
+// can affect the others.
+func (s *Server) publishDiagnostics() error {
+	names := make([]string, 0, len(s.open))
+	for name := range s.open {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		text, _ := s.service.Text(name)
+		params := &publishDiagnosticsParams{URI: uriOf(name), Diagnostics: []diagnostic{}}
+		for _, diag := range s.service.Diagnostics(name) {
+			var r textRange
+			if diag.Span != nil && diag.Span.Start != nil && diag.Span.End != nil {
+				r = textRange{Start: positionAt(text, diag.Span.Start.Offset), End: positionAt(text, diag.Span.End.Offset)}
+			}
+			params.Diagnostics = append(params.Diagnostics, diagnostic{
+				Range:    r,
+				Severity: severity(diag.Category),
+				Code:     diag.DisplayCode(),
+				Source:   "ngc-go",
+				Message:  diag.Message,
+			})
+		}
+		if err := s.notify("textDocument/publishDiagnostics", params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// severity maps the category of a diagnostic to its LSP severity.
+func severity(category diagnostics.Category) int {
+	switch category {
+	case diagnostics.CategoryError:
+		return 1
+	case diagnostics.CategoryWarning:
+		return 2
+	case diagnostics.CategoryMessage:
+		return 3
+	}
+	return 4
+}
+
+func (s *Server) hover(fileName string, text string, offset int) interface{} {
+	info := s.service.QuickInfo(fileName, offset)
+	if info == nil {
+		return nil
+	}
+	return &hover{
+		Contents: markupContent{Kind: "markdown", Value: info.Text},
+		Range:    textRange{Start: positionAt(text, info.Span.Start), End: positionAt(text, info.Span.End)},
+	}
+}
+
+func (s *Server) definition(fileName string, offset int) interface{} {
+	locations := []location{}
+	for _, span := range s.service.Definition(fileName, offset) {
+		text, _ := s.service.Text(span.FileName)
+		locations = append(locations, location{
+			URI:   uriOf(span.FileName),
+			Range: textRange{Start: positionAt(text, span.Start), End: positionAt(text, span.End)},
+		})
+	}
+	return locations
+}
+
+// completionKinds maps the kinds of completions to LSP completion item kinds.
+var completionKinds = map[languageservice.CompletionKind]int{
+	languageservice.CompletionElement:   12, // Value
+	languageservice.CompletionComponent: 7,  // Class
+	languageservice.CompletionAttribute: 5,  // Field
+	languageservice.CompletionProperty:  10, // Property
+	languageservice.CompletionEvent:     23, // Event
+	languageservice.CompletionBlock:     14, // Keyword
+}
+
+func (s *Server) completion(fileName string, text string, offset int) interface{} {
+	list := &completionList{Items: []completionItem{}}
+	for _, completion := range s.service.Completions(fileName, offset) {
+		list.Items = append(list.Items, completionItem{
+			Label:  completion.Label,
+			Kind:   completionKinds[completion.Kind],
+			Detail: completion.Detail,
+			TextEdit: textEdit{
+				Range:   textRange{Start: positionAt(text, completion.Replace.Start), End: positionAt(text, completion.Replace.End)},
+				NewText: completion.Label,
+			},
+		})
+	}
+	return list
+}
+
+func (s *Server) respond(id *json.RawMessage, result interface{}, err *responseError) error {
+	msg := map[string]interface{}{"id": id}
+	if err != nil {
+		msg["error"] = err
+	} else {
+		msg["result"] = result
+	}
+	return writeMessage(s.out, msg)
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return writeMessage(s.out, map[string]interface{}{"method": method, "params": params})
+}
+
+// fileNameOf returns the file name of a `file:` URI.
+func fileNameOf(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// uriOf returns the `file:` URI of a file name.
+func uriOf(fileName string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(fileName)}).String()
+}
@@ -0,0 +1,266 @@
+package languageservice
+
+import (
+	"fmt"
+	"strings"
+
+	"ngc-go/packages/compiler-cli/src/ngtsc/annotations"
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/render3/view"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// QuickInfo describes what is at an offset of a template, in Markdown.
+type QuickInfo struct {
+	Text string
+	// Span is the span of what is described.
+	Span Span
+}
+
+// blockDocs describes the blocks of the control flow.
+var blockDocs = map[string]string{
+	"if":          "Renders its content when the condition is truthy.",
+	"else if":     "Renders its content when the condition is truthy and the previous ones are not.",
+	"else":        "Renders its content when none of the previous conditions is truthy.",
+	"for":         "Renders its content for each item of a collection, tracked by the `track` expression.",
+	"empty":       "Renders its content when the collection of the `@for` block is empty.",
+	"switch":      "Renders the first case matching the value.",
+	"case":        "Renders its content when its value matches the one of the `@switch` block.",
+	"default":     "Renders its content when no case of the `@switch` block matches.",
+	"defer":       "Loads its content and its dependencies lazily, when its triggers fire.",
+	"placeholder": "Rendered before the content of the `@defer` block loads.",
+	"loading":     "Rendered while the content of the `@defer` block loads.",
+	"error":       "Rendered when the content of the `@defer` block fails to load.",
+}
+
+// QuickInfo returns the description of what is at an offset of a template of a file: an
+// element and the directives matching it, an attribute or binding and the input or output it
+// binds to, a reference, a variable, a `@let` declaration or a block. It returns nil elsewhere.
+func (s *LanguageService) QuickInfo(fileName string, offset int) *QuickInfo {
+	t := s.templateAt(fileName, offset)
+	if t == nil {
+		return nil
+	}
+	text, _ := s.Text(fileName)
+	target := findTarget(t.parsed.Nodes, offset)
+	info := func(span *util.ParseSourceSpan, format string, args ...interface{}) *QuickInfo {
+		return &QuickInfo{Text: fmt.Sprintf(format, args...), Span: Span{FileName: t.template.TemplateURL, Start: span.Start.Offset, End: span.End.Offset}}
+	}
+
+	if target.block != nil {
+		span := blockNameSpan(target.block)
+		name := strings.Join(strings.Fields(strings.TrimPrefix(text[span.Start.Offset:span.End.Offset], "@")), " ")
+		return info(span, "(block) `@%s`\n\n%s", name, blockDocs[name])
+	}
+	switch key := target.key.(type) {
+	case *render3.TextAttribute:
+		if input := bindingInput(t, key); input != "" {
+			return info(key.KeySpan, "%s", input)
+		}
+		return info(key.KeySpan, "(attribute) `%s`", key.Name)
+	case *render3.BoundAttribute:
+		if input := bindingInput(t, key); input != "" {
+			return info(key.KeySpan, "%s", input)
+		}
+		return info(key.KeySpan, "(property) `%s.%s`", ownerName(target.owner), key.Name)
+	case *render3.BoundEvent:
+		if dir := t.template.Directive(t.bound.GetConsumerOfBinding(key)); dir != nil {
+			for _, output := range dir.Outputs {
+				if output.Name == key.Name {
+					return info(key.KeySpan, "(output) `%s.%s`", dir.Name, output.Property)
+				}
+			}
+		}
+		return info(key.KeySpan, "(event) `%s.%s`", ownerName(target.owner), key.Name)
+	case *render3.Reference:
+		return info(key.KeySpan, "%s", describeEntity(t, key))
+	case *render3.Variable:
+		return info(key.KeySpan, "%s", describeEntity(t, key))
+	case *render3.LetDeclaration:
+		return info(key.NameSpan, "%s", describeEntity(t, key))
+	}
+
+	if read, ok := target.expr.(*expression_parser.PropertyRead); ok {
+		span := absoluteSpan(t.template.TemplateURL, read.NameSpan())
+		if entity := t.bound.GetExpressionTarget(read); entity != nil {
+			return &QuickInfo{Text: describeEntity(t, entity), Span: span}
+		}
+		if isImplicit(read.Receiver) {
+			return &QuickInfo{Text: fmt.Sprintf("(property) `%s.%s`", t.template.ClassName, read.Name), Span: span}
+		}
+		return &QuickInfo{Text: fmt.Sprintf("(property) `%s`", read.Name), Span: span}
+	}
+	if pipe, ok := target.expr.(*expression_parser.BindingPipe); ok {
+		return &QuickInfo{Text: fmt.Sprintf("(pipe) `%s`", pipe.Name), Span: absoluteSpan(t.template.TemplateURL, pipe.NameSpan())}
+	}
+
+	if span := tagNameSpan(text, target.node, offset); span != nil {
+		var lines []string
+		if element, ok := target.node.(*render3.Element); ok {
+			lines = append(lines, fmt.Sprintf("(element) `<%s>`", element.Name))
+		} else {
+			lines = append(lines, "(template) `<ng-template>`")
+		}
+		for _, dir := range directivesOf(t, target.node) {
+			kind := "directive"
+			if dir.IsComponent {
+				kind = "component"
+			}
+			lines = append(lines, fmt.Sprintf("(%s) `%s` matching `%s`", kind, dir.Name, dir.Selector))
+		}
+		return &QuickInfo{Text: strings.Join(lines, "\n\n"), Span: *span}
+	}
+	return nil
+}
+
+// bindingInput describes the directive input an attribute or property binding binds to, if any.
+func bindingInput(t *boundTemplate, binding interface{}) string {
+	input, dir := findInput(t, binding)
+	if input == nil {
+		return ""
+	}
+	description := fmt.Sprintf("(input) `%s.%s`", dir.Name, input.Property)
+	if input.Required {
+		description += " (required)"
+	}
+	return description
+}
+
+// findInput returns the directive input an attribute or property binding binds to.
+func findInput(t *boundTemplate, binding interface{}) (*annotations.ScopeBinding, *annotations.ScopeDirective) {
+	var name string
+	switch b := binding.(type) {
+	case *render3.TextAttribute:
+		name = b.Name
+	case *render3.BoundAttribute:
+		name = b.Name
+	}
+	dir := t.template.Directive(t.bound.GetConsumerOfBinding(binding))
+	if dir == nil {
+		return nil, nil
+	}
+	for _, input := range dir.Inputs {
+		if input.Name == name {
+			return input, dir
+		}
+	}
+	return nil, nil
+}
+
+// describeEntity describes a reference, a variable or a `@let` declaration.
+func describeEntity(t *boundTemplate, entity view.TemplateEntity) string {
+	switch e := entity.(type) {
+	case *render3.Reference:
+		target := "unknown"
+		switch r := t.bound.GetReferenceTarget(e).(type) {
+		case *view.ReferenceTargetElement:
+			target = fmt.Sprintf("`<%s>`", r.Element.Name)
+		case *view.ReferenceTargetTemplate:
+			target = "`TemplateRef`"
+		case *view.ReferenceTargetWithDirective:
+			if dir := t.template.Directive(r.Directive); dir != nil {
+				target = "`" + dir.Name + "`"
+			}
+		}
+		return fmt.Sprintf("(reference) `#%s`: %s", e.Name, target)
+	case *render3.Variable:
+		if e.Value != "" && e.Value != "$implicit" {
+			return fmt.Sprintf("(variable) `%s`: `%s`", e.Name, e.Value)
+		}
+		return fmt.Sprintf("(variable) `%s`", e.Name)
+	case *render3.LetDeclaration:
+		return fmt.Sprintf("(let) `@let %s`", e.Name)
+	}
+	return ""
+}
+
+// directivesOf returns the directives matching an element or template.
+func directivesOf(t *boundTemplate, node render3.Node) []*annotations.ScopeDirective {
+	if !view.IsDirectiveOwner(node) {
+		return nil
+	}
+	var dirs []*annotations.ScopeDirective
+	for _, dir := range t.bound.GetDirectivesOfNode(node) {
+		if scopeDir := t.template.Directive(dir); scopeDir != nil {
+			dirs = append(dirs, scopeDir)
+		}
+	}
+	return dirs
+}
+
+// ownerName names the element or template owning an attribute.
+func ownerName(node render3.Node) string {
+	if element, ok := node.(*render3.Element); ok {
+		return element.Name
+	}
+	return "ng-template"
+}
+
+func isImplicit(receiver expression_parser.AST) bool {
+	_, ok := receiver.(*expression_parser.ImplicitReceiver)
+	return ok
+}
+
+func absoluteSpan(fileName string, span *expression_parser.AbsoluteSourceSpan) Span {
+	return Span{FileName: fileName, Start: span.Start, End: span.End}
+}
+
+// blockNameSpan returns the span of the name of a block, after the `@`.
+func blockNameSpan(node render3.Node) *util.ParseSourceSpan {
+	switch n := node.(type) {
+	case *render3.IfBlock:
+		return n.NameSpan
+	case *render3.IfBlockBranch:
+		return n.NameSpan
+	case *render3.ForLoopBlock:
+		return n.NameSpan
+	case *render3.ForLoopBlockEmpty:
+		return n.NameSpan
+	case *render3.SwitchBlock:
+		return n.NameSpan
+	case *render3.SwitchBlockCase:
+		return n.NameSpan
+	case *render3.DeferredBlock:
+		return n.NameSpan
+	case *render3.DeferredBlockPlaceholder:
+		return n.NameSpan
+	case *render3.DeferredBlockLoading:
+		return n.NameSpan
+	case *render3.DeferredBlockError:
+		return n.NameSpan
+	}
+	return nil
+}
+
+// tagNameSpan returns the span of the tag name of an element or template in its start or end
+// tag when it contains the offset, nil otherwise.
+func tagNameSpan(text string, node render3.Node, offset int) *Span {
+	var start, end *util.ParseSourceSpan
+	switch n := node.(type) {
+	case *render3.Element:
+		start, end = n.StartSourceSpan, n.EndSourceSpan
+	case *render3.Template:
+		if n.TagName == nil {
+			return nil
+		}
+		start, end = n.StartSourceSpan, n.EndSourceSpan
+	default:
+		return nil
+	}
+	for i, tag := range []*util.ParseSourceSpan{start, end} {
+		if tag == nil || tag.Start == nil {
+			continue
+		}
+		// The name follows `<` in start tags and `</` in end tags.
+		nameStart := tag.Start.Offset + 1 + i
+		nameEnd := nameStart
+		for nameEnd < len(text) && !strings.ContainsRune(" \t\r\n/>", rune(text[nameEnd])) {
+			nameEnd++
+		}
+		if nameStart <= offset && offset <= nameEnd {
+			return &Span{FileName: tag.Start.File.URL, Start: nameStart, End: nameEnd}
+		}
+	}
+	return nil
+}
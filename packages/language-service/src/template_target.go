@@ -0,0 +1,198 @@
+package languageservice
+
+import (
+	"ngc-go/packages/compiler-cli/src/ngtsc/typecheck"
+	"ngc-go/packages/compiler/src/expression_parser"
+	"ngc-go/packages/compiler/src/render3"
+	"ngc-go/packages/compiler/src/util"
+)
+
+// templateTarget is what is at an offset of a template.
+type templateTarget struct {
+	// node is the innermost node containing the offset.
+	node render3.Node
+	// key is the attribute, binding, reference, variable or `@let` declaration whose name is at
+	// the offset, and owner the node declaring it.
+	key   render3.Node
+	owner render3.Node
+	// block is the block whose name is at the offset, e.g. `@if`.
+	block render3.Node
+	// expr is the innermost expression containing the offset.
+	expr expression_parser.AST
+}
+
+// findTarget returns what is at an offset of the nodes of a template.
+func findTarget(nodes []render3.Node, offset int) *templateTarget {
+	f := &targetFinder{offset: offset, target: &templateTarget{}}
+	f.visitAll(nodes)
+	return f.target
+}
+
+type targetFinder struct {
+	offset int
+	target *templateTarget
+}
+
+func (f *targetFinder) visitAll(nodes []render3.Node) {
+	for _, node := range nodes {
+		if node != nil && f.contains(node.SourceSpan()) {
+			f.visit(node)
+		}
+	}
+}
+
+func (f *targetFinder) visit(node render3.Node) {
+	f.target.node = node
+	switch n := node.(type) {
+	case *render3.Element:
+		f.visitOwner(n, n.Attributes, n.Inputs, n.Outputs, n.References, nil)
+		f.visitAll(n.Children)
+	case *render3.Template:
+		f.visitOwner(n, n.Attributes, n.Inputs, n.Outputs, n.References, n.Variables)
+		for _, attr := range n.TemplateAttrs {
+			switch a := attr.(type) {
+			case *render3.TextAttribute:
+				f.visitOwner(n, []*render3.TextAttribute{a}, nil, nil, nil, nil)
+			case *render3.BoundAttribute:
+				f.visitOwner(n, nil, []*render3.BoundAttribute{a}, nil, nil, nil)
+			}
+		}
+		f.visitAll(n.Children)
+	case *render3.Component:
+		f.visitOwner(n, n.Attributes, n.Inputs, n.Outputs, n.References, nil)
+		f.visitAll(n.Children)
+	case *render3.Content:
+		f.visitOwner(n, n.Attributes, nil, nil, nil, nil)
+		f.visitAll(n.Children)
+	case *render3.BoundText:
+		f.expression(n.Value)
+	case *render3.LetDeclaration:
+		if f.contains(n.NameSpan) {
+			f.target.key, f.target.owner = n, n
+		}
+		f.expression(n.Value)
+	case *render3.IfBlock:
+		f.blockName(n, n.BlockNode)
+		for _, branch := range n.Branches {
+			if f.contains(branch.SourceSpan()) {
+				f.target.node = branch
+				f.blockName(branch, branch.BlockNode)
+				f.variables(branch, branch.ExpressionAlias)
+				f.expression(branch.Expression)
+				f.visitAll(branch.Children)
+			}
+		}
+	case *render3.ForLoopBlock:
+		f.blockName(n, n.BlockNode)
+		f.variables(n, append([]*render3.Variable{n.Item}, n.ContextVariables...)...)
+		if n.Expression != nil {
+			f.expression(n.Expression)
+		}
+		if n.TrackBy != nil {
+			f.expression(n.TrackBy)
+		}
+		f.visitAll(n.Children)
+		if n.Empty != nil && f.contains(n.Empty.SourceSpan()) {
+			f.target.node = n.Empty
+			f.blockName(n.Empty, n.Empty.BlockNode)
+			f.visitAll(n.Empty.Children)
+		}
+	case *render3.SwitchBlock:
+		f.blockName(n, n.BlockNode)
+		f.expression(n.Expression)
+		for _, c := range n.Cases {
+			if f.contains(c.SourceSpan()) {
+				f.target.node = c
+				f.blockName(c, c.BlockNode)
+				f.expression(c.Expression)
+				f.visitAll(c.Children)
+			}
+		}
+	case *render3.DeferredBlock:
+		f.blockName(n, n.BlockNode)
+		for _, triggers := range []*render3.DeferredBlockTriggers{n.Triggers, n.PrefetchTriggers, n.HydrateTriggers} {
+			if triggers != nil && triggers.When != nil {
+				f.expression(triggers.When.Value)
+			}
+		}
+		f.visitAll(n.Children)
+		if n.Placeholder != nil && f.contains(n.Placeholder.SourceSpan()) {
+			f.target.node = n.Placeholder
+			f.blockName(n.Placeholder, n.Placeholder.BlockNode)
+			f.visitAll(n.Placeholder.Children)
+		}
+		if n.Loading != nil && f.contains(n.Loading.SourceSpan()) {
+			f.target.node = n.Loading
+			f.blockName(n.Loading, n.Loading.BlockNode)
+			f.visitAll(n.Loading.Children)
+		}
+		if n.Error != nil && f.contains(n.Error.SourceSpan()) {
+			f.target.node = n.Error
+			f.blockName(n.Error, n.Error.BlockNode)
+			f.visitAll(n.Error.Children)
+		}
+	}
+}
+
+// visitOwner looks for the offset in the attributes, bindings, references and variables of an
+// element or template.
+func (f *targetFinder) visitOwner(owner render3.Node, attrs []*render3.TextAttribute, inputs []*render3.BoundAttribute,
+	outputs []*render3.BoundEvent, refs []*render3.Reference, vars []*render3.Variable) {
+	for _, attr := range attrs {
+		if f.contains(attr.KeySpan) {
+			f.target.key, f.target.owner = attr, owner
+		}
+	}
+	for _, input := range inputs {
+		if f.contains(input.KeySpan) {
+			f.target.key, f.target.owner = input, owner
+		}
+		f.expression(input.Value)
+	}
+	for _, output := range outputs {
+		if f.contains(output.KeySpan) {
+			f.target.key, f.target.owner = output, owner
+		}
+		f.expression(output.Handler)
+	}
+	for _, ref := range refs {
+		if f.contains(ref.KeySpan) {
+			f.target.key, f.target.owner = ref, owner
+		}
+	}
+	f.variables(owner, vars...)
+}
+
+func (f *targetFinder) variables(owner render3.Node, vars ...*render3.Variable) {
+	for _, v := range vars {
+		if v != nil && f.contains(v.KeySpan) {
+			f.target.key, f.target.owner = v, owner
+		}
+	}
+}
+
+func (f *targetFinder) blockName(node render3.Node, block *render3.BlockNode) {
+	if block != nil && f.contains(block.NameSpan) {
+		f.target.block = node
+	}
+}
+
+// expression records the innermost sub-expression of an expression containing the offset.
+// Implicit receivers are empty and never targets.
+func (f *targetFinder) expression(ast expression_parser.AST) {
+	typecheck.WalkExpression(ast, func(ast expression_parser.AST) {
+		switch ast.(type) {
+		case *expression_parser.ImplicitReceiver, *expression_parser.ThisReceiver, *expression_parser.EmptyExpr:
+			return
+		}
+		if span := ast.SourceSpan(); span != nil && span.Start <= f.offset && f.offset <= span.End {
+			f.target.expr = ast
+		}
+	})
+}
+
+// contains reports whether a span contains the offset. The end of a span counts, as the cursor
+// is often right after a name.
+func (f *targetFinder) contains(span *util.ParseSourceSpan) bool {
+	return span != nil && span.Start != nil && span.End != nil && span.Start.Offset <= f.offset && f.offset <= span.End.Offset
+}
@@ -0,0 +1,215 @@
+package languageservice_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	languageservice "ngc-go/packages/language-service/src"
+)
+
+const highlightDirective = `import {Directive, Input, Output, EventEmitter} from '@angular/core';
+
+@Directive({selector: '[highlight]', standalone: true, exportAs: 'highlight'})
+export class HighlightDirective {
+  @Input({required: true}) highlight = '';
+  @Output() highlighted = new EventEmitter<string>();
+}
+`
+
+const heroComponent = `import {Component} from '@angular/core';
+import {HighlightDirective} from './highlight.directive';
+
+@Component({
+  selector: 'app-hero',
+  standalone: true,
+  imports: [HighlightDirective],
+  template: '<p #para [highlight]="name" (highlighted)="log($event)">{{ name }} {{ para.id }}</p>',
+})
+export class HeroComponent {
+  name = 'Windstorm';
+}
+`
+
+const listComponent = `import {Component} from '@angular/core';
+import {HeroComponent} from './hero.component';
+
+@Component({
+  selector: 'app-list',
+  standalone: true,
+  imports: [HeroComponent],
+  templateUrl: './list.component.html',
+})
+export class ListComponent {
+  heroes = ['Windstorm'];
+}
+`
+
+const listTemplate = `@let count = heroes.length;
+@for (hero of heroes; track hero) {
+  <app-hero></app-hero>
+}
+<span>{{ count }}</span>
+`
+
+// project writes the fixture project and returns a language service for it.
+func project(t *testing.T) (*languageservice.LanguageService, string) {
+	t.Helper()
+	root := t.TempDir()
+	for name, text := range map[string]string{
+		"highlight.directive.ts": highlightDirective,
+		"hero.component.ts":      heroComponent,
+		"list.component.ts":      listComponent,
+		"list.component.html":    listTemplate,
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return languageservice.New(root), root
+}
+
+// offset returns the offset of the first occurrence of marker in text, plus delta.
+func offset(t *testing.T, text string, marker string, delta int) int {
+	t.Helper()
+	i := strings.Index(text, marker)
+	if i < 0 {
+		t.Fatalf("%q not found", marker)
+	}
+	return i + delta
+}
+
+func TestLanguageService(t *testing.T) {
+	t.Run("should report template problems of open documents", func(t *testing.T) {
+		ls, root := project(t)
+		list := filepath.Join(root, "list.component.html")
+		if diags := ls.Diagnostics(list); len(diags) != 0 {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+		ls.Open(list, listTemplate+"<app-unknown></app-unknown>\n</div>\n")
+		var messages []string
+		for _, diag := range ls.Diagnostics(list) {
+			messages = append(messages, diag.Message)
+		}
+		if len(messages) != 1 || !strings.Contains(messages[0], "Unexpected closing tag \"div\"") {
+			t.Errorf("expected the parse error only, got %q", messages)
+		}
+		ls.Open(list, listTemplate+"<app-unknown></app-unknown>\n")
+		diags := ls.Diagnostics(list)
+		if len(diags) != 1 || !strings.Contains(diags[0].Message, "'app-unknown' is not a known element") {
+			t.Errorf("expected an unknown element, got %v", diags)
+		}
+		ls.Close(list)
+		if diags := ls.Diagnostics(list); len(diags) != 0 {
+			t.Errorf("expected the file on disk to be used again, got %v", diags)
+		}
+	})
+
+	t.Run("should describe elements, bindings and template entities", func(t *testing.T) {
+		ls, root := project(t)
+		hero := filepath.Join(root, "hero.component.ts")
+		list := filepath.Join(root, "list.component.html")
+		for _, c := range []struct {
+			file, text, marker string
+			delta              int
+			expected           string
+		}{
+			{hero, heroComponent, "[highlight]", 2, "(input) `HighlightDirective.highlight` (required)"},
+			{hero, heroComponent, "(highlighted)", 2, "(output) `HighlightDirective.highlighted`"},
+			{hero, heroComponent, "para.id", 1, "(reference) `#para`: `<p>`"},
+			{hero, heroComponent, "{{ name", 4, "(property) `HeroComponent.name`"},
+			{hero, heroComponent, "<p", 1, "(element) `<p>`\n\n(directive) `HighlightDirective` matching `[highlight]`"},
+			{list, listTemplate, "<app-hero>", 2, "(element) `<app-hero>`\n\n(component) `HeroComponent` matching `app-hero`"},
+			{list, listTemplate, "{{ count", 4, "(let) `@let count`"},
+			{list, listTemplate, "track hero", 7, "(variable) `hero`"},
+			{list, listTemplate, "@for", 2, "(block) `@for`\n\nRenders its content for each item of a collection, tracked by the `track` expression."},
+		} {
+			info := ls.QuickInfo(c.file, offset(t, c.text, c.marker, c.delta))
+			if info == nil {
+				t.Errorf("expected quick info at %q", c.marker)
+				continue
+			}
+			if info.Text != c.expected {
+				t.Errorf("expected %q at %q, got %q", c.expected, c.marker, info.Text)
+			}
+		}
+		if info := ls.QuickInfo(hero, offset(t, heroComponent, "selector", 0)); info != nil {
+			t.Errorf("expected no quick info outside of templates, got %q", info.Text)
+		}
+	})
+
+	t.Run("should find the definitions of references, @let and components", func(t *testing.T) {
+		ls, root := project(t)
+		hero := filepath.Join(root, "hero.component.ts")
+		list := filepath.Join(root, "list.component.html")
+
+		spans := ls.Definition(hero, offset(t, heroComponent, "para.id", 1))
+		if len(spans) != 1 || spans[0].FileName != hero || heroComponent[spans[0].Start:spans[0].End] != "para" {
+			t.Errorf("expected the reference, got %+v", spans)
+		}
+		spans = ls.Definition(list, offset(t, listTemplate, "{{ count", 4))
+		if len(spans) != 1 || listTemplate[spans[0].Start:spans[0].End] != "count" {
+			t.Errorf("expected the @let declaration, got %+v", spans)
+		}
+		spans = ls.Definition(list, offset(t, listTemplate, "<app-hero>", 2))
+		if len(spans) != 1 || spans[0].FileName != hero || !strings.HasPrefix(heroComponent[spans[0].Start:], "class HeroComponent") {
+			t.Errorf("expected the component class, got %+v", spans)
+		}
+		spans = ls.Definition(hero, offset(t, heroComponent, "[highlight]", 2))
+		if len(spans) != 1 || !strings.HasPrefix(highlightDirective[spans[0].Start:], "@Input({required: true}) highlight") {
+			t.Errorf("expected the input property, got %+v", spans)
+		}
+	})
+
+	t.Run("should complete elements, bindings and blocks", func(t *testing.T) {
+		ls, root := project(t)
+		list := filepath.Join(root, "list.component.html")
+		labels := func(text string) map[string]languageservice.CompletionKind {
+			t.Helper()
+			ls.Open(list, text)
+			completions := ls.Completions(list, strings.Index(text, "|"))
+			kinds := make(map[string]languageservice.CompletionKind)
+			for _, completion := range completions {
+				kinds[completion.Label] = completion.Kind
+			}
+			return kinds
+		}
+
+		elements := labels("<app-h|")
+		if elements["app-hero"] != languageservice.CompletionComponent || elements["div"] != languageservice.CompletionElement {
+			t.Errorf("expected components and DOM elements, got %v", elements)
+		}
+		inputs := labels("<p [|")
+		if _, ok := inputs["[highlight]"]; ok {
+			t.Errorf("expected the directives out of scope to be left out")
+		}
+		if _, ok := inputs["[title]"]; !ok {
+			t.Errorf("expected DOM properties, got %v", inputs)
+		}
+		blocks := labels("<div>@f|")
+		if blocks["for"] != languageservice.CompletionBlock || blocks["let"] != languageservice.CompletionBlock {
+			t.Errorf("expected block keywords, got %v", blocks)
+		}
+		if attrs := labels(`<p title="@|`); len(attrs) != 0 {
+			t.Errorf("expected no completion in attribute values, got %v", attrs)
+		}
+	})
+
+	t.Run("should complete the inputs of directives in scope", func(t *testing.T) {
+		ls, root := project(t)
+		hero := filepath.Join(root, "hero.component.ts")
+		text := strings.Replace(heroComponent, "<p #para", "<p [h #para", 1)
+		ls.Open(hero, text)
+		completions := ls.Completions(hero, offset(t, text, "[h ", 2))
+		for _, completion := range completions {
+			if completion.Label == "[highlight]" {
+				if completion.Detail != "HighlightDirective.highlight" || text[completion.Replace.Start:completion.Replace.End] != "[h" {
+					t.Errorf("unexpected completion %+v", completion)
+				}
+				return
+			}
+		}
+		t.Errorf("expected [highlight] among %d completions", len(completions))
+	})
+}
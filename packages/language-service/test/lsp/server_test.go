@@ -0,0 +1,138 @@
+package lsp_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"ngc-go/packages/language-service/src/lsp"
+)
+
+const heroComponent = `import {Component} from '@angular/core';
+
+@Component({
+  selector: 'app-hero',
+  standalone: true,
+  template: '<p #para>{{ name }} {{ para.id }}</p><app-unknown></app-unknown>',
+})
+export class HeroComponent {
+  name = 'Windstorm';
+}
+`
+
+// session frames messages as a client does and returns what the server wrote back.
+func session(t *testing.T, root string, messages ...map[string]interface{}) []map[string]interface{} {
+	t.Helper()
+	var in bytes.Buffer
+	for _, msg := range messages {
+		msg["jsonrpc"] = "2.0"
+		body, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	var out bytes.Buffer
+	if err := lsp.NewServer(root).Serve(&in, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	var received []map[string]interface{}
+	r := bufio.NewReader(&out)
+	for {
+		header, err := textproto.NewReader(r).ReadMIMEHeader()
+		if err == io.EOF {
+			return received
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		length, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			t.Fatal(err)
+		}
+		var msg map[string]interface{}
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatal(err)
+		}
+		received = append(received, msg)
+	}
+}
+
+func TestServer(t *testing.T) {
+	root := t.TempDir()
+	fileName := filepath.Join(root, "hero.component.ts")
+	if err := os.WriteFile(fileName, []byte(heroComponent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	uri := "file://" + filepath.ToSlash(fileName)
+	// `para` of `para.id`, on the line of the template.
+	line := strings.Count(heroComponent[:strings.Index(heroComponent, "para.id")], "\n")
+	character := strings.Index(strings.Split(heroComponent, "\n")[line], "para.id") + 1
+	position := map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     map[string]interface{}{"line": line, "character": character},
+	}
+
+	received := session(t, "",
+		map[string]interface{}{"id": 1, "method": "hover", "params": position},
+		map[string]interface{}{"id": 2, "method": "initialize", "params": map[string]interface{}{"rootUri": "file://" + filepath.ToSlash(root)}},
+		map[string]interface{}{"method": "initialized", "params": map[string]interface{}{}},
+		map[string]interface{}{"method": "textDocument/didOpen", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "languageId": "typescript", "version": 1, "text": heroComponent},
+		}},
+		map[string]interface{}{"id": 3, "method": "textDocument/hover", "params": position},
+		map[string]interface{}{"id": 4, "method": "textDocument/definition", "params": position},
+		map[string]interface{}{"id": 5, "method": "workspace/symbol", "params": map[string]interface{}{}},
+		map[string]interface{}{"id": 6, "method": "shutdown"},
+		map[string]interface{}{"method": "exit"},
+	)
+	if len(received) != 7 {
+		t.Fatalf("expected 7 messages, got %d: %v", len(received), received)
+	}
+
+	if code := received[0]["error"].(map[string]interface{})["code"]; code != float64(-32002) {
+		t.Errorf("expected requests before initialize to fail, got %v", received[0])
+	}
+	capabilities := received[1]["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	if capabilities["hoverProvider"] != true || capabilities["definitionProvider"] != true {
+		t.Errorf("unexpected capabilities %v", capabilities)
+	}
+
+	if received[2]["method"] != "textDocument/publishDiagnostics" {
+		t.Fatalf("expected diagnostics, got %v", received[2])
+	}
+	diags := received[2]["params"].(map[string]interface{})["diagnostics"].([]interface{})
+	if len(diags) != 1 || diags[0].(map[string]interface{})["code"] != "NG8001" {
+		t.Errorf("expected an unknown element, got %v", diags)
+	}
+
+	hover := received[3]["result"].(map[string]interface{})
+	if value := hover["contents"].(map[string]interface{})["value"]; value != "(reference) `#para`: `<p>`" {
+		t.Errorf("unexpected hover %q", value)
+	}
+	locations := received[4]["result"].([]interface{})
+	if len(locations) != 1 {
+		t.Fatalf("expected the reference, got %v", locations)
+	}
+	start := locations[0].(map[string]interface{})["range"].(map[string]interface{})["start"].(map[string]interface{})
+	if start["line"] != float64(line) || start["character"] != float64(strings.Index(strings.Split(heroComponent, "\n")[line], "#para")+1) {
+		t.Errorf("unexpected definition %v", locations[0])
+	}
+
+	if code := received[5]["error"].(map[string]interface{})["code"]; code != float64(-32601) {
+		t.Errorf("expected unsupported methods to fail, got %v", received[5])
+	}
+	if _, ok := received[6]["result"]; !ok || received[6]["result"] != nil {
+		t.Errorf("expected shutdown to return null, got %v", received[6])
+	}
+}